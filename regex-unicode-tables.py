@@ -21,6 +21,33 @@ BASE_URL = 'http://www.unicode.org/Public/6.3.0/ucd/'
 DATA = 'UnicodeData.txt'
 SCRIPTS = 'Scripts.txt'
 
+# The rules for grapheme clusters and words (`\b{g}` and `\b{wb}`) need
+# properties that are much newer than the ones above (e.g., for emoji ZWJ
+# sequences and Indic conjuncts), so they're read from a newer version.
+SEGMENT_URL = 'http://www.unicode.org/Public/16.0.0/ucd/'
+GRAPHEME_BREAK = 'auxiliary/GraphemeBreakProperty.txt'
+WORD_BREAK = 'auxiliary/WordBreakProperty.txt'
+EMOJI = 'emoji/emoji-data.txt'
+DERIVED = 'DerivedCoreProperties.txt'
+
+# The names of the segmentation categories in src/segment.rs.
+grapheme_cats = {
+    'CR': 'GCR', 'LF': 'GLF', 'Control': 'GControl', 'Extend': 'GExtend',
+    'ZWJ': 'GZWJ', 'Regional_Indicator': 'GRegionalIndicator',
+    'Prepend': 'GPrepend', 'SpacingMark': 'GSpacingMark', 'L': 'GL',
+    'V': 'GV', 'T': 'GT', 'LV': 'GLV', 'LVT': 'GLVT',
+}
+word_cats = {
+    'CR': 'WCR', 'LF': 'WLF', 'Newline': 'WNewline', 'Extend': 'WExtend',
+    'ZWJ': 'WZWJ', 'Regional_Indicator': 'WRegionalIndicator',
+    'Format': 'WFormat', 'Katakana': 'WKatakana',
+    'Hebrew_Letter': 'WHebrewLetter', 'ALetter': 'WALetter',
+    'Single_Quote': 'WSingleQuote', 'Double_Quote': 'WDoubleQuote',
+    'MidNumLet': 'WMidNumLet', 'MidLetter': 'WMidLetter',
+    'MidNum': 'WMidNum', 'Numeric': 'WNumeric',
+    'ExtendNumLet': 'WExtendNumLet', 'WSegSpace': 'WWSegSpace',
+}
+
 # Mapping taken from Table 12 from:
 # http://www.unicode.org/reports/tr44/#General_Category_Values
 expanded_categories = {
@@ -36,6 +63,13 @@ expanded_categories = {
 }
 
 
+def ucd_version(url):
+    # The URLs of the database have its version after `Public`, e.g.,
+    # `http://www.unicode.org/Public/6.3.0/ucd/`.
+    parts = url.rstrip('/').split('/')
+    return parts[parts.index('Public') + 1]
+
+
 def as_4byte_uni(n):
     s = hex(n)[2:]
     return '\\U%s%s' % ('0' * (8 - len(s)), s)
@@ -60,6 +94,28 @@ def read_cats(f):
     return assigned
 
 
+def read_names(f):
+    # Characters whose names are derived from their code points are listed
+    # as ranges (e.g., `<CJK Ideograph, First>`), and control characters
+    # have no names.
+    names, ideographs = [], []
+    for row in csv.reader(f, delimiter=';'):
+        (hex, name) = (int(row[0], 16), row[1])
+        if name.startswith('<CJK Ideograph'):
+            ideographs.append(hex)
+        elif not name.startswith('<'):
+            names.append((name, hex))
+    # The ranges are given by their first and last characters.
+    pairs = zip(ideographs[0::2], ideographs[1::2])
+    letters = [c for first, last in pairs for c in xrange(first, last + 1)]
+    return sorted(names), group(letters)
+
+
+def names_to_rust(names):
+    names = ('("%s", \'%s\')' % (name, as_4byte_uni(c)) for name, c in names)
+    return ',\n    '.join(names)
+
+
 def read_scripts(f):
     assigned = defaultdict(list)
     for line in f:
@@ -80,6 +136,72 @@ def read_scripts(f):
     return assigned
 
 
+def read_incb(f):
+    # Lines look like `0915..0939    ; InCB; Consonant # ...`.
+    assigned = defaultdict(list)
+    for line in f:
+        line = line.split('#')[0].strip()
+        if not line:
+            continue
+        fields = map(str.strip, line.split(';'))
+        if len(fields) != 3 or fields[1] != 'InCB':
+            continue
+        if '..' not in fields[0]:
+            hex1 = hex2 = int(fields[0], 16)
+        else:
+            hex1, hex2 = map(lambda s: int(s, 16), fields[0].split('..'))
+        assigned[fields[2]].extend(xrange(hex1, hex2 + 1))
+    return assigned
+
+
+def grapheme_categories(breaks, emoji, incb):
+    # Extended_Pictographic and InCB=Consonant characters are all
+    # Grapheme_Cluster_Break=Other, and InCB=Extend and InCB=Linker ones are
+    # all Extend or ZWJ, so each character is given a single category.
+    cats = {}
+    for name, letters in breaks.items():
+        for letter in letters:
+            cats[letter] = grapheme_cats[name]
+    for letter in emoji['Extended_Pictographic']:
+        assert letter not in cats
+        cats[letter] = 'GExtPict'
+    for letter in incb['Consonant']:
+        assert letter not in cats
+        cats[letter] = 'GConsonant'
+    for letter in incb['Extend']:
+        if cats[letter] == 'GExtend':
+            cats[letter] = 'GConjunctExtend'
+    for letter in incb['Linker']:
+        assert cats[letter] == 'GExtend'
+        cats[letter] = 'GLinker'
+    return cats
+
+
+def word_categories(breaks):
+    cats = {}
+    for name, letters in breaks.items():
+        for letter in letters:
+            cats[letter] = word_cats[name]
+    return cats
+
+
+def group_categories(cats):
+    grouped = []
+    for letter in sorted(cats):
+        if grouped and grouped[-1][1] == letter - 1 \
+                and grouped[-1][2] == cats[letter]:
+            grouped[-1][1] = letter
+        else:
+            grouped.append([letter, letter, cats[letter]])
+    return grouped
+
+
+def categories_to_rust(rs):
+    rs = ("('%s', '%s', %s)" % (as_4byte_uni(s), as_4byte_uni(e), cat)
+          for s, e, cat in rs)
+    return ',\n    '.join(rs)
+
+
 def group(letters):
     letters = sorted(set(letters))
     grouped = []
@@ -111,6 +233,57 @@ def groups_to_rust(groups):
     return '\n'.join(rust_groups)
 
 
+NAMES_TPL = '''// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// DO NOT EDIT. Automatically generated by 'src/etc/regex-unicode-tables'
+// on {date},
+// from version {version} of the Unicode Character Database.
+
+use parse::Class;
+
+// The names of characters, sorted, for `\\N{{name}}`. The names of CJK
+// unified ideographs and Hangul syllables are derived from their code
+// points instead.
+pub static UNICODE_NAMES: &'static [(&'static str, char)] = &[
+    {names}
+];
+
+// The CJK unified ideographs, whose names are `CJK UNIFIED IDEOGRAPH-`
+// followed by their code point in hex.
+pub static UNIFIED_IDEOGRAPHS: Class = &[
+    {ideographs}
+];
+
+// The short names of the jamo that Hangul syllables are made of, from
+// Jamo.txt, in the order in which the syllables are numbered: a syllable's
+// name is `HANGUL SYLLABLE ` followed by the names of its leading
+// consonant, its vowel and its trailing consonant (if any).
+pub static JAMO_L: &'static [&'static str] = &[
+    "G", "GG", "N", "D", "DD", "R", "M", "B", "BB", "S", "SS", "", "J", "JJ",
+    "C", "K", "T", "P", "H",
+];
+
+pub static JAMO_V: &'static [&'static str] = &[
+    "A", "AE", "YA", "YAE", "EO", "E", "YEO", "YE", "O", "WA", "WAE", "OE",
+    "YO", "U", "WEO", "WE", "WI", "YU", "EU", "YI", "I",
+];
+
+pub static JAMO_T: &'static [&'static str] = &[
+    "", "G", "GG", "GS", "N", "NJ", "NH", "D", "L", "LG", "LM", "LB", "LS",
+    "LT", "LP", "LH", "M", "B", "BS", "S", "SS", "NG", "J", "C", "K", "T",
+    "P", "H",
+];
+'''
+
+
 if __name__ == '__main__':
     parser = argparse.ArgumentParser(
         description='Generate Unicode character class tables.')
@@ -120,8 +293,27 @@ if __name__ == '__main__':
             'the CWD.')
     aa('--base-url', type=str, default=BASE_URL,
        help='The base URL to use for downloading Unicode data files.')
+    aa('--segment-url', type=str, default=SEGMENT_URL,
+       help='The base URL to use for downloading the Unicode data files '
+            'of grapheme cluster and word boundaries.')
+    aa('--names', action='store_true',
+       help='When set, the table of character names (for `\\N{name}`) is '
+            'generated instead.')
     args = parser.parse_args()
 
+    if args.names:
+        if args.local:
+            data = open(DATA)
+        else:
+            data = urllib2.urlopen(args.base_url + '/' + DATA)
+        names, ideographs = read_names(data)
+        now = datetime.datetime.now()
+        print(NAMES_TPL.format(date=str(now),
+                               version=ucd_version(args.base_url),
+                               names=names_to_rust(names),
+                               ideographs=ranges_to_rust(ideographs)))
+        raise SystemExit
+
     if args.local:
         cats = read_cats(open(DATA))
         scripts = read_scripts(open(SCRIPTS))
@@ -129,6 +321,15 @@ if __name__ == '__main__':
         cats = read_cats(urllib2.urlopen(args.base_url + '/' + DATA))
         scripts = read_scripts(urllib2.urlopen(args.base_url + '/' + SCRIPTS))
 
+    def open_segment(name):
+        if args.local:
+            return open(name.split('/')[-1])
+        return urllib2.urlopen(args.segment_url + '/' + name)
+    gbreaks = read_scripts(open_segment(GRAPHEME_BREAK))
+    wbreaks = read_scripts(open_segment(WORD_BREAK))
+    emoji = read_scripts(open_segment(EMOJI))
+    incb = read_incb(open_segment(DERIVED))
+
     # Get Rust code for all Unicode general categories and scripts.
     combined = dict(cats, **scripts)
     unigroups = groups_to_rust({k: group(letters)
@@ -145,6 +346,15 @@ if __name__ == '__main__':
     perlw = [ord('_')] + perld + low + up
     wgroups = ranges_to_rust(group(perlw + cats['L'][:]))
 
+    # The word characters of Unicode word boundaries (the `w` flag).
+    uwgroups = ranges_to_rust(group(perlw + cats['L'] + cats['M']
+                                    + cats['N'] + cats['Pc']))
+
+    # The categories of grapheme cluster and word boundaries.
+    gcats = categories_to_rust(group_categories(
+        grapheme_categories(gbreaks, emoji, incb)))
+    wcats = categories_to_rust(group_categories(word_categories(wbreaks)))
+
     tpl = '''// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
 // file at the top-level directory of this distribution and at
 // http://rust-lang.org/COPYRIGHT.
@@ -156,9 +366,22 @@ if __name__ == '__main__':
 // except according to those terms.
 
 // DO NOT EDIT. Automatically generated by 'src/etc/regexp-unicode-tables'
-// on {date}.
+// on {date},
+// from version {version} of the Unicode Character Database (and version
+// {segment} for the categories of grapheme cluster and word boundaries).
 
 use parse::{{Class, NamedClasses}};
+use segment::{{GraphemeCat, WordCat}};
+use segment::{{
+    GCR, GLF, GControl, GExtend, GConjunctExtend, GLinker, GZWJ,
+    GRegionalIndicator, GPrepend, GSpacingMark, GL, GV, GT, GLV, GLVT,
+    GExtPict, GConsonant,
+}};
+use segment::{{
+    WCR, WLF, WNewline, WExtend, WZWJ, WRegionalIndicator, WFormat,
+    WKatakana, WHebrewLetter, WALetter, WSingleQuote, WDoubleQuote,
+    WMidNumLet, WMidLetter, WMidNum, WNumeric, WExtendNumLet, WWSegSpace,
+}};
 
 pub static UNICODE_CLASSES: NamedClasses = &[
 
@@ -177,7 +400,26 @@ pub static PERLS: Class = &[
 pub static PERLW: Class = &[
     {wgroups}
 ];
+
+pub static UNICODE_WORD: Class = &[
+    {uwgroups}
+];
+
+// The categories of grapheme cluster boundaries (`\\b{{g}}`), from Unicode
+// {segment}. Characters that aren't listed are `GOther`.
+pub static GRAPHEME_CATS: &'static [(char, char, GraphemeCat)] = &[
+    {gcats}
+];
+
+// The categories of word boundaries (`\\b{{wb}}`), from Unicode {segment}.
+// Characters that aren't listed are `WOther`.
+pub static WORD_CATS: &'static [(char, char, WordCat)] = &[
+    {wcats}
+];
 '''
     now = datetime.datetime.now()
-    print(tpl.format(date=str(now), groups=unigroups,
-                     dgroups=dgroups, sgroups=sgroups, wgroups=wgroups))
+    print(tpl.format(date=str(now), version=ucd_version(args.base_url),
+                     segment=ucd_version(args.segment_url),
+                     groups=unigroups,
+                     dgroups=dgroups, sgroups=sgroups, wgroups=wgroups,
+                     uwgroups=uwgroups, gcats=gcats, wcats=wcats))
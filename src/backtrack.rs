@@ -0,0 +1,675 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module implements a bounded backtracking matching engine. It explores
+// the alternatives of a program one at a time, in priority order, so the
+// first `Match` instruction it reaches is the leftmost-first match. This
+// avoids copying capture groups between threads, which makes it faster than
+// the NFA simulation in vm.rs on short inputs.
+//
+// Backtracking is exponential in general. To keep it linear, every pair of
+// instruction and input position is visited at most once: if a pair was
+// already visited, then every path from it has already been explored (with
+// a higher priority) and failed. The set of visited pairs is a bitset of
+// size `len(insts) * len(input)`, which is why this engine is only used when
+// that product is small (see `should_exec`). This is the same approach taken
+// by RE2 and Go's regexp package.
+//
+// Since captures have no influence on whether a path succeeds, the set of
+// visited pairs is kept when moving on to the next starting position. It
+// only covers the positions that the paths explored so far have reached,
+// starting at the lowest one that the paths from the current starting
+// position can reach (which is before it, for lookbehind), so it grows as
+// the search goes further and the pairs at positions left behind are
+// dropped. A search finding a match near where it starts doesn't touch the
+// rest of the text.
+//
+// This is the only engine that evaluates lookahead and lookbehind. The
+// expression inside a lookaround is searched for on the same job stack, on
+// top of the jobs of the path that reached it, and whether it matches at a
+// position doesn't depend on that path, so the pairs it visits can be
+// remembered too. The exception is a search that succeeds, which stops
+// before every path has been explored: the pairs it visited are forgotten
+// again. The expression of a lookbehind is compiled in reverse, and is
+// searched for by consuming the characters before the position instead of
+// the ones after it, so the visited set also covers the positions before the
+// start of the search that lookbehind can reach. Programs with lookaround are
+// run by this engine however long the input is, so their visited set is
+// bounded instead (see `WINDOW_LIMIT`): a search whose paths from a single
+// starting position reach further than it allows fails, as one that
+// exceeds its step limit does.
+//
+// It's also the only engine that evaluates backreferences, for which nothing
+// can be remembered: whether a path from a pair succeeds depends on what the
+// groups it refers to matched. So programs with backreferences are searched
+// without a visited set, in time that can be exponential in the length of
+// the input. The only thing kept per path is the position at which it last
+// took each `Split`, so that a loop that matched the empty string isn't
+// repeated forever at that position.
+//
+// And it's the only engine that evaluates `\K`, which is compiled to a
+// `Save(0)` that overwrites the start of the match. The other engines compare
+// where threads started by that slot, but here the path that matches first
+// (or the longest one) wins no matter where its match is said to start, so
+// the last `\K` that the winning path crossed is the one reported.
+//
+// In programs with lookaround, backreferences or `\K`, atomic groups (and
+// possessive repetition) are compiled to `Cut` instructions, whose
+// expression is searched for like that of a positive lookahead, except that
+// the path continues where the first match of it ends. The pairs visited
+// are remembered the same way.
+
+use std::cmp;
+use compile::{
+    Program, Match, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    EmptySegmentBoundary, EmptyLook, Cut, LookMatch, GroupRef,
+    Save, Jump, Split,
+};
+use parse;
+use parse::{Flags, FLAG_MULTI, FLAG_NEGATED, FLAG_SEARCH, FLAG_BEHIND};
+use parse::{FLAG_NOCASE, FLAG_ASCII};
+use vm;
+use vm::{CaptureLocs, MatchKind, Exists, Location, Submatches};
+use vm::{Budget, StepLimitExceeded};
+
+/// The default maximum number of bits in the visited set of the backtracker.
+/// (This is 32KB.)
+pub static DEFAULT_LIMIT: uint = 256 * (1 << 10);
+
+/// The least number of bits that the visited set may take when searching a
+/// program that only the backtracker can run, whatever the limit given.
+/// (This is 1MB.)
+pub static WINDOW_LIMIT: uint = 8 * (1 << 20);
+
+/// Returns true if and only if the backtracker can search the program given
+/// between `start` and `end` with a visited set of at most `limit` bits.
+pub fn should_exec(prog: &Program, start: uint, end: uint,
+                   limit: uint) -> bool {
+    visited_bits(prog.insts.len(), end - start) <= limit
+}
+
+/// Returns the number of bits that the visited set of a search of a program
+/// of `ninsts` instructions takes to cover `len` bytes of text.
+pub fn visited_bits(ninsts: uint, len: uint) -> uint {
+    ninsts * (len + 1)
+}
+
+/// Returns the most bits that the visited set of a search of the program
+/// given may take, with the limit given. Programs that other engines can run
+/// should only be searched when `should_exec` is true, so that the set
+/// never reaches the limit.
+pub fn visited_limit(prog: &Program, limit: uint) -> uint {
+    if prog.backtrack_only() {
+        cmp::max(limit, WINDOW_LIMIT)
+    } else {
+        limit
+    }
+}
+
+/// Runs a backtracking search on the compiled expression given on the search
+/// text `input`. The semantics are exactly the same as `vm::run`.
+///
+/// The memory used is at most proportional to the number of instructions
+/// times the length of the text searched, and it's bounded by `limit` (see
+/// `visited_limit`). Callers should check `should_exec` first, unless the
+/// program can only be run by the backtracker, whose searches fail with
+/// `StepLimitExceeded` when their paths reach further than that bound
+/// allows.
+pub fn run<'r, 't, 'b>(which: MatchKind, prog: &'r Program, input: &'t str,
+                       start: uint, end: uint, anchored: bool, limit: uint,
+                       budget: &mut Budget<'b>)
+                  -> Result<CaptureLocs, StepLimitExceeded> {
+    exec(which, prog, input, start, end, anchored, false, limit, budget)
+}
+
+/// Runs a backtracking search like `run`, except that the leftmost-longest
+/// match is found. The semantics are the same as `vm::run_longest`.
+///
+/// Of the longest matches, the capture groups of the one that's first by
+/// priority are returned (which aren't necessarily the ones that POSIX
+/// would choose).
+pub fn run_longest<'r, 't, 'b>(which: MatchKind, prog: &'r Program,
+                               input: &'t str, start: uint, end: uint,
+                               anchored: bool, limit: uint,
+                               budget: &mut Budget<'b>)
+                              -> Result<CaptureLocs, StepLimitExceeded> {
+    exec(which, prog, input, start, end, anchored, true, limit, budget)
+}
+
+/// Returns the index of the `Match` instruction that the match found by `run`
+/// (or `run_longest`, if `longest` is true) starting exactly at `start` ends
+/// at, or `None` if there's no such match. This tells which alternate of a
+/// top-level alternation the match comes from (see `Program::branches`).
+pub fn run_match_pc<'r, 't, 'b>(prog: &'r Program, input: &'t str,
+                                start: uint, longest: bool, limit: uint,
+                                budget: &mut Budget<'b>)
+                               -> Result<Option<uint>, StepLimitExceeded> {
+    let mut bt = Backtrack::new(Location, prog, input, start, input.len(),
+                                true, longest, limit, *budget);
+    let caps = bt.run();
+    *budget = bt.budget;
+    let caps = try!(caps);
+    Ok(if caps.get(0).is_some() { Some(bt.matched) } else { None })
+}
+
+fn exec<'r, 't, 'b>(which: MatchKind, prog: &'r Program, input: &'t str,
+                    start: uint, end: uint, anchored: bool, longest: bool,
+                    limit: uint, budget: &mut Budget<'b>)
+                   -> Result<CaptureLocs, StepLimitExceeded> {
+    let mut bt = Backtrack::new(which, prog, input, start, end, anchored,
+                                longest, limit, *budget);
+    let caps = bt.run();
+    *budget = bt.budget;
+    caps
+}
+
+// A unit of work for the backtracker: either continue from an instruction at
+// a position, or undo a change to a capture slot or to the position at which
+// a `Split` was last taken.
+enum Job {
+    Inst(uint, uint),
+    RestoreCapture(uint, Option<uint>),
+    RestoreSplit(uint, Option<uint>),
+}
+
+struct Backtrack<'r, 't, 'b> {
+    which: MatchKind,
+    prog: &'r Program,
+    input: &'t str,
+    start: uint,
+    end: uint,
+    // The first position in the visited set.
+    lo: uint,
+    anchored: bool,
+    // Whether every path is explored, to find the longest match.
+    longest: bool,
+    caps: CaptureLocs,
+    // When finding the longest match, the captures of the longest found so
+    // far.
+    best: Option<CaptureLocs>,
+    // The `Match` instruction that the match found (or the longest one so
+    // far) ends at.
+    matched: uint,
+    jobs: Vec<Job>,
+    // The visited pairs, by position and then by instruction, from `lo` to
+    // the furthest position reached so far.
+    visited: Vec<u32>,
+    // The lowest position that the paths from the current starting position
+    // can reach, before which visited pairs can be dropped.
+    reach: uint,
+    // The most bits that `visited` may take.
+    cap: uint,
+    // Whether visited pairs are remembered, which they can't be when the
+    // program has backreferences. Instead, the position at which the
+    // current path last took each `Split` is kept.
+    memo: bool,
+    splits: Vec<Option<uint>>,
+    // The number of lookarounds being searched, and the visited pairs (as
+    // instructions and positions) they've marked.
+    depth: uint,
+    trail: Vec<(uint, uint)>,
+    // Whether a lookbehind is being searched, and the position it can't
+    // consume characters before.
+    backward: bool,
+    floor: uint,
+    // Where the expression of the last lookaround or atomic group to match
+    // ended.
+    look_end: uint,
+    budget: Budget<'b>,
+    // Set when the budget runs out, which stops the search.
+    exceeded: bool,
+}
+
+impl<'r, 't, 'b> Backtrack<'r, 't, 'b> {
+    fn new(which: MatchKind, prog: &'r Program, input: &'t str, start: uint,
+           end: uint, anchored: bool, longest: bool, limit: uint,
+           budget: Budget<'b>) -> Backtrack<'r, 't, 'b> {
+        // Backreferences need every group, whatever is asked for.
+        let ncaps = match which {
+            _ if prog.backrefs => prog.num_captures(),
+            Exists => 0,
+            Location => 1,
+            Submatches => prog.num_captures(),
+        };
+        let lo = start - cmp::min(start, prog.behind);
+        let splits = if prog.backrefs { prog.insts.len() } else { 0 };
+        Backtrack {
+            which: which,
+            prog: prog,
+            input: input,
+            start: start,
+            end: end,
+            lo: lo,
+            anchored: anchored,
+            longest: longest,
+            caps: Vec::from_elem(ncaps * 2, None),
+            best: None,
+            matched: 0,
+            jobs: Vec::with_capacity(10),
+            visited: vec!(),
+            reach: lo,
+            cap: visited_limit(prog, limit),
+            memo: !prog.backrefs,
+            splits: Vec::from_elem(splits, None),
+            depth: 0,
+            trail: vec!(),
+            backward: false,
+            floor: 0,
+            look_end: 0,
+            budget: budget,
+            exceeded: false,
+        }
+    }
+
+    fn run(&mut self) -> Result<CaptureLocs, StepLimitExceeded> {
+        // Just like in the NFA, an anchored expression can only match at
+        // the start of the search.
+        let anchored = self.anchored ||
+            match *self.prog.insts.get(1) {
+                EmptyBegin(flags) if flags & FLAG_MULTI == 0 => true,
+                _ => false,
+            };
+        let mut at = self.start;
+        loop {
+            if !anchored && self.prog.prefilter.is_some() {
+                let haystack = self.input.as_bytes().slice_from(at);
+                match self.prog.prefilter.find(haystack) {
+                    None => break,
+                    Some(i) => at += i,
+                }
+                if at > self.end {
+                    break
+                }
+                self.budget.count_candidate();
+            }
+            self.reach = at - cmp::min(at, self.prog.behind);
+            if self.backtrack(at) || self.best.is_some() {
+                if self.exceeded {
+                    return Err(StepLimitExceeded)
+                }
+                return Ok(match (self.which, self.best.take()) {
+                    (Exists, _) => vec![Some(0), Some(0)],
+                    (_, Some(best)) => self.result(best),
+                    (_, None) => {
+                        let caps = self.caps.clone();
+                        self.result(caps)
+                    }
+                })
+            }
+            if anchored || at >= self.end {
+                break
+            }
+            at = self.input.char_range_at(at).next;
+        }
+        Ok(match self.which {
+            Exists | Location => vec![None, None],
+            Submatches => Vec::from_elem(self.caps.len(), None),
+        })
+    }
+
+    // Returns the slots asked for of the captures given. (Every group is
+    // tracked for backreferences, even when only the location is wanted.)
+    fn result(&self, mut caps: CaptureLocs) -> CaptureLocs {
+        match self.which {
+            Location => caps.truncate(2),
+            Exists | Submatches => {}
+        }
+        caps
+    }
+
+    // Explores every path starting at the beginning of the program at `at`,
+    // in priority order. Returns true as soon as one of them matches.
+    fn backtrack(&mut self, at: uint) -> bool {
+        self.jobs.clear();
+        self.jobs.push(Inst(0, at));
+        loop {
+            match self.jobs.pop() {
+                None => return false,
+                Some(RestoreCapture(slot, old)) => {
+                    *self.caps.get_mut(slot) = old
+                }
+                Some(RestoreSplit(pc, old)) => *self.splits.get_mut(pc) = old,
+                Some(Inst(pc, ic)) => {
+                    if self.step(pc, ic) {
+                        return true
+                    }
+                }
+            }
+        }
+    }
+
+    // Follows a single path from `pc` at `ic` until it either matches or
+    // fails. Lower priority alternatives are pushed on to the job stack.
+    //
+    // When the budget runs out, `exceeded` is set and true is returned, so
+    // that the search stops.
+    fn step(&mut self, mut pc: uint, mut ic: uint) -> bool {
+        let prog = self.prog;
+        loop {
+            if self.has_visited(pc, ic) {
+                // The visited set may have run out of room instead.
+                return self.exceeded
+            }
+            if !self.budget.take(1) {
+                self.exceeded = true;
+                return true
+            }
+            match *prog.insts.get(pc) {
+                Match => {
+                    if !self.longest || self.caps.len() < 2 {
+                        self.matched = pc;
+                        return true
+                    }
+                    let longer = match self.best {
+                        None => true,
+                        Some(ref best) => Some(ic) > *best.get(1),
+                    };
+                    if longer {
+                        self.best = Some(self.caps.clone());
+                        self.matched = pc;
+                    }
+                    return false
+                }
+                LookMatch => {
+                    self.look_end = ic;
+                    return true
+                }
+                Save(slot) => {
+                    if slot < self.caps.len() {
+                        let old = *self.caps.get(slot);
+                        self.jobs.push(RestoreCapture(slot, old));
+                        *self.caps.get_mut(slot) = Some(ic);
+                    }
+                    pc += 1;
+                }
+                Jump(to) => pc = to,
+                Split(x, y) => {
+                    if !self.memo {
+                        // Coming back to a Split without consuming anything
+                        // would only repeat the same path.
+                        let old = *self.splits.get(pc);
+                        if old == Some(ic) {
+                            return false
+                        }
+                        self.jobs.push(RestoreSplit(pc, old));
+                        *self.splits.get_mut(pc) = Some(ic);
+                    }
+                    self.jobs.push(Inst(y, ic));
+                    pc = x;
+                }
+                GroupRef(cap, flags) => {
+                    let group = (*self.caps.get(2 * cap),
+                                 *self.caps.get(2 * cap + 1));
+                    ic = match group {
+                        (Some(s), Some(e)) if s <= e => {
+                            match self.match_group(s, e, ic, flags) {
+                                None => return false,
+                                Some(next) => next,
+                            }
+                        }
+                        _ => return false,
+                    };
+                    pc += 1;
+                }
+                EmptyBegin(flags) if flags & FLAG_SEARCH > 0 => {
+                    if ic != self.start {
+                        return false
+                    }
+                    pc += 1;
+                }
+                EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_) => {
+                    let (prev, cur) = (self.prev(ic), self.cur(ic));
+                    let last = ic + 1 == self.input.len();
+                    if !vm::empty_matches(prog.insts.get(pc), prev, cur,
+                                          last) {
+                        return false
+                    }
+                    pc += 1;
+                }
+                EmptySegmentBoundary(seg, flags) => {
+                    if !vm::segment_matches(seg, flags, self.input, ic) {
+                        return false
+                    }
+                    pc += 1;
+                }
+                EmptyLook(next, flags) => {
+                    let negated = flags & FLAG_NEGATED > 0;
+                    let backward = flags & FLAG_BEHIND > 0;
+                    let floor =
+                        if flags & FLAG_SEARCH > 0 { self.start } else { 0 };
+                    let matched = self.look(pc + 1, ic, backward, floor,
+                                            negated);
+                    if self.exceeded {
+                        return true
+                    }
+                    if matched == negated {
+                        return false
+                    }
+                    pc = next;
+                }
+                Cut(next) => {
+                    let (backward, floor) = (self.backward, self.floor);
+                    let matched = self.look(pc + 1, ic, backward, floor,
+                                            false);
+                    if self.exceeded {
+                        return true
+                    }
+                    if !matched {
+                        return false
+                    }
+                    pc = next;
+                    ic = self.look_end;
+                }
+                ref inst if self.backward => {
+                    if ic <= self.floor {
+                        return false
+                    }
+                    let prev = self.input.char_range_at_reverse(ic);
+                    if !vm::char_matches(inst, Some(prev.ch)) {
+                        return false
+                    }
+                    pc += 1;
+                    ic = prev.next;
+                }
+                ref inst => {
+                    if ic >= self.end {
+                        return false
+                    }
+                    let next = self.input.char_range_at(ic);
+                    if !vm::char_matches(inst, Some(next.ch)) {
+                        return false
+                    }
+                    pc += 1;
+                    ic = next.next;
+                }
+            }
+        }
+    }
+
+    // Searches for the expression of a lookaround or an atomic group, which
+    // starts at `pc`, at `ic`, consuming characters backwards (down to
+    // `floor`) if `backward` is set. Returns true if it matches, and sets
+    // `look_end` to where it ended.
+    //
+    // The captures it sets are kept when it matches (and restored when the
+    // path that follows it fails), unless `discard` is set, as it is for a
+    // negative lookaround, which fails when its expression matches.
+    fn look(&mut self, pc: uint, ic: uint, backward: bool, floor: uint,
+            discard: bool) -> bool {
+        let (base, mark) = (self.jobs.len(), self.trail.len());
+        let saved = self.caps.clone();
+        let splits = self.splits.clone();
+        let (outer_backward, outer_floor) = (self.backward, self.floor);
+        self.backward = backward;
+        self.floor = floor;
+        self.depth += 1;
+        self.jobs.push(Inst(pc, ic));
+        let mut matched = false;
+        while self.jobs.len() > base {
+            match self.jobs.pop().unwrap() {
+                RestoreCapture(slot, old) => *self.caps.get_mut(slot) = old,
+                RestoreSplit(pc, old) => *self.splits.get_mut(pc) = old,
+                Inst(pc, ic) => {
+                    if self.step(pc, ic) {
+                        matched = true;
+                        break
+                    }
+                }
+            }
+        }
+        self.depth -= 1;
+        self.backward = outer_backward;
+        self.floor = outer_floor;
+        self.splits = splits;
+        if matched {
+            self.jobs.truncate(base);
+            for i in range(mark, self.trail.len()) {
+                let (pc, ic) = *self.trail.get(i);
+                let k = self.bit(pc, ic);
+                *self.visited.get_mut(k / 32) &= !(1u32 << (k % 32));
+            }
+            if discard {
+                self.caps = saved;
+            } else {
+                for (slot, &old) in saved.iter().enumerate() {
+                    if *self.caps.get(slot) != old {
+                        self.jobs.push(RestoreCapture(slot, old));
+                    }
+                }
+            }
+        }
+        self.trail.truncate(mark);
+        matched
+    }
+
+    // Marks the instruction `pc` at position `ic` as visited, and returns
+    // whether it had already been visited. When the visited set would take
+    // more than `cap` bits, `exceeded` is set and true is returned.
+    #[inline]
+    fn has_visited(&mut self, pc: uint, ic: uint) -> bool {
+        if !self.memo || ic < self.lo {
+            return false
+        }
+        let mut k = self.bit(pc, ic);
+        if k / 32 >= self.visited.len() {
+            if k >= self.cap {
+                if self.slide() {
+                    k = self.bit(pc, ic);
+                }
+                if k >= self.cap {
+                    self.exceeded = true;
+                    return true
+                }
+            }
+            if k / 32 >= self.visited.len() {
+                // Grown by doubling, so that growing one position at a time
+                // doesn't copy the set each time.
+                let len = self.visited.len();
+                let want = cmp::min(cmp::max(k / 32 + 1, 2 * len),
+                                    self.cap / 32 + 1);
+                self.visited.grow(want - len, &0u32);
+            }
+        }
+        let (word, bit) = (k / 32, 1u32 << (k % 32));
+        let w = self.visited.get_mut(word);
+        if *w & bit > 0 {
+            return true
+        }
+        *w |= bit;
+        if self.depth > 0 {
+            self.trail.push((pc, ic));
+        }
+        false
+    }
+
+    // The index in the visited set of the instruction `pc` at position `ic`.
+    #[inline]
+    fn bit(&self, pc: uint, ic: uint) -> uint {
+        (ic - self.lo) * self.prog.insts.len() + pc
+    }
+
+    // Drops the visited pairs at the positions before `reach`, which no path
+    // can reach anymore, so that the set starts there. Returns false without
+    // dropping anything if they're less than half of what the set may take,
+    // since the set would soon be full again.
+    fn slide(&mut self) -> bool {
+        let shift = (self.reach - self.lo) * self.prog.insts.len();
+        if shift < self.cap / 2 {
+            return false
+        }
+        let (q, r) = (shift / 32, shift % 32);
+        let len = self.visited.len();
+        if q >= len {
+            self.visited.clear();
+        } else {
+            for i in range(0, len - q) {
+                let mut w = *self.visited.get(i + q) >> r;
+                if r > 0 && i + q + 1 < len {
+                    w |= *self.visited.get(i + q + 1) << (32 - r);
+                }
+                *self.visited.get_mut(i) = w;
+            }
+            self.visited.truncate(len - q);
+        }
+        self.lo = self.reach;
+        true
+    }
+
+    // Matches the text between `s` and `e`, which a group matched, at `ic`.
+    // Returns the position after it, if it matches. (The parser rejects
+    // backreferences in lookbehind, so they're never matched backwards.)
+    fn match_group(&self, s: uint, e: uint, mut ic: uint,
+                   flags: Flags) -> Option<uint> {
+        for gc in self.input.slice(s, e).chars() {
+            if ic >= self.end {
+                return None
+            }
+            let next = self.input.char_range_at(ic);
+            if !fold_eq(flags, gc, next.ch) {
+                return None
+            }
+            ic = next.next;
+        }
+        Some(ic)
+    }
+
+    // The character preceding `ic`, if any. (The range of the search is
+    // ignored, so that assertions see the surrounding text.)
+    #[inline]
+    fn prev(&self, ic: uint) -> Option<char> {
+        if ic == 0 {
+            None
+        } else {
+            Some(self.input.char_range_at_reverse(ic).ch)
+        }
+    }
+
+    // The character at `ic`, if any.
+    #[inline]
+    fn cur(&self, ic: uint) -> Option<char> {
+        if ic < self.input.len() {
+            Some(self.input.char_at(ic))
+        } else {
+            None
+        }
+    }
+}
+
+// Returns true if the characters are equal, ignoring case if the flags of a
+// backreference say so (and then only for ASCII letters, if Unicode is off).
+fn fold_eq(flags: Flags, a: char, b: char) -> bool {
+    if a == b || flags & FLAG_NOCASE == 0 {
+        a == b
+    } else if flags & FLAG_ASCII > 0 {
+        parse::swap_ascii_case(a) == b
+    } else {
+        a.to_uppercase() == b.to_uppercase()
+    }
+}
@@ -0,0 +1,191 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module tells which alternate of a regex's top-level alternation (e.g.,
+// `foo|foobar|ba+r`) a match comes from, without a capture group around each
+// alternate.
+//
+// The alternates are found by the parser (see `parse::parse_alternates`),
+// since the syntax tree of the whole expression has other alternations in
+// it, and the compiler ends each of them with a `Match` of its own (see
+// `Program::with_alternates`). Alternates of literals are still merged into
+// a trie (`foo|foobar` shares the `foo` of both), but each end of the trie
+// has its own `Match` too. So the `Match` that a search reaches tells the
+// alternate its match comes from.
+//
+// A match is found by the regex as usual, and then the program is run again
+// anchored where it starts, by a simulation that only tracks where threads
+// end, to find the `Match` that the regex's own search would have reached.
+// Telling the alternate costs about what finding the capture groups of the
+// match does, with a single pair of them. Programs with lookaround are run
+// by the backtracker instead, which reports the `Match` it reached.
+//
+// A match that comes from an alternate with a backreference may depend on
+// the groups of the others, and `\K` moves the start of the match, so that
+// it can't be run from where it starts. So the alternate of the matches of a
+// regex that uses either isn't known.
+
+use std::mem;
+
+use backtrack;
+use compile::{
+    Program, Inst, Match, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    EmptySegmentBoundary, Save, Jump, Split,
+};
+use parse::{FLAG_NEGATED, FLAG_SEARCH};
+use vm;
+use vm::{Budget, CaptureLocs, CharReader, Location, Threads};
+
+/// Returns the index of the alternate of the top-level alternation of `prog`
+/// that the match starting at `s` in `text` comes from, or `None` if it
+/// isn't known (see `Program::branches`). `longest` and `limit` are the
+/// regex's semantics and backtrack limit.
+pub fn find(prog: &Program, text: &str, s: uint, longest: bool, limit: uint)
+           -> Option<uint> {
+    if prog.branches.len() == 0 || prog.backrefs || prog.keep {
+        return None
+    }
+    let pc =
+        if prog.lookaround {
+            let mut budget = Budget::new(None, None);
+            match backtrack::run_match_pc(prog, text, s, longest, limit,
+                                          &mut budget) {
+                Ok(pc) => pc,
+                Err(_) => None,
+            }
+        } else {
+            Nfa {
+                prog: prog,
+                start: s,
+                ic: 0,
+                chars: CharReader::new(text),
+            }.run(longest)
+        };
+    pc.and_then(|pc| {
+        prog.branches.as_slice().bsearch(|&(p, _)| p.cmp(&pc))
+            .map(|i| { let (_, alt) = *prog.branches.get(i); alt })
+    })
+}
+
+// An NFA simulation anchored at `start`, which reports the `Match` that the
+// match found ends at.
+struct Nfa<'r, 't> {
+    prog: &'r Program,
+    start: uint,
+    ic: uint,
+    chars: CharReader<'t>,
+}
+
+impl<'r, 't> Nfa<'r, 't> {
+    // Returns the `Match` the leftmost-first match ends at, or that of the
+    // longest match if `longest` is true (and of those that are as long, the
+    // one that's first by priority).
+    fn run(&mut self, longest: bool) -> Option<uint> {
+        let ninsts = self.prog.insts.len();
+        let mut clist = &mut Threads::new(Location, ninsts, 1);
+        let mut nlist = &mut Threads::new(Location, ninsts, 1);
+        let mut groups: CaptureLocs = vec![None, None];
+        let mut best: Option<(uint, uint)> = None;
+
+        self.ic = self.start;
+        let mut next_ic = self.chars.set(self.start);
+        self.add(clist, 0, groups.as_mut_slice());
+        while clist.size > 0 {
+            self.ic = next_ic;
+            next_ic = self.chars.advance();
+
+            let mut i = 0;
+            while i < clist.size {
+                let pc = clist.pc(i);
+                i += 1;
+                match *self.prog.insts.get(pc) {
+                    Match => {
+                        let end = clist.groups(i - 1)[1].unwrap();
+                        if !longest {
+                            // The threads that follow have a lower priority,
+                            // and those of a higher one are in `nlist`.
+                            best = Some((pc, end));
+                            break
+                        }
+                        best = match best {
+                            Some((_, e)) if e >= end => best,
+                            _ => Some((pc, end)),
+                        };
+                    }
+                    ref inst => {
+                        if vm::char_matches(inst, self.chars.prev) {
+                            self.add(nlist, pc + 1, clist.groups(i - 1));
+                        }
+                    }
+                }
+            }
+            mem::swap(&mut clist, &mut nlist);
+            nlist.empty();
+        }
+        best.map(|(pc, _)| pc)
+    }
+
+    fn add(&self, nlist: &mut Threads, pc: uint, groups: &mut [Option<uint>]) {
+        if nlist.contains(pc) {
+            return
+        }
+        // See the corresponding comments in vm.rs for why states are added
+        // even for empty instructions.
+        match *self.prog.insts.get(pc) {
+            EmptyBegin(flags) if flags & FLAG_SEARCH > 0 => {
+                nlist.add(pc, groups, true);
+                if self.ic == self.start {
+                    self.add(nlist, pc + 1, groups)
+                }
+            }
+            ref inst @ EmptyBegin(_) => self.add_empty(nlist, pc, inst, groups),
+            ref inst @ EmptyEnd(_) => self.add_empty(nlist, pc, inst, groups),
+            ref inst @ EmptyWordBoundary(_) =>
+                self.add_empty(nlist, pc, inst, groups),
+            EmptySegmentBoundary(seg, flags) => {
+                nlist.add(pc, groups, true);
+                let boundary = self.chars.is_segment_boundary(seg);
+                if boundary == !(flags & FLAG_NEGATED > 0) {
+                    self.add(nlist, pc + 1, groups)
+                }
+            }
+            Save(slot) => {
+                nlist.add(pc, groups, true);
+                if slot <= 1 {
+                    let old = groups[slot];
+                    groups[slot] = Some(self.ic);
+                    self.add(nlist, pc + 1, groups);
+                    groups[slot] = old;
+                } else {
+                    self.add(nlist, pc + 1, groups);
+                }
+            }
+            Jump(to) => {
+                nlist.add(pc, groups, true);
+                self.add(nlist, to, groups)
+            }
+            Split(x, y) => {
+                nlist.add(pc, groups, true);
+                self.add(nlist, x, groups);
+                self.add(nlist, y, groups);
+            }
+            _ => nlist.add(pc, groups, false),
+        }
+    }
+
+    fn add_empty(&self, nlist: &mut Threads, pc: uint, inst: &Inst,
+                 groups: &mut [Option<uint>]) {
+        nlist.add(pc, groups, true);
+        if vm::empty_matches(inst, self.chars.prev, self.chars.cur,
+                             self.chars.is_last()) {
+            self.add(nlist, pc + 1, groups)
+        }
+    }
+}
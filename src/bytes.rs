@@ -0,0 +1,384 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// Every matching engine in this crate works on characters. To match bytes,
+// the bytes are transcoded to a string with one character per byte (byte `b`
+// becomes `b as char`, as in Latin-1), which is searched by a regex parsed
+// with `parse::parse_bytes`. Since every byte is a character of its own,
+// `.` and negated classes match any single byte where Unicode is off, and
+// invalid UTF8 is never replaced by U+FFFD. Where Unicode is on, the parser
+// compiles characters to the bytes of their UTF8 encodings instead, which
+// invalid UTF8 never matches. Positions in the transcoded string are
+// converted back to positions in the bytes before they're returned.
+//
+// Bytes that are all ASCII are the same as their transcoded string, so
+// they're searched as they are. Others are transcoded once per call, or once
+// per iterator for `find_iter`, and the texts of `filter` and `is_match_any`
+// one at a time, as they're searched.
+
+use std::fmt;
+use std::str;
+use std::str::{MaybeOwned, Owned, Slice};
+
+use parse;
+use parse::{Ast, FLAG_EMPTY, FLAG_ASCII};
+use re;
+use re::Regex;
+
+/// ByteRegex is a regular expression that matches arbitrary bytes (which
+/// need not be UTF8) rather than characters.
+///
+/// # Bytes that aren't valid UTF8
+///
+/// A regex compiled with `ByteRegex::new` starts with Unicode off, as if it
+/// began with `(?-u)` (see the crate documentation), so every byte is
+/// matched on its own, whether or not it's part of valid UTF8: `.` matches
+/// any byte but `\n`, `[^a]` matches any byte but `a`, `\w`, `\d`, `\s`
+/// and `\b` are ASCII only, and case insensitive matching only folds the
+/// ASCII letters.
+///
+/// Where Unicode is off, escapes like `\xFF` and `\x{FF}` match a single
+/// byte, and so can be used anywhere, including in ranges like
+/// `[\x80-\xFF]`. A non-ASCII character written in the expression (e.g.,
+/// `é`) matches the bytes of its UTF8 encoding, but it's an error to use one
+/// in a character class, since it isn't a single byte. Unicode classes like
+/// `\pL` are errors too, as are escapes above `\xFF`.
+///
+/// A regex compiled with `ByteRegex::with_unicode` starts with Unicode on
+/// instead, where `.`, classes and literals match the UTF8 encodings of
+/// characters just like a `Regex` does, so bytes that aren't part of valid
+/// UTF8 never match them. Such bytes can only be matched where Unicode is
+/// turned off (e.g., `(?-u:\xFF)` or `(?-u:.)`), or not at all if it never
+/// is. (A Unicode `\b` is an error, since it can't tell whether the bytes
+/// around it are word characters. Use `(?-u:\b)` instead.)
+///
+/// Either way, a search advances past a whole character after an empty
+/// match, just like a `Regex` does, so that an empty match is never found
+/// in the middle of the UTF8 encoding of a character (even where Unicode
+/// is off, and a non-empty match could end there). A byte that isn't part
+/// of valid UTF8 is advanced past on its own.
+///
+/// Positions are byte indices into the bytes searched.
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::ByteRegex;
+/// let re = ByteRegex::new(r"(?s)\x00(..)\xFF").unwrap();
+/// let bytes = &[0x61, 0x00, 0xC3, 0x28, 0xFF];
+/// assert_eq!(re.find(bytes), Some((1, 5)));
+/// assert_eq!(re.captures_pos(bytes).unwrap(),
+///            vec!(Some((1, 5)), Some((2, 4))));
+///
+/// let re = ByteRegex::with_unicode(r"\w+(?-u:\xFF)").unwrap();
+/// assert_eq!(re.find(&[0xCE, 0x94, 0x78, 0xFF]), Some((0, 4)));
+/// assert_eq!(re.find(&[0xCE, 0x78, 0xFF]), Some((1, 3)));
+/// ```
+#[deriving(Clone)]
+pub struct ByteRegex {
+    re: Regex,
+}
+
+impl fmt::Show for ByteRegex {
+    /// Shows the original regular expression.
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "{}", self.re.original)
+    }
+}
+
+impl ByteRegex {
+    /// Compiles a regular expression that matches bytes, which starts with
+    /// Unicode off.
+    ///
+    /// If an invalid expression is given, then an error is returned.
+    pub fn new(re: &str) -> Result<ByteRegex, parse::Error> {
+        ByteRegex::compile(re, false)
+    }
+
+    /// Compiles a regular expression that matches bytes, which starts with
+    /// Unicode on, so that it only matches valid UTF8 unless Unicode is
+    /// turned off with `(?-u)`.
+    ///
+    /// If an invalid expression is given, then an error is returned.
+    pub fn with_unicode(re: &str) -> Result<ByteRegex, parse::Error> {
+        ByteRegex::compile(re, true)
+    }
+
+    fn compile(re: &str, unicode: bool) -> Result<ByteRegex, parse::Error> {
+        let flags = if unicode { FLAG_EMPTY } else { FLAG_ASCII };
+        let ast = try!(parse::parse_bytes(re, flags,
+                                          parse::CompileLimits::new()));
+        Ok(ByteRegex { re: re::from_ast(re.to_owned(), ast) })
+    }
+
+    /// Returns the syntax tree that this regex was compiled from, in which
+    /// characters stand for bytes (see `Regex::syntax`).
+    pub fn syntax(&self) -> ~Ast {
+        self.re.syntax()
+    }
+
+    /// Returns true if and only if the regex matches the bytes given.
+    pub fn is_match(&self, bytes: &[u8]) -> bool {
+        self.re.is_match(transcode(bytes).as_slice())
+    }
+
+    /// Returns the indices of the byte strings in `texts` that the regex
+    /// matches, in order, like `Regex::filter`.
+    pub fn filter(&self, texts: &[&[u8]]) -> Vec<uint> {
+        let mut found = vec!();
+        re::filter(&self.re, texts.iter().map(|b| transcode(*b)),
+                   |i| { found.push(i); true });
+        found
+    }
+
+    /// Returns true if and only if the regex matches one of the byte
+    /// strings in `texts`, like `Regex::is_match_any`. No byte string after
+    /// the first one that matches is searched.
+    pub fn is_match_any(&self, texts: &[&[u8]]) -> bool {
+        let mut found = false;
+        re::filter(&self.re, texts.iter().map(|b| transcode(*b)),
+                   |_| { found = true; false });
+        found
+    }
+
+    /// Returns the start and end byte range of the leftmost-first match in
+    /// `bytes`. If no match exists, then `None` is returned.
+    pub fn find(&self, bytes: &[u8]) -> Option<(uint, uint)> {
+        self.find_at(bytes, 0)
+    }
+
+    /// Returns the start and end byte range of the leftmost-first match in
+    /// `bytes` that starts at or after the byte position `start`, like
+    /// `Regex::find_at`: the bytes before `start` are still looked at by
+    /// anchors and word boundaries. Any position up to the length of
+    /// `bytes` may be given, otherwise this function fails.
+    pub fn find_at(&self, bytes: &[u8], start: uint) -> Option<(uint, uint)> {
+        let text = transcode(bytes);
+        let text = text.as_slice();
+        let mut offsets = Offsets::new(text, bytes.len());
+        let pos = offsets.pos(text, bytes, start);
+        self.re.find_at(text, pos).map(|(s, e)| {
+            (offsets.byte(text, s), offsets.byte(text, e))
+        })
+    }
+
+    /// Returns an iterator over the start and end byte range of every
+    /// successive non-overlapping match in `bytes`, which follow the rules
+    /// given for `find_all`. The bytes are transcoded once, when the
+    /// iterator is created (see the module documentation).
+    pub fn find_iter<'r, 't>(&'r self, bytes: &'t [u8]) -> ByteMatches<'r, 't> {
+        let text = transcode(bytes);
+        let offsets = Offsets::new(text.as_slice(), bytes.len());
+        ByteMatches {
+            re: self,
+            bytes: bytes,
+            text: text,
+            offsets: offsets,
+            last_end: 0,
+            last_match: None,
+        }
+    }
+
+    /// Returns the start and end byte range of every successive
+    /// non-overlapping match in `bytes`.
+    ///
+    /// Successive matches follow the same rules as `Regex::find_iter`: an
+    /// empty match is followed by a search that starts after the character
+    /// that follows it (or after one byte, if its bytes aren't valid UTF8),
+    /// and an empty match that starts where the previous match ended isn't
+    /// reported. So a regex that can match the empty string finds an empty
+    /// match between every pair of characters (or bytes that aren't valid
+    /// UTF8) that isn't part of a match.
+    pub fn find_all(&self, bytes: &[u8]) -> Vec<(uint, uint)> {
+        self.find_iter(bytes).collect()
+    }
+
+    /// Returns the start and end byte range of every capture group of the
+    /// leftmost-first match in `bytes`, indexed by group. A group that
+    /// didn't take part in the match is `None`. If no match exists, then
+    /// `None` is returned.
+    pub fn captures_pos(&self, bytes: &[u8])
+                       -> Option<Vec<Option<(uint, uint)>>> {
+        let text = transcode(bytes);
+        let text = text.as_slice();
+        let mut offsets = Offsets::new(text, bytes.len());
+        self.re.captures(text).map(|caps| {
+            caps.iter_pos().map(|pos| {
+                pos.map(|(s, e)| (offsets.byte(text, s), offsets.byte(text, e)))
+            }).collect()
+        })
+    }
+
+    /// Replaces every non-overlapping match in `bytes` with `rep`, which is
+    /// inserted as is. If no match exists, then a copy of `bytes` is
+    /// returned.
+    pub fn replace_all(&self, bytes: &[u8], rep: &[u8]) -> Vec<u8> {
+        let mut dst = Vec::with_capacity(bytes.len());
+        let mut last = 0;
+        for (s, e) in self.find_iter(bytes) {
+            dst.push_all(bytes.slice(last, s));
+            dst.push_all(rep);
+            last = e;
+        }
+        dst.push_all(bytes.slice_from(last));
+        dst
+    }
+
+    /// Returns the bytes delimited by every non-overlapping match in
+    /// `bytes`, like `Regex::split`: the delimiters are the matches that
+    /// `find_iter` finds, and nothing follows one at the end of `bytes`.
+    pub fn split<'t>(&self, bytes: &'t [u8]) -> Vec<&'t [u8]> {
+        let mut pieces = vec!();
+        let mut last = 0;
+        for (s, e) in self.find_iter(bytes) {
+            pieces.push(bytes.slice(last, s));
+            last = e;
+        }
+        if last < bytes.len() {
+            pieces.push(bytes.slice_from(last));
+        }
+        pieces
+    }
+}
+
+/// ByteMatches is an iterator over the start and end byte ranges of the
+/// successive non-overlapping matches of a `ByteRegex` in some bytes.
+///
+/// `'r` is the lifetime of the regex and `'t` is the lifetime of the bytes.
+pub struct ByteMatches<'r, 't> {
+    re: &'r ByteRegex,
+    bytes: &'t [u8],
+    // The string searched, which borrows `bytes` if they're all ASCII.
+    text: MaybeOwned<'t>,
+    offsets: Offsets,
+    last_end: uint,
+    last_match: Option<uint>,
+}
+
+impl<'r, 't> Iterator<(uint, uint)> for ByteMatches<'r, 't> {
+    fn next(&mut self) -> Option<(uint, uint)> {
+        let text = self.text.as_slice();
+        while self.last_end <= text.len() {
+            let at = self.last_end;
+            let (s, e) = match self.re.re.find_at(text, at) {
+                None => return None,
+                Some(m) => m,
+            };
+            if !re::accept_match(text, (s, e), &mut self.last_end,
+                                 &mut self.last_match) {
+                // A byte that isn't valid UTF8 is skipped on its own.
+                self.last_end = skip(self.bytes, text, &mut self.offsets, at);
+                continue
+            }
+            return Some((self.offsets.byte(text, s),
+                         self.offsets.byte(text, e)))
+        }
+        None
+    }
+}
+
+// Returns the position in `text` that follows the character at `pos`, or
+// the byte at `pos` if it isn't part of valid UTF8. (Each byte is a
+// character of `text`, so a byte that isn't valid UTF8 is always skipped on
+// its own, whether or not the bytes before it were.)
+fn skip(bytes: &[u8], text: &str, offsets: &mut Offsets, pos: uint) -> uint {
+    if pos >= text.len() {
+        return re::next_char(text, pos)
+    }
+    let mut next = pos;
+    for _ in range(0, utf8_len(bytes.slice_from(offsets.byte(text, pos)))) {
+        next = re::next_char(text, next);
+    }
+    next
+}
+
+// Transcodes bytes to the string searched, with one character per byte.
+// Bytes that are all ASCII are already that string, and aren't copied.
+fn transcode<'t>(bytes: &'t [u8]) -> MaybeOwned<'t> {
+    if bytes.iter().all(|&b| b < 0x80) {
+        // ASCII is valid UTF8.
+        return Slice(str::from_utf8(bytes).unwrap())
+    }
+    let mut text = StrBuf::with_capacity(bytes.len() + bytes.len() / 2);
+    for &b in bytes.iter() {
+        text.push_char(b as char);
+    }
+    Owned(text.into_owned())
+}
+
+// Returns the number of bytes in the UTF8 encoding of the character that
+// `bytes` starts with, or 1 if it doesn't start with valid UTF8.
+fn utf8_len(bytes: &[u8]) -> uint {
+    let n = str::utf8_char_width(bytes[0]);
+    if n > 1 && n <= bytes.len() && str::is_utf8(bytes.slice_to(n)) {
+        n
+    } else {
+        1
+    }
+}
+
+// Offsets converts positions in a transcoded string back to positions in
+// the bytes it came from, and back. Conversions are quickest when the
+// positions given increase, since the string is only scanned forward from
+// the last one. The string is passed to each conversion, since it may be
+// kept in the same struct as this.
+struct Offsets {
+    // When every byte is ASCII, the positions are the same.
+    ascii: bool,
+    // The last position converted and the byte at it.
+    pos: uint,
+    byte: uint,
+}
+
+impl Offsets {
+    fn new(text: &str, nbytes: uint) -> Offsets {
+        Offsets {
+            ascii: text.len() == nbytes,
+            pos: 0,
+            byte: 0,
+        }
+    }
+
+    // Returns the byte that the position `pos` in `text` came from.
+    fn byte(&mut self, text: &str, pos: uint) -> uint {
+        if self.ascii {
+            return pos
+        }
+        if pos < self.pos {
+            self.pos = 0;
+            self.byte = 0;
+        }
+        while self.pos < pos {
+            self.pos = text.char_range_at(self.pos).next;
+            self.byte += 1;
+        }
+        self.byte
+    }
+
+    // Returns the position in `text` of the byte `byte` of `bytes`.
+    fn pos(&mut self, text: &str, bytes: &[u8], byte: uint) -> uint {
+        if byte > bytes.len() {
+            fail!("byte position {} is past the end of {} bytes",
+                  byte, bytes.len());
+        }
+        if self.ascii {
+            return byte
+        }
+        if byte < self.byte {
+            self.pos = 0;
+            self.byte = 0;
+        }
+        while self.byte < byte {
+            self.pos = text.char_range_at(self.pos).next;
+            self.byte += 1;
+        }
+        self.pos
+    }
+}
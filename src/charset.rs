@@ -0,0 +1,131 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module implements the executable form of a character class. The
+// syntax tree keeps a class as sorted ranges and flags, which the compiler
+// turns into a set that can tell quickly whether a character is in it:
+// characters below 256 are looked up in a bitmap that has negation and case
+// folding applied already, and other characters are searched for in the
+// ranges. A class with a few ranges is searched one range after the other,
+// and a bigger one by binary search.
+//
+// A case insensitive class compares the uppercase of a character with the
+// uppercase of the bounds of each range (see the FIXME in vm.rs). The bounds
+// are uppercased once, when the set is built, rather than at every step of
+// the search.
+
+use std::fmt;
+use std::mem;
+
+use parse::{Flags, FLAG_NOCASE, FLAG_NEGATED};
+
+// Classes with at most this many ranges are searched linearly.
+static LINEAR_RANGES: uint = 4;
+
+/// CharSet is a character class compiled for matching.
+pub struct CharSet {
+    /// The ranges of the class, as in the syntax tree.
+    pub ranges: Vec<(char, char)>,
+    // The ranges that characters are compared with, which are the uppercase
+    // of `ranges` for a case insensitive class.
+    search: Vec<(char, char)>,
+    // One bit for each character below 256, set when the class matches it.
+    latin1: [u64, ..4],
+    casei: bool,
+    negated: bool,
+    linear: bool,
+}
+
+impl CharSet {
+    /// Compiles the class with the ranges and flags given. Only the
+    /// `FLAG_NOCASE` and `FLAG_NEGATED` flags matter.
+    pub fn new(ranges: Vec<(char, char)>, flags: Flags) -> CharSet {
+        let casei = flags & FLAG_NOCASE > 0;
+        let search =
+            if casei {
+                ranges.iter().map(|&(start, end)| {
+                    (start.to_uppercase(), end.to_uppercase())
+                }).collect()
+            } else {
+                ranges.clone()
+            };
+        let mut set = CharSet {
+            linear: !casei && ranges.len() <= LINEAR_RANGES,
+            ranges: ranges,
+            search: search,
+            latin1: [0, ..4],
+            casei: casei,
+            negated: flags & FLAG_NEGATED > 0,
+        };
+        for n in range(0u, 256) {
+            if set.search_ranges(n as u8 as char) {
+                set.latin1[n / 64] |= 1 << (n % 64);
+            }
+        }
+        set
+    }
+
+    /// Returns true if the class matches the character given.
+    #[inline]
+    pub fn matches(&self, c: char) -> bool {
+        let n = c as uint;
+        if n < 256 {
+            return self.latin1[n / 64] & (1 << (n % 64)) > 0
+        }
+        self.search_ranges(c)
+    }
+
+    // Searches the ranges for the character given (or its uppercase, for a
+    // case insensitive class), and then applies negation.
+    fn search_ranges(&self, c: char) -> bool {
+        let found =
+            if self.linear {
+                self.search.iter().any(|&(start, end)| c >= start && c <= end)
+            } else {
+                let c = if self.casei { c.to_uppercase() } else { c };
+                self.search.as_slice().bsearch(|&(start, end)| {
+                    if c >= start && c <= end {
+                        Equal
+                    } else if start > c {
+                        Greater
+                    } else {
+                        Less
+                    }
+                }).is_some()
+            };
+        found != self.negated
+    }
+
+    /// Returns the number of bytes that this set occupies on the heap.
+    pub fn heap_size(&self) -> uint {
+        (self.ranges.capacity() + self.search.capacity())
+        * mem::size_of::<(char, char)>()
+    }
+}
+
+impl Clone for CharSet {
+    fn clone(&self) -> CharSet {
+        CharSet {
+            ranges: self.ranges.clone(),
+            search: self.search.clone(),
+            latin1: self.latin1,
+            casei: self.casei,
+            negated: self.negated,
+            linear: self.linear,
+        }
+    }
+}
+
+impl fmt::Show for CharSet {
+    /// Shows the ranges of the class, like the syntax tree does.
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "{}", self.ranges)
+    }
+}
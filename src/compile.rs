@@ -14,15 +14,24 @@
 
 use std::cmp;
 use std::iter;
+use std::mem;
+use std::str;
+use charset::CharSet;
+use literals;
+use literals::Prefilter;
+use onepass::OnePass;
+use shiftor::ShiftOr;
 use parse;
 use parse::{
-    Flags, FLAG_EMPTY,
-    Nothing, Literal, Dot, Class, Begin, End, WordBoundary, Capture, Cat, Alt,
-    Rep,
+    Flags, FLAG_EMPTY, FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL, FLAG_NEGATED,
+    FLAG_SEARCH, FLAG_ASCII, FLAG_UWORD, FLAG_BEHIND, FLAG_FINALNL,
+    Nothing, Literal, Dot, Class, Begin, End, WordBoundary, SegmentBoundary,
+    Lookaround, Backref, Keep, Atomic, Capture, Cat, Alt, Rep,
     ZeroOne, ZeroMore, OneMore,
 };
+use segment::{Segment, Grapheme, Word};
 
-type InstIdx = uint;
+pub type InstIdx = uint;
 
 #[deriving(Show, Clone)]
 pub enum Inst {
@@ -34,10 +43,10 @@ pub enum Inst {
     OneChar(char, Flags),
 
     // The CharClass instruction tries to match one input character against
-    // the range of characters given.
+    // the set of characters given (see charset.rs).
     // The flags indicate whether to do a case insentivie match and whether
     // the character class is negated or not.
-    CharClass(Vec<(char, char)>, Flags),
+    CharClass(CharSet, Flags),
 
     // Matches any character except new lines.
     // The flags indicate whether to include the '\n' character.
@@ -59,6 +68,42 @@ pub enum Inst {
     // that isn't a word boundary.
     EmptyWordBoundary(Flags),
 
+    // Matches a boundary between grapheme clusters or words, as defined by
+    // Unicode Standard Annex #29, and consumes no character.
+    // The flags indicate whether this matches a boundary or something that
+    // isn't a boundary.
+    EmptySegmentBoundary(Segment, Flags),
+
+    // Matches if the instructions that follow it, up to the `LookMatch`
+    // that ends them, match at the current position, and consumes no
+    // characters. Execution continues at the index given, which follows the
+    // `LookMatch`.
+    // The flags indicate whether this matches when they don't match instead,
+    // and whether this is a lookbehind, whose instructions are compiled in
+    // reverse and consume the characters before the current position. (And
+    // for a lookbehind, whether it can see the text before the start of the
+    // search.)
+    EmptyLook(InstIdx, Flags),
+
+    // Matches the instructions that follow it, up to the `LookMatch` that
+    // ends them, the first way that they can match (by priority), and then
+    // continues at the index given, which follows the `LookMatch`. No other
+    // way for them to match is tried, even if what follows fails.
+    // This is only used for the atomic groups of programs that are run by
+    // the backtracker anyway (see `backtrack_only`). Elsewhere, atomic
+    // groups are compiled like any other group.
+    Cut(InstIdx),
+
+    // When a LookMatch instruction is executed, the lookaround (or atomic
+    // group) that it ends has matched.
+    LookMatch,
+
+    // Matches the text that the capture group given last matched (and fails
+    // if it didn't match at all).
+    // The flags indicate whether to do a case insensitive match, and
+    // whether it only folds the ASCII letters.
+    GroupRef(uint, Flags),
+
     // Saves the current position in the input string to the Nth save slot.
     Save(uint),
 
@@ -71,6 +116,26 @@ pub enum Inst {
     Split(InstIdx, InstIdx),
 }
 
+/// Returns the number of bytes that the instructions given occupy on the
+/// heap, including the ranges of their character classes.
+pub fn insts_size(insts: &Vec<Inst>) -> uint {
+    insts.iter().fold(insts.capacity() * mem::size_of::<Inst>(), |n, inst| {
+        match *inst {
+            CharClass(ref set, _) => n + set.heap_size(),
+            _ => n,
+        }
+    })
+}
+
+// Returns the number of bytes that the parts of a program given occupy on
+// the heap, where the tables of the character classes of `insts` occupy
+// `classes`.
+fn heap_size(insts: &Vec<Inst>, classes: uint, prefix: &str,
+             branches: &Vec<(InstIdx, uint)>) -> uint {
+    insts.capacity() * mem::size_of::<Inst>() + classes + prefix.len()
+    + branches.capacity() * mem::size_of::<(InstIdx, uint)>()
+}
+
 /// Program represents a compiled regular expression. Once an expression is
 /// compiled, its representation is immutable and will never change.
 ///
@@ -85,24 +150,97 @@ pub struct Program {
     /// match, that prefix is stored here. (It's used in the VM to implement
     /// an optimization.)
     pub prefix: ~str,
+    /// Finds positions in the search text at which a match could start.
+    /// (It's used by the VM and the DFA to skip over text quickly.)
+    pub prefilter: Prefilter,
+    /// When the program is one-pass, this is its analysis. (It's used to
+    /// resolve capture groups without the VM.)
+    pub onepass: Option<OnePass>,
+    /// When the expression is tiny, this is a bit-parallel matcher for it.
+    /// (It's used to answer "is there a match" quickly.)
+    pub shiftor: Option<ShiftOr>,
+    /// Whether the expression uses `\G`, which can only be evaluated by
+    /// engines that know where the search started. (The DFA doesn't.)
+    pub search_start: bool,
+    /// Whether the expression uses `\b{g}` or `\b{wb}`, which can only be
+    /// evaluated by engines that know where they are in the input. (The DFA
+    /// doesn't.)
+    pub segments: bool,
+    /// Whether the expression uses lookahead or lookbehind, which only the
+    /// backtracking engine can evaluate.
+    pub lookaround: bool,
+    /// The most bytes before a position that lookbehind can look at.
+    pub behind: uint,
+    /// Whether the expression uses backreferences, which only the
+    /// backtracking engine can evaluate.
+    pub backrefs: bool,
+    /// Whether the expression uses `\K`, which saves the start of the match
+    /// again. Only the backtracking engine doesn't rely on where a match
+    /// starts being the position at which it was first saved.
+    pub keep: bool,
+    /// When the expression has a top-level alternation, the index of each
+    /// `Match` instruction along with the alternate that it ends, sorted by
+    /// the index (see `Program::with_alternates`).
+    pub branches: Vec<(InstIdx, uint)>,
+    /// The number of bytes that the instructions (with the tables of their
+    /// character classes), the literal prefix and the branches occupy on the
+    /// heap, as recorded when the program was built.
+    pub heap: uint,
+    /// The number of bytes that the one-pass analysis and the bit-parallel
+    /// matcher occupy on the heap, as recorded when they were built.
+    pub analyses_heap: uint,
 }
 
 impl Program {
     /// Compiles a Regex given its AST.
     pub fn new(ast: ~parse::Ast) -> (Program, ~[Option<~str>]) {
+        Program::with_alternates(vec!(ast))
+    }
+
+    /// Compiles a Regex given the alternates of its top-level alternation
+    /// (see `parse::parse_alternates`). When there's more than one, every
+    /// alternate ends with `Save(1)` and a `Match` of its own, so that the
+    /// `Match` a search reaches tells which alternate its match comes from
+    /// (see `branches`). Otherwise, the program is the one `new` compiles.
+    pub fn with_alternates(mut alts: Vec<~parse::Ast>)
+                          -> (Program, ~[Option<~str>]) {
+        let (prefilter, shiftor, cut) = {
+            let whole =
+                if alts.len() == 1 {
+                    None
+                } else {
+                    Some(parse::join_alternates(alts.clone()))
+                };
+            let ast = match whole {
+                Some(ref ast) => &**ast,
+                None => &**alts.get(0),
+            };
+            (Prefilter::new(ast), ShiftOr::new(ast), backtracks(ast))
+        };
         let mut c = Compiler {
             insts: Vec::with_capacity(100),
             names: Vec::with_capacity(10),
+            backward: false,
+            cut: cut,
+            classes: 0,
         };
 
         c.insts.push(Save(0));
-        c.compile(ast);
-        c.insts.push(Save(1));
-        c.insts.push(Match);
+        let branches =
+            if alts.len() == 1 {
+                c.compile(factor(alts.pop().unwrap()));
+                c.insts.push(Save(1));
+                c.insts.push(Match);
+                vec!()
+            } else {
+                c.compile_alternates(alts)
+            };
 
         // Try to discover a literal string prefix.
         // This is a bit hacky since we have to skip over the initial
         // 'Save' instruction.
+        // Case insensitive characters are never part of the prefix, since
+        // the prefix is searched for byte by byte.
         let mut pre = StrBuf::with_capacity(5);
         for i in iter::range(1, c.insts.len()) {
             match *c.insts.get(i) {
@@ -112,11 +250,158 @@ impl Program {
         }
 
         let names = c.names.as_slice().into_owned();
+        let prog = Program::from_parts(c.insts, &[0], pre.into_owned(),
+                                       prefilter, shiftor, branches,
+                                       c.classes);
+        (prog, names)
+    }
+
+    /// Builds a program from its instructions, whose expressions start at
+    /// the indices given, and the parts of it that can't be derived from
+    /// them. Whether it uses `\G`, segments, lookaround, backreferences or
+    /// `\K` is derived from the instructions, and so are how far back its
+    /// lookbehinds look and its one-pass analysis. `classes` is the number of
+    /// bytes that the tables of the character classes of the instructions
+    /// occupy on the heap.
+    ///
+    /// Every lookbehind in the instructions must be bounded (see
+    /// `max_behind`).
+    pub fn from_parts(insts: Vec<Inst>, starts: &[InstIdx], prefix: ~str,
+                      prefilter: Prefilter, shiftor: Option<ShiftOr>,
+                      branches: Vec<(InstIdx, uint)>, classes: uint)
+                     -> Program {
+        let search_start = uses_search_start(insts.as_slice());
+        let segments = uses_segments(insts.as_slice());
+        let lookaround = uses_lookaround(insts.as_slice());
+        let behind = max_behind(insts.as_slice())
+                     .expect("BUG: Unbounded lookbehind.");
+        let backrefs = uses_backrefs(insts.as_slice());
+        let keep = uses_keep(insts.as_slice(), starts);
+        let heap = heap_size(&insts, classes, prefix.as_slice(), &branches);
+        let mut prog = Program {
+            insts: insts,
+            prefix: prefix,
+            prefilter: prefilter,
+            onepass: None,
+            shiftor: shiftor,
+            search_start: search_start,
+            segments: segments,
+            lookaround: lookaround,
+            behind: behind,
+            backrefs: backrefs,
+            keep: keep,
+            branches: branches,
+            heap: heap,
+            analyses_heap: 0,
+        };
+        prog.onepass = OnePass::new(&prog);
+        prog.analyses_heap =
+            prog.onepass.as_ref().map_or(0, |op| op.heap_size())
+            + prog.shiftor.as_ref().map_or(0, |so| so.heap_size());
+        prog
+    }
+
+    /// Compiles a Regex given its AST such that the program matches the
+    /// *reverse* of every string matched by the Regex. Assertions are
+    /// flipped accordingly: `^` becomes `$` and vice versa.
+    ///
+    /// The reverse program is used by the DFA to find where a match starts
+    /// by scanning backwards from where it ends.
+    pub fn new_reverse(ast: ~parse::Ast) -> Program {
+        let (prog, _) = Program::new(reverse(ast));
+        prog
+    }
+
+    /// Compiles several regular expressions (given as ASTs) into a single
+    /// program. Each expression gets its own sequence of instructions that
+    /// starts with `Save(0)` and ends with `Save(1)` followed by `Match`.
+    ///
+    /// Along with the program, the index of the first instruction of each
+    /// expression is returned, in addition to the capture names of each
+    /// expression.
+    ///
+    /// The program returned has no literal prefix or prefilter, since
+    /// there's no single entry point.
+    pub fn new_set(asts: Vec<~parse::Ast>)
+                  -> (Program, Vec<InstIdx>, Vec<~[Option<~str>]>) {
+        let mut c = Compiler {
+            insts: Vec::with_capacity(100 * asts.len()),
+            names: Vec::with_capacity(10),
+            backward: false,
+            // Sets with lookaround, backreferences or `\K` are rejected.
+            cut: false,
+            classes: 0,
+        };
+        let mut starts = Vec::with_capacity(asts.len());
+        let mut names = Vec::with_capacity(asts.len());
+        for ast in asts.move_iter() {
+            starts.push(c.insts.len());
+            c.insts.push(Save(0));
+            c.compile(factor(ast));
+            c.insts.push(Save(1));
+            c.insts.push(Match);
+
+            // Capture indices restart for each expression, so the names
+            // must be collected separately.
+            names.push(c.names.as_slice().into_owned());
+            c.names.clear();
+        }
+        let search_start = uses_search_start(c.insts.as_slice());
+        let segments = uses_segments(c.insts.as_slice());
+        let lookaround = uses_lookaround(c.insts.as_slice());
+        // The parser only accepts bounded lookbehind.
+        let behind = max_behind(c.insts.as_slice()).unwrap();
+        let backrefs = uses_backrefs(c.insts.as_slice());
+        let keep = uses_keep(c.insts.as_slice(), starts.as_slice());
+        let heap = heap_size(&c.insts, c.classes, "", &vec!());
         let prog = Program {
             insts: c.insts,
-            prefix: pre.into_owned(),
+            prefix: ~"",
+            prefilter: Prefilter::none(),
+            onepass: None,
+            shiftor: None,
+            search_start: search_start,
+            segments: segments,
+            lookaround: lookaround,
+            behind: behind,
+            backrefs: backrefs,
+            keep: keep,
+            branches: vec!(),
+            heap: heap,
+            analyses_heap: 0,
         };
-        (prog, names)
+        (prog, starts, names)
+    }
+
+    /// Returns true if and only if the program can only be run by the
+    /// backtracking engine, because it uses lookaround, backreferences or
+    /// `\K`.
+    pub fn backtrack_only(&self) -> bool {
+        self.lookaround || self.backrefs || self.keep
+    }
+
+    /// Returns true if every match starts at the beginning of the text,
+    /// because the program starts with `^` or `\A` (but not `\G`) outside
+    /// of multi-line mode.
+    pub fn anchored_start(&self) -> bool {
+        match self.insts.as_slice().get(1) {
+            Some(&EmptyBegin(flags)) => flags & (FLAG_MULTI | FLAG_SEARCH) == 0,
+            _ => false,
+        }
+    }
+
+    /// Returns the number of bytes that the instructions of this program
+    /// (with their character classes), its literal prefix and its branches
+    /// occupy on the heap. The prefilter and the analyses are counted on
+    /// their own (see `analyses_size`).
+    pub fn heap_size(&self) -> uint {
+        self.heap
+    }
+
+    /// Returns the number of bytes that the one-pass analysis and the
+    /// bit-parallel matcher of this program occupy on the heap.
+    pub fn analyses_size(&self) -> uint {
+        self.analyses_heap
     }
 
     /// Returns the total number of capture groups in the regular expression.
@@ -132,11 +417,136 @@ impl Program {
         // There's exactly 2 Save slots for every capture.
         n / 2
     }
+
+    /// Returns a listing of the instructions of the program, one per line
+    /// with its index. The format is meant for debugging and may change.
+    pub fn dump(&self) -> ~str {
+        let mut out = StrBuf::new();
+        for (pc, inst) in self.insts.iter().enumerate() {
+            out.push_str(format!("{:04} {}\n", pc, describe(inst)).as_slice());
+        }
+        out.into_owned()
+    }
+}
+
+// The most ranges of a class that `describe` lists.
+static DESCRIBE_RANGES: uint = 8;
+
+/// Describes an instruction in a human readable form, like `split 3, 5` or
+/// `char 'a' (i)`. The format is meant for debugging and may change.
+pub fn describe(inst: &Inst) -> ~str {
+    let (name, flags) = match *inst {
+        Match => return ~"match",
+        OneChar(c, flags) => {
+            (format!("char '{}'", escape(c)), flags & FLAG_NOCASE)
+        }
+        CharClass(ref set, flags) => {
+            let ranges = &set.ranges;
+            let mut class = StrBuf::from_str("class [");
+            if flags & FLAG_NEGATED > 0 {
+                class.push_char('^');
+            }
+            for &(start, end) in ranges.iter().take(DESCRIBE_RANGES) {
+                class.push_str(escape(start).as_slice());
+                if end != start {
+                    class.push_char('-');
+                    class.push_str(escape(end).as_slice());
+                }
+            }
+            if ranges.len() > DESCRIBE_RANGES {
+                class.push_str(format!("... ({} ranges)",
+                                       ranges.len()).as_slice());
+            }
+            class.push_char(']');
+            (class.into_owned(), flags & FLAG_NOCASE)
+        }
+        Any(flags) => (~"any", flags & FLAG_DOTNL),
+        EmptyBegin(flags) if flags & FLAG_SEARCH > 0 => {
+            (~"search start", FLAG_EMPTY)
+        }
+        // Only reverse programs have this one.
+        EmptyBegin(flags) if flags & FLAG_FINALNL > 0 => {
+            (~"begin or after a final new line", FLAG_EMPTY)
+        }
+        EmptyBegin(flags) => (~"begin", flags & FLAG_MULTI),
+        EmptyEnd(flags) if flags & FLAG_FINALNL > 0 => {
+            (~"end or before a final new line", FLAG_EMPTY)
+        }
+        EmptyEnd(flags) => (~"end", flags & FLAG_MULTI),
+        EmptyWordBoundary(flags) => {
+            let not = if flags & FLAG_NEGATED > 0 { "not " } else { "" };
+            let flags = flags & (FLAG_ASCII | FLAG_UWORD);
+            (format!("{}word boundary", not), flags)
+        }
+        EmptySegmentBoundary(seg, flags) => {
+            let not = if flags & FLAG_NEGATED > 0 { "not " } else { "" };
+            let seg = match seg { Grapheme => "grapheme", Word => "word" };
+            return format!("{}{} segment boundary", not, seg)
+        }
+        EmptyLook(to, flags) => {
+            let not = if flags & FLAG_NEGATED > 0 { "negative " } else { "" };
+            let dir = if flags & FLAG_BEHIND > 0 { "behind" } else { "ahead" };
+            return format!("{}look{} then {}", not, dir, to)
+        }
+        Cut(to) => return format!("atomic then {}", to),
+        LookMatch => return ~"look match",
+        GroupRef(group, flags) => {
+            (format!("backref {}", group), flags & (FLAG_NOCASE | FLAG_ASCII))
+        }
+        Save(slot) => return format!("save {}", slot),
+        Jump(to) => return format!("jump {}", to),
+        Split(x, y) => return format!("split {}, {}", x, y),
+    };
+    let mut letters = StrBuf::new();
+    for &(flag, letter) in [(FLAG_NOCASE, 'i'), (FLAG_MULTI, 'm'),
+                            (FLAG_DOTNL, 's'), (FLAG_ASCII, 'a'),
+                            (FLAG_UWORD, 'w')].iter() {
+        if flags & flag > 0 {
+            letters.push_char(letter);
+        }
+    }
+    if letters.len() == 0 {
+        name
+    } else {
+        format!("{} ({})", name, letters)
+    }
+}
+
+fn escape(c: char) -> ~str {
+    str::from_char(c).escape_default()
+}
+
+// Reverses an expression. Capture groups are kept (so that the programs have
+// the same number of `Save` slots), but they don't mean anything when their
+// locations are recorded in reverse.
+fn reverse(ast: ~parse::Ast) -> ~parse::Ast {
+    match ast {
+        ~Begin(flags) => ~End(flags),
+        ~End(flags) => ~Begin(flags),
+        ~Capture(cap, name, x) => ~Capture(cap, name, reverse(x)),
+        ~Atomic(x) => ~Atomic(reverse(x)),
+        ~Cat(xs) => {
+            let mut xs: Vec<~parse::Ast> = xs.move_iter().map(reverse).collect();
+            xs.as_mut_slice().reverse();
+            ~Cat(xs)
+        }
+        ~Alt(x, y) => ~Alt(reverse(x), reverse(y)),
+        ~Rep(x, op, g) => ~Rep(reverse(x), op, g),
+        ast => ast,
+    }
 }
 
 struct Compiler<'r> {
     insts: Vec<Inst>,
     names: Vec<Option<~str>>,
+    // Whether a lookbehind is being compiled, whose instructions match
+    // from right to left.
+    backward: bool,
+    // Whether atomic groups are compiled to `Cut` instructions.
+    cut: bool,
+    // The number of bytes that the tables of the character classes compiled
+    // so far occupy on the heap.
+    classes: uint,
 }
 
 // The compiler implemented here is extremely simple. Most of the complexity
@@ -149,11 +559,39 @@ impl<'r> Compiler<'r> {
             ~Nothing => {},
             ~Literal(c, flags) => self.push(OneChar(c, flags)),
             ~Dot(nl) => self.push(Any(nl)),
-            ~Class(ranges, flags) =>
-                self.push(CharClass(ranges, flags)),
+            ~Class(ranges, flags) => {
+                let set = CharSet::new(ranges, flags);
+                self.classes += set.heap_size();
+                self.push(CharClass(set, flags))
+            }
             ~Begin(flags) => self.push(EmptyBegin(flags)),
             ~End(flags) => self.push(EmptyEnd(flags)),
             ~WordBoundary(flags) => self.push(EmptyWordBoundary(flags)),
+            ~SegmentBoundary(seg, flags) => {
+                self.push(EmptySegmentBoundary(seg, flags))
+            }
+            ~Lookaround(x, flags) => {
+                let look = self.empty_look(flags);
+                let backward = self.backward;
+                self.backward = flags & FLAG_BEHIND > 0;
+                self.compile(x);
+                self.backward = backward;
+                self.push(LookMatch);
+                let next = self.insts.len();
+                self.set_look(look, next);
+            }
+            ~Backref(cap, flags) => self.push(GroupRef(cap, flags)),
+            ~Keep => self.push(Save(0)),
+            ~Atomic(x) => {
+                if !self.cut {
+                    return self.compile(x)
+                }
+                let cut = self.empty_cut();
+                self.compile(x);
+                self.push(LookMatch);
+                let next = self.insts.len();
+                self.set_cut(cut, next);
+            }
             ~Capture(cap, name, x) => {
                 let len = self.names.len();
                 if cap >= len {
@@ -161,13 +599,26 @@ impl<'r> Compiler<'r> {
                 }
                 *self.names.get_mut(cap) = name;
 
-                self.push(Save(2 * cap));
+                // Matching right to left, the end is found first.
+                let (first, last) =
+                    if self.backward {
+                        (2 * cap + 1, 2 * cap)
+                    } else {
+                        (2 * cap, 2 * cap + 1)
+                    };
+                self.push(Save(first));
                 self.compile(x);
-                self.push(Save(2 * cap + 1));
+                self.push(Save(last));
             }
             ~Cat(xs) => {
-                for x in xs.move_iter() {
-                    self.compile(x)
+                if self.backward {
+                    for x in xs.move_iter().rev() {
+                        self.compile(x)
+                    }
+                } else {
+                    for x in xs.move_iter() {
+                        self.compile(x)
+                    }
                 }
             }
             ~Alt(x, y) => {
@@ -224,6 +675,80 @@ impl<'r> Compiler<'r> {
         }
     }
 
+    // Compiles the alternates of a top-level alternation, in order, each
+    // followed by a `Match` of its own, and returns the alternate that each
+    // `Match` ends. A run of alternates that are literals is compiled as a
+    // trie (see `factor`), whose ends each have their own `Match` too.
+    fn compile_alternates(&mut self, alts: Vec<~parse::Ast>)
+                         -> Vec<(InstIdx, uint)> {
+        let mut items = vec!();
+        let mut run = vec!();
+        for (i, alt) in alts.move_iter().enumerate() {
+            if literal_chars(&*alt).is_some() {
+                run.push((i, alt));
+            } else {
+                flush_tagged_run(&mut items, mem::replace(&mut run, vec!()));
+                items.push(TopAlternate(i, alt));
+            }
+        }
+        flush_tagged_run(&mut items, run);
+
+        let mut branches = vec!();
+        let n = items.len();
+        for (k, item) in items.move_iter().enumerate() {
+            let split = if k + 1 < n { Some(self.empty_split()) } else { None };
+            let j1 = self.insts.len();
+            match item {
+                TopAlternate(i, alt) => {
+                    self.compile(factor(alt));
+                    self.push_match(i, &mut branches);
+                }
+                TopTrie(trie) => self.compile_trie(trie, &mut branches),
+            }
+            match split {
+                None => {}
+                Some(split) => {
+                    let j2 = self.insts.len();
+                    self.set_split(split, j1, j2);
+                }
+            }
+        }
+        branches
+    }
+
+    // Compiles a trie of the alternates of a top-level alternation, whose
+    // branches are in priority order like those of `Trie::into_ast`.
+    fn compile_trie(&mut self, trie: Trie,
+                    branches: &mut Vec<(InstIdx, uint)>) {
+        let Trie { items } = trie;
+        let n = items.len();
+        for (k, item) in items.move_iter().enumerate() {
+            let split = if k + 1 < n { Some(self.empty_split()) } else { None };
+            let j1 = self.insts.len();
+            match item {
+                TrieEnd(alt) => self.push_match(alt, branches),
+                TrieBranch(c, flags, next) => {
+                    self.push(OneChar(c, flags));
+                    self.compile_trie(next, branches);
+                }
+            }
+            match split {
+                None => {}
+                Some(split) => {
+                    let j2 = self.insts.len();
+                    self.set_split(split, j1, j2);
+                }
+            }
+        }
+    }
+
+    // Ends the top-level alternate given with a `Match` of its own.
+    fn push_match(&mut self, alt: uint, branches: &mut Vec<(InstIdx, uint)>) {
+        self.push(Save(1));
+        branches.push((self.insts.len(), alt));
+        self.push(Match);
+    }
+
     /// Appends the given instruction to the program.
     #[inline]
     fn push(&mut self, x: Inst) {
@@ -271,4 +796,431 @@ impl<'r> Compiler<'r> {
             _ => fail!("BUG: Invalid jump index."),
         }
     }
+
+    /// Appends an *empty* `EmptyLook` instruction with the flags given to
+    /// the program and returns the index of that instruction.
+    #[inline]
+    fn empty_look(&mut self, flags: Flags) -> InstIdx {
+        self.insts.push(EmptyLook(0, flags));
+        self.insts.len() - 1
+    }
+
+    /// Sets the location that an `EmptyLook` instruction at index `i`
+    /// continues at to `pc`.
+    /// If the instruction at index `i` isn't an `EmptyLook` instruction, then
+    /// `fail!` is called.
+    #[inline]
+    fn set_look(&mut self, i: InstIdx, pc: InstIdx) {
+        let look = self.insts.get_mut(i);
+        match *look {
+            EmptyLook(_, flags) => *look = EmptyLook(pc, flags),
+            _ => fail!("BUG: Invalid lookahead index."),
+        }
+    }
+
+    /// Appends an *empty* `Cut` instruction to the program and returns the
+    /// index of that instruction.
+    #[inline]
+    fn empty_cut(&mut self) -> InstIdx {
+        self.insts.push(Cut(0));
+        self.insts.len() - 1
+    }
+
+    /// Sets the location that a `Cut` instruction at index `i` continues at
+    /// to `pc`.
+    /// If the instruction at index `i` isn't a `Cut` instruction, then
+    /// `fail!` is called.
+    #[inline]
+    fn set_cut(&mut self, i: InstIdx, pc: InstIdx) {
+        let cut = self.insts.get_mut(i);
+        match *cut {
+            Cut(_) => *cut = Cut(pc),
+            _ => fail!("BUG: Invalid atomic group index."),
+        }
+    }
+}
+
+// The fewest alternates of literals in a row that are compiled as a trie.
+static TRIE_MIN_ALTERNATES: uint = 3;
+
+// Rewrites every alternation of (case sensitive) literals, like
+// `foo|foobar|food`, so that the alternates share their common prefixes as
+// they would in a trie: `foo(?:|bar|d)`. The program then examines each
+// character once, rather than once for every alternate, and it's smaller.
+//
+// Two alternates that differ at some position never match at the same
+// start, so their order doesn't matter. But when one alternate is a prefix
+// of another, the order of the two decides which of them matches (when what
+// follows the alternation can match after either). So an alternate only
+// joins the branch of the trie that shares its next character if no earlier
+// alternate ends between that branch and the end of the node (see
+// `Trie::insert`), which keeps every such pair in its original order.
+//
+// Lookaround is left alone, since a lookbehind is matched backwards.
+fn factor(ast: ~parse::Ast) -> ~parse::Ast {
+    match ast {
+        ~Alt(x, y) => factor_alt(x, y),
+        ~Cat(xs) => ~Cat(xs.move_iter().map(factor).collect()),
+        ~Capture(cap, name, x) => ~Capture(cap, name, factor(x)),
+        ~Atomic(x) => ~Atomic(factor(x)),
+        ~Rep(x, op, g) => ~Rep(factor(x), op, g),
+        ast => ast,
+    }
+}
+
+fn factor_alt(x: ~parse::Ast, y: ~parse::Ast) -> ~parse::Ast {
+    // Alternations nest to the right, and there may be thousands of
+    // alternates, so they're collected without recursion.
+    let mut alts = vec!(x);
+    let mut rest = y;
+    loop {
+        match rest {
+            ~Alt(x, y) => {
+                alts.push(x);
+                rest = y;
+            }
+            last => {
+                alts.push(last);
+                break
+            }
+        }
+    }
+
+    let mut factored = vec!();
+    let mut run = vec!();
+    for alt in alts.move_iter() {
+        if literal_chars(&*alt).is_some() {
+            run.push(alt);
+        } else {
+            flush_run(&mut factored, mem::replace(&mut run, vec!()));
+            factored.push(factor(alt));
+        }
+    }
+    flush_run(&mut factored, run);
+    let last = factored.pop().unwrap();
+    factored.move_iter().rev().fold(last, |alt, x| ~Alt(x, alt))
+}
+
+// Moves a run of alternates that are literals to `factored`, as a trie if
+// there are enough of them.
+fn flush_run(factored: &mut Vec<~parse::Ast>, run: Vec<~parse::Ast>) {
+    if run.len() < TRIE_MIN_ALTERNATES {
+        factored.push_all_move(run);
+        return
+    }
+    let mut trie = Trie { items: vec!() };
+    for alt in run.iter() {
+        let chars = literal_chars(&**alt).unwrap();
+        // Only the ends of a top-level trie tell apart their alternates.
+        trie.insert(chars.as_slice(), 0);
+    }
+    factored.push(trie.into_ast());
+}
+
+// The items of a top-level alternation, as they're compiled: an alternate,
+// or a trie of alternates that are literals.
+enum TopItem {
+    TopAlternate(uint, ~parse::Ast),
+    TopTrie(Trie),
+}
+
+// Moves a run of top-level alternates that are literals, with their indices,
+// to `items`, as a trie if there are enough of them (like `flush_run`).
+fn flush_tagged_run(items: &mut Vec<TopItem>, run: Vec<(uint, ~parse::Ast)>) {
+    if run.len() < TRIE_MIN_ALTERNATES {
+        for (i, alt) in run.move_iter() {
+            items.push(TopAlternate(i, alt));
+        }
+        return
+    }
+    let mut trie = Trie { items: vec!() };
+    for &(i, ref alt) in run.iter() {
+        let chars = literal_chars(&**alt).unwrap();
+        trie.insert(chars.as_slice(), i);
+    }
+    items.push(TopTrie(trie));
+}
+
+// Returns the characters of a case sensitive literal (which may be empty),
+// along with their flags.
+fn literal_chars(ast: &parse::Ast) -> Option<Vec<(char, Flags)>> {
+    match *ast {
+        Nothing => Some(vec!()),
+        Literal(c, flags) if flags & FLAG_NOCASE == 0 => {
+            Some(vec!((c, flags)))
+        }
+        Cat(ref xs) => {
+            let mut chars = Vec::with_capacity(xs.len());
+            for x in xs.iter() {
+                match **x {
+                    Literal(c, flags) if flags & FLAG_NOCASE == 0 => {
+                        chars.push((c, flags))
+                    }
+                    _ => return None,
+                }
+            }
+            Some(chars)
+        }
+        _ => None,
+    }
+}
+
+// A node of a trie of literals. Its items are in priority order: an end
+// for the literal that ends at this node (with the index of its alternate),
+// and a branch for each next character of the others.
+struct Trie {
+    items: Vec<TrieItem>,
+}
+
+enum TrieItem {
+    TrieEnd(uint),
+    TrieBranch(char, Flags, Trie),
+}
+
+impl TrieItem {
+    fn is_end(&self) -> bool {
+        match *self {
+            TrieEnd(_) => true,
+            TrieBranch(_, _, _) => false,
+        }
+    }
+}
+
+impl Trie {
+    // Adds the literal of the alternate given, which has a lower priority
+    // than every literal added before it.
+    fn insert(&mut self, chars: &[(char, Flags)], alt: uint) {
+        if chars.len() == 0 {
+            // A literal that's here already always matches instead.
+            if !self.items.iter().any(|item| item.is_end()) {
+                self.items.push(TrieEnd(alt));
+            }
+            return
+        }
+        let (c, flags) = chars[0];
+        let after_end = match self.items.iter().rposition(|i| i.is_end()) {
+            None => 0,
+            Some(i) => i + 1,
+        };
+        for item in self.items.mut_iter().skip(after_end) {
+            match *item {
+                TrieBranch(bc, _, ref mut next) if bc == c => {
+                    next.insert(chars.slice_from(1), alt);
+                    return
+                }
+                _ => {}
+            }
+        }
+        let mut next = Trie { items: vec!() };
+        next.insert(chars.slice_from(1), alt);
+        self.items.push(TrieBranch(c, flags, next));
+    }
+
+    fn into_ast(self) -> ~parse::Ast {
+        let Trie { items } = self;
+        let mut alts: Vec<~parse::Ast> = items.move_iter().map(|item| {
+            match item {
+                TrieEnd(_) => ~Nothing,
+                TrieBranch(c, flags, next) => branch_ast(c, flags, next),
+            }
+        }).collect();
+        let last = alts.pop().unwrap();
+        alts.move_iter().rev().fold(last, |alt, x| ~Alt(x, alt))
+    }
+}
+
+// Returns the expression for the branch of a trie that starts with the
+// character given.
+fn branch_ast(c: char, flags: Flags, mut next: Trie) -> ~parse::Ast {
+    let mut cat = vec!(~Literal(c, flags));
+    // Characters that only one literal continues with are just
+    // concatenated.
+    while next.items.len() == 1 {
+        match next.items.pop().unwrap() {
+            TrieEnd(_) => break,
+            TrieBranch(c, flags, after) => {
+                cat.push(~Literal(c, flags));
+                next = after;
+            }
+        }
+    }
+    if next.items.len() > 0 {
+        cat.push(next.into_ast());
+    }
+    if cat.len() == 1 { cat.pop().unwrap() } else { ~Cat(cat) }
+}
+
+// Returns true if the expression uses lookaround, backreferences or `\K`, so
+// that its program is run by the backtracker, whose atomic groups cut.
+fn backtracks(ast: &parse::Ast) -> bool {
+    match *ast {
+        Lookaround(_, _) | Backref(_, _) | Keep => true,
+        Atomic(ref x) | Capture(_, _, ref x) | Rep(ref x, _, _) => {
+            backtracks(&**x)
+        }
+        Cat(ref xs) => xs.iter().any(|x| backtracks(&**x)),
+        Alt(ref x, ref y) => backtracks(&**x) || backtracks(&**y),
+        _ => false,
+    }
+}
+
+// Returns true if any of the instructions given is `\G`.
+fn uses_search_start(insts: &[Inst]) -> bool {
+    insts.iter().any(|inst| {
+        match *inst {
+            EmptyBegin(flags) => flags & FLAG_SEARCH > 0,
+            _ => false,
+        }
+    })
+}
+
+// Returns true if any of the instructions given is `\b{g}` or `\b{wb}` (or
+// their negations).
+fn uses_segments(insts: &[Inst]) -> bool {
+    insts.iter().any(|inst| {
+        match *inst {
+            EmptySegmentBoundary(_, _) => true,
+            _ => false,
+        }
+    })
+}
+
+// Returns true if any of the instructions given is a backreference.
+fn uses_backrefs(insts: &[Inst]) -> bool {
+    insts.iter().any(|inst| {
+        match *inst {
+            GroupRef(_, _) => true,
+            _ => false,
+        }
+    })
+}
+
+// Returns true if any of the instructions given is a lookahead or a
+// lookbehind.
+fn uses_lookaround(insts: &[Inst]) -> bool {
+    insts.iter().any(|inst| {
+        match *inst {
+            EmptyLook(_, _) => true,
+            _ => false,
+        }
+    })
+}
+
+// Returns true if any of the instructions given saves the start of the match
+// again (for `\K`), other than the first instruction of each expression.
+fn uses_keep(insts: &[Inst], starts: &[InstIdx]) -> bool {
+    insts.iter().enumerate().any(|(pc, inst)| {
+        match *inst {
+            Save(0) => !starts.contains(&pc),
+            _ => false,
+        }
+    })
+}
+
+/// Returns the most bytes before a position that the lookbehinds of the
+/// instructions given can look at: the sum of the most that each of them
+/// can consume (even when nested), since a lookbehind sees the text before
+/// the one that contains it. `None` is returned if a lookbehind can consume
+/// any number of characters, because it loops or has a backreference.
+pub fn max_behind(insts: &[Inst]) -> Option<uint> {
+    let mut widths = Vec::from_elem(insts.len(), WidthUnknown);
+    let mut behind = 0;
+    for (pc, inst) in insts.iter().enumerate() {
+        match *inst {
+            EmptyLook(_, flags) if flags & FLAG_BEHIND > 0 => {
+                match width(insts, pc + 1, &mut widths) {
+                    None => return None,
+                    Some(w) => behind += w,
+                }
+            }
+            _ => {}
+        }
+    }
+    Some(behind)
+}
+
+// The most bytes consumed from an instruction to the `LookMatch` that ends
+// the lookaround (or atomic group) it belongs to.
+#[deriving(Clone)]
+enum Width {
+    WidthUnknown,
+    // Being found, by the instructions that it's the most of.
+    WidthPending,
+    WidthKnown(uint),
+}
+
+// Returns the most bytes consumed from the instruction `root` to the
+// `LookMatch` that ends its lookaround, or `None` if it's unbounded. The
+// widths found (which don't depend on where they're found from) are kept in
+// `widths`. This doesn't recurse, since the paths can be as long as the
+// program.
+fn width(insts: &[Inst], root: InstIdx, widths: &mut Vec<Width>)
+        -> Option<uint> {
+    // Each instruction, with how many bytes it consumes, the instructions
+    // it's followed by and whether it's followed by both of them in a row
+    // (as a `Cut` continues after its atomic group) rather than either.
+    let next = |pc: InstIdx| -> Option<(uint, Vec<InstIdx>, bool)> {
+        Some(match *insts.get(pc).unwrap() {
+            Match | LookMatch => (0, vec!(), false),
+            OneChar(c, flags) => {
+                // A case insensitive character could match one with a
+                // longer encoding.
+                let w = if flags & FLAG_NOCASE > 0 {
+                    4
+                } else {
+                    c.len_utf8_bytes()
+                };
+                (w, vec!(pc + 1), false)
+            }
+            CharClass(_, _) | Any(_) => (4, vec!(pc + 1), false),
+            EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_)
+            | EmptySegmentBoundary(_, _) | Save(_) => (0, vec!(pc + 1), false),
+            // What the group matches isn't known here.
+            GroupRef(_, _) => return None,
+            EmptyLook(to, _) | Jump(to) => (0, vec!(to), false),
+            Split(x, y) => (0, vec!(x, y), false),
+            Cut(to) => (0, vec!(pc + 1, to), true),
+        })
+    };
+    let mut stack = vec!(root);
+    while stack.len() > 0 {
+        let pc = *stack.last().unwrap();
+        match *widths.get(pc) {
+            WidthKnown(_) => {
+                stack.pop();
+            }
+            WidthUnknown => {
+                *widths.get_mut(pc) = WidthPending;
+                let (_, succs, _) = match next(pc) {
+                    None => return None,
+                    Some(next) => next,
+                };
+                for &to in succs.iter() {
+                    match *widths.get(to) {
+                        WidthUnknown => stack.push(to),
+                        // It loops.
+                        WidthPending => return None,
+                        WidthKnown(_) => {}
+                    }
+                }
+            }
+            WidthPending => {
+                stack.pop();
+                let (w, succs, both) = next(pc).unwrap();
+                let mut rest = 0;
+                for &to in succs.iter() {
+                    let n = match *widths.get(to) {
+                        WidthKnown(n) => n,
+                        _ => unreachable!(),
+                    };
+                    rest = if both { rest + n } else { cmp::max(rest, n) };
+                }
+                *widths.get_mut(pc) = WidthKnown(w + rest);
+            }
+        }
+    }
+    match *widths.get(root) {
+        WidthKnown(n) => Some(n),
+        _ => unreachable!(),
+    }
 }
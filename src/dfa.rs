@@ -0,0 +1,2022 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module implements a lazy DFA. It answers the "does this match" and
+// "where does the match end" questions without tracking any capture groups.
+// States are built on the fly from sets of NFA states and cached, so that
+// each state is only ever computed once.
+//
+// A DFA state is an *ordered* list of instructions. The order corresponds to
+// thread priority in the NFA simulation in vm.rs, which is what lets the DFA
+// implement leftmost-first semantics: when a `Match` instruction is reached,
+// every instruction after it in the list is dropped.
+//
+// Zero-width assertions are handled by delaying the epsilon closure of a
+// state until the *next* character is known. That is, a state stores the
+// instructions reached just after consuming a character (the "kernel") along
+// with a few bits describing the character consumed. Computing a transition
+// on a character `c` first computes the closure of the kernel (where `c` is
+// the lookahead for `$` and `\b`) and then steps over `c`. A consequence is
+// that a match ending at position `i` is only discovered when computing the
+// transition on the character *at* `i` (or on the end of the input).
+//
+// `\Z` also looks at whether the new line it precedes ends the input, so a
+// new line at the last byte has a transition of its own. (Scanning
+// backwards, the state entered by consuming it has a flag of its own.)
+//
+// The DFA can't report where a match starts. But it can report a lower bound
+// on where it starts: the last position at which no NFA threads were alive.
+// The start of the match is then found by running a DFA for the *reverse*
+// of the regex (see `Program::new_reverse`) backwards from the end of the
+// match, but no further than the lower bound. It does an anchored search for
+// the longest match, which ends (in reverse) at the leftmost position from
+// which the regex matches up to the end of the forward match. Since no match
+// can start before the leftmost-first match, that's where it starts.
+//
+// When scanning backwards, "previous" and "next" are swapped. This is why
+// the reverse program flips `^` and `$`: the flags of a state in the reverse
+// DFA describe the character *after* the current position.
+//
+// If there's no reverse program (or it exceeds its memory budget), the NFA
+// is run on the (typically short) span between the lower bound and the end
+// of the match instead.
+//
+// When the program has a prefilter (see literals.rs), the DFA uses it to skip
+// ahead whenever no threads are alive.
+//
+// Transitions are only cached for ASCII characters and the end of the input.
+// Transitions on other characters are computed every time they're needed,
+// although the resulting states are still cached. Either way, a search that
+// only visits states that are cached allocates no memory, so that checking
+// for a match (or finding one) is allocation free in the steady state.
+//
+// If the states cached exceed a memory budget, the search is abandoned, the
+// cache is cleared and the caller is expected to fall back to the NFA.
+//
+// A search has a DFA to itself while it runs, so that searches of the same
+// regex from many tasks don't wait for each other. DFAs are kept in a pool:
+// a search takes one out (or creates one, if every DFA is in use) and puts it
+// back when it's done, which only locks the pool for as long as it takes to
+// move the DFA. The pool is split into shards, each with a lock of its own
+// and room for one DFA. Every task has a home shard, which its searches look
+// at first before trying the others, so that tasks searching at once rarely
+// contend for the same lock (and a task usually gets back the DFA it used
+// last, with the states its searches need). A DFA that's put back when its
+// shard is full is dropped, which bounds the memory a regex retains to one
+// DFA (of at most the size limit) per shard.
+//
+// A search may also have a budget of steps (see `vm::Budget`). The DFA takes
+// a step for every character it consumes and for every instruction of a
+// state whose transition it computes. Unlike running out of memory, running
+// out of steps abandons the search for good.
+//
+// For a regex that's searched so often that no cost up front is too high,
+// every state of the DFA can be computed ahead of time (see `compile_full`).
+// Its transitions are then looked up in a table with a row per state and a
+// column per class of characters, where the characters of a class are the
+// ones no instruction (or assertion) of the program tells apart. Most
+// programs only tell apart a few dozen classes, which keeps the tables small
+// even though they cover every character, not just ASCII. A search with the
+// tables never computes a state and never gives up. The flags of a state
+// only keep the bits that the program's assertions look at, since the other
+// bits would only multiply the states.
+//
+// Tiny expressions are executed by the bit-parallel engine in shiftor.rs
+// instead of the DFA. It's disabled along with the DFA.
+//
+// Whenever the NFA is needed (e.g., to find capture groups), the bounded
+// backtracker in backtrack.rs is used instead if the text to search is
+// short enough. Programs with lookahead are always run by the backtracker,
+// since no other engine can evaluate it.
+
+use collections::HashMap;
+use std::char;
+use std::fmt;
+use std::mem;
+use std::local_data;
+use std::sync::atomics::{AtomicUint, INIT_ATOMIC_UINT, Relaxed};
+use std::uint;
+use sync::{Arc, Mutex};
+
+use backtrack;
+use compile::{
+    Program, Inst,
+    Match, OneChar, CharClass, Any, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    EmptySegmentBoundary, EmptyLook, Cut, LookMatch, GroupRef,
+    Save, Jump, Split,
+};
+use parse::{FLAG_MULTI, FLAG_NEGATED, FLAG_ASCII, FLAG_UWORD, FLAG_FINALNL};
+use parse::FLAG_NOCASE;
+use parse::unicode::{PERLW, UNICODE_WORD};
+use posix;
+use prefilter::Prefilter;
+use shiftor::ShiftOr;
+use stats::{Counters, EngineLiterals, EngineFullDfa, EngineShiftOr, EngineDfa};
+use stats::{EngineNfa, EngineBacktrack, EnginePosix};
+use vm;
+use vm::{CaptureLocs, MatchKind, Exists, Location, Submatches};
+use vm::{Budget, Cancel, StepLimitExceeded};
+
+/// The default number of bytes that the states of a lazy DFA may occupy
+/// before the DFA gives up and defers to the NFA.
+pub static DEFAULT_SIZE_LIMIT: uint = 2 * (1 << 20);
+
+// The number of cached transitions per state: one for each ASCII character,
+// one for the end of the input and one for a new line that ends the input.
+static NUM_TRANS: uint = 130;
+static TRANS_EOF: uint = 128;
+static TRANS_FINALNL: uint = 129;
+static UNKNOWN: uint = uint::MAX;
+
+// The index of the dead state. Once entered, it is never left.
+static DEAD: uint = 0;
+
+// The number of shards in a pool of DFAs, which is the most DFAs that it
+// keeps.
+static POOL_SHARDS: uint = 8;
+
+// The number of tasks that have been given a home shard, which gives the
+// next one its home.
+static mut HOMES: AtomicUint = INIT_ATOMIC_UINT;
+
+// The home shard of the current task, in every pool.
+local_data_key!(HOME_SHARD: uint)
+
+// The number of characters consumed between checks of the step budget.
+static STEP_CHUNK: uint = 4096;
+
+// Bits describing the character preceding a position, which is all that is
+// needed to evaluate a zero-width assertion at that position (given the
+// following character).
+static PREV_BEGIN: u8 = 1 << 0;
+static PREV_NL:    u8 = 1 << 1;
+static PREV_WORD:  u8 = 1 << 2;
+static PREV_AWORD: u8 = 1 << 3; // an ASCII word character
+static PREV_UWORD: u8 = 1 << 4; // a word character for the `w` flag
+static PREV_FINALNL: u8 = 1 << 5; // a new line that ends the input
+static NUM_FLAGS: uint = 1 << 6;
+static ALL_FLAGS: u8 = (NUM_FLAGS - 1) as u8;
+
+// No character at or above this one has an uppercase that's a different
+// character, so case insensitive instructions treat them like case
+// sensitive ones do.
+static MAX_CASED: u32 = 0x20000;
+
+static ASCII_WORD: &'static [(char, char)] = &[
+    ('0', '9'), ('A', 'Z'), ('_', '_'), ('a', 'z'),
+];
+
+/// The result of running a lazy DFA.
+pub enum DfaResult {
+    /// There is no match.
+    NoMatch,
+    /// There is a match. The first position is a lower bound on where the
+    /// leftmost-first match starts and the second position is where it ends.
+    Matched(uint, uint),
+    /// The DFA exceeded its memory budget before completing the search.
+    GaveUp,
+    /// The search took more steps than its budget allowed.
+    OutOfSteps,
+}
+
+/// DfaError describes why a DFA couldn't be compiled ahead of time.
+#[deriving(Clone)]
+pub struct DfaError {
+    /// A message describing the error.
+    pub msg: ~str,
+    /// What went wrong.
+    pub kind: DfaErrorKind,
+}
+
+/// DfaErrorKind tells a DFA that's too large apart from a regex whose
+/// searches can't use a DFA at all.
+#[deriving(Clone, Eq, Show)]
+pub enum DfaErrorKind {
+    /// The tables of the DFA take more bytes than the limit allows.
+    DfaTooLarge,
+    /// Searches with the regex never use a DFA (e.g., because it has
+    /// lookaround or backreferences, or because it was compiled with the
+    /// `regex!` macro).
+    DfaUnsupported,
+}
+
+impl fmt::Show for DfaError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "Regex DFA error: {}", self.msg)
+    }
+}
+
+/// DfaCache is the lazy DFA attached to a compiled regular expression.
+///
+/// It keeps a pool of DFAs so that a `Regex` can be searched from many tasks
+/// at the same time.
+pub struct DfaCache {
+    dfa: DfaPool,
+    // DFAs for the reverse program, which find where matches start.
+    rdfa: DfaPool,
+    rprog: Option<Arc<Program>>,
+    // The DFAs compiled ahead of time, if any.
+    full: Option<Arc<FullDfa>>,
+    limit: uint,
+    // The size of the largest visited set the backtracker may use.
+    backtrack_limit: uint,
+    // The number of steps a search may take.
+    step_limit: Option<uint>,
+    // Whether searches find leftmost-longest matches.
+    longest: bool,
+    // Whether the capture groups of leftmost-longest matches follow the
+    // POSIX rules.
+    posix: bool,
+    // The caller's prefilter, which is shared by clones.
+    hook: Option<Arc<~Prefilter:Send+Share>>,
+    // The counters of searches, if they're counted. They're shared by
+    // clones too.
+    stats: Option<Arc<Counters>>,
+}
+
+// The engine that `search` starts with (see `DfaCache::route`).
+enum Route<'a> {
+    NfaRoute,
+    LiteralsRoute,
+    FullRoute(&'a FullDfa),
+    ShiftOrRoute(&'a ShiftOr),
+    DfaRoute,
+}
+
+impl DfaCache {
+    /// Creates a new empty cache with the default size limit.
+    pub fn new() -> DfaCache {
+        DfaCache {
+            dfa: DfaPool::new(false),
+            rdfa: DfaPool::new(true),
+            rprog: None,
+            full: None,
+            limit: DEFAULT_SIZE_LIMIT,
+            backtrack_limit: backtrack::DEFAULT_LIMIT,
+            step_limit: None,
+            longest: false,
+            posix: false,
+            hook: None,
+            stats: None,
+        }
+    }
+
+    /// Creates a new empty cache that can use the reverse program given
+    /// (see `Program::new_reverse`) to find the start of matches.
+    pub fn with_reverse(rprog: Program) -> DfaCache {
+        DfaCache { rprog: Some(Arc::new(rprog)), ..DfaCache::new() }
+    }
+
+    /// Returns the reverse program that finds the start of matches, if
+    /// there is one.
+    pub fn reverse<'a>(&'a self) -> Option<&'a Program> {
+        self.rprog.as_ref().map(|rprog| &**rprog)
+    }
+
+    /// Returns the number of bytes the DFA may use before giving up.
+    pub fn size_limit(&self) -> uint {
+        self.limit
+    }
+
+    /// Returns the most bytes that the states of the lazy DFAs kept by this
+    /// cache may occupy: the size limit of each DFA in each pool.
+    pub fn max_size(&self) -> uint {
+        let pools = if self.rprog.is_some() { 2 } else { 1 };
+        pools * POOL_SHARDS * self.limit
+    }
+
+    /// Sets the number of bytes the DFA may use before giving up.
+    /// A limit of `0` disables the DFA. The DFAs compiled ahead of time are
+    /// thrown away.
+    pub fn set_size_limit(&mut self, limit: uint) {
+        self.limit = limit;
+        self.full = None;
+        self.dfa.clear();
+        self.rdfa.clear();
+    }
+
+    /// Returns the approximate number of bytes used by the states of the
+    /// forward and reverse DFAs. Each of them is held to the size limit on
+    /// its own.
+    pub fn size(&self) -> uint {
+        self.dfa.size() + self.rdfa.size()
+    }
+
+    /// Computes every state of the forward and reverse DFAs for the program
+    /// given, so that searches look up each transition in a table instead
+    /// of computing it (or of giving up). An error is returned if the tables
+    /// of either DFA take more than `limit` bytes, or if searches with the
+    /// program never use a DFA.
+    pub fn compile_full(&mut self, prog: &Program, limit: uint)
+                       -> Result<(), DfaError> {
+        if prog.search_start || prog.segments || prog.backtrack_only() {
+            return Err(DfaError {
+                msg: ~"Searches with this regex can't use a DFA.",
+                kind: DfaUnsupported,
+            })
+        }
+        let fwd = try!(FullTables::new(prog, false, limit));
+        let rev = match self.rprog {
+            None => None,
+            Some(ref rprog) => Some(try!(FullTables::new(&**rprog, true,
+                                                         limit))),
+        };
+        self.full = Some(Arc::new(FullDfa { fwd: fwd, rev: rev }));
+        Ok(())
+    }
+
+    /// Returns the number of bytes used by the tables of the DFAs compiled
+    /// ahead of time, or `0` if there are none.
+    pub fn full_size(&self) -> uint {
+        match self.full {
+            None => 0,
+            Some(ref full) => {
+                full.fwd.size() + full.rev.as_ref().map_or(0, |rev| rev.size())
+            }
+        }
+    }
+
+    /// Returns the number of bits the backtracker's visited set may use.
+    pub fn backtrack_limit(&self) -> uint {
+        self.backtrack_limit
+    }
+
+    /// Sets the number of bits the backtracker's visited set may use.
+    /// A limit of `0` disables the backtracker.
+    pub fn set_backtrack_limit(&mut self, limit: uint) {
+        self.backtrack_limit = limit;
+    }
+
+    /// Returns the number of steps a search may take, or `None` if it's
+    /// unlimited.
+    pub fn step_limit(&self) -> Option<uint> {
+        self.step_limit
+    }
+
+    /// Sets the number of steps a search may take. `None` removes the
+    /// limit.
+    pub fn set_step_limit(&mut self, limit: Option<uint>) {
+        self.step_limit = limit;
+    }
+
+    /// Returns the caller's prefilter, if there's one.
+    pub fn hook<'a>(&'a self) -> Option<&'a Prefilter> {
+        match self.hook {
+            None => None,
+            Some(ref hook) => Some(&***hook as &Prefilter),
+        }
+    }
+
+    /// Sets the caller's prefilter, or removes it if `hook` is `None`.
+    pub fn set_hook(&mut self, hook: Option<~Prefilter:Send+Share>) {
+        self.hook = hook.map(|hook| Arc::new(hook));
+    }
+
+    /// Returns the counters of searches, if they're counted.
+    pub fn stats<'a>(&'a self) -> Option<&'a Counters> {
+        self.stats.as_ref().map(|stats| &**stats)
+    }
+
+    /// Sets whether searches are counted. Counting starts from zero.
+    pub fn set_stats(&mut self, yes: bool) {
+        self.stats = if yes { Some(Arc::new(Counters::new())) } else { None };
+    }
+
+    /// Counts searches with the counters of `other` (or doesn't count them,
+    /// if `other` doesn't).
+    pub fn share_stats(&mut self, other: &DfaCache) {
+        self.stats = other.stats.clone();
+    }
+
+    // Returns a budget with the step limit, which counts what the search
+    // does if searches are counted.
+    fn budget<'a>(&'a self, cancel: Option<&'a Cancel>) -> Budget<'a> {
+        Budget::new(self.step_limit, cancel).with_stats(self.stats())
+    }
+
+    // Counts a search between `start` and `end`, if searches are counted.
+    fn count_search(&self, start: uint, end: uint, matched: bool) {
+        match self.stats() {
+            None => {}
+            Some(stats) => stats.search(end - start, matched),
+        }
+    }
+
+    // Counts the states of a lazy DFA being thrown away, if searches are
+    // counted.
+    fn count_clear(&self) {
+        match self.stats() {
+            None => {}
+            Some(stats) => stats.dfa_clear(),
+        }
+    }
+
+    /// Returns true if searches find the leftmost-longest match instead of
+    /// the leftmost-first one.
+    pub fn longest(&self) -> bool {
+        self.longest
+    }
+
+    /// Sets whether searches find the leftmost-longest match. Only the NFA
+    /// simulation can find it, so the other engines are only used to test
+    /// whether a match exists.
+    pub fn set_longest(&mut self, yes: bool) {
+        self.longest = yes;
+    }
+
+    /// Returns true if the capture groups of leftmost-longest matches are
+    /// chosen by the POSIX rules.
+    pub fn posix(&self) -> bool {
+        self.posix
+    }
+
+    /// Sets whether the capture groups of leftmost-longest matches are
+    /// chosen by the POSIX rules (see posix.rs). Otherwise, they're the
+    /// groups of the first path, in priority order, to find the match.
+    pub fn set_posix(&mut self, yes: bool) {
+        self.posix = yes;
+    }
+
+    /// Describes how searches with the program given are run: which engine
+    /// finds a match, which finds its capture groups, which literals the
+    /// prefilter scans for and whether the program is anchored at the
+    /// start. The format is meant for debugging and may change.
+    pub fn explain(&self, prog: &Program) -> ~str {
+        // The engine is the one `search` starts with for the whole text, and
+        // the groups are found as `exec_nfa` (and `Regex`, which runs the
+        // one-pass engine) find them.
+        let nfa =
+            if self.backtrack_limit > 0 {
+                "nfa (or backtrack, for short texts)"
+            } else {
+                "nfa"
+            };
+        let find = match self.route(Location, prog, true) {
+            NfaRoute if prog.backtrack_only() => "backtrack",
+            NfaRoute => nfa,
+            LiteralsRoute => "literals",
+            FullRoute(_) => "full dfa",
+            ShiftOrRoute(_) => "shift-or",
+            DfaRoute => "dfa",
+        };
+        let captures =
+            if prog.backtrack_only() {
+                "backtrack"
+            } else if self.finds_posix() {
+                "posix"
+            } else if prog.onepass.is_some() && self.step_limit.is_none()
+                      && !self.longest {
+                "onepass"
+            } else {
+                nfa
+            };
+        let anchored = if prog.anchored_start() { "yes" } else { "no" };
+        format!("engine: {}\ncaptures: {}\nprefilter: {}\nanchored: {}",
+                find, captures, prog.prefilter, anchored)
+    }
+
+    /// Executes the program given, using the DFA when possible.
+    /// The semantics are exactly the same as `vm::run`. If the search takes
+    /// more steps than the step limit allows (or `cancel` is set), then it's
+    /// abandoned.
+    pub fn exec(&self, which: MatchKind, prog: &Program, input: &str,
+                start: uint, end: uint, cancel: Option<&Cancel>)
+               -> Result<CaptureLocs, StepLimitExceeded> {
+        let mut caps = vec!();
+        try!(self.exec_into(which, prog, input, start, end, cancel,
+                            &mut caps));
+        Ok(caps)
+    }
+
+    /// Executes the program given like `exec`, except that the capture
+    /// locations are appended to `caps` rather than returned. Nothing is
+    /// appended if the search is abandoned.
+    pub fn exec_into(&self, which: MatchKind, prog: &Program, input: &str,
+                     start: uint, end: uint, cancel: Option<&Cancel>,
+                     caps: &mut CaptureLocs)
+                    -> Result<(), StepLimitExceeded> {
+        let base = caps.len();
+        let found = self.exec_counted(which, prog, input, start, end, cancel,
+                                      caps);
+        if self.stats.is_some() {
+            let matched = found.is_ok() && caps.get(base).is_some();
+            self.count_search(start, end, matched);
+        }
+        found
+    }
+
+    fn exec_counted(&self, which: MatchKind, prog: &Program, input: &str,
+                    start: uint, end: uint, cancel: Option<&Cancel>,
+                    caps: &mut CaptureLocs)
+                   -> Result<(), StepLimitExceeded> {
+        let mut budget = self.budget(cancel);
+        match which {
+            Submatches if self.finds_posix() => {
+                let found = try!(self.exec_posix(prog, input, start, end,
+                                                 false, &mut budget));
+                vm::push_locs(caps, found);
+                Ok(())
+            }
+            // The DFA knows nothing about submatches, but it does know where
+            // the match is. Then only its text has to be searched for the
+            // groups, by a search anchored at its start that can't go past
+            // its end. (Of the matches that start there, the one the DFA
+            // found is first by priority, so it's first among those that
+            // end by its end too.)
+            Submatches if self.finds_spans(prog, input, end) => {
+                let found = try!(self.search(Location, prog, input, start,
+                                             end, &mut budget));
+                match found {
+                    None => {
+                        caps.grow(prog.num_captures() * 2, &None);
+                        Ok(())
+                    }
+                    Some((s, e)) => {
+                        self.exec_nfa_into(which, prog, input, s, e, true,
+                                           &mut budget, caps)
+                    }
+                }
+            }
+            Submatches => {
+                self.exec_nfa_into(which, prog, input, start, end, false,
+                                   &mut budget, caps)
+            }
+            Exists | Location => {
+                let found = try!(self.search(which, prog, input, start, end,
+                                             &mut budget));
+                let (s, e) = match found {
+                    None => (None, None),
+                    Some((s, e)) => (Some(s), Some(e)),
+                };
+                caps.push(s);
+                caps.push(e);
+                Ok(())
+            }
+        }
+    }
+
+    /// Executes the program given like `exec`, except that only a match
+    /// starting at `start` is found.
+    pub fn exec_anchored(&self, which: MatchKind, prog: &Program,
+                         input: &str, start: uint, end: uint,
+                         cancel: Option<&Cancel>)
+                        -> Result<CaptureLocs, StepLimitExceeded> {
+        let caps = self.exec_candidate(which, prog, input, start, end,
+                                       cancel);
+        self.count_caps(start, end, &caps);
+        caps
+    }
+
+    /// Like `exec_anchored`, except that the search isn't counted (but the
+    /// engine that runs it is). It's for the candidates tried by a search
+    /// that's counted as a whole, like one run with a prefilter hook.
+    pub fn exec_candidate(&self, which: MatchKind, prog: &Program,
+                          input: &str, start: uint, end: uint,
+                          cancel: Option<&Cancel>)
+                         -> Result<CaptureLocs, StepLimitExceeded> {
+        // The NFA stops as soon as no thread started at `start` is alive,
+        // so it's usually much faster than a search would be.
+        let mut budget = self.budget(cancel);
+        match which {
+            Submatches if self.finds_posix() => {
+                self.exec_posix(prog, input, start, end, true, &mut budget)
+            }
+            _ => {
+                self.exec_nfa(which, prog, input, start, end, true,
+                              &mut budget)
+            }
+        }
+    }
+
+    /// Returns where the longest match of the program given that starts
+    /// exactly at `start` ends, or `None` if no match starts there. Like
+    /// `exec_anchored`, the search stops as soon as no match can start at
+    /// `start` anymore, and it goes to the end of the input.
+    pub fn find_prefix(&self, prog: &Program, input: &str, start: uint,
+                       cancel: Option<&Cancel>)
+                      -> Result<Option<uint>, StepLimitExceeded> {
+        let mut budget = self.budget(cancel);
+        let caps = self.run_longest(Location, prog, input, start, input.len(),
+                                    true, &mut budget);
+        self.count_caps(start, input.len(), &caps);
+        let caps = try!(caps);
+        Ok(*caps.get(1))
+    }
+
+    /// Executes the program given like `exec`, except that the
+    /// leftmost-longest match is found whether or not `set_longest` was
+    /// called.
+    pub fn exec_longest(&self, which: MatchKind, prog: &Program,
+                        input: &str, start: uint, end: uint,
+                        cancel: Option<&Cancel>)
+                       -> Result<CaptureLocs, StepLimitExceeded> {
+        let mut budget = self.budget(cancel);
+        let caps = match which {
+            Submatches if self.posix => {
+                self.exec_posix(prog, input, start, end, false, &mut budget)
+            }
+            _ => {
+                self.run_longest(which, prog, input, start, end, false,
+                                 &mut budget)
+            }
+        };
+        self.count_caps(start, end, &caps);
+        caps
+    }
+
+    // Counts a search that found the capture locations given, if searches
+    // are counted.
+    fn count_caps(&self, start: uint, end: uint,
+                  caps: &Result<CaptureLocs, StepLimitExceeded>) {
+        if self.stats.is_some() {
+            let matched = match *caps {
+                Ok(ref caps) => caps.get(0).is_some(),
+                Err(_) => false,
+            };
+            self.count_search(start, end, matched);
+        }
+    }
+
+    /// Returns true if the program given matches between `start` and `end`.
+    /// This is the same as `exec` with `Exists`, except that no memory is
+    /// allocated when the DFA can answer (once it has computed the states
+    /// the search visits).
+    pub fn is_match(&self, prog: &Program, input: &str, start: uint,
+                    end: uint, cancel: Option<&Cancel>)
+                   -> Result<bool, StepLimitExceeded> {
+        let mut budget = self.budget(cancel);
+        let found = try!(self.search(Exists, prog, input, start, end,
+                                     &mut budget));
+        self.count_search(start, end, found.is_some());
+        Ok(found.is_some())
+    }
+
+    /// Calls `each` with the index of every text in `texts` that the program
+    /// matches, in order, until it returns false. A text whose search
+    /// exceeds the step limit doesn't match.
+    ///
+    /// This finds the same texts as calling `is_match` on each one, but the
+    /// lazy DFA is taken from the pool once for all of them. Since the texts
+    /// are searched from the same start state, most of them are searched
+    /// with states computed for the ones before. Each text is taken from
+    /// `texts` when it's searched.
+    pub fn filter<S: Str, I: Iterator<S>>(&self, prog: &Program, texts: I,
+                                          each: |uint| -> bool) {
+        if !self.lazy_only(prog) {
+            for (i, text) in texts.enumerate() {
+                let text = text.as_slice();
+                let found = self.is_match(prog, text, 0, text.len(), None);
+                if found == Ok(true) && !each(i) {
+                    return
+                }
+            }
+            return
+        }
+        let (shard, mut dfa) = self.dfa.get();
+        for (i, text) in texts.enumerate() {
+            if self.filter_one(&mut dfa, prog, text.as_slice()) && !each(i) {
+                break
+            }
+        }
+        self.dfa.put(shard, dfa);
+    }
+
+    // Returns true if `search` would go straight to the lazy DFA for every
+    // text (when the prefilter doesn't rule it out).
+    fn lazy_only(&self, prog: &Program) -> bool {
+        match self.route(Exists, prog, true) {
+            DfaRoute => true,
+            _ => false,
+        }
+    }
+
+    // Searches all of `text` for a match with the DFA given, like `search`
+    // does for `Exists` once it has checked that the lazy DFA can be used.
+    fn filter_one(&self, dfa: &mut Dfa, prog: &Program, text: &str) -> bool {
+        if !prog.prefilter.is_some()
+           && !prog.prefilter.may_match(text.as_bytes()) {
+            self.count_search(0, text.len(), false);
+            return false
+        }
+        let mut budget = self.budget(None);
+        budget.count_engine(EngineDfa);
+        let found =
+            match dfa.exec(prog, Exists, text, 0, self.limit, &mut budget) {
+                NoMatch | OutOfSteps => false,
+                Matched(_, _) => true,
+                GaveUp => {
+                    dfa.clear();
+                    self.count_clear();
+                    let found = self.search_nfa(Exists, prog, text, 0,
+                                                text.len(), &mut budget);
+                    match found {
+                        Ok(Some(_)) => true,
+                        _ => false,
+                    }
+                }
+            };
+        self.count_search(0, text.len(), found);
+        found
+    }
+
+    /// Returns the location of the leftmost-first match of the program given
+    /// between `start` and `end`. This is the same as `exec` with `Location`,
+    /// except that no memory is allocated when the DFA can find the match.
+    pub fn find(&self, prog: &Program, input: &str, start: uint, end: uint,
+                cancel: Option<&Cancel>)
+               -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+        let mut budget = self.budget(cancel);
+        let found = self.search(Location, prog, input, start, end,
+                                &mut budget);
+        if self.stats.is_some() {
+            let matched = match found { Ok(Some(_)) => true, _ => false };
+            self.count_search(start, end, matched);
+        }
+        found
+    }
+
+    // Searches for a match with the fastest engine available. When `which`
+    // is `Exists`, the location returned is meaningless.
+    //
+    // Scanning for literals and the bit-parallel engine take no steps from
+    // the budget, since they never do more than a little work per byte.
+    fn search(&self, which: MatchKind, prog: &Program, input: &str,
+              mut start: uint, end: uint, budget: &mut Budget)
+             -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+        // The DFA knows nothing about searching only part of the input.
+        let whole = end == input.len();
+        match self.route(which, prog, whole) {
+            NfaRoute => {
+                return self.search_nfa(which, prog, input, start, end, budget)
+            }
+            LiteralsRoute => {
+                budget.count_engine(EngineLiterals);
+                let haystack = input.as_bytes().slice_from(start);
+                return Ok(prog.prefilter.find_match(haystack).map(|(s, e)| {
+                    (start + s, start + e)
+                }))
+            }
+            FullRoute(full) => {
+                return self.search_full(full, which, prog, input, start,
+                                        budget)
+            }
+            ShiftOrRoute(so) => {
+                budget.count_engine(EngineShiftOr);
+                match (so.exec(input, start, end), which) {
+                    (None, _) => return Ok(None),
+                    (Some(_), Exists) => return Ok(Some((0, 0))),
+                    // No match starts before `lower`, so the search can
+                    // start there. (Unlike the NFA, the DFA doesn't
+                    // allocate once it has the states it needs.)
+                    (Some((lower, _)), _) => start = lower,
+                }
+                if !whole {
+                    return self.search_nfa(which, prog, input, start, end,
+                                           budget)
+                }
+            }
+            DfaRoute => {}
+        }
+        if !prog.prefilter.is_some()
+           && !prog.prefilter.may_match(input.as_bytes().slice_from(start)) {
+            // A literal required by every match is missing.
+            return Ok(None)
+        }
+        budget.count_engine(EngineDfa);
+        let result = {
+            let (shard, mut dfa) = self.dfa.get();
+            let result = dfa.exec(prog, which, input, start, self.limit,
+                                  budget);
+            match result {
+                GaveUp => {
+                    dfa.clear();
+                    self.count_clear();
+                }
+                _ => {}
+            }
+            self.dfa.put(shard, dfa);
+            result
+        };
+        match (result, which) {
+            (NoMatch, _) => Ok(None),
+            (OutOfSteps, _) => Err(StepLimitExceeded),
+            (Matched(_, _), Exists) => Ok(Some((0, 0))),
+            (Matched(s, e), _) => self.find_start(prog, input, s, e, budget),
+            (GaveUp, _) => {
+                self.search_nfa(which, prog, input, start, end, budget)
+            }
+        }
+    }
+
+    // Chooses the engine that `search` starts with, where `whole` is true
+    // if the search ends at the end of the input. `explain` reports the
+    // same choice.
+    fn route<'a>(&'a self, which: MatchKind, prog: &'a Program, whole: bool)
+                -> Route<'a> {
+        if self.needs_nfa(which, prog) {
+            return NfaRoute
+        }
+        if prog.prefilter.is_complete() && whole {
+            // The expression is just a set of literals, so the prefilter
+            // can find matches all by itself.
+            return LiteralsRoute
+        }
+        match self.full {
+            Some(ref full) if whole => return FullRoute(&**full),
+            _ => {}
+        }
+        match (which, &prog.shiftor) {
+            (Submatches, _) => {}
+            (_, &Some(ref so)) if self.limit > 0 => return ShiftOrRoute(so),
+            _ => {}
+        }
+        if self.limit > 0 && whole { DfaRoute } else { NfaRoute }
+    }
+
+    // Searches with the DFAs compiled ahead of time. There's no need for the
+    // prefilter's other checks, since the DFA never computes a state.
+    fn search_full(&self, full: &FullDfa, which: MatchKind, prog: &Program,
+                   input: &str, start: uint, budget: &mut Budget)
+                  -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+        budget.count_engine(EngineFullDfa);
+        let (lower, end) = match full.fwd.exec(prog, which, input, start,
+                                               budget) {
+            NoMatch => return Ok(None),
+            OutOfSteps => return Err(StepLimitExceeded),
+            Matched(s, e) => (s, e),
+            // The tables never run out of memory.
+            GaveUp => {
+                return self.search_nfa(which, prog, input, start,
+                                       input.len(), budget)
+            }
+        };
+        let rev = match (which, &full.rev) {
+            (Exists, _) => return Ok(Some((0, 0))),
+            (_, &None) => {
+                return self.find_start(prog, input, lower, end, budget)
+            }
+            (_, &Some(ref rev)) => rev,
+        };
+        match rev.exec_reverse(input, end, lower, budget) {
+            Matched(s, e) => Ok(Some((s, e))),
+            OutOfSteps => Err(StepLimitExceeded),
+            NoMatch | GaveUp => {
+                self.search_nfa(Location, prog, input, lower, end, budget)
+            }
+        }
+    }
+
+    // Simulates the NFA, using the backtracker when the text is short enough.
+    fn exec_nfa(&self, which: MatchKind, prog: &Program, input: &str,
+                start: uint, end: uint, anchored: bool, budget: &mut Budget)
+               -> Result<CaptureLocs, StepLimitExceeded> {
+        if self.finds_longest(which) {
+            self.run_longest(which, prog, input, start, end, anchored, budget)
+        } else if self.backtracks(prog, start, end) {
+            budget.count_engine(EngineBacktrack);
+            backtrack::run(which, prog, input, start, end, anchored,
+                           self.backtrack_limit, budget)
+        } else {
+            budget.count_engine(EngineNfa);
+            vm::run(which, prog, input, start, end, anchored, budget)
+        }
+    }
+
+    // Like `exec_nfa`, but appends the capture locations to `caps`. Only the
+    // NFA writes them there directly.
+    fn exec_nfa_into(&self, which: MatchKind, prog: &Program, input: &str,
+                     start: uint, end: uint, anchored: bool,
+                     budget: &mut Budget, caps: &mut CaptureLocs)
+                    -> Result<(), StepLimitExceeded> {
+        if self.finds_longest(which) || self.backtracks(prog, start, end) {
+            let found = try!(self.exec_nfa(which, prog, input, start, end,
+                                           anchored, budget));
+            vm::push_locs(caps, found);
+            Ok(())
+        } else {
+            budget.count_engine(EngineNfa);
+            vm::run_into(which, prog, input, start, end, anchored, budget,
+                         caps)
+        }
+    }
+
+    // Returns true if the backtracker searches between `start` and `end`
+    // rather than the NFA, when the leftmost-first match is wanted.
+    fn backtracks(&self, prog: &Program, start: uint, end: uint) -> bool {
+        prog.backtrack_only()
+        || backtrack::should_exec(prog, start, end, self.backtrack_limit)
+    }
+
+    // Finds the leftmost-longest match with the NFA, or with the backtracker
+    // when the program can only be run by it (see `backtrack_only`).
+    fn run_longest(&self, which: MatchKind, prog: &Program, input: &str,
+                   start: uint, end: uint, anchored: bool,
+                   budget: &mut Budget)
+                  -> Result<CaptureLocs, StepLimitExceeded> {
+        if prog.backtrack_only() {
+            budget.count_engine(EngineBacktrack);
+            backtrack::run_longest(which, prog, input, start, end, anchored,
+                                   self.backtrack_limit, budget)
+        } else {
+            budget.count_engine(EngineNfa);
+            vm::run_longest(which, prog, input, start, end, anchored, budget)
+        }
+    }
+
+    // Returns true if only the NFA can find where a match of the program
+    // given is. Only the NFA knows where the search started (or where it is
+    // in the input, for `\b{g}`, `\b{wb}` and lookahead, or how to find the
+    // longest match, or what a group matched, for backreferences, or where a
+    // match starts, for `\K`).
+    fn needs_nfa(&self, which: MatchKind, prog: &Program) -> bool {
+        prog.search_start || prog.segments || prog.backtrack_only()
+        || self.finds_longest(which)
+    }
+
+    // Returns true if the DFA can find where a leftmost-first match ends
+    // before its capture groups are found. (Where it can't, finding the
+    // match first would only search the text twice.)
+    fn finds_spans(&self, prog: &Program, input: &str, end: uint) -> bool {
+        self.limit > 0 && end == input.len()
+        && !self.needs_nfa(Submatches, prog)
+    }
+
+    // Returns true if the search for `which` must find the leftmost-longest
+    // match. Any match will do when only its existence matters.
+    fn finds_longest(&self, which: MatchKind) -> bool {
+        self.longest && match which { Exists => false, _ => true }
+    }
+
+    // Returns true if capture groups must be chosen by the POSIX rules.
+    fn finds_posix(&self) -> bool {
+        self.longest && self.posix
+    }
+
+    // Finds the leftmost-longest match and then its capture groups by the
+    // POSIX rules. (Except for programs that only the backtracker runs,
+    // whose groups are those of the longest match that's first by priority.)
+    fn exec_posix(&self, prog: &Program, input: &str, start: uint, end: uint,
+                  anchored: bool, budget: &mut Budget)
+                 -> Result<CaptureLocs, StepLimitExceeded> {
+        if prog.backtrack_only() {
+            budget.count_engine(EngineBacktrack);
+            return backtrack::run_longest(Submatches, prog, input, start, end,
+                                          anchored, self.backtrack_limit,
+                                          budget)
+        }
+        budget.count_engine(EngineNfa);
+        let caps = try!(vm::run_longest(Location, prog, input, start, end,
+                                        anchored, budget));
+        match (*caps.get(0), *caps.get(1)) {
+            (Some(s), Some(e)) => {
+                budget.count_engine(EnginePosix);
+                posix::run(prog, input, start, s, e, self.backtrack_limit,
+                           budget)
+            }
+            _ => Ok(Vec::from_elem(prog.num_captures() * 2, None)),
+        }
+    }
+
+    // Like `exec_nfa`, but only returns the location of the match.
+    fn search_nfa(&self, which: MatchKind, prog: &Program, input: &str,
+                  start: uint, end: uint, budget: &mut Budget)
+                 -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+        let caps = try!(self.exec_nfa(which, prog, input, start, end, false,
+                                      budget));
+        Ok(match (*caps.get(0), *caps.get(1)) {
+            (Some(s), Some(e)) => Some((s, e)),
+            _ => None,
+        })
+    }
+
+    // Given the end of a leftmost-first match and a lower bound on where it
+    // starts, returns the location of the match.
+    //
+    // The start is found by running the reverse program backwards from the
+    // end of the match. If that's not possible, then the NFA is run on the
+    // text between the lower bound and the end.
+    fn find_start(&self, prog: &Program, input: &str,
+                  lower: uint, end: uint, budget: &mut Budget)
+                 -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+        let rprog = match self.rprog {
+            None => {
+                return self.search_nfa(Location, prog, input, lower, end,
+                                       budget)
+            }
+            Some(ref rprog) => &**rprog,
+        };
+        let result = {
+            let (shard, mut rdfa) = self.rdfa.get();
+            let result = rdfa.exec_reverse(rprog, input, end, lower,
+                                           self.limit, budget);
+            match result {
+                GaveUp => {
+                    rdfa.clear();
+                    self.count_clear();
+                }
+                _ => {}
+            }
+            self.rdfa.put(shard, rdfa);
+            result
+        };
+        match result {
+            Matched(s, e) => Ok(Some((s, e))),
+            OutOfSteps => Err(StepLimitExceeded),
+            // The forward DFA found a match, so the reverse DFA must have
+            // too. Either way, the NFA knows best.
+            NoMatch | GaveUp => {
+                self.search_nfa(Location, prog, input, lower, end, budget)
+            }
+        }
+    }
+}
+
+impl Clone for DfaCache {
+    /// Clones the cache's configuration. The states of the lazy DFA are not
+    /// copied, but the DFAs compiled ahead of time are shared.
+    fn clone(&self) -> DfaCache {
+        DfaCache {
+            dfa: DfaPool::new(false),
+            rdfa: DfaPool::new(true),
+            rprog: self.rprog.clone(),
+            full: self.full.clone(),
+            limit: self.limit,
+            backtrack_limit: self.backtrack_limit,
+            step_limit: self.step_limit,
+            longest: self.longest,
+            posix: self.posix,
+            hook: self.hook.clone(),
+            stats: self.stats.clone(),
+        }
+    }
+}
+
+// A pool of DFAs for one program (see the comments at the top).
+struct DfaPool {
+    shards: Vec<Mutex<Option<Dfa>>>,
+    reverse: bool,
+}
+
+impl DfaPool {
+    fn new(reverse: bool) -> DfaPool {
+        DfaPool {
+            shards: Vec::from_fn(POOL_SHARDS, |_| Mutex::new(None)),
+            reverse: reverse,
+        }
+    }
+
+    // Takes a DFA out of the pool, or creates one if there are none. It
+    // should be put back into the shard returned along with it. The
+    // task's home shard is looked at first.
+    fn get(&self) -> (uint, Dfa) {
+        let first = home_shard();
+        for i in range(0, POOL_SHARDS) {
+            let shard = (first + i) % POOL_SHARDS;
+            let mut guard = self.shards.get(shard).lock();
+            let slot: &mut Option<Dfa> = &mut *guard;
+            match slot.take() {
+                None => {}
+                Some(dfa) => return (shard, dfa),
+            }
+        }
+        (first, Dfa::new(self.reverse))
+    }
+
+    // Puts a DFA back into its shard, or drops it if the shard has one
+    // already (which another search put there while this one ran).
+    fn put(&self, shard: uint, dfa: Dfa) {
+        let mut guard = self.shards.get(shard).lock();
+        let slot: &mut Option<Dfa> = &mut *guard;
+        if slot.is_none() {
+            *slot = Some(dfa);
+        }
+    }
+
+    // Throws away the states of every DFA in the pool.
+    fn clear(&self) {
+        for shard in self.shards.iter() {
+            let mut guard = shard.lock();
+            *guard = None;
+        }
+    }
+
+    // Returns the approximate number of bytes used by the states of the
+    // DFAs in the pool (but not of the ones in use).
+    fn size(&self) -> uint {
+        self.shards.iter().fold(0, |size, shard| {
+            let guard = shard.lock();
+            size + (*guard).as_ref().map_or(0, |dfa| dfa.size())
+        })
+    }
+}
+
+// Returns the home shard of the current task, giving it one if it has none
+// yet. Tasks are given homes in turn.
+fn home_shard() -> uint {
+    match local_data::get(HOME_SHARD, |home| home.map(|&home| home)) {
+        Some(home) => home,
+        None => {
+            let home = unsafe { HOMES.fetch_add(1, Relaxed) } % POOL_SHARDS;
+            local_data::set(HOME_SHARD, home);
+            home
+        }
+    }
+}
+
+struct State {
+    // NFA instructions reached after consuming a character, in priority
+    // order.
+    kernel: Vec<uint>,
+    // Describes the character consumed to get to this state.
+    flags: u8,
+    // Whether a match has been seen. Once a match is seen, no new threads
+    // are started (just like in the NFA).
+    matched: bool,
+    // Cached transitions. Each one is either UNKNOWN or the index of the next
+    // state shifted left by one, with the low bit set if the transition
+    // reports a match.
+    trans: Vec<uint>,
+}
+
+#[deriving(Eq, TotalEq, Hash)]
+struct StateKey {
+    kernel: Vec<uint>,
+    flags: u8,
+    matched: bool,
+}
+
+/// Dfa is a lazily constructed DFA for a single program.
+pub struct Dfa {
+    states: Vec<State>,
+    cache: HashMap<StateKey, uint>,
+    // Start states, indexed by the flags of the preceding character.
+    starts: Vec<uint>,
+    // When true, this DFA runs a reverse program backwards from the end of a
+    // match. Such a search is anchored and reports the *longest* match,
+    // which corresponds to the leftmost start of the forward match.
+    reverse: bool,
+    // The bits of the flags that states keep. Only a DFA compiled ahead of
+    // time drops any (see `flag_mask`).
+    mask: u8,
+    // Approximate number of bytes used by the states above.
+    size: uint,
+    // Scratch space for computing the epsilon closure of a state.
+    seen: SparseSet,
+    closure: Vec<uint>,
+    // Scratch space for the kernel of the next state, which is only copied
+    // when the state is new. This way, a transition that isn't cached (such
+    // as one on a character that isn't ASCII) doesn't allocate when the
+    // state it leads to is.
+    kernel: Vec<uint>,
+}
+
+impl Dfa {
+    fn new(reverse: bool) -> Dfa {
+        let mut dfa = Dfa {
+            states: vec!(),
+            cache: HashMap::new(),
+            starts: vec!(),
+            reverse: reverse,
+            mask: ALL_FLAGS,
+            size: 0,
+            seen: SparseSet::new(0),
+            closure: vec!(),
+            kernel: vec!(),
+        };
+        dfa.clear();
+        dfa
+    }
+
+    /// Throws away every state, except for the dead state.
+    fn clear(&mut self) {
+        self.states.clear();
+        self.cache.clear();
+        self.starts = Vec::from_elem(NUM_FLAGS, UNKNOWN);
+        self.size = 0;
+        self.states.push(State {
+            kernel: vec!(),
+            flags: 0,
+            matched: true,
+            trans: Vec::from_elem(NUM_TRANS, DEAD << 1),
+        });
+    }
+
+    /// Returns the approximate number of bytes used by cached states.
+    pub fn size(&self) -> uint {
+        self.size
+    }
+
+    fn exec(&mut self, prog: &Program, which: MatchKind, input: &str,
+            start: uint, limit: uint, budget: &mut Budget) -> DfaResult {
+        if self.seen.capacity() != prog.insts.len() {
+            // This DFA was last used with a different program.
+            self.clear();
+            self.seen = SparseSet::new(prog.insts.len());
+        }
+        let exists = match which { Exists => true, _ => false };
+        let bytes = input.as_bytes();
+        let mut si = match self.start_state(prev_flags(input, start), limit) {
+            None => return GaveUp,
+            Some(si) => si,
+        };
+        let (mut i, mut lower) = (start, start);
+        let mut last_match = None;
+        // Characters are taken from the budget in chunks.
+        let mut charged = start;
+        loop {
+            if i >= charged + STEP_CHUNK {
+                if !budget.take(i - charged) {
+                    return OutOfSteps
+                }
+                charged = i;
+            }
+            let at_start = {
+                let st = self.states.get(si);
+                !st.matched && st.kernel.len() == 0
+            };
+            if at_start {
+                // No threads are alive, so a match can't start before this
+                // position. If there's a prefilter, then a match can't start
+                // before the next position it reports either.
+                if prog.prefilter.is_some() {
+                    match prog.prefilter.find(bytes.slice_from(i)) {
+                        None => break,
+                        Some(0) => budget.count_candidate(),
+                        Some(j) => {
+                            budget.count_candidate();
+                            i += j;
+                            let flags = prev_flags(input, i);
+                            si = match self.start_state(flags, limit) {
+                                None => return GaveUp,
+                                Some(si) => si,
+                            };
+                        }
+                    }
+                }
+                lower = i;
+            }
+            let (c, ti, next) =
+                if i >= bytes.len() {
+                    (None, TRANS_EOF, i)
+                } else if bytes[i] == '\n' as u8 && i + 1 == bytes.len() {
+                    (Some('\n'), TRANS_FINALNL, i + 1)
+                } else if bytes[i] < 0x80 {
+                    (Some(bytes[i] as char), bytes[i] as uint, i + 1)
+                } else {
+                    let r = input.char_range_at(i);
+                    (Some(r.ch), UNKNOWN, r.next)
+                };
+            let mut t = UNKNOWN;
+            if ti != UNKNOWN {
+                t = *self.states.get(si).trans.get(ti);
+            }
+            if t == UNKNOWN {
+                if !budget.take(self.states.get(si).kernel.len() + 1) {
+                    return OutOfSteps
+                }
+                let last = ti == TRANS_FINALNL;
+                t = match self.transition(prog, si, c, last, limit) {
+                    None => return GaveUp,
+                    Some(t) => t,
+                };
+                if ti != UNKNOWN {
+                    *self.states.get_mut(si).trans.get_mut(ti) = t;
+                }
+            }
+            if t & 1 == 1 {
+                if exists {
+                    if !budget.take(i - charged) {
+                        return OutOfSteps
+                    }
+                    return Matched(lower, i)
+                }
+                last_match = Some(i);
+            }
+            si = t >> 1;
+            if c.is_none() || si == DEAD {
+                break
+            }
+            i = next;
+        }
+        if !budget.take(i - charged) {
+            return OutOfSteps
+        }
+        match last_match {
+            None => NoMatch,
+            Some(e) => Matched(lower, e),
+        }
+    }
+
+    // Runs the reverse program backwards from `end`, but no further than
+    // `floor`. The match returned starts at the leftmost position at which
+    // the reverse program matches.
+    fn exec_reverse(&mut self, prog: &Program, input: &str, end: uint,
+                    floor: uint, limit: uint, budget: &mut Budget)
+                   -> DfaResult {
+        if self.seen.capacity() != prog.insts.len() {
+            self.clear();
+            self.seen = SparseSet::new(prog.insts.len());
+        }
+        let bytes = input.as_bytes();
+        let mut si = match self.start_state(next_flags(input, end), limit) {
+            None => return GaveUp,
+            Some(si) => si,
+        };
+        let mut i = end;
+        let mut first_match = None;
+        let mut charged = end;
+        loop {
+            if i + STEP_CHUNK <= charged {
+                if !budget.take(charged - i) {
+                    return OutOfSteps
+                }
+                charged = i;
+            }
+            let (c, ti, next) =
+                if i == 0 {
+                    (None, TRANS_EOF, 0)
+                } else if bytes[i - 1] == '\n' as u8 && i == bytes.len() {
+                    (Some('\n'), TRANS_FINALNL, i - 1)
+                } else if bytes[i - 1] < 0x80 {
+                    (Some(bytes[i - 1] as char), bytes[i - 1] as uint, i - 1)
+                } else {
+                    let r = input.char_range_at_reverse(i);
+                    (Some(r.ch), UNKNOWN, r.next)
+                };
+            let mut t = UNKNOWN;
+            if ti != UNKNOWN {
+                t = *self.states.get(si).trans.get(ti);
+            }
+            if t == UNKNOWN {
+                if !budget.take(self.states.get(si).kernel.len() + 1) {
+                    return OutOfSteps
+                }
+                let last = ti == TRANS_FINALNL;
+                t = match self.transition(prog, si, c, last, limit) {
+                    None => return GaveUp,
+                    Some(t) => t,
+                };
+                if ti != UNKNOWN {
+                    *self.states.get_mut(si).trans.get_mut(ti) = t;
+                }
+            }
+            if t & 1 == 1 {
+                first_match = Some(i);
+            }
+            si = t >> 1;
+            if c.is_none() || si == DEAD || i <= floor {
+                break
+            }
+            i = next;
+        }
+        if !budget.take(charged - i) {
+            return OutOfSteps
+        }
+        match first_match {
+            None => NoMatch,
+            Some(s) => Matched(s, end),
+        }
+    }
+
+    // Returns the state in which a search starts, given the flags for the
+    // character preceding the start of the search.
+    fn start_state(&mut self, flags: u8, limit: uint) -> Option<uint> {
+        let flags = flags & self.mask;
+        let si = *self.starts.get(flags as uint);
+        if si != UNKNOWN {
+            return Some(si)
+        }
+        // An anchored search starts with the first instruction and never
+        // starts any other threads, which is exactly what the `matched` flag
+        // does.
+        let added =
+            if self.reverse {
+                self.add_state(vec!(0), flags, true, limit)
+            } else {
+                self.add_state(vec!(), flags, false, limit)
+            };
+        let si = match added {
+            None => return None,
+            Some(si) => si,
+        };
+        *self.starts.get_mut(flags as uint) = si;
+        Some(si)
+    }
+
+    // Computes the transition out of state `si` on the character `c` (or
+    // the end of the input if `c` is `None`), where `last` tells whether `c`
+    // is a new line that ends the input. The encoding of the value returned
+    // is the same as the one used for `State.trans`.
+    //
+    // If the new state doesn't fit in the memory budget, `None` is returned.
+    fn transition(&mut self, prog: &Program, si: uint, c: Option<char>,
+                  last: bool, limit: uint) -> Option<uint> {
+        let (flags, matched) = {
+            let st = self.states.get(si);
+            (st.flags, st.matched)
+        };
+        self.seen.clear();
+        self.closure.clear();
+        for i in range(0, self.states.get(si).kernel.len()) {
+            let pc = *self.states.get(si).kernel.get(i);
+            self.add(prog, pc, flags, c, last);
+        }
+        if !matched {
+            // This simulates the preceding '.*?' of every search.
+            self.add(prog, 0, flags, c, last);
+        }
+
+        let mut is_match = false;
+        let mut kernel = mem::replace(&mut self.kernel, vec!());
+        kernel.clear();
+        for &pc in self.closure.iter() {
+            match *prog.insts.get(pc) {
+                Match => {
+                    is_match = true;
+                    // Leftmost-first: drop every lower priority thread.
+                    // When looking for the longest match, keep going.
+                    if !self.reverse {
+                        break
+                    }
+                }
+                ref inst => {
+                    if vm::char_matches(inst, c) {
+                        kernel.push(pc + 1);
+                    }
+                }
+            }
+        }
+        let nflags = match c {
+            None => 0,
+            Some(_) if last => (PREV_NL | PREV_FINALNL) & self.mask,
+            Some(c) => char_flags(c) & self.mask,
+        };
+        let next = match self.add_state(kernel, nflags, matched || is_match,
+                                        limit) {
+            None => return None,
+            Some(next) => next,
+        };
+        Some((next << 1) | (if is_match { 1 } else { 0 }))
+    }
+
+    // Adds the instruction at `pc` and everything reachable from it via
+    // empty transitions to the current closure. `flags` describes the
+    // character before the current position and `cur` is the character
+    // after it (`last` is as for `transition`).
+    fn add(&mut self, prog: &Program, pc: uint, flags: u8, cur: Option<char>,
+           last: bool) {
+        if self.seen.contains(pc) {
+            return
+        }
+        self.seen.insert(pc);
+        match *prog.insts.get(pc) {
+            EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_) => {
+                if empty_ok(prog.insts.get(pc), flags, cur, last) {
+                    self.add(prog, pc + 1, flags, cur, last)
+                }
+            }
+            // Programs with these never reach the DFA (see `search`).
+            EmptySegmentBoundary(_, _) | EmptyLook(_, _) | Cut(_) | LookMatch
+            | GroupRef(_, _) => {}
+            Save(_) => self.add(prog, pc + 1, flags, cur, last),
+            Jump(to) => self.add(prog, to, flags, cur, last),
+            Split(x, y) => {
+                self.add(prog, x, flags, cur, last);
+                self.add(prog, y, flags, cur, last);
+            }
+            Match | OneChar(_, _) | CharClass(_, _) | Any(_) => {
+                self.closure.push(pc)
+            }
+        }
+    }
+
+    // Returns the index of the state given, creating it if it doesn't exist.
+    fn add_state(&mut self, kernel: Vec<uint>, flags: u8, matched: bool,
+                 limit: uint) -> Option<uint> {
+        if matched && kernel.len() == 0 {
+            self.kernel = kernel;
+            return Some(DEAD)
+        }
+        let key = StateKey { kernel: kernel, flags: flags, matched: matched };
+        match self.cache.find_copy(&key) {
+            Some(si) => {
+                // The kernel can be used again for the next transition.
+                self.kernel = key.kernel;
+                return Some(si)
+            }
+            None => {}
+        }
+        // Both the state and its key hold a copy of the kernel.
+        let size = 2 * key.kernel.len() * uint::BYTES
+                   + NUM_TRANS * uint::BYTES
+                   + mem::size_of::<State>() + mem::size_of::<StateKey>();
+        if self.size + size > limit {
+            return None
+        }
+        self.size += size;
+        let si = self.states.len();
+        self.states.push(State {
+            kernel: key.kernel.clone(),
+            flags: flags,
+            matched: matched,
+            trans: Vec::from_elem(NUM_TRANS, UNKNOWN),
+        });
+        self.cache.insert(key, si);
+        Some(si)
+    }
+}
+
+// The DFAs compiled ahead of time for a program and its reverse.
+struct FullDfa {
+    fwd: FullTables,
+    rev: Option<FullTables>,
+}
+
+// Every state of a DFA, with its transitions in a table (see the comments at
+// the top).
+struct FullTables {
+    // The class of each ASCII character.
+    ascii: Vec<uint>,
+    // The classes of the other characters, as sorted ranges.
+    ranges: Vec<(char, char, uint)>,
+    // The number of columns per state: one for each class, one for a new
+    // line that ends the input and one for the end of the input, which is
+    // last.
+    stride: uint,
+    // The transitions of every state, encoded like those of `State.trans`.
+    trans: Vec<uint>,
+    // Start states, indexed by the flags of the preceding character.
+    starts: Vec<uint>,
+    // Whether each state has no threads alive.
+    idle: Vec<bool>,
+}
+
+impl FullTables {
+    // Computes every state of the DFA for the program given, or fails if
+    // the tables would take more than `limit` bytes.
+    fn new(prog: &Program, reverse: bool, limit: uint)
+          -> Result<FullTables, DfaError> {
+        let mask = flag_mask(prog);
+        let (ascii, ranges, reps) = char_classes(prog, mask);
+        let stride = reps.len() + 2;
+        let fixed = (ascii.len() + NUM_FLAGS) * uint::BYTES
+                    + ranges.len() * mem::size_of::<(char, char, uint)>();
+        let mut dfa = Dfa::new(reverse);
+        dfa.mask = mask;
+        dfa.seen = SparseSet::new(prog.insts.len());
+        // Without a limit, computing a state never fails.
+        let starts = Vec::from_fn(NUM_FLAGS, |flags| {
+            dfa.start_state(flags as u8, uint::MAX).unwrap()
+        });
+        let (mut trans, mut idle) = (vec!(), vec!());
+        let mut si = 0;
+        while si < dfa.states.len() {
+            let rows = dfa.states.len();
+            if fixed + rows * (stride * uint::BYTES + 1) > limit {
+                return Err(DfaError {
+                    msg: format!("The DFA needs more than {} bytes.", limit),
+                    kind: DfaTooLarge,
+                })
+            }
+            for &c in reps.iter() {
+                trans.push(dfa.transition(prog, si, Some(c), false, uint::MAX)
+                              .unwrap());
+            }
+            trans.push(dfa.transition(prog, si, Some('\n'), true, uint::MAX)
+                          .unwrap());
+            trans.push(dfa.transition(prog, si, None, false, uint::MAX)
+                          .unwrap());
+            let st = dfa.states.get(si);
+            idle.push(!st.matched && st.kernel.len() == 0);
+            si += 1;
+        }
+        Ok(FullTables {
+            ascii: ascii,
+            ranges: ranges,
+            stride: stride,
+            trans: trans,
+            starts: starts,
+            idle: idle,
+        })
+    }
+
+    // Returns the number of bytes used by the tables.
+    fn size(&self) -> uint {
+        (self.ascii.len() + self.starts.len() + self.trans.len())
+        * uint::BYTES
+        + self.ranges.len() * mem::size_of::<(char, char, uint)>()
+        + self.idle.len()
+    }
+
+    // Returns the column of the transitions on the character given.
+    fn column(&self, c: char) -> uint {
+        if (c as u32) < 0x80 {
+            return *self.ascii.get(c as uint)
+        }
+        let found = self.ranges.as_slice().bsearch(|&(start, end, _)| {
+            if start > c {
+                Greater
+            } else if end < c {
+                Less
+            } else {
+                Equal
+            }
+        });
+        match found {
+            // Every character is in a range.
+            None => unreachable!(),
+            Some(i) => {
+                let (_, _, class) = *self.ranges.get(i);
+                class
+            }
+        }
+    }
+
+    // Like `Dfa::exec`, except that no state is ever computed.
+    fn exec(&self, prog: &Program, which: MatchKind, input: &str,
+            start: uint, budget: &mut Budget) -> DfaResult {
+        let exists = match which { Exists => true, _ => false };
+        let bytes = input.as_bytes();
+        let eof = self.stride - 1;
+        let mut si = *self.starts.get(prev_flags(input, start) as uint);
+        let (mut i, mut lower) = (start, start);
+        let mut last_match = None;
+        let mut charged = start;
+        loop {
+            if i >= charged + STEP_CHUNK {
+                if !budget.take(i - charged) {
+                    return OutOfSteps
+                }
+                charged = i;
+            }
+            if *self.idle.get(si) {
+                if prog.prefilter.is_some() {
+                    match prog.prefilter.find(bytes.slice_from(i)) {
+                        None => break,
+                        Some(0) => budget.count_candidate(),
+                        Some(j) => {
+                            budget.count_candidate();
+                            i += j;
+                            let flags = prev_flags(input, i);
+                            si = *self.starts.get(flags as uint);
+                        }
+                    }
+                }
+                lower = i;
+            }
+            let (col, next) =
+                if i >= bytes.len() {
+                    (eof, i)
+                } else if bytes[i] == '\n' as u8 && i + 1 == bytes.len() {
+                    (eof - 1, i + 1)
+                } else if bytes[i] < 0x80 {
+                    (*self.ascii.get(bytes[i] as uint), i + 1)
+                } else {
+                    let r = input.char_range_at(i);
+                    (self.column(r.ch), r.next)
+                };
+            let t = *self.trans.get(si * self.stride + col);
+            if t & 1 == 1 {
+                if exists {
+                    if !budget.take(i - charged) {
+                        return OutOfSteps
+                    }
+                    return Matched(lower, i)
+                }
+                last_match = Some(i);
+            }
+            si = t >> 1;
+            if col == eof || si == DEAD {
+                break
+            }
+            i = next;
+        }
+        if !budget.take(i - charged) {
+            return OutOfSteps
+        }
+        match last_match {
+            None => NoMatch,
+            Some(e) => Matched(lower, e),
+        }
+    }
+
+    // Like `Dfa::exec_reverse`, except that no state is ever computed.
+    fn exec_reverse(&self, input: &str, end: uint, floor: uint,
+                    budget: &mut Budget) -> DfaResult {
+        let bytes = input.as_bytes();
+        let eof = self.stride - 1;
+        let mut si = *self.starts.get(next_flags(input, end) as uint);
+        let mut i = end;
+        let mut first_match = None;
+        let mut charged = end;
+        loop {
+            if i + STEP_CHUNK <= charged {
+                if !budget.take(charged - i) {
+                    return OutOfSteps
+                }
+                charged = i;
+            }
+            let (col, next) =
+                if i == 0 {
+                    (eof, 0)
+                } else if bytes[i - 1] == '\n' as u8 && i == bytes.len() {
+                    (eof - 1, i - 1)
+                } else if bytes[i - 1] < 0x80 {
+                    (*self.ascii.get(bytes[i - 1] as uint), i - 1)
+                } else {
+                    let r = input.char_range_at_reverse(i);
+                    (self.column(r.ch), r.next)
+                };
+            let t = *self.trans.get(si * self.stride + col);
+            if t & 1 == 1 {
+                first_match = Some(i);
+            }
+            si = t >> 1;
+            if col == eof || si == DEAD || i <= floor {
+                break
+            }
+            i = next;
+        }
+        if !budget.take(charged - i) {
+            return OutOfSteps
+        }
+        match first_match {
+            None => NoMatch,
+            Some(s) => Matched(s, end),
+        }
+    }
+}
+
+// Returns the bits of the flags that the assertions of the program given
+// look at. Whether the preceding character is the beginning of the input
+// always matters, since `\A` and `\b` look at it.
+fn flag_mask(prog: &Program) -> u8 {
+    prog.insts.iter().fold(PREV_BEGIN, |mask, inst| {
+        mask | match *inst {
+            EmptyBegin(flags) if flags & FLAG_MULTI > 0 => PREV_NL,
+            EmptyBegin(flags) if flags & FLAG_FINALNL > 0 => PREV_FINALNL,
+            EmptyWordBoundary(flags) if flags & FLAG_ASCII > 0 => PREV_AWORD,
+            EmptyWordBoundary(flags) if flags & FLAG_UWORD > 0 => PREV_UWORD,
+            EmptyWordBoundary(_) => PREV_WORD,
+            _ => 0,
+        }
+    })
+}
+
+// Splits the characters into classes that the program given can't tell
+// apart: every character of a class is matched by the same instructions,
+// has the same flags (in `mask`) and is a newline or not. Returns the class
+// of each ASCII character, the classes of the other characters as sorted
+// ranges, and a character of each class.
+//
+// The classes are found by cutting the characters at every bound of a
+// range that an instruction matches (or that the flags depend on), and then
+// merging the pieces that are alike. The ranges of a case insensitive
+// instruction aren't the characters it matches, so its cuts are found by
+// trying every character that can have a different uppercase.
+fn char_classes(prog: &Program, mask: u8)
+               -> (Vec<uint>, Vec<(char, char, uint)>, Vec<char>) {
+    let mut cuts: Vec<u32> = vec!(0, 0x80, '\n' as u32, '\n' as u32 + 1,
+                                  0xD800, 0xE000, MAX_CASED, 0x110000);
+    let mut consuming = vec!();
+    let mut casei = vec!();
+    for inst in prog.insts.iter() {
+        match *inst {
+            OneChar(c, flags) => {
+                push_cuts(&mut cuts, (c, c));
+                if flags & FLAG_NOCASE > 0 {
+                    let upper = c.to_uppercase();
+                    push_cuts(&mut cuts, (upper, upper));
+                    casei.push(inst);
+                }
+            }
+            CharClass(ref set, flags) => {
+                for &(s, e) in set.ranges.iter() {
+                    push_cuts(&mut cuts, (s, e));
+                    if flags & FLAG_NOCASE > 0 {
+                        let upper = (s.to_uppercase(), e.to_uppercase());
+                        push_cuts(&mut cuts, upper);
+                    }
+                }
+                if flags & FLAG_NOCASE > 0 {
+                    casei.push(inst);
+                }
+            }
+            Any(_) => {}
+            _ => continue,
+        }
+        consuming.push(inst);
+    }
+    let mut words = vec!();
+    if mask & (PREV_WORD | PREV_AWORD | PREV_UWORD) > 0 {
+        words.push(ASCII_WORD);
+    }
+    if mask & PREV_WORD > 0 {
+        words.push(PERLW);
+    }
+    if mask & PREV_UWORD > 0 {
+        words.push(UNICODE_WORD);
+    }
+    for table in words.iter() {
+        for &range in table.iter() {
+            push_cuts(&mut cuts, range);
+        }
+    }
+    if casei.len() > 0 {
+        let mut last = vec!();
+        for n in range(0, MAX_CASED) {
+            let c = match char::from_u32(n) {
+                None => continue,
+                Some(c) => c,
+            };
+            let matched: Vec<bool> =
+                casei.iter().map(|inst| vm::char_matches(*inst, Some(c)))
+                     .collect();
+            if matched != last {
+                cuts.push(n);
+                last = matched;
+            }
+        }
+    }
+    cuts.sort();
+    cuts.dedup();
+
+    let mut ids: HashMap<Vec<bool>, uint> = HashMap::new();
+    let mut reps = vec!();
+    let mut ascii = Vec::from_elem(0x80, 0u);
+    let mut ranges: Vec<(char, char, uint)> = vec!();
+    for w in cuts.as_slice().windows(2) {
+        let (s, e) = (w[0], w[1] - 1);
+        let (s, e) = match (char::from_u32(s), char::from_u32(e)) {
+            (Some(s), Some(e)) => (s, e),
+            // Surrogates aren't characters.
+            _ => continue,
+        };
+        let flags = char_flags(s) & mask;
+        let mut key: Vec<bool> =
+            consuming.iter().map(|inst| vm::char_matches(*inst, Some(s)))
+                     .collect();
+        key.push(s == '\n');
+        for bit in range(0, 8) {
+            key.push(flags & (1 << bit) > 0);
+        }
+        let class = match ids.find(&key) {
+            Some(&class) => class,
+            None => reps.len(),
+        };
+        if class == reps.len() {
+            ids.insert(key, class);
+            reps.push(s);
+        }
+        if (s as u32) < 0x80 {
+            for n in range(s as uint, e as uint + 1) {
+                *ascii.get_mut(n) = class;
+            }
+            continue
+        }
+        let extends = match ranges.last() {
+            Some(&(_, end, last)) => {
+                last == class && end as u32 + 1 == s as u32
+            }
+            None => false,
+        };
+        if extends {
+            let (start, _, _) = ranges.pop().unwrap();
+            ranges.push((start, e, class));
+        } else {
+            ranges.push((s, e, class));
+        }
+    }
+    (ascii, ranges, reps)
+}
+
+// Cuts the characters before and after the range given.
+fn push_cuts(cuts: &mut Vec<u32>, (start, end): (char, char)) {
+    cuts.push(start as u32);
+    cuts.push(end as u32 + 1);
+}
+
+// Computes the flags describing the character preceding `start`.
+fn prev_flags(input: &str, start: uint) -> u8 {
+    if start == 0 {
+        return PREV_BEGIN
+    }
+    char_flags(input.char_range_at_reverse(start).ch)
+}
+
+// Computes the flags describing the character following `end`, which is the
+// character "preceding" `end` when scanning backwards.
+fn next_flags(input: &str, end: uint) -> u8 {
+    if end >= input.len() {
+        return PREV_BEGIN
+    }
+    if end + 1 == input.len() && input.as_bytes()[end] == '\n' as u8 {
+        return PREV_NL | PREV_FINALNL
+    }
+    char_flags(input.char_at(end))
+}
+
+// Computes the flags describing a character that precedes a position.
+fn char_flags(c: char) -> u8 {
+    match c {
+        '\n' => PREV_NL,
+        c if vm::is_ascii_word(Some(c)) => {
+            PREV_WORD | PREV_AWORD | PREV_UWORD
+        }
+        c => {
+            let word = if vm::is_word(Some(c)) { PREV_WORD } else { 0 };
+            if vm::is_unicode_word(Some(c)) { word | PREV_UWORD } else { word }
+        }
+    }
+}
+
+// Evaluates a zero-width assertion at a position described by `flags` (the
+// preceding character) and `cur` (the following character, which is a new
+// line that ends the input if `last` is true).
+fn empty_ok(inst: &Inst, flags: u8, cur: Option<char>, last: bool) -> bool {
+    match *inst {
+        // Only reverse programs have these (for `\Z`).
+        EmptyBegin(iflags) if iflags & FLAG_FINALNL > 0 => {
+            flags & (PREV_BEGIN | PREV_FINALNL) > 0
+        }
+        EmptyBegin(iflags) => {
+            flags & PREV_BEGIN > 0
+            || (iflags & FLAG_MULTI > 0 && flags & PREV_NL > 0)
+        }
+        EmptyEnd(iflags) if iflags & FLAG_FINALNL > 0 => {
+            cur.is_none() || last
+        }
+        EmptyEnd(iflags) => {
+            cur.is_none() || (iflags & FLAG_MULTI > 0 && cur == Some('\n'))
+        }
+        EmptyWordBoundary(iflags) if iflags & FLAG_ASCII > 0 => {
+            let boundary = (flags & PREV_AWORD > 0) != vm::is_ascii_word(cur);
+            boundary == !(iflags & FLAG_NEGATED > 0)
+        }
+        EmptyWordBoundary(iflags) if iflags & FLAG_UWORD > 0 => {
+            let boundary =
+                (flags & PREV_UWORD > 0) != vm::is_unicode_word(cur);
+            boundary == !(iflags & FLAG_NEGATED > 0)
+        }
+        EmptyWordBoundary(iflags) => {
+            let boundary =
+                if flags & PREV_BEGIN > 0 {
+                    vm::is_word(cur)
+                } else if cur.is_none() {
+                    flags & PREV_WORD > 0
+                } else {
+                    (flags & PREV_WORD > 0) != vm::is_word(cur)
+                };
+            boundary == !(iflags & FLAG_NEGATED > 0)
+        }
+        _ => false,
+    }
+}
+
+// A sparse set of instruction indices. See vm.rs (and
+// http://research.swtch.com/sparse) for the trick.
+struct SparseSet {
+    dense: Vec<uint>,
+    sparse: Vec<uint>,
+    size: uint,
+}
+
+impl SparseSet {
+    fn new(capacity: uint) -> SparseSet {
+        SparseSet {
+            dense: Vec::from_elem(capacity, 0u),
+            sparse: Vec::from_elem(capacity, 0u),
+            size: 0,
+        }
+    }
+
+    #[inline]
+    fn capacity(&self) -> uint {
+        self.dense.len()
+    }
+
+    #[inline]
+    fn contains(&self, x: uint) -> bool {
+        let s = *self.sparse.get(x);
+        s < self.size && *self.dense.get(s) == x
+    }
+
+    #[inline]
+    fn insert(&mut self, x: uint) {
+        *self.dense.get_mut(self.size) = x;
+        *self.sparse.get_mut(x) = self.size;
+        self.size += 1;
+    }
+
+    #[inline]
+    fn clear(&mut self) {
+        self.size = 0;
+    }
+}
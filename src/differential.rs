@@ -0,0 +1,305 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module checks that every way of running a search finds the same
+// matches. The NFA simulation is the reference, since it's the simplest
+// engine and it handles every program (except ones with lookaround or
+// backreferences, which every engine leaves to the backtracker). The other
+// engines are forced by the limits a regex has: a DFA size limit of zero
+// disables the DFA, and a backtrack limit of zero disables the backtracker
+// (or, when it's as big as can be, runs it on every text).
+//
+// Patterns are generated from a small grammar that favors the constructs
+// that engines tend to disagree on: empty-width assertions, repetitions of
+// groups that can match the empty string, alternates that share a prefix,
+// and Unicode classes and case folding.
+
+use std::fmt;
+use std::str;
+use std::uint;
+
+use parse;
+use re::Regex;
+
+/// The ways of running a search that are compared with the NFA simulation.
+#[deriving(Clone, Eq, Show)]
+pub enum Engine {
+    /// The bounded backtracker, for every text.
+    EngineBacktrack,
+    /// Every engine but the backtracker, so that the lazy DFA finds where
+    /// matches are.
+    EngineDfa,
+    /// Whichever engines a regex picks with its default limits.
+    EngineDefault,
+    /// A regex compiled from the canonical form of the pattern (see
+    /// `Regex::canonical`), with its default limits.
+    EngineCanonical,
+}
+
+static ENGINES: &'static [Engine] = &[
+    EngineBacktrack, EngineDfa, EngineDefault, EngineCanonical,
+];
+
+/// A divergence is a search for which an engine finds different matches than
+/// the NFA simulation.
+#[deriving(Clone)]
+pub struct Divergence {
+    /// The pattern searched for.
+    pub pattern: ~str,
+    /// The text searched.
+    pub text: ~str,
+    /// The engine that disagrees.
+    pub engine: Engine,
+    /// The locations of the capture groups of every match found by the NFA
+    /// simulation, as in `Captures::iter_pos`.
+    pub expected: Vec<Vec<Option<(uint, uint)>>>,
+    /// The locations found by the engine.
+    pub got: Vec<Vec<Option<(uint, uint)>>>,
+}
+
+impl fmt::Show for Divergence {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "{} disagrees with the NFA on /{}/ against {}: \
+                       expected {}, but got {}",
+               self.engine, self.pattern, self.text.escape_default(),
+               self.expected, self.got)
+    }
+}
+
+/// A harness runs searches with every engine, and reports the first one that
+/// disagrees with the NFA simulation.
+///
+/// Divergences that are known (and intended) can be allowed, so that a
+/// harness run by a test or a fuzzer only fails for new ones.
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::difftest::{Harness, Generator};
+/// let harness = Harness::new();
+/// assert!(harness.check(r"(a|ab)(c|bcd)(d*)", "abcd").is_ok());
+///
+/// let mut gen = Generator::new(42);
+/// match harness.run(&mut gen, 100) {
+///     Ok(_) => {}
+///     Err(div) => fail!("{}", div),
+/// }
+/// ```
+pub struct Harness {
+    allowed: Vec<(Engine, Regex)>,
+}
+
+impl Harness {
+    /// Creates a harness that allows no divergences.
+    pub fn new() -> Harness {
+        Harness { allowed: vec!() }
+    }
+
+    /// Allows `engine` to diverge on every pattern that `pattern` matches
+    /// somewhere in. (It's a regex that's searched for in the patterns,
+    /// not in the texts.) An error is returned if it's invalid.
+    pub fn allow(&mut self, engine: Engine, pattern: &str)
+                -> Result<(), parse::Error> {
+        let re = try!(Regex::new(pattern));
+        self.allowed.push((engine, re));
+        Ok(())
+    }
+
+    /// Searches for every match of `pattern` in `text` with every engine,
+    /// and returns the first divergence that isn't allowed. A pattern that
+    /// doesn't compile (e.g., a generated one that's invalid) has no
+    /// divergences.
+    pub fn check(&self, pattern: &str, text: &str) -> Result<(), Divergence> {
+        let mut nfa = match Regex::new(pattern) {
+            Ok(re) => re,
+            Err(_) => return Ok(()),
+        };
+        nfa.set_dfa_size_limit(0);
+        nfa.set_backtrack_limit(0);
+        let expected = locations(&nfa, text);
+        for &engine in ENGINES.iter() {
+            if self.allowed.iter().any(|&(e, ref re)| {
+                e == engine && re.is_match(pattern)
+            }) {
+                continue
+            }
+            let got = locations(&compile(pattern, engine), text);
+            if got != expected {
+                return Err(Divergence {
+                    pattern: pattern.to_owned(),
+                    text: text.to_owned(),
+                    engine: engine,
+                    expected: expected,
+                    got: got,
+                })
+            }
+        }
+        Ok(())
+    }
+
+    /// Checks the pattern and text given by the bytes of a fuzzer's input:
+    /// the pattern is the bytes up to the first NUL byte, and the text is
+    /// the rest. Input that isn't UTF-8 has no divergences.
+    pub fn fuzz(&self, data: &[u8]) -> Result<(), Divergence> {
+        let (pattern, text) = match data.iter().position(|&b| b == 0) {
+            None => return Ok(()),
+            Some(i) => (data.slice_to(i), data.slice_from(i + 1)),
+        };
+        match (str::from_utf8(pattern), str::from_utf8(text)) {
+            (Some(pattern), Some(text)) => self.check(pattern, text),
+            _ => Ok(()),
+        }
+    }
+
+    /// Checks `patterns` patterns from the generator given, each against a
+    /// few of its texts. The number of searches compared is returned, or
+    /// the first divergence.
+    pub fn run(&self, gen: &mut Generator, patterns: uint)
+              -> Result<uint, Divergence> {
+        let mut searches = 0;
+        for _ in range(0, patterns) {
+            let pattern = gen.pattern();
+            for _ in range(0, TEXTS_PER_PATTERN) {
+                let text = gen.text();
+                try!(self.check(pattern.as_slice(), text.as_slice()));
+                searches += 1;
+            }
+        }
+        Ok(searches)
+    }
+}
+
+// Compiles an (already checked) pattern so that searches use the engine
+// given.
+fn compile(pattern: &str, engine: Engine) -> Regex {
+    let mut re = Regex::new(pattern).unwrap();
+    match engine {
+        EngineBacktrack => {
+            re.set_dfa_size_limit(0);
+            re.set_backtrack_limit(uint::MAX);
+        }
+        EngineDfa => re.set_backtrack_limit(0),
+        EngineDefault => {}
+        EngineCanonical => re = Regex::new(re.canonical().as_slice()).unwrap(),
+    }
+    re
+}
+
+fn locations(re: &Regex, text: &str) -> Vec<Vec<Option<(uint, uint)>>> {
+    re.captures_iter(text).map(|caps| caps.iter_pos().collect()).collect()
+}
+
+// Mixed into seeds, so that small seeds don't start with a state that has
+// few bits set (which takes a few steps to look random).
+static SEED_MASK: u64 = 0x2545F4914F6CDD1D;
+
+// The number of texts that each generated pattern is checked against.
+static TEXTS_PER_PATTERN: uint = 4;
+
+// The deepest that generated groups are nested.
+static MAX_DEPTH: uint = 3;
+
+// The longest generated text, in characters.
+static MAX_TEXT_LEN: uint = 12;
+
+static ATOMS: &'static [&'static str] = &[
+    "a", "b", "ab", "é", "δ", "(?i)δ", "(?i)k", "[ab]", "[^a]", "[a-cé]",
+    ".", "(?s:.)", r"\w", r"\d", r"\s", r"\W", r"\pL", r"\p{Greek}", r"\.",
+];
+
+static ASSERTIONS: &'static [&'static str] = &[
+    "^", "$", r"\A", r"\z", r"\b", r"\B", "(?m:^)", "(?m:$)",
+];
+
+static REPEATS: &'static [&'static str] = &[
+    "*", "+", "?", "*?", "+?", "??", "{2}", "{0,2}", "{1,}",
+];
+
+static GROUPS: &'static [&'static str] = &["(", "(", "(?:", "(?i:", "(?s:"];
+
+static TEXT_CHARS: &'static [char] = &[
+    'a', 'b', 'c', 'a', 'b', ' ', '\n', '.', '1', '_', 'é', 'δ', 'Δ', 'K',
+];
+
+/// A generator produces patterns and texts to search, from a seed. The same
+/// seed always produces the same ones, so a divergence found with a seed can
+/// be found again.
+pub struct Generator {
+    state: u64,
+}
+
+impl Generator {
+    /// Creates a generator from the seed given.
+    pub fn new(seed: u64) -> Generator {
+        // The state of a xorshift generator must not be zero.
+        let state = seed ^ SEED_MASK;
+        Generator { state: if state == 0 { SEED_MASK } else { state } }
+    }
+
+    /// Returns the next pattern, which may be invalid (e.g., when it repeats
+    /// an assertion).
+    pub fn pattern(&mut self) -> ~str {
+        let mut out = StrBuf::new();
+        self.alternates(&mut out, 0);
+        out.into_owned()
+    }
+
+    /// Returns the next text to search.
+    pub fn text(&mut self) -> ~str {
+        let mut out = StrBuf::new();
+        for _ in range(0, self.below(MAX_TEXT_LEN + 1)) {
+            out.push_char(TEXT_CHARS[self.below(TEXT_CHARS.len())]);
+        }
+        out.into_owned()
+    }
+
+    fn alternates(&mut self, out: &mut StrBuf, depth: uint) {
+        let n = if self.below(3) == 0 { 2 } else { 1 };
+        for i in range(0, n) {
+            if i > 0 {
+                out.push_char('|');
+            }
+            for _ in range(0, 1 + self.below(3)) {
+                self.item(out, depth);
+            }
+        }
+    }
+
+    fn item(&mut self, out: &mut StrBuf, depth: uint) {
+        match self.below(8) {
+            0 => {
+                // Assertions aren't repeated, since that's an error.
+                out.push_str(self.pick(ASSERTIONS));
+                return
+            }
+            1 | 2 if depth < MAX_DEPTH => {
+                out.push_str(self.pick(GROUPS));
+                self.alternates(out, depth + 1);
+                out.push_char(')');
+            }
+            _ => out.push_str(self.pick(ATOMS)),
+        }
+        if self.below(3) == 0 {
+            out.push_str(self.pick(REPEATS));
+        }
+    }
+
+    fn pick(&mut self, choices: &[&'static str]) -> &'static str {
+        choices[self.below(choices.len())]
+    }
+
+    // Returns a number that's less than `n`.
+    fn below(&mut self, n: uint) -> uint {
+        self.state ^= self.state << 13;
+        self.state ^= self.state >> 7;
+        self.state ^= self.state << 17;
+        (self.state % (n as u64)) as uint
+    }
+}
@@ -0,0 +1,818 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// Compiled regexes (and sets) can be encoded to bytes and decoded again
+// later, which saves parsing and compiling them (e.g., when thousands of
+// expressions are compiled once by a build step and loaded at run time).
+//
+// An encoding starts with a magic number, the version of the format and what
+// kind of value is encoded, and ends with an FNV-1a hash of everything before
+// it. Everything in between is the compiled program (and the reverse program
+// used by the DFA), the literals of its prefilter, the names of its capture
+// groups and its limits. Integers are 8 bytes, little endian, and strings
+// and sequences are preceded by their length.
+//
+// Whatever can be derived cheaply from the program isn't encoded, but
+// derived again when it's decoded: the scanners of the prefilter, the masks
+// of the bit-parallel matcher, the one-pass analysis, whether the program
+// uses the features that only some engines can evaluate and how far back its
+// lookbehinds look. (So a corrupt encoding can't claim, say, that a program
+// with lookaround can be run by the DFA.)
+//
+// The version must be incremented whenever the format or the meaning of the
+// instructions changes. Bytes encoded by any other version are rejected,
+// and so are bytes whose hash doesn't match. Since a hash can't rule out
+// bytes that were crafted, every program decoded is also checked: each
+// instruction that jumps to another one (or that is followed by another one)
+// must stay within the program, and there must be a name for every group
+// that it saves or refers to, so that no engine can index out of bounds.
+// Nor may it loop without consuming anything or going through a `Split`
+// (which the engines would follow forever), run from a lookaround into the
+// rest of the program (or the other way around) or have a lookbehind that
+// isn't bounded. The reverse program must also be one that the DFA can run,
+// when the program is.
+
+use std::char;
+use std::fmt;
+use std::str;
+use std::uint;
+
+use charset::CharSet;
+use compile;
+use compile::{
+    Program, Inst,
+    Match, OneChar, CharClass, Any, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    EmptySegmentBoundary, EmptyLook, Cut, LookMatch, GroupRef, Save, Jump,
+    Split,
+};
+use literals::Prefilter;
+use parse;
+use re;
+use re::{Regex, Dynamic, Native};
+use segment::{Grapheme, Word};
+use shiftor::ShiftOr;
+
+/// The version of the format written by this crate. It's the only version
+/// that can be decoded.
+static VERSION: u32 = 4;
+
+/// The first bytes of every encoding ("RGXB").
+static MAGIC: [u8, ..4] = [0x52, 0x47, 0x58, 0x42];
+
+/// The kinds of values that can be encoded.
+pub static KIND_REGEX: u8 = 1;
+pub static KIND_SET: u8 = 2;
+
+/// DecodeError describes bytes that can't be decoded to a compiled regex.
+#[deriving(Clone)]
+pub struct DecodeError {
+    /// A message describing the error.
+    pub msg: ~str,
+    /// What went wrong.
+    pub kind: DecodeErrorKind,
+}
+
+/// DecodeErrorKind tells bytes encoded by an incompatible version of this
+/// crate apart from bytes that aren't a valid encoding.
+#[deriving(Clone, Eq, Show)]
+pub enum DecodeErrorKind {
+    /// The bytes were encoded with a version of the format that this version
+    /// of the crate can't decode. Compiling the expression again (and
+    /// encoding the result) fixes this.
+    UnsupportedVersion,
+    /// The bytes aren't an encoding of the kind of value asked for, or
+    /// they're corrupt.
+    InvalidEncoding,
+}
+
+impl fmt::Show for DecodeError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "Regex decoding error: {}", self.msg)
+    }
+}
+
+/// Returns an error for an invalid encoding.
+pub fn invalid<T>(msg: &str) -> Result<T, DecodeError> {
+    Err(DecodeError { msg: msg.to_owned(), kind: InvalidEncoding })
+}
+
+/// Encoder accumulates the bytes of an encoding.
+pub struct Encoder {
+    buf: Vec<u8>,
+}
+
+impl Encoder {
+    /// Starts an encoding of the kind of value given.
+    pub fn new(kind: u8) -> Encoder {
+        let mut e = Encoder { buf: Vec::with_capacity(1024) };
+        e.buf.push_all(MAGIC.as_slice());
+        for i in range(0, 4) {
+            e.buf.push((VERSION >> (8 * i)) as u8);
+        }
+        e.buf.push(kind);
+        e
+    }
+
+    /// Finishes the encoding and returns its bytes.
+    pub fn unwrap(self) -> Vec<u8> {
+        let Encoder { mut buf } = self;
+        let hash = fnv(buf.as_slice());
+        for i in range(0, 8) {
+            buf.push((hash >> (8 * i)) as u8);
+        }
+        buf
+    }
+
+    pub fn write_u8(&mut self, n: u8) {
+        self.buf.push(n);
+    }
+
+    pub fn write_bool(&mut self, yes: bool) {
+        self.buf.push(if yes { 1 } else { 0 });
+    }
+
+    pub fn write_u64(&mut self, n: u64) {
+        for i in range(0, 8) {
+            self.buf.push((n >> (8 * i)) as u8);
+        }
+    }
+
+    pub fn write_uint(&mut self, n: uint) {
+        self.write_u64(n as u64);
+    }
+
+    pub fn write_flags(&mut self, flags: parse::Flags) {
+        self.buf.push(flags as u8);
+        self.buf.push((flags >> 8) as u8);
+    }
+
+    pub fn write_char(&mut self, c: char) {
+        self.write_uint(c as uint);
+    }
+
+    pub fn write_bytes(&mut self, bytes: &[u8]) {
+        self.write_uint(bytes.len());
+        self.buf.push_all(bytes);
+    }
+
+    pub fn write_str(&mut self, s: &str) {
+        self.write_bytes(s.as_bytes());
+    }
+
+    pub fn write_option_uint(&mut self, n: Option<uint>) {
+        match n {
+            None => self.write_bool(false),
+            Some(n) => {
+                self.write_bool(true);
+                self.write_uint(n);
+            }
+        }
+    }
+}
+
+/// Decoder reads the values of an encoding in the order they were written.
+pub struct Decoder<'a> {
+    bytes: &'a [u8],
+    pos: uint,
+}
+
+impl<'a> Decoder<'a> {
+    /// Checks the header and the hash of an encoding of the kind of value
+    /// given, and returns a decoder for what's in between.
+    pub fn new(bytes: &'a [u8], kind: u8)
+              -> Result<Decoder<'a>, DecodeError> {
+        let header = MAGIC.len() + 4 + 1;
+        if bytes.len() < header + 8
+           || bytes.slice_to(MAGIC.len()) != MAGIC.as_slice() {
+            return invalid("The bytes aren't an encoded regex.")
+        }
+        let mut version = 0u32;
+        for i in range(0, 4) {
+            version |= bytes[MAGIC.len() + i] as u32 << (8 * i);
+        }
+        if version != VERSION {
+            return Err(DecodeError {
+                msg: format!("The regex was encoded with version {} of the \
+                              format, but only version {} can be decoded.",
+                             version, VERSION),
+                kind: UnsupportedVersion,
+            })
+        }
+        let end = bytes.len() - 8;
+        let mut hash = 0u64;
+        for i in range(0, 8) {
+            hash |= bytes[end + i] as u64 << (8 * i);
+        }
+        if hash != fnv(bytes.slice_to(end)) {
+            return invalid("The encoded regex is corrupt.")
+        }
+        let found = bytes[header - 1];
+        if found != kind {
+            let name = |k: u8| {
+                if k == KIND_REGEX {
+                    "a Regex"
+                } else if k == KIND_SET {
+                    "a RegexSet"
+                } else {
+                    "an unknown value"
+                }
+            };
+            let msg = format!("The bytes encode {}, not {}.",
+                              name(found), name(kind));
+            return invalid(msg.as_slice())
+        }
+        Ok(Decoder { bytes: bytes.slice(header, end), pos: 0 })
+    }
+
+    /// Returns an error unless every value has been read.
+    pub fn finish(&self) -> Result<(), DecodeError> {
+        if self.pos < self.bytes.len() {
+            return invalid("The encoded regex has trailing bytes.")
+        }
+        Ok(())
+    }
+
+    fn take(&mut self, n: uint) -> Result<&'a [u8], DecodeError> {
+        if n > self.bytes.len() - self.pos {
+            return invalid("The encoded regex is truncated.")
+        }
+        let taken = self.bytes.slice(self.pos, self.pos + n);
+        self.pos += n;
+        Ok(taken)
+    }
+
+    pub fn read_u8(&mut self) -> Result<u8, DecodeError> {
+        Ok(try!(self.take(1))[0])
+    }
+
+    pub fn read_bool(&mut self) -> Result<bool, DecodeError> {
+        match try!(self.read_u8()) {
+            0 => Ok(false),
+            1 => Ok(true),
+            _ => invalid("The encoded regex has an invalid boolean."),
+        }
+    }
+
+    pub fn read_u64(&mut self) -> Result<u64, DecodeError> {
+        let bytes = try!(self.take(8));
+        let mut n = 0u64;
+        for (i, &b) in bytes.iter().enumerate() {
+            n |= b as u64 << (8 * i);
+        }
+        Ok(n)
+    }
+
+    pub fn read_uint(&mut self) -> Result<uint, DecodeError> {
+        let n = try!(self.read_u64());
+        if n > uint::MAX as u64 {
+            return invalid("The encoded regex has an integer that's too big.")
+        }
+        Ok(n as uint)
+    }
+
+    /// Reads the length of a sequence, each of whose elements is encoded in
+    /// at least one byte. (So an absurd length can't be used to allocate an
+    /// absurd amount of memory.)
+    pub fn read_len(&mut self) -> Result<uint, DecodeError> {
+        let n = try!(self.read_uint());
+        if n > self.bytes.len() - self.pos {
+            return invalid("The encoded regex is truncated.")
+        }
+        Ok(n)
+    }
+
+    pub fn read_flags(&mut self) -> Result<parse::Flags, DecodeError> {
+        let bytes = try!(self.take(2));
+        Ok(bytes[0] as parse::Flags | (bytes[1] as parse::Flags << 8))
+    }
+
+    pub fn read_char(&mut self) -> Result<char, DecodeError> {
+        let n = try!(self.read_uint());
+        if n > 0x10FFFF {
+            return invalid("The encoded regex has an invalid character.")
+        }
+        match char::from_u32(n as u32) {
+            None => invalid("The encoded regex has an invalid character."),
+            Some(c) => Ok(c),
+        }
+    }
+
+    pub fn read_bytes(&mut self) -> Result<Vec<u8>, DecodeError> {
+        let n = try!(self.read_len());
+        Ok(Vec::from_slice(try!(self.take(n))))
+    }
+
+    pub fn read_str(&mut self) -> Result<~str, DecodeError> {
+        let n = try!(self.read_len());
+        match str::from_utf8(try!(self.take(n))) {
+            None => invalid("The encoded regex has a string that isn't UTF8."),
+            Some(s) => Ok(s.to_owned()),
+        }
+    }
+
+    pub fn read_option_uint(&mut self) -> Result<Option<uint>, DecodeError> {
+        if try!(self.read_bool()) {
+            Ok(Some(try!(self.read_uint())))
+        } else {
+            Ok(None)
+        }
+    }
+}
+
+// The 64 bit FNV-1a hash of the bytes given.
+fn fnv(bytes: &[u8]) -> u64 {
+    let mut hash = 0xcbf29ce484222325u64;
+    for &b in bytes.iter() {
+        hash ^= b as u64;
+        hash *= 0x100000001b3;
+    }
+    hash
+}
+
+/// Encodes a regex. A native regex (compiled by `regex!`) has no program to
+/// encode, so its expression is compiled again to get one.
+pub fn encode_regex(re: &Regex) -> Vec<u8> {
+    match re.p {
+        Dynamic(ref prog) => encode_compiled(re, re, &**prog),
+        Native(_) => {
+            // The expression was compiled successfully before.
+            let compiled = Regex::new(re.original.as_slice()).unwrap();
+            match compiled.p {
+                Dynamic(ref prog) => encode_compiled(re, &compiled, &**prog),
+                Native(_) => unreachable!(),
+            }
+        }
+    }
+}
+
+// Encodes a regex given the dynamic regex that it was compiled to (which is
+// itself, unless it's native) and its program. The limits are the regex's.
+fn encode_compiled(re: &Regex, compiled: &Regex, prog: &Program) -> Vec<u8> {
+    let mut e = Encoder::new(KIND_REGEX);
+    e.write_str(re.original.as_slice());
+    encode_names(&mut e, compiled.names.as_slice());
+    encode_program(&mut e, prog);
+    match compiled.dfa.reverse() {
+        None => e.write_bool(false),
+        Some(rprog) => {
+            e.write_bool(true);
+            encode_program(&mut e, rprog);
+        }
+    }
+    e.write_uint(re.dfa.size_limit());
+    e.write_uint(re.dfa.backtrack_limit());
+    e.write_option_uint(re.dfa.step_limit());
+    e.write_bool(re.dfa.longest());
+    e.write_bool(re.dfa.posix());
+    e.unwrap()
+}
+
+/// Decodes a regex encoded by `encode_regex`.
+pub fn decode_regex(bytes: &[u8]) -> Result<Regex, DecodeError> {
+    let mut d = try!(Decoder::new(bytes, KIND_REGEX));
+    let original = try!(d.read_str());
+    let names = try!(decode_names(&mut d));
+    let prog = try!(decode_program(&mut d, names.len(), &[0]));
+    let rprog =
+        if try!(d.read_bool()) {
+            Some(try!(decode_program(&mut d, names.len(), &[0])))
+        } else {
+            None
+        };
+    match rprog {
+        Some(ref rprog) if dfa_runs(&prog) && !dfa_runs(rprog) => {
+            return invalid("The encoded reverse program can't be run by the \
+                            DFA.")
+        }
+        _ => {}
+    }
+    let mut re = re::from_program(original, names, prog, rprog);
+    re.dfa.set_size_limit(try!(d.read_uint()));
+    re.dfa.set_backtrack_limit(try!(d.read_uint()));
+    re.dfa.set_step_limit(try!(d.read_option_uint()));
+    re.dfa.set_longest(try!(d.read_bool()));
+    re.dfa.set_posix(try!(d.read_bool()));
+    try!(d.finish());
+    Ok(re)
+}
+
+// Returns true if the DFA can run the program given (as long as the search
+// doesn't need to find the leftmost-longest match), so that its reverse
+// program is run too.
+fn dfa_runs(prog: &Program) -> bool {
+    !prog.search_start && !prog.segments && !prog.backtrack_only()
+}
+
+/// Encodes the names of the capture groups of an expression.
+pub fn encode_names(e: &mut Encoder, names: &[Option<~str>]) {
+    e.write_uint(names.len());
+    for name in names.iter() {
+        match *name {
+            None => e.write_bool(false),
+            Some(ref name) => {
+                e.write_bool(true);
+                e.write_str(name.as_slice());
+            }
+        }
+    }
+}
+
+/// Decodes the names of the capture groups of an expression.
+pub fn decode_names(d: &mut Decoder) -> Result<~[Option<~str>], DecodeError> {
+    let n = try!(d.read_len());
+    let mut names = Vec::with_capacity(n);
+    for _ in range(0, n) {
+        if try!(d.read_bool()) {
+            names.push(Some(try!(d.read_str())));
+        } else {
+            names.push(None);
+        }
+    }
+    Ok(names.as_slice().into_owned())
+}
+
+/// Encodes a program, except for what can be derived from its instructions.
+pub fn encode_program(e: &mut Encoder, prog: &Program) {
+    e.write_uint(prog.insts.len());
+    for inst in prog.insts.iter() {
+        encode_inst(e, inst);
+    }
+    e.write_str(prog.prefix.as_slice());
+    prog.prefilter.encode(e);
+    match prog.shiftor {
+        None => e.write_bool(false),
+        Some(ref so) => {
+            e.write_bool(true);
+            so.encode(e);
+        }
+    }
+    e.write_uint(prog.branches.len());
+    for &(pc, alt) in prog.branches.iter() {
+        e.write_uint(pc);
+        e.write_uint(alt);
+    }
+}
+
+/// Decodes a program encoded by `encode_program`, whose expressions have
+/// at most `ncaps` groups and start at the instructions given.
+///
+/// The instructions are checked before anything is derived from them:
+/// every instruction that jumps to another one (or that's followed by
+/// another one) must stay within the program, every expression must start
+/// by saving the start of its match, and no instruction may save or refer
+/// to a group beyond the last one. The program may not loop without
+/// consuming anything or going through a `Split`, every lookaround must
+/// end with a `LookMatch` and the expressions with a `Match`, and every
+/// lookbehind must be bounded. The alternates of its `Match` instructions
+/// (see `Program::branches`), if it has them, must be given for every one
+/// of them in order.
+pub fn decode_program(d: &mut Decoder, ncaps: uint, starts: &[uint])
+                     -> Result<Program, DecodeError> {
+    let n = try!(d.read_len());
+    let mut insts = Vec::with_capacity(n);
+    let mut classes = 0;
+    for _ in range(0, n) {
+        let inst = try!(decode_inst(d));
+        match inst {
+            CharClass(ref set, _) => classes += set.heap_size(),
+            _ => {}
+        }
+        insts.push(inst);
+    }
+    try!(check_targets(insts.as_slice()));
+    try!(check_groups(insts.as_slice(), ncaps, starts));
+    try!(check_loops(insts.as_slice()));
+    try!(check_lookarounds(insts.as_slice(), starts));
+    if compile::max_behind(insts.as_slice()).is_none() {
+        return invalid("The encoded program has an unbounded lookbehind.")
+    }
+    let prefix = try!(d.read_str());
+    let prefilter = try!(Prefilter::decode(d));
+    let shiftor =
+        if try!(d.read_bool()) {
+            Some(try!(ShiftOr::decode(d)))
+        } else {
+            None
+        };
+    let n = try!(d.read_len());
+    let mut branches = Vec::with_capacity(n);
+    for _ in range(0, n) {
+        let pc = try!(d.read_uint());
+        branches.push((pc, try!(d.read_uint())));
+    }
+    try!(check_branches(insts.as_slice(), branches.as_slice()));
+    Ok(Program::from_parts(insts, starts, prefix, prefilter, shiftor,
+                           branches, classes))
+}
+
+// Checks that the alternates of the `Match` instructions, when there are
+// any, are given for every one of them and in the order of the program.
+fn check_branches(insts: &[Inst], branches: &[(uint, uint)])
+                 -> Result<(), DecodeError> {
+    if branches.len() == 0 {
+        return Ok(())
+    }
+    let matches = insts.iter().enumerate().filter_map(|(pc, inst)| {
+        match *inst { Match => Some(pc), _ => None }
+    });
+    let mut listed = branches.iter().map(|&(pc, _)| pc);
+    for pc in matches {
+        if listed.next() != Some(pc) {
+            return invalid("The encoded program has a match that isn't in \
+                            its alternates.")
+        }
+    }
+    if listed.next().is_some() {
+        return invalid("The encoded program has an alternate that doesn't \
+                        end with a match.")
+    }
+    Ok(())
+}
+
+// Checks that every expression starts by saving the start of its match
+// (and is followed by another instruction, which the engines look at), and
+// that no instruction saves or refers to a group beyond the last one.
+fn check_groups(insts: &[Inst], ncaps: uint, starts: &[uint])
+               -> Result<(), DecodeError> {
+    for &start in starts.iter() {
+        match insts.get(start) {
+            Some(&Save(0)) if start + 1 < insts.len() => {}
+            _ => return invalid("The encoded program doesn't start a match."),
+        }
+    }
+    for inst in insts.iter() {
+        match *inst {
+            Save(slot) if slot / 2 >= ncaps => {
+                return invalid("The encoded program saves an unknown group.")
+            }
+            GroupRef(group, _) if group >= ncaps => {
+                return invalid("The encoded program refers to an unknown \
+                                group.")
+            }
+            _ => {}
+        }
+    }
+    Ok(())
+}
+
+// Checks that every instruction jumps to (or is followed by) an instruction
+// in the program.
+fn check_targets(insts: &[Inst]) -> Result<(), DecodeError> {
+    for (pc, inst) in insts.iter().enumerate() {
+        let (next, targets) = match *inst {
+            Match | LookMatch => (false, (None, None)),
+            Jump(to) => (false, (Some(to), None)),
+            Split(x, y) => (false, (Some(x), Some(y))),
+            EmptyLook(to, _) | Cut(to) => (true, (Some(to), None)),
+            _ => (true, (None, None)),
+        };
+        let (x, y) = targets;
+        let outside = |to: Option<uint>| {
+            match to {
+                None => false,
+                Some(to) => to >= insts.len(),
+            }
+        };
+        if (next && pc + 1 >= insts.len()) || outside(x) || outside(y) {
+            let msg = format!("The encoded program jumps outside of itself \
+                               (at instruction {}).", pc);
+            return invalid(msg.as_slice())
+        }
+    }
+    Ok(())
+}
+
+// Checks that no instruction can come back to itself without consuming
+// anything or going through a `Split`. Every loop compiled from a repetition
+// goes through one, which the engines only follow once at each position.
+fn check_loops(insts: &[Inst]) -> Result<(), DecodeError> {
+    // The instructions that an instruction is followed by without consuming
+    // anything or splitting. (A lookaround or an atomic group is followed
+    // by both its expression and what comes after it.)
+    let next = |pc: uint| -> Vec<uint> {
+        match *insts.get(pc).unwrap() {
+            Jump(to) => vec!(to),
+            Save(_) | EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_)
+            | EmptySegmentBoundary(_, _) | GroupRef(_, _) => vec!(pc + 1),
+            EmptyLook(to, _) | Cut(to) => vec!(pc + 1, to),
+            _ => vec!(),
+        }
+    };
+    // Whether each instruction hasn't been seen yet, is on the path being
+    // followed or has been checked.
+    static UNSEEN: u8 = 0;
+    static ON_PATH: u8 = 1;
+    static CHECKED: u8 = 2;
+    let mut seen = Vec::from_elem(insts.len(), UNSEEN);
+    for start in range(0, insts.len()) {
+        if *seen.get(start) != UNSEEN {
+            continue
+        }
+        // Each instruction on the path, with how many of the instructions
+        // it's followed by have been followed.
+        let mut path = vec!((start, 0u));
+        *seen.get_mut(start) = ON_PATH;
+        while path.len() > 0 {
+            let last = path.len() - 1;
+            let (pc, i) = *path.get(last);
+            let succs = next(pc);
+            if i == succs.len() {
+                *seen.get_mut(pc) = CHECKED;
+                path.pop();
+                continue
+            }
+            *path.get_mut(last) = (pc, i + 1);
+            let to = *succs.get(i);
+            if *seen.get(to) == ON_PATH {
+                let msg = format!("The encoded program loops without \
+                                   consuming anything (at instruction {}).",
+                                  to);
+                return invalid(msg.as_slice())
+            } else if *seen.get(to) == UNSEEN {
+                *seen.get_mut(to) = ON_PATH;
+                path.push((to, 0));
+            }
+        }
+    }
+    Ok(())
+}
+
+// Checks that the instructions of each lookaround (and atomic group) can
+// only reach the `LookMatch` that ends it, and that those of the expressions
+// can only reach a `Match`, so that no instruction belongs to both.
+fn check_lookarounds(insts: &[Inst], starts: &[uint])
+                    -> Result<(), DecodeError> {
+    // The first instruction of the lookaround that each instruction belongs
+    // to, or `uint::MAX` for the expressions.
+    let mut owner: Vec<Option<uint>> = Vec::from_elem(insts.len(), None);
+    let mut stack: Vec<(uint, uint)> =
+        starts.iter().map(|&start| (start, uint::MAX)).collect();
+    while stack.len() > 0 {
+        let (pc, look) = stack.pop().unwrap();
+        match *owner.get(pc) {
+            Some(other) if other == look => continue,
+            Some(_) => {
+                let msg = format!("The encoded program's lookarounds \
+                                   overlap (at instruction {}).", pc);
+                return invalid(msg.as_slice())
+            }
+            None => *owner.get_mut(pc) = Some(look),
+        }
+        match *insts.get(pc).unwrap() {
+            Match if look != uint::MAX => {
+                return invalid("The encoded program matches inside a \
+                                lookaround.")
+            }
+            LookMatch if look == uint::MAX => {
+                return invalid("The encoded program ends a lookaround \
+                                that it isn't in.")
+            }
+            Match | LookMatch => {}
+            Jump(to) => stack.push((to, look)),
+            Split(x, y) => {
+                stack.push((y, look));
+                stack.push((x, look));
+            }
+            EmptyLook(to, _) | Cut(to) => {
+                stack.push((to, look));
+                stack.push((pc + 1, pc + 1));
+            }
+            _ => stack.push((pc + 1, look)),
+        }
+    }
+    Ok(())
+}
+
+/// Encodes an instruction.
+pub fn encode_inst(e: &mut Encoder, inst: &Inst) {
+    match *inst {
+        Match => e.write_u8(0),
+        OneChar(c, flags) => {
+            e.write_u8(1);
+            e.write_char(c);
+            e.write_flags(flags);
+        }
+        CharClass(ref set, flags) => {
+            let ranges = &set.ranges;
+            e.write_u8(2);
+            e.write_uint(ranges.len());
+            for &(start, end) in ranges.iter() {
+                e.write_char(start);
+                e.write_char(end);
+            }
+            e.write_flags(flags);
+        }
+        Any(flags) => {
+            e.write_u8(3);
+            e.write_flags(flags);
+        }
+        EmptyBegin(flags) => {
+            e.write_u8(4);
+            e.write_flags(flags);
+        }
+        EmptyEnd(flags) => {
+            e.write_u8(5);
+            e.write_flags(flags);
+        }
+        EmptyWordBoundary(flags) => {
+            e.write_u8(6);
+            e.write_flags(flags);
+        }
+        EmptySegmentBoundary(seg, flags) => {
+            e.write_u8(7);
+            e.write_u8(match seg { Grapheme => 0, Word => 1 });
+            e.write_flags(flags);
+        }
+        EmptyLook(to, flags) => {
+            e.write_u8(8);
+            e.write_uint(to);
+            e.write_flags(flags);
+        }
+        Cut(to) => {
+            e.write_u8(9);
+            e.write_uint(to);
+        }
+        LookMatch => e.write_u8(10),
+        GroupRef(group, flags) => {
+            e.write_u8(11);
+            e.write_uint(group);
+            e.write_flags(flags);
+        }
+        Save(slot) => {
+            e.write_u8(12);
+            e.write_uint(slot);
+        }
+        Jump(to) => {
+            e.write_u8(13);
+            e.write_uint(to);
+        }
+        Split(x, y) => {
+            e.write_u8(14);
+            e.write_uint(x);
+            e.write_uint(y);
+        }
+    }
+}
+
+/// Decodes an instruction encoded by `encode_inst`.
+pub fn decode_inst(d: &mut Decoder) -> Result<Inst, DecodeError> {
+    Ok(match try!(d.read_u8()) {
+        0 => Match,
+        1 => {
+            let c = try!(d.read_char());
+            OneChar(c, try!(d.read_flags()))
+        }
+        2 => {
+            let n = try!(d.read_len());
+            let mut ranges = Vec::with_capacity(n);
+            for _ in range(0, n) {
+                let start = try!(d.read_char());
+                let end = try!(d.read_char());
+                if start > end {
+                    return invalid("The encoded program has an invalid \
+                                    class.")
+                }
+                ranges.push((start, end));
+            }
+            let flags = try!(d.read_flags());
+            CharClass(CharSet::new(ranges, flags), flags)
+        }
+        3 => Any(try!(d.read_flags())),
+        4 => EmptyBegin(try!(d.read_flags())),
+        5 => EmptyEnd(try!(d.read_flags())),
+        6 => EmptyWordBoundary(try!(d.read_flags())),
+        7 => {
+            let seg = match try!(d.read_u8()) {
+                0 => Grapheme,
+                1 => Word,
+                _ => return invalid("The encoded program has an unknown \
+                                     segment."),
+            };
+            EmptySegmentBoundary(seg, try!(d.read_flags()))
+        }
+        8 => {
+            let to = try!(d.read_uint());
+            EmptyLook(to, try!(d.read_flags()))
+        }
+        9 => Cut(try!(d.read_uint())),
+        10 => LookMatch,
+        11 => {
+            let group = try!(d.read_uint());
+            GroupRef(group, try!(d.read_flags()))
+        }
+        12 => Save(try!(d.read_uint())),
+        13 => Jump(try!(d.read_uint())),
+        14 => {
+            let x = try!(d.read_uint());
+            Split(x, try!(d.read_uint()))
+        }
+        _ => return invalid("The encoded program has an unknown instruction."),
+    })
+}
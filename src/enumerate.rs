@@ -0,0 +1,290 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module lists the strings matched by a regex whose language is finite,
+// by expanding its syntax tree bottom up. Each expression is expanded to the
+// strings it matches in the order that a backtracking engine tries them: the
+// strings of the first alternate come first, a class's characters are in
+// ascending order and a greedy `?` tries its expression before the empty
+// string. A string that an expression can match in several ways is kept
+// where it first appears.
+//
+// The language is checked for being finite before anything is expanded. A
+// class is checked against the limit before it's expanded, so a class like
+// `\pL` is never expanded only to be thrown away, and any other expansion
+// stops as soon as it has one string too many. (Its size can't be found
+// ahead of time by multiplying and adding, since the same string may be
+// matched in several ways and is only listed once.)
+//
+// Assertions (like `^` or `\b`), lookaround and `\K` are expanded as if they
+// matched the empty string, and atomic groups as if they were ordinary
+// groups. This can list strings that aren't matched (e.g., `ab` for `a\bb`),
+// so when an expression uses any of them, the strings are checked with
+// `Regex::is_full_match` at the end. (The limit still applies to the strings
+// before they're checked.) Backreferences aren't supported.
+
+use collections::HashSet;
+use std::char;
+use std::cmp;
+use std::fmt;
+use std::str;
+
+use literals;
+use parse;
+use parse::{
+    Nothing, Literal, Dot, Class, Begin, End, WordBoundary, SegmentBoundary,
+    Lookaround, Backref, Keep, Atomic, Capture, Cat, Alt, Rep,
+    ZeroOne, ZeroMore, OneMore,
+    FLAG_NOCASE, FLAG_NEGATED, FLAG_DOTNL,
+};
+use re::Regex;
+
+/// EnumerateError describes why the strings matched by a regex can't be
+/// listed.
+#[deriving(Clone)]
+pub struct EnumerateError {
+    /// A message describing the error.
+    pub msg: ~str,
+    /// What went wrong.
+    pub kind: EnumerateErrorKind,
+}
+
+/// EnumerateErrorKind tells a regex that matches infinitely many strings
+/// apart from one that matches too many, or one that can't be expanded.
+#[deriving(Clone, Eq, Show)]
+pub enum EnumerateErrorKind {
+    /// The regex matches infinitely many strings, because it repeats an
+    /// expression without an upper bound (e.g., with `*` or `+`).
+    EnumerateInfinite,
+    /// The regex matches more strings than the limit allows.
+    EnumerateTooMany,
+    /// The regex uses backreferences.
+    EnumerateUnsupported,
+}
+
+impl fmt::Show for EnumerateError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "Regex enumeration error: {}", self.msg)
+    }
+}
+
+/// Returns every string that `re` matches (all of), in the order described
+/// by `Regex::enumerate`, or an error if there are more than `limit`.
+pub fn strings(re: &Regex, limit: uint) -> Result<Vec<~str>, EnumerateError> {
+    let ast = re.syntax();
+    try!(check(&*ast));
+    let mut e = Enumerator { limit: limit, inexact: false };
+    let strs = try!(e.expand(&*ast)).list;
+    if !e.inexact {
+        return Ok(strs)
+    }
+    Ok(strs.move_iter().filter(|s| re.is_full_match(s.as_slice())).collect())
+}
+
+// Returns an error if the expression given uses backreferences, or repeats
+// something that can match a non-empty string without an upper bound.
+fn check(ast: &parse::Ast) -> Result<(), EnumerateError> {
+    match *ast {
+        Backref(_, _) => {
+            err(EnumerateUnsupported, ~"Backreferences aren't supported.")
+        }
+        Rep(ref x, ZeroMore, _) | Rep(ref x, OneMore, _)
+        if literals::max_len(&**x) != Some(0) => {
+            err(EnumerateInfinite, ~"The regex matches infinitely many \
+                                     strings.")
+        }
+        Lookaround(ref x, _) | Atomic(ref x) | Capture(_, _, ref x)
+        | Rep(ref x, _, _) => check(&**x),
+        Cat(ref xs) => {
+            for x in xs.iter() {
+                try!(check(&**x));
+            }
+            Ok(())
+        }
+        Alt(ref x, ref y) => {
+            try!(check(&**x));
+            check(&**y)
+        }
+        _ => Ok(()),
+    }
+}
+
+fn err<T>(kind: EnumerateErrorKind, msg: ~str) -> Result<T, EnumerateError> {
+    Err(EnumerateError { msg: msg, kind: kind })
+}
+
+struct Enumerator {
+    limit: uint,
+    // Whether an expression that was expanded approximately was seen (see
+    // above).
+    inexact: bool,
+}
+
+impl Enumerator {
+    fn expand(&mut self, ast: &parse::Ast) -> Result<Strings, EnumerateError> {
+        match *ast {
+            Nothing => self.just_empty(),
+            Begin(_) | End(_) | WordBoundary(_) | SegmentBoundary(_, _)
+            | Lookaround(_, _) | Keep => {
+                self.inexact = true;
+                self.just_empty()
+            }
+            // Ruled out by `check`.
+            Backref(_, _) => unreachable!(),
+            Literal(c, flags) => {
+                if flags & FLAG_NOCASE > 0 {
+                    self.chars(parse::fold_unicode(&[(c, c)]).as_slice())
+                } else {
+                    self.chars(&[(c, c)])
+                }
+            }
+            Dot(flags) => {
+                let max = '\U0010FFFF';
+                if flags & FLAG_DOTNL > 0 {
+                    self.chars(&[('\x00', max)])
+                } else {
+                    self.chars(&[('\x00', '\x09'), ('\x0B', max)])
+                }
+            }
+            Class(ref ranges, flags) => {
+                let ranges =
+                    if flags & FLAG_NOCASE > 0 {
+                        // Folding only adds characters, so a class that's
+                        // already too big isn't folded.
+                        if count(ranges.as_slice()) > self.limit {
+                            return self.too_many()
+                        }
+                        parse::fold_unicode(ranges.as_slice())
+                    } else {
+                        ranges.clone()
+                    };
+                if flags & FLAG_NEGATED > 0 {
+                    self.chars(parse::negate_ranges(ranges.as_slice())
+                                   .as_slice())
+                } else {
+                    self.chars(ranges.as_slice())
+                }
+            }
+            Atomic(ref x) => {
+                self.inexact = true;
+                self.expand(&**x)
+            }
+            Capture(_, _, ref x) => self.expand(&**x),
+            Cat(ref xs) => {
+                let mut strs = try!(self.just_empty());
+                for x in xs.iter() {
+                    let next = try!(self.expand(&**x));
+                    let mut cat = Strings::new();
+                    for pre in strs.list.iter() {
+                        for suf in next.list.iter() {
+                            try!(self.push(&mut cat, format!("{}{}", pre,
+                                                             suf)));
+                        }
+                    }
+                    strs = cat;
+                }
+                Ok(strs)
+            }
+            Alt(ref x, ref y) => {
+                let mut strs = try!(self.expand(&**x));
+                for s in try!(self.expand(&**y)).list.move_iter() {
+                    try!(self.push(&mut strs, s));
+                }
+                Ok(strs)
+            }
+            Rep(ref x, op, ref greed) => {
+                // Unless it's `?`, the expression only matches the empty
+                // string (see `check`), so repeating it changes nothing.
+                let x = try!(self.expand(&**x));
+                if op != ZeroOne {
+                    return Ok(x)
+                }
+                let mut strs = Strings::new();
+                if !greed.is_greedy() {
+                    try!(self.push(&mut strs, ~""));
+                }
+                for s in x.list.move_iter() {
+                    try!(self.push(&mut strs, s));
+                }
+                try!(self.push(&mut strs, ~""));
+                Ok(strs)
+            }
+        }
+    }
+
+    fn just_empty(&self) -> Result<Strings, EnumerateError> {
+        let mut strs = Strings::new();
+        try!(self.push(&mut strs, ~""));
+        Ok(strs)
+    }
+
+    // Expands a set of characters, which is first checked against the limit.
+    fn chars(&self, ranges: &[(char, char)])
+            -> Result<Strings, EnumerateError> {
+        if count(ranges) > self.limit {
+            return self.too_many()
+        }
+        let mut strs = Strings::new();
+        for &(start, end) in ranges.iter() {
+            for n in range(start as u32, end as u32 + 1) {
+                match char::from_u32(n) {
+                    None => {}
+                    Some(c) => try!(self.push(&mut strs, str::from_char(c))),
+                }
+            }
+        }
+        Ok(strs)
+    }
+
+    // Adds a string if it isn't there already, unless it's one too many.
+    fn push(&self, strs: &mut Strings, s: ~str) -> Result<(), EnumerateError> {
+        if strs.seen.contains(&s) {
+            return Ok(())
+        }
+        if strs.list.len() == self.limit {
+            return self.too_many()
+        }
+        strs.seen.insert(s.clone());
+        strs.list.push(s);
+        Ok(())
+    }
+
+    fn too_many<T>(&self) -> Result<T, EnumerateError> {
+        err(EnumerateTooMany,
+            format!("The regex matches more than {} strings.", self.limit))
+    }
+}
+
+// The strings an expression expands to, in order, and without duplicates.
+struct Strings {
+    list: Vec<~str>,
+    seen: HashSet<~str>,
+}
+
+impl Strings {
+    fn new() -> Strings {
+        Strings { list: vec!(), seen: HashSet::new() }
+    }
+}
+
+// Returns the number of characters in the ranges given (which are sorted
+// and don't overlap), leaving out the surrogates.
+pub fn count(ranges: &[(char, char)]) -> uint {
+    ranges.iter().fold(0u, |n, &(s, e)| {
+        let (s, e) = (s as uint, e as uint);
+        let surrogates =
+            if s > 0xDFFF || e < 0xD800 {
+                0
+            } else {
+                cmp::min(e, 0xDFFF) + 1 - cmp::max(s, 0xD800)
+            };
+        n + (e - s + 1) - surrogates
+    })
+}
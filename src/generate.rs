@@ -0,0 +1,228 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module generates random strings that a regex matches, for testing
+// code that only accepts input that a regex has checked. A string is built
+// by walking the syntax tree: an alternation picks one of its alternates,
+// a repetition picks how many times it repeats, and a class (or `.`) picks
+// one of its characters, each uniformly at random. A backreference repeats
+// whatever its group was given.
+//
+// Empty-width expressions (assertions, lookaround and `\K`) are walked as
+// if they matched the empty string, and atomic groups as if they were
+// ordinary groups, so a string built this way might not match (e.g., `ab`
+// for `a\bb`). Every string is checked with `Regex::is_full_match` before
+// it's returned, and a string that doesn't match is thrown away and another
+// one is built, up to a limit. A regex that can't be satisfied, like `a\bb`
+// or `(?=a)b`, fails once the limit is reached.
+
+use collections::HashMap;
+use std::char;
+use std::cmp;
+use std::fmt;
+use std::rand::Rng;
+
+use enumerate;
+use parse;
+use parse::{
+    Nothing, Literal, Dot, Class, Begin, End, WordBoundary, SegmentBoundary,
+    Lookaround, Backref, Keep, Atomic, Capture, Cat, Alt, Rep,
+    ZeroOne, ZeroMore, OneMore,
+    FLAG_NOCASE, FLAG_NEGATED, FLAG_DOTNL,
+};
+use re::Regex;
+
+/// The options that random strings are generated with (see
+/// `Regex::generate`).
+pub struct GenerateOptions {
+    /// The most times that `*` or `+` repeats its expression. (A counted
+    /// repetition, like `{2,5}`, repeats at most as many times as it says,
+    /// except that `{n,}` repeats up to `n + max_repeat` times.)
+    pub max_repeat: uint,
+    /// The number of strings that are built and thrown away, because they
+    /// didn't match, before giving up.
+    pub retries: uint,
+}
+
+impl GenerateOptions {
+    /// Returns the default options: `*` and `+` repeat at most 8 times, and
+    /// 100 strings are thrown away before giving up.
+    pub fn new() -> GenerateOptions {
+        GenerateOptions { max_repeat: 8, retries: 100 }
+    }
+}
+
+/// GenerateError describes why no string that a regex matches was found.
+#[deriving(Clone)]
+pub struct GenerateError {
+    /// A message describing the error.
+    pub msg: ~str,
+}
+
+impl fmt::Show for GenerateError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "Regex generation error: {}", self.msg)
+    }
+}
+
+/// Returns a random string that `re` matches all of, as described by
+/// `Regex::generate`.
+pub fn generate<R: Rng>(re: &Regex, rng: &mut R, opts: &GenerateOptions)
+                       -> Result<~str, GenerateError> {
+    let ast = re.syntax();
+    for _ in range(0, opts.retries + 1) {
+        let mut g = Generator {
+            max_repeat: opts.max_repeat,
+            out: StrBuf::new(),
+            groups: HashMap::new(),
+        };
+        try!(g.gen(rng, &*ast));
+        let s = g.out.into_owned();
+        if re.is_full_match(s.as_slice()) {
+            return Ok(s)
+        }
+    }
+    Err(GenerateError {
+        msg: format!("No string that was generated matched, after {} tries.",
+                     opts.retries + 1),
+    })
+}
+
+struct Generator {
+    max_repeat: uint,
+    out: StrBuf,
+    // The text given to each capture group that has been walked, for
+    // backreferences.
+    groups: HashMap<uint, ~str>,
+}
+
+impl Generator {
+    fn gen<R: Rng>(&mut self, rng: &mut R, ast: &parse::Ast)
+                  -> Result<(), GenerateError> {
+        match *ast {
+            Nothing | Begin(_) | End(_) | WordBoundary(_)
+            | SegmentBoundary(_, _) | Lookaround(_, _) | Keep => Ok(()),
+            Literal(c, flags) => {
+                if flags & FLAG_NOCASE > 0 {
+                    self.pick(rng, parse::fold_unicode(&[(c, c)]).as_slice())
+                } else {
+                    self.out.push_char(c);
+                    Ok(())
+                }
+            }
+            Dot(flags) => {
+                let max = '\U0010FFFF';
+                if flags & FLAG_DOTNL > 0 {
+                    self.pick(rng, &[('\x00', max)])
+                } else {
+                    self.pick(rng, &[('\x00', '\x09'), ('\x0B', max)])
+                }
+            }
+            Class(ref ranges, flags) => {
+                let ranges =
+                    if flags & FLAG_NOCASE > 0 {
+                        parse::fold_unicode(ranges.as_slice())
+                    } else {
+                        ranges.clone()
+                    };
+                if flags & FLAG_NEGATED > 0 {
+                    self.pick(rng, parse::negate_ranges(ranges.as_slice())
+                                       .as_slice())
+                } else {
+                    self.pick(rng, ranges.as_slice())
+                }
+            }
+            Backref(i, _) => {
+                // A group that hasn't matched fails the backreference, which
+                // the check at the end finds.
+                match self.groups.find(&i) {
+                    None => {}
+                    Some(s) => self.out.push_str(s.as_slice()),
+                }
+                Ok(())
+            }
+            Atomic(ref x) => self.gen(rng, &**x),
+            Capture(i, _, ref x) => {
+                let start = self.out.len();
+                try!(self.gen(rng, &**x));
+                let text = self.out.as_slice().slice_from(start).to_owned();
+                self.groups.insert(i, text);
+                Ok(())
+            }
+            Cat(ref xs) => {
+                for x in xs.iter() {
+                    try!(self.gen(rng, &**x));
+                }
+                Ok(())
+            }
+            Alt(_, _) => {
+                // `a|b|c` is `a|(b|c)`, so the alternates are collected
+                // first, to pick each of them as often.
+                let mut alts = vec!();
+                let mut ast = ast;
+                loop {
+                    match *ast {
+                        Alt(ref x, ref y) => {
+                            alts.push(&**x);
+                            ast = &**y;
+                        }
+                        _ => {
+                            alts.push(ast);
+                            break
+                        }
+                    }
+                }
+                let x = *alts.get(rng.gen_range(0, alts.len()));
+                self.gen(rng, x)
+            }
+            Rep(ref x, op, _) => {
+                let n = match op {
+                    ZeroOne => rng.gen_range(0u, 2),
+                    ZeroMore => rng.gen_range(0, self.max_repeat + 1),
+                    OneMore => {
+                        rng.gen_range(1, cmp::max(self.max_repeat, 1) + 1)
+                    }
+                };
+                for _ in range(0, n) {
+                    try!(self.gen(rng, &**x));
+                }
+                Ok(())
+            }
+        }
+    }
+
+    // Adds one of the characters in the ranges given (which are sorted and
+    // don't overlap), picked uniformly.
+    fn pick<R: Rng>(&mut self, rng: &mut R, ranges: &[(char, char)])
+                   -> Result<(), GenerateError> {
+        let total = enumerate::count(ranges);
+        if total == 0 {
+            return Err(GenerateError {
+                msg: ~"The regex has a class that matches no characters.",
+            })
+        }
+        let mut i = rng.gen_range(0, total);
+        for &(start, end) in ranges.iter() {
+            let n = enumerate::count(&[(start, end)]);
+            if i >= n {
+                i -= n;
+                continue
+            }
+            // `count` leaves out the surrogates, so they're stepped over.
+            let mut c = start as uint + i;
+            if (start as uint) < 0xD800 && c >= 0xD800 {
+                c += 0x800;
+            }
+            self.out.push_char(char::from_u32(c as u32).unwrap());
+            break
+        }
+        Ok(())
+    }
+}
@@ -0,0 +1,262 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module searches the lines of the text read from a `Reader`, like
+// grep does. Each line is searched on its own, without its `\n`, as if it
+// was all of the text (so `^` and `$` match at its start and end).
+//
+// The text is read in large chunks, and the complete lines in the buffer
+// aren't all handed to the regex one by one. The literals of the regex's
+// prefilter are looked for across the buffer instead, and only the lines
+// that contain one are searched. Every match contains one of them, and a
+// match of a line is in the line, so the other lines don't match (which is
+// all that an inverted search needs to know about them too). Empty lines
+// are always searched, since a match of one contains nothing.
+//
+// A line that's longer than the maximum is dealt with as soon as the buffer
+// holds that much of it, so the buffer never holds much more than the
+// maximum and a chunk.
+
+use std::io::{IoResult, IoError, Reader, EndOfFile, InvalidInput};
+use std::str;
+
+use literals::Prefilter;
+use re::{Regex, Dynamic, Native};
+use stream;
+use stream::CHUNK_SIZE;
+use vm;
+
+/// What's done with a line that's longer than the maximum (see
+/// `GrepOptions`).
+#[deriving(Clone, Eq, Show)]
+pub enum LongLines {
+    /// Reading stops, and an error is returned.
+    LongLinesError,
+    /// The line is skipped: it's neither searched nor reported, but it's
+    /// counted, so the numbers of the lines after it are right.
+    LongLinesSkip,
+    /// Only the start of the line (up to the maximum, but not in the middle
+    /// of a character) is searched and reported, as if it was all of it.
+    LongLinesTruncate,
+}
+
+/// The options that the lines of a reader are searched with (see
+/// `Regex::grep`).
+pub struct GrepOptions {
+    /// Lines that don't match are reported instead of the ones that do
+    /// (like `grep -v`).
+    pub invert: bool,
+    /// The length of the longest line, in bytes, not counting its `\n`.
+    pub max_line_len: uint,
+    /// What's done with longer lines.
+    pub long_lines: LongLines,
+}
+
+impl GrepOptions {
+    /// Returns the default options: matching lines are reported, and a line
+    /// that's longer than 1MB is an error.
+    pub fn new() -> GrepOptions {
+        GrepOptions {
+            invert: false,
+            max_line_len: 1 << 20,
+            long_lines: LongLinesError,
+        }
+    }
+}
+
+/// Calls `each` with the number (counting from 1) and the text of every
+/// line read from `src` that `re` matches (or doesn't, with `opts.invert`),
+/// until it returns false. When `locate` is true, the location of the first
+/// match in each line that matches is given too. (Otherwise, the lines are
+/// only checked with `is_match`.)
+///
+/// The number of lines reported is returned. It's an error if the text
+/// isn't valid UTF-8.
+pub fn grep(re: &Regex, src: &mut Reader, opts: &GrepOptions, locate: bool,
+            mut each: |uint, &str, Option<(uint, uint)>| -> bool)
+           -> IoResult<uint> {
+    let prefilter = match re.p {
+        Dynamic(ref prog) => Some(&prog.prefilter),
+        Native(_) => None,
+    };
+    let mut g = Lines {
+        re: re,
+        prefilter: prefilter,
+        opts: opts,
+        locate: locate,
+        lineno: 0,
+        reported: 0,
+    };
+    let mut buf: Vec<u8> = Vec::with_capacity(CHUNK_SIZE);
+    let mut chunk = Vec::from_elem(CHUNK_SIZE, 0u8);
+    // Whether the rest of a long line that's been dealt with is skipped.
+    let mut skipping = false;
+    loop {
+        let eof = match src.read(chunk.as_mut_slice()) {
+            Ok(k) => {
+                buf.push_all(chunk.slice_to(k));
+                false
+            }
+            Err(ref err) if err.kind == EndOfFile => true,
+            Err(err) => return Err(err),
+        };
+        if skipping {
+            match vm::memchr('\n' as u8, buf.as_slice()) {
+                None => buf.clear(),
+                Some(i) => {
+                    buf = Vec::from_slice(buf.slice_from(i + 1));
+                    skipping = false;
+                }
+            }
+        }
+        // The lines that are complete, which at the end is all of them.
+        let complete =
+            if eof {
+                buf.len()
+            } else {
+                match buf.iter().rposition(|&b| b == '\n' as u8) {
+                    None => 0,
+                    Some(i) => i + 1,
+                }
+            };
+        let more = {
+            let text = match str::from_utf8(buf.slice_to(complete)) {
+                None => return Err(stream::invalid_utf8()),
+                Some(text) => text,
+            };
+            try!(g.search(text, &mut each))
+        };
+        if !more {
+            break
+        }
+        if eof {
+            break
+        }
+        buf = Vec::from_slice(buf.slice_from(complete));
+        if buf.len() > opts.max_line_len {
+            // The line at the end of the buffer is already too long.
+            g.lineno += 1;
+            skipping = true;
+            match opts.long_lines {
+                LongLinesError => return Err(line_too_long()),
+                LongLinesSkip => {}
+                LongLinesTruncate => {
+                    let start = buf.slice_to(opts.max_line_len);
+                    let valid = stream::utf8_prefix(start);
+                    let line = match str::from_utf8(start.slice_to(valid)) {
+                        None => return Err(stream::invalid_utf8()),
+                        Some(line) => line,
+                    };
+                    if !g.line(line, true, &mut each) {
+                        break
+                    }
+                }
+            }
+            buf.clear();
+        }
+    }
+    Ok(g.reported)
+}
+
+struct Lines<'a> {
+    re: &'a Regex,
+    // The prefilter of a dynamic regex. (A native one has none.)
+    prefilter: Option<&'a Prefilter>,
+    opts: &'a GrepOptions,
+    locate: bool,
+    // The number of the last line that was read.
+    lineno: uint,
+    reported: uint,
+}
+
+impl<'a> Lines<'a> {
+    // Searches the complete lines of `text` (the last of which may not end
+    // with a `\n`). Returns false if searching should stop.
+    fn search(&mut self, text: &str,
+              each: &mut |uint, &str, Option<(uint, uint)>| -> bool)
+             -> IoResult<bool> {
+        let bytes = text.as_bytes();
+        // The position of the next literal of the prefilter, at or after
+        // the start of the line.
+        let mut hit = self.scan(bytes, 0);
+        let mut start = 0;
+        while start < text.len() {
+            let (mut end, next) =
+                match vm::memchr('\n' as u8, bytes.slice_from(start)) {
+                    None => (text.len(), text.len()),
+                    Some(i) => (start + i, start + i + 1),
+                };
+            self.lineno += 1;
+            if end - start > self.opts.max_line_len {
+                match self.opts.long_lines {
+                    LongLinesError => return Err(line_too_long()),
+                    LongLinesSkip => {
+                        start = next;
+                        continue
+                    }
+                    LongLinesTruncate => {
+                        let max = start + self.opts.max_line_len;
+                        end = stream::floor_char(text, max);
+                    }
+                }
+            }
+            match hit {
+                Some(h) if h < start => hit = self.scan(bytes, start),
+                _ => {}
+            }
+            let candidate = match hit {
+                Some(h) => h < end || start == end,
+                None => start == end,
+            };
+            if !self.line(text.slice(start, end), candidate, each) {
+                return Ok(false)
+            }
+            start = next;
+        }
+        Ok(true)
+    }
+
+    // Searches the last line read (unless it's known not to match), and
+    // reports it if it should be. Returns false if searching should stop.
+    fn line(&mut self, line: &str, candidate: bool,
+            each: &mut |uint, &str, Option<(uint, uint)>| -> bool) -> bool {
+        let (matched, loc) =
+            if !candidate {
+                (false, None)
+            } else if self.locate && !self.opts.invert {
+                let loc = self.re.find(line);
+                (loc.is_some(), loc)
+            } else {
+                (self.re.is_match(line), None)
+            };
+        if matched == self.opts.invert {
+            return true
+        }
+        self.reported += 1;
+        (*each)(self.lineno, line, loc)
+    }
+
+    // Returns the position of the first literal of the prefilter at or after
+    // `start`, or `start` itself if there's no prefilter.
+    fn scan(&self, bytes: &[u8], start: uint) -> Option<uint> {
+        match self.prefilter {
+            None => Some(start),
+            Some(pre) => pre.scan(bytes.slice_from(start)).map(|i| start + i),
+        }
+    }
+}
+
+fn line_too_long() -> IoError {
+    IoError {
+        kind: InvalidInput,
+        desc: "line is longer than the maximum line length",
+        detail: None,
+    }
+}
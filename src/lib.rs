@@ -134,6 +134,35 @@
 //! provides more flexibility than is seen here. (See the documentation for
 //! `Regex::replace` for more details.)
 //!
+//! # Example: searching for many expressions at once
+//!
+//! When several expressions need to be searched for in the same text, they
+//! can be compiled together into a `RegexSet`. A set reports which of its
+//! expressions match after a *single* scan of the text:
+//!
+//! ```rust
+//! use regex::RegexSet;
+//! let set = RegexSet::new(&["agggtaaa|tttaccct", "(?i)AGG[ACT]TAAA"]).unwrap();
+//! assert_eq!(set.matches("xxaggataaaxx"), vec!(1));
+//! ```
+//!
+//! # Example: compiling once and loading many times
+//!
+//! Compiling thousands of expressions takes a while. They can be compiled
+//! once instead (e.g., by a build step) and encoded with `to_bytes`. Then
+//! `from_bytes` decodes them without parsing or compiling them again:
+//!
+//! ```rust
+//! use regex::Regex;
+//! let bytes = Regex::new(r"\d{4}-\d{2}-\d{2}").unwrap().to_bytes();
+//! let re = Regex::from_bytes(bytes.as_slice()).unwrap();
+//! assert!(re.is_match("2014-01-01"));
+//! ```
+//!
+//! Sets can be encoded the same way. Bytes encoded by a version of this crate
+//! with a different format are rejected with an `UnsupportedVersion` error,
+//! so they can be compiled again.
+//!
 //! # Pay for what you use
 //!
 //! With respect to searching text with a regular expression, there are three
@@ -174,6 +203,14 @@
 //! # }
 //! ```
 //!
+//! Text that isn't valid UTF8 can't be searched by a `Regex`. Strings are
+//! always valid, and the methods that search a `Reader` return an
+//! `InvalidInput` error when they read bytes that aren't. To search such
+//! text, use a `ByteRegex`, which either matches every byte on its own or
+//! (when compiled with `ByteRegex::with_unicode`) only matches bytes that
+//! aren't valid UTF8 where Unicode is turned off with `(?-u)`. See its
+//! documentation for details.
+//!
 //! Finally, Unicode general categories and scripts are available as character
 //! classes. For example, you can match a sequence of numerals, Greek or
 //! Cherokee letters:
@@ -199,20 +236,76 @@
 //! [xyz]       A character class matching either x, y or z.
 //! [^xyz]      A character class matching any character except x, y and z.
 //! [a-z]       A character class matching any character in range a-z.
+//! [x[^xyz]]   A nested class matching x or any character except x, y and z.
+//! [a-y&&xyz]  Intersection (matching x or y).
+//! [a-y--xyz]  Difference (matching any character in range a-w).
+//! [a-y~~xyz]  Symmetric difference (matching a-w or z).
 //! \d          Perl character class ([0-9])
 //! \D          Negated Perl character class ([^0-9])
 //! [:alpha:]   ASCII character class ([A-Za-z])
 //! [:^alpha:]  Negated ASCII character class ([^A-Za-z])
 //! \pN         One letter name Unicode character class
-//! \p{Greek}   Unicode character class (general category or script)
+//! \p{Greek}   Unicode character class (general category, script, etc.)
 //! \PN         Negated one letter name Unicode character class
-//! \P{Greek}   negated Unicode character class (general category or script)
+//! \P{Greek}   negated Unicode character class (see below)
+//! \X          a single extended grapheme cluster (includes new line)
 //! </pre>
 //!
 //! Any named character class may appear inside a bracketed `[...]` character
 //! class. For example, `[\p{Greek}\pN]` matches any Greek or numeral
 //! character.
 //!
+//! The set operations `&&`, `--` and `~~` combine the items on either side of
+//! them. Union binds most tightly, so `[ab&&bc]` is `[[ab]&&[bc]]`, and the
+//! set operations all bind equally tightly from left to right, so
+//! `[\pL--\p{Greek}&&\p{Lu}]` is `[[\pL--\p{Greek}]&&\p{Lu}]`. A leading `^`
+//! negates the result of all of them: `[^a-z&&b]` is `[^[a-z&&b]]`. For
+//! example, `[a-z&&[^aeiou]]` matches any lowercase ASCII consonant.
+//!
+//! Since `[` inside a class starts a nested class, and `&&`, `--` and `~~`
+//! are operators, they have to be escaped (as `\[`, `\&`, `\-` and `\~`) to
+//! be matched literally. An unclosed nested class, an operator that's
+//! missing an operand and a class that matches nothing after its set
+//! operations are errors, which catches most classes that were written to
+//! match them literally. Leading hyphens are still literal (e.g., `[--a]`).
+//! But a class that contains a complete nested class still parses, with a
+//! new meaning: `[[x]y]` was the class `[\[x]` followed by `y]` before
+//! nested classes, and is now a class matching `x` or `y`. The old meaning
+//! is written `[\[x]y\]`.
+//!
+//! The names in `\p{...}` are matched loosely, as Unicode Standard Annex #44
+//! recommends: case, spaces, underscores and hyphens are ignored, and so is
+//! an `Is` prefix. A name can be a general category (e.g., `Lu` or
+//! `Uppercase_Letter`), a script (`Greek` or `Grek`), a binary property
+//! (`Alphabetic`, `White_Space`, `Uppercase`, `Lowercase`, `Math`, `Dash`,
+//! `Hex_Digit`, `ASCII_Hex_Digit`, `Ideographic`, `Diacritic`,
+//! `Default_Ignorable_Code_Point` or `Noncharacter_Code_Point`, or their
+//! short aliases like `Alpha`), or one of `Any`, `Assigned` and `ASCII`.
+//! The kind of property can also be given, as in `\p{gc=Lu}`,
+//! `\p{General_Category=Letter}`, `\p{sc=Greek}` and `\p{Alpha=No}` (which
+//! is `\P{Alpha}`), with `=` or `:`. A script matches the characters whose
+//! Script property is that script, and `\p{scx=Greek}` (or
+//! `\p{Script_Extensions:Greek}`) also matches the ones that are used with
+//! it, such as the combining marks shared by several scripts. An unknown
+//! name is an error that suggests the names closest to it.
+//!
+//! ```rust
+//! # use regex::Regex;
+//! assert!(Regex::new(r"^\p{IsGreek}+$").unwrap().is_match("αβγ"));
+//! let re = Regex::new(r"^\p{letter}\p{White Space}$").unwrap();
+//! assert!(re.is_match("a "));
+//! assert!(!Regex::new(r"\p{sc=Greek}").unwrap().is_match("\u0342"));
+//! assert!(Regex::new(r"\p{scx=Greek}").unwrap().is_match("\u0342"));
+//! let err = Regex::new(r"\p{Lettr}").unwrap_err();
+//! assert!(err.msg.contains("Did you mean 'Letter'?"));
+//! ```
+//!
+//! `\X` matches what a reader sees as a single character, which may be more
+//! than one Unicode character (e.g., `e\u0301` or an emoji ZWJ sequence). It's
+//! equivalent to `(?s:.)(?:\B{g}(?s:.))*\b{g}` (see `\b{g}` below), so it
+//! can't appear inside a character class and it isn't supported where
+//! `\b{g}` isn't.
+//!
 //! ## Composites
 //!
 //! <pre class="rust">
@@ -235,8 +328,16 @@
 //! x{n,m}?   at least n and at most x (ungreedy)
 //! x{n,}?    at least n x (ungreedy)
 //! x{n}?     exactly n x
+//! x*+       zero or more of x (possessive)
+//! x++       one or more of x (possessive)
+//! x?+       zero or one of x (possessive)
+//! x{n,m}+   at least n and at most x (possessive)
+//! x{n,}+    at least n x (possessive)
 //! </pre>
 //!
+//! A possessive repetition is an atomic group around a greedy one (e.g.,
+//! `a*+` is `(?>a*)`); see below.
+//!
 //! ## Empty matches
 //!
 //! <pre class="rust">
@@ -244,34 +345,259 @@
 //! $     the end of text (or end-of-line with multi-line mode)
 //! \A    only the beginning of text (even with multi-line mode enabled)
 //! \z    only the end of text (even with multi-line mode enabled)
+//! \Z    the end of text, or before a new line that ends the text
+//! \G    only the position at which the search started (see below)
 //! \b    a Unicode word boundary (\w on one side and \W, \A, or \z on other)
 //! \B    not a Unicode word boundary
+//! \b{wb} a boundary between Unicode words (see below)
+//! \b{g}  a boundary between grapheme clusters (see below)
+//! \B{wb} not a boundary between Unicode words (and likewise \B{g})
+//! \K    resets the start of the match reported to here (see below)
+//! </pre>
+//!
+//! `\G` matches where a search starts, which is the beginning of the text
+//! for methods like `find`, the position given to methods like `find_at`, and
+//! where the previous match ended (or one character later, after an empty
+//! match) for iterators like `find_iter`. A regex that starts with `\G`
+//! therefore finds a contiguous sequence of matches, and stops at the first
+//! position that can't be matched. Unlike `^`, it never matches at the
+//! beginning of a line, and unlike `\A`, it matches wherever a search
+//! starts. Searching streams (e.g., with `Regex::stream_replace_all`) isn't
+//! supported for regexes that use `\G`.
+//!
+//! `\b{wb}` and `\b{g}` match the boundaries between words and between
+//! (extended) grapheme clusters defined by [Unicode Standard Annex
+//! #29](http://www.unicode.org/reports/tr29/). A grapheme cluster is what a
+//! reader sees as a single character, such as a letter followed by its
+//! combining marks, a flag, an emoji ZWJ sequence like `👩‍👩‍👧` or an
+//! Indic conjunct. Unlike `\b`, every segment of the text is a word, so the
+//! spaces and punctuation between words are segments too, but a word like
+//! `can't` or `3.14` is still a single segment. Since the rules for these
+//! boundaries can look at many of the characters around a position, regexes
+//! that use them are searched by the slower engines, and searching streams
+//! isn't supported for them. They can't be used in a `ByteRegex`.
+//!
+//! ```rust
+//! # #![feature(phase)]
+//! # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+//! # fn main() {
+//! let re = regex!(r"(?s).+?\b{g}");
+//! let found: Vec<(uint, uint)> = re.find_iter("e\u0301🇫🇷").collect();
+//! assert_eq!(found, vec!((0, 3), (3, 11)));
+//!
+//! let re = regex!(r"\b{wb}.+?\b{wb}");
+//! let found: Vec<(uint, uint)> = re.find_iter("can't 3.14").collect();
+//! assert_eq!(found, vec!((0, 5), (5, 6), (6, 10)));
+//! # }
+//! ```
+//!
+//! ## Lookaround
+//!
+//! <pre class="rust">
+//! (?=exp)    matches if exp matches at this position (consumes no characters)
+//! (?!exp)    matches if exp doesn't match at this position
+//! (?<=exp)   matches if exp matches text that ends at this position
+//! (?<!exp)   matches if exp doesn't match text that ends at this position
+//! </pre>
+//!
+//! The text that a lookaround matches isn't part of the match, but capture
+//! groups inside a `(?=...)` or `(?<=...)` are set like any other (inside a
+//! negative lookaround, they never are). Lookarounds may be nested, and like
+//! the other empty matches, they can't be repeated with `*`, `+` or `?`.
+//!
+//! A lookbehind must match text of a bounded length, so it can't repeat
+//! anything without an upper bound (e.g., `(?<=a+)` is an error, but
+//! `(?<=a{1,3})` isn't). It sees the text before the start of a search,
+//! just like `\b` does: the text before the offset given to `find_at`, and
+//! the text of the previous match for iterators like `find_iter` (see
+//! `Regex::find_at_with` for a way to hide it).
+//!
+//! No finite automaton can evaluate a lookaround, so a regex that uses one
+//! is always searched by the bounded backtracker (see
+//! `Regex::has_lookaround`), which is slower on long texts. The memory it
+//! takes is bounded (see `Regex::set_backtrack_limit`), so a search whose
+//! paths from a single position reach very far into the text fails as one
+//! exceeding its step limit does. Lookaround isn't supported by the `regex!`
+//! macro, by `RegexSet` or when searching streams.
+//!
+//! ```rust
+//! # use regex::Regex;
+//! let re = Regex::new(r"\w+(?=,)").unwrap();
+//! assert_eq!(re.replace_all("a, b, c", "x").as_slice(), "x, x, c");
+//!
+//! let re = Regex::new(r"\b(?!un)\w+able\b").unwrap();
+//! let found: Vec<(uint, uint)> = re.find_iter("usable unable").collect();
+//! assert_eq!(found, vec!((0, 6)));
+//!
+//! let re = Regex::new(r"(?<!-)\b\d+").unwrap();
+//! let found: Vec<(uint, uint)> = re.find_iter("-1 2 -3 45").collect();
+//! assert_eq!(found, vec!((3, 4), (8, 10)));
+//! ```
+//!
+//! ## Backreferences
+//!
+//! <pre class="rust">
+//! \1 ... \9    matches the text that capture group 1 ... 9 matched
+//! \k&lt;name&gt;     matches the text that the named capture group matched
 //! </pre>
 //!
+//! A backreference must come after the group it refers to, and not inside
+//! of it. If the group didn't take part in the match (e.g., `(a)?b\1` on
+//! `b`), then the backreference never matches. Case insensitive
+//! backreferences fold case just like literals do. When a name is used by
+//! groups in several branches of an alternation, `\k<name>` matches the text
+//! of whichever of them matched.
+//!
+//! Since `\1` to `\9` are backreferences, octal escapes of a single digit
+//! must be written with more digits (e.g., `\001` instead of `\1`).
+//!
+//! Like lookaround, backreferences are only evaluated by the bounded
+//! backtracker (see `Regex::has_backrefs`), and they aren't supported by the
+//! `regex!` macro or by `RegexSet`. Unlike every other feature, they can
+//! make a search take time exponential in the length of the text, since no
+//! automaton can tell which paths have already failed. Regexes with
+//! backreferences that come from untrusted sources should be given a step
+//! limit (see `Regex::set_step_limit`), or forbidden entirely with
+//! `CompileLimits`.
+//!
+//! ```rust
+//! # use regex::Regex;
+//! let re = Regex::new(r"\b(\w+)\s+\1\b").unwrap();
+//! assert_eq!(re.find("it was the the best"), Some((7, 14)));
+//!
+//! let re = Regex::new(r#"(?P<quote>['"]).*?\k<quote>"#).unwrap();
+//! assert_eq!(re.find(r#"say "it's" now"#), Some((4, 10)));
+//! ```
+//!
+//! ## Atomic groups
+//!
+//! Once the expression of an atomic group `(?>exp)` has matched, a
+//! backtracking search never goes back to try another way for it to match,
+//! even if what follows it fails. This keeps expressions with lookaround or
+//! backreferences, which are run by a backtracking search, from trying an
+//! exponential number of ways to fail.
+//!
+//! These semantics only apply to regexes that use lookaround,
+//! backreferences or `\K`. In every other regex, atomic groups and possessive
+//! repetition are just non-capturing groups and greedy repetition. (The
+//! automata that search them explore every way to match at once, so they
+//! can't give any up, and every engine must find the same matches.) For
+//! example, `(?>a|ab)c` matches `abc`, but `(?>a|ab)c(?!x)` doesn't.
+//!
+//! ```rust
+//! # use regex::Regex;
+//! let re = Regex::new(r"(?=\d)\d++0").unwrap();
+//! assert_eq!(re.find("1200"), None);
+//! let re = Regex::new(r"\d++0").unwrap();
+//! assert_eq!(re.find("1200"), Some((0, 4)));
+//! ```
+//!
+//! ## Resetting the start of a match
+//!
+//! `\K` matches the empty string, and moves the start of the match that's
+//! reported to where it matched. The text matched before it must still be
+//! there for the regex to match, but it isn't part of the match: `find`
+//! returns the span that starts at `\K`, group 0 of `captures` is that span
+//! too (the other groups aren't changed), and `replace_all` leaves the text
+//! before it as it is. When a match crosses several `\K` (e.g., in
+//! different places or under a repetition), the last one crossed counts.
+//! `\K` isn't allowed inside lookaround or a character class.
+//!
+//! Like lookaround, `\K` is only evaluated by the bounded backtracker, and
+//! it isn't supported by the `regex!` macro or by `RegexSet`.
+//!
+//! ```rust
+//! # use regex::Regex;
+//! let re = Regex::new(r"\w+=\K\d+").unwrap();
+//! assert_eq!(re.find("width=100"), Some((6, 9)));
+//! assert_eq!(re.replace_all("w=1, h=22", "0").as_slice(), "w=0, h=0");
+//! ```
+//!
 //! ## Grouping and flags
 //!
 //! <pre class="rust">
 //! (exp)          numbered capture group (indexed by opening parenthesis)
 //! (?P&lt;name&gt;exp)  named (also numbered) capture group (allowed chars: [_0-9a-zA-Z])
 //! (?:exp)        non-capturing group
+//! (?>exp)        atomic non-capturing group (see below)
 //! (?flags)       set flags within current group
 //! (?flags:exp)   set flags for exp (non-capturing)
 //! </pre>
 //!
+//! Names must be unique, except that groups in different branches of an
+//! alternation may share a name, since only one of them can take part in a
+//! match. For example, `(?P<n>\d+)px|(?P<n>\d+)%`. Looking up a group by
+//! name (e.g., with `Captures::name` or `$n` in a replacement) finds the one
+//! that took part in the match.
+//!
 //! Flags are each a single character. For example, `(?x)` sets the flag `x`
 //! and `(?-x)` clears the flag `x`. Multiple flags can be set or cleared at
 //! the same time: `(?xy)` sets both the `x` and `y` flags and `(?x-y)` sets
 //! the `x` flag and clears the `y` flag.
 //!
-//! All flags are by default disabled. They are:
+//! All flags are by default disabled (except `u`), unless they're enabled
+//! with the `Options` given to `Regex::with_options`. They are:
 //!
 //! <pre class="rust">
 //! i     case insensitive
+//! f     full case folding for case insensitive literals (see below)
 //! m     multi-line mode: ^ and $ match begin/end of line
 //! s     allow . to match \n
 //! U     swap the meaning of x* and x*?
+//! u     Unicode support (enabled by default)
+//! w     Unicode word boundaries: \b and \B also treat marks, numbers and
+//!       connector punctuation as word characters
+//! x     free-spacing mode: ignore whitespace and allow comments with #
 //! </pre>
 //!
+//! In free-spacing mode, whitespace is ignored and `#` starts a comment that
+//! runs to the end of the line, except inside a character class, where both
+//! match themselves. An escaped space or `#` (e.g., `\ ` or `\#`) matches
+//! itself anywhere. Like the other flags, `x` only applies to the rest of
+//! the group it's set in:
+//!
+//! ```rust
+//! # #![feature(phase)]
+//! # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+//! # fn main() {
+//! let re = regex!(r"(?x)
+//!   (?P<year>\d{4}) - # the year
+//!   (?P<month>\d{2})  # the month
+//!   (?-x: [#]\d+)$    # a space, then the number of the entry
+//! ");
+//! let caps = re.captures("2014-05 #12").unwrap();
+//! assert_eq!(caps.name("month"), "05");
+//! # }
+//! ```
+//!
+//! By default, the word characters of `\b` and `\B` are the ones matched by
+//! `\w`: ASCII word characters and letters. So a combining mark (e.g., the
+//! U+0301 accent in `e\u0301`) ends a word. With the `w` flag, they're the
+//! word characters Unicode defines (letters, marks, numbers and connector
+//! punctuation such as `‿`), which keeps such marks in the same word:
+//!
+//! ```rust
+//! # #![feature(phase)]
+//! # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+//! # fn main() {
+//! let text = "cafe\u0301 bar";
+//! assert_eq!(regex!(r"\w+\b").find(text), Some((0, 4)));
+//! assert_eq!(regex!(r"(?w)\w+\b").find(text), Some((7, 10)));
+//! # }
+//! ```
+//!
+//! When Unicode is off (with `(?-u)`), the Perl classes `\d`, `\s` and `\w`
+//! and the word boundaries `\b` and `\B` are ASCII only, and case
+//! insensitive matching only folds the ASCII letters. To match arbitrary
+//! bytes rather than UTF8 text, use a `ByteRegex`, in which Unicode starts
+//! off and every byte, valid UTF8 or not, is matched on its own:
+//!
+//! ```rust
+//! # use regex::ByteRegex;
+//! let re = ByteRegex::new(r"(?i)GIF8[79]a[\x00-\xFF]").unwrap();
+//! assert!(re.is_match(&[0x67, 0x69, 0x66, 0x38, 0x39, 0x61, 0xFE]));
+//! ```
+//!
 //! Here's an example that matches case insensitively for only part of the
 //! expression:
 //!
@@ -285,6 +611,31 @@
 //! # }
 //! ```
 //!
+//! Case insensitive matching uses simple case folding, in which every
+//! character folds to a single character, so `(?i)straße` doesn't match
+//! `STRASSE`. With the `f` flag (as well as `i`), the literals in the
+//! expression use full case folding instead, in which some characters fold
+//! to several (`ß` to `ss`, `ﬁ` to `fi`), as defined by Unicode's
+//! CaseFolding.txt. Both sides are folded: `(?if)straße` matches `STRASSE`,
+//! and `(?if)strasse` matches `straße`. Character classes and
+//! backreferences still use simple folding, so `(?if)[ß]` doesn't match
+//! `ss`. The `f` flag has no effect without `i`, and it isn't supported
+//! in a `ByteRegex`.
+//!
+//! Full folding is done by replacing the literals that could match a
+//! folding with alternations, so those regexes compile to larger programs
+//! and are somewhat slower to search. In a long run of foldings that
+//! overlap (e.g., many `s` in a row, any two of which `ß` could match),
+//! only foldings that don't overlap, found from left to right, are tried.
+//!
+//! ```rust
+//! # use regex::Regex;
+//! let re = Regex::new(r"(?if)straße").unwrap();
+//! assert!(re.is_match("STRASSE"));
+//! assert!(!Regex::new(r"(?i)straße").unwrap().is_match("STRASSE"));
+//! assert!(Regex::new(r"(?if)ﬁle").unwrap().is_match("FILE"));
+//! ```
+//!
 //! Notice that the `a+` matches either `a` or `A`, but the `b+` only matches
 //! `b`.
 //!
@@ -298,11 +649,28 @@
 //! \n         new line
 //! \r         carriage return
 //! \v         vertical tab (\x0B)
-//! \123       octal character code (up to three digits)
+//! \123       octal character code (two or three digits, see backreferences)
 //! \x7F       hex character code (exactly two digits)
 //! \x{10FFFF} any hex character code corresponding to a valid UTF8 codepoint
+//! \N{U+E4}   the character with the code point given (1 to 6 hex digits)
+//! \N{SNOWMAN} the character with the Unicode name given
+//! \R         any line break (\r\n, or one of [\n\v\f\r\x85\x{2028}\x{2029}])
 //! </pre>
 //!
+//! A character name is matched without regard to ASCII case, and includes
+//! the names derived from code points, like `CJK UNIFIED IDEOGRAPH-4E00` and
+//! `HANGUL SYLLABLE GAG`. (Aliases and the names of control characters
+//! aren't supported.) The names are those of version 6.3.0 of the Unicode
+//! Character Database, like the classes of `\p{...}`. `\N` must always be
+//! followed by braces. Both forms can appear inside a character class, like
+//! any other character.
+//!
+//! `\R` is the same as `(?>\r\n|[\n\v\f\r\x85\x{2028}\x{2029}])`, so it
+//! matches a `\r\n` whole when it can. Where atomic groups apply (see
+//! "Atomic groups" above), `\R\n` never matches the `\r` of `\r\n` on its
+//! own. Where Unicode is off, it only matches the ASCII line breaks. It can't
+//! appear inside a character class.
+//!
 //! ## Perl character classes (Unicode friendly)
 //!
 //! <pre class="rust">
@@ -365,6 +733,8 @@
 #![deny(missing_doc)]
 
 extern crate collections;
+extern crate serialize;
+extern crate sync;
 #[cfg(test)]
 extern crate stdtest = "test";
 #[cfg(test)]
@@ -375,21 +745,115 @@ extern crate rand;
 #[cfg(test)]
 extern crate regex;
 
-pub use parse::Error;
+pub use parse::{Error, ErrorKind, CompileLimits};
+pub use parse::{SyntaxError, ProgramTooLarge, NestingTooDeep};
+pub use parse::BackrefsForbidden;
 pub use re::{Regex, Captures, SubCaptures, SubCapturesPos};
-pub use re::{FindCaptures, FindMatches};
-pub use re::{Replacer, NoExpand, RegexSplits, RegexSplitsN};
-pub use re::{quote, is_match};
+pub use re::{FindCaptures, FindMatches, FindOverlapping, CaptureCursor};
+pub use re::{FindCharMatches, FindCharCaptures, FindBranches};
+pub use re::{Replacer, NoExpand, RegexSplits, RegexSplitsN, RegexSplitsKeep};
+pub use re::{quote, is_match, canonicalize, Options};
+pub use re::{StartAnchor, StartAnchorAtBeginning, StartAnchorAtOffset};
+pub use vm::{StepLimitExceeded, Cancel, Cancelled};
+pub use set::{RegexSet, SetMatch};
+pub use replacer::MultiReplacer;
+pub use stream::{ReplaceWriter, Splitter};
+pub use template::{Template, TemplateError, PreserveCase};
+pub use bytes::{ByteRegex, ByteMatches};
+pub use encode::{DecodeError, DecodeErrorKind};
+pub use encode::{UnsupportedVersion, InvalidEncoding};
+pub use dfa::{DfaError, DfaErrorKind, DfaTooLarge, DfaUnsupported};
+pub use prefilter::Prefilter;
+pub use literals::PrefilterInfo;
+pub use memory::MemoryEstimate;
+pub use matcher::{Matcher, MatchStatus, Matched, NoMatchYet, CannotMatch};
+pub use matcher::{MatcherError, MatcherErrorKind, MatcherUnsupported};
+pub use matcher::MatcherInvalidUtf8;
+pub use pcre::{translate_pcre, PcreError, PcreErrorKind};
+pub use pcre::{PcreRecursion, PcreConditional, PcreControl, PcreUnsupported};
+pub use pcre::PcreSyntax;
+pub use enumerate::{EnumerateError, EnumerateErrorKind};
+pub use enumerate::{EnumerateInfinite, EnumerateTooMany, EnumerateUnsupported};
+pub use generate::{GenerateOptions, GenerateError};
+pub use grep::{GrepOptions, LongLines};
+pub use grep::{LongLinesError, LongLinesSkip, LongLinesTruncate};
+pub use scan::{CapturesDecoder, ScanError, ScanErrorKind};
+pub use scan::{ScanNoGroup, ScanMissingGroup, ScanInvalidValue};
+pub use scan::ScanUnsupported;
+pub use stats::{Stats, Engine, EngineLiterals, EngineFullDfa, EngineShiftOr};
+pub use stats::{EngineDfa, EngineNfa, EngineBacktrack, EngineOnePass};
+pub use stats::EnginePosix;
 
+mod backtrack;
+mod branch;
+mod bytes;
+mod charset;
 mod compile;
+mod dfa;
+mod differential;
+mod encode;
+mod enumerate;
+mod generate;
+mod grep;
+mod literals;
+mod matcher;
+mod memory;
+mod meta;
+mod onepass;
+mod parallel;
 mod parse;
+mod pcre;
+mod posix;
+mod prefilter;
 mod re;
+mod render;
+mod replacer;
+mod scan;
+mod segment;
+mod set;
+mod shiftor;
+mod stats;
+mod stream;
+mod template;
 mod vm;
 
 // FIXME(#13725) windows needs fixing.
 #[cfg(test, not(windows))]
 mod test;
 
+/// The syntax tree of a regular expression, as returned by `Regex::syntax`.
+///
+/// This is useful for tools that analyze expressions (e.g., linters or
+/// explainers), which then don't have to parse them again and hope that the
+/// flags are the same. A tree can be compiled with `Regex::from_syntax`,
+/// which saves tools that generate expressions from writing them out (and
+/// escaping them) themselves.
+pub mod syntax {
+    pub use parse::{
+        Ast, Nothing, Literal, Dot, Class, Begin, End, WordBoundary,
+        SegmentBoundary, Lookaround, Backref, Keep, Atomic, Capture, Cat, Alt,
+        Rep,
+    };
+    pub use parse::{Repeater, ZeroOne, ZeroMore, OneMore};
+    pub use parse::{Greed, Greedy, Ungreedy};
+    pub use parse::{
+        Flags, FLAG_EMPTY, FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL,
+        FLAG_SWAP_GREED, FLAG_NEGATED, FLAG_SEARCH, FLAG_ASCII, FLAG_UWORD,
+        FLAG_BEHIND, FLAG_FINALNL,
+    };
+    pub use segment::{Segment, Grapheme, Word};
+}
+
+/// Support for differential testing, which checks that every engine finds
+/// the same matches as the NFA simulation, for generated patterns and texts
+/// or for the input of a fuzzer. Divergences that are known can be allowed.
+pub mod difftest {
+    pub use differential::{Harness, Generator, Divergence};
+    pub use differential::{
+        Engine, EngineBacktrack, EngineDfa, EngineDefault, EngineCanonical,
+    };
+}
+
 /// The `program` module exists to support the `regex!` macro. Do not use.
 #[doc(hidden)]
 pub mod native {
@@ -411,13 +875,18 @@ pub mod native {
     pub use compile::{
         Program,
         OneChar, CharClass, Any, Save, Jump, Split,
-        Match, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+        Match, EmptyBegin, EmptyEnd, EmptyWordBoundary, EmptySegmentBoundary,
     };
     pub use parse::{
         FLAG_EMPTY, FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL,
-        FLAG_SWAP_GREED, FLAG_NEGATED,
+        FLAG_SWAP_GREED, FLAG_NEGATED, FLAG_SEARCH, FLAG_ASCII, FLAG_UWORD,
+        FLAG_FINALNL,
     };
-    pub use re::{Dynamic, Native};
+    pub use charset::CharSet;
+    pub use dfa::DfaCache;
+    pub use meta::MetaCache;
+    pub use re::{Dynamic, Native, LazyRegex, LazyProgram, LazySyntax};
+    pub use segment::{Segment, Grapheme, Word};
     pub use vm::{
         MatchKind, Exists, Location, Submatches,
         StepState, StepMatchEarlyReturn, StepMatch, StepContinue,
@@ -0,0 +1,902 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module extracts literal strings from the syntax of a regular
+// expression and builds "prefilters" out of them. A prefilter quickly finds
+// positions in the search text where a match *could* start. The matching
+// engines use it to skip over text whenever they have no threads alive.
+//
+// A prefilter never decides whether there's a match. It only narrows the
+// positions at which the real matching engine needs to look, which means
+// leftmost-first semantics and capture groups are unaffected.
+//
+// Literal prefixes are the most useful, since each occurrence is exactly
+// where a match would start. When there are no prefixes, a literal that must
+// appear later in every match (e.g., "@example.com" in
+// `[a-z]+@example\.com`) is used instead. If the number of bytes that can
+// precede that literal in a match is bounded, then the engines can still skip
+// to (a bit before) each occurrence. Otherwise, the literal can only be used
+// to reject a search text that doesn't contain it.
+//
+// Case insensitive literals are usable when they're ASCII. Their literals are
+// folded to lower case, and the scanners compare the text in either case
+// (Teddy and Aho-Corasick look up both cases of each letter in their tables,
+// which costs nothing per byte). A case insensitive letter whose upper case
+// is shared by a character that isn't ASCII (`i` and `ı`, `s` and `ſ`, or
+// `k` and the Kelvin sign) can match that character too, so it isn't
+// literal.
+
+use std::cmp;
+use std::fmt;
+use std::mem;
+use std::str;
+use std::uint;
+
+use encode;
+use encode::{Encoder, Decoder, DecodeError};
+use parse;
+use parse::{
+    Nothing, Literal, Dot, Class, Begin, End, WordBoundary, SegmentBoundary,
+    Lookaround, Backref, Keep, Atomic, Capture, Cat, Alt, Rep,
+    ZeroOne, ZeroMore, OneMore,
+    FLAG_NOCASE, FLAG_NEGATED,
+};
+use vm;
+
+// The maximum number of literals extracted from an expression. Beyond this,
+// scanning for the literals isn't much faster than running a matching engine.
+static MAX_LITERALS: uint = 64;
+
+// The maximum length of a single literal. Longer literals are truncated,
+// which is fine since a prefix of a prefix is still a prefix.
+static MAX_LITERAL_LEN: uint = 32;
+
+// The maximum number of literals for which Teddy is used instead of
+// Aho-Corasick. With more literals than this, the buckets fill up and too
+// many candidates need to be verified.
+static MAX_TEDDY_LITERALS: uint = 32;
+
+// The number of buckets used by Teddy. Each bucket is a bit in a byte.
+static TEDDY_BUCKETS: uint = 8;
+
+// The maximum number of leading bytes of each literal that Teddy uses to
+// find candidates.
+static MAX_TEDDY_MASKS: uint = 3;
+
+// The maximum number of characters in a class that is expanded into one
+// literal per character.
+static MAX_CLASS_SIZE: uint = 10;
+
+/// A set of literal prefixes such that every match of an expression starts
+/// with at least one of them.
+#[deriving(Clone)]
+struct Prefixes {
+    lits: Vec<Vec<u8>>,
+    // When true, every literal is a complete match of the expression (i.e.,
+    // the literal set is exactly the language of the expression).
+    complete: bool,
+    // When true, the literals are in lower case, and they match text in
+    // which ASCII letters are in either case.
+    nocase: bool,
+}
+
+impl Prefixes {
+    // Just the empty string, which permits a match to start anywhere.
+    fn empty(complete: bool) -> Prefixes {
+        Prefixes { lits: vec!(vec!()), complete: complete, nocase: false }
+    }
+
+    // Makes the literals case insensitive. If any of them has an ASCII
+    // letter, they now match more than the expression does.
+    fn fold(&mut self) {
+        if self.nocase {
+            return
+        }
+        let letters = self.lits.iter().any(|lit| {
+            lit.iter().any(|&b| is_ascii_letter(b))
+        });
+        if letters {
+            self.complete = false;
+        }
+        for lit in self.lits.mut_iter() {
+            for b in lit.mut_iter() {
+                *b = fold_byte(*b);
+            }
+        }
+        self.nocase = true;
+    }
+
+    fn is_useful(&self) -> bool {
+        self.lits.len() > 0 && self.lits.iter().all(|lit| lit.len() > 0)
+    }
+
+    // Appends the literals in `other` to every literal in `self`.
+    // Returns false (and leaves `self` untouched) if the product is too big.
+    fn cross(&mut self, other: &Prefixes) -> bool {
+        if self.lits.len() * other.lits.len() > MAX_LITERALS {
+            return false
+        }
+        let mut lits = Vec::with_capacity(self.lits.len() * other.lits.len());
+        for pre in self.lits.iter() {
+            for suf in other.lits.iter() {
+                let mut lit = pre.clone();
+                lit.push_all(suf.as_slice());
+                lit.truncate(MAX_LITERAL_LEN);
+                lits.push(lit);
+            }
+        }
+        self.lits = lits;
+        true
+    }
+}
+
+// Computes a set of literal prefixes for the expression given.
+fn prefixes(ast: &parse::Ast) -> Prefixes {
+    match *ast {
+        Nothing => Prefixes::empty(true),
+        Literal(c, flags) => {
+            if flags & FLAG_NOCASE == 0 {
+                return Prefixes {
+                    lits: vec!(char_bytes(c)),
+                    complete: true,
+                    nocase: false,
+                }
+            }
+            if !folds_ascii(c) {
+                return Prefixes::empty(false)
+            }
+            Prefixes {
+                lits: vec!(vec!(fold_byte(c as u8))),
+                complete: true,
+                nocase: true,
+            }
+        }
+        Class(ref ranges, flags) => {
+            if flags & (FLAG_NOCASE | FLAG_NEGATED) > 0 {
+                return Prefixes::empty(false)
+            }
+            let size = ranges.iter().fold(0u, |size, &(s, e)| {
+                size + (e as uint) - (s as uint) + 1
+            });
+            if size > MAX_CLASS_SIZE {
+                return Prefixes::empty(false)
+            }
+            let mut lits = vec!();
+            for &(s, e) in ranges.iter() {
+                for c in range(s as u32, e as u32 + 1) {
+                    // Surrogate code points can't appear in a class.
+                    match ::std::char::from_u32(c) {
+                        None => {}
+                        Some(c) => lits.push(char_bytes(c)),
+                    }
+                }
+            }
+            Prefixes { lits: lits, complete: true, nocase: false }
+        }
+        Dot(_) | Begin(_) | End(_) | WordBoundary(_)
+        | SegmentBoundary(_, _) | Lookaround(_, _) | Backref(_, _) | Keep => {
+            Prefixes::empty(false)
+        }
+        Atomic(ref x) | Capture(_, _, ref x) => prefixes(&**x),
+        Cat(ref xs) => prefixes_cat(xs.as_slice()),
+        Alt(ref x, ref y) => {
+            let (mut x, mut y) = (prefixes(&**x), prefixes(&**y));
+            if x.lits.len() + y.lits.len() > MAX_LITERALS {
+                return Prefixes::empty(false)
+            }
+            if x.nocase != y.nocase {
+                x.fold();
+                y.fold();
+            }
+            for lit in y.lits.move_iter() {
+                if !x.lits.contains(&lit) {
+                    x.lits.push(lit);
+                }
+            }
+            x.complete = x.complete && y.complete;
+            x
+        }
+        Rep(ref x, OneMore, _) => {
+            let mut x = prefixes(&**x);
+            x.complete = false;
+            x
+        }
+        Rep(_, ZeroOne, _) | Rep(_, ZeroMore, _) => Prefixes::empty(false),
+    }
+}
+
+// Computes a set of literal prefixes for the concatenation given.
+fn prefixes_cat(xs: &[~parse::Ast]) -> Prefixes {
+    let mut pres = Prefixes::empty(true);
+    for x in xs.iter() {
+        let mut next = prefixes(&**x);
+        if !next.is_useful() {
+            pres.complete = false;
+            break
+        }
+        if pres.nocase != next.nocase {
+            pres.fold();
+            next.fold();
+        }
+        if !pres.cross(&next) {
+            pres.complete = false;
+            break
+        }
+        pres.complete = pres.complete && next.complete;
+        if !pres.complete {
+            break
+        }
+    }
+    pres
+}
+
+// Finds a set of literals such that every match of the expression given
+// contains at least one of them, *after* some prefix of the match. The
+// literals are found in the prefix set of some suffix of a top-level
+// concatenation. Along with the literals, the maximum number of bytes that
+// can precede them in a match is returned (or `None` if it's unbounded).
+//
+// When there are several candidates, the ones with a bounded offset are
+// preferred, followed by the ones whose shortest literal is longest.
+fn inner(ast: &parse::Ast) -> Option<(Prefixes, Option<uint>)> {
+    let xs = match *ast {
+        Capture(_, _, ref x) => return inner(&**x),
+        Cat(ref xs) => xs.as_slice(),
+        _ => return None,
+    };
+    let mut best: Option<(Prefixes, Option<uint>)> = None;
+    let mut offset = Some(0u);
+    for i in range(1, xs.len()) {
+        offset = match (offset, max_len(&*xs[i - 1])) {
+            (Some(a), Some(b)) => Some(a + b),
+            _ => None,
+        };
+        let pres = prefixes_cat(xs.slice_from(i));
+        if !pres.is_useful() {
+            continue
+        }
+        let better = match best {
+            None => true,
+            Some((ref b, boffset)) => score(&pres, offset) > score(b, boffset),
+        };
+        if better {
+            best = Some((pres, offset));
+        }
+    }
+    best
+}
+
+fn score(pres: &Prefixes, offset: Option<uint>) -> (bool, uint) {
+    let shortest = pres.lits.iter().map(|l| l.len()).min().unwrap();
+    (offset.is_some(), shortest)
+}
+
+/// Returns the maximum length in bytes of any match of the expression given,
+/// or `None` if it's unbounded.
+pub fn max_len(ast: &parse::Ast) -> Option<uint> {
+    match *ast {
+        Nothing | Begin(_) | End(_) | WordBoundary(_)
+        | SegmentBoundary(_, _) | Lookaround(_, _) | Keep => Some(0),
+        // What the group matches isn't known here.
+        Backref(_, _) => None,
+        Literal(c, flags) => {
+            // A case insensitive literal could match a character with a
+            // longer encoding.
+            if flags & FLAG_NOCASE > 0 {
+                Some(4)
+            } else {
+                Some(c.len_utf8_bytes())
+            }
+        }
+        Dot(_) | Class(_, _) => Some(4),
+        Atomic(ref x) | Capture(_, _, ref x) => max_len(&**x),
+        Cat(ref xs) => {
+            xs.iter().fold(Some(0u), |len, x| {
+                match (len, max_len(&**x)) {
+                    (Some(a), Some(b)) => Some(a + b),
+                    _ => None,
+                }
+            })
+        }
+        Alt(ref x, ref y) => {
+            match (max_len(&**x), max_len(&**y)) {
+                (Some(a), Some(b)) => Some(cmp::max(a, b)),
+                _ => None,
+            }
+        }
+        Rep(ref x, ZeroOne, _) => max_len(&**x),
+        Rep(_, ZeroMore, _) | Rep(_, OneMore, _) => None,
+    }
+}
+
+fn char_bytes(c: char) -> Vec<u8> {
+    Vec::from_slice(::std::str::from_char(c).as_bytes())
+}
+
+// Returns true if a case insensitive `c` matches only the ASCII characters
+// that it matches in either case.
+fn folds_ascii(c: char) -> bool {
+    c.is_ascii() && !"iIkKsS".contains_char(c)
+}
+
+fn is_ascii_letter(b: u8) -> bool {
+    (b >= 'a' as u8 && b <= 'z' as u8) || (b >= 'A' as u8 && b <= 'Z' as u8)
+}
+
+// Returns the byte in lower case if it's an ASCII letter.
+#[inline]
+fn fold_byte(b: u8) -> u8 {
+    if b >= 'A' as u8 && b <= 'Z' as u8 { b + 32 } else { b }
+}
+
+// Returns true if `haystack` starts with `lit`, ignoring the case of ASCII
+// letters in `haystack`. (The literal is already in lower case.)
+fn starts_with_nocase(haystack: &[u8], lit: &[u8]) -> bool {
+    haystack.len() >= lit.len()
+    && lit.iter().zip(haystack.iter()).all(|(&l, &h)| l == fold_byte(h))
+}
+
+// Returns the number of bytes that the literals given occupy on the heap.
+fn lits_size(lits: &Vec<Vec<u8>>) -> uint {
+    lits.iter().fold(lits.capacity() * mem::size_of::<Vec<u8>>(), |n, lit| {
+        n + lit.capacity()
+    })
+}
+
+/// A prefilter finds the next position in the search text at which a match
+/// could start.
+#[deriving(Clone)]
+pub struct Prefilter {
+    // Every match contains one of these literals. They're in priority order
+    // (i.e., the order in which a backtracking engine would try them).
+    lits: Vec<Vec<u8>>,
+    // The maximum number of bytes in a match that can precede one of the
+    // literals. This is `Some(0)` when the literals are prefixes and `None`
+    // when the literals can appear anywhere in a match.
+    offset: Option<uint>,
+    // When true, the literals are *exactly* the strings matched by the
+    // expression.
+    complete: bool,
+    // When true, the literals are in lower case, and match text in which
+    // ASCII letters are in either case.
+    nocase: bool,
+    scanner: Scanner,
+}
+
+/// PrefilterInfo describes the literals that a regex's prefilter scans for,
+/// so that code outside of this crate (e.g., a planner deciding whether a
+/// substring index can serve a regex) can make the same choice the matching
+/// engines do.
+#[deriving(Clone, Eq, Show)]
+pub struct PrefilterInfo {
+    /// Every match starts with at least one of these literals. They're in
+    /// priority order, and each is at most 32 bytes long (a longer literal
+    /// is cut short, even in the middle of a character). This is empty when
+    /// the prefilter doesn't use prefixes.
+    pub prefixes: Vec<Vec<u8>>,
+    /// When there are no prefixes, every match contains one of these
+    /// literals after its start instead. This is empty when there are
+    /// prefixes, or when no literal could be found at all.
+    pub inner: Vec<Vec<u8>>,
+    /// The maximum number of bytes in a match that can precede one of the
+    /// inner literals, or `None` if there's no bound.
+    pub inner_offset: Option<uint>,
+    /// True if every match starts at the beginning of the text.
+    pub anchored: bool,
+    /// True if the prefixes are *exactly* the strings matched by the regex.
+    /// Otherwise, the literals are only necessary: a text that contains
+    /// none of them has no match, but one that does might not match either.
+    pub exact: bool,
+    /// True if the literals are in lower case, and match text in which ASCII
+    /// letters are in either case.
+    pub nocase: bool,
+}
+
+/// The strategy used to find occurrences of a prefilter's literals.
+#[deriving(Clone)]
+pub enum Scanner {
+    /// No prefilter could be built, so a match could start anywhere.
+    ScanNone,
+    /// There's exactly one literal, which is found with `memchr` and a
+    /// comparison of the remaining bytes.
+    ScanMemchr,
+    /// There are a few literals (or case insensitive ones), which are found
+    /// with the Teddy algorithm.
+    ScanTeddy(Teddy),
+    /// There are many literals, which are found with an Aho-Corasick
+    /// automaton.
+    ScanAho(AhoCorasick),
+}
+
+impl Prefilter {
+    /// Builds a prefilter for the expression given.
+    ///
+    /// Literal prefixes are preferred. If there are none, then a literal
+    /// that must appear somewhere else in every match is used instead.
+    pub fn new(ast: &parse::Ast) -> Prefilter {
+        let pres = prefixes(ast);
+        if pres.is_useful() {
+            // Truncated literals aren't complete matches.
+            let complete =
+                pres.complete
+                && pres.lits.iter().all(|l| l.len() < MAX_LITERAL_LEN);
+            return Prefilter::from_lits(pres.lits, Some(0), complete,
+                                        pres.nocase)
+        }
+        match inner(ast) {
+            None => Prefilter::none(),
+            Some((pres, offset)) => {
+                Prefilter::from_lits(pres.lits, offset, false, pres.nocase)
+            }
+        }
+    }
+
+    fn from_lits(lits: Vec<Vec<u8>>, offset: Option<uint>, complete: bool,
+                 nocase: bool) -> Prefilter {
+        let scanner =
+            if lits.len() == 1 && !nocase {
+                ScanMemchr
+            } else if lits.len() <= MAX_TEDDY_LITERALS {
+                ScanTeddy(Teddy::new(lits.as_slice(), nocase))
+            } else {
+                ScanAho(AhoCorasick::new(lits.as_slice(), nocase))
+            };
+        Prefilter {
+            lits: lits,
+            offset: offset,
+            complete: complete,
+            nocase: nocase,
+            scanner: scanner,
+        }
+    }
+
+    /// Returns a prefilter that never skips any text.
+    pub fn none() -> Prefilter {
+        Prefilter {
+            lits: vec!(),
+            offset: Some(0),
+            complete: false,
+            nocase: false,
+            scanner: ScanNone,
+        }
+    }
+
+    /// Encodes the literals of this prefilter. (Its scanner is built again
+    /// when it's decoded.)
+    pub fn encode(&self, e: &mut Encoder) {
+        e.write_uint(self.lits.len());
+        for lit in self.lits.iter() {
+            e.write_bytes(lit.as_slice());
+        }
+        e.write_option_uint(self.offset);
+        e.write_bool(self.complete);
+        e.write_bool(self.nocase);
+    }
+
+    /// Decodes a prefilter encoded by `encode`. The literals must be ones
+    /// that `new` could have found.
+    pub fn decode(d: &mut Decoder) -> Result<Prefilter, DecodeError> {
+        let n = try!(d.read_len());
+        let mut lits = Vec::with_capacity(n);
+        for _ in range(0, n) {
+            lits.push(try!(d.read_bytes()));
+        }
+        let offset = try!(d.read_option_uint());
+        let complete = try!(d.read_bool());
+        let nocase = try!(d.read_bool());
+        if lits.len() == 0 {
+            return Ok(Prefilter::none())
+        }
+        // A complete match can't end in the middle of a character, and case
+        // insensitive literals are in lower case.
+        if lits.len() > MAX_LITERALS
+           || lits.iter().any(|l| l.len() == 0 || l.len() > MAX_LITERAL_LEN)
+           || (complete && !lits.iter().all(|l| str::is_utf8(l.as_slice())))
+           || (nocase && lits.iter().any(|l| {
+                  l.iter().any(|&b| fold_byte(b) != b)
+              })) {
+            return encode::invalid("The encoded prefilter is invalid.")
+        }
+        Ok(Prefilter::from_lits(lits, offset, complete, nocase))
+    }
+
+    /// Returns true if this prefilter can be used to skip text with
+    /// `find`.
+    #[inline]
+    pub fn is_some(&self) -> bool {
+        match self.scanner {
+            ScanNone => false,
+            _ => self.offset.is_some(),
+        }
+    }
+
+    /// Returns true if the literals of this prefilter are exactly the
+    /// strings matched by its expression. In that case, `find_match` can be
+    /// used instead of a matching engine.
+    #[inline]
+    pub fn is_complete(&self) -> bool {
+        self.complete
+    }
+
+    /// Describes the literals of this prefilter. Whether its program is
+    /// anchored at the start is given, since the prefilter doesn't know.
+    pub fn info(&self, anchored: bool) -> PrefilterInfo {
+        let (prefixes, inner) = match (&self.scanner, self.offset) {
+            (&ScanNone, _) => (vec!(), vec!()),
+            (_, Some(0)) => (self.lits.clone(), vec!()),
+            _ => (vec!(), self.lits.clone()),
+        };
+        PrefilterInfo {
+            inner_offset: if inner.len() > 0 { self.offset } else { None },
+            prefixes: prefixes,
+            inner: inner,
+            anchored: anchored,
+            exact: self.complete,
+            nocase: self.nocase,
+        }
+    }
+
+    /// Returns the literals that every match starts with, and whether they
+    /// match text in which ASCII letters are in either case (in which case
+    /// they're in lower case). If matches don't all start with one of a few
+    /// literals, then `None` is returned.
+    pub fn prefixes<'a>(&'a self) -> Option<(&'a [Vec<u8>], bool)> {
+        match (&self.scanner, self.offset) {
+            (&ScanNone, _) => None,
+            (_, Some(0)) => Some((self.lits.as_slice(), self.nocase)),
+            _ => None,
+        }
+    }
+
+    /// Returns false only if there's definitely no match in `haystack`.
+    ///
+    /// Unlike `find`, this is useful even when the literals can appear
+    /// anywhere in a match, but it should only be called once per search.
+    pub fn may_match(&self, haystack: &[u8]) -> bool {
+        match self.scanner {
+            ScanNone => true,
+            _ => self.scan(haystack).is_some(),
+        }
+    }
+
+    /// Returns the earliest position in `haystack` at which a match could
+    /// start. If no match is possible, then `None` is returned.
+    ///
+    /// This should only be called when `is_some` returns true.
+    #[inline]
+    pub fn find(&self, haystack: &[u8]) -> Option<uint> {
+        let offset = self.offset.unwrap();
+        let mut s = match self.scan(haystack) {
+            None => return None,
+            Some(i) if i <= offset => return Some(0),
+            Some(i) => i - offset,
+        };
+        // A match can only start at a character boundary.
+        while s < haystack.len() && haystack[s] & 0xC0 == 0x80 {
+            s += 1;
+        }
+        Some(s)
+    }
+
+    /// Returns the leftmost-first match in `haystack`. This may only be used
+    /// when the prefilter is complete.
+    pub fn find_match(&self, haystack: &[u8]) -> Option<(uint, uint)> {
+        assert!(self.complete);
+        let s = match self.scan(haystack) {
+            None => return None,
+            Some(s) => s,
+        };
+        // Among the literals that start at `s`, the match is the one that
+        // comes first in priority order.
+        let rest = haystack.slice_from(s);
+        for lit in self.lits.iter() {
+            let found =
+                if self.nocase {
+                    starts_with_nocase(rest, lit.as_slice())
+                } else {
+                    rest.starts_with(lit.as_slice())
+                };
+            if found {
+                return Some((s, s + lit.len()))
+            }
+        }
+        unreachable!()
+    }
+
+    /// Returns the position of the leftmost occurrence of any literal (or
+    /// `0`, if there are none). Unlike `find`, this doesn't account for
+    /// where the literals are in a match.
+    #[inline]
+    pub fn scan(&self, haystack: &[u8]) -> Option<uint> {
+        match self.scanner {
+            ScanNone => Some(0),
+            ScanMemchr => vm::find_prefix(self.lits.get(0).as_slice(), haystack),
+            ScanTeddy(ref teddy) => teddy.find(haystack),
+            ScanAho(ref aho) => aho.find(haystack),
+        }
+    }
+
+    /// Returns the number of bytes that this prefilter occupies on the heap:
+    /// its literals and the tables of its scanner.
+    pub fn heap_size(&self) -> uint {
+        let scanner = match self.scanner {
+            ScanNone | ScanMemchr => 0,
+            ScanTeddy(ref teddy) => teddy.heap_size(),
+            ScanAho(ref aho) => aho.heap_size(),
+        };
+        lits_size(&self.lits) + scanner
+    }
+}
+
+impl fmt::Show for Prefilter {
+    /// Describes the prefilter in a human readable form.
+    /// The format is meant for debugging and may change.
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        let scanner = match self.scanner {
+            ScanNone => return write!(f.buf, "none"),
+            ScanMemchr => "memchr",
+            ScanTeddy(_) => "teddy",
+            ScanAho(_) => "aho-corasick",
+        };
+        let position = match self.offset {
+            Some(0) if self.complete => ~"complete",
+            Some(0) => ~"prefix",
+            Some(n) => format!("inner (at most {} bytes in)", n),
+            None => ~"inner (unbounded)",
+        };
+        let lits: Vec<~str> = self.lits.iter().map(|lit| {
+            str::from_utf8_lossy(lit.as_slice()).into_owned().escape_default()
+        }).collect();
+        let nocase =
+            if self.nocase { " (ASCII case insensitive)" } else { "" };
+        write!(f.buf, "{} literals [\"{}\"]{} using {}", position,
+               lits.as_slice().connect("\", \""), nocase, scanner)
+    }
+}
+
+/// An Aho-Corasick automaton for finding the leftmost occurrence of any of a
+/// set of literals.
+///
+/// The automaton is stored as a full transition table (with failure
+/// transitions already followed), so that searching costs exactly one table
+/// lookup per byte. For case insensitive literals, an upper case letter
+/// leads to the same state as its lower case.
+#[deriving(Clone)]
+pub struct AhoCorasick {
+    // `trans[s * 256 + b]` is the state reached from state `s` on byte `b`.
+    trans: Vec<uint>,
+    // For each state, the length of the longest literal ending at it (or `0`
+    // if no literal ends at it).
+    out: Vec<uint>,
+    // The length of the longest literal.
+    max_len: uint,
+}
+
+impl AhoCorasick {
+    /// Builds an automaton for the (non-empty) literals given, which are in
+    /// lower case if `nocase` is true.
+    pub fn new(lits: &[Vec<u8>], nocase: bool) -> AhoCorasick {
+        let mut aho = AhoCorasick {
+            trans: Vec::from_elem(256, uint::MAX),
+            out: vec!(0),
+            max_len: 0,
+        };
+        // Build the trie. State `0` is the root.
+        for lit in lits.iter() {
+            let mut s = 0;
+            for &b in lit.iter() {
+                let t = *aho.trans.get(s * 256 + b as uint);
+                s = if t != uint::MAX {
+                    t
+                } else {
+                    let t = aho.out.len();
+                    aho.out.push(0);
+                    aho.trans.grow(256, &uint::MAX);
+                    *aho.trans.get_mut(s * 256 + b as uint) = t;
+                    t
+                };
+            }
+            *aho.out.get_mut(s) = cmp::max(*aho.out.get(s), lit.len());
+            aho.max_len = cmp::max(aho.max_len, lit.len());
+        }
+        // Fill in the failure transitions in breadth first order, so that
+        // the transitions of a state's failure state are always complete.
+        let mut fail = Vec::from_elem(aho.out.len(), 0u);
+        let mut queue = vec!();
+        for b in range(0u, 256) {
+            let t = *aho.trans.get(b);
+            if t == uint::MAX {
+                *aho.trans.get_mut(b) = 0;
+            } else {
+                queue.push(t);
+            }
+        }
+        let mut qi = 0;
+        while qi < queue.len() {
+            let s = *queue.get(qi);
+            qi += 1;
+            let f = *fail.get(s);
+            for b in range(0u, 256) {
+                let ft = *aho.trans.get(f * 256 + b);
+                let t = *aho.trans.get(s * 256 + b);
+                if t == uint::MAX {
+                    *aho.trans.get_mut(s * 256 + b) = ft;
+                } else {
+                    *fail.get_mut(t) = ft;
+                    *aho.out.get_mut(t) = cmp::max(*aho.out.get(t),
+                                                   *aho.out.get(ft));
+                    queue.push(t);
+                }
+            }
+        }
+        if nocase {
+            for s in range(0, aho.out.len()) {
+                for b in range('A' as uint, 'Z' as uint + 1) {
+                    let t = *aho.trans.get(s * 256 + b + 32);
+                    *aho.trans.get_mut(s * 256 + b) = t;
+                }
+            }
+        }
+        aho
+    }
+
+    /// Returns the starting position of the leftmost occurrence of any
+    /// literal in `haystack`.
+    pub fn find(&self, haystack: &[u8]) -> Option<uint> {
+        let mut s = 0;
+        let mut best: Option<uint> = None;
+        for (i, &b) in haystack.iter().enumerate() {
+            s = *self.trans.get(s * 256 + b as uint);
+            let len = *self.out.get(s);
+            if len > 0 {
+                let start = i + 1 - len;
+                best = Some(match best {
+                    None => start,
+                    Some(best) => cmp::min(best, start),
+                });
+            }
+            // An occurrence that starts before `best` must end before this
+            // position, so there's nothing better left to find.
+            match best {
+                Some(best) if i + 1 >= best + self.max_len => break,
+                _ => {}
+            }
+        }
+        best
+    }
+
+    /// Returns the number of bytes that the automaton occupies on the heap.
+    pub fn heap_size(&self) -> uint {
+        (self.trans.capacity() + self.out.capacity()) * uint::BYTES
+    }
+}
+
+/// An implementation of the Teddy algorithm (from Intel's Hyperscan) for
+/// finding the leftmost occurrence of any of a small set of literals.
+///
+/// Literals are split into 8 buckets. For each of the first (up to) 3 bytes
+/// of the literals, there's a table mapping a byte to the set of buckets
+/// containing a literal with that byte at that offset. A position in the
+/// search text is a candidate only if the tables agree on at least one
+/// bucket, in which case the literals in those buckets are compared.
+///
+/// The SIMD version of Teddy looks up 16 or 32 positions at a time with
+/// vector shuffles (which is why it splits every table by nibble). This
+/// compiler provides no access to those instructions, so this is the portable
+/// formulation, which does the same lookups one position at a time with
+/// whole byte tables. Its behavior is identical on every architecture. Since
+/// the lookups for a position are independent of each other (unlike the
+/// state transitions of Aho-Corasick), it's still faster than Aho-Corasick
+/// for a small number of literals.
+#[deriving(Clone)]
+pub struct Teddy {
+    lits: Vec<Vec<u8>>,
+    // The indices of the literals in each bucket.
+    buckets: Vec<Vec<uint>>,
+    // `masks[k * 256 + b]` is the set of buckets with a literal whose byte at
+    // offset `k` is `b`.
+    masks: Vec<u8>,
+    // The number of tables in `masks`, which is at most the length of the
+    // shortest literal.
+    nmasks: uint,
+    // Whether the literals are in lower case, and match text in which ASCII
+    // letters are in either case.
+    nocase: bool,
+}
+
+impl Teddy {
+    /// Builds a Teddy searcher for the (non-empty) literals given, which are
+    /// in lower case if `nocase` is true.
+    pub fn new(lits: &[Vec<u8>], nocase: bool) -> Teddy {
+        let shortest = lits.iter().map(|l| l.len()).min().unwrap();
+        let nmasks = cmp::min(MAX_TEDDY_MASKS, shortest);
+        let mut teddy = Teddy {
+            lits: Vec::from_slice(lits),
+            buckets: Vec::from_elem(TEDDY_BUCKETS, vec!()),
+            masks: Vec::from_elem(nmasks * 256, 0u8),
+            nmasks: nmasks,
+            nocase: nocase,
+        };
+        for (i, lit) in lits.iter().enumerate() {
+            let bucket = i * TEDDY_BUCKETS / lits.len();
+            teddy.buckets.get_mut(bucket).push(i);
+            for k in range(0, nmasks) {
+                let b = *lit.get(k);
+                *teddy.masks.get_mut(k * 256 + b as uint) |= 1 << bucket;
+                if nocase && is_ascii_letter(b) {
+                    let upper = b as uint - 32;
+                    *teddy.masks.get_mut(k * 256 + upper) |= 1 << bucket;
+                }
+            }
+        }
+        teddy
+    }
+
+    /// Returns the starting position of the leftmost occurrence of any
+    /// literal in `haystack`.
+    pub fn find(&self, haystack: &[u8]) -> Option<uint> {
+        if haystack.len() < self.nmasks {
+            return None
+        }
+        // Every literal is at least `nmasks` bytes long, so none can start
+        // after this position.
+        let last = haystack.len() - self.nmasks;
+        let masks = self.masks.as_slice();
+        let mut i = 0;
+        while i <= last {
+            let mut m = masks[haystack[i] as uint];
+            if self.nmasks > 1 {
+                m &= masks[256 + haystack[i + 1] as uint];
+            }
+            if self.nmasks > 2 {
+                m &= masks[512 + haystack[i + 2] as uint];
+            }
+            if m != 0 && self.verify(haystack.slice_from(i), m) {
+                return Some(i)
+            }
+            i += 1;
+        }
+        None
+    }
+
+    // Returns true if any literal in the buckets given is a prefix of
+    // `haystack`.
+    fn verify(&self, haystack: &[u8], buckets: u8) -> bool {
+        for b in range(0, TEDDY_BUCKETS) {
+            if buckets & (1 << b) == 0 {
+                continue
+            }
+            for &li in self.buckets.get(b).iter() {
+                let lit = self.lits.get(li).as_slice();
+                let found =
+                    if self.nocase {
+                        starts_with_nocase(haystack, lit)
+                    } else {
+                        haystack.starts_with(lit)
+                    };
+                if found {
+                    return true
+                }
+            }
+        }
+        false
+    }
+
+    /// Returns the number of bytes that the literals and the tables occupy
+    /// on the heap.
+    pub fn heap_size(&self) -> uint {
+        let buckets = self.buckets.iter().fold(0, |n, b| {
+            n + b.capacity() * uint::BYTES
+        });
+        lits_size(&self.lits)
+        + self.buckets.capacity() * mem::size_of::<Vec<uint>>() + buckets
+        + self.masks.capacity()
+    }
+}
@@ -37,9 +37,11 @@ use syntax::print::pprust;
 use regex::Regex;
 use regex::native::{
     OneChar, CharClass, Any, Save, Jump, Split,
-    Match, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    Match, EmptyBegin, EmptyEnd, EmptyWordBoundary, EmptySegmentBoundary,
     Program, Dynamic, Native,
-    FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL, FLAG_NEGATED,
+    FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL, FLAG_NEGATED, FLAG_SEARCH,
+    FLAG_ASCII, FLAG_UWORD, FLAG_FINALNL,
+    Grapheme, Word,
 };
 
 /// For the `regex!` syntax extension. Do not use.
@@ -87,9 +89,24 @@ fn native(cx: &mut ExtCtxt, sp: codemap::Span, tts: &[ast::TokenTree])
         }
     };
     let prog = match re.p {
-        Dynamic(ref prog) => prog.clone(),
+        Dynamic(ref prog) => (**prog).clone(),
         Native(_) => unreachable!(),
     };
+    if prog.lookaround {
+        cx.span_err(sp, "lookaround is not supported by regex!; \
+                         use Regex::new instead");
+        return DummyResult::any(sp)
+    }
+    if prog.backrefs {
+        cx.span_err(sp, "backreferences are not supported by regex!; \
+                         use Regex::new instead");
+        return DummyResult::any(sp)
+    }
+    if prog.keep {
+        cx.span_err(sp, "\\K is not supported by regex!; \
+                         use Regex::new instead");
+        return DummyResult::any(sp)
+    }
 
     let mut gen = NfaGen {
         cx: &*cx, sp: sp, prog: prog,
@@ -137,7 +154,7 @@ impl<'a> NfaGen<'a> {
 
         quote_expr!(self.cx, {
 fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
-            start: uint, end: uint) -> Vec<Option<uint>> {
+            start: uint, end: uint, anchored: bool) -> Vec<Option<uint>> {
     #![allow(unused_imports)]
     use regex::native::{
         MatchKind, Exists, Location, Submatches,
@@ -149,8 +166,9 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
         which: which,
         input: input,
         ic: 0,
+        start: start,
         chars: CharReader::new(input),
-    }.run(start, end);
+    }.run(start, end, anchored);
 
     type Captures = [Option<uint>, ..$num_cap_locs];
 
@@ -158,12 +176,14 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
         which: MatchKind,
         input: &'t str,
         ic: uint,
+        start: uint,
         chars: CharReader<'t>,
     }
 
     impl<'t> Nfa<'t> {
         #[allow(unused_variable)]
-        fn run(&mut self, start: uint, end: uint) -> Vec<Option<uint>> {
+        fn run(&mut self, start: uint, end: uint,
+               anchored: bool) -> Vec<Option<uint>> {
             let mut matched = false;
             let prefix_bytes: &[u8] = &$prefix_bytes;
             let mut clist = &mut Threads::new(self.which);
@@ -178,9 +198,15 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
                     if matched {
                         break
                     }
-                    $check_prefix
+                    if anchored && self.ic > start {
+                        break
+                    }
+                    if !anchored {
+                        $check_prefix
+                    }
                 }
-                if clist.size == 0 || (!$prefix_anchor && !matched) {
+                if clist.size == 0
+                   || (!$prefix_anchor && !anchored && !matched) {
                     self.add(clist, 0, &mut groups)
                 }
 
@@ -311,6 +337,13 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
     original: ~$regex,
     names: ~$cap_names,
     p: ::regex::native::Native(exec),
+    dfa: ::regex::native::DfaCache::new(),
+    full: ::regex::native::LazyRegex::new(),
+    offset: ::regex::native::LazyRegex::new(),
+    longest: ::regex::native::LazyRegex::new(),
+    meta: ::regex::native::MetaCache::new(),
+    program: ::regex::native::LazyProgram::new(),
+    ast: ::regex::native::LazySyntax::new(),
 }
         })
     }
@@ -324,7 +357,9 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
                 EmptyBegin(flags) => {
                     let nl = '\n';
                     let cond =
-                        if flags & FLAG_MULTI > 0 {
+                        if flags & FLAG_SEARCH > 0 {
+                            quote_expr!(self.cx, self.ic == self.start)
+                        } else if flags & FLAG_MULTI > 0 {
                             quote_expr!(self.cx,
                                 self.chars.is_begin()
                                 || self.chars.prev == Some($nl)
@@ -340,7 +375,13 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
                 EmptyEnd(flags) => {
                     let nl = '\n';
                     let cond =
-                        if flags & FLAG_MULTI > 0 {
+                        if flags & FLAG_FINALNL > 0 {
+                            quote_expr!(self.cx,
+                                self.chars.is_end()
+                                || (self.chars.cur == Some($nl)
+                                    && self.chars.is_last())
+                            )
+                        } else if flags & FLAG_MULTI > 0 {
                             quote_expr!(self.cx,
                                 self.chars.is_end()
                                 || self.chars.cur == Some($nl)
@@ -354,11 +395,40 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
                     })
                 }
                 EmptyWordBoundary(flags) => {
+                    let boundary =
+                        if flags & FLAG_ASCII > 0 {
+                            quote_expr!(self.cx,
+                                        self.chars.is_ascii_word_boundary())
+                        } else if flags & FLAG_UWORD > 0 {
+                            quote_expr!(self.cx,
+                                        self.chars.is_unicode_word_boundary())
+                        } else {
+                            quote_expr!(self.cx, self.chars.is_word_boundary())
+                        };
                     let cond =
                         if flags & FLAG_NEGATED > 0 {
-                            quote_expr!(self.cx, !self.chars.is_word_boundary())
+                            quote_expr!(self.cx, !$boundary)
                         } else {
-                            quote_expr!(self.cx, self.chars.is_word_boundary())
+                            boundary
+                        };
+                    quote_expr!(self.cx, {
+                        nlist.add_empty($pc);
+                        if $cond { self.add(nlist, $nextpc, &mut *groups) }
+                    })
+                }
+                EmptySegmentBoundary(seg, flags) => {
+                    let seg = match seg {
+                        Grapheme => quote_expr!(self.cx,
+                                                ::regex::native::Grapheme),
+                        Word => quote_expr!(self.cx, ::regex::native::Word),
+                    };
+                    let boundary = quote_expr!(self.cx,
+                        self.chars.is_segment_boundary($seg));
+                    let cond =
+                        if flags & FLAG_NEGATED > 0 {
+                            quote_expr!(self.cx, !$boundary)
+                        } else {
+                            boundary
                         };
                     quote_expr!(self.cx, {
                         nlist.add_empty($pc);
@@ -461,7 +531,7 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
                         })
                     }
                 }
-                CharClass(ref ranges, flags) => {
+                CharClass(ref set, flags) => {
                     let negate = flags & FLAG_NEGATED > 0;
                     let casei = flags & FLAG_NOCASE > 0;
                     let get_char =
@@ -476,7 +546,8 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
                         } else {
                             quote_expr!(self.cx, found)
                         };
-                    let mranges = self.match_class(casei, ranges.as_slice());
+                    let ranges = set.ranges.as_slice();
+                    let mranges = self.match_class(casei, ranges);
                     quote_expr!(self.cx, {
                         if self.chars.prev.is_some() {
                             let c = $get_char;
@@ -499,7 +570,8 @@ fn exec<'t>(which: ::regex::native::MatchKind, input: &'t str,
                         })
                     }
                 }
-                // EmptyBegin, EmptyEnd, EmptyWordBoundary, Save, Jump, Split
+                // EmptyBegin, EmptyEnd, EmptyWordBoundary,
+                // EmptySegmentBoundary, Save, Jump, Split
                 _ => quote_expr!(self.cx, {}),
             };
             self.arm_inst(pc, body)
@@ -0,0 +1,431 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module searches text that's fed to it in pieces, as they arrive,
+// for the first match of a regex. Unlike the searches of stream.rs, which
+// look at a window of the text again and again, it runs the NFA simulation
+// of vm.rs one character at a time and keeps its threads between pieces, so
+// each byte is looked at once and the text itself is never kept.
+//
+// The simulation steps over a character once the character after it is
+// known, since the threads it adds are at the position between the two
+// (where `$` and `\b` need to see both). So the matcher holds on to the last
+// character it was fed, along with the bytes of a character that was cut
+// off at the end of a piece. That is all it keeps of the text: the threads
+// hold the positions where their matches started, so capture positions
+// don't need the text to be kept either. (So there's no limit on the bytes
+// kept to configure: they're never more than a character and three bytes of
+// the next one.)
+//
+// A match is reported as soon as no thread that could still find a match
+// that's preferred to it is alive, which is when the same search of all of
+// the text would stop. Until then, it may still be replaced (by a longer
+// match of a higher priority alternative), or an end of the input may be
+// needed to decide it (e.g., for `a$`), which is what `close` is for.
+
+use std::cmp;
+use std::fmt;
+use std::mem;
+use std::str;
+
+use compile::{
+    Program,
+    Match, OneChar, CharClass, Any, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    EmptySegmentBoundary, EmptyLook, Cut, LookMatch, GroupRef, Save, Jump,
+    Split,
+};
+use parse::{FLAG_SEARCH, FLAG_FINALNL};
+use re::{Regex, Dynamic, Native};
+use stream;
+use vm;
+use vm::{CaptureLocs, Threads, Location};
+
+/// MatchStatus is what a `Matcher` knows about the first match in the text
+/// it has been fed so far.
+#[deriving(Clone, Eq, Show)]
+pub enum MatchStatus {
+    /// The first match starts and ends at the byte offsets given (counted
+    /// from the start of all of the text), and no more text can change it.
+    Matched(uint, uint),
+    /// There's no match yet, but the text that follows may complete one (or
+    /// start one).
+    NoMatchYet,
+    /// There's no match, whatever text follows (e.g., because the regex is
+    /// anchored at the start of the text, and it didn't match there).
+    CannotMatch,
+}
+
+/// MatcherError describes why a regex can't be fed text, or why the text
+/// fed to it was rejected.
+#[deriving(Clone, Eq)]
+pub struct MatcherError {
+    /// A message describing the error.
+    pub msg: ~str,
+    /// What went wrong.
+    pub kind: MatcherErrorKind,
+}
+
+/// MatcherErrorKind tells the ways that feeding text to a regex can fail
+/// apart.
+#[deriving(Clone, Eq, Show)]
+pub enum MatcherErrorKind {
+    /// The regex can't be searched incrementally: it was compiled with the
+    /// `regex!` macro, or it uses lookaround, backreferences, `\K`, `\Z`,
+    /// `\b{g}` or `\b{wb}`, which need more of the text than the matcher
+    /// keeps.
+    MatcherUnsupported,
+    /// The text isn't valid UTF-8.
+    MatcherInvalidUtf8,
+}
+
+impl fmt::Show for MatcherError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "Regex matcher error: {}", self.msg)
+    }
+}
+
+fn err<T>(kind: MatcherErrorKind, msg: ~str) -> Result<T, MatcherError> {
+    Err(MatcherError { msg: msg, kind: kind })
+}
+
+/// Matcher searches text that it's fed in pieces for the first match of a
+/// regex (see `Regex::matcher`).
+pub struct Matcher<'r> {
+    prog: &'r Program,
+    longest: bool,
+    // Whether matches can only start at the start of the text.
+    anchored: bool,
+    clist: Threads,
+    nlist: Threads,
+    // The position of the character that's stepped over next, and the
+    // characters before it and at it. The one at it is `None` until the
+    // character after it has been fed.
+    at: uint,
+    prev: Option<char>,
+    pending: Option<char>,
+    // The bytes of a character that was cut off by the end of a piece.
+    partial: Vec<u8>,
+    // The best match found so far.
+    best: CaptureLocs,
+    matched: bool,
+    // What's known for good, once it is.
+    status: Option<MatchStatus>,
+}
+
+/// Returns a matcher for `re` that hasn't been fed any text.
+pub fn new<'r>(re: &'r Regex) -> Result<Matcher<'r>, MatcherError> {
+    let prog = match re.p {
+        Dynamic(ref prog) => &**prog,
+        Native(_) => {
+            return err(MatcherUnsupported,
+                       ~"Regexes compiled with regex! can't be fed text.")
+        }
+    };
+    if !can_settle(prog) {
+        return err(MatcherUnsupported,
+                   format!("The regex '{}' needs more of the text than a \
+                            matcher keeps.", re.original))
+    }
+    let ninsts = prog.insts.len();
+    Ok(Matcher {
+        prog: prog,
+        longest: re.is_longest(),
+        anchored: prog.anchored_start(),
+        clist: Threads::new(Location, ninsts, 1),
+        nlist: Threads::new(Location, ninsts, 1),
+        at: 0,
+        prev: None,
+        pending: None,
+        partial: vec!(),
+        best: vec![None, None],
+        matched: false,
+        status: None,
+    })
+}
+
+impl<'r> Matcher<'r> {
+    /// Feeds the next piece of the text to the matcher, and returns what's
+    /// known about the first match so far. A piece may end in the middle of
+    /// a character. Once the match is known (or it's known that there's
+    /// none), the rest of the text is ignored.
+    ///
+    /// It's an error if the text isn't valid UTF-8, in which case the piece
+    /// is ignored.
+    pub fn feed(&mut self, bytes: &[u8]) -> Result<MatchStatus, MatcherError> {
+        if self.status.is_some() {
+            return Ok(self.status())
+        }
+        let mut buf = mem::replace(&mut self.partial, vec!());
+        let kept = buf.len();
+        buf.push_all(bytes);
+        let valid = stream::utf8_prefix(buf.as_slice());
+        let text =
+            if starts_char(buf.slice_from(valid)) {
+                str::from_utf8(buf.slice_to(valid))
+            } else {
+                None
+            };
+        match text {
+            None => {
+                self.partial = Vec::from_slice(buf.slice_to(kept));
+                return invalid_utf8()
+            }
+            Some(text) => {
+                for c in text.chars() {
+                    match self.pending {
+                        None => {}
+                        Some(p) => self.step(Some(p), Some(c)),
+                    }
+                    if self.status.is_some() {
+                        return Ok(self.status())
+                    }
+                    self.pending = Some(c);
+                }
+            }
+        }
+        self.partial = Vec::from_slice(buf.slice_from(valid));
+        Ok(self.status())
+    }
+
+    /// Tells the matcher that the text has ended, and returns the first
+    /// match in it, or `CannotMatch` if there's none. (It's never
+    /// `NoMatchYet`.)
+    ///
+    /// It's an error if the text ends in the middle of a character.
+    pub fn close(&mut self) -> Result<MatchStatus, MatcherError> {
+        if self.status.is_none() {
+            if self.partial.len() > 0 {
+                return invalid_utf8()
+            }
+            match self.pending.take() {
+                None => {}
+                Some(p) => self.step(Some(p), None),
+            }
+            if self.status.is_none() {
+                self.step(None, None);
+            }
+            if self.status.is_none() {
+                self.status = Some(CannotMatch);
+            }
+        }
+        Ok(self.status())
+    }
+
+    /// Returns what's known about the first match in the text fed so far,
+    /// like `feed` does.
+    pub fn status(&self) -> MatchStatus {
+        self.status.unwrap_or(NoMatchYet)
+    }
+
+    // Runs the simulation at the position of the next character, `cur`
+    // (which is `None` at the end of the text), given the character after
+    // it.
+    fn step(&mut self, cur: Option<char>, next: Option<char>) {
+        let prog = self.prog;
+        // This simulates a preceding `.*?`, like `vm::run` does.
+        if !self.matched && (self.clist.size == 0 || !self.anchored) {
+            let mut groups = [None, None];
+            add(prog, &mut self.clist, 0, groups.as_mut_slice(), self.at,
+                self.prev, cur);
+        }
+        let next_at = self.at + cur.map_or(0, |c| c.len_utf8_bytes());
+        let mut i = 0;
+        while i < self.clist.size {
+            let pc = self.clist.pc(i);
+            let late = self.longest && {
+                vm::starts_after(self.clist.groups(i), self.best.as_slice())
+            };
+            if late {
+                // A match starting further left has been found already.
+                i += 1;
+                continue
+            }
+            match *prog.insts.get(pc) {
+                Match => {
+                    let caps = {
+                        let caps = self.clist.groups(i);
+                        [caps[0], caps[1]]
+                    };
+                    if !self.longest
+                       || vm::is_longer(caps.as_slice(), self.best.as_slice()) {
+                        *self.best.get_mut(0) = caps[0];
+                        *self.best.get_mut(1) = caps[1];
+                    }
+                    self.matched = true;
+                    // Threads of lower priority may still find a longer
+                    // match.
+                    if !self.longest {
+                        self.clist.empty()
+                    }
+                }
+                EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_)
+                | Save(_) | Jump(_) | Split(_, _) => {}
+                ref inst => {
+                    if vm::char_matches(inst, cur) {
+                        add(prog, &mut self.nlist, pc + 1,
+                            self.clist.groups(i), next_at, cur, next);
+                    }
+                }
+            }
+            i += 1;
+        }
+        mem::swap(&mut self.clist, &mut self.nlist);
+        self.nlist.empty();
+        self.at = next_at;
+        self.prev = cur;
+        if self.clist.size == 0 {
+            if self.matched {
+                let (s, e) = (self.best.get(0).unwrap(),
+                              self.best.get(1).unwrap());
+                self.status = Some(Matched(s, e));
+            } else if self.anchored {
+                self.status = Some(CannotMatch);
+            }
+        }
+    }
+}
+
+/// Returns true if `settle` can decide the matches of `prog`.
+pub fn can_settle(prog: &Program) -> bool {
+    !prog.backtrack_only() && !prog.segments && !ends_final_newline(prog)
+}
+
+/// Runs the simulation over `text` from `from`, as if more text followed
+/// it, and returns the first match starting at or after `from` (or that
+/// there's none) if no text that may follow can change it. Otherwise,
+/// returns the position before which no match starts, and whether a match
+/// that may still change has been found. `prog` must pass `can_settle`.
+///
+/// This is how a window of a stream is searched when the length of its
+/// matches isn't bounded (see stream.rs).
+pub fn settle(prog: &Program, longest: bool, text: &str, from: uint)
+             -> Result<Option<(uint, uint)>, (uint, bool)> {
+    let ninsts = prog.insts.len();
+    let mut m = Matcher {
+        prog: prog,
+        longest: longest,
+        anchored: prog.anchored_start(),
+        clist: Threads::new(Location, ninsts, 1),
+        nlist: Threads::new(Location, ninsts, 1),
+        at: from,
+        prev: if from == 0 {
+            None
+        } else {
+            Some(text.char_range_at_reverse(from).ch)
+        },
+        pending: None,
+        partial: vec!(),
+        best: vec![None, None],
+        matched: false,
+        status: None,
+    };
+    for c in text.slice_from(from).chars() {
+        match m.pending {
+            None => {}
+            Some(p) => m.step(Some(p), Some(c)),
+        }
+        match m.status {
+            Some(Matched(s, e)) => return Ok(Some((s, e))),
+            Some(_) => return Ok(None),
+            None => {}
+        }
+        m.pending = Some(c);
+    }
+    // The character that's pending hasn't been stepped over, so a match may
+    // still start at it, or after it.
+    let mut start = m.at;
+    for i in range(0, m.clist.size) {
+        start = cmp::min(start, m.clist.groups(i)[0].unwrap_or(start));
+    }
+    Err((start, m.matched))
+}
+
+// Adds the thread at `pc` to `nlist` at the position `at`, between the
+// characters `prev` and `cur`, along with the threads that the instructions
+// that consume no character lead to. This is `add` of vm.rs for `Location`.
+fn add(prog: &Program, nlist: &mut Threads, pc: uint,
+       groups: &mut [Option<uint>], at: uint, prev: Option<char>,
+       cur: Option<char>) {
+    if nlist.contains(pc) {
+        return
+    }
+    match *prog.insts.get(pc) {
+        // The matcher always starts at the start of the text, so `\G`
+        // matches only there.
+        EmptyBegin(flags) if flags & FLAG_SEARCH > 0 => {
+            nlist.add(pc, groups, true);
+            if at == 0 {
+                add(prog, nlist, pc + 1, groups, at, prev, cur)
+            }
+        }
+        EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_) => {
+            nlist.add(pc, groups, true);
+            // `\Z` is rejected by `new`, so whether `cur` is the last
+            // character doesn't matter.
+            if vm::empty_matches(prog.insts.get(pc), prev, cur, false) {
+                add(prog, nlist, pc + 1, groups, at, prev, cur)
+            }
+        }
+        Save(slot) => {
+            nlist.add(pc, groups, true);
+            if slot <= 1 {
+                let old = groups[slot];
+                groups[slot] = Some(at);
+                add(prog, nlist, pc + 1, groups, at, prev, cur);
+                groups[slot] = old;
+            } else {
+                add(prog, nlist, pc + 1, groups, at, prev, cur)
+            }
+        }
+        Jump(to) => {
+            nlist.add(pc, groups, true);
+            add(prog, nlist, to, groups, at, prev, cur)
+        }
+        Split(x, y) => {
+            nlist.add(pc, groups, true);
+            add(prog, nlist, x, groups, at, prev, cur);
+            add(prog, nlist, y, groups, at, prev, cur);
+        }
+        Match | OneChar(_, _) | CharClass(_, _) | Any(_) => {
+            nlist.add(pc, groups, false);
+        }
+        // Programs with these are rejected by `new`.
+        EmptySegmentBoundary(_, _) | EmptyLook(_, _) | Cut(_) | LookMatch
+        | GroupRef(_, _) => {}
+    }
+}
+
+// Returns true if the program has `\Z`, which would need to know whether
+// the character after a new line has been fed yet.
+fn ends_final_newline(prog: &Program) -> bool {
+    prog.insts.iter().any(|inst| {
+        match *inst {
+            EmptyEnd(flags) => flags & FLAG_FINALNL > 0,
+            _ => false,
+        }
+    })
+}
+
+// Returns true if `bytes`, which are left over at the end of a piece (see
+// `stream::utf8_prefix`), start a character that the next piece may
+// complete. Otherwise they'd be kept, and fail every piece that follows.
+fn starts_char(bytes: &[u8]) -> bool {
+    match bytes.head() {
+        None => true,
+        Some(&b) => {
+            b >= 0xC2 && b <= 0xF4
+            && bytes.slice_from(1).iter().all(|&c| c & 0xC0 == 0x80)
+        }
+    }
+}
+
+fn invalid_utf8<T>() -> Result<T, MatcherError> {
+    err(MatcherInvalidUtf8, ~"The text isn't valid UTF-8.")
+}
@@ -0,0 +1,157 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module estimates the memory that a regex occupies and that searches
+// with it allocate, for deciding whether to accept an expression (e.g., one
+// given by a user) before searching with it.
+//
+// The sizes of a program are recorded when it's built, from the capacity of
+// the vectors it owns (see `Program::heap_size`), so the estimate follows
+// what was allocated rather than the length of the expression. What it
+// leaves out is the overhead of the allocator for each allocation, and the
+// states of the lazy DFAs, which are only bounded by their size limit. The
+// memory of a search is computed from the same functions the engines use to
+// decide what they allocate (e.g., `backtrack::visited_bits`).
+//
+// The estimate isn't checked against what is actually allocated: the
+// allocator doesn't report how much memory is in use, so there's no test
+// that compiles a batch of regexes and compares the estimates with it.
+
+use std::cmp;
+use std::mem;
+
+use backtrack;
+use compile::Program;
+use dfa::DfaCache;
+use vm::Threads;
+
+/// MemoryEstimate breaks down the memory used by a regex into what it
+/// occupies for as long as it lives and what each search with it allocates
+/// (see `Regex::memory_estimate`). Every size is in bytes.
+#[deriving(Clone, Eq, Show)]
+pub struct MemoryEstimate {
+    /// The instructions of the compiled program (with their character
+    /// classes), and those of the reverse program that finds where matches
+    /// start.
+    pub program: uint,
+    /// The literals of the prefilters and the tables they're scanned for
+    /// with.
+    pub prefilter: uint,
+    /// The one-pass analyses and the bit-parallel matchers of the programs.
+    pub analyses: uint,
+    /// The tables computed by `Regex::compile_dfa`.
+    pub full_dfa: uint,
+    /// The most that the states of the lazy DFAs may occupy. They're kept
+    /// between searches, and there's one for each search running at the
+    /// same time (up to 8 for each direction), so it's usually much less.
+    pub lazy_dfa: uint,
+    /// What a search with the NFA simulation allocates, whatever the
+    /// length of the text: its two lists of threads, each of which has a
+    /// copy of the capture locations.
+    pub threads: uint,
+    // The number of instructions of the program and the backtrack limit,
+    // which decide how large the visited set of the backtracker gets.
+    insts: uint,
+    backtrack_limit: uint,
+    // Whether only the backtracker can search the program, whatever the
+    // length of the text, and the most bits its visited set may then take
+    // (none, with backreferences).
+    backtrack_only: bool,
+    visited_limit: uint,
+    // The capture locations that the backtracker keeps (and, with
+    // backreferences, where each `Split` was last taken).
+    slots: uint,
+}
+
+impl MemoryEstimate {
+    /// Returns the memory that the regex occupies while it isn't searched,
+    /// not counting the states of its lazy DFAs.
+    pub fn fixed(&self) -> uint {
+        self.program + self.prefilter + self.analyses + self.full_dfa
+    }
+
+    /// Returns the memory that a search for the capture groups of a match
+    /// in a text of `len` bytes allocates (besides the states of a lazy
+    /// DFA). Texts that are short enough for the backtracker (see
+    /// `Regex::set_backtrack_limit`) take a bit for each instruction at each
+    /// position, and longer ones take the threads of the NFA simulation.
+    /// Regexes that only the backtracker can search (e.g., with lookaround)
+    /// take a bit for each instruction at each position of any text, up to
+    /// the bound of its visited set.
+    pub fn per_search(&self, len: uint) -> uint {
+        let bits = backtrack::visited_bits(self.insts, len);
+        if self.backtrack_only {
+            visited_size(cmp::min(bits, self.visited_limit)) + self.slots
+        } else if bits <= self.backtrack_limit {
+            // The check of `backtrack::should_exec`.
+            visited_size(bits) + self.slots
+        } else {
+            self.threads
+        }
+    }
+}
+
+// Returns the bytes that a visited set of `bits` bits takes.
+fn visited_size(bits: uint) -> uint {
+    (bits + 31) / 32 * mem::size_of::<u32>()
+}
+
+/// Returns the estimate for a dynamic regex with the program and cache
+/// given.
+pub fn estimate(prog: &Program, dfa: &DfaCache) -> MemoryEstimate {
+    let mut est = estimate_searches(prog, dfa.backtrack_limit());
+    est.program = prog.heap_size();
+    est.prefilter = prog.prefilter.heap_size();
+    est.analyses = prog.analyses_size();
+    match dfa.reverse() {
+        None => {}
+        Some(rprog) => {
+            est.program += rprog.heap_size();
+            est.prefilter += rprog.prefilter.heap_size();
+            est.analyses += rprog.analyses_size();
+        }
+    }
+    est.full_dfa = dfa.full_size();
+    est.lazy_dfa = dfa.max_size();
+    est
+}
+
+/// Returns the estimate for a program that's part of the binary (as the
+/// one of a regex compiled with the `regex!` macro is), which occupies no
+/// memory of its own and is never searched with a DFA or the backtracker.
+pub fn estimate_static(prog: &Program) -> MemoryEstimate {
+    estimate_searches(prog, 0)
+}
+
+// Returns the estimate of what searches with the program given allocate,
+// with every other size left at zero.
+fn estimate_searches(prog: &Program, backtrack_limit: uint)
+                    -> MemoryEstimate {
+    let ncaps = prog.num_captures();
+    let (visited_limit, splits) =
+        if prog.backrefs {
+            (0, prog.insts.len())
+        } else {
+            (backtrack::visited_limit(prog, backtrack_limit), 0)
+        };
+    MemoryEstimate {
+        program: 0,
+        prefilter: 0,
+        analyses: 0,
+        full_dfa: 0,
+        lazy_dfa: 0,
+        threads: 2 * Threads::heap_size(prog.insts.len(), ncaps),
+        insts: prog.insts.len(),
+        backtrack_limit: backtrack_limit,
+        backtrack_only: prog.backtrack_only(),
+        visited_limit: visited_limit,
+        slots: (ncaps * 2 + splits) * mem::size_of::<Option<uint>>(),
+    }
+}
@@ -0,0 +1,302 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module computes static facts about the matches of an expression from
+// its syntax tree: how long they can be, whether they're anchored, and which
+// literals they must contain. Every fact is conservative. For example, the
+// minimum length is never more than the length of the shortest match, but
+// it may be less (e.g., for a case insensitive literal, which could match a
+// character with a shorter encoding).
+//
+// `\K` moves the start of the match reported, so that the part of the
+// expression before it isn't in the match. For an expression with `\K`, the
+// minimum length is zero, the start isn't anchored and no literals are
+// required.
+
+use std::cmp;
+use sync::{Arc, Mutex};
+
+use literals;
+use parse;
+use parse::{
+    Nothing, Literal, Dot, Class, Begin, End, WordBoundary, SegmentBoundary,
+    Lookaround, Backref, Keep, Atomic, Capture, Cat, Alt, Rep,
+    ZeroOne, ZeroMore, OneMore,
+    FLAG_NOCASE, FLAG_NEGATED, FLAG_MULTI, FLAG_SEARCH, FLAG_FINALNL,
+};
+
+/// Static facts about the matches of an expression.
+#[deriving(Clone, Show)]
+pub struct Metadata {
+    /// The minimum length in bytes of a match.
+    pub min_len: uint,
+    /// The maximum length in bytes of a match, or `None` if it's unbounded.
+    pub max_len: Option<uint>,
+    /// The only string matched, if the expression matches a fixed string.
+    pub literal: Option<~str>,
+    /// Whether every match starts at the beginning of the text.
+    pub anchored_start: bool,
+    /// Whether every match ends at the end of the text.
+    pub anchored_end: bool,
+    /// Literals that each appear in every match.
+    pub required: Vec<~str>,
+}
+
+impl Metadata {
+    /// Computes the facts about the expression given.
+    pub fn new(ast: &parse::Ast) -> Metadata {
+        let keep = has_keep(ast);
+        Metadata {
+            min_len: if keep { 0 } else { min_len(ast) },
+            max_len: literals::max_len(ast),
+            literal: literal(ast).map(|s| s.into_owned()),
+            anchored_start: !keep && anchored_start(ast),
+            anchored_end: anchored_end(ast),
+            required: if keep { vec!() } else { required(ast) },
+        }
+    }
+}
+
+/// MetaCache holds the metadata of a regex, which is computed the first time
+/// it's needed (unless the regex was compiled from a syntax tree, which
+/// computes it right away).
+///
+/// It's exported to support the `regex!` syntax extension. Do not use.
+#[doc(hidden)]
+pub struct MetaCache {
+    meta: Mutex<Option<Arc<Metadata>>>,
+}
+
+impl MetaCache {
+    /// Creates a cache without metadata.
+    pub fn new() -> MetaCache {
+        MetaCache { meta: Mutex::new(None) }
+    }
+
+    /// Creates a cache with the metadata given.
+    pub fn with(meta: Metadata) -> MetaCache {
+        MetaCache { meta: Mutex::new(Some(Arc::new(meta))) }
+    }
+
+    /// Returns the metadata, computing it with `init` if it hasn't been yet.
+    pub fn get(&self, init: || -> Metadata) -> Arc<Metadata> {
+        let mut guard = self.meta.lock();
+        let meta: &mut Option<Arc<Metadata>> = &mut *guard;
+        if meta.is_none() {
+            *meta = Some(Arc::new(init()));
+        }
+        meta.get_ref().clone()
+    }
+}
+
+impl Clone for MetaCache {
+    /// Clones share the metadata once it has been computed.
+    fn clone(&self) -> MetaCache {
+        let guard = self.meta.lock();
+        MetaCache { meta: Mutex::new((*guard).clone()) }
+    }
+}
+
+// Returns the minimum length in bytes of any match of the expression given.
+fn min_len(ast: &parse::Ast) -> uint {
+    match *ast {
+        Nothing | Begin(_) | End(_) | WordBoundary(_)
+        | SegmentBoundary(_, _) | Lookaround(_, _) | Keep => 0,
+        // The group may have matched the empty string.
+        Backref(_, _) => 0,
+        Literal(c, flags) => {
+            // A case insensitive literal could match a character with a
+            // shorter encoding (e.g., `ſ` matches `s`).
+            if flags & FLAG_NOCASE > 0 { 1 } else { c.len_utf8_bytes() }
+        }
+        Class(ref ranges, flags) => {
+            // The ranges are sorted, so the first character is the one with
+            // the shortest encoding.
+            if flags & (FLAG_NOCASE | FLAG_NEGATED) > 0 || ranges.is_empty() {
+                1
+            } else {
+                let (s, _) = *ranges.get(0);
+                s.len_utf8_bytes()
+            }
+        }
+        Dot(_) => 1,
+        Atomic(ref x) | Capture(_, _, ref x) => min_len(&**x),
+        Cat(ref xs) => xs.iter().fold(0, |len, x| len + min_len(&**x)),
+        Alt(ref x, ref y) => cmp::min(min_len(&**x), min_len(&**y)),
+        Rep(ref x, OneMore, _) => min_len(&**x),
+        Rep(_, ZeroOne, _) | Rep(_, ZeroMore, _) => 0,
+    }
+}
+
+// Returns the string matched by the expression given, if it matches only
+// one (case sensitively, and without assertions).
+fn literal(ast: &parse::Ast) -> Option<StrBuf> {
+    let mut lit = StrBuf::new();
+    if push_literal(ast, &mut lit) { Some(lit) } else { None }
+}
+
+fn push_literal(ast: &parse::Ast, lit: &mut StrBuf) -> bool {
+    match *ast {
+        Nothing => true,
+        Literal(c, flags) if flags & FLAG_NOCASE == 0 => {
+            lit.push_char(c);
+            true
+        }
+        Class(ref ranges, flags) => {
+            match single_char(ranges.as_slice(), flags) {
+                Some(c) => { lit.push_char(c); true }
+                None => false,
+            }
+        }
+        Atomic(ref x) | Capture(_, _, ref x) => push_literal(&**x, lit),
+        Cat(ref xs) => xs.iter().all(|x| push_literal(&**x, lit)),
+        _ => false,
+    }
+}
+
+// Returns the only character that a class matches, if there's one.
+fn single_char(ranges: &[(char, char)], flags: parse::Flags) -> Option<char> {
+    match ranges {
+        [(s, e)] if s == e && flags & (FLAG_NOCASE | FLAG_NEGATED) == 0 => {
+            Some(s)
+        }
+        _ => None,
+    }
+}
+
+// Returns true if every match of the expression given starts at the
+// beginning of the text.
+fn anchored_start(ast: &parse::Ast) -> bool {
+    match *ast {
+        Begin(flags) => flags & (FLAG_MULTI | FLAG_SEARCH) == 0,
+        Atomic(ref x) | Capture(_, _, ref x) | Rep(ref x, OneMore, _) => {
+            anchored_start(&**x)
+        }
+        Alt(ref x, ref y) => anchored_start(&**x) && anchored_start(&**y),
+        Cat(ref xs) => anchored_cat(xs.iter(), anchored_start),
+        _ => false,
+    }
+}
+
+// Returns true if every match of the expression given ends at the end of the
+// text.
+fn anchored_end(ast: &parse::Ast) -> bool {
+    match *ast {
+        End(flags) => flags & (FLAG_MULTI | FLAG_FINALNL) == 0,
+        Atomic(ref x) | Capture(_, _, ref x) | Rep(ref x, OneMore, _) => {
+            anchored_end(&**x)
+        }
+        Alt(ref x, ref y) => anchored_end(&**x) && anchored_end(&**y),
+        Cat(ref xs) => anchored_cat(xs.iter().rev(), anchored_end),
+        _ => false,
+    }
+}
+
+// Returns true if one of the expressions given is anchored, and only
+// expressions that never consume a character come before it.
+fn anchored_cat<'a, I: Iterator<&'a ~parse::Ast>>
+               (mut xs: I, anchored: fn(&parse::Ast) -> bool) -> bool {
+    for x in xs {
+        if anchored(&**x) {
+            return true
+        }
+        if literals::max_len(&**x) != Some(0) {
+            return false
+        }
+    }
+    false
+}
+
+fn has_keep(ast: &parse::Ast) -> bool {
+    match *ast {
+        Keep => true,
+        Lookaround(ref x, _) | Atomic(ref x) | Capture(_, _, ref x)
+        | Rep(ref x, _, _) => has_keep(&**x),
+        Cat(ref xs) => xs.iter().any(|x| has_keep(&**x)),
+        Alt(ref x, ref y) => has_keep(&**x) || has_keep(&**y),
+        _ => false,
+    }
+}
+
+// Collects the literals that appear in every match of an expression. Each
+// literal is a run of characters that are matched one after the other (and
+// case sensitively). Assertions don't end a run, since they don't consume
+// anything.
+struct Required {
+    run: StrBuf,
+    lits: Vec<~str>,
+}
+
+impl Required {
+    fn add(&mut self, ast: &parse::Ast) {
+        match *ast {
+            Nothing | Begin(_) | End(_) | WordBoundary(_)
+            | SegmentBoundary(_, _) | Lookaround(_, _) | Keep => {}
+            Literal(c, flags) if flags & FLAG_NOCASE == 0 => {
+                self.run.push_char(c)
+            }
+            Atomic(ref x) | Capture(_, _, ref x) => self.add(&**x),
+            Cat(ref xs) => {
+                for x in xs.iter() {
+                    self.add(&**x);
+                }
+            }
+            Alt(ref x, ref y) => {
+                self.flush();
+                // Only the literals that both alternates require are
+                // required.
+                let (x, y) = (required(&**x), required(&**y));
+                for lit in x.move_iter() {
+                    if y.contains(&lit) {
+                        self.push(lit);
+                    }
+                }
+            }
+            Class(ref ranges, flags) => {
+                match single_char(ranges.as_slice(), flags) {
+                    Some(c) => self.run.push_char(c),
+                    None => self.flush(),
+                }
+            }
+            Rep(ref x, OneMore, _) => {
+                // What's repeated appears at least once, but what's next to
+                // it isn't adjacent to all of it.
+                self.flush();
+                self.add(&**x);
+                self.flush();
+            }
+            Literal(_, _) | Dot(_) | Backref(_, _) | Rep(_, _, _) => {
+                self.flush()
+            }
+        }
+    }
+
+    fn flush(&mut self) {
+        if self.run.len() > 0 {
+            let lit = self.run.as_slice().to_owned();
+            self.push(lit);
+            self.run = StrBuf::new();
+        }
+    }
+
+    fn push(&mut self, lit: ~str) {
+        if !self.lits.contains(&lit) {
+            self.lits.push(lit);
+        }
+    }
+}
+
+// Returns the literals that appear in every match of the expression given.
+fn required(ast: &parse::Ast) -> Vec<~str> {
+    let mut req = Required { run: StrBuf::new(), lits: vec!() };
+    req.add(ast);
+    req.flush();
+    req.lits
+}
@@ -0,0 +1,368 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module implements a matching engine for "one-pass" programs. A
+// program is one-pass if it's anchored at the beginning of the text and if,
+// at every `Split` instruction, the next character (or the end of the text)
+// determines which branch has to be taken. Such a program can be executed
+// with a single thread, which makes resolving capture groups very cheap.
+//
+// The analysis computes, for both branches of every `Split`, the set of
+// characters that can be consumed next and whether (and under which
+// condition) a `Match` can be reached without consuming anything. The
+// program is one-pass if these never overlap. Zero-width assertions are
+// assumed to always succeed, except for `$`, which is common enough at the
+// end of anchored expressions that it's worth being precise about.
+//
+// Since the branches of a one-pass program never overlap, taking the first
+// branch that can proceed is exactly what the NFA's leftmost-first semantics
+// would produce, down to every capture group.
+
+use std::cmp;
+use std::mem;
+use std::uint;
+
+use compile::{
+    Program, Inst,
+    Match, OneChar, CharClass, Any, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    EmptySegmentBoundary, Save, Jump, Split,
+};
+use parse::{FLAG_NOCASE, FLAG_NEGATED, FLAG_MULTI, FLAG_DOTNL, FLAG_SEARCH};
+use parse::FLAG_FINALNL;
+use vm;
+use vm::CaptureLocs;
+
+/// The condition under which a `Match` instruction can be reached from some
+/// instruction without consuming a character. The order matters: later
+/// conditions are weaker.
+#[deriving(Clone, Eq, TotalEq, Ord, TotalOrd)]
+enum MatchCond {
+    AtEnd,
+    AtEndOrFinalNewline,
+    AtEndOrNewline,
+    Always,
+}
+
+impl MatchCond {
+    // `last` tells whether `cur` is the last character of the input.
+    fn holds(&self, cur: Option<char>, last: bool) -> bool {
+        match *self {
+            AtEnd => cur.is_none(),
+            AtEndOrFinalNewline => cur.is_none() || (last && cur == Some('\n')),
+            AtEndOrNewline => cur.is_none() || cur == Some('\n'),
+            Always => true,
+        }
+    }
+
+    // Returns true if this condition can hold when one of the characters
+    // given is next.
+    fn overlaps(&self, chars: &[(u32, u32)]) -> bool {
+        match *self {
+            AtEnd => false,
+            AtEndOrFinalNewline | AtEndOrNewline => {
+                contains(chars, '\n' as u32)
+            }
+            Always => chars.len() > 0,
+        }
+    }
+}
+
+/// What can happen next in the first branch of a `Split` instruction.
+#[deriving(Clone)]
+struct Branch {
+    // The character consuming instructions reachable without consuming a
+    // character.
+    consumers: Vec<uint>,
+    // Whether a `Match` is reachable without consuming a character.
+    matches: Option<MatchCond>,
+}
+
+/// OnePass is the result of a successful one-pass analysis of a program.
+#[deriving(Clone)]
+pub struct OnePass {
+    // For every `Split` instruction, what can happen in its first branch.
+    splits: Vec<Option<Branch>>,
+}
+
+// Everything reachable from an instruction without consuming a character.
+struct Leaves {
+    consumers: Vec<uint>,
+    chars: Vec<(u32, u32)>,
+    matches: Option<MatchCond>,
+}
+
+impl OnePass {
+    /// Returns the one-pass analysis of the program given, or `None` if the
+    /// program isn't one-pass.
+    pub fn new(prog: &Program) -> Option<OnePass> {
+        if prog.backtrack_only() {
+            return None
+        }
+        match prog.insts.as_slice().get(1) {
+            Some(&EmptyBegin(flags)) if flags & FLAG_MULTI == 0 => {}
+            _ => return None,
+        }
+        let mut splits = Vec::from_elem(prog.insts.len(), None);
+        for (pc, inst) in prog.insts.iter().enumerate() {
+            let (x, y) = match *inst {
+                Split(x, y) => (leaves(prog, x), leaves(prog, y)),
+                _ => continue,
+            };
+            if x.matches.is_some() && y.matches.is_some() {
+                return None
+            }
+            if overlaps(x.chars.as_slice(), y.chars.as_slice()) {
+                return None
+            }
+            match x.matches {
+                Some(ref cond) if cond.overlaps(y.chars.as_slice()) => {
+                    return None
+                }
+                _ => {}
+            }
+            match y.matches {
+                Some(ref cond) if cond.overlaps(x.chars.as_slice()) => {
+                    return None
+                }
+                _ => {}
+            }
+            *splits.get_mut(pc) = Some(Branch {
+                consumers: x.consumers,
+                matches: x.matches,
+            });
+        }
+        Some(OnePass { splits: splits })
+    }
+
+    /// Executes the program at `start`, returning the capture locations of
+    /// the match (if any), exactly as `vm::run` would with `Submatches`.
+    ///
+    /// The search is anchored, so this can only find a match when `start` is
+    /// at the beginning of the input (or the program starts with `\G`).
+    pub fn exec(&self, prog: &Program, input: &str, start: uint)
+               -> CaptureLocs {
+        let mut caps = vec!();
+        self.exec_into(prog, input, start, &mut caps);
+        caps
+    }
+
+    /// Executes the program like `exec`, except that the capture locations
+    /// are appended to `caps` rather than returned.
+    pub fn exec_into(&self, prog: &Program, input: &str, start: uint,
+                     caps: &mut CaptureLocs) {
+        let base = caps.len();
+        caps.grow(prog.num_captures() * 2, &None);
+        let groups = caps.mut_slice_from(base);
+        if !self.run(prog, input, start, groups) {
+            for slot in groups.mut_iter() {
+                *slot = None;
+            }
+        }
+    }
+
+    // Runs the program at `start` and returns whether it matches, writing
+    // the locations it saves to `caps`.
+    fn run(&self, prog: &Program, input: &str, start: uint,
+           caps: &mut [Option<uint>]) -> bool {
+        let mut prev =
+            if start == 0 {
+                None
+            } else {
+                Some(input.char_range_at_reverse(start).ch)
+            };
+        let mut ic = start;
+        let mut pc = 0;
+        // The instructions visited at the current position, which detects
+        // loops that don't consume any characters.
+        let mut seen = Vec::from_elem(prog.insts.len(), false);
+        loop {
+            let (cur, next) =
+                if ic < input.len() {
+                    let r = input.char_range_at(ic);
+                    (Some(r.ch), r.next)
+                } else {
+                    (None, ic)
+                };
+            for s in seen.mut_iter() {
+                *s = false;
+            }
+            // Follow empty transitions until a character can be consumed.
+            loop {
+                if *seen.get(pc) {
+                    return false
+                }
+                *seen.get_mut(pc) = true;
+                match *prog.insts.get(pc) {
+                    Match => return true,
+                    Save(slot) => {
+                        caps[slot] = Some(ic);
+                        pc += 1;
+                    }
+                    Jump(to) => pc = to,
+                    Split(x, y) => {
+                        let first = self.splits.get(pc).get_ref();
+                        let last = ic + 1 == input.len();
+                        pc =
+                            if first.proceeds(prog, cur, last) { x } else { y };
+                    }
+                    EmptyBegin(flags) if flags & FLAG_SEARCH > 0 => {
+                        if ic != start {
+                            return false
+                        }
+                        pc += 1;
+                    }
+                    EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_) => {
+                        let inst = prog.insts.get(pc);
+                        let last = ic + 1 == input.len();
+                        if !vm::empty_matches(inst, prev, cur, last) {
+                            return false
+                        }
+                        pc += 1;
+                    }
+                    EmptySegmentBoundary(seg, flags) => {
+                        if !vm::segment_matches(seg, flags, input, ic) {
+                            return false
+                        }
+                        pc += 1;
+                    }
+                    ref inst => {
+                        if !vm::char_matches(inst, cur) {
+                            return false
+                        }
+                        pc += 1;
+                        break
+                    }
+                }
+            }
+            prev = cur;
+            ic = next;
+        }
+    }
+
+    /// Returns the number of bytes that the analysis occupies on the heap.
+    pub fn heap_size(&self) -> uint {
+        self.splits.iter().fold(0, |n, split| {
+            match *split {
+                None => n,
+                Some(ref b) => n + b.consumers.capacity() * uint::BYTES,
+            }
+        }) + self.splits.capacity() * mem::size_of::<Option<Branch>>()
+    }
+}
+
+impl Branch {
+    fn proceeds(&self, prog: &Program, cur: Option<char>, last: bool)
+               -> bool {
+        match self.matches {
+            Some(ref cond) if cond.holds(cur, last) => return true,
+            _ => {}
+        }
+        self.consumers.iter().any(|&pc| {
+            vm::char_matches(prog.insts.get(pc), cur)
+        })
+    }
+}
+
+fn leaves(prog: &Program, pc: uint) -> Leaves {
+    let mut leaves = Leaves { consumers: vec!(), chars: vec!(), matches: None };
+    // Instructions are visited at most once per condition.
+    let mut seen = Vec::from_elem(prog.insts.len() * 4, false);
+    walk(prog, pc, Always, seen.as_mut_slice(), &mut leaves);
+    leaves
+}
+
+fn walk(prog: &Program, pc: uint, cond: MatchCond, seen: &mut [bool],
+        leaves: &mut Leaves) {
+    let key = pc * 4 + cond as uint;
+    if seen[key] {
+        return
+    }
+    seen[key] = true;
+    match *prog.insts.get(pc) {
+        Match => {
+            leaves.matches = Some(match leaves.matches {
+                None => cond,
+                Some(old) => cmp::max(old, cond),
+            });
+        }
+        EmptyEnd(flags) => {
+            let end =
+                if flags & FLAG_MULTI > 0 {
+                    AtEndOrNewline
+                } else if flags & FLAG_FINALNL > 0 {
+                    AtEndOrFinalNewline
+                } else {
+                    AtEnd
+                };
+            walk(prog, pc + 1, cmp::min(cond, end), seen, leaves)
+        }
+        EmptyBegin(_) | EmptyWordBoundary(_) | EmptySegmentBoundary(_, _)
+        | Save(_) => {
+            walk(prog, pc + 1, cond, seen, leaves)
+        }
+        Jump(to) => walk(prog, to, cond, seen, leaves),
+        Split(x, y) => {
+            walk(prog, x, cond, seen, leaves);
+            walk(prog, y, cond, seen, leaves);
+        }
+        ref inst => {
+            leaves.consumers.push(pc);
+            leaves.chars.push_all_move(chars(inst));
+        }
+    }
+}
+
+static MAX_CHAR: u32 = 0x10FFFF;
+
+// Returns the set of characters matched by the instruction given, as sorted
+// ranges. Case insensitive instructions conservatively match everything.
+fn chars(inst: &Inst) -> Vec<(u32, u32)> {
+    match *inst {
+        OneChar(_, flags) if flags & FLAG_NOCASE > 0 => vec!((0, MAX_CHAR)),
+        OneChar(c, _) => vec!((c as u32, c as u32)),
+        CharClass(_, flags) if flags & FLAG_NOCASE > 0 => vec!((0, MAX_CHAR)),
+        CharClass(ref set, flags) => {
+            let ranges: Vec<(u32, u32)> = set.ranges.iter().map(|&(s, e)| {
+                (s as u32, e as u32)
+            }).collect();
+            if flags & FLAG_NEGATED > 0 {
+                negate(ranges.as_slice())
+            } else {
+                ranges
+            }
+        }
+        Any(flags) if flags & FLAG_DOTNL > 0 => vec!((0, MAX_CHAR)),
+        Any(_) => negate(&[('\n' as u32, '\n' as u32)]),
+        _ => vec!(),
+    }
+}
+
+fn negate(ranges: &[(u32, u32)]) -> Vec<(u32, u32)> {
+    let mut negated = vec!();
+    let mut next = 0;
+    for &(s, e) in ranges.iter() {
+        if s > next {
+            negated.push((next, s - 1));
+        }
+        next = e + 1;
+    }
+    if next <= MAX_CHAR {
+        negated.push((next, MAX_CHAR));
+    }
+    negated
+}
+
+fn overlaps(xs: &[(u32, u32)], ys: &[(u32, u32)]) -> bool {
+    xs.iter().any(|&(xs, xe)| ys.iter().any(|&(ys, ye)| xs <= ye && ys <= xe))
+}
+
+fn contains(ranges: &[(u32, u32)], c: u32) -> bool {
+    ranges.iter().any(|&(s, e)| s <= c && c <= e)
+}
@@ -0,0 +1,194 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module finds every match of a regex in a big text with many tasks at
+// once. The text is split into chunks, and each task finds the matches that
+// start in its chunk as if `find_iter` had started at the beginning of the
+// chunk. A match found that way isn't necessarily a match found by
+// `find_iter` on all of the text, since a match from an earlier chunk may
+// run into the chunk (or end where an empty match would be found).
+//
+// So the chunks are stitched together in order: starting where the matches
+// of the chunks before it left off, the search continues one match at a
+// time, until it's in the state that the chunk's task was in when it found
+// one of its matches (where the next search starts, and where the last
+// match ended). From there on, the matches are the same, so the rest of the
+// chunk's matches are used as they are. Usually, the states agree after a
+// match or two, or right away when no match crosses into the chunk.
+//
+// Every task searches all of the text (with a clone of the regex), so that
+// assertions near the bounds of a chunk see the characters around them.
+
+use std::cmp;
+use sync::Arc;
+
+use re::{Regex, accept_match};
+
+// The smallest chunk searched by a task of its own.
+static MIN_CHUNK: uint = 1 << 12;
+
+// The state of a search for successive matches, as in `FindMatches`.
+#[deriving(Clone, Eq)]
+struct Resume {
+    // Where the next search starts.
+    at: uint,
+    // Where the last match ended, since an empty match can't be found
+    // there.
+    last: Option<uint>,
+}
+
+// The matches that start in a chunk, each with the state of the search that
+// found it, and the state after the last one.
+struct Chunk {
+    found: Vec<(Resume, (uint, uint))>,
+    end: Resume,
+}
+
+/// Returns the location of every match of `re` in `text`, exactly like
+/// `find_iter`, with up to `workers` tasks searching at once.
+pub fn find_all(re: &Regex, text: &str, workers: uint) -> Vec<(uint, uint)> {
+    let workers = cmp::min(workers, text.len() / MIN_CHUNK);
+    if workers <= 1 {
+        return re.find_iter(text).collect()
+    }
+    let bounds = chunk_bounds(text, workers);
+    // The tasks can't borrow the text, so they share a copy.
+    let shared = Arc::new((re.clone(), text.to_owned()));
+    let (tx, rx) = channel();
+    for (i, &(start, end)) in bounds.iter().enumerate().skip(1) {
+        let (shared, tx) = (shared.clone(), tx.clone());
+        spawn(proc() {
+            let &(ref re, ref text) = &*shared;
+            tx.send((i, scan(re, text.as_slice(), start, end)));
+        });
+    }
+
+    // The first chunk is searched while the others are. Its matches are
+    // the first ones found by `find_iter`.
+    let mut chunks = Vec::from_fn(bounds.len(), |_| None);
+    let (_, first_end) = *bounds.get(0);
+    *chunks.get_mut(0) = Some(scan(re, text, 0, first_end));
+    for _ in range(1, bounds.len()) {
+        let (i, chunk) = rx.recv();
+        *chunks.get_mut(i) = Some(chunk);
+    }
+
+    let mut found = vec!();
+    let mut state = Resume { at: 0, last: None };
+    for (i, &(_, end)) in bounds.iter().enumerate() {
+        let chunk = chunks.get(i).get_ref();
+        stitch(re, text, &mut found, &mut state, chunk, end);
+    }
+    found
+}
+
+// Splits the text into chunks that start at character boundaries. The last
+// one ends past the end of the text, since an empty match may be found at
+// the end.
+fn chunk_bounds(text: &str, chunks: uint) -> Vec<(uint, uint)> {
+    let mut bounds = vec!();
+    let mut start = 0;
+    for i in range(1, chunks) {
+        let mut end = cmp::max(start, text.len() / chunks * i);
+        while !text.is_char_boundary(end) {
+            end += 1;
+        }
+        if end > start {
+            bounds.push((start, end));
+            start = end;
+        }
+    }
+    bounds.push((start, text.len() + 1));
+    bounds
+}
+
+// Finds the matches that start between `start` and `end`, as if the search
+// started at `start`.
+fn scan(re: &Regex, text: &str, start: uint, end: uint) -> Chunk {
+    let mut state = Resume { at: start, last: None };
+    let mut found = vec!();
+    while state.at < end {
+        match step(re, text, &state) {
+            Some(((s, e), next)) if s < end => {
+                found.push((state, (s, e)));
+                state = next;
+            }
+            _ => break,
+        }
+    }
+    Chunk { found: found, end: state }
+}
+
+// Adds the matches that start before `end` to `found`, continuing from the
+// state given until it's one that the chunk's search was in.
+fn stitch(re: &Regex, text: &str, found: &mut Vec<(uint, uint)>,
+          state: &mut Resume, chunk: &Chunk, end: uint) {
+    // The states of the chunk's search only move forward, and so does the
+    // state here, so they're compared in one pass.
+    let states: Vec<&Resume> = chunk.found.iter().map(|&(ref s, _)| s)
+                                      .collect();
+    let mut k = 0;
+    while state.at < end {
+        while k < states.len() && states.get(k).at < state.at {
+            k += 1;
+        }
+        let mut same = None;
+        for j in range(k, states.len()) {
+            if states.get(j).at > state.at {
+                break
+            }
+            if *states.get(j) == &*state {
+                same = Some(j);
+                break
+            }
+        }
+        if same.is_none() && chunk.end == *state {
+            same = Some(states.len());
+        }
+        match same {
+            Some(j) => {
+                for &(_, m) in chunk.found.slice_from(j).iter() {
+                    found.push(m);
+                }
+                *state = chunk.end.clone();
+                return
+            }
+            None => {}
+        }
+        match step(re, text, &*state) {
+            None => {
+                // There are no more matches at all.
+                state.at = text.len() + 1;
+                return
+            }
+            Some((m, next)) => {
+                found.push(m);
+                *state = next;
+            }
+        }
+    }
+}
+
+// Finds the next match like `FindMatches::next` does, and returns it along
+// with the state that the search continues from.
+fn step(re: &Regex, text: &str, state: &Resume)
+       -> Option<((uint, uint), Resume)> {
+    let (mut at, mut last) = (state.at, state.last);
+    while at <= text.len() {
+        let m = match re.find_at(text, at) {
+            None => return None,
+            Some(m) => m,
+        };
+        if accept_match(text, m, &mut at, &mut last) {
+            return Some((m, Resume { at: at, last: last }))
+        }
+    }
+    None
+}
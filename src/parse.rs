@@ -13,16 +13,36 @@ use std::cmp;
 use std::fmt;
 use std::iter;
 use std::num;
+use std::ascii::StrAsciiExt;
 use std::str;
+use std::uint;
+
+use literals;
+use segment::{Segment, Grapheme, Word};
 
 /// Static data containing Unicode ranges for general categories and scripts.
-use self::unicode::{UNICODE_CLASSES, PERLD, PERLS, PERLW};
+use self::unicode::{UNICODE_CLASSES, PERLD, PERLS, PERLW, FULL_FOLDS};
+use self::unicode::{GC_NAMES, SCRIPT_NAMES, BINARY_NAMES, BINARY_PROPERTIES};
+use self::unicode::SCRIPT_EXTRAS;
 #[allow(visible_private_types)]
 pub mod unicode;
+/// The names of characters, for `\N{name}`. Leaving them out (with
+/// `--cfg no_unicode_names`) makes the library much smaller, and only allows
+/// characters to be given by their code points.
+#[cfg(not(no_unicode_names))]
+mod unicode_names;
 
 /// The maximum number of repetitions allowed with the `{n,m}` syntax.
 static MAX_REPEAT: uint = 1000;
 
+/// The maximum number of ways that full case folding tries to match a
+/// sequence of literals whose foldings overlap (e.g., `sss`, which `ßs` and
+/// `sß` both match).
+static MAX_FOLD_TILINGS: uint = 32;
+
+/// The largest Unicode scalar value.
+static MAX_CHAR: char = '\U0010FFFF';
+
 /// Error corresponds to something that can go wrong while parsing
 /// a regular expression.
 ///
@@ -33,12 +53,140 @@ pub struct Error {
     pub pos: uint,
     /// A message describing the error.
     pub msg: ~str,
+    /// What went wrong. This is what callers should match on to tell errors
+    /// apart, since the message may change.
+    pub kind: ErrorKind,
+    /// The byte index where the part of the expression at fault starts
+    /// (e.g., the opening parenthesis that isn't closed, or the backslash
+    /// of an invalid escape sequence).
+    pub start: uint,
+    /// The byte index where the part of the expression at fault ends. It's
+    /// never before `start`, and only equal to it at the end of the
+    /// expression (e.g., when it ends before a group is closed).
+    pub end: uint,
+    /// The expression that the error is about. It's empty for errors that
+    /// aren't about a single expression (e.g., those of a `RegexSet`).
+    pub expr: ~str,
+}
+
+impl Error {
+    /// Returns the part of the expression at fault.
+    pub fn snippet<'a>(&'a self) -> &'a str {
+        self.expr.slice(self.start, self.end)
+    }
+
+    /// Returns the expression on one line, followed by a line with carets
+    /// under the part of it at fault, and then the message. Where the
+    /// expression has several lines (e.g., in free-spacing mode), the
+    /// carets are under the line at fault.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let err = Regex::new(r"(foo|ba\q)").unwrap_err();
+    /// assert_eq!(err.snippet(), r"\q");
+    /// assert_eq!(err.format(), ~"(foo|ba\\q)\n       ^^\n\
+    ///                            Invalid escape sequence '\\q'");
+    /// ```
+    pub fn format(&self) -> ~str {
+        let mut out = StrBuf::new();
+        let (mut at, mut marked) = (0, false);
+        for line in self.expr.split('\n') {
+            let end = at + line.len();
+            out.push_str(line);
+            out.push_char('\n');
+            if !marked && self.start >= at && self.start <= end {
+                // Tabs are kept, so that the carets line up after them.
+                for c in line.slice_to(self.start - at).chars() {
+                    out.push_char(if c == '\t' { '\t' } else { ' ' });
+                }
+                let upto = cmp::min(self.end, end);
+                let width = line.slice(self.start - at, upto).char_len();
+                for _ in range(0, cmp::max(width, 1)) {
+                    out.push_char('^');
+                }
+                out.push_char('\n');
+                marked = true;
+            }
+            at = end + 1;
+        }
+        out.push_str(self.msg.as_slice());
+        out.into_owned()
+    }
+
+    // Replaces the character indices that the parser gives the part at
+    // fault with byte indices into the expression, which is kept.
+    fn locate(self, expr: &str) -> Error {
+        let byte = |i: uint| {
+            expr.char_indices().nth(i).map_or(expr.len(), |(b, _)| b)
+        };
+        let start = byte(self.start);
+        let end = cmp::max(byte(self.end), start);
+        Error {
+            start: start,
+            end: end,
+            expr: expr.to_owned(),
+            ..self
+        }
+    }
+}
+
+/// ErrorKind tells an invalid expression apart from one that exceeds the
+/// limits it was compiled with (see `CompileLimits`).
+#[deriving(Clone, Eq, Show)]
+pub enum ErrorKind {
+    /// The expression is invalid.
+    SyntaxError,
+    /// The compiled program would have more instructions than allowed.
+    ProgramTooLarge,
+    /// Groups are nested deeper than allowed.
+    NestingTooDeep,
+    /// A backreference is used, but the limits forbid them.
+    BackrefsForbidden,
 }
 
 impl fmt::Show for Error {
     fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-        write!(f.buf, "Regex syntax error near position {}: {}",
-               self.pos, self.msg)
+        match self.kind {
+            SyntaxError => {
+                write!(f.buf, "Regex syntax error near position {}: {}",
+                       self.pos, self.msg)
+            }
+            ProgramTooLarge | NestingTooDeep | BackrefsForbidden => {
+                write!(f.buf, "Regex exceeds a limit near position {}: {}",
+                       self.pos, self.msg)
+            }
+        }
+    }
+}
+
+/// CompileLimits bounds the size of the regexes compiled with
+/// `Regex::with_limits`. Expressions that can't be trusted should be
+/// compiled with limits much lower than the defaults, which only bound what
+/// fits in memory.
+#[deriving(Clone, Show)]
+pub struct CompileLimits {
+    /// The largest number of instructions the compiled program may have.
+    /// Counted repetition (e.g., `a{1000}`) copies the instructions of the
+    /// expression repeated.
+    pub max_insts: uint,
+    /// The deepest that groups may be nested. (`(a)` is nested once.)
+    pub max_depth: uint,
+    /// Whether backreferences (e.g., `\1`) may be used. Searches for an
+    /// expression with backreferences can take time exponential in the
+    /// length of the text.
+    pub backrefs: bool,
+}
+
+impl CompileLimits {
+    /// Returns the default limits, which accept every valid expression.
+    pub fn new() -> CompileLimits {
+        CompileLimits {
+            max_insts: uint::MAX,
+            max_depth: uint::MAX,
+            backrefs: true,
+        }
     }
 }
 
@@ -50,37 +198,86 @@ impl fmt::Show for Error {
 ///
 /// Note that this representation prevents one from reproducing the regex as
 /// it was typed. (But it could be used to reproduce an equivalent regex.)
-#[deriving(Show, Clone)]
+///
+/// Every node carries the flags in effect where it appears that matter to
+/// it. Counted repetition is already expanded, and Unicode classes are
+/// already resolved to the ranges of characters that they match.
+#[deriving(Show, Clone, Eq)]
 pub enum Ast {
+    /// Matches the empty string.
     Nothing,
+    /// Matches a character. The flags indicate whether to match case
+    /// insensitively (and whether to only fold the ASCII letters).
     Literal(char, Flags),
+    /// Matches any character except a new line. The flags indicate whether
+    /// to match a new line too.
     Dot(Flags),
+    /// Matches a character in one of the (sorted) ranges given. The flags
+    /// indicate whether to match case insensitively and whether the class
+    /// is negated.
     Class(Vec<(char, char)>, Flags),
+    /// Matches the beginning of the text. The flags indicate whether it
+    /// also matches after a new line, or where the search started (`\G`).
     Begin(Flags),
+    /// Matches the end of the text. The flags indicate whether it also
+    /// matches before a new line.
     End(Flags),
+    /// Matches a word boundary. The flags indicate whether it's negated
+    /// (`\B`), and whether word characters are ASCII only.
     WordBoundary(Flags),
+    /// Matches a boundary between grapheme clusters or words. The flags
+    /// indicate whether it's negated.
+    SegmentBoundary(Segment, Flags),
+    /// Matches when the expression matches at the current position (or, for
+    /// lookbehind, ends at it), or doesn't when negated, and consumes no
+    /// characters. The flags indicate whether it's negated and whether
+    /// it's a lookbehind.
+    Lookaround(~Ast, Flags),
+    /// Matches the text that the capture group given matched last. The flags
+    /// indicate whether to compare case insensitively (and only ASCII
+    /// letters, without Unicode).
+    Backref(uint, Flags),
+    /// Matches the empty string, and moves the start of the match reported
+    /// to the current position (`\K`).
+    Keep,
+    /// Matches like the expression does, except that once it has matched,
+    /// the backtracker never tries another way for it to match (see
+    /// `compile.rs`). Possessive repetition (e.g., `a*+`) is an atomic group
+    /// around a greedy repetition.
+    Atomic(~Ast),
+    /// A capture group, given its index and its name (if any).
     Capture(uint, Option<~str>, ~Ast),
-    // Represent concatenation as a flat vector to avoid blowing the
-    // stack in the compiler.
+    /// Concatenation, represented as a flat vector to avoid blowing the
+    /// stack in the compiler.
     Cat(Vec<~Ast>),
+    /// Alternation, which prefers the first expression.
     Alt(~Ast, ~Ast),
+    /// Repetition of an expression.
     Rep(~Ast, Repeater, Greed),
 }
 
+/// The kinds of repetition.
 #[deriving(Show, Eq, Clone)]
 pub enum Repeater {
+    /// `?`
     ZeroOne,
+    /// `*`
     ZeroMore,
+    /// `+`
     OneMore,
 }
 
-#[deriving(Show, Clone)]
+/// Whether a repetition prefers to match as much as possible or as little.
+#[deriving(Show, Clone, Eq)]
 pub enum Greed {
+    /// As much as possible (e.g., `a*`).
     Greedy,
+    /// As little as possible (e.g., `a*?`).
     Ungreedy,
 }
 
 impl Greed {
+    /// Returns true if and only if the repetition is greedy.
     pub fn is_greedy(&self) -> bool {
         match *self {
             Greedy => true,
@@ -105,27 +302,30 @@ impl Greed {
 enum BuildAst {
     Ast(~Ast),
     Paren(Flags, uint, ~str), // '('
+    LookParen(Flags, Flags, uint), // '(?=', '(?!', '(?<=' or '(?<!'
+    AtomicParen(Flags), // '(?>'
     Bar, // '|'
 }
 
 impl BuildAst {
     fn paren(&self) -> bool {
         match *self {
-            Paren(_, _, _) => true,
+            Paren(_, _, _) | LookParen(_, _, _) | AtomicParen(_) => true,
             _ => false,
         }
     }
 
     fn flags(&self) -> Flags {
         match *self {
-            Paren(flags, _, _) => flags,
+            Paren(flags, _, _) | LookParen(flags, _, _) => flags,
+            AtomicParen(flags) => flags,
             _ => fail!("Cannot get flags from {}", self),
         }
     }
 
     fn capture(&self) -> Option<uint> {
         match *self {
-            Paren(_, 0, _) => None,
+            Paren(_, 0, _) | LookParen(_, _, _) | AtomicParen(_) => None,
             Paren(_, c, _) => Some(c),
             _ => fail!("Cannot get capture group from {}", self),
         }
@@ -133,7 +333,7 @@ impl BuildAst {
 
     fn capture_name(&self) -> Option<~str> {
         match *self {
-            Paren(_, 0, _) => None,
+            Paren(_, 0, _) | LookParen(_, _, _) | AtomicParen(_) => None,
             Paren(_, _, ref name) => {
                 if name.len() == 0 {
                     None
@@ -145,6 +345,22 @@ impl BuildAst {
         }
     }
 
+    // Returns the flags of the lookaround this paren opens and the position
+    // of the paren, if it opens one.
+    fn look(&self) -> Option<(Flags, uint)> {
+        match *self {
+            LookParen(_, look, start) => Some((look, start)),
+            _ => None,
+        }
+    }
+
+    fn atomic(&self) -> bool {
+        match *self {
+            AtomicParen(_) => true,
+            _ => false,
+        }
+    }
+
     fn bar(&self) -> bool {
         match *self {
             Bar => true,
@@ -160,16 +376,43 @@ impl BuildAst {
     }
 }
 
+/// ClassItems is what's parsed between the brackets of a character class. A
+/// class without set operations is kept as the union of its ranges and the
+/// classes in it (e.g., `\pL`), which may be negated, but one with set
+/// operations is resolved to the characters it matches.
+enum ClassItems {
+    Union(Flags, Vec<(char, char)>, Vec<~Ast>),
+    Resolved(Vec<(char, char)>),
+}
+
 /// Flags represents all options that can be twiddled by a user in an
 /// expression.
-pub type Flags = u8;
-
-pub static FLAG_EMPTY:      u8 = 0;
-pub static FLAG_NOCASE:     u8 = 1 << 0; // i
-pub static FLAG_MULTI:      u8 = 1 << 1; // m
-pub static FLAG_DOTNL:      u8 = 1 << 2; // s
-pub static FLAG_SWAP_GREED: u8 = 1 << 3; // U
-pub static FLAG_NEGATED:    u8 = 1 << 4; // char class or not word boundary
+pub type Flags = u16;
+
+/// No flags.
+pub static FLAG_EMPTY:      u16 = 0;
+/// Case insensitive (`i`).
+pub static FLAG_NOCASE:     u16 = 1 << 0; // i
+/// `^` and `$` match at new lines (`m`).
+pub static FLAG_MULTI:      u16 = 1 << 1; // m
+/// `.` matches a new line (`s`).
+pub static FLAG_DOTNL:      u16 = 1 << 2; // s
+/// The meaning of `?` after a repetition is swapped (`U`).
+pub static FLAG_SWAP_GREED: u16 = 1 << 3; // U
+/// A negated class or boundary.
+pub static FLAG_NEGATED:    u16 = 1 << 4; // char class or not word boundary
+/// `\G`, which matches where the search started.
+pub static FLAG_SEARCH:     u16 = 1 << 5; // \G, the start of the search
+/// Unicode is off (`-u`), so classes, `\b` and case folding are ASCII only.
+pub static FLAG_ASCII:      u16 = 1 << 6; // -u, ASCII classes and \b
+/// Word boundaries follow Unicode Standard Annex #29 (`w`).
+pub static FLAG_UWORD:      u16 = 1 << 7; // w, Unicode word boundaries
+pub static FLAG_EXTENDED:   u16 = 1 << 8; // x, free-spacing (parser only)
+/// A lookbehind rather than a lookahead.
+pub static FLAG_BEHIND:     u16 = 1 << 9; // lookbehind, not lookahead
+pub static FLAG_FULLCASE:   u16 = 1 << 10; // f, full folding (parser only)
+/// `\Z`, which also matches before a new line that ends the text.
+pub static FLAG_FINALNL:    u16 = 1 << 11; // \Z, before a final new line
 
 struct Parser<'a> {
     // The input, parsed only as a sequence of UTF8 code points.
@@ -184,43 +427,204 @@ struct Parser<'a> {
     // Incremented each time an opening left paren is seen (assuming it is
     // opening a capture group).
     caps: uint,
-    // A set of all capture group names used only to detect duplicates.
-    names: Vec<~str>,
+    // The capture group names used so far, with the branches they're in
+    // (see `branches`), to detect duplicates, and their indices.
+    names: Vec<(~str, Vec<(uint, uint)>, uint)>,
+    // The number of groups that are open.
+    depth: uint,
+    limits: CompileLimits,
+    // For the whole expression and every group that is open, a number that
+    // identifies it and the number of `|` seen in it so far. Two groups are
+    // in different branches of an alternation if these differ for the
+    // innermost group that encloses both.
+    branches: Vec<(uint, uint)>,
+    // The number of groups opened so far.
+    groups: uint,
+    // Whether the expression matches bytes (see `parse_bytes`).
+    bytes: bool,
+    // Whether a literal has been parsed with full case folding.
+    fullcase: bool,
+    // The index of every opening parenthesis of a group that is open, and
+    // of the one being parsed.
+    opens: Vec<uint>,
+    paren: uint,
 }
 
 pub fn parse(s: &str) -> Result<~Ast, Error> {
-    Parser {
+    parse_with_limits(s, CompileLimits::new())
+}
+
+/// Parses an expression like `parse`, except that an error is returned if
+/// the expression exceeds the limits given.
+pub fn parse_with_limits(s: &str, limits: CompileLimits)
+                        -> Result<~Ast, Error> {
+    parse_with_flags(s, FLAG_EMPTY, limits)
+}
+
+/// Parses an expression like `parse_with_limits`, except that the flags
+/// given are set at the start of the expression, as if it began with a
+/// group like `(?i)`. Flags set inside the expression change them as usual.
+pub fn parse_with_flags(s: &str, flags: Flags, limits: CompileLimits)
+                       -> Result<~Ast, Error> {
+    parse_mode(s, flags, limits, false)
+}
+
+/// Parses an expression that matches bytes rather than characters, where the
+/// text searched is given one character per byte (i.e., byte `b` is
+/// `b as char`). The flags given are set at the start of the expression,
+/// like `parse_with_flags`.
+///
+/// Where Unicode is off (`FLAG_ASCII`), everything matches single bytes
+/// except that a non-ASCII character written in the expression matches its
+/// UTF8 encoding. Such a character can't be used in a character class, where
+/// bytes must be given as escapes like `\xFF`. Escapes above `\xFF` and
+/// Unicode classes are errors.
+///
+/// Where Unicode is on, every literal, class and `.` is compiled to the
+/// UTF8 encodings of the characters it matches, so none of them match bytes
+/// that aren't valid UTF8. A Unicode `\b` is an error.
+pub fn parse_bytes(s: &str, flags: Flags, limits: CompileLimits)
+                  -> Result<~Ast, Error> {
+    parse_mode(s, flags, limits, true)
+}
+
+/// Parses an expression like `parse_with_flags`, except that the alternates
+/// of its top-level alternation (e.g., the three of `foo|foobar|ba+r`) are
+/// returned, in the order they're written. An expression without one is
+/// its only alternate.
+///
+/// The alternates can't be found in the syntax tree of the whole expression,
+/// since other syntax parses to alternations too (like `\R`, or a class
+/// that's matched byte by byte).
+pub fn parse_alternates(s: &str, flags: Flags, limits: CompileLimits)
+                       -> Result<Vec<~Ast>, Error> {
+    let (mut ast, n) = try!(parse_counted(s, flags, limits, false));
+    // The alternation nests to the right, and the last alternate may be an
+    // alternation itself.
+    let mut alts = Vec::with_capacity(n);
+    while alts.len() + 1 < n {
+        match ast {
+            ~Alt(x, y) => {
+                alts.push(x);
+                ast = y;
+            }
+            _ => unreachable!(),
+        }
+    }
+    alts.push(ast);
+    Ok(alts)
+}
+
+/// Joins the alternates given back into the syntax tree of their alternation,
+/// which is the one that `parse_with_flags` returns.
+pub fn join_alternates(mut alts: Vec<~Ast>) -> ~Ast {
+    let mut ast = alts.pop().unwrap();
+    while alts.len() > 0 {
+        ast = ~Alt(alts.pop().unwrap(), ast);
+    }
+    ast
+}
+
+fn parse_mode(s: &str, flags: Flags, limits: CompileLimits, bytes: bool)
+             -> Result<~Ast, Error> {
+    parse_counted(s, flags, limits, bytes).map(|(ast, _)| ast)
+}
+
+// Parses an expression, and returns its syntax tree along with the number of
+// alternates of its top-level alternation (which is one if it has none).
+fn parse_counted(s: &str, flags: Flags, limits: CompileLimits, bytes: bool)
+                -> Result<(~Ast, uint), Error> {
+    let mut parser = Parser {
         chars: s.chars().collect(),
         chari: 0,
         stack: vec!(),
-        flags: FLAG_EMPTY,
+        flags: flags,
         caps: 0,
         names: vec!(),
-    }.parse()
+        depth: 0,
+        limits: limits,
+        branches: vec!((0, 0)),
+        groups: 0,
+        bytes: bytes,
+        fullcase: false,
+        opens: vec!(),
+        paren: 0,
+    };
+    let parsed = parser.parse();
+    let (_, bars) = *parser.branches.get(0);
+    parsed.map(|ast| (ast, bars + 1)).map_err(|err| err.locate(s))
+}
+
+/// Returns the number of instructions that `compile.rs` compiles the
+/// expression given to (not counting the instructions that surround every
+/// program).
+pub fn program_size(ast: &Ast) -> uint {
+    match *ast {
+        Nothing => 0,
+        Literal(_, _) | Dot(_) | Class(_, _) | Begin(_) | End(_)
+        | WordBoundary(_) | SegmentBoundary(_, _) | Backref(_, _) | Keep => 1,
+        Lookaround(ref x, _) | Atomic(ref x) | Capture(_, _, ref x) => {
+            program_size(&**x) + 2
+        }
+        Cat(ref xs) => xs.iter().fold(0, |n, x| n + program_size(&**x)),
+        Alt(ref x, ref y) => program_size(&**x) + program_size(&**y) + 2,
+        Rep(ref x, ZeroOne, _) | Rep(ref x, OneMore, _) => {
+            program_size(&**x) + 1
+        }
+        Rep(ref x, ZeroMore, _) => program_size(&**x) + 2,
+    }
+}
+
+// Returns true if groups opened in the branches `a` and `b` (see
+// `Parser::branches`) can't both take part in a match, because they're in
+// different branches of an alternation.
+fn exclusive(a: &[(uint, uint)], b: &[(uint, uint)]) -> bool {
+    for (&(ga, na), &(gb, nb)) in a.iter().zip(b.iter()) {
+        if ga != gb {
+            return false
+        }
+        if na != nb {
+            return true
+        }
+    }
+    false
 }
 
+// The number of instructions that surround every program: saving the
+// bounds of the match and the final match instruction.
+static PROGRAM_OVERHEAD: uint = 3;
+
 impl<'a> Parser<'a> {
     fn parse(&mut self) -> Result<~Ast, Error> {
         loop {
             let c = self.cur();
             match c {
+                // In free-spacing mode, whitespace and comments are skipped.
+                c if c.is_whitespace() && self.flags & FLAG_EXTENDED > 0 => {}
+                '#' if self.flags & FLAG_EXTENDED > 0 => self.skip_comment(),
                 '?' | '*' | '+' => try!(self.push_repeater(c)),
                 '\\' => {
                     let ast = try!(self.parse_escape());
+                    let ast = self.encode_utf8(ast);
                     self.push(ast)
                 }
                 '{' => try!(self.parse_counted()),
                 '[' => match self.try_parse_ascii() {
                     None => try!(self.parse_class()),
-                    Some(class) => self.push(class),
+                    Some(class) => {
+                        let class = self.encode_utf8(class);
+                        self.push(class)
+                    }
                 },
                 '(' => {
+                    self.paren = self.chari;
                     if self.peek_is(1, '?') {
                         try!(self.expect('?'))
                         try!(self.parse_group_opts())
                     } else {
                         self.caps += 1;
-                        self.stack.push(Paren(self.flags, self.caps, ~""))
+                        try!(self.push_paren(Paren(self.flags, self.caps,
+                                                   ~"")))
                     }
                 }
                 ')' => {
@@ -232,12 +636,16 @@ impl<'a> Parser<'a> {
                     // Before we smush the alternates together and pop off the
                     // left paren, let's grab the old flags and see if we
                     // need a capture.
-                    let (cap, cap_name, oldflags) = {
+                    let (cap, cap_name, oldflags, look, atomic) = {
                         let paren = self.stack.get(altfrom-1);
-                        (paren.capture(), paren.capture_name(), paren.flags())
+                        (paren.capture(), paren.capture_name(), paren.flags(),
+                         paren.look(), paren.atomic())
                     };
                     try!(self.alternate(altfrom));
                     self.flags = oldflags;
+                    self.depth -= 1;
+                    self.opens.pop();
+                    self.branches.pop();
 
                     // If this was a capture, pop what we just pushed in
                     // alternate and make it a capture.
@@ -245,6 +653,34 @@ impl<'a> Parser<'a> {
                         let ast = try!(self.pop_ast());
                         self.push(~Capture(cap.unwrap(), cap_name, ast));
                     }
+                    // Likewise for a lookaround.
+                    if look.is_some() {
+                        let (look, start) = look.unwrap();
+                        let ast = try!(self.pop_ast());
+                        if look & FLAG_BEHIND > 0
+                           && literals::max_len(&*ast).is_none() {
+                            return Err(Error {
+                                pos: start,
+                                msg: format!(
+                                    "Lookbehind '{}' has no maximum width, \
+                                     since it repeats something without an \
+                                     upper bound (with *, + or \\{n,\\}) \
+                                     or uses a backreference. Only bounded \
+                                     lookbehind is supported.",
+                                    self.slice(start, self.chari + 1)),
+                                kind: SyntaxError,
+                                start: start,
+                                end: self.chari + 1,
+                                expr: ~"",
+                            })
+                        }
+                        self.push(~Lookaround(ast, look));
+                    }
+                    // Likewise for an atomic group.
+                    if atomic {
+                        let ast = try!(self.pop_ast());
+                        self.push(~Atomic(ast));
+                    }
                 }
                 '|' => {
                     let catfrom = try!(
@@ -252,6 +688,9 @@ impl<'a> Parser<'a> {
                     try!(self.concat(catfrom));
 
                     self.stack.push(Bar);
+                    let last = self.branches.len() - 1;
+                    let (group, bars) = *self.branches.get(last);
+                    *self.branches.get_mut(last) = (group, bars + 1);
                 }
                 _ => try!(self.push_literal(c)),
             }
@@ -263,14 +702,24 @@ impl<'a> Parser<'a> {
         // Try to improve error handling. At this point, there should be
         // no remaining open parens.
         if self.stack.iter().any(|x| x.paren()) {
-            return self.err("Unclosed parenthesis.")
+            let open = *self.opens.last().unwrap();
+            return self.err_span(open, open + 1, "Unclosed parenthesis.")
         }
         let catfrom = try!(self.pos_last(true, |x| x.bar()));
         try!(self.concat(catfrom));
         try!(self.alternate(0));
 
         assert!(self.stack.len() == 1);
-        self.pop_ast()
+        let ast = try!(self.pop_ast());
+        let ast = if self.fullcase { fold_full(ast) } else { ast };
+        let size = program_size(&*ast) + PROGRAM_OVERHEAD;
+        if size > self.limits.max_insts {
+            // The whole expression is at fault.
+            return self.error(ProgramTooLarge, 0, self.chars.len(), format!(
+                "The compiled program would have {} instructions, but the \
+                 limit is {}.", size, self.limits.max_insts))
+        }
+        Ok(ast)
     }
 
     fn noteof(&mut self, expected: &str) -> Result<(), Error> {
@@ -305,6 +754,21 @@ impl<'a> Parser<'a> {
         self.stack.push(Ast(ast))
     }
 
+    // Pushes an opening paren, which opens a group.
+    fn push_paren(&mut self, paren: BuildAst) -> Result<(), Error> {
+        if self.depth >= self.limits.max_depth {
+            return self.limit_err(NestingTooDeep, format!(
+                "Groups are nested more than {} deep.",
+                self.limits.max_depth))
+        }
+        self.depth += 1;
+        self.opens.push(self.paren);
+        self.groups += 1;
+        self.branches.push((self.groups, 0));
+        self.stack.push(paren);
+        Ok(())
+    }
+
     fn push_repeater(&mut self, c: char) -> Result<(), Error> {
         if self.stack.len() == 0 {
             return self.err(
@@ -315,6 +779,11 @@ impl<'a> Parser<'a> {
             _ => fail!("Not a valid repeater operator."),
         };
 
+        // A '+' following a repeat operator makes it possessive.
+        let possessive = self.peek_is(1, '+');
+        if possessive {
+            try!(self.expect('+'))
+        }
         match self.peek(1) {
             Some('*') | Some('+') =>
                 return self.err(
@@ -323,20 +792,26 @@ impl<'a> Parser<'a> {
         }
         let ast = try!(self.pop_ast());
         match ast {
-            ~Begin(_) | ~End(_) | ~WordBoundary(_) =>
+            ~Begin(_) | ~End(_) | ~WordBoundary(_) | ~SegmentBoundary(_, _)
+            | ~Lookaround(_, _) | ~Keep =>
                 return self.err(
                     "Repeat arguments cannot be empty width assertions."),
             _ => {}
         }
-        let greed = try!(self.get_next_greedy());
-        self.push(~Rep(ast, rep, greed));
+        if possessive {
+            self.push(~Atomic(~Rep(ast, rep, Greedy)));
+        } else {
+            let greed = try!(self.get_next_greedy());
+            self.push(~Rep(ast, rep, greed));
+        }
         Ok(())
     }
 
     fn push_literal(&mut self, c: char) -> Result<(), Error> {
         match c {
             '.' => {
-                self.push(~Dot(self.flags))
+                let dot = self.encode_utf8(~Dot(self.flags));
+                self.push(dot)
             }
             '^' => {
                 self.push(~Begin(self.flags))
@@ -344,16 +819,174 @@ impl<'a> Parser<'a> {
             '$' => {
                 self.push(~End(self.flags))
             }
+            c if self.bytes_only() && c > '\x7F' => {
+                // The character is matched as the bytes that encode it.
+                let mut buf = [0u8, ..4];
+                let n = c.encode_utf8(buf.as_mut_slice());
+                let lits = buf.slice_to(n).iter()
+                              .map(|&b| ~Literal(b as char, FLAG_EMPTY))
+                              .collect();
+                self.push(~Cat(lits))
+            }
             _ => {
-                self.push(~Literal(c, self.flags))
+                let lit = self.fold_ascii(~Literal(c, self.flags));
+                let lit = self.encode_utf8(lit);
+                if self.flags & FLAG_FULLCASE > 0 {
+                    self.fullcase = true;
+                }
+                self.push(lit)
             }
         }
         Ok(())
     }
 
+    // When Unicode is off, case insensitive literals and classes are replaced
+    // here by ones that match both cases of ASCII letters only, so that no
+    // other characters are folded (e.g., when matching bytes, `\xE9` and
+    // `\xC9` differ).
+    fn fold_ascii(&self, ast: ~Ast) -> ~Ast {
+        if self.flags & FLAG_ASCII == 0 {
+            return ast
+        }
+        match ast {
+            ~Literal(c, flags) if flags & FLAG_NOCASE > 0 => {
+                let other = swap_ascii_case(c);
+                if other == c {
+                    ~Literal(c, flags & !FLAG_NOCASE)
+                } else {
+                    ~Class(combine_ranges(vec!((c, c), (other, other))),
+                           FLAG_EMPTY)
+                }
+            }
+            ~Class(ranges, flags) if flags & FLAG_NOCASE > 0 => {
+                let mut folded = ranges.clone();
+                for &(start, end) in ranges.iter() {
+                    for &(lo, hi) in [('a', 'z'), ('A', 'Z')].iter() {
+                        let (s, e) = (cmp::max(start, lo), cmp::min(end, hi));
+                        if s <= e {
+                            folded.push((swap_ascii_case(s),
+                                         swap_ascii_case(e)));
+                        }
+                    }
+                }
+                ~Class(combine_ranges(folded), flags & !FLAG_NOCASE)
+            }
+            ast => ast,
+        }
+    }
+
+    // Returns the flags that select the word characters of `\b` and `\B`.
+    // With Unicode off, they're ASCII only, even if `w` is set.
+    fn word_flags(&self) -> Flags {
+        if self.flags & FLAG_ASCII > 0 {
+            FLAG_ASCII
+        } else {
+            self.flags & FLAG_UWORD
+        }
+    }
+
+    // Returns true if the expression matches bytes and Unicode is off, so
+    // that everything matches single bytes.
+    fn bytes_only(&self) -> bool {
+        self.bytes && self.flags & FLAG_ASCII > 0
+    }
+
+    // When matching bytes with Unicode on, replaces a literal, class or `.`
+    // by an alternation of the sequences of bytes that encode the characters
+    // it matches.
+    fn encode_utf8(&self, ast: ~Ast) -> ~Ast {
+        if !self.bytes || self.flags & FLAG_ASCII > 0 {
+            return ast
+        }
+        let ranges = match ast {
+            ~Literal(c, flags) if flags & FLAG_NOCASE > 0 => {
+                fold_unicode(&[(c, c)])
+            }
+            ~Literal(c, _) => vec!((c, c)),
+            ~Dot(flags) if flags & FLAG_DOTNL > 0 => vec!(('\x00', MAX_CHAR)),
+            ~Dot(_) => vec!(('\x00', '\x09'), ('\x0B', MAX_CHAR)),
+            ~Class(ranges, flags) => {
+                let ranges =
+                    if flags & FLAG_NOCASE > 0 {
+                        fold_unicode(ranges.as_slice())
+                    } else {
+                        combine_ranges(ranges)
+                    };
+                if flags & FLAG_NEGATED > 0 {
+                    negate_ranges(ranges.as_slice())
+                } else {
+                    ranges
+                }
+            }
+            ast => return ast,
+        };
+        let mut alts = utf8_sequences(ranges.as_slice()).move_iter().map(|seq| {
+            let mut bytes: Vec<~Ast> = seq.move_iter().map(|(lo, hi)| {
+                if lo == hi {
+                    ~Literal(lo as char, FLAG_EMPTY)
+                } else {
+                    ~Class(vec!((lo as char, hi as char)), FLAG_EMPTY)
+                }
+            }).collect();
+            if bytes.len() == 1 { bytes.pop().unwrap() } else { ~Cat(bytes) }
+        }).collect::<Vec<~Ast>>();
+        let mut ast = match alts.pop() {
+            // A class may match nothing at all (e.g., `[^\x00-\x{10FFFF}]`).
+            None => return ~Class(vec!(), FLAG_EMPTY),
+            Some(ast) => ast,
+        };
+        while alts.len() > 0 {
+            ast = ~Alt(alts.pop().unwrap(), ast);
+        }
+        ast
+    }
+
     // Parses all forms of character classes.
     // Assumes that '[' is the current character.
     fn parse_class(&mut self) -> Result<(), Error> {
+        let ast = match try!(self.parse_class_items()) {
+            Union(negated, ranges, alts) => {
+                self.class_union(negated, ranges, alts)
+            }
+            Resolved(ranges) => self.encode_utf8(~Class(ranges, FLAG_EMPTY)),
+        };
+        self.push(ast);
+        Ok(())
+    }
+
+    // Parses a class nested inside another one (e.g., `[a-z&&[^aeiou]]`),
+    // and returns the characters it matches.
+    // Assumes that '[' is the current character.
+    fn parse_nested_class(&mut self) -> Result<Vec<(char, char)>, Error> {
+        let start = self.chari;
+        match self.parse_class_items() {
+            Err(_) if self.chari >= self.chars.len() => self.err_span(
+                start, self.chars.len(), format!(
+                "Unclosed nested character class at position {}. To match \
+                 '[' inside a character class, escape it as '\\\\['.", start)),
+            Err(err) => Err(err),
+            Ok(Union(negated, ranges, alts)) => {
+                let ranges = self.class_ranges(ranges, alts);
+                if negated > 0 {
+                    Ok(negate_ranges(ranges.as_slice()))
+                } else {
+                    Ok(ranges)
+                }
+            }
+            Ok(Resolved(ranges)) => Ok(ranges),
+        }
+    }
+
+    // Parses the items of a character class up to and including its closing
+    // ']'. Items are ranges and classes (including nested ones), and their
+    // union may be combined with another by one of the set operations
+    // `&&` (intersection), `--` (difference) and `~~` (symmetric
+    // difference). These bind less tightly than union, and all bind equally
+    // tightly and from left to right, so that `[a-z&&[^x]--b]` is
+    // `[[[a-z]&&[^x]]--[b]]`. A leading `^` negates the result of all of
+    // them.
+    // Assumes that '[' is the current character.
+    fn parse_class_items(&mut self) -> Result<ClassItems, Error> {
         let negated =
             if self.peek_is(1, '^') {
                 try!(self.expect('^'))
@@ -363,6 +996,9 @@ impl<'a> Parser<'a> {
             };
         let mut ranges: Vec<(char, char)> = vec!();
         let mut alts: Vec<~Ast> = vec!();
+        // The result of the set operations so far, and the operation to
+        // apply to it and the items that follow.
+        let mut set: Option<(Vec<(char, char)>, char)> = None;
 
         if self.peek_is(1, ']') {
             try!(self.expect(']'))
@@ -376,25 +1012,60 @@ impl<'a> Parser<'a> {
             try!(self.noteof("a closing ']' or a non-empty character class)"))
             let mut c = self.cur();
             match c {
+                '&' | '-' | '~' if self.peek_is(1, c) => {
+                    let operand = try!(self.class_operand(c, ranges, alts));
+                    set = Some((match set {
+                        None => operand,
+                        Some((lhs, op)) => class_set_op(op, lhs, operand),
+                    }, c));
+                    ranges = vec!();
+                    alts = vec!();
+                    self.chari += 1;
+                    continue
+                }
                 '[' =>
                     match self.try_parse_ascii() {
                         Some(~Class(asciis, flags)) => {
-                            alts.push(~Class(asciis, flags ^ negated));
+                            alts.push(~Class(asciis, flags));
                             continue
                         }
                         Some(ast) =>
                             fail!("Expected Class AST but got '{}'", ast),
-                        // Just drop down and try to add as a regular character.
-                        None => {},
+                        None => {
+                            let nested = try!(self.parse_nested_class());
+                            alts.push(~Class(nested, FLAG_EMPTY));
+                            continue
+                        }
                     },
+                '\\' if self.is_backref(1) => {
+                    self.chari += 1;
+                    return self.err(
+                        "Backreferences like '\\1' are not valid inside a \
+                         character class. Use an octal escape with three \
+                         digits (e.g., '\\001') instead.")
+                }
+                '\\' if self.peek_is(1, 'K') => {
+                    self.chari += 1;
+                    return self.err(
+                        "\\K is not a valid escape sequence inside a \
+                         character class.")
+                }
+                '\\' if self.peek_is(1, 'X') || self.peek_is(1, 'R') => {
+                    self.chari += 1;
+                    return self.err(
+                        "\\X and \\R are not valid escape sequences inside \
+                         a character class, since they can match more than \
+                         one character.")
+                }
                 '\\' => {
                     match try!(self.parse_escape()) {
                         ~Class(asciis, flags) => {
-                            alts.push(~Class(asciis, flags ^ negated));
+                            alts.push(~Class(asciis, flags));
                             continue
                         }
                         ~Literal(c2, _) => c = c2, // process below
-                        ~Begin(_) | ~End(_) | ~WordBoundary(_) =>
+                        ~Begin(_) | ~End(_) | ~WordBoundary(_)
+                        | ~SegmentBoundary(_, _) =>
                             return self.err(
                                 "\\A, \\z, \\b and \\B are not valid escape \
                                  sequences inside a character class."),
@@ -405,32 +1076,57 @@ impl<'a> Parser<'a> {
             }
             match c {
                 ']' => {
-                    if ranges.len() > 0 {
-                        let flags = negated | (self.flags & FLAG_NOCASE);
-                        let mut ast = ~Class(combine_ranges(ranges), flags);
-                        for alt in alts.move_iter() {
-                            ast = ~Alt(alt, ast)
-                        }
-                        self.push(ast);
-                    } else if alts.len() > 0 {
-                        let mut ast = alts.pop().unwrap();
-                        for alt in alts.move_iter() {
-                            ast = ~Alt(alt, ast)
-                        }
-                        self.push(ast);
+                    let (lhs, op) = match set {
+                        None => return Ok(Union(negated, ranges, alts)),
+                        Some(set) => set,
+                    };
+                    let operand = try!(self.class_operand(op, ranges, alts));
+                    let mut result = class_set_op(op, lhs, operand);
+                    if negated > 0 {
+                        result = negate_ranges(result.as_slice());
                     }
-                    return Ok(())
+                    if result.len() == 0 {
+                        return self.err(
+                            "Character class matches no characters after \
+                             its set operations. If '&&', '--' or '~~' was \
+                             meant to match literally, escape it (e.g., \
+                             '\\&\\&').")
+                    }
+                    return Ok(Resolved(result))
                 }
                 c => {
-                    if self.peek_is(1, '-') && !self.peek_is(2, ']') {
+                    if self.bytes_only() && self.cur() > '\x7F' {
+                        return self.err(format!(
+                            "Non-ASCII character '{}' in a class that matches \
+                             bytes. Use escapes like '\\xFF' instead.",
+                             self.cur()))
+                    }
+                    if self.peek_is(1, '-') && !self.peek_is(2, ']')
+                       && !self.peek_is(2, '-') {
                         try!(self.expect('-'))
                         try!(self.noteof("not a ']'"))
-                        let c2 = self.cur();
+                        let c2 = match self.cur() {
+                            // The end of a range may be escaped too (e.g.,
+                            // `[\x80-\xFF]`).
+                            '\\' => match try!(self.parse_escape()) {
+                                ~Literal(c2, _) => c2,
+                                _ => return self.err(
+                                    "Expected a character at the end of a \
+                                     character class range."),
+                            },
+                            c2 if self.bytes_only() && c2 > '\x7F' => {
+                                return self.err(format!(
+                                    "Non-ASCII character '{}' in a class \
+                                     that matches bytes. Use escapes like \
+                                     '\\xFF' instead.", c2))
+                            }
+                            c2 => c2,
+                        };
                         if c2 < c {
                             return self.err(format!(
                                 "Invalid character class range '{}-{}'", c, c2))
                         }
-                        ranges.push((c, self.cur()))
+                        ranges.push((c, c2))
                     } else {
                         ranges.push((c, c))
                     }
@@ -439,6 +1135,84 @@ impl<'a> Parser<'a> {
         }
     }
 
+    // Returns the characters matched by an operand of a set operation, which
+    // is the union of `ranges` and `alts`. It's an error for it to be empty,
+    // which most likely means that the operator was meant literally, as it
+    // was before set operations were supported.
+    fn class_operand(&self, op: char, ranges: Vec<(char, char)>,
+                     alts: Vec<~Ast>) -> Result<Vec<(char, char)>, Error> {
+        if ranges.len() == 0 && alts.len() == 0 {
+            return self.err(format!(
+                "Missing an operand of the character class set operation \
+                 '{}{}'. To match it literally, escape it as '\\\\{}\\\\{}'.",
+                op, op, op, op))
+        }
+        Ok(self.class_ranges(ranges, alts))
+    }
+
+    // Returns the characters matched by the union of `ranges` and `alts`,
+    // with case folding applied.
+    fn class_ranges(&self, ranges: Vec<(char, char)>, alts: Vec<~Ast>)
+                   -> Vec<(char, char)> {
+        let mut classes = alts;
+        if ranges.len() > 0 {
+            let flags = self.flags & FLAG_NOCASE;
+            classes.push(self.fold_ascii(~Class(combine_ranges(ranges),
+                                                flags)));
+        }
+        let mut all = vec!();
+        for class in classes.move_iter() {
+            let (ranges, flags) = match class {
+                ~Class(ranges, flags) => (ranges, flags),
+                ast => fail!("Expected Class AST but got '{}'", ast),
+            };
+            let ranges =
+                if flags & FLAG_NOCASE > 0 {
+                    fold_unicode(ranges.as_slice())
+                } else {
+                    combine_ranges(ranges)
+                };
+            if flags & FLAG_NEGATED > 0 {
+                all.push_all_move(negate_ranges(ranges.as_slice()));
+            } else {
+                all.push_all_move(ranges);
+            }
+        }
+        combine_ranges(all)
+    }
+
+    // Returns the expression for a class without set operations, which is
+    // an alternation of its ranges and the classes in it.
+    fn class_union(&self, negated: Flags, ranges: Vec<(char, char)>,
+                   alts: Vec<~Ast>) -> ~Ast {
+        let items = alts.len() + if ranges.len() > 0 { 1 } else { 0 };
+        if negated > 0 && items > 1 {
+            // The negation of a union isn't the union of the negated items,
+            // so the characters it matches are found instead.
+            let ranges = self.class_ranges(ranges, alts);
+            return self.encode_utf8(
+                ~Class(negate_ranges(ranges.as_slice()), FLAG_EMPTY))
+        }
+        let mut alts: Vec<~Ast> = alts.move_iter().map(|alt| {
+            match alt {
+                ~Class(asciis, flags) => ~Class(asciis, flags ^ negated),
+                ast => ast,
+            }
+        }).collect();
+        let mut ast =
+            if ranges.len() > 0 {
+                let flags = negated | (self.flags & FLAG_NOCASE);
+                let class = ~Class(combine_ranges(ranges), flags);
+                self.encode_utf8(self.fold_ascii(class))
+            } else {
+                self.encode_utf8(alts.pop().unwrap())
+            };
+        for alt in alts.move_iter() {
+            ast = ~Alt(self.encode_utf8(alt), ast)
+        }
+        ast
+    }
+
     // Tries to parse an ASCII character class of the form [:name:].
     // If successful, returns an AST character class corresponding to name
     // and moves the parser to the final ']' character.
@@ -473,7 +1247,7 @@ impl<'a> Parser<'a> {
             Some(ranges) => {
                 self.chari = closer;
                 let flags = negated | (self.flags & FLAG_NOCASE);
-                Some(~Class(combine_ranges(ranges), flags))
+                Some(self.fold_ascii(~Class(combine_ranges(ranges), flags)))
             }
         }
     }
@@ -489,12 +1263,20 @@ impl<'a> Parser<'a> {
         let closer =
             match self.pos('}') {
                 Some(i) => i,
-                None => return self.err(format!(
+                None => return self.err_span(start, self.chars.len(), format!(
                     "No closing brace for counted repetition starting at \
                      position {}.", start)),
             };
         self.chari = closer;
-        let greed = try!(self.get_next_greedy());
+        // A '+' following the closing brace makes it possessive.
+        let possessive = self.peek_is(1, '+');
+        let greed =
+            if possessive {
+                try!(self.expect('+'))
+                Greedy
+            } else {
+                try!(self.get_next_greedy())
+            };
         let inner = str::from_chars(
             self.chars.as_slice().slice(start + 1, closer));
 
@@ -539,31 +1321,55 @@ impl<'a> Parser<'a> {
             }
         }
 
+        // Make sure the copies fit in the program before making any.
+        let size = match self.stack.last() {
+            Some(&Ast(ref ast)) => program_size(&**ast),
+            _ => 0,
+        };
+        let total =
+            match max {
+                None => min * size + size + 2,
+                Some(max) => min * size + (max - min) * (size + 1),
+            };
+        if total + PROGRAM_OVERHEAD > self.limits.max_insts {
+            return self.limit_err(ProgramTooLarge, format!(
+                "The repetition would compile to {} instructions, but the \
+                 limit is {}.", total, self.limits.max_insts))
+        }
+
         // Now manipulate the AST be repeating elements.
+        let ast = try!(self.pop_ast());
+        let mut copies = Vec::with_capacity(min + 1);
         if max.is_none() {
             // Require N copies of what's on the stack and then repeat it.
-            let ast = try!(self.pop_ast());
             for _ in iter::range(0, min) {
-                self.push(ast.clone())
+                copies.push(ast.clone())
             }
-            self.push(~Rep(ast, ZeroMore, greed));
+            copies.push(~Rep(ast, ZeroMore, greed));
         } else {
             // Require N copies of what's on the stack and then repeat it
             // up to M times optionally.
-            let ast = try!(self.pop_ast());
             for _ in iter::range(0, min) {
-                self.push(ast.clone())
+                copies.push(ast.clone())
             }
             if max.is_some() {
                 for _ in iter::range(min, max.unwrap()) {
-                    self.push(~Rep(ast.clone(), ZeroOne, greed))
+                    copies.push(~Rep(ast.clone(), ZeroOne, greed))
                 }
             }
             // It's possible that we popped something off the stack but
             // never put anything back on it. To keep things simple, add
             // a no-op expression.
             if min == 0 && (max.is_none() || max == Some(0)) {
-                self.push(~Nothing)
+                copies.push(~Nothing)
+            }
+        }
+        if possessive {
+            // The copies are matched atomically, as a whole.
+            self.push(~Atomic(~Cat(copies)));
+        } else {
+            for copy in copies.move_iter() {
+                self.push(copy)
             }
         }
         Ok(())
@@ -586,19 +1392,55 @@ impl<'a> Parser<'a> {
             'r' => Ok(~Literal('\r', FLAG_EMPTY)),
             'v' => Ok(~Literal('\x0B', FLAG_EMPTY)),
             'A' => Ok(~Begin(FLAG_EMPTY)),
+            'G' => Ok(~Begin(FLAG_SEARCH)),
             'z' => Ok(~End(FLAG_EMPTY)),
-            'b' => Ok(~WordBoundary(FLAG_EMPTY)),
-            'B' => Ok(~WordBoundary(FLAG_NEGATED)),
+            'Z' => Ok(~End(FLAG_FINALNL)),
+            'K' => self.keep(),
+            // Whitespace and '#' can be escaped to match them in free-spacing
+            // mode.
+            c if c.is_whitespace() => Ok(~Literal(c, FLAG_EMPTY)),
+            '#' => Ok(~Literal(c, FLAG_EMPTY)),
+            // The class set operators can be escaped to match them
+            // literally.
+            '-' | '&' | '~' => Ok(~Literal(c, FLAG_EMPTY)),
+            'X' => Ok(try!(self.parse_grapheme())),
+            'R' => Ok(self.parse_line_break()),
+            'b' | 'B' if self.peek_is(1, '{') => {
+                Ok(try!(self.parse_segment_boundary()))
+            }
+            'b' | 'B' if self.bytes && self.flags & FLAG_ASCII == 0 => {
+                self.err("Unicode word boundaries can't be used in an \
+                          expression that matches bytes. Use '(?-u:\\b)' \
+                          instead.")
+            }
+            'b' => Ok(~WordBoundary(self.word_flags())),
+            'B' => Ok(~WordBoundary(FLAG_NEGATED | self.word_flags())),
+            // A single digit (other than 0) is a backreference, and more
+            // digits are an octal escape.
+            _ if self.is_backref(0) => {
+                if c == 'k' {
+                    self.parse_named_backref()
+                } else {
+                    self.backref(c.to_digit(10).unwrap())
+                }
+            }
             '0'|'1'|'2'|'3'|'4'|'5'|'6'|'7' => Ok(try!(self.parse_octal())),
             'x' => Ok(try!(self.parse_hex())),
+            'N' => Ok(try!(self.parse_char_name())),
             'p' | 'P' => Ok(try!(self.parse_unicode_name())),
             'd' | 'D' | 's' | 'S' | 'w' | 'W' => {
-                let ranges = perl_unicode_class(c);
+                let ranges =
+                    if self.flags & FLAG_ASCII > 0 {
+                        perl_ascii_class(c)
+                    } else {
+                        perl_unicode_class(c)
+                    };
                 let mut flags = self.flags & FLAG_NOCASE;
                 if c.is_uppercase() { flags |= FLAG_NEGATED }
-                Ok(~Class(ranges, flags))
+                Ok(self.fold_ascii(~Class(ranges, flags)))
             }
-            _ => self.err(format!("Invalid escape sequence '\\\\{}'", c)),
+            _ => self.err_span(self.chari - 1, self.chari + 1,
+                               format!("Invalid escape sequence '\\\\{}'", c)),
         }
     }
 
@@ -608,6 +1450,11 @@ impl<'a> Parser<'a> {
     // Assumes that \p or \P has been read (and 'p' or 'P' is the current
     // character).
     fn parse_unicode_name(&mut self) -> Result<~Ast, Error> {
+        if self.bytes_only() {
+            return self.err(
+                "Unicode classes can't be used where Unicode is off in an \
+                 expression that matches bytes.")
+        }
         let negated = if self.cur() == 'P' { FLAG_NEGATED } else { FLAG_EMPTY };
         let mut name: ~str;
         if self.peek_is(1, '{') {
@@ -631,15 +1478,166 @@ impl<'a> Parser<'a> {
             name = self.slice(self.chari + 1, self.chari + 2);
             self.chari += 1;
         }
-        match find_class(UNICODE_CLASSES, name) {
-            None => return self.err(format!(
-                "Could not find Unicode class '{}'", name)),
-            Some(ranges) => {
+        match unicode_class(name) {
+            Err(near) => {
+                let near: Vec<~str> =
+                    near.iter().map(|n| format!("'{}'", n)).collect();
+                let hint = if near.len() == 0 {
+                    ~""
+                } else {
+                    let near = near.as_slice().connect(", ");
+                    format!(". Did you mean {}?", near)
+                };
+                return self.err(format!(
+                    "Could not find Unicode class '{}'{}", name, hint))
+            }
+            Ok(ranges) => {
                 Ok(~Class(ranges, negated | (self.flags & FLAG_NOCASE)))
             }
         }
     }
 
+    // Parses a boundary between text segments, which is either \b{g} (for
+    // grapheme clusters) or \b{wb} (for words), or its negation with \B.
+    // Assumes that \b or \B has been read (and 'b' or 'B' is the current
+    // character) and that '{' follows it.
+    fn parse_segment_boundary(&mut self) -> Result<~Ast, Error> {
+        if self.bytes {
+            return self.err(
+                "Text segment boundaries can't be used in an expression \
+                 that matches bytes.")
+        }
+        let negated = if self.cur() == 'B' { FLAG_NEGATED } else { FLAG_EMPTY };
+        try!(self.expect('{'))
+        let closer =
+            match self.pos('}') {
+                Some(i) => i,
+                None => return self.err(format!(
+                    "Missing '\\}' for unclosed '\\{' at position {}",
+                    self.chari)),
+            };
+        let name = self.slice(self.chari + 1, closer);
+        self.chari = closer;
+        let seg = match name.as_slice() {
+            "g" => Grapheme,
+            "wb" => Word,
+            _ => return self.err(format!(
+                "Unknown text segment boundary '{}'. Expected 'g' (for \
+                 grapheme clusters) or 'wb' (for words).", name)),
+        };
+        Ok(~SegmentBoundary(seg, negated))
+    }
+
+    // Parses \X, which matches a single extended grapheme cluster. It's
+    // rewritten as `(?s:.)(?:\B{g}(?s:.))*\b{g}`, i.e., any character
+    // followed by the characters that continue its cluster, so that it never
+    // matches less or more than one cluster.
+    // Assumes that \X has been read (and 'X' is the current character).
+    fn parse_grapheme(&self) -> Result<~Ast, Error> {
+        if self.bytes {
+            return self.err(
+                "\\X can't be used in an expression that matches bytes.")
+        }
+        let more = ~Cat(vec!(~SegmentBoundary(Grapheme, FLAG_NEGATED),
+                             ~Dot(FLAG_DOTNL)));
+        Ok(~Cat(vec!(~Dot(FLAG_DOTNL),
+                     ~Rep(more, ZeroMore, Greedy),
+                     ~SegmentBoundary(Grapheme, FLAG_EMPTY))))
+    }
+
+    // Parses \R, which matches any line break: `\r\n`, or one of `\n`, `\v`,
+    // `\f`, `\r`, `\x85`, `\u2028` and `\u2029` (the last three only where
+    // Unicode is on). It's rewritten as `(?>\r\n|[\n\v\f\r...])`, with
+    // `\r\n` first so that it's preferred, and atomic so that a backtracking
+    // search doesn't go back to match its `\r` on its own.
+    // Assumes that \R has been read (and 'R' is the current character).
+    fn parse_line_break(&self) -> ~Ast {
+        let mut ranges = vec!(('\n', '\r'));
+        if self.flags & FLAG_ASCII == 0 {
+            ranges.push(('\x85', '\x85'));
+            ranges.push(('\u2028', '\u2029'));
+        }
+        let crlf = ~Cat(vec!(~Literal('\r', FLAG_EMPTY),
+                             ~Literal('\n', FLAG_EMPTY)));
+        let others = self.encode_utf8(~Class(ranges, FLAG_EMPTY));
+        ~Atomic(~Alt(crlf, others))
+    }
+
+    // Returns `\K`, which can't be used inside lookaround, since the start
+    // there isn't the start of the match.
+    fn keep(&self) -> Result<~Ast, Error> {
+        if self.stack.iter().any(|x| x.look().is_some()) {
+            return self.err("\\K is not supported inside lookaround.")
+        }
+        Ok(~Keep)
+    }
+
+    // Returns true if the escape whose letter is at `offset` from the current
+    // character is a backreference: a single digit other than 0 (more digits
+    // are an octal escape) or '\k<'.
+    fn is_backref(&self, offset: uint) -> bool {
+        match self.peek(offset) {
+            Some('k') => self.peek_is(offset + 1, '<'),
+            Some(c) if c >= '1' && c <= '9' => {
+                !self.peek(offset + 1).map_or(false, |d| d.is_digit())
+            }
+            _ => false,
+        }
+    }
+
+    // Returns a backreference to the capture group given, which must have
+    // been closed before it.
+    fn backref(&self, group: uint) -> Result<~Ast, Error> {
+        if !self.limits.backrefs {
+            return self.limit_err(BackrefsForbidden,
+                                  "Backreferences are not allowed.")
+        }
+        if group > self.caps {
+            return self.err(format!(
+                "Backreference to group {}, which isn't defined before it.",
+                group))
+        }
+        let open = self.stack.iter().any(|x| {
+            match *x { Paren(_, cap, _) => cap == group, _ => false }
+        });
+        if open {
+            return self.err(format!(
+                "Backreference to group {} inside of it.", group))
+        }
+        Ok(~Backref(group, self.flags & (FLAG_NOCASE | FLAG_ASCII)))
+    }
+
+    // Parses a backreference to a named group, `\k<name>`. When groups in
+    // different branches share the name, it matches the text of whichever
+    // of them matched.
+    // Assumes that '\k' has been read and that the current character is
+    // 'k'. When done, parser will be at the closing '>' character.
+    fn parse_named_backref(&mut self) -> Result<~Ast, Error> {
+        try!(self.expect('<'))
+        let closer =
+            match self.pos('>') {
+                Some(i) => i,
+                None => return self.err("Capture name must end with '>'."),
+            };
+        let name = self.slice(self.chari + 1, closer);
+        let groups: Vec<uint> = self.names.iter()
+            .filter(|&&(ref other, _, _)| *other == name)
+            .map(|&(_, _, cap)| cap)
+            .collect();
+        if groups.len() == 0 {
+            return self.err(format!(
+                "Backreference to group '{}', which isn't defined before it.",
+                name))
+        }
+        let mut refs = vec!();
+        for &group in groups.iter() {
+            refs.push(try!(self.backref(group)));
+        }
+        self.chari = closer;
+        let last = refs.pop().unwrap();
+        Ok(refs.move_iter().rev().fold(last, |alt, r| ~Alt(r, alt)))
+    }
+
     // Parses an octal number, up to 3 digits.
     // Assumes that \n has been read, where n is the first digit.
     fn parse_octal(&mut self) -> Result<~Ast, Error> {
@@ -700,6 +1698,46 @@ impl<'a> Parser<'a> {
         }
     }
 
+    // Parses a character given by its name, of the form \N{name}, or by its
+    // code point, of the form \N{U+hex}.
+    // Assumes that \N has been read (and 'N' is the current character).
+    // After return, parser will point at the closing '}'.
+    fn parse_char_name(&mut self) -> Result<~Ast, Error> {
+        let escape = self.chari - 1;
+        if !self.peek_is(1, '{') {
+            return self.err_span(escape, self.chari + 1,
+                "'\\N' must be followed by a character name in braces \
+                 (e.g., '\\N{U+00E4}').")
+        }
+        let start = self.chari + 2;
+        let closer =
+            match self.pos('}') {
+                None => return self.err(format!(
+                    "Missing '\\}' for unclosed '\\{' at position {}", start)),
+                Some(i) => i,
+            };
+        self.chari = closer;
+        let name = self.slice(start, closer);
+        if name.starts_with("U+") {
+            let hex = name.slice_from(2);
+            if hex.len() == 0 || hex.len() > 6
+               || !hex.chars().all(|c| c.is_digit_radix(16)) {
+                return self.err_span(escape, closer + 1, format!(
+                    "Invalid code point '{}'. It must be 'U+' followed by \
+                     1 to 6 hex digits.", name))
+            }
+            return self.parse_hex_digits(hex)
+        }
+        match char_from_name(name) {
+            Some(c) => {
+                Ok(~Literal(try!(self.char_from_u32(c as u32)), FLAG_EMPTY))
+            }
+            None => self.err_span(start, closer, format!(
+                "Unknown character name '{}'. A character can also be given \
+                 by its code point (e.g., '\\N\{U+00E4\}').", name)),
+        }
+    }
+
     // Parses a named capture.
     // Assumes that '(?P<' has been consumed and that the current character
     // is '<'.
@@ -709,24 +1747,33 @@ impl<'a> Parser<'a> {
         let closer =
             match self.pos('>') {
                 Some(i) => i,
-                None => return self.err("Capture name must end with '>'."),
+                None => return self.err_span(
+                    self.paren, self.chars.len(),
+                    "Capture name must end with '>'."),
             };
         if closer - self.chari == 0 {
-            return self.err("Capture names must have at least 1 character.")
+            return self.err_span(self.paren, closer + 1,
+                "Capture names must have at least 1 character.")
         }
         let name = self.slice(self.chari, closer);
         if !name.chars().all(is_valid_cap) {
-            return self.err(
+            return self.err_span(self.chari, closer,
                 "Capture names can only have underscores, letters and digits.")
         }
-        if self.names.contains(&name) {
-            return self.err(format!("Duplicate capture group name '{}'.", name))
+        // A name may be used again in another branch of an alternation,
+        // since only one of the groups can take part in a match.
+        let dupe = self.names.iter().any(|&(ref other, ref branches, _)| {
+            *other == name && !exclusive(branches.as_slice(),
+                                         self.branches.as_slice())
+        });
+        if dupe {
+            return self.err_span(self.chari, closer, format!(
+                "Duplicate capture group name '{}'.", name))
         }
-        self.names.push(name.clone());
-        self.chari = closer;
         self.caps += 1;
-        self.stack.push(Paren(self.flags, self.caps, name));
-        Ok(())
+        self.names.push((name.clone(), self.branches.clone(), self.caps));
+        self.chari = closer;
+        self.push_paren(Paren(self.flags, self.caps, name))
     }
 
     // Parses non-capture groups and options.
@@ -737,39 +1784,80 @@ impl<'a> Parser<'a> {
             try!(self.expect('P')) try!(self.expect('<'))
             return self.parse_named_capture()
         }
+        let behind = self.peek_is(1, '<');
+        let at = if behind { 2 } else { 1 };
+        if self.peek_is(at, '=') || self.peek_is(at, '!') {
+            let mut look = if behind { FLAG_BEHIND } else { FLAG_EMPTY };
+            if self.peek_is(at, '!') {
+                look = look | FLAG_NEGATED;
+            }
+            let start = self.chari - 1;
+            self.chari += at;
+            return self.push_paren(LookParen(self.flags, look, start))
+        }
+        if self.peek_is(1, '>') {
+            self.chari += 1;
+            return self.push_paren(AtomicParen(self.flags))
+        }
         let start = self.chari;
         let mut flags = self.flags;
         let mut sign = 1;
         let mut saw_flag = false;
         loop {
             try!(self.noteof("expected non-empty set of flags or closing ')'"))
+            let flag = match self.cur() {
+                'i' => FLAG_NOCASE,
+                'm' => FLAG_MULTI,
+                's' => FLAG_DOTNL,
+                'U' => FLAG_SWAP_GREED,
+                'w' => FLAG_UWORD,
+                'x' => FLAG_EXTENDED,
+                'f' => FLAG_FULLCASE,
+                _ => FLAG_EMPTY,
+            };
             match self.cur() {
-                'i' => { flags = flags | FLAG_NOCASE;     saw_flag = true},
-                'm' => { flags = flags | FLAG_MULTI;      saw_flag = true},
-                's' => { flags = flags | FLAG_DOTNL;      saw_flag = true},
-                'U' => { flags = flags | FLAG_SWAP_GREED; saw_flag = true},
+                'u' => {
+                    // Unicode is on by default, so `(?-u)` sets the flag.
+                    if sign < 0 {
+                        flags = flags | FLAG_ASCII;
+                    } else {
+                        flags = flags & !FLAG_ASCII;
+                    }
+                    saw_flag = true;
+                }
+                'f' if self.bytes => {
+                    return self.err(
+                        "Full case folding (the 'f' flag) is not supported \
+                         in an expression that matches bytes.")
+                }
+                'i' | 'm' | 's' | 'U' | 'w' | 'x' | 'f' => {
+                    // Only the flags named are changed, so that the ones
+                    // set before (e.g., by `parse_with_flags`) are kept.
+                    if sign < 0 {
+                        flags = flags & !flag;
+                    } else {
+                        flags = flags | flag;
+                    }
+                    saw_flag = true;
+                }
                 '-' => {
                     if sign < 0 {
-                        return self.err(format!(
+                        return self.err_span(start - 1, self.chari + 1, format!(
                             "Cannot negate flags twice in '{}'.",
                             self.slice(start, self.chari + 1)))
                     }
                     sign = -1;
                     saw_flag = false;
-                    flags = flags ^ flags;
                 }
                 ':' | ')' => {
-                    if sign < 0 {
-                        if !saw_flag {
-                            return self.err(format!(
-                                "A valid flag does not follow negation in '{}'",
-                                self.slice(start, self.chari + 1)))
-                        }
-                        flags = flags ^ flags;
+                    if sign < 0 && !saw_flag {
+                        return self.err_span(start - 1, self.chari + 1, format!(
+                            "A valid flag does not follow negation in '{}'",
+                            self.slice(start, self.chari + 1)))
                     }
                     if self.cur() == ':' {
                         // Save the old flags with the opening paren.
-                        self.stack.push(Paren(self.flags, 0, ~""));
+                        try!(self.push_paren(Paren(self.flags, 0, ~"")));
                     }
                     self.flags = flags;
                     return Ok(())
@@ -780,6 +1868,15 @@ impl<'a> Parser<'a> {
         }
     }
 
+    // Skips a comment in free-spacing mode, up to the new line that ends it
+    // (which is skipped as whitespace) or the end of the expression.
+    // Assumes that '#' is the current character.
+    fn skip_comment(&mut self) {
+        while self.chari + 1 < self.chars.len() && !self.peek_is(1, '\n') {
+            self.chari += 1;
+        }
+    }
+
     // Peeks at the next character and returns whether it's ungreedy or not.
     // If it is, then the next character is consumed.
     fn get_next_greedy(&mut self) -> Result<Greed, Error> {
@@ -875,6 +1972,10 @@ impl<'a> Parser<'a> {
     }
 
     fn char_from_u32(&self, n: u32) -> Result<char, Error> {
+        if self.bytes_only() && n > 0xFF {
+            return self.err(format!(
+                "Code {:X} (hex) is larger than a byte.", n))
+        }
         match char::from_u32(n) {
             Some(c) => Ok(c),
             None => self.err(format!(
@@ -887,10 +1988,33 @@ impl<'a> Parser<'a> {
             .skip(self.chari).position(|&c2| c2 == c).map(|i| self.chari + i)
     }
 
+    // Returns a syntax error at the current character.
     fn err<T>(&self, msg: &str) -> Result<T, Error> {
+        self.error(SyntaxError, self.chari, self.chari + 1, msg)
+    }
+
+    // Returns a syntax error about the characters from `start` up to `end`.
+    fn err_span<T>(&self, start: uint, end: uint, msg: &str)
+                  -> Result<T, Error> {
+        self.error(SyntaxError, start, end, msg)
+    }
+
+    fn limit_err<T>(&self, kind: ErrorKind, msg: &str) -> Result<T, Error> {
+        self.error(kind, self.chari, self.chari + 1, msg)
+    }
+
+    // The indices of the part at fault are character indices until the
+    // error is returned by `parse_mode`, which replaces them (see
+    // `Error::locate`).
+    fn error<T>(&self, kind: ErrorKind, start: uint, end: uint, msg: &str)
+               -> Result<T, Error> {
         Err(Error {
             pos: self.chari,
             msg: msg.to_owned(),
+            kind: kind,
+            start: start,
+            end: end,
+            expr: ~"",
         })
     }
 
@@ -959,6 +2083,361 @@ fn perl_unicode_class(which: char) -> Vec<(char, char)> {
     }
 }
 
+// Constructs an ASCII Perl character class from \d, \s or \w (or any of
+// their negated forms), as used when Unicode is off. This does not handle
+// negation either.
+fn perl_ascii_class(which: char) -> Vec<(char, char)> {
+    let name = match which.to_lowercase() {
+        'd' => "digit",
+        's' => "space",
+        'w' => "word",
+        _ => unreachable!(),
+    };
+    find_class(ASCII_CLASSES, name).unwrap()
+}
+
+/// Adds the uppercase and lowercase forms of every character in `ranges`,
+/// which is how case insensitive classes are matched (see `vm::char_eq`).
+pub fn fold_unicode(ranges: &[(char, char)]) -> Vec<(char, char)> {
+    let mut folded = Vec::from_slice(ranges);
+    for &(start, end) in ranges.iter() {
+        for n in iter::range_inclusive(start as u32, end as u32) {
+            match char::from_u32(n) {
+                None => {}
+                Some(c) => {
+                    let (upper, lower) = (c.to_uppercase(), c.to_lowercase());
+                    if upper != c { folded.push((upper, upper)) }
+                    if lower != c { folded.push((lower, lower)) }
+                }
+            }
+        }
+    }
+    combine_ranges(folded)
+}
+
+// Full case folding (the `f` flag) is applied to the expression once it's
+// parsed. Every sequence of case insensitive literals in a concatenation is
+// folded first: a literal whose full folding is several characters (e.g.,
+// `ß`, which folds to `ss`) is replaced by those characters. Then wherever
+// the folding of some character occurs in the sequence (`ss` again), an
+// alternation is added that matches that character too. Foldings can
+// overlap (e.g., in `sss`), and the sequence is then matched in every way
+// that they can tile it, unless there are more than `MAX_FOLD_TILINGS` of
+// them, in which case the foldings are only tried from left to right
+// without overlapping.
+//
+// Only literals are folded this way. Character classes and backreferences
+// are still matched with simple folding.
+
+// Returns the expression with full case folding applied to its literals,
+// from which the flag is cleared.
+fn fold_full(ast: ~Ast) -> ~Ast {
+    match ast {
+        ~Literal(c, flags) if folds_full(flags) => {
+            let mut asts = fold_run(&[(c, flags)]);
+            if asts.len() == 1 { asts.pop().unwrap() } else { ~Cat(asts) }
+        }
+        ~Literal(c, flags) => ~Literal(c, flags & !FLAG_FULLCASE),
+        ~Cat(xs) => {
+            let (mut cat, mut run) = (vec!(), vec!());
+            for x in xs.move_iter() {
+                match x {
+                    ~Literal(c, flags) if folds_full(flags) => {
+                        run.push((c, flags))
+                    }
+                    x => {
+                        cat.push_all_move(fold_run(run.as_slice()));
+                        run.clear();
+                        cat.push(fold_full(x));
+                    }
+                }
+            }
+            cat.push_all_move(fold_run(run.as_slice()));
+            ~Cat(cat)
+        }
+        ~Lookaround(x, flags) => ~Lookaround(fold_full(x), flags),
+        ~Atomic(x) => ~Atomic(fold_full(x)),
+        ~Capture(cap, name, x) => ~Capture(cap, name, fold_full(x)),
+        ~Alt(x, y) => ~Alt(fold_full(x), fold_full(y)),
+        ~Rep(x, op, g) => ~Rep(fold_full(x), op, g),
+        ast => ast,
+    }
+}
+
+fn folds_full(flags: Flags) -> bool {
+    flags & FLAG_FULLCASE > 0 && flags & FLAG_NOCASE > 0
+}
+
+// The characters that a folding starting at some position in a sequence is
+// the folding of, by the number of characters in it.
+type Windows = Vec<(uint, Vec<(char, char)>)>;
+
+// Returns the expressions that match a sequence of literals with full case
+// folding.
+fn fold_run(run: &[(char, Flags)]) -> Vec<~Ast> {
+    let mut folded = vec!();
+    for &(c, flags) in run.iter() {
+        let flags = flags & !FLAG_FULLCASE;
+        match full_fold(c) {
+            None => folded.push((c, flags)),
+            Some(s) => {
+                for f in s.chars() {
+                    folded.push((f, flags));
+                }
+            }
+        }
+    }
+    let folded = folded.as_slice();
+    let windows: Vec<Windows> = range(0, folded.len()).map(|i| {
+        fold_windows(folded, i)
+    }).collect();
+    let windows = windows.as_slice();
+    let mut asts = vec!();
+    let mut i = 0;
+    while i < folded.len() {
+        // The foldings that start before the end of this part of the
+        // sequence (and after its start) all end before it too.
+        let mut end = i + 1;
+        let mut j = i;
+        while j < end {
+            for &(len, _) in windows[j].iter() {
+                end = cmp::max(end, j + len);
+            }
+            j += 1;
+        }
+        if count_tilings(windows, i, end) <= MAX_FOLD_TILINGS {
+            asts.push(fold_tile(folded, windows, i, end));
+        } else {
+            let mut j = i;
+            while j < end {
+                let (c, flags) = folded[j];
+                match windows[j].as_slice().head() {
+                    Some(&(len, ref chars)) if j + len <= end => {
+                        let lits = folded.slice(j, j + len).iter()
+                                         .map(|&(c, f)| ~Literal(c, f))
+                                         .collect();
+                        asts.push(~Alt(~Cat(lits),
+                                       ~Class(chars.clone(), FLAG_EMPTY)));
+                        j += len;
+                    }
+                    _ => {
+                        asts.push(~Literal(c, flags));
+                        j += 1;
+                    }
+                }
+            }
+        }
+        i = end;
+    }
+    asts
+}
+
+// Returns the expression that matches the folded characters from `i` up to
+// `end` in every way that the foldings between them can tile them.
+fn fold_tile(folded: &[(char, Flags)], windows: &[Windows], i: uint,
+             end: uint) -> ~Ast {
+    let then = |first: ~Ast, next: uint| -> ~Ast {
+        if next == end {
+            first
+        } else {
+            ~concat_flatten(first, fold_tile(folded, windows, next, end))
+        }
+    };
+    let (c, flags) = folded[i];
+    let mut alts = vec!(then(~Literal(c, flags), i + 1));
+    for &(len, ref chars) in windows[i].iter() {
+        alts.push(then(~Class(chars.clone(), FLAG_EMPTY), i + len));
+    }
+    let last = alts.pop().unwrap();
+    alts.move_iter().rev().fold(last, |alt, x| ~Alt(x, alt))
+}
+
+// Returns the number of ways that the foldings from `i` up to `end` can
+// tile the characters between them, or `MAX_FOLD_TILINGS + 1` if there are
+// more.
+fn count_tilings(windows: &[Windows], i: uint, end: uint) -> uint {
+    let mut counts = Vec::from_elem(end - i + 1, 0u);
+    *counts.get_mut(end - i) = 1;
+    for j in range(i, end).rev() {
+        let mut n = *counts.get(j + 1 - i);
+        for &(len, _) in windows[j].iter() {
+            n += *counts.get(j + len - i);
+        }
+        *counts.get_mut(j - i) = cmp::min(n, MAX_FOLD_TILINGS + 1);
+    }
+    *counts.get(0)
+}
+
+// Returns the foldings that occur in `folded` at `i`.
+fn fold_windows(folded: &[(char, Flags)], i: uint) -> Windows {
+    let mut found = vec!();
+    for &(c, s) in FULL_FOLDS.iter() {
+        let n = s.char_len();
+        if i + n > folded.len() {
+            continue
+        }
+        let same = s.chars().zip(folded.slice(i, i + n).iter()).all(|(a, b)| {
+            let (b, _) = *b;
+            a == b || a.to_uppercase() == b.to_uppercase()
+                   || a.to_lowercase() == b.to_lowercase()
+        });
+        if same {
+            found.push((n, c));
+        }
+    }
+    let mut windows = vec!();
+    // Every full folding is two or three characters.
+    for n in range(2u, 4) {
+        let chars: Vec<(char, char)> = found.iter()
+            .filter(|&&(m, _)| m == n)
+            .map(|&(_, c)| (c, c))
+            .collect();
+        if chars.len() > 0 {
+            windows.push((n, fold_unicode(chars.as_slice())));
+        }
+    }
+    windows
+}
+
+// Returns the full folding of `c`, if it's more than one character.
+fn full_fold(c: char) -> Option<&'static str> {
+    match FULL_FOLDS.bsearch(|&(f, _)| f.cmp(&c)) {
+        Some(i) => Some(FULL_FOLDS[i].val1()),
+        None => None,
+    }
+}
+
+/// Returns the characters not in `ranges`, which must be sorted and must not
+/// overlap.
+pub fn negate_ranges(ranges: &[(char, char)]) -> Vec<(char, char)> {
+    let mut negated = vec!();
+    let mut next = 0u32;
+    for &(start, end) in ranges.iter() {
+        if start as u32 > next {
+            push_chars(&mut negated, next, start as u32 - 1);
+        }
+        next = end as u32 + 1;
+    }
+    if next <= MAX_CHAR as u32 {
+        push_chars(&mut negated, next, MAX_CHAR as u32);
+    }
+    negated
+}
+
+// Returns the result of the class set operation whose operator is `op`
+// doubled (`&&`, `--` or `~~`) on the characters in `xs` and `ys`, which
+// must be sorted and must not overlap.
+fn class_set_op(op: char, xs: Vec<(char, char)>, ys: Vec<(char, char)>)
+               -> Vec<(char, char)> {
+    let (xs, ys) = (xs.as_slice(), ys.as_slice());
+    match op {
+        '&' => intersect_ranges(xs, ys),
+        '-' => intersect_ranges(xs, negate_ranges(ys).as_slice()),
+        '~' => {
+            let mut either = intersect_ranges(xs, negate_ranges(ys).as_slice());
+            either.push_all_move(
+                intersect_ranges(ys, negate_ranges(xs).as_slice()));
+            combine_ranges(either)
+        }
+        _ => fail!("Not a class set operator: '{}'", op),
+    }
+}
+
+// Returns the characters in both `xs` and `ys`, which must be sorted and must
+// not overlap.
+fn intersect_ranges(xs: &[(char, char)], ys: &[(char, char)])
+                   -> Vec<(char, char)> {
+    let mut both = vec!();
+    let (mut i, mut j) = (0, 0);
+    while i < xs.len() && j < ys.len() {
+        let ((s1, e1), (s2, e2)) = (xs[i], ys[j]);
+        let (s, e) = (cmp::max(s1, s2), cmp::min(e1, e2));
+        if s <= e {
+            both.push((s, e));
+        }
+        if e1 < e2 { i += 1 } else { j += 1 }
+    }
+    both
+}
+
+// Pushes the characters from `start` to `end` (which may be surrogates, which
+// aren't characters) on to `ranges`.
+fn push_chars(ranges: &mut Vec<(char, char)>, start: u32, end: u32) {
+    let (start, end) = match (start, end) {
+        (s, e) if s >= 0xD800 && e <= 0xDFFF => return,
+        (s, e) if s >= 0xD800 && s <= 0xDFFF => (0xE000, e),
+        (s, e) if e >= 0xD800 && e <= 0xDFFF => (s, 0xD7FF),
+        se => se,
+    };
+    if start <= end {
+        ranges.push((char::from_u32(start).unwrap(),
+                     char::from_u32(end).unwrap()));
+    }
+}
+
+// Returns the sequences of byte ranges that match the UTF8 encodings of the
+// characters in `ranges`, which must be sorted. A range is split until the
+// encodings of its characters all have the same length and differ only in
+// every byte between the first and last ones, which then give the ranges of
+// each byte. (e.g., U+0080-U+07FF is `[\xC2-\xDF][\x80-\xBF]`.)
+fn utf8_sequences(ranges: &[(char, char)]) -> Vec<Vec<(u8, u8)>> {
+    let mut seqs = vec!();
+    let mut todo: Vec<(u32, u32)> = ranges.iter().rev().map(|&(s, e)| {
+        (s as u32, e as u32)
+    }).collect();
+    'ranges: loop {
+        let (s, e) = match todo.pop() {
+            None => break,
+            Some(range) => range,
+        };
+        if s < 0xD800 && e > 0xDFFF {
+            todo.push((0xE000, e));
+            todo.push((s, 0xD7FF));
+            continue
+        }
+        for &max in [0x7Fu32, 0x7FF, 0xFFFF].iter() {
+            if s <= max && max < e {
+                todo.push((max + 1, e));
+                todo.push((s, max));
+                continue 'ranges
+            }
+        }
+        if e <= 0x7F {
+            seqs.push(vec!((s as u8, e as u8)));
+            continue
+        }
+        for i in range(1u32, 4) {
+            let m = (1u32 << (6 * i)) - 1;
+            if s & !m != e & !m {
+                if s & m != 0 {
+                    todo.push(((s | m) + 1, e));
+                    todo.push((s, s | m));
+                    continue 'ranges
+                }
+                if e & m != m {
+                    todo.push((e & !m, e));
+                    todo.push((s, (e & !m) - 1));
+                    continue 'ranges
+                }
+            }
+        }
+        let (mut sbuf, mut ebuf) = ([0u8, ..4], [0u8, ..4]);
+        let n = char::from_u32(s).unwrap().encode_utf8(sbuf.as_mut_slice());
+        char::from_u32(e).unwrap().encode_utf8(ebuf.as_mut_slice());
+        seqs.push(range(0, n).map(|i| (sbuf[i], ebuf[i])).collect());
+    }
+    seqs
+}
+
+// Returns the other case of an ASCII letter or `c` itself if it isn't one.
+pub fn swap_ascii_case(c: char) -> char {
+    match c {
+        'a' .. 'z' => ((c as u8) - 32) as char,
+        'A' .. 'Z' => ((c as u8) + 32) as char,
+        _ => c,
+    }
+}
+
 // Returns a concatenation of two expressions. This also guarantees that a
 // `Cat` expression will never be a direct child of another `Cat` expression.
 fn concat_flatten(x: ~Ast, y: ~Ast) -> Ast {
@@ -990,8 +2469,232 @@ fn find_class(classes: NamedClasses, name: &str) -> Option<Vec<(char, char)>> {
     }
 }
 
+// Returns the character with the Unicode name given, which is matched
+// without regard to ASCII case. The names of CJK unified ideographs (e.g.,
+// `CJK UNIFIED IDEOGRAPH-4E00`) and Hangul syllables (e.g., `HANGUL SYLLABLE
+// GAG`) aren't in the table, since they're derived from their code points.
+#[cfg(not(no_unicode_names))]
+fn char_from_name(name: &str) -> Option<char> {
+    use self::unicode_names::{UNICODE_NAMES, UNIFIED_IDEOGRAPHS};
+    use self::unicode_names::{JAMO_L, JAMO_V, JAMO_T};
+
+    let name = name.to_ascii_upper();
+    let name = name.as_slice();
+    match UNICODE_NAMES.bsearch(|&(n, _)| n.cmp(&name)) {
+        Some(i) => return Some(UNICODE_NAMES[i].val1()),
+        None => {}
+    }
+    if name.starts_with("CJK UNIFIED IDEOGRAPH-") {
+        let hex = name.slice_from("CJK UNIFIED IDEOGRAPH-".len());
+        let c = match num::from_str_radix::<u32>(hex, 16) {
+            None => return None,
+            Some(n) => match char::from_u32(n) {
+                None => return None,
+                Some(c) => c,
+            },
+        };
+        // Only the canonical spelling of the code point is its name.
+        if format!("{:X}", c as u32).as_slice() != hex {
+            return None
+        }
+        let found = UNIFIED_IDEOGRAPHS.iter()
+            .any(|&(s, e)| s <= c && c <= e);
+        return if found { Some(c) } else { None }
+    }
+    if name.starts_with("HANGUL SYLLABLE ") {
+        // A syllable's name is the short names of its leading consonant,
+        // its vowel and its trailing consonant (any of which but the vowel
+        // may be empty), so every way of splitting it is tried.
+        let jamo = name.slice_from("HANGUL SYLLABLE ".len());
+        for (l, lname) in JAMO_L.iter().enumerate() {
+            if !jamo.starts_with(*lname) {
+                continue
+            }
+            let rest = jamo.slice_from(lname.len());
+            for (v, vname) in JAMO_V.iter().enumerate() {
+                if !rest.starts_with(*vname) {
+                    continue
+                }
+                let rest = rest.slice_from(vname.len());
+                for (t, tname) in JAMO_T.iter().enumerate() {
+                    if rest == *tname {
+                        let n = 0xAC00 + ((l * 21 + v) * 28 + t) as u32;
+                        return char::from_u32(n)
+                    }
+                }
+            }
+        }
+    }
+    None
+}
+
+#[cfg(no_unicode_names)]
+fn char_from_name(_: &str) -> Option<char> {
+    None
+}
+
+// Returns the ranges of the Unicode class named, which is a name in
+// `UNICODE_CLASSES` spelled exactly, or a property matched loosely (see
+// `loose_name`): a general category, a script, a binary property or one of
+// the pseudo-properties Any, Assigned and ASCII, optionally preceded by
+// `Is`. It can also be `key=value` (or `key:value`), where the key is the
+// general category (`gc`), the script (`sc`), the script extensions (`scx`)
+// or a binary property, whose value is then `Yes` or `No`.
+//
+// When there's no such class, the names that are closest to the name given
+// are returned instead.
+fn unicode_class(name: &str) -> Result<Vec<(char, char)>, Vec<&'static str>> {
+    match find_class(UNICODE_CLASSES, name) {
+        Some(ranges) => return Ok(ranges),
+        None => {}
+    }
+    let (key, value) = match name.find(|c: char| c == '=' || c == ':') {
+        None => return loose_class(loose_name(name).as_slice()),
+        Some(i) => {
+            (loose_name(name.slice_to(i)), loose_name(name.slice_from(i + 1)))
+        }
+    };
+    let value = value.as_slice();
+    match key.as_slice() {
+        "gc" | "generalcategory" => {
+            match pseudo_class(value) {
+                Some(ranges) => Ok(ranges),
+                None => find_alias(&[GC_NAMES], value, UNICODE_CLASSES),
+            }
+        }
+        "sc" | "script" => find_alias(&[SCRIPT_NAMES], value, UNICODE_CLASSES),
+        "scx" | "scriptextensions" => {
+            let mut ranges =
+                try!(find_alias(&[SCRIPT_NAMES], value, UNICODE_CLASSES));
+            let script = SCRIPT_NAMES.iter()
+                .find(|&&(alias, _)| loose_name(alias).as_slice() == value)
+                .unwrap().val1();
+            match find_class(SCRIPT_EXTRAS, script) {
+                None => {}
+                Some(extras) => ranges.push_all_move(extras),
+            }
+            Ok(combine_ranges(ranges))
+        }
+        key => {
+            let ranges = try!(find_alias(&[BINARY_NAMES], key,
+                                         BINARY_PROPERTIES));
+            match value {
+                "y" | "yes" | "t" | "true" => Ok(ranges),
+                "n" | "no" | "f" | "false" => {
+                    Ok(negate_ranges(combine_ranges(ranges).as_slice()))
+                }
+                _ => Err(vec!("Yes", "No")),
+            }
+        }
+    }
+}
+
+// Returns the ranges of the class named loosely without a key, trying it
+// without an `is` prefix too.
+fn loose_class(name: &str) -> Result<Vec<(char, char)>, Vec<&'static str>> {
+    let names: &[Aliases] = &[GC_NAMES, SCRIPT_NAMES, BINARY_NAMES];
+    let mut tries = vec!(name);
+    if name.starts_with("is") {
+        tries.push(name.slice_from(2));
+    }
+    for &name in tries.iter() {
+        match pseudo_class(name) {
+            Some(ranges) => return Ok(ranges),
+            None => {}
+        }
+        let found = find_alias(names.slice_to(2), name, UNICODE_CLASSES)
+            .or_else(|_| find_alias(names.slice_from(2), name,
+                                    BINARY_PROPERTIES));
+        if found.is_ok() {
+            return found
+        }
+    }
+    Err(near_names(names, name))
+}
+
+// Returns the ranges of the pseudo-properties that have no table of their
+// own, Any, ASCII and Unassigned (the general category Cn).
+fn pseudo_class(name: &str) -> Option<Vec<(char, char)>> {
+    match name {
+        "any" => Some(vec!(('\x00', MAX_CHAR))),
+        "ascii" => Some(vec!(('\x00', '\x7F'))),
+        "cn" | "unassigned" => {
+            let assigned = find_class(BINARY_PROPERTIES, "Assigned").unwrap();
+            Some(negate_ranges(assigned.as_slice()))
+        }
+        _ => None,
+    }
+}
+
+// Returns the class of `classes` that the alias matching `name` loosely in
+// one of `names` is for, or the aliases closest to `name` if none does.
+fn find_alias(names: &[Aliases], name: &str, classes: NamedClasses)
+             -> Result<Vec<(char, char)>, Vec<&'static str>> {
+    for aliases in names.iter() {
+        for &(alias, class) in aliases.iter() {
+            if loose_name(alias).as_slice() == name {
+                return Ok(find_class(classes, class).unwrap())
+            }
+        }
+    }
+    Err(near_names(names, name))
+}
+
+// Returns the form of a property name (or value) that's compared when
+// matching it loosely (by rule UAX44-LM3 of Unicode Standard Annex #44):
+// case, spaces, underscores and hyphens are ignored.
+fn loose_name(name: &str) -> ~str {
+    name.chars()
+        .filter(|&c| c != ' ' && c != '_' && c != '-')
+        .map(|c| c.to_lowercase())
+        .collect()
+}
+
+// Returns up to three aliases in `names` that are a couple of edits at most
+// away from `name` (compared loosely), the closest first. Only the closest
+// alias of each class is returned.
+fn near_names(names: &[Aliases], name: &str) -> Vec<&'static str> {
+    let max = if name.char_len() < 4 { 1 } else { 2 };
+    let mut near: Vec<(uint, &'static str, &'static str)> = vec!();
+    for aliases in names.iter() {
+        for &(alias, class) in aliases.iter() {
+            let dist = edit_distance(loose_name(alias).as_slice(), name);
+            if dist > max {
+                continue
+            }
+            match near.iter().position(|&(_, _, c)| c == class) {
+                Some(i) if near.get(i).val0() <= dist => {}
+                Some(i) => *near.get_mut(i) = (dist, alias, class),
+                None => near.push((dist, alias, class)),
+            }
+        }
+    }
+    near.as_mut_slice().sort_by(|&(d1, _, _), &(d2, _, _)| d1.cmp(&d2));
+    near.move_iter().take(3).map(|(_, alias, _)| alias).collect()
+}
+
+// Returns the number of characters that must be inserted, deleted or
+// replaced to turn `a` into `b` (their Levenshtein distance).
+fn edit_distance(a: &str, b: &str) -> uint {
+    let b: Vec<char> = b.chars().collect();
+    let mut prev: Vec<uint> = range(0, b.len() + 1).collect();
+    for (i, ca) in a.chars().enumerate() {
+        let mut cur = vec!(i + 1);
+        for (j, &cb) in b.iter().enumerate() {
+            let replace = *prev.get(j) + if ca == cb { 0 } else { 1 };
+            let best = cmp::min(replace, cmp::min(*prev.get(j + 1) + 1,
+                                                  *cur.get(j) + 1));
+            cur.push(best);
+        }
+        prev = cur;
+    }
+    *prev.get(b.len())
+}
+
 type Class = &'static [(char, char)];
 type NamedClasses = &'static [(&'static str, Class)];
+// Aliases of the names of classes, with the name each is for.
+type Aliases = &'static [(&'static str, &'static str)];
 
 static ASCII_CLASSES: NamedClasses = &[
     // Classes must be in alphabetical order so that bsearch works.
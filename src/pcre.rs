@@ -0,0 +1,888 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module translates PCRE patterns to the syntax of this crate. Most of
+// PCRE's syntax means the same thing here and is copied as is. What's
+// rewritten falls in three groups:
+//
+// * Syntax that's spelled differently, like `(?<name>...)`, `\g{-1}`,
+//   `\Q...\E`, `\e` or `\o{...}`.
+// * Syntax that's spelled the same but means something else, like `$`
+//   (which PCRE also matches before a new line at the end, as `\Z` does
+//   here), `\d`, `\w`,
+//   `\s` and `\b` (which are ASCII only in PCRE unless the pattern starts
+//   with `(*UCP)`), `\v` (vertical whitespace in PCRE) or a `[` in a class
+//   (which is literal in PCRE, but starts a nested class here).
+// * Syntax that's literal in PCRE but special here, like a `{` that doesn't
+//   start a repetition, or `&&` and `--` in a class.
+//
+// Anything that can't be translated exactly is an error (e.g., recursion
+// or conditional groups), except for the few constructs whose meaning
+// depends on the version of PCRE, which are translated as the older
+// versions read them, with a warning.
+//
+// The translation keeps, for every character it writes, the position of the
+// PCRE syntax it came from, so that an error in the translated expression
+// can be reported at a position in the original pattern.
+
+use std::char;
+use std::cmp;
+use std::fmt;
+use std::from_str::from_str;
+use std::num;
+use std::str;
+use std::uint;
+
+use parse;
+use re::Regex;
+
+// The characters matched by PCRE's `\h` and `\v`, as the items of a class.
+static HSPACE: &'static str = "\\t\\x20\\xA0\\x{1680}\\x{180E}\\x{2000}-\
+                               \\x{200A}\\x{202F}\\x{205F}\\x{3000}";
+static VSPACE: &'static str = "\\n\\x0B\\x0C\\r\\x{85}\\x{2028}\\x{2029}";
+
+// The characters matched by PCRE's `\d`, `\w` and `\s` without `(*UCP)`.
+static DIGIT: &'static str = "0-9";
+static WORD: &'static str = "0-9A-Za-z_";
+static SPACE: &'static str = "\\t\\n\\x0B\\x0C\\r\\x20";
+
+/// PcreError describes a PCRE pattern that can't be translated, or whose
+/// translation isn't a valid expression.
+#[deriving(Clone)]
+pub struct PcreError {
+    /// The character index in the pattern of the syntax at fault.
+    pub pos: uint,
+    /// A message describing the error.
+    pub msg: ~str,
+    /// What went wrong.
+    pub kind: PcreErrorKind,
+}
+
+/// PcreErrorKind tells the PCRE features that have no translation apart
+/// from patterns that are malformed.
+#[deriving(Clone, Eq, Show)]
+pub enum PcreErrorKind {
+    /// Recursion or a subroutine call, like `(?R)`, `(?1)`, `(?&name)` or
+    /// `\g<name>`.
+    PcreRecursion,
+    /// A conditional group, like `(?(1)a|b)`.
+    PcreConditional,
+    /// A backtracking control verb like `(*SKIP)`, an option at the start
+    /// of the pattern other than `(*UTF)` and `(*UCP)`, or a callout like
+    /// `(?C1)`.
+    PcreControl,
+    /// Any other feature that has no translation, like branch reset groups
+    /// or `\C`.
+    PcreUnsupported,
+    /// The pattern isn't valid PCRE, or its translation isn't a valid
+    /// expression.
+    PcreSyntax,
+}
+
+impl fmt::Show for PcreError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "PCRE translation error near position {}: {}",
+               self.pos, self.msg)
+    }
+}
+
+/// Translates a PCRE pattern to an expression that matches the same text in
+/// the same way, and returns it along with warnings about the constructs
+/// whose meaning depends on the version of PCRE (see `Regex::from_pcre`).
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::translate_pcre;
+/// let (re, warnings) = translate_pcre(r"(?<year>\d{4})\Z").unwrap();
+/// assert_eq!(re.as_slice(), r"(?P<year>[0-9]{4})\Z");
+/// assert!(warnings.is_empty());
+/// ```
+pub fn translate_pcre(pattern: &str)
+                     -> Result<(~str, Vec<~str>), PcreError> {
+    let mut t = Translator::new(pattern);
+    try!(t.translate());
+    Ok((t.out.into_owned(), t.warnings))
+}
+
+/// Translates a PCRE pattern and compiles the translation. An error in the
+/// translation is reported at the position in the pattern that it came
+/// from.
+pub fn compile(pattern: &str) -> Result<(Regex, Vec<~str>), PcreError> {
+    let mut t = Translator::new(pattern);
+    try!(t.translate());
+    match Regex::new(t.out.as_slice()) {
+        Ok(re) => Ok((re, t.warnings)),
+        Err(err) => {
+            let pos = if err.pos < t.origins.len() {
+                *t.origins.get(err.pos)
+            } else {
+                t.chars.len()
+            };
+            Err(PcreError {
+                pos: pos,
+                msg: format!("The translation '{}' isn't valid: {}", t.out,
+                             err.msg),
+                kind: PcreSyntax,
+            })
+        }
+    }
+}
+
+// The options that change how the syntax that follows is translated.
+#[deriving(Clone)]
+struct Mode {
+    multi: bool,
+    extended: bool,
+}
+
+struct Translator {
+    chars: Vec<char>,
+    i: uint,
+    out: StrBuf,
+    // The position in `chars` of the syntax that each character of `out`
+    // was translated from.
+    origins: Vec<uint>,
+    // Where the syntax being translated starts.
+    at: uint,
+    warnings: Vec<~str>,
+    mode: Mode,
+    // The modes of the groups that are open, to restore when they close.
+    scopes: Vec<Mode>,
+    // Whether `\d`, `\w`, `\s` and `\b` are Unicode (after `(*UCP)`).
+    ucp: bool,
+    // The number of capture groups opened so far.
+    groups: uint,
+}
+
+impl Translator {
+    fn new(pattern: &str) -> Translator {
+        Translator {
+            chars: pattern.chars().collect(),
+            i: 0,
+            out: StrBuf::with_capacity(pattern.len()),
+            origins: Vec::with_capacity(pattern.len()),
+            at: 0,
+            warnings: vec!(),
+            mode: Mode { multi: false, extended: false },
+            scopes: vec!(),
+            ucp: false,
+            groups: 0,
+        }
+    }
+
+    fn translate(&mut self) -> Result<(), PcreError> {
+        try!(self.start_options());
+        while self.i < self.chars.len() {
+            self.at = self.i;
+            match self.cur() {
+                '\\' => try!(self.escape(false)),
+                '[' => try!(self.class()),
+                '(' => try!(self.group()),
+                ')' => {
+                    // An unbalanced ')' is left for the parser to report.
+                    match self.scopes.pop() {
+                        Some(mode) => self.mode = mode,
+                        None => {}
+                    }
+                    self.copy(1);
+                }
+                '$' if !self.mode.multi => {
+                    // `$` also matches before a new line at the end.
+                    self.emit("\\Z");
+                    self.i += 1;
+                }
+                '{' => self.brace(),
+                ']' | '}' => {
+                    self.emit_char('\\');
+                    self.copy(1);
+                }
+                '#' if self.mode.extended => {
+                    while self.i < self.chars.len() && self.cur() != '\n' {
+                        self.copy(1);
+                    }
+                }
+                _ => self.copy(1),
+            }
+        }
+        Ok(())
+    }
+
+    // Translates the options that may start a pattern, like `(*UCP)`.
+    fn start_options(&mut self) -> Result<(), PcreError> {
+        while self.starts_with("(*") {
+            let start = self.i;
+            let name = match self.until(start + 2, ')') {
+                None => return self.err(start, PcreSyntax,
+                                        "A '(*' isn't closed."),
+                Some(name) => name,
+            };
+            match name.as_slice() {
+                "UTF" | "UTF8" => {}
+                "UCP" => self.ucp = true,
+                _ => {
+                    return self.err(start, PcreControl, format!(
+                        "The option '(*{})' isn't supported.", name))
+                }
+            }
+            self.i = start + 3 + name.char_len();
+        }
+        Ok(())
+    }
+
+    // Translates an escape sequence, in a class if `class` is true.
+    // Assumes that '\' is the current character.
+    fn escape(&mut self, class: bool) -> Result<(), PcreError> {
+        let start = self.i;
+        let c = match self.peek(1) {
+            None => return self.err(start, PcreSyntax,
+                                    "The pattern ends with a '\\'."),
+            Some(c) => c,
+        };
+        self.i += 2;
+        match c {
+            'Q' => {
+                let end = self.find_seq(self.i, "\\E")
+                              .unwrap_or(self.chars.len());
+                for i in range(self.i, end) {
+                    let c = *self.chars.get(i);
+                    self.emit_literal(c);
+                }
+                self.i = if end < self.chars.len() { end + 2 } else { end };
+            }
+            // A '\E' without a '\Q' is ignored.
+            'E' => {}
+            'd' | 'D' | 's' | 'S' | 'w' | 'W' if !self.ucp => {
+                let items = match c.to_lowercase() {
+                    'd' => DIGIT,
+                    'w' => WORD,
+                    _ => SPACE,
+                };
+                self.emit_class(items, c.is_uppercase(), class);
+            }
+            'b' if class => self.emit("\\x08"),
+            'b' | 'B' if !self.ucp => {
+                self.emit(if c == 'b' { "(?-u:\\b)" } else { "(?-u:\\B)" })
+            }
+            'h' | 'H' => self.emit_class(HSPACE, c == 'H', class),
+            'v' | 'V' => self.emit_class(VSPACE, c == 'V', class),
+            'Z' if !class => self.emit("\\Z"),
+            'N' if self.peek(0) == Some('{') => {
+                let name = match self.until(self.i + 1, '}') {
+                    Some(name) => name,
+                    None => {
+                        return self.err(start, PcreSyntax,
+                                        "A '\\N{' isn't closed.")
+                    }
+                };
+                if name.starts_with("U+") {
+                    self.i += name.char_len() + 2;
+                    try!(self.emit_code(start, name.slice_from(2), 16));
+                } else {
+                    // A name is copied, and left for the parser to look up.
+                    self.i = start;
+                    self.copy(name.char_len() + 4);
+                }
+            }
+            'N' if !class => self.emit("[^\\n]"),
+            'e' => self.emit("\\x1B"),
+            'c' => {
+                let x = match self.peek(0) {
+                    Some(x) if x.is_ascii() => x.to_uppercase(),
+                    _ => return self.err(start, PcreSyntax,
+                                         "A '\\c' must be followed by an \
+                                          ASCII character."),
+                };
+                self.i += 1;
+                let code = format!("{:x}", x as u32 ^ 0x40);
+                try!(self.emit_code(start, code.as_slice(), 16));
+            }
+            'o' => {
+                let digits = match self.peek(0) {
+                    Some('{') => self.until(self.i + 1, '}'),
+                    _ => None,
+                };
+                let digits = match digits {
+                    None => return self.err(start, PcreSyntax,
+                                            "A '\\o' must be followed by \
+                                             '{' and octal digits."),
+                    Some(digits) => digits,
+                };
+                self.i += digits.char_len() + 2;
+                try!(self.emit_code(start, digits.as_slice(), 8));
+            }
+            'x' => {
+                let digits = if self.peek(0) == Some('{') {
+                    match self.until(self.i + 1, '}') {
+                        None => return self.err(start, PcreSyntax,
+                                                "A '\\x{' isn't closed."),
+                        Some(digits) => {
+                            self.i += digits.char_len() + 2;
+                            digits
+                        }
+                    }
+                } else {
+                    // Up to two digits, and none is NUL.
+                    let digits = self.digits(16, 2);
+                    if digits.is_empty() { ~"0" } else { digits }
+                };
+                try!(self.emit_code(start, digits.as_slice(), 16));
+            }
+            '0' => {
+                // Up to two more octal digits.
+                let digits = format!("0{}", self.digits(8, 2));
+                try!(self.emit_code(start, digits.as_slice(), 8));
+            }
+            '1' .. '9' => try!(self.number_escape(start, c, class)),
+            'g' => try!(self.g_backref(start)),
+            'k' => try!(self.k_backref(start)),
+            'C' => {
+                return self.err(start, PcreUnsupported,
+                                "'\\C' (which matches a single byte) isn't \
+                                 supported.")
+            }
+            'p' | 'P' => {
+                let name = match self.peek(0) {
+                    Some('{') => match self.until(self.i + 1, '}') {
+                        None => return self.err(start, PcreSyntax,
+                                                "A '\\p{' isn't closed."),
+                        Some(name) => name,
+                    },
+                    Some(c) => str::from_char(c),
+                    None => return self.err(start, PcreSyntax,
+                                            "A '\\p' must be followed by \
+                                             the name of a property."),
+                };
+                self.i += if self.peek(0) == Some('{') {
+                    name.char_len() + 2
+                } else {
+                    1
+                };
+                // PCRE negates with '\p{^...}' too.
+                let negated = name.starts_with("^");
+                let name = if negated {
+                    name.slice_from(1)
+                } else {
+                    name.as_slice()
+                };
+                let p = if negated != (c == 'P') { "\\P{" } else { "\\p{" };
+                self.emit(p);
+                self.emit(name);
+                self.emit("}");
+            }
+            c if c.is_alphanumeric() => {
+                self.emit_char('\\');
+                self.emit_char(c);
+            }
+            // Any other character that's escaped is literal.
+            c => self.emit_literal(c),
+        }
+        Ok(())
+    }
+
+    // Translates an escape that starts with a digit other than 0, which
+    // PCRE reads as a backreference or as an octal escape. Assumes that the
+    // first digit has been read.
+    fn number_escape(&mut self, start: uint, first: char, class: bool)
+                    -> Result<(), PcreError> {
+        let digits = format!("{}{}", first, self.digits(10, uint::MAX));
+        let n = from_str::<uint>(digits.as_slice()).unwrap_or(uint::MAX);
+        // Outside of a class, PCRE reads `\1` to `\9` as backreferences,
+        // and so is any other number of a group that comes before it.
+        if !class && (n < 10 || n <= self.groups) {
+            return self.emit_backref(start, n)
+        }
+        let octal: ~str = digits.chars().take_while(|c| c.is_digit_radix(8))
+                                .take(3).collect();
+        if octal.is_empty() {
+            self.warn(start, format!(
+                "'\\\\{}' in a class is the literal '{}' in older versions \
+                 of PCRE, and an error in newer ones. It's translated as \
+                 the literal.", first, first));
+            self.emit_char(first);
+        } else {
+            if !class {
+                self.warn(start, format!(
+                    "'\\\\{}' is read as the octal escape '\\\\{}', since \
+                     there aren't as many groups before it.", digits, octal));
+            }
+            try!(self.emit_code(start, octal.as_slice(), 8));
+        }
+        // The digits after the escape are literal.
+        self.i = start + 1 + cmp::max(octal.char_len(), 1);
+        Ok(())
+    }
+
+    // Translates `\g` followed by the number of a group (which may be
+    // relative, like `\g{-1}`) or by its name. Assumes that `\g` has been
+    // read.
+    fn g_backref(&mut self, start: uint) -> Result<(), PcreError> {
+        let group = match self.peek(0) {
+            Some('<') | Some('\'') => {
+                return self.err(start, PcreRecursion,
+                                "Subroutine calls like '\\g<name>' aren't \
+                                 supported.")
+            }
+            Some('{') => match self.until(self.i + 1, '}') {
+                None => return self.err(start, PcreSyntax,
+                                        "A '\\g{' isn't closed."),
+                Some(group) => {
+                    self.i += group.char_len() + 2;
+                    group
+                }
+            },
+            _ => {
+                let minus = self.peek(0) == Some('-');
+                if minus {
+                    self.i += 1;
+                }
+                let digits = self.digits(10, uint::MAX);
+                if digits.is_empty() {
+                    return self.err(start, PcreSyntax,
+                                    "A '\\g' must be followed by a group.")
+                }
+                if minus { format!("-{}", digits) } else { digits }
+            }
+        };
+        if group.starts_with("-") {
+            match from_str::<uint>(group.slice_from(1)) {
+                Some(n) if n > 0 && n <= self.groups => {
+                    let n = self.groups + 1 - n;
+                    return self.emit_backref(start, n)
+                }
+                _ => {
+                    return self.err(start, PcreSyntax, format!(
+                        "'\\\\g\\{{}\\}' doesn't refer to a group before it.",
+                        group))
+                }
+            }
+        }
+        match from_str::<uint>(group.as_slice()) {
+            Some(n) => self.emit_backref(start, n),
+            None => {
+                self.emit("\\k<");
+                self.emit(group.as_slice());
+                self.emit(">");
+                Ok(())
+            }
+        }
+    }
+
+    // Translates `\k<name>`, `\k'name'` or `\k{name}`. Assumes that `\k`
+    // has been read.
+    fn k_backref(&mut self, start: uint) -> Result<(), PcreError> {
+        let close = match self.peek(0) {
+            Some('<') => '>',
+            Some('\'') => '\'',
+            Some('{') => '}',
+            _ => return self.err(start, PcreSyntax,
+                                 "A '\\k' must be followed by a name."),
+        };
+        let name = match self.until(self.i + 1, close) {
+            None => return self.err(start, PcreSyntax,
+                                    "A group name isn't closed."),
+            Some(name) => name,
+        };
+        self.i += name.char_len() + 2;
+        self.emit("\\k<");
+        self.emit(name.as_slice());
+        self.emit(">");
+        Ok(())
+    }
+
+    // Writes a backreference to the group numbered `n`.
+    fn emit_backref(&mut self, start: uint, n: uint)
+                   -> Result<(), PcreError> {
+        if n == 0 || n > 9 {
+            return self.err(start, PcreUnsupported, format!(
+                "A backreference to group {} isn't supported, since only \
+                 groups 1 to 9 can be referred to.", n))
+        }
+        // A digit after it would be read as part of it.
+        let digit = self.peek(0).map_or(false, |c| c.is_digit());
+        if digit {
+            self.emit("(?:");
+        }
+        self.emit_char('\\');
+        self.emit_char(char::from_digit(n, 10).unwrap());
+        if digit {
+            self.emit(")");
+        }
+        Ok(())
+    }
+
+    // Translates the start of a group. Assumes that '(' is the current
+    // character.
+    fn group(&mut self) -> Result<(), PcreError> {
+        let start = self.i;
+        if self.peek(1) == Some('*') {
+            return self.err(start, PcreControl,
+                            "Backtracking control verbs like '(*SKIP)' \
+                             aren't supported.")
+        }
+        if self.peek(1) != Some('?') {
+            self.groups += 1;
+            self.open(1);
+            return Ok(())
+        }
+        // `(?R)`, `(?1)`, `(?-1)`, `(?+1)` and `(?&name)`.
+        let recursion = match (self.peek(2), self.peek(3)) {
+            (Some('-'), Some(c)) => c.is_digit(),
+            (Some(c), _) => c == 'R' || c == '&' || c == '+' || c.is_digit(),
+            (None, _) => false,
+        };
+        match self.peek(2) {
+            _ if recursion => {
+                return self.err(start, PcreRecursion,
+                                "Recursion and subroutine calls like '(?R)', \
+                                 '(?1)' or '(?&name)' aren't supported.")
+            }
+            Some('#') => match self.find_seq(start, ")") {
+                None => return self.err(start, PcreSyntax,
+                                        "A comment isn't closed."),
+                Some(end) => self.i = end + 1,
+            },
+            Some(':') | Some('>') | Some('=') | Some('!') => self.open(3),
+            Some('<') if self.peek(3) == Some('=')
+                         || self.peek(3) == Some('!') => self.open(4),
+            Some('<') => try!(self.named_group(start, 3, '>')),
+            Some('\'') => try!(self.named_group(start, 3, '\'')),
+            Some('P') if self.peek(3) == Some('<') => {
+                try!(self.named_group(start, 4, '>'))
+            }
+            Some('P') if self.peek(3) == Some('=') => {
+                let name = match self.until(start + 4, ')') {
+                    None => return self.err(start, PcreSyntax,
+                                            "A group name isn't closed."),
+                    Some(name) => name,
+                };
+                self.i = start + name.char_len() + 5;
+                self.emit("\\k<");
+                self.emit(name.as_slice());
+                self.emit(">");
+            }
+            Some('P') if self.peek(3) == Some('>') => {
+                return self.err(start, PcreRecursion,
+                                "Subroutine calls like '(?P>name)' aren't \
+                                 supported.")
+            }
+            Some('|') => {
+                return self.err(start, PcreUnsupported,
+                                "Branch reset groups like '(?|a|b)' aren't \
+                                 supported.")
+            }
+            Some('(') => {
+                return self.err(start, PcreConditional,
+                                "Conditional groups like '(?(1)a|b)' aren't \
+                                 supported.")
+            }
+            Some('C') => {
+                return self.err(start, PcreControl,
+                                "Callouts like '(?C1)' aren't supported.")
+            }
+            _ => try!(self.options(start)),
+        }
+        Ok(())
+    }
+
+    // Translates a named group, whose name starts `skip` characters after
+    // the '(' and ends before `close`.
+    fn named_group(&mut self, start: uint, skip: uint, close: char)
+                  -> Result<(), PcreError> {
+        let name = match self.until(start + skip, close) {
+            None => return self.err(start, PcreSyntax,
+                                    "A group name isn't closed."),
+            Some(name) => name,
+        };
+        self.groups += 1;
+        self.scopes.push(self.mode);
+        self.i = start + skip + name.char_len() + 1;
+        self.emit("(?P<");
+        self.emit(name.as_slice());
+        self.emit(">");
+        Ok(())
+    }
+
+    // Translates a group that sets options, like `(?i)` or `(?m-x:...)`.
+    // The options are spelled the same here, so only the ones this crate
+    // lacks need to be checked.
+    fn options(&mut self, start: uint) -> Result<(), PcreError> {
+        let mut mode = self.mode;
+        let mut on = true;
+        let mut i = start + 2;
+        loop {
+            if i >= self.chars.len() {
+                return self.err(start, PcreSyntax, "A group isn't closed.")
+            }
+            match *self.chars.get(i) {
+                'i' | 's' | 'U' => {}
+                'm' => mode.multi = on,
+                'x' if self.char_at(i + 1) == Some('x') => {
+                    return self.err(i, PcreUnsupported,
+                                    "The option 'xx' isn't supported.")
+                }
+                'x' => mode.extended = on,
+                '-' => on = false,
+                ':' | ')' => break,
+                c => {
+                    return self.err(i, PcreUnsupported, format!(
+                        "The option '{}' isn't supported.", c))
+                }
+            }
+            i += 1;
+        }
+        if *self.chars.get(i) == ':' {
+            self.scopes.push(self.mode);
+        }
+        self.mode = mode;
+        self.copy(i + 1 - start);
+        Ok(())
+    }
+
+    // Copies a '{' that starts a repetition, or escapes it (since it's
+    // literal in PCRE). Assumes that '{' is the current character.
+    fn brace(&mut self) {
+        match self.until(self.i + 1, '}') {
+            Some(ref body) if is_repetition(body.as_slice()) => {
+                self.copy(body.char_len() + 2);
+                return
+            }
+            Some(ref body) if body.starts_with(",")
+                              && body.len() > 1
+                              && is_repetition(body.slice_from(1)) => {
+                self.warn(self.i, format!(
+                    "'\\{{}\\}' is literal text in versions of PCRE before \
+                     10.43, which are what it's translated for, and a \
+                     repetition in later ones.", body));
+            }
+            _ => {}
+        }
+        self.emit_char('\\');
+        self.copy(1);
+    }
+
+    // Translates a class. Assumes that '[' is the current character.
+    fn class(&mut self) -> Result<(), PcreError> {
+        let start = self.i;
+        self.copy(1);
+        if self.peek(0) == Some('^') {
+            self.copy(1);
+        }
+        // A ']' first is literal in PCRE.
+        if self.peek(0) == Some(']') {
+            self.emit_char('\\');
+            self.copy(1);
+        }
+        loop {
+            self.at = self.i;
+            let c = match self.peek(0) {
+                None => return self.err(start, PcreSyntax,
+                                        "A class isn't closed."),
+                Some(c) => c,
+            };
+            match c {
+                ']' => {
+                    self.copy(1);
+                    return Ok(())
+                }
+                '\\' => try!(self.escape(true)),
+                '[' if self.posix_class_len() > 0 => {
+                    let n = self.posix_class_len();
+                    self.copy(n);
+                }
+                // These are literal in PCRE, but a nested class or a set
+                // operation here.
+                '[' | '&' | '~' => self.emit_literal_at(c),
+                '-' if self.peek(1) == Some('-') => {
+                    self.emit_literal_at(c);
+                    self.emit_literal_at(c);
+                }
+                c if c.is_whitespace() && self.mode.extended => {
+                    self.emit_literal_at(c)
+                }
+                _ => self.copy(1),
+            }
+        }
+    }
+
+    // Returns the length of the POSIX class (like `[:alpha:]`) that starts
+    // at the current character, or 0 if there's none.
+    fn posix_class_len(&self) -> uint {
+        if self.peek(1) != Some(':') {
+            return 0
+        }
+        let mut i = self.i + 2;
+        if self.char_at(i) == Some('^') {
+            i += 1;
+        }
+        while i < self.chars.len() && self.chars.get(i).is_alphabetic() {
+            i += 1;
+        }
+        match self.find_seq(i, ":]") {
+            Some(end) if end == i => end + 2 - self.i,
+            _ => 0,
+        }
+    }
+
+    // Copies `n` characters.
+    fn copy(&mut self, n: uint) {
+        for _ in range(0, n) {
+            let c = *self.chars.get(self.i);
+            self.out.push_char(c);
+            self.origins.push(self.i);
+            self.i += 1;
+        }
+    }
+
+    // Opens a group, whose first `n` characters are copied.
+    fn open(&mut self, n: uint) {
+        self.scopes.push(self.mode);
+        self.copy(n);
+    }
+
+    fn emit(&mut self, s: &str) {
+        for c in s.chars() {
+            self.emit_char(c);
+        }
+    }
+
+    fn emit_char(&mut self, c: char) {
+        self.out.push_char(c);
+        self.origins.push(self.at);
+    }
+
+    // Writes a class with the items given. In a class that isn't negated,
+    // the items are written as is.
+    fn emit_class(&mut self, items: &str, negated: bool, class: bool) {
+        if class && !negated {
+            self.emit(items);
+            return
+        }
+        self.emit(if negated { "[^" } else { "[" });
+        self.emit(items);
+        self.emit("]");
+    }
+
+    // Writes a character so that it's always literal, in a class or not.
+    fn emit_literal(&mut self, c: char) {
+        if parse::is_punct(c) || "#&-~".contains_char(c) {
+            self.emit_char('\\');
+            self.emit_char(c);
+        } else if c.is_whitespace() {
+            self.emit("\\x{");
+            self.emit((c as u32).to_str_radix(16).as_slice());
+            self.emit("}");
+        } else {
+            self.emit_char(c);
+        }
+    }
+
+    // Writes the current character so that it's literal, and skips it.
+    fn emit_literal_at(&mut self, c: char) {
+        self.emit_literal(c);
+        self.i += 1;
+    }
+
+    // Writes the character whose code is given in the radix given.
+    fn emit_code(&mut self, start: uint, digits: &str, radix: uint)
+                -> Result<(), PcreError> {
+        let code = num::from_str_radix::<u32>(digits, radix);
+        match code.and_then(|n| char::from_u32(n)) {
+            None => self.err(start, PcreSyntax, format!(
+                "'{}' isn't the code of a character.", digits)),
+            Some(c) => {
+                self.emit("\\x{");
+                self.emit((c as u32).to_str_radix(16).as_slice());
+                self.emit("}");
+                Ok(())
+            }
+        }
+    }
+
+    fn cur(&self) -> char {
+        *self.chars.get(self.i)
+    }
+
+    // Returns the character `n` characters after the current one.
+    fn peek(&self, n: uint) -> Option<char> {
+        self.char_at(self.i + n)
+    }
+
+    fn char_at(&self, i: uint) -> Option<char> {
+        if i < self.chars.len() { Some(*self.chars.get(i)) } else { None }
+    }
+
+    fn starts_with(&self, s: &str) -> bool {
+        let s: Vec<char> = s.chars().collect();
+        self.i + s.len() <= self.chars.len()
+        && self.chars.slice(self.i, self.i + s.len()) == s.as_slice()
+    }
+
+    // Returns the position at or after `from` where `seq` starts.
+    fn find_seq(&self, from: uint, seq: &str) -> Option<uint> {
+        let seq: Vec<char> = seq.chars().collect();
+        let mut i = from;
+        while i + seq.len() <= self.chars.len() {
+            if self.chars.slice(i, i + seq.len()) == seq.as_slice() {
+                return Some(i)
+            }
+            i += 1;
+        }
+        None
+    }
+
+    // Returns the characters from `from` up to the first `close`, if
+    // there's one.
+    fn until(&self, from: uint, close: char) -> Option<~str> {
+        let mut s = StrBuf::new();
+        for i in range(from, self.chars.len()) {
+            let c = *self.chars.get(i);
+            if c == close {
+                return Some(s.into_owned())
+            }
+            s.push_char(c);
+        }
+        None
+    }
+
+    // Reads up to `max` digits in the radix given.
+    fn digits(&mut self, radix: uint, max: uint) -> ~str {
+        let mut s = StrBuf::new();
+        while s.len() < max {
+            match self.peek(0) {
+                Some(c) if c.is_digit_radix(radix) => {
+                    s.push_char(c);
+                    self.i += 1;
+                }
+                _ => break,
+            }
+        }
+        s.into_owned()
+    }
+
+    fn warn(&mut self, pos: uint, msg: &str) {
+        self.warnings.push(format!("Near position {}: {}", pos, msg));
+    }
+
+    fn err<T>(&self, pos: uint, kind: PcreErrorKind, msg: &str)
+             -> Result<T, PcreError> {
+        Err(PcreError { pos: pos, msg: msg.to_owned(), kind: kind })
+    }
+}
+
+// Returns true if `body` is the inside of a repetition, like `2`, `2,` or
+// `2,5`.
+fn is_repetition(body: &str) -> bool {
+    let mut parts = body.splitn(',', 1);
+    let min = parts.next().unwrap();
+    let max = parts.next().unwrap_or("");
+    !min.is_empty() && min.chars().all(|c| c.is_digit())
+    && max.chars().all(|c| c.is_digit())
+}
@@ -0,0 +1,319 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module finds the capture groups of a leftmost-longest match according
+// to the POSIX rules: of all the ways the expression can match the text of
+// the match, the one chosen is the one in which the first group is as far
+// left and then as long as possible, then the second group, and so on.
+// Groups are numbered by their opening parenthesis, so outer groups take
+// priority over the groups inside them and earlier groups over later ones.
+//
+// The NFA simulation can't do this, since it keeps one thread per
+// instruction and decides which one to keep by the priority of the paths
+// taken so far, while the POSIX choice depends on groups that may only be
+// closed later. So every path that matches is explored instead, one at a
+// time like the backtracker in backtrack.rs does, and the best set of groups
+// is kept.
+//
+// The bounds of the match are found first (by `vm::run_longest`), so only
+// paths that end exactly at the end of the match are considered. A pair of
+// instruction and position from which no path reaches the end is only
+// explored once. Even so, the number of paths that match can be exponential
+// in the length of the match (e.g., `(a|a)*` has two for every `a`), so this
+// is much slower than the other engines. Every instruction explored takes a
+// step from the budget. It also takes one from a fixed allowance, which is
+// proportional to the bound on the sets of pairs (see `max_bits`), so that a
+// search can't take exponential time even without a step limit. A match
+// whose groups take more steps than that to choose, or whose sets would be
+// larger than their bound, fails with `StepLimitExceeded`, like a search
+// that exceeds its step limit.
+//
+// A path that comes back to an instruction at the same position without
+// consuming anything (e.g., around the empty loop in `(a*)*`) is cut short,
+// which is the same as never repeating an empty iteration.
+
+use std::cmp;
+
+use backtrack;
+use compile::{
+    Program, Match, EmptyBegin, EmptyEnd, EmptyWordBoundary,
+    EmptySegmentBoundary, Save, Jump, Split,
+};
+use parse::FLAG_SEARCH;
+use vm;
+use vm::{CaptureLocs, Budget, StepLimitExceeded};
+
+/// Returns the capture groups, chosen by the POSIX rules, of the match of
+/// `prog` that starts at `start` and ends at `end`. `search` is where the
+/// search that found the match started (which is where `\G` matches).
+///
+/// The match must exist. The memory used is proportional to the number of
+/// instructions times the length of the match, and it's bounded by `limit`
+/// (see `max_bits`), as is the number of instructions explored.
+pub fn run<'r, 't, 'b>(prog: &'r Program, input: &'t str, search: uint,
+                       start: uint, end: uint, limit: uint,
+                       budget: &mut Budget<'b>)
+                  -> Result<CaptureLocs, StepLimitExceeded> {
+    let ncaps = prog.num_captures();
+    let bits = backtrack::visited_bits(prog.insts.len(), end - start);
+    let limit = max_bits(limit);
+    if bits > limit {
+        return Err(StepLimitExceeded)
+    }
+    let mut p = Posix {
+        prog: prog,
+        input: input,
+        search: search,
+        start: start,
+        end: end,
+        caps: Vec::from_elem(ncaps * 2, None),
+        best: None,
+        jobs: Vec::with_capacity(10),
+        dead: Vec::from_elem((bits + 31) / 32, 0u32),
+        on_path: Vec::from_elem((bits + 31) / 32, 0u32),
+        found: 0,
+        cuts: 0,
+        steps: STEPS_PER_BIT * limit,
+        budget: *budget,
+    };
+    let caps = p.run();
+    *budget = p.budget;
+    caps
+}
+
+// The number of instructions that may be explored for each bit that the sets
+// of pairs may take.
+static STEPS_PER_BIT: uint = 4;
+
+// Returns the most bits that each set of pairs of a search may take, given
+// the backtrack limit of the regex (like `backtrack::visited_limit`).
+fn max_bits(backtrack_limit: uint) -> uint {
+    cmp::max(backtrack_limit, backtrack::WINDOW_LIMIT)
+}
+
+// A unit of work: continue from an instruction at a position, undo a change
+// to a capture slot, or finish with an instruction at a position once every
+// path from it has been explored. The counts of matches found and of paths
+// cut short when the instruction was entered tell whether it's dead.
+enum Job {
+    Inst(uint, uint),
+    RestoreCapture(uint, Option<uint>),
+    Leave(uint, uint, uint, uint),
+}
+
+struct Posix<'r, 't, 'b> {
+    prog: &'r Program,
+    input: &'t str,
+    search: uint,
+    start: uint,
+    end: uint,
+    caps: CaptureLocs,
+    // The best groups found so far.
+    best: Option<CaptureLocs>,
+    jobs: Vec<Job>,
+    // The pairs from which no path reaches the end of the match.
+    dead: Vec<u32>,
+    // The pairs on the path being explored.
+    on_path: Vec<u32>,
+    // The number of paths that matched.
+    found: uint,
+    // The number of paths cut short because they came back to a pair on the
+    // path.
+    cuts: uint,
+    // The number of instructions that may still be explored.
+    steps: uint,
+    budget: Budget<'b>,
+}
+
+impl<'r, 't, 'b> Posix<'r, 't, 'b> {
+    fn run(&mut self) -> Result<CaptureLocs, StepLimitExceeded> {
+        self.jobs.push(Inst(0, self.start));
+        loop {
+            match self.jobs.pop() {
+                None => break,
+                Some(RestoreCapture(slot, old)) => {
+                    *self.caps.get_mut(slot) = old
+                }
+                Some(Leave(pc, ic, found, cuts)) => {
+                    let k = self.key(pc, ic);
+                    clear(&mut self.on_path, k);
+                    if self.found == found && self.cuts == cuts {
+                        set(&mut self.dead, k);
+                    }
+                }
+                Some(Inst(pc, ic)) => {
+                    if self.steps == 0 || !self.budget.take(1) {
+                        return Err(StepLimitExceeded)
+                    }
+                    self.steps -= 1;
+                    self.step(pc, ic)
+                }
+            }
+        }
+        Ok(match self.best.take() {
+            Some(best) => best,
+            None => Vec::from_elem(self.caps.len(), None),
+        })
+    }
+
+    // Explores the instruction `pc` at position `ic`, pushing the jobs that
+    // continue every path from it.
+    fn step(&mut self, pc: uint, ic: uint) {
+        let k = self.key(pc, ic);
+        if test(&self.dead, k) {
+            return
+        }
+        if test(&self.on_path, k) {
+            self.cuts += 1;
+            return
+        }
+        let prog = self.prog;
+        let next = match *prog.insts.get(pc) {
+            Match => {
+                if ic == self.end {
+                    self.found += 1;
+                    let better = match self.best {
+                        None => true,
+                        Some(ref best) => {
+                            is_better(self.caps.as_slice(), best.as_slice())
+                        }
+                    };
+                    if better {
+                        self.best = Some(self.caps.clone());
+                    }
+                } else {
+                    set(&mut self.dead, k);
+                }
+                return
+            }
+            Save(slot) => {
+                set(&mut self.on_path, k);
+                self.jobs.push(Leave(pc, ic, self.found, self.cuts));
+                let old = *self.caps.get(slot);
+                self.jobs.push(RestoreCapture(slot, old));
+                *self.caps.get_mut(slot) = Some(ic);
+                self.jobs.push(Inst(pc + 1, ic));
+                return
+            }
+            Split(x, y) => {
+                set(&mut self.on_path, k);
+                self.jobs.push(Leave(pc, ic, self.found, self.cuts));
+                self.jobs.push(Inst(y, ic));
+                self.jobs.push(Inst(x, ic));
+                return
+            }
+            Jump(to) => Some((to, ic)),
+            EmptyBegin(flags) if flags & FLAG_SEARCH > 0 => {
+                if ic == self.search { Some((pc + 1, ic)) } else { None }
+            }
+            EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_) => {
+                let (prev, cur) = (self.prev(ic), self.cur(ic));
+                let last = ic + 1 == self.input.len();
+                if vm::empty_matches(prog.insts.get(pc), prev, cur, last) {
+                    Some((pc + 1, ic))
+                } else {
+                    None
+                }
+            }
+            EmptySegmentBoundary(seg, flags) => {
+                if vm::segment_matches(seg, flags, self.input, ic) {
+                    Some((pc + 1, ic))
+                } else {
+                    None
+                }
+            }
+            ref inst => {
+                if ic >= self.end {
+                    None
+                } else {
+                    let next = self.input.char_range_at(ic);
+                    if vm::char_matches(inst, Some(next.ch)) {
+                        Some((pc + 1, next.next))
+                    } else {
+                        None
+                    }
+                }
+            }
+        };
+        match next {
+            None => set(&mut self.dead, k),
+            Some((to, at)) => {
+                set(&mut self.on_path, k);
+                self.jobs.push(Leave(pc, ic, self.found, self.cuts));
+                self.jobs.push(Inst(to, at));
+            }
+        }
+    }
+
+    #[inline]
+    fn key(&self, pc: uint, ic: uint) -> uint {
+        pc * (self.end - self.start + 1) + (ic - self.start)
+    }
+
+    // The character preceding `ic`, if any.
+    #[inline]
+    fn prev(&self, ic: uint) -> Option<char> {
+        if ic == 0 {
+            None
+        } else {
+            Some(self.input.char_range_at_reverse(ic).ch)
+        }
+    }
+
+    // The character at `ic`, if any.
+    #[inline]
+    fn cur(&self, ic: uint) -> Option<char> {
+        if ic < self.input.len() {
+            Some(self.input.char_at(ic))
+        } else {
+            None
+        }
+    }
+}
+
+// Returns true if the groups `caps` are preferred to `best` by the POSIX
+// rules. The groups are compared in order: one that took part in the match
+// beats one that didn't, then the one that starts first wins, then the one
+// that ends last.
+fn is_better(caps: &[Option<uint>], best: &[Option<uint>]) -> bool {
+    let mut i = 2;
+    while i + 1 < caps.len() {
+        match ((caps[i], caps[i + 1]), (best[i], best[i + 1])) {
+            ((Some(s), Some(e)), (Some(bs), Some(be))) => {
+                if s != bs {
+                    return s < bs
+                }
+                if e != be {
+                    return e > be
+                }
+            }
+            ((Some(_), Some(_)), _) => return true,
+            (_, (Some(_), Some(_))) => return false,
+            _ => {}
+        }
+        i += 2;
+    }
+    false
+}
+
+#[inline]
+fn test(bits: &Vec<u32>, k: uint) -> bool {
+    *bits.get(k / 32) & (1u32 << (k % 32)) > 0
+}
+
+#[inline]
+fn set(bits: &mut Vec<u32>, k: uint) {
+    *bits.get_mut(k / 32) |= 1u32 << (k % 32);
+}
+
+#[inline]
+fn clear(bits: &mut Vec<u32>, k: uint) {
+    *bits.get_mut(k / 32) &= !(1u32 << (k % 32));
+}
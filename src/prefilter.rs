@@ -0,0 +1,104 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module lets the caller tell a regex where matches may start, with a
+// `Prefilter` of their own (e.g., an index of the text, or a scanner that
+// knows more about it than the regex does). A search asks the prefilter for
+// the next candidate at or after where it is, and then runs an anchored
+// search there. When that finds nothing, it asks for the next candidate
+// after it, and so on. The text between candidates is never searched, which
+// is why a prefilter must never skip the start of a match.
+//
+// The anchored search at a candidate finds exactly the match that an
+// unanchored search would, since a leftmost-first match is the one that the
+// highest priority thread starting at its start position finds. That isn't
+// true when the start of a match is moved by `\K`, or when matches depend on
+// where the search started (for `\G`), so the prefilter isn't used then.
+
+use std::str::CharRange;
+
+/// A prefilter finds the positions in a text where a match may start, to
+/// spare a regex from searching the text in between (see
+/// `Regex::set_prefilter`).
+///
+/// A prefilter may report positions where no match starts (they're
+/// verified by the regex), but must never skip a position where a match
+/// starts. Otherwise matches are missed.
+///
+/// # Example
+///
+/// Only search the lines of a text that begin with `#`:
+///
+/// ```rust
+/// use regex::{Regex, Prefilter};
+///
+/// struct Comments;
+///
+/// impl Prefilter for Comments {
+///     fn next_candidate(&self, text: &[u8], from: uint) -> Option<uint> {
+///         range(from, text.len()).find(|&i| {
+///             text[i] == '#' as u8 && (i == 0 || text[i - 1] == '\n' as u8)
+///         })
+///     }
+/// }
+///
+/// let mut re = Regex::new(r"(?m)^#\s*(\w+)").unwrap();
+/// re.set_prefilter(~Comments);
+/// let caps = re.captures("x = 1 # no\n# yes").unwrap();
+/// assert_eq!(caps.at(1), "yes");
+/// ```
+pub trait Prefilter {
+    /// Returns the first position at or after `from` where a match may
+    /// start in `text`, or `None` if no match starts after `from`. The
+    /// position may be the length of `text` (where an empty match could
+    /// be).
+    ///
+    /// A position before `from` is a bug in the prefilter. The regex then
+    /// stops using it for the rest of the search, to find the right matches
+    /// anyway.
+    fn next_candidate(&self, text: &[u8], from: uint) -> Option<uint>;
+}
+
+/// Finds the leftmost match starting at or after `start` in `text`, by
+/// calling `anchored` with every candidate that `hook` gives, until one
+/// finds a match. A candidate that isn't at the start of a character is
+/// moved forward to the next one.
+///
+/// `None` is returned if `hook` gives a candidate before the position it
+/// was asked about, in which case the caller should search without it.
+pub fn find<T>(hook: &Prefilter, text: &str, start: uint,
+               anchored: |uint| -> Option<T>) -> Option<Option<T>> {
+    let mut from = start;
+    loop {
+        let at = match hook.next_candidate(text.as_bytes(), from) {
+            None => return Some(None),
+            Some(at) if at < from => return None,
+            Some(at) if at > text.len() => return Some(None),
+            Some(at) => ceil_char(text, at),
+        };
+        match anchored(at) {
+            Some(found) => return Some(Some(found)),
+            None if at >= text.len() => return Some(None),
+            None => {
+                let CharRange { next, .. } = text.char_range_at(at);
+                from = next;
+            }
+        }
+    }
+}
+
+// Returns the first character boundary in `text` at or after `i`.
+fn ceil_char(text: &str, i: uint) -> uint {
+    let mut i = i;
+    while !text.is_char_boundary(i) {
+        i += 1;
+    }
+    i
+}
@@ -9,14 +9,46 @@
 // except according to those terms.
 
 use collections::HashMap;
+use serialize::Decodable;
 use std::fmt;
-use std::from_str::from_str;
+use std::io::{IoResult, Reader, Writer};
+use std::rand::Rng;
+use std::str;
 use std::str::{MaybeOwned, Owned, Slice};
+use sync::{Arc, Mutex};
 
+use backtrack;
+use branch;
 use compile::Program;
+use dfa;
+use dfa::{DfaCache, DfaError, DfaUnsupported};
+use encode;
+use enumerate;
+use generate;
+use grep;
+use literals;
+use matcher;
+use memory;
+use meta::{Metadata, MetaCache};
+use parallel;
 use parse;
+use pcre;
+use prefilter;
+use scan;
+use stats::{Stats, EngineOnePass};
+use prefilter::Prefilter;
+use parse::{Ast, Begin, End, Lookaround, Atomic, Capture, Cat, Alt, Rep};
+use parse::{Nothing, Literal, Dot, Class, WordBoundary, SegmentBoundary};
+use parse::{Backref, Keep};
+use parse::{FLAG_EMPTY, FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL, FLAG_SEARCH};
+use parse::{FLAG_UWORD, FLAG_EXTENDED, FLAG_BEHIND, FLAG_FULLCASE};
+use render;
+use stream;
+use stream::{ReplaceWriter, Splitter};
+use template;
 use vm;
 use vm::{CaptureLocs, MatchKind, Exists, Location, Submatches};
+use vm::{StepLimitExceeded, Cancel, Cancelled};
 
 /// Escapes all regular expression meta characters in `text` so that it may be
 /// safely used in a regular expression as a literal string.
@@ -45,6 +77,128 @@ pub fn is_match(regex: &str, text: &str) -> Result<bool, parse::Error> {
     Regex::new(regex).map(|r| r.is_match(text))
 }
 
+/// Returns the canonical form of the regular expression given (see
+/// `Regex::canonical`).
+///
+/// If there was a problem parsing the regular expression, an error is
+/// returned.
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::canonicalize;
+/// assert_eq!(canonicalize("(?:a)(?m:b)[cba]").unwrap(), ~"ab[a-c]");
+/// ```
+pub fn canonicalize(regex: &str) -> Result<~str, parse::Error> {
+    let ast = try!(parse::parse(regex));
+    // A tree parsed from an expression can always be rendered.
+    Ok(render::render(&*ast).unwrap())
+}
+
+/// StartAnchor controls where `^` and `\A` may match when a search starts
+/// at an offset into the text (e.g., with `Regex::find_at_with`).
+#[deriving(Clone, Eq, Show)]
+pub enum StartAnchor {
+    /// `^` and `\A` only match at the beginning of the text (and `^` at
+    /// the beginning of every line, in multi-line mode). This is what
+    /// `find_at` does.
+    StartAnchorAtBeginning,
+    /// `^` and `\A` also match at the offset, as if the text began there.
+    /// This is what searching `text.slice_from(offset)` does.
+    StartAnchorAtOffset,
+}
+
+/// Options configures the compilation of a regex with `Regex::with_options`.
+/// The flags are set at the start of the expression, as if it began with a
+/// group like `(?ims)`, so flags set inside the expression change them as
+/// usual.
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::{Regex, Options};
+/// let opts = Options { case_insensitive: true, ..Options::new() };
+/// let re = Regex::with_options("a(?-i)b", opts).unwrap();
+/// assert!(re.is_match("Ab"));
+/// assert!(!re.is_match("AB"));
+/// ```
+#[deriving(Clone, Show)]
+pub struct Options {
+    /// Letters match both their lower and upper case forms (like `i`).
+    pub case_insensitive: bool,
+    /// Case insensitive literals use full case folding, so that `ß` matches
+    /// `ss` (like `f`). This has no effect unless `case_insensitive` is set
+    /// (or `i` is, inside the expression).
+    pub full_case_folding: bool,
+    /// `^` and `$` match at the beginning and end of lines (like `m`).
+    pub multi_line: bool,
+    /// `.` matches `\n` (like `s`).
+    pub dot_matches_new_line: bool,
+    /// `\b` and `\B` treat every Unicode word character (letters, marks,
+    /// numbers and connector punctuation) as a word character (like `w`).
+    pub unicode_word_boundary: bool,
+    /// Whitespace is ignored and `#` starts a comment (like `x`). This
+    /// doesn't apply to a `literal` expression.
+    pub ignore_whitespace: bool,
+    /// Searches find the leftmost-longest match instead of the
+    /// leftmost-first one. For example, `a|ab` finds `ab` in `abc`.
+    /// Finding the longest match requires the NFA simulation, so it's
+    /// slower.
+    pub longest: bool,
+    /// Searches find leftmost-longest matches (as if `longest` was set), and
+    /// their capture groups are chosen by the POSIX rules: of all the ways
+    /// the expression can match, the one chosen is the one in which the
+    /// first group starts as far left and is as long as possible, then the
+    /// second group, and so on. (Otherwise, the groups are the ones the
+    /// first alternative to find the match, in the order they're written,
+    /// found.) For example, `(a|ab)(c|bcd)(d*)` matches all of `abcd`
+    /// either way, but only the POSIX rules put `ab` in the first group.
+    ///
+    /// Choosing the groups this way explores every way the expression can
+    /// match the text of the match, which can take time exponential in its
+    /// length. So it takes at most a fixed number of steps and a bounded
+    /// amount of memory (two bits for each instruction at each position of
+    /// the match), which grow with the backtrack limit and are at least 32M
+    /// steps and 2MB. A match whose groups would take more fails like a search
+    /// that exceeds its step limit: it isn't found, and methods like
+    /// `try_captures` return `StepLimitExceeded`. Set a step limit to bound
+    /// the time taken further.
+    pub posix: bool,
+    /// The whole expression is a literal string (as if given to `quote`).
+    /// The other flags still apply to it.
+    pub literal: bool,
+    /// The limits the expression is held to (see `Regex::with_limits`).
+    pub limits: parse::CompileLimits,
+    /// See `Regex::set_dfa_size_limit`.
+    pub dfa_size_limit: uint,
+    /// See `Regex::set_backtrack_limit`.
+    pub backtrack_limit: uint,
+    /// See `Regex::set_step_limit`.
+    pub step_limit: Option<uint>,
+}
+
+impl Options {
+    /// Returns the options `Regex::new` compiles with: no flags set and the
+    /// default limits.
+    pub fn new() -> Options {
+        Options {
+            case_insensitive: false,
+            full_case_folding: false,
+            multi_line: false,
+            dot_matches_new_line: false,
+            unicode_word_boundary: false,
+            ignore_whitespace: false,
+            longest: false,
+            posix: false,
+            literal: false,
+            limits: parse::CompileLimits::new(),
+            dfa_size_limit: dfa::DEFAULT_SIZE_LIMIT,
+            backtrack_limit: backtrack::DEFAULT_LIMIT,
+            step_limit: None,
+        }
+    }
+}
+
 /// Regex is a compiled regular expression, represented as either a sequence
 /// of bytecode instructions (dynamic) or as a specialized Rust function
 /// (native). It can be used to search, split
@@ -98,6 +252,14 @@ pub fn is_match(regex: &str, text: &str) -> Result<bool, parse::Error> {
 /// makes it much faster when searching text.
 /// More details about the `regex!` macro can be found in the `regex` crate
 /// documentation.
+///
+/// Cloning a regex is cheap. The clone shares the compiled programs (along
+/// with their prefilters) with the original, and only copies its expression,
+/// the names of its groups and its configuration. So a regex can be compiled
+/// once and cloned for each worker that needs different limits (e.g.,
+/// `set_step_limit`): changing them on a clone never affects the original,
+/// or other clones. A clone also starts with DFAs of its own, so that its
+/// searches (whatever its limits) never use states computed for another.
 #[deriving(Clone)]
 #[allow(visible_private_types)]
 pub struct Regex {
@@ -112,6 +274,20 @@ pub struct Regex {
     pub names: ~[Option<~str>],
     #[doc(hidden)]
     pub p: MaybeNative,
+    #[doc(hidden)]
+    pub dfa: DfaCache,
+    #[doc(hidden)]
+    pub full: LazyRegex,
+    #[doc(hidden)]
+    pub offset: LazyRegex,
+    #[doc(hidden)]
+    pub longest: LazyRegex,
+    #[doc(hidden)]
+    pub meta: MetaCache,
+    #[doc(hidden)]
+    pub program: LazyProgram,
+    #[doc(hidden)]
+    pub ast: LazySyntax,
 }
 
 impl fmt::Show for Regex {
@@ -122,8 +298,8 @@ impl fmt::Show for Regex {
 }
 
 pub enum MaybeNative {
-    Dynamic(Program),
-    Native(fn(MatchKind, &str, uint, uint) -> Vec<Option<uint>>),
+    Dynamic(Arc<Program>),
+    Native(fn(MatchKind, &str, uint, uint, bool) -> Vec<Option<uint>>),
 }
 
 impl Clone for MaybeNative {
@@ -144,269 +320,2163 @@ impl Regex {
     ///
     /// If an invalid expression is given, then an error is returned.
     pub fn new(re: &str) -> Result<Regex, parse::Error> {
-        let ast = try!(parse::parse(re));
-        let (prog, names) = Program::new(ast);
-        Ok(Regex { original: re.to_owned(), names: names, p: Dynamic(prog) })
+        Regex::with_limits(re, parse::CompileLimits::new())
     }
 
-    /// Returns true if and only if the regex matches the string given.
+    /// Compiles a dynamic regular expression like `new`, except that an
+    /// error is returned if it exceeds the limits given. The `kind` of the
+    /// error says which limit was exceeded (`ProgramTooLarge`,
+    /// `NestingTooDeep` or `BackrefsForbidden`), and its position says
+    /// where.
     ///
     /// # Example
     ///
-    /// Test if some text contains at least one word with exactly 13
-    /// characters:
-    ///
     /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let text = "I categorically deny having triskaidekaphobia.";
-    /// let matched = regex!(r"\b\w{13}\b").is_match(text);
-    /// assert!(matched);
-    /// # }
+    /// # use regex::{Regex, CompileLimits, ProgramTooLarge};
+    /// let limits = CompileLimits { max_insts: 1000, ..CompileLimits::new() };
+    /// assert!(Regex::with_limits(r"\w{10}", limits).is_ok());
+    /// let err = Regex::with_limits(r"(a|b){1000}{1000}", limits);
+    /// assert_eq!(err.unwrap_err().kind, ProgramTooLarge);
     /// ```
-    pub fn is_match(&self, text: &str) -> bool {
-        has_match(&exec(self, Exists, text))
+    pub fn with_limits(re: &str, limits: parse::CompileLimits)
+                      -> Result<Regex, parse::Error> {
+        let alts = try!(parse::parse_alternates(re, FLAG_EMPTY, limits));
+        Ok(from_alternates(re.to_owned(), alts))
     }
 
-    /// Returns the start and end byte range of the leftmost-first match in
-    /// `text`. If no match exists, then `None` is returned.
+    /// Compiles a PCRE pattern, by translating it to an expression that
+    /// matches the same text in the same way (see `translate_pcre`). The
+    /// regex is returned along with warnings about the parts of the pattern
+    /// whose meaning depends on the version of PCRE.
     ///
-    /// Note that this should only be used if you want to discover the position
-    /// of the match. Testing the existence of a match is faster if you use
-    /// `is_match`.
+    /// The translation rewrites what's spelled differently here (like
+    /// `(?<name>...)`, `\g{-1}` or `\Q...\E`) and what's spelled the same
+    /// but means something else (like `$`, which PCRE also matches before a
+    /// new line at the end, as `\Z` does, or `\d`, `\w`, `\s` and `\b`, which
+    /// are ASCII only unless the pattern starts with `(*UCP)`). Features
+    /// that have no translation, like recursion, conditional groups,
+    /// backtracking control verbs or backreferences past the ninth group,
+    /// are errors whose `kind` says which one was found, and whose position
+    /// says where.
     ///
     /// # Example
     ///
-    /// Find the start and end location of every word with exactly 13
-    /// characters:
-    ///
     /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let text = "I categorically deny having triskaidekaphobia.";
-    /// let pos = regex!(r"\b\w{13}\b").find(text);
-    /// assert_eq!(pos, Some((2, 15)));
-    /// # }
+    /// # use regex::{Regex, PcreRecursion};
+    /// let (re, _) = Regex::from_pcre(r"^(?<word>\w+)\s+\k<word>$").unwrap();
+    /// assert!(re.is_match("the the\n"));
+    /// assert!(!re.is_match("δ δ"));
+    ///
+    /// let err = Regex::from_pcre(r"\((?:[^()]|(?R))*\)").unwrap_err();
+    /// assert_eq!((err.kind, err.pos), (PcreRecursion, 11));
     /// ```
-    pub fn find(&self, text: &str) -> Option<(uint, uint)> {
-        let caps = exec(self, Location, text);
-        if has_match(&caps) {
-            Some((caps.get(0).unwrap(), caps.get(1).unwrap()))
-        } else {
-            None
-        }
+    pub fn from_pcre(pattern: &str)
+                    -> Result<(Regex, Vec<~str>), pcre::PcreError> {
+        pcre::compile(pattern)
     }
 
-    /// Returns an iterator for each successive non-overlapping match in
-    /// `text`, returning the start and end byte indices with respect to
-    /// `text`.
+    /// Compiles a dynamic regular expression with the options given, which
+    /// saves prepending flags like `(?i)` (or calling `quote`) when the
+    /// expression comes from elsewhere.
     ///
-    /// # Example
+    /// The regex shows itself (e.g., with `format!("{}", re)`) as the
+    /// expression it's equivalent to, with the flags prepended. For example,
+    /// `abc` compiled case insensitively shows itself as `(?i)abc`.
     ///
-    /// Find the start and end location of the first word with exactly 13
-    /// characters:
+    /// # Example
     ///
     /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let text = "Retroactively relinquishing remunerations is reprehensible.";
-    /// for pos in regex!(r"\b\w{13}\b").find_iter(text) {
-    ///     println!("{}", pos);
-    /// }
-    /// // Output:
-    /// // (0, 13)
-    /// // (14, 27)
-    /// // (28, 41)
-    /// // (45, 58)
-    /// # }
+    /// # use regex::{Regex, Options};
+    /// let opts = Options { literal: true, ..Options::new() };
+    /// let re = Regex::with_options("a.b", opts).unwrap();
+    /// assert_eq!(re.find("axb a.b"), Some((4, 7)));
+    ///
+    /// let opts = Options { longest: true, ..Options::new() };
+    /// let re = Regex::with_options("a|ab", opts).unwrap();
+    /// assert_eq!(re.find("abc"), Some((0, 2)));
     /// ```
-    pub fn find_iter<'r, 't>(&'r self, text: &'t str) -> FindMatches<'r, 't> {
-        FindMatches {
-            re: self,
-            search: text,
-            last_end: 0,
-            last_match: None,
+    pub fn with_options(re: &str, opts: Options)
+                       -> Result<Regex, parse::Error> {
+        let mut flags = FLAG_EMPTY;
+        let mut names = StrBuf::new();
+        for &(yes, flag, name) in [(opts.case_insensitive, FLAG_NOCASE, 'i'),
+                                   (opts.full_case_folding, FLAG_FULLCASE,
+                                    'f'),
+                                   (opts.multi_line, FLAG_MULTI, 'm'),
+                                   (opts.dot_matches_new_line, FLAG_DOTNL,
+                                    's'),
+                                   (opts.unicode_word_boundary, FLAG_UWORD,
+                                    'w'),
+                                   (opts.ignore_whitespace && !opts.literal,
+                                    FLAG_EXTENDED, 'x')].iter() {
+            if yes {
+                flags = flags | flag;
+                names.push_char(name);
+            }
         }
+        let expr = if opts.literal { quote(re) } else { re.to_owned() };
+        let alts = try!(parse::parse_alternates(expr.as_slice(), flags,
+                                                opts.limits));
+        // The flags are prepended so that the expression shown by the regex
+        // is the one it matches.
+        let original = if names.len() == 0 {
+            expr
+        } else {
+            format!("(?{}){}", names, expr)
+        };
+        let mut re = from_alternates(original, alts);
+        re.dfa.set_size_limit(opts.dfa_size_limit);
+        re.dfa.set_backtrack_limit(opts.backtrack_limit);
+        re.dfa.set_step_limit(opts.step_limit);
+        re.dfa.set_longest(opts.longest || opts.posix);
+        re.dfa.set_posix(opts.posix);
+        Ok(re)
     }
 
-    /// Returns the capture groups corresponding to the leftmost-first
-    /// match in `text`. Capture group `0` always corresponds to the entire
-    /// match. If no match is found, then `None` is returned.
-    ///
-    /// You should only use `captures` if you need access to submatches.
-    /// Otherwise, `find` is faster for discovering the location of the overall
-    /// match.
-    ///
-    /// # Examples
+    /// Compiles a regex from a syntax tree (see the `syntax` module), which
+    /// may have been built by hand or changed from one returned by `syntax`.
+    /// Leftmost-longest matches are found if `longest` is true, as with
+    /// `Options::longest`.
     ///
-    /// Say you have some text with movie names and their release years,
-    /// like "'Citizen Kane' (1941)". It'd be nice if we could search for text
-    /// looking like that, while also extracting the movie name and its release
-    /// year separately.
+    /// The tree is rendered as an expression, with whatever escapes and
+    /// flags it needs, and compiled like any other. So the regex matches
+    /// exactly like one compiled from that expression, which is what it
+    /// shows itself as. Capture groups are numbered in the order they
+    /// appear, whatever the indices in the tree, and a backreference refers
+    /// to the group with the index it gives.
     ///
-    /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let re = regex!(r"'([^']+)'\s+\((\d{4})\)");
-    /// let text = "Not my favorite movie: 'Citizen Kane' (1941).";
-    /// let caps = re.captures(text).unwrap();
-    /// assert_eq!(caps.at(1), "Citizen Kane");
-    /// assert_eq!(caps.at(2), "1941");
-    /// assert_eq!(caps.at(0), "'Citizen Kane' (1941)");
-    /// # }
-    /// ```
+    /// An error is returned for a tree that no expression parses to, e.g.,
+    /// one that repeats `^`, or that refers to a group that isn't in it. Its
+    /// position is in the rendered expression.
     ///
-    /// Note that the full match is at capture group `0`. Each subsequent
-    /// capture group is indexed by the order of its opening `(`.
+    /// There's no way to compile a regex from a program built by hand, but
+    /// a program encoded by `to_bytes` is checked by `from_bytes` before
+    /// it's used.
     ///
-    /// We can make this example a bit clearer by using *named* capture groups:
+    /// # Example
     ///
     /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let re = regex!(r"'(?P<title>[^']+)'\s+\((?P<year>\d{4})\)");
-    /// let text = "Not my favorite movie: 'Citizen Kane' (1941).";
-    /// let caps = re.captures(text).unwrap();
-    /// assert_eq!(caps.name("title"), "Citizen Kane");
-    /// assert_eq!(caps.name("year"), "1941");
-    /// assert_eq!(caps.at(0), "'Citizen Kane' (1941)");
-    /// # }
+    /// # use regex::Regex;
+    /// # use regex::syntax::{Cat, Literal, Dot, Rep, ZeroMore, Greedy};
+    /// # use regex::syntax::FLAG_EMPTY;
+    /// let any = ~Rep(~Dot(FLAG_EMPTY), ZeroMore, Greedy);
+    /// let ast = Cat(vec!(any, ~Literal('.', FLAG_EMPTY)));
+    /// let re = Regex::from_syntax(&ast, false).unwrap();
+    /// assert_eq!(format!("{}", re), ~r".*\.");
+    /// assert_eq!(re.find("a.b.c"), Some((0, 4)));
     /// ```
+    pub fn from_syntax(ast: &Ast, longest: bool)
+                      -> Result<Regex, parse::Error> {
+        let expr = try!(render::render(ast));
+        let mut re = try!(Regex::new(expr.as_slice()));
+        re.dfa.set_longest(longest);
+        Ok(re)
+    }
+
+    /// Returns the canonical form of this regex: the expression that its
+    /// syntax tree renders as (see `from_syntax`). It compiles to a regex
+    /// that matches exactly like this one, and whose canonical form is the
+    /// same, so regexes that are written differently but parse to the same
+    /// tree have the same canonical form.
     ///
-    /// Here we name the capture groups, which we can access with the `name`
-    /// method. Note that the named capture groups are still accessible with
-    /// `at`.
+    /// Counted repetitions are expanded, classes are shown as sorted ranges,
+    /// groups that don't capture are only kept where they're needed, and
+    /// flags are only set where they change how something matches.
+    /// Whether matches are leftmost-longest isn't part of it.
+    pub fn canonical(&self) -> ~str {
+        // The tree was parsed from an expression, so it can be rendered.
+        render::render(&*self.syntax()).unwrap()
+    }
+
+    /// Returns the name of every capture group, indexed by group. The first
+    /// one, for the whole match, is always `None`, as is the name of every
+    /// group without one. When groups in different branches share a name,
+    /// it's listed for each of them.
+    pub fn capture_names<'a>(&'a self) -> &'a [Option<~str>] {
+        self.names.as_slice()
+    }
+
+    /// Returns the number of bytes that the lazy DFA used by this regex may
+    /// occupy before searches fall back to the (slower) NFA simulation.
+    pub fn dfa_size_limit(&self) -> uint {
+        self.dfa.size_limit()
+    }
+
+    /// Sets the number of bytes that the lazy DFA used by this regex may
+    /// occupy. When a search exceeds this budget, the states computed so far
+    /// are thrown away and the search is completed by the NFA simulation.
+    /// A limit of `0` disables the DFA entirely, along with the bit-parallel
+    /// engine used for tiny expressions.
     ///
-    /// The `0`th capture group is always unnamed, so it must always be
-    /// accessed with `at(0)`.
-    pub fn captures<'t>(&self, text: &'t str) -> Option<Captures<'t>> {
-        let caps = exec(self, Submatches, text);
-        Captures::new(self, text, caps)
+    /// The DFA is only used to answer `is_match` and to find the bounds of
+    /// matches (e.g., `find` and `find_iter`). When capture groups are
+    /// requested, it finds the bounds of the match first, and then only the
+    /// text of the match is searched for its groups (except for
+    /// leftmost-longest matches). It isn't used by regexes compiled with the
+    /// `regex!` macro.
+    ///
+    /// The limit applies to each DFA on its own: the one that scans forward
+    /// for the end of a match, the one that scans backward for its start
+    /// and those of the regexes derived for `is_full_match` and
+    /// `find_at_with` (once they've been used). Use `dfa_size` to see how
+    /// much memory they occupy.
+    pub fn set_dfa_size_limit(&mut self, limit: uint) {
+        self.dfa.set_size_limit(limit);
+        self.full = LazyRegex::new();
+        self.offset = LazyRegex::new();
+        self.longest = LazyRegex::new();
     }
 
-    /// Returns an iterator over all the non-overlapping capture groups matched
-    /// in `text`. This is operationally the same as `find_iter` (except it
-    /// yields information about submatches).
+    /// Returns the approximate number of bytes occupied by the states of
+    /// the lazy DFAs used by this regex (including the regexes derived from
+    /// it). It grows as searches compute new states, and shrinks when a DFA
+    /// exceeds its limit and throws its states away.
     ///
-    /// # Example
+    /// Searches that run at the same time (from different tasks) each use a
+    /// DFA of their own, which is kept for later searches, so a regex that's
+    /// shared by many tasks may occupy several times the limit. At most 8
+    /// DFAs are kept for each direction of search, so it's never more than
+    /// 16 times the limit. The DFAs of searches that are running aren't
+    /// counted.
     ///
-    /// We can use this to find all movie titles and their release years in
-    /// some text, where the movie is formatted like "'Title' (xxxx)":
+    /// # Example
     ///
     /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let re = regex!(r"'(?P<title>[^']+)'\s+\((?P<year>\d{4})\)");
-    /// let text = "'Citizen Kane' (1941), 'The Wizard of Oz' (1939), 'M' (1931).";
-    /// for caps in re.captures_iter(text) {
-    ///     println!("Movie: {}, Released: {}", caps.name("title"), caps.name("year"));
-    /// }
-    /// // Output:
-    /// // Movie: Citizen Kane, Released: 1941
-    /// // Movie: The Wizard of Oz, Released: 1939
-    /// // Movie: M, Released: 1931
-    /// # }
+    /// # use regex::Regex;
+    /// let mut re = Regex::new(r"(a|b)*a(a|b){10}").unwrap();
+    /// re.set_dfa_size_limit(1 << 16);
+    /// assert_eq!(re.dfa_size(), 0);
+    /// re.is_match("abbabaabbbababbbaaabab");
+    /// assert!(re.dfa_size() > 0 && re.dfa_size() <= 2 * (1 << 16));
     /// ```
-    pub fn captures_iter<'r, 't>(&'r self, text: &'t str)
-                                -> FindCaptures<'r, 't> {
-        FindCaptures {
-            re: self,
-            search: text,
-            last_match: None,
-            last_end: 0,
+    pub fn dfa_size(&self) -> uint {
+        let mut size = self.dfa.size();
+        for lazy in [&self.full, &self.offset, &self.longest].iter() {
+            match lazy.compiled() {
+                None => {}
+                Some(re) => size += re.dfa_size(),
+            }
         }
+        size
     }
 
-    /// Returns an iterator of substrings of `text` delimited by a match
-    /// of the regular expression.
-    /// Namely, each element of the iterator corresponds to text that *isn't*
-    /// matched by the regular expression.
+    /// Computes every state of the DFA used by this regex ahead of time, so
+    /// that searches look up each transition in a table instead of
+    /// computing (and caching) it on the fly. This costs time and memory up
+    /// front, but searches never miss the cache or fall back to the NFA
+    /// simulation, which pays off for a regex that's searched all the time.
     ///
-    /// This method will *not* copy the text given.
+    /// The tables have a column for each class of characters that the regex
+    /// can't tell apart, rather than one for each character, so they're
+    /// usually small. An error is returned if the tables of the DFA that
+    /// finds where matches end (or of the one that finds where they start)
+    /// would take more than `limit` bytes, or if searches with this regex
+    /// never use a DFA (e.g., it has backreferences, or it was compiled with
+    /// the `regex!` macro). The regex is unchanged when there's an error.
+    ///
+    /// Like the lazy DFA, the tables are only used to answer `is_match` and
+    /// to find the bounds of matches. They're thrown away by
+    /// `set_dfa_size_limit`, and they're shared by clones of the regex.
     ///
     /// # Example
     ///
-    /// To split a string delimited by arbitrary amounts of spaces or tabs:
+    /// ```rust
+    /// # use regex::{Regex, DfaTooLarge};
+    /// let mut re = Regex::new(r"(a|b)*a(a|b){3}").unwrap();
+    /// re.compile_dfa(1 << 20).unwrap();
+    /// assert!(re.full_dfa_size() > 0);
+    /// assert_eq!(re.find("bbabab"), Some((0, 6)));
+    ///
+    /// let mut re = Regex::new(r"(a|b)*a(a|b){20}").unwrap();
+    /// assert_eq!(re.compile_dfa(1 << 16).unwrap_err().kind, DfaTooLarge);
+    /// ```
+    pub fn compile_dfa(&mut self, limit: uint) -> Result<(), DfaError> {
+        match self.p {
+            Dynamic(ref prog) => self.dfa.compile_full(&**prog, limit),
+            Native(_) => Err(DfaError {
+                msg: ~"Regexes compiled with regex! have no DFA.",
+                kind: DfaUnsupported,
+            }),
+        }
+    }
+
+    /// Returns the number of bytes occupied by the tables computed by
+    /// `compile_dfa`, or `0` if there are none.
+    pub fn full_dfa_size(&self) -> uint {
+        self.dfa.full_size()
+    }
+
+    /// Returns the number of instructions this regex is compiled to. The
+    /// time that the NFA simulation takes for each character it searches,
+    /// and the memory most engines take, grow with it, so it's a measure of
+    /// what an expression costs that doesn't depend on its syntax.
+    ///
+    /// # Example
     ///
     /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let re = regex!(r"[ \t]+");
-    /// let fields: Vec<&str> = re.split("a b \t  c\td    e").collect();
-    /// assert_eq!(fields, vec!("a", "b", "c", "d", "e"));
-    /// # }
+    /// # use regex::Regex;
+    /// let small = Regex::new(r"a+").unwrap();
+    /// let large = Regex::new(r"(a+){100}").unwrap();
+    /// assert!(large.program_size() > 100 * small.program_size() / 2);
     /// ```
-    pub fn split<'r, 't>(&'r self, text: &'t str) -> RegexSplits<'r, 't> {
-        RegexSplits {
-            finder: self.find_iter(text),
-            last: 0,
+    pub fn program_size(&self) -> uint {
+        match self.p {
+            Dynamic(ref prog) => prog.insts.len(),
+            Native(_) => self.native_program().insts.len(),
         }
     }
 
-    /// Returns an iterator of at most `limit` substrings of `text` delimited
-    /// by a match of the regular expression. (A `limit` of `0` will return no
-    /// substrings.)
-    /// Namely, each element of the iterator corresponds to text that *isn't*
-    /// matched by the regular expression.
-    /// The remainder of the string that is not split will be the last element
-    /// in the iterator.
+    /// Returns an estimate of the memory that this regex occupies and that
+    /// searches with it allocate (see `MemoryEstimate`). The sizes are those
+    /// of what the regex allocated when it was compiled, so summing them
+    /// over many regexes tracks the memory they occupy (leaving out what the
+    /// allocator adds to each allocation, which isn't measured, nor are the
+    /// estimates checked against what's actually allocated). The regexes
+    /// derived from this one for methods like `is_full_match` aren't
+    /// counted, and neither are the states of its lazy DFAs other than by
+    /// their limit.
     ///
-    /// This method will *not* copy the text given.
+    /// A regex compiled with the `regex!` macro is part of the binary, so
+    /// only the memory of its searches is estimated.
     ///
     /// # Example
     ///
-    /// Get the first two words in some text:
-    ///
     /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let re = regex!(r"\W+");
-    /// let fields: Vec<&str> = re.splitn("Hey! How are you?", 3).collect();
-    /// assert_eq!(fields, vec!("Hey", "How", "are you?"));
-    /// # }
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"(\w+)@(\w+)\.com").unwrap();
+    /// let est = re.memory_estimate();
+    /// assert!(est.fixed() > 0);
+    /// assert!(est.per_search(1 << 20) >= est.threads);
     /// ```
-    pub fn splitn<'r, 't>(&'r self, text: &'t str, limit: uint)
-                         -> RegexSplitsN<'r, 't> {
-        RegexSplitsN {
-            splits: self.split(text),
-            cur: 0,
-            limit: limit,
+    pub fn memory_estimate(&self) -> memory::MemoryEstimate {
+        match self.p {
+            Dynamic(ref prog) => memory::estimate(&**prog, &self.dfa),
+            Native(_) => memory::estimate_static(&*self.native_program()),
         }
     }
 
-    /// Replaces the leftmost-first match with the replacement provided.
-    /// The replacement can be a regular string (where `$N` and `$name` are
-    /// expanded to match capture groups) or a function that takes the matches'
-    /// `Captures` and returns the replaced string.
+    // Returns the program that a native regex's expression compiles to,
+    // which is compiled once.
+    fn native_program(&self) -> Arc<Program> {
+        self.program.get(self.original.as_slice())
+    }
+
+    /// Returns the largest product of the number of instructions in this
+    /// regex and the length of the text searched for which the bounded
+    /// backtracker is used instead of the NFA simulation.
+    pub fn backtrack_limit(&self) -> uint {
+        self.dfa.backtrack_limit()
+    }
+
+    /// Sets the largest product of the number of instructions in this regex
+    /// and the length of the text searched for which the bounded
+    /// backtracker is used. The backtracker is typically faster than the
+    /// NFA simulation on short texts (such as a single line of a log file),
+    /// but it needs one bit of memory for every instruction at every
+    /// position. A limit of `0` disables the backtracker for regexes that
+    /// the NFA simulation can run.
     ///
-    /// If no match is found, then a copy of the string is returned unchanged.
+    /// Regexes that use lookaround, backreferences or `\K` are always
+    /// searched by the backtracker. Its memory is then bounded by the larger
+    /// of this limit and 8Mbits (1MB), a bit for each instruction at each
+    /// position that the search reaches. A search whose paths from a single
+    /// starting position reach further than that fails like one that
+    /// exceeds its step limit (see `try_find`).
     ///
-    /// # Examples
+    /// Like the DFA, the backtracker isn't used by regexes compiled with the
+    /// `regex!` macro.
+    pub fn set_backtrack_limit(&mut self, limit: uint) {
+        self.dfa.set_backtrack_limit(limit);
+        self.full = LazyRegex::new();
+        self.offset = LazyRegex::new();
+        self.longest = LazyRegex::new();
+    }
+
+    /// Returns the number of steps a single search with this regex may
+    /// take, or `None` if searches are unlimited (which is the default).
+    pub fn step_limit(&self) -> Option<uint> {
+        self.dfa.step_limit()
+    }
+
+    /// Sets the number of steps a single search with this regex may take.
+    /// A step is roughly one instruction of the regex simulated at one
+    /// position of the text (or one character consumed by the DFA), so the
+    /// time a search takes is bounded by the limit rather than by the size
+    /// of the regex times the length of the text. This is useful when
+    /// neither the regex nor the text can be trusted. `None` removes the
+    /// limit.
     ///
-    /// Note that this function is polymorphic with respect to the replacement.
-    /// In typical usage, this can just be a normal string:
+    /// A search that runs out of steps is abandoned. The `try_` methods
+    /// (like `try_find`) report this as an error, while every other method
+    /// behaves as if there was no match.
     ///
-    /// ```rust
-    /// # #![feature(phase)]
-    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
-    /// # fn main() {
-    /// let re = regex!("[^01]+");
-    /// assert_eq!(re.replace("1078910", "").as_slice(), "1010");
-    /// # }
-    /// ```
+    /// The limit isn't used by regexes compiled with the `regex!` macro.
     ///
-    /// But anything satisfying the `Replacer` trait will work. For example,
-    /// a closure of type `|&Captures| -> ~str` provides direct access to the
-    /// captures corresponding to a match. This allows one to access
-    /// submatches easily:
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let mut re = Regex::new(r"(a|b|ab)*c").unwrap();
+    /// re.set_step_limit(Some(100));
+    /// let text = "ab".repeat(1000) + "c";
+    /// assert!(re.try_is_match(text.as_slice()).is_err());
+    /// assert!(!re.is_match(text.as_slice()));
+    ///
+    /// re.set_step_limit(None);
+    /// assert!(re.is_match(text.as_slice()));
+    /// ```
+    pub fn set_step_limit(&mut self, limit: Option<uint>) {
+        self.dfa.set_step_limit(limit);
+        self.full = LazyRegex::new();
+        self.offset = LazyRegex::new();
+        self.longest = LazyRegex::new();
+    }
+
+    /// Sets a prefilter that finds where matches may start, so that searches
+    /// only look for matches at the positions it gives (see `Prefilter`),
+    /// instead of scanning all of the text. It replaces the prefilter that
+    /// the regex would use by itself, so it's only worth it when it knows
+    /// something about the text that the regex doesn't (e.g., from an
+    /// index). Clones of the regex share it.
+    ///
+    /// The prefilter is used by `is_match`, `find` and `captures` (and
+    /// every method built on them, like `find_iter` and `replace_all`),
+    /// except when they're given a `Cancel`. It isn't used for regexes that
+    /// use `\K` or `\G` (whose matches an anchored search can't find), or
+    /// for regexes compiled with the `regex!` macro.
+    pub fn set_prefilter(&mut self, hook: ~Prefilter:Send+Share) {
+        self.dfa.set_hook(Some(hook));
+        self.full = LazyRegex::new();
+        self.offset = LazyRegex::new();
+        self.longest = LazyRegex::new();
+    }
+
+    /// Removes the prefilter set with `set_prefilter`, if any.
+    pub fn remove_prefilter(&mut self) {
+        self.dfa.set_hook(None);
+        self.full = LazyRegex::new();
+        self.offset = LazyRegex::new();
+        self.longest = LazyRegex::new();
+    }
+
+    /// Starts counting what searches with this regex cost (see `Stats`),
+    /// from zero. Clones of the regex made afterwards share the counts.
+    ///
+    /// Counting costs a few atomic additions per search, so it's off by
+    /// default. Searches with regexes compiled with the `regex!` macro
+    /// aren't counted.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let mut re = Regex::new(r"[a-z]+\d").unwrap();
+    /// re.enable_stats();
+    /// assert!(re.is_match("abc1"));
+    /// assert!(!re.is_match("abc"));
+    /// let stats = re.take_stats().unwrap();
+    /// assert_eq!((stats.searches, stats.matches), (2, 1));
+    /// assert_eq!(stats.bytes, 7);
+    /// assert_eq!(re.stats().unwrap().searches, 0);
+    /// ```
+    pub fn enable_stats(&mut self) {
+        self.dfa.set_stats(true);
+        self.full = LazyRegex::new();
+        self.offset = LazyRegex::new();
+        self.longest = LazyRegex::new();
+    }
+
+    /// Stops counting searches with this regex.
+    pub fn disable_stats(&mut self) {
+        self.dfa.set_stats(false);
+        self.full = LazyRegex::new();
+        self.offset = LazyRegex::new();
+        self.longest = LazyRegex::new();
+    }
+
+    /// Returns the counts of what searches with this regex cost since
+    /// counting was enabled (or since they were last taken), or `None` if
+    /// they aren't counted.
+    pub fn stats(&self) -> Option<Stats> {
+        self.dfa.stats().map(|stats| stats.load())
+    }
+
+    /// Returns the counts like `stats` does, and starts counting from zero
+    /// again.
+    pub fn take_stats(&self) -> Option<Stats> {
+        self.dfa.stats().map(|stats| stats.take())
+    }
+
+    /// Returns a description of the literal optimization chosen for this
+    /// regex. This is useful for checking whether a pattern benefits from
+    /// scanning for literal prefixes (or for literals that must appear
+    /// elsewhere in every match). The format is meant for debugging and may
+    /// change.
+    ///
+    /// Regexes compiled with the `regex!` macro always report `none`.
+    pub fn explain_prefilter(&self) -> ~str {
+        match self.p {
+            Dynamic(ref prog) => format!("{}", prog.prefilter),
+            Native(_) => ~"none",
+        }
+    }
+
+    /// Returns the literals that the prefilter of this regex scans for, and
+    /// whether matches can only start at the beginning of the text. These
+    /// are the literals that the matching engines use, so a text that
+    /// contains none of them has no match (unless there are none at all).
+    ///
+    /// Regexes compiled with the `regex!` macro have no literals.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"^(foo|bar)\d").unwrap();
+    /// let info = re.prefilter_info();
+    /// assert_eq!(info.prefixes, vec!(Vec::from_slice(bytes!("foo")),
+    ///                                Vec::from_slice(bytes!("bar"))));
+    /// assert!(info.anchored && !info.exact);
+    /// let info = Regex::new(r"\w+@example\.com").unwrap().prefilter_info();
+    /// assert_eq!(info.inner, vec!(Vec::from_slice(bytes!("@example.com"))));
+    /// assert_eq!(info.inner_offset, None);
+    /// ```
+    pub fn prefilter_info(&self) -> literals::PrefilterInfo {
+        match self.p {
+            Dynamic(ref prog) => prog.prefilter.info(prog.anchored_start()),
+            Native(_) => literals::Prefilter::none().info(false),
+        }
+    }
+
+    /// Returns the literals that every match of this regex starts with (as the
+    /// prefilter finds them, so a long literal may be cut short), and
+    /// whether they match text in which ASCII letters are in either case
+    /// (in which case they're in lower case). If there are none, then `None`
+    /// is returned.
+    ///
+    /// Regexes compiled with the `regex!` macro always return `None`.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"(?i)error: \d+").unwrap();
+    /// assert_eq!(re.literal_prefixes(), Some((vec!(~"error: "), true)));
+    /// let re = Regex::new(r"warn(ing)?").unwrap();
+    /// assert_eq!(re.literal_prefixes(), Some((vec!(~"warn"), false)));
+    /// ```
+    pub fn literal_prefixes(&self) -> Option<(Vec<~str>, bool)> {
+        let info = self.prefilter_info();
+        if info.prefixes.len() == 0 {
+            return None
+        }
+        let lits = info.prefixes.iter().map(|lit| {
+            // A literal that was cut short may end in the middle of a
+            // character.
+            let lit = lit.as_slice();
+            let mut n = lit.len();
+            while !str::is_utf8(lit.slice_to(n)) {
+                n -= 1;
+            }
+            str::from_utf8(lit.slice_to(n)).unwrap().to_owned()
+        }).collect();
+        Some((lits, info.nocase))
+    }
+
+    /// Returns a summary of how searches with this regex are run: which
+    /// engine finds matches (e.g., `dfa`), which finds their capture groups
+    /// (e.g., `onepass`), the prefilter (as `explain_prefilter` describes
+    /// it) and whether matches can only start at the beginning of the text.
+    /// This answers most questions about why a search is slow. The format is
+    /// meant for debugging and may change.
+    ///
+    /// Regexes compiled with the `regex!` macro report `engine: native`.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"^(\w+)@(\w+)$").unwrap();
+    /// let summary = re.explain();
+    /// assert!(summary.contains("captures: onepass"));
+    /// assert!(summary.contains("anchored: yes"));
+    /// ```
+    pub fn explain(&self) -> ~str {
+        match self.p {
+            Dynamic(ref prog) => self.dfa.explain(&**prog),
+            Native(_) => ~"engine: native",
+        }
+    }
+
+    /// Returns the summary returned by `explain`, followed by a listing of
+    /// the instructions of the compiled program, one per line. The format
+    /// is meant for debugging and may change.
+    ///
+    /// A regex compiled with the `regex!` macro has no program to list, so
+    /// the program that its expression compiles to dynamically is listed.
+    pub fn dump_program(&self) -> ~str {
+        match self.p {
+            Dynamic(ref prog) => {
+                format!("{}\n{}", self.dfa.explain(&**prog), prog.dump())
+            }
+            Native(_) => {
+                format!("{}\n{}", self.explain(),
+                        self.dynamic().dump_program())
+            }
+        }
+    }
+
+    /// Searches for the leftmost-first match in `text` like `find`, and
+    /// writes a transcript of the search to `w`: every position that the
+    /// search steps over, how many threads are alive there, and every
+    /// thread created there and character consumed by one (with the
+    /// instruction listed by `dump_program`). The format is meant for
+    /// debugging and may change.
+    ///
+    /// The search is run by the NFA simulation, whichever engine would run
+    /// it otherwise, since that's the only engine whose threads can be
+    /// traced. Searches that only the backtracker can run (for regexes with
+    /// lookaround, backreferences or `\K`) aren't traced, which is noted
+    /// in the transcript. A regex compiled with the `regex!` macro is traced
+    /// as its expression compiled dynamically.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// use std::io::MemWriter;
+    ///
+    /// let re = Regex::new("ab").unwrap();
+    /// let mut w = MemWriter::new();
+    /// assert_eq!(re.trace("xab", &mut w).unwrap(), Some((1, 3)));
+    /// let transcript = std::str::from_utf8(w.get_ref()).unwrap().to_owned();
+    /// assert!(transcript.contains("char 'b': matched"));
+    /// ```
+    pub fn trace<W: Writer>(&self, text: &str, w: &mut W)
+                           -> IoResult<Option<(uint, uint)>> {
+        let prog = match self.p {
+            Dynamic(ref prog) => &**prog,
+            Native(_) => return self.dynamic().trace(text, w),
+        };
+        if prog.backtrack_only() {
+            try!(w.write_str("The search is run by the backtracker, which \
+                              isn't traced.\n"));
+            return Ok(self.find(text))
+        }
+        let (caps, transcript) = vm::trace(prog, text);
+        try!(w.write_str(transcript.as_slice()));
+        Ok(match (*caps.get(0), *caps.get(1)) {
+            (Some(s), Some(e)) => Some((s, e)),
+            _ => None,
+        })
+    }
+
+    // Returns a dynamic regex compiled from the expression of this one.
+    fn dynamic(&self) -> Regex {
+        let mut re = from_ast(self.original.clone(), (*self.tree()).clone());
+        re.dfa.set_longest(self.dfa.longest());
+        re.dfa.set_posix(self.dfa.posix());
+        re
+    }
+
+    /// Encodes the compiled regex to bytes, which `from_bytes` decodes to
+    /// an identical regex without parsing or compiling the expression
+    /// again. The size limit of the DFA and the other limits are encoded
+    /// too.
+    ///
+    /// A regex compiled with the `regex!` macro has no program to encode, so
+    /// its expression is compiled dynamically first. (The regex decoded is
+    /// dynamic.)
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let bytes = Regex::new(r"(\w+)@(\w+)").unwrap().to_bytes();
+    /// let re = Regex::from_bytes(bytes.as_slice()).unwrap();
+    /// assert_eq!(re.captures("me@home").unwrap().at(2), "home");
+    /// ```
+    pub fn to_bytes(&self) -> Vec<u8> {
+        encode::encode_regex(self)
+    }
+
+    /// Decodes a regex encoded by `to_bytes`.
+    ///
+    /// An error is returned if the bytes were encoded by a version of this
+    /// crate with an incompatible format (the error's kind is then
+    /// `UnsupportedVersion`), or if they aren't an encoded regex at all,
+    /// including when they've been corrupted (`InvalidEncoding`). The
+    /// program decoded is checked, so that no instruction can make a search
+    /// read outside of it.
+    pub fn from_bytes(bytes: &[u8]) -> Result<Regex, encode::DecodeError> {
+        encode::decode_regex(bytes)
+    }
+
+    /// Returns true if and only if the regex matches the string given.
+    ///
+    /// # Example
+    ///
+    /// Test if some text contains at least one word with exactly 13
+    /// characters:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let text = "I categorically deny having triskaidekaphobia.";
+    /// let matched = regex!(r"\b\w{13}\b").is_match(text);
+    /// assert!(matched);
+    /// # }
+    /// ```
+    pub fn is_match(&self, text: &str) -> bool {
+        match try_is_match_at(self, text, 0, None) {
+            Ok(found) => found,
+            Err(_) => false,
+        }
+    }
+
+    /// Returns true if and only if the regex matches the string given, like
+    /// `is_match`. If the search exceeds the step limit (see
+    /// `set_step_limit`), then an error is returned instead.
+    pub fn try_is_match(&self, text: &str) -> Result<bool, StepLimitExceeded> {
+        try_is_match_at(self, text, 0, None)
+    }
+
+    /// Returns the indices of the texts in `texts` that the regex matches
+    /// somewhere in, in order. This is the same as calling `is_match` on
+    /// each text, but faster when there are many short texts, since the
+    /// state of the lazy DFA is shared by all of their searches.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"^[a-z]+\.rs$");
+    /// let files = &["lib.rs", "Makefile", "re.rs", "re.rs~"];
+    /// assert_eq!(re.filter(files), vec!(0, 2));
+    /// # }
+    /// ```
+    pub fn filter(&self, texts: &[&str]) -> Vec<uint> {
+        let mut found = vec!();
+        filter(self, texts.iter().map(|&t| t), |i| { found.push(i); true });
+        found
+    }
+
+    /// Returns true if and only if the regex matches somewhere in one of
+    /// the texts in `texts`. Like `filter`, the texts share the state of
+    /// the lazy DFA, and no text after the first one that matches is
+    /// searched.
+    pub fn is_match_any(&self, texts: &[&str]) -> bool {
+        let mut found = false;
+        filter(self, texts.iter().map(|&t| t), |_| { found = true; false });
+        found
+    }
+
+    /// Returns true if and only if the regex matches all of `text`. This
+    /// is not the same as checking that `find` returns the bounds of `text`,
+    /// since the leftmost-first match may be shorter than a match of all of
+    /// `text`. (For example, `a|ab` matches all of `ab`, but its
+    /// leftmost-first match in `ab` is `a`.)
+    ///
+    /// The search is anchored at both ends of `text`, as if the expression
+    /// were wrapped in `(?-m:^(?:...)$)`. In multi-line mode, `^` and `$` in
+    /// the expression still match at the beginning and end of every line.
+    ///
+    /// The anchored program is compiled the first time it's needed, and
+    /// then kept for later searches. It's always executed by the dynamic
+    /// engines, even for regexes compiled with the `regex!` macro.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"[0-9]+|[0-9]+\.[0-9]+");
+    /// assert!(re.is_full_match("3.14"));
+    /// assert!(!re.is_full_match("3.14 "));
+    /// # }
+    /// ```
+    pub fn is_full_match(&self, text: &str) -> bool {
+        match try_is_match_at(&*self.full(), text, 0, None) {
+            Ok(found) => found,
+            Err(_) => false,
+        }
+    }
+
+    /// Returns the capture groups of a match of all of `text`, like
+    /// `is_full_match`. If the regex doesn't match all of `text`, then
+    /// `None` is returned.
+    pub fn full_captures<'t>(&self, text: &'t str) -> Option<Captures<'t>> {
+        let full = self.full();
+        Captures::new(&*full, text, exec(&*full, Submatches, text))
+    }
+
+    // Returns the regex that only matches all of a text.
+    fn full(&self) -> Arc<Regex> {
+        self.full.get(|| {
+            let ast = (*self.tree()).clone();
+            let ast = ~Cat(vec!(~Begin(FLAG_EMPTY), ast, ~End(FLAG_EMPTY)));
+            let mut full = from_ast(self.original.clone(), ast);
+            full.set_dfa_size_limit(self.dfa_size_limit());
+            full.set_backtrack_limit(self.backtrack_limit());
+            full.set_step_limit(self.step_limit());
+            full.dfa.set_longest(self.dfa.longest());
+            full.dfa.set_posix(self.dfa.posix());
+            full.dfa.share_stats(&self.dfa);
+            full
+        })
+    }
+
+    /// Returns the start and end byte range of the leftmost-first match in
+    /// `text`. If no match exists, then `None` is returned.
+    ///
+    /// Note that this should only be used if you want to discover the position
+    /// of the match. Testing the existence of a match is faster if you use
+    /// `is_match`.
+    ///
+    /// # Example
+    ///
+    /// Find the start and end location of every word with exactly 13
+    /// characters:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let text = "I categorically deny having triskaidekaphobia.";
+    /// let pos = regex!(r"\b\w{13}\b").find(text);
+    /// assert_eq!(pos, Some((2, 15)));
+    /// # }
+    /// ```
+    pub fn find(&self, text: &str) -> Option<(uint, uint)> {
+        find_at(self, text, 0)
+    }
+
+    /// Returns the start and end byte range of the leftmost-first match in
+    /// `text` that starts at or after the byte index `start`. If no match
+    /// exists, then `None` is returned.
+    ///
+    /// Unlike searching `text.slice_from(start)`, zero-width assertions like
+    /// `^`, `\b` and `\B` are evaluated with respect to all of `text`, and
+    /// the positions returned are byte indices into `text`. This is what a
+    /// search that resumes after a previous match needs. (Use `find_at_with`
+    /// to make `^` match at `start`.)
+    ///
+    /// `start` must be at a character boundary of `text` (or equal to its
+    /// length), otherwise this function fails.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\bfoo");
+    /// assert_eq!(re.find_at("xfoo foo", 1), Some((5, 8)));
+    /// assert_eq!(re.find("xfoo foo".slice_from(1)), Some((0, 3)));
+    /// # }
+    /// ```
+    pub fn find_at(&self, text: &str, start: uint) -> Option<(uint, uint)> {
+        check_start(text, start);
+        find_at(self, text, start)
+    }
+
+    /// Returns the start and end byte range of the leftmost-first match in
+    /// `text`, like `find`. If the search exceeds the step limit (see
+    /// `set_step_limit`), then an error is returned instead.
+    pub fn try_find(&self, text: &str)
+                   -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+        try_find_at(self, text, 0, None)
+    }
+
+    /// Returns the start and end byte range of the leftmost-first match in
+    /// `text` that starts at or after `start`, like `find_at`. If the search
+    /// exceeds the step limit (see `set_step_limit`), then an error is
+    /// returned instead.
+    pub fn try_find_at(&self, text: &str, start: uint)
+                      -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+        check_start(text, start);
+        try_find_at(self, text, start, None)
+    }
+
+    /// Returns the start and end byte range of the leftmost-first match in
+    /// `text` that starts at or after the byte index `start`, like
+    /// `find_at`. `anchor` says whether `^` and `\A` may match at `start`.
+    ///
+    /// With `StartAnchorAtOffset`, `^` and `\A` match at `start` just as
+    /// they would at the beginning of `text.slice_from(start)`. (In
+    /// multi-line mode, `^` still matches after every `\n` too.) Either
+    /// way, `\b` and `\B` look at the character before `start`, and the
+    /// positions returned are byte indices into `text`. Lookbehind sees the
+    /// text before `start` with `StartAnchorAtBeginning`, but not with
+    /// `StartAnchorAtOffset`.
+    ///
+    /// The program for `StartAnchorAtOffset` is compiled the first time
+    /// it's needed, and then kept for later searches. It's always executed
+    /// by the dynamic engines, even for regexes compiled with the `regex!`
+    /// macro.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # use regex::{StartAnchorAtBeginning, StartAnchorAtOffset};
+    /// # fn main() {
+    /// let re = regex!(r"^\w+");
+    /// let text = "key=value";
+    /// assert_eq!(re.find_at_with(text, 4, StartAnchorAtBeginning), None);
+    /// assert_eq!(re.find_at_with(text, 4, StartAnchorAtOffset),
+    ///            Some((4, 9)));
+    /// # }
+    /// ```
+    pub fn find_at_with(&self, text: &str, start: uint, anchor: StartAnchor)
+                       -> Option<(uint, uint)> {
+        match anchor {
+            StartAnchorAtBeginning => self.find_at(text, start),
+            StartAnchorAtOffset => self.offset().find_at(text, start),
+        }
+    }
+
+    /// Returns the capture groups corresponding to the leftmost-first match
+    /// in `text` that starts at or after the byte index `start`, like
+    /// `captures_at`. `anchor` says whether `^` and `\A` may match at
+    /// `start`, exactly as for `find_at_with`.
+    pub fn captures_at_with<'t>(&self, text: &'t str, start: uint,
+                                anchor: StartAnchor) -> Option<Captures<'t>> {
+        match anchor {
+            StartAnchorAtBeginning => self.captures_at(text, start),
+            StartAnchorAtOffset => self.offset().captures_at(text, start),
+        }
+    }
+
+    // Returns the regex in which `^` and `\A` also match where the search
+    // starts.
+    fn offset(&self) -> Arc<Regex> {
+        self.offset.get(|| {
+            let ast = (*self.tree()).clone();
+            let mut offset = from_ast(self.original.clone(),
+                                      begin_at_search(ast));
+            offset.set_dfa_size_limit(self.dfa_size_limit());
+            offset.set_backtrack_limit(self.backtrack_limit());
+            offset.set_step_limit(self.step_limit());
+            offset.dfa.set_longest(self.dfa.longest());
+            offset.dfa.set_posix(self.dfa.posix());
+            offset.dfa.share_stats(&self.dfa);
+            offset
+        })
+    }
+
+    /// Returns true if this regex finds leftmost-longest matches (see
+    /// `Options`) rather than leftmost-first ones.
+    pub fn is_longest(&self) -> bool {
+        self.dfa.longest()
+    }
+
+    /// Returns true if this regex finds leftmost-longest matches whose
+    /// capture groups follow the POSIX rules (see `Options`).
+    pub fn is_posix(&self) -> bool {
+        self.dfa.posix()
+    }
+
+    /// Returns the syntax tree that this regex was compiled from.
+    ///
+    /// Every node of the tree carries the flags in effect where it appears,
+    /// which include the flags given by `Options`. (They're part of the
+    /// expression shown by the regex, too.) Whether matches are
+    /// leftmost-longest isn't part of the tree, but is reported by
+    /// `is_longest` and `is_posix`.
+    ///
+    /// The tree is a copy, which may be changed freely without affecting the
+    /// regex.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// # use regex::syntax::{Literal, FLAG_NOCASE};
+    /// let re = Regex::new("(?i)a").unwrap();
+    /// match *re.syntax() {
+    ///     Literal(c, flags) => assert!(c == 'a' && flags & FLAG_NOCASE > 0),
+    ///     ref ast => fail!("unexpected syntax: {}", ast),
+    /// }
+    /// ```
+    pub fn syntax(&self) -> ~Ast {
+        (*self.tree()).clone()
+    }
+
+    // Returns the syntax tree that this regex was compiled from.
+    fn tree(&self) -> Arc<~Ast> {
+        self.ast.get(self.original.as_slice())
+    }
+
+    /// Returns true if this regex uses lookahead (`(?=...)` or `(?!...)`) or
+    /// lookbehind (`(?<=...)` or `(?<!...)`).
+    ///
+    /// Only the bounded backtracker can evaluate lookaround, so every search
+    /// for such a regex is run by it, whatever the length of the text and
+    /// the `backtrack_limit`. The matches found are the same as they would
+    /// be with any other engine, but searches of long texts are slower and
+    /// use more memory.
+    pub fn has_lookaround(&self) -> bool {
+        match self.p {
+            Dynamic(ref prog) => prog.lookaround,
+            Native(_) => false,
+        }
+    }
+
+    /// Returns true if this regex uses backreferences (`\1` or `\k<name>`).
+    ///
+    /// Like lookaround, backreferences are only evaluated by the bounded
+    /// backtracker, but without remembering which paths failed, so a search
+    /// can take time exponential in the length of the text. Consider
+    /// setting a step limit (see `set_step_limit`) for such a regex.
+    pub fn has_backrefs(&self) -> bool {
+        match self.p {
+            Dynamic(ref prog) => prog.backrefs,
+            Native(_) => false,
+        }
+    }
+
+    /// Returns the minimum length in bytes of a match of this regex.
+    ///
+    /// Like the other facts about the matches of a regex (`max_match_len`,
+    /// `literal`, `is_anchored_start`, `is_anchored_end` and
+    /// `required_literals`), it's computed from the syntax tree once, and is
+    /// conservative: a match may be longer than the minimum, but never
+    /// shorter. (A case insensitive literal counts as one byte, since it
+    /// could match a character with a shorter encoding.)
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"a(bc|d)+$").unwrap();
+    /// assert_eq!(re.min_match_len(), 2);
+    /// assert_eq!(re.max_match_len(), None);
+    /// ```
+    pub fn min_match_len(&self) -> uint {
+        self.meta().min_len
+    }
+
+    /// Returns the maximum length in bytes of a match of this regex, or
+    /// `None` if it's unbounded (e.g., because of `*`, `+` or a
+    /// backreference).
+    pub fn max_match_len(&self) -> Option<uint> {
+        self.meta().max_len
+    }
+
+    /// Returns the string that this regex matches, if it matches one fixed
+    /// string (and nothing else), e.g., `foo` or `f(o)[o]`. A regex with
+    /// assertions or case insensitive literals doesn't have one.
+    pub fn literal(&self) -> Option<~str> {
+        self.meta().literal.clone()
+    }
+
+    /// Returns true if every match of this regex starts at the beginning of
+    /// the text (e.g., with `^` or `\A`, but not `^` in multi-line mode).
+    pub fn is_anchored_start(&self) -> bool {
+        self.meta().anchored_start
+    }
+
+    /// Returns true if every match of this regex ends at the end of the text
+    /// (e.g., with `$` or `\z`, but not `$` in multi-line mode).
+    pub fn is_anchored_end(&self) -> bool {
+        self.meta().anchored_end
+    }
+
+    /// Returns every string that this regex matches all of (as
+    /// `is_full_match` does), if there are at most `limit` of them.
+    ///
+    /// The strings are in the order that a backtracking engine would try
+    /// them: the strings of an alternate come before those of the ones after
+    /// it, the characters of a class are in ascending order, and a greedy `?`
+    /// tries its expression before the empty string (a lazy `??` tries the
+    /// empty string first). Each string is listed once.
+    ///
+    /// An error is returned if the regex matches infinitely many strings
+    /// (e.g., because of `*` or `+`), if it matches more than `limit` (the
+    /// listing stops once there are too many, and a class with more than
+    /// `limit` characters isn't listed at all, so `\pL{3}` fails quickly),
+    /// or if it uses backreferences. Where the regex has assertions or
+    /// lookaround, the strings are listed as if those matched the empty
+    /// string and then checked, so the limit applies to the strings before
+    /// they're checked.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::{Regex, EnumerateInfinite, EnumerateTooMany};
+    /// let re = Regex::new(r"(GET|POST) /api/v[12]/users").unwrap();
+    /// assert_eq!(re.enumerate(10).unwrap(),
+    ///            vec!(~"GET /api/v1/users", ~"GET /api/v2/users",
+    ///                 ~"POST /api/v1/users", ~"POST /api/v2/users"));
+    ///
+    /// let re = Regex::new(r"v\d+").unwrap();
+    /// assert_eq!(re.enumerate(10).unwrap_err().kind, EnumerateInfinite);
+    /// let re = Regex::new(r"\pL{3}").unwrap();
+    /// assert_eq!(re.enumerate(1000).unwrap_err().kind, EnumerateTooMany);
+    /// ```
+    pub fn enumerate(&self, limit: uint)
+                    -> Result<Vec<~str>, enumerate::EnumerateError> {
+        enumerate::strings(self, limit)
+    }
+
+    /// Returns a random string that this regex matches all of (as
+    /// `is_full_match` does), e.g., to test code that only accepts input
+    /// that this regex has checked.
+    ///
+    /// Each alternation picks one of its alternates, each repetition picks
+    /// how many times it repeats (`*` and `+` at most `opts.max_repeat`
+    /// times) and each class picks one of its characters, uniformly at
+    /// random. Assertions and lookaround aren't satisfied as the string is
+    /// built. Instead, a string that doesn't match is thrown away and
+    /// another is built, and an error is returned if none of
+    /// `opts.retries + 1` strings match (e.g., for `a\bb` or `(?=a)b`).
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::{Regex, GenerateOptions};
+    /// use std::rand::task_rng;
+    ///
+    /// let re = Regex::new(r"[a-z]{3,8}@example\.(com|org)").unwrap();
+    /// let opts = GenerateOptions::new();
+    /// let email = re.generate(&mut task_rng(), &opts).unwrap();
+    /// assert!(re.is_full_match(email.as_slice()));
+    ///
+    /// let re = Regex::new(r"a\bb").unwrap();
+    /// assert!(re.generate(&mut task_rng(), &opts).is_err());
+    /// ```
+    pub fn generate<R: Rng>(&self, rng: &mut R,
+                            opts: &generate::GenerateOptions)
+                           -> Result<~str, generate::GenerateError> {
+        generate::generate(self, rng, opts)
+    }
+
+    /// Returns literals that each appear in every match of this regex. Only
+    /// the text matched is taken into account, so literals in lookaround
+    /// aren't required, and a regex with `\K` requires none.
+    ///
+    /// This is useful to build an index of texts (e.g., by trigrams) that
+    /// narrows the texts worth searching to those with all of the literals.
+    /// No literal is required where the regex has alternates that don't
+    /// share it.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"(\w+)@(mail|post)\.example\.com").unwrap();
+    /// assert_eq!(re.required_literals(), vec!(~"@", ~".example.com"));
+    /// ```
+    pub fn required_literals(&self) -> Vec<~str> {
+        self.meta().required.clone()
+    }
+
+    // Returns the facts about the matches of this regex, computing them from
+    // the syntax tree if they haven't been yet.
+    fn meta(&self) -> Arc<Metadata> {
+        self.meta.get(|| Metadata::new(&**self.tree()))
+    }
+
+    /// Returns the start and end byte range of the leftmost-longest match in
+    /// `text`: of the matches starting at the leftmost position, the
+    /// longest. This is the match `find` returns for regexes compiled with
+    /// `Options::longest`, but it can be asked of any regex. The compiled
+    /// program is shared with the other searches, so a regex can be
+    /// searched both ways (even from many tasks at once).
+    ///
+    /// Like every search for the longest match, it's done by the NFA
+    /// simulation. Regexes compiled with the `regex!` macro compile a
+    /// dynamic program for it the first time it's needed.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new("a|ab").unwrap();
+    /// assert_eq!(re.find("abc"), Some((0, 1)));
+    /// assert_eq!(re.find_longest("abc"), Some((0, 2)));
+    /// ```
+    pub fn find_longest(&self, text: &str) -> Option<(uint, uint)> {
+        self.find_at_longest(text, 0)
+    }
+
+    /// Returns the start and end byte range of the leftmost-longest match in
+    /// `text` that starts at or after the byte index `start`, like
+    /// `find_longest`.
+    pub fn find_at_longest(&self, text: &str, start: uint)
+                          -> Option<(uint, uint)> {
+        check_start(text, start);
+        let caps = exec_longest(self, Location, text, start);
+        if has_match(&caps) {
+            Some((caps.get(0).unwrap(), caps.get(1).unwrap()))
+        } else {
+            None
+        }
+    }
+
+    /// Returns the capture groups of the leftmost-longest match in `text`,
+    /// like `find_longest`. The groups are the ones the first alternative
+    /// (in the order `captures` tries them) to match that way found.
+    pub fn captures_longest<'t>(&self, text: &'t str)
+                               -> Option<Captures<'t>> {
+        self.captures_at_longest(text, 0)
+    }
+
+    /// Returns the capture groups of the leftmost-longest match in `text`
+    /// that starts at or after the byte index `start`, like
+    /// `captures_longest`.
+    pub fn captures_at_longest<'t>(&self, text: &'t str, start: uint)
+                                  -> Option<Captures<'t>> {
+        check_start(text, start);
+        let caps = exec_longest(self, Submatches, text, start);
+        Captures::new(self, text, caps)
+    }
+
+    // Returns the dynamic regex that finds leftmost-longest matches for a
+    // native regex.
+    fn longest(&self) -> Arc<Regex> {
+        self.longest.get(|| {
+            let mut longest = from_ast(self.original.clone(),
+                                       (*self.tree()).clone());
+            longest.set_dfa_size_limit(self.dfa_size_limit());
+            longest.set_backtrack_limit(self.backtrack_limit());
+            longest.set_step_limit(self.step_limit());
+            longest.dfa.set_longest(true);
+            longest.dfa.set_posix(self.dfa.posix());
+            longest.dfa.share_stats(&self.dfa);
+            longest
+        })
+    }
+
+    /// Returns the start and end byte range of the match in `text` that
+    /// starts exactly at the byte index `start`, or `None` if no match
+    /// starts there. Among the matches starting at `start`, the one found
+    /// is the one `find_at` would find (if it found one starting there).
+    ///
+    /// The anchoring applies to the search position, not to the regex:
+    /// `^` still only matches at the beginning of `text` (or of a line in
+    /// multi-line mode). Zero-width assertions are evaluated with respect to
+    /// all of `text`. `start` must be at a character boundary of `text` (or
+    /// equal to its length), otherwise this function fails.
+    ///
+    /// The search stops as soon as no match can start at `start` anymore, so
+    /// trying several expressions in turn at the same position (as a lexer
+    /// does) doesn't scan the rest of the text.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"[0-9]+");
+    /// assert_eq!(re.find_anchored("x = 42", 4), Some((4, 6)));
+    /// assert_eq!(re.find_anchored("x = 42", 3), None);
+    /// # }
+    /// ```
+    pub fn find_anchored(&self, text: &str, start: uint)
+                        -> Option<(uint, uint)> {
+        check_start(text, start);
+        let caps = exec_anchored(self, Location, text, start);
+        if has_match(&caps) {
+            Some((caps.get(0).unwrap(), caps.get(1).unwrap()))
+        } else {
+            None
+        }
+    }
+
+    /// Returns the byte index at which the longest match in `text` that
+    /// starts exactly at the byte index `at` ends, or `None` if no match
+    /// starts there. The match is the longest one whether or not the regex
+    /// finds leftmost-longest matches, which is what a lexer wants from
+    /// the expression of each of its tokens (see `RegexSet::find_prefix`
+    /// for matching all of them at once).
+    ///
+    /// Like `find_anchored`, the anchoring applies to the search position:
+    /// zero-width assertions like `^` and `\b` are evaluated with respect to
+    /// all of `text`, and the search stops as soon as no match can start at
+    /// `at` anymore. `at` must be at a character boundary of `text` (or
+    /// equal to its length), otherwise this function fails.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"\b(a|ab|abc)").unwrap();
+    /// assert_eq!(re.find_prefix("x abcd", 2), Some(5));
+    /// assert_eq!(re.find_prefix("xabcd", 1), None);
+    /// ```
+    pub fn find_prefix(&self, text: &str, at: uint) -> Option<uint> {
+        check_start(text, at);
+        match self.p {
+            Dynamic(ref prog) => {
+                match self.dfa.find_prefix(&**prog, text, at, None) {
+                    Ok(end) => end,
+                    Err(_) => None,
+                }
+            }
+            Native(_) => self.longest().find_prefix(text, at),
+        }
+    }
+
+    /// Returns the capture groups of the match in `text` that starts
+    /// exactly at the byte index `start`, like `find_anchored`. If no match
+    /// starts there, then `None` is returned.
+    pub fn captures_anchored<'t>(&self, text: &'t str, start: uint)
+                                -> Option<Captures<'t>> {
+        check_start(text, start);
+        let caps = exec_anchored(self, Submatches, text, start);
+        Captures::new(self, text, caps)
+    }
+
+    /// Splits `text` around the leftmost-first match, returning the text
+    /// before the match, the text of the match and the text after it. If
+    /// there's no match, then `None` is returned (and all of `text` is
+    /// before it).
+    ///
+    /// Only the bounds of one match are found, so this is as fast as `find`.
+    /// When the match is empty, the text before or after it may be empty too:
+    /// an empty match at the start of `text` cuts it into `""`, `""` and
+    /// `text`.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\s*=\s*");
+    /// assert_eq!(re.cut("key = val"), Some(("key", " = ", "val")));
+    /// assert_eq!(re.cut("key"), None);
+    /// # }
+    /// ```
+    pub fn cut<'t>(&self, text: &'t str)
+                  -> Option<(&'t str, &'t str, &'t str)> {
+        self.find(text).map(|(s, e)| {
+            (text.slice_to(s), text.slice(s, e), text.slice_from(e))
+        })
+    }
+
+    /// Splits `text` around the leftmost-first match like `cut`, except
+    /// that the capture groups of the match are returned in place of its
+    /// text.
+    pub fn cut_captures<'t>(&self, text: &'t str)
+                           -> Option<(&'t str, Captures<'t>, &'t str)> {
+        self.captures(text).map(|caps| {
+            let (s, e) = caps.pos(0).unwrap();
+            (text.slice_to(s), caps, text.slice_from(e))
+        })
+    }
+
+    /// Returns an iterator for each successive non-overlapping match in
+    /// `text`, returning the start and end byte indices with respect to
+    /// `text`.
+    ///
+    /// An empty match that starts where the previous match ended isn't
+    /// reported (so `a*` finds `(0, 1)` and `(2, 2)` in `ab`, but not
+    /// `(1, 1)`), and after an empty match, the search resumes at the next
+    /// character, so that no match starts in the middle of one. Every method
+    /// that finds successive matches follows the same rules, including
+    /// `captures_iter`, `split` and `replace_all`.
+    ///
+    /// # Example
+    ///
+    /// Find the start and end location of the first word with exactly 13
+    /// characters:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let text = "Retroactively relinquishing remunerations is reprehensible.";
+    /// for pos in regex!(r"\b\w{13}\b").find_iter(text) {
+    ///     println!("{}", pos);
+    /// }
+    /// // Output:
+    /// // (0, 13)
+    /// // (14, 27)
+    /// // (28, 41)
+    /// // (45, 58)
+    /// # }
+    /// ```
+    pub fn find_iter<'r, 't>(&'r self, text: &'t str) -> FindMatches<'r, 't> {
+        FindMatches {
+            re: self,
+            search: text,
+            last_end: 0,
+            last_match: None,
+        }
+    }
+
+    /// Returns an iterator over the same matches as `find_iter`, except that
+    /// their start and end positions are character offsets rather than byte
+    /// offsets. This is what editors and linters that count characters need.
+    ///
+    /// The offsets are counted as the matches are found, so the text before
+    /// a match is only counted once, rather than once per match.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\w+");
+    /// let found: Vec<(uint, uint)> = re.find_iter_chars("δδ ab").collect();
+    /// assert_eq!(found, vec!((0, 2), (3, 5)));
+    /// # }
+    /// ```
+    pub fn find_iter_chars<'r, 't>(&'r self, text: &'t str)
+                                  -> FindCharMatches<'r, 't> {
+        FindCharMatches {
+            matches: self.find_iter(text),
+            offsets: CharOffsets::new(text),
+        }
+    }
+
+    /// Returns the leftmost-first match in `text` like `find`, along with
+    /// the index of the alternate of the regex's top-level alternation that
+    /// it comes from. For example, the alternates of `foo|foobar|ba+r` are
+    /// numbered 0, 1 and 2. The index is `None` if the regex has no
+    /// top-level alternation.
+    ///
+    /// This tells what matched without a capture group around each
+    /// alternate. The alternates are numbered as they're written, even
+    /// though alternates of literals are merged when the regex is compiled.
+    /// Only an alternation that isn't in any group is top-level, so
+    /// `(?:a|b)` has none.
+    ///
+    /// Finding the alternate costs about what finding the capture groups of
+    /// the match does (and when the alternates use lookaround, what the
+    /// backtracker takes to find the match again). When they use
+    /// backreferences or `\K`, the alternate isn't known, and the index is
+    /// `None`.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"foo|foobar|ba+r").unwrap();
+    /// assert_eq!(re.find_branch("a foobar"), Some(((2, 5), Some(0))));
+    /// assert_eq!(re.find_branch("baaar"), Some(((0, 5), Some(2))));
+    /// assert_eq!(Regex::new("a+").unwrap().find_branch("aa"),
+    ///            Some(((0, 2), None)));
+    /// ```
+    pub fn find_branch(&self, text: &str)
+                      -> Option<((uint, uint), Option<uint>)> {
+        self.find(text).map(|(s, e)| {
+            ((s, e), self.branch(&*program(self), text, s))
+        })
+    }
+
+    /// Returns an iterator over the same matches as `find_iter`, along with
+    /// the index of the alternate of the regex's top-level alternation that
+    /// each comes from (see `find_branch`).
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"[0-9]+|[a-z]+").unwrap();
+    /// let found: Vec<Option<uint>> =
+    ///     re.find_branch_iter("ab 12 c").map(|(_, branch)| branch).collect();
+    /// assert_eq!(found, vec!(Some(1), Some(0), Some(1)));
+    /// ```
+    pub fn find_branch_iter<'r, 't>(&'r self, text: &'t str)
+                                   -> FindBranches<'r, 't> {
+        FindBranches { matches: self.find_iter(text), prog: program(self) }
+    }
+
+    // Returns the alternate that the match starting at `s` in `text` comes
+    // from, given the program returned by `program`.
+    fn branch(&self, prog: &Program, text: &str, s: uint) -> Option<uint> {
+        branch::find(prog, text, s, self.is_longest(), self.backtrack_limit())
+    }
+
+    /// Returns the start and end character offsets of the leftmost-first
+    /// match in `chars`, exactly like `find` on the string made of the
+    /// characters given.
+    ///
+    /// Every matching engine searches UTF-8, so each call copies the
+    /// characters into a new string (of up to four bytes per character) to
+    /// search it. The positions are still never converted to bytes and back
+    /// by the caller. To search the same characters more than once, encode
+    /// them once with `str::from_chars` and use `find_iter_chars` instead.
+    pub fn find_chars(&self, chars: &[char]) -> Option<(uint, uint)> {
+        let text = str::from_chars(chars);
+        self.find_iter_chars(text.as_slice()).next()
+    }
+
+    /// Returns the number of non-overlapping matches in `text`. This is
+    /// always the same as `self.find_iter(text).count()`, including how
+    /// empty matches are counted.
+    ///
+    /// Since only the bounds of each match are needed, they are found with
+    /// the fastest engine available, which usually doesn't allocate.
+    ///
+    /// # Example
+    ///
+    /// Count the number of words:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\b\w+\b");
+    /// assert_eq!(re.count("Hello, brave new world!"), 4);
+    /// # }
+    /// ```
+    pub fn count(&self, text: &str) -> uint {
+        self.find_iter(text).count()
+    }
+
+    /// Returns the start and end byte indices of every non-overlapping match
+    /// in `text`, exactly like `find_iter`, using up to `workers` tasks at
+    /// once. This is for searching texts that are big enough (many times
+    /// larger than a few kilobytes per worker) to be worth splitting up;
+    /// smaller texts are searched by this task alone.
+    ///
+    /// The text is split into a chunk for each worker, and each chunk is
+    /// searched as if its matches were found by `find_iter` starting at the
+    /// beginning of the chunk. Where a match crosses from one chunk into the
+    /// next one, the matches near the start of the next chunk are found
+    /// again until the searches agree, so matches that straddle chunks (and
+    /// empty matches) are found exactly as `find_iter` finds them.
+    ///
+    /// The text is copied once, so that the workers can share it. Use
+    /// `truncate` on the result to keep only the first matches.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"\w+").unwrap();
+    /// let text = "lorem ipsum dolor ".repeat(10000);
+    /// let found = re.find_iter_parallel(text.as_slice(), 4);
+    /// assert_eq!(found.len(), 30000);
+    /// assert_eq!(found, re.find_iter(text.as_slice()).collect());
+    /// ```
+    pub fn find_iter_parallel(&self, text: &str, workers: uint)
+                             -> Vec<(uint, uint)> {
+        parallel::find_all(self, text, workers)
+    }
+
+    /// Returns the number of non-overlapping matches in `text`, like
+    /// `count`, using up to `workers` tasks at once (see
+    /// `find_iter_parallel`).
+    pub fn count_parallel(&self, text: &str, workers: uint) -> uint {
+        parallel::find_all(self, text, workers).len()
+    }
+
+    /// Returns an iterator over the leftmost-first match starting at every
+    /// position of `text` at which one starts, so matches may overlap. After
+    /// each match, the search resumes one character after where the match
+    /// started (rather than where it ended, like `find_iter`). Every match
+    /// (empty or not) starts after the previous one, so the iterator always
+    /// ends. Use `take` to stop after a number of matches.
+    ///
+    /// Each search takes advantage of the DFA states computed by the ones
+    /// before it (and skips ahead to the next occurrence of a literal prefix
+    /// when there is one), but the text of a match is scanned again by the
+    /// search for every match that starts inside it. So the time taken is
+    /// quadratic in the length of the text at worst: `a+` on a text of `n`
+    /// `a`s finds `n` matches, and the search for each scans to the end.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new("aa").unwrap();
+    /// let found: Vec<(uint, uint)> =
+    ///     re.find_overlapping_iter("aaaa").collect();
+    /// assert_eq!(found, vec![(0, 2), (1, 3), (2, 4)]);
+    /// assert_eq!(re.find_iter("aaaa").count(), 2);
+    /// ```
+    pub fn find_overlapping_iter<'r, 't>(&'r self, text: &'t str)
+                                        -> FindOverlapping<'r, 't> {
+        FindOverlapping {
+            re: self,
+            search: text,
+            next_start: 0,
+        }
+    }
+
+    /// Returns the number of matches in `text`, counting overlapping ones.
+    /// This is always the same as `self.find_overlapping_iter(text).count()`.
+    pub fn count_overlapping(&self, text: &str) -> uint {
+        self.find_overlapping_iter(text).count()
+    }
+
+    /// Returns the capture groups corresponding to the leftmost-first
+    /// match in `text`. Capture group `0` always corresponds to the entire
+    /// match. If no match is found, then `None` is returned.
+    ///
+    /// You should only use `captures` if you need access to submatches.
+    /// Otherwise, `find` is faster for discovering the location of the overall
+    /// match.
+    ///
+    /// # Examples
+    ///
+    /// Say you have some text with movie names and their release years,
+    /// like "'Citizen Kane' (1941)". It'd be nice if we could search for text
+    /// looking like that, while also extracting the movie name and its release
+    /// year separately.
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"'([^']+)'\s+\((\d{4})\)");
+    /// let text = "Not my favorite movie: 'Citizen Kane' (1941).";
+    /// let caps = re.captures(text).unwrap();
+    /// assert_eq!(caps.at(1), "Citizen Kane");
+    /// assert_eq!(caps.at(2), "1941");
+    /// assert_eq!(caps.at(0), "'Citizen Kane' (1941)");
+    /// # }
+    /// ```
+    ///
+    /// Note that the full match is at capture group `0`. Each subsequent
+    /// capture group is indexed by the order of its opening `(`.
+    ///
+    /// We can make this example a bit clearer by using *named* capture groups:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"'(?P<title>[^']+)'\s+\((?P<year>\d{4})\)");
+    /// let text = "Not my favorite movie: 'Citizen Kane' (1941).";
+    /// let caps = re.captures(text).unwrap();
+    /// assert_eq!(caps.name("title"), "Citizen Kane");
+    /// assert_eq!(caps.name("year"), "1941");
+    /// assert_eq!(caps.at(0), "'Citizen Kane' (1941)");
+    /// # }
+    /// ```
+    ///
+    /// Here we name the capture groups, which we can access with the `name`
+    /// method. Note that the named capture groups are still accessible with
+    /// `at`.
+    ///
+    /// The `0`th capture group is always unnamed, so it must always be
+    /// accessed with `at(0)`.
+    pub fn captures<'t>(&self, text: &'t str) -> Option<Captures<'t>> {
+        let caps = exec(self, Submatches, text);
+        Captures::new(self, text, caps)
+    }
+
+    /// Returns the capture groups corresponding to the leftmost-first match
+    /// in `text` that starts at or after the byte index `start`. If no match
+    /// is found, then `None` is returned.
+    ///
+    /// As with `find_at`, zero-width assertions are evaluated with respect to
+    /// all of `text` and the positions of the capture groups are byte
+    /// indices into `text`. `start` must be at a character boundary of
+    /// `text` (or equal to its length), otherwise this function fails.
+    pub fn captures_at<'t>(&self, text: &'t str, start: uint)
+                          -> Option<Captures<'t>> {
+        check_start(text, start);
+        let caps = exec_slice(self, Submatches, text, start, text.len());
+        Captures::new(self, text, caps)
+    }
+
+    /// Returns the matched string for every named capture group of the
+    /// leftmost-first match in `text`, keyed by name (see
+    /// `Captures::named_map`). If no match is found, then `None` is returned.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"(?P<y>\d{4})-(?P<m>\d{2})(-(?P<d>\d{2}))?");
+    /// let map = re.captures_map("due 2014-05").unwrap();
+    /// assert_eq!(*map.get(&~"y"), "2014");
+    /// assert_eq!(*map.get(&~"m"), "05");
+    /// assert!(!map.contains_key(&~"d"));
+    /// # }
+    /// ```
+    pub fn captures_map<'t>(&self, text: &'t str)
+                           -> Option<HashMap<~str, &'t str>> {
+        self.captures(text).map(|caps| caps.named_map())
+    }
+
+    /// Returns the start and end positions of every named capture group of
+    /// the leftmost-first match in `text`, keyed by name, like
+    /// `captures_map`.
+    pub fn captures_pos_map(&self, text: &str)
+                           -> Option<HashMap<~str, (uint, uint)>> {
+        self.captures(text).map(|caps| caps.named_pos_map())
+    }
+
+    /// Decodes the named capture groups of the leftmost-first match in
+    /// `text` into a struct, which is usually `#[deriving(Decodable)]`. If
+    /// no match is found, then `None` is returned.
+    ///
+    /// Each field is decoded from the group with its name, or, if there's
+    /// none, from the one whose name is the same but for ASCII case. The
+    /// text of a group is parsed (with `from_str`) for a field that's a
+    /// number or a `bool`, and taken as it is for a string or a character
+    /// (or a `Vec<u8>`, which gets its bytes). A type whose `Decodable` impl
+    /// reads a string can parse the text itself.
+    ///
+    /// A field that's an `Option` is `None` when its group didn't take part
+    /// in the match. It's an error if the group of any other field didn't
+    /// take part in the match, or there's no such group. The error names the
+    /// group (or the field).
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// extern crate regex;
+    /// extern crate serialize;
+    /// # fn main() {
+    /// use regex::Regex;
+    ///
+    /// #[deriving(Decodable)]
+    /// struct Size { width: uint, height: uint, unit: Option<~str> }
+    ///
+    /// let re = Regex::new(r"(?P<Width>\d+)x(?P<Height>\d+)(?P<unit>px)?")
+    ///               .unwrap();
+    /// let size: Size = re.scan("800x600").unwrap().unwrap();
+    /// assert_eq!((size.width, size.height, size.unit), (800, 600, None));
+    /// # }
+    /// ```
+    pub fn scan<'a, T: Decodable<scan::CapturesDecoder<'a>, scan::ScanError>>
+               (&'a self, text: &'a str)
+               -> Result<Option<T>, scan::ScanError> {
+        match self.captures(text) {
+            None => Ok(None),
+            Some(caps) => scan::decode(self, caps).map(|v| Some(v)),
+        }
+    }
+
+    /// Decodes the named capture groups of every successive non-overlapping
+    /// match in `text` into a struct, like `scan`, in order. The first match
+    /// that can't be decoded is an error.
+    pub fn scan_all<'a, T: Decodable<scan::CapturesDecoder<'a>,
+                                     scan::ScanError>>
+                   (&'a self, text: &'a str)
+                   -> Result<Vec<T>, scan::ScanError> {
+        let mut all = vec!();
+        for caps in self.captures_iter(text) {
+            all.push(try!(scan::decode(self, caps)));
+        }
+        Ok(all)
+    }
+
+    /// Returns the capture groups corresponding to the leftmost-first match
+    /// in `text`, like `captures`. If the search exceeds the step limit (see
+    /// `set_step_limit`), then an error is returned instead.
+    pub fn try_captures<'t>(&self, text: &'t str)
+                           -> Result<Option<Captures<'t>>, StepLimitExceeded> {
+        self.try_captures_at(text, 0)
+    }
+
+    /// Returns the capture groups corresponding to the leftmost-first match
+    /// in `text` that starts at or after `start`, like `captures_at`. If the
+    /// search exceeds the step limit (see `set_step_limit`), then an error
+    /// is returned instead.
+    pub fn try_captures_at<'t>(&self, text: &'t str, start: uint)
+                              -> Result<Option<Captures<'t>>,
+                                        StepLimitExceeded> {
+        check_start(text, start);
+        let caps = try!(try_exec_slice(self, Submatches, text, start,
+                                       text.len(), None));
+        Ok(Captures::new(self, text, caps))
+    }
+
+    /// Returns true if and only if the regex matches the string given, like
+    /// `is_match`. If `cancel` is set before the search completes, then an
+    /// error is returned instead.
+    ///
+    /// The flag is polled every few thousand steps of the search (see
+    /// `set_step_limit`), so a search over a large text stops promptly.
+    /// Regexes compiled with the `regex!` macro only poll it before they
+    /// start searching.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::{Regex, Cancel, Cancelled};
+    /// let re = Regex::new(r"\w+z").unwrap();
+    /// let cancel = Cancel::new();
+    /// assert_eq!(re.is_match_cancel("abc", &cancel), Ok(false));
+    /// cancel.cancel();
+    /// assert_eq!(re.is_match_cancel("abc", &cancel), Err(Cancelled));
+    /// ```
+    pub fn is_match_cancel(&self, text: &str, cancel: &Cancel)
+                          -> Result<bool, Cancelled> {
+        if cancel.is_cancelled() {
+            return Err(Cancelled)
+        }
+        match try_is_match_at(self, text, 0, Some(cancel)) {
+            Ok(found) => Ok(found),
+            Err(_) if cancel.is_cancelled() => Err(Cancelled),
+            Err(_) => Ok(false),
+        }
+    }
+
+    /// Returns the start and end byte range of every non-overlapping match
+    /// in `text`, exactly like `find_iter`. If `cancel` is set before the
+    /// search completes, then an error is returned and none of the matches
+    /// are.
+    ///
+    /// The flag is polled during each search just like `is_match_cancel`
+    /// does, and between matches.
+    pub fn find_all_cancel(&self, text: &str, cancel: &Cancel)
+                          -> Result<Vec<(uint, uint)>, Cancelled> {
+        let mut found = vec!();
+        let (mut last_end, mut last_match) = (0, None);
+        while last_end <= text.len() {
+            let m = match try!(find_at_cancel(self, text, last_end,
+                                              cancel)) {
+                None => break,
+                Some(m) => m,
+            };
+            if accept_match(text, m, &mut last_end, &mut last_match) {
+                found.push(m);
+            }
+        }
+        Ok(found)
+    }
+
+    /// Replaces all non-overlapping matches in `text` with the replacement
+    /// provided, exactly like `replace_all`. If `cancel` is set before the
+    /// search completes, then an error is returned instead of a partially
+    /// replaced copy of `text`.
+    ///
+    /// The flag is polled during each search just like `is_match_cancel`
+    /// does, and between matches.
+    pub fn replace_all_cancel<R: Replacer>
+                             (&self, text: &str, mut rep: R, cancel: &Cancel)
+                             -> Result<StrBuf, Cancelled> {
+        let mut new = StrBuf::with_capacity(text.len());
+        let (mut written, mut last_end, mut last_match) = (0, 0, None);
+        while last_end <= text.len() {
+            let caps = match try!(captures_at_cancel(self, text, last_end,
+                                                     cancel)) {
+                None => break,
+                Some(caps) => caps,
+            };
+            let (s, e) = caps.pos(0).unwrap();
+            if !accept_match(text, (s, e), &mut last_end, &mut last_match) {
+                continue
+            }
+            new.push_str(text.slice(written, s));
+            new.push_str(rep.reg_replace(&caps).as_slice());
+            written = e;
+        }
+        new.push_str(text.slice_from(written));
+        Ok(new)
+    }
+
+    /// Appends the locations of the capture groups of the leftmost-first
+    /// match in `text` to `locs`, and returns `true`. Each capture group
+    /// (including the zeroth) appends two byte indices: where the group
+    /// starts and where it ends. Both are `None` if the group didn't match
+    /// anything.
+    ///
+    /// If there is no match, then `locs` is left unchanged and `false` is
+    /// returned. (This distinguishes a failed search from a match whose
+    /// groups are all empty.)
+    ///
+    /// This is useful when searching many texts: unlike `captures`, it
+    /// doesn't build a `Captures` value, and the same vector can be reused
+    /// for every search.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"(\d+)-(\d+)?");
+    /// let mut locs = Vec::new();
+    /// assert!(re.push_captures("x 12-", &mut locs));
+    /// assert_eq!(locs, vec!(Some(2), Some(5), Some(2), Some(4), None, None));
+    /// assert!(!re.push_captures("x", &mut locs));
+    /// assert_eq!(locs.len(), 6);
+    /// # }
+    /// ```
+    pub fn push_captures(&self, text: &str,
+                         locs: &mut Vec<Option<uint>>) -> bool {
+        let base = locs.len();
+        exec_slice_into(self, Submatches, text, 0, text.len(), locs);
+        if locs.get(base).is_none() {
+            locs.truncate(base);
+            return false
+        }
+        true
+    }
+
+    /// Appends the locations of the capture groups of every non-overlapping
+    /// match in `text` to `locs`, and returns the number of matches. The
+    /// matches are the same as those found by `captures_iter`, and the
+    /// locations of each match are appended as in `push_captures`. (So the
+    /// locations of the `i`th match start at index `2 * i * n`, where `n` is
+    /// the number of capture groups, including the zeroth.)
+    pub fn push_captures_all(&self, text: &str,
+                             locs: &mut Vec<Option<uint>>) -> uint {
+        let (mut last_end, mut last_match) = (0, None);
+        let mut n = 0;
+        while next_captures_into(self, text, &mut last_end, &mut last_match,
+                                 locs) {
+            n += 1;
+        }
+        n
+    }
+
+    /// Returns an iterator over all the non-overlapping capture groups matched
+    /// in `text`. This is operationally the same as `find_iter` (except it
+    /// yields information about submatches).
+    ///
+    /// # Example
+    ///
+    /// We can use this to find all movie titles and their release years in
+    /// some text, where the movie is formatted like "'Title' (xxxx)":
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"'(?P<title>[^']+)'\s+\((?P<year>\d{4})\)");
+    /// let text = "'Citizen Kane' (1941), 'The Wizard of Oz' (1939), 'M' (1931).";
+    /// for caps in re.captures_iter(text) {
+    ///     println!("Movie: {}, Released: {}", caps.name("title"), caps.name("year"));
+    /// }
+    /// // Output:
+    /// // Movie: Citizen Kane, Released: 1941
+    /// // Movie: The Wizard of Oz, Released: 1939
+    /// // Movie: M, Released: 1931
+    /// # }
+    /// ```
+    pub fn captures_iter<'r, 't>(&'r self, text: &'t str)
+                                -> FindCaptures<'r, 't> {
+        FindCaptures {
+            re: self,
+            search: text,
+            last_match: None,
+            last_end: 0,
+        }
+    }
+
+    /// Returns an iterator over the locations of the capture groups of the
+    /// same matches as `captures_iter`, as in `Captures::iter_pos`, except
+    /// that the start and end positions are character offsets rather than
+    /// byte offsets. Like in `find_iter_chars`, the offsets are counted as
+    /// the matches are found.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"(\w+)=(\w+)?");
+    /// let mut it = re.captures_iter_chars("é=δ a=");
+    /// assert_eq!(it.next(), Some(vec!(Some((0, 3)), Some((0, 1)),
+    ///                                 Some((2, 3)))));
+    /// assert_eq!(it.next(), Some(vec!(Some((4, 6)), Some((4, 5)), None)));
+    /// assert_eq!(it.next(), None);
+    /// # }
+    /// ```
+    pub fn captures_iter_chars<'r, 't>(&'r self, text: &'t str)
+                                      -> FindCharCaptures<'r, 't> {
+        FindCharCaptures {
+            re: self,
+            search: text,
+            last_match: None,
+            last_end: 0,
+            offsets: CharOffsets::new(text),
+        }
+    }
+
+    /// Returns the locations of the capture groups of the leftmost-first
+    /// match in `chars`, as character offsets, exactly like `captures` on
+    /// the string made of the characters given. Like `find_chars`, it
+    /// copies the characters into a new string on each call.
+    pub fn captures_chars(&self, chars: &[char])
+                         -> Option<Vec<Option<(uint, uint)>>> {
+        let text = str::from_chars(chars);
+        self.captures_iter_chars(text.as_slice()).next()
+    }
+
+    /// Returns a cursor over the capture groups of each successive
+    /// non-overlapping match in `text`. This finds the same matches as
+    /// `captures_iter`, but reuses the same storage for every match, which
+    /// is cheaper when there are many matches.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"(?P<key>\w+)=(?P<val>\w+)");
+    /// let mut cur = re.captures_cursor("a=1 b=2 c=3");
+    /// while cur.advance() {
+    ///     if cur.name("key") == "b" {
+    ///         println!("{}", cur.name("val"));
+    ///         break
+    ///     }
+    /// }
+    /// // Output:
+    /// // 2
+    /// # }
+    /// ```
+    pub fn captures_cursor<'r, 't>(&'r self, text: &'t str)
+                                  -> CaptureCursor<'r, 't> {
+        CaptureCursor {
+            re: self,
+            search: text,
+            last_match: None,
+            last_end: 0,
+            locs: Vec::with_capacity(self.names.len() * 2),
+        }
+    }
+
+    /// Returns an iterator of substrings of `text` delimited by a match
+    /// of the regular expression.
+    /// Namely, each element of the iterator corresponds to text that *isn't*
+    /// matched by the regular expression.
+    ///
+    /// The delimiters are the matches that `find_iter` finds, so a regex
+    /// that can match the empty string splits the text between characters.
+    /// This method will *not* copy the text given.
+    ///
+    /// # Example
+    ///
+    /// To split a string delimited by arbitrary amounts of spaces or tabs:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"[ \t]+");
+    /// let fields: Vec<&str> = re.split("a b \t  c\td    e").collect();
+    /// assert_eq!(fields, vec!("a", "b", "c", "d", "e"));
+    /// # }
+    /// ```
+    pub fn split<'r, 't>(&'r self, text: &'t str) -> RegexSplits<'r, 't> {
+        RegexSplits {
+            finder: self.find_iter(text),
+            last: 0,
+        }
+    }
+
+    /// Returns an iterator of at most `limit` substrings of `text` delimited
+    /// by a match of the regular expression. (A `limit` of `0` will return no
+    /// substrings.)
+    /// Namely, each element of the iterator corresponds to text that *isn't*
+    /// matched by the regular expression.
+    /// The remainder of the string that is not split will be the last element
+    /// in the iterator.
+    ///
+    /// This method will *not* copy the text given.
+    ///
+    /// # Example
+    ///
+    /// Get the first two words in some text:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\W+");
+    /// let fields: Vec<&str> = re.splitn("Hey! How are you?", 3).collect();
+    /// assert_eq!(fields, vec!("Hey", "How", "are you?"));
+    /// # }
+    /// ```
+    pub fn splitn<'r, 't>(&'r self, text: &'t str, limit: uint)
+                         -> RegexSplitsN<'r, 't> {
+        RegexSplitsN {
+            splits: self.split(text),
+            cur: 0,
+            limit: limit,
+        }
+    }
+
+    /// Returns an iterator of substrings of `text` delimited by a match of
+    /// the regular expression, like `split`, except that the text matched
+    /// by the capture groups of each delimiter is yielded between the
+    /// substrings it separates. This is a convenient way to tokenize text:
+    /// the parts of a delimiter that should be kept are wrapped in a group.
+    ///
+    /// All of the capture groups of the regex are yielded for every
+    /// delimiter, in order, so a regex without capture groups behaves
+    /// exactly like `split`. A group that didn't participate in a match
+    /// yields the empty string (just like `Captures::at`).
+    ///
+    /// Every other detail follows `split`: a delimiter at the start of the
+    /// text yields an empty substring first, empty matches split the text
+    /// between characters, and no empty substring follows a delimiter at the
+    /// end of the text (although its groups are still yielded).
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\s*([-+*/])\s*");
+    /// let tokens: Vec<&str> = re.split_keep("1 + 22*3").collect();
+    /// assert_eq!(tokens, vec!("1", "+", "22", "*", "3"));
+    /// # }
+    /// ```
+    pub fn split_keep<'r, 't>(&'r self, text: &'t str)
+                             -> RegexSplitsKeep<'r, 't> {
+        RegexSplitsKeep {
+            finder: self.captures_iter(text),
+            last: 0,
+            delim: None,
+            group: 0,
+            cur: 0,
+            limit: None,
+        }
+    }
+
+    /// Returns an iterator like `split_keep`, except that at most `limit`
+    /// substrings delimited by a match are yielded (along with the capture
+    /// groups of the delimiters between them). The remainder of the string
+    /// that is not split will be the last substring. (A `limit` of `0` will
+    /// return nothing.) This is the same convention as `splitn`.
+    pub fn splitn_keep<'r, 't>(&'r self, text: &'t str, limit: uint)
+                              -> RegexSplitsKeep<'r, 't> {
+        RegexSplitsKeep { limit: Some(limit), ..self.split_keep(text) }
+    }
+
+    /// Replaces the leftmost-first match with the replacement provided.
+    /// The replacement can be a regular string (where `$N` and `$name` are
+    /// expanded to match capture groups) or a function that takes the matches'
+    /// `Captures` and returns the replaced string.
+    ///
+    /// If no match is found, then a copy of the string is returned unchanged.
+    ///
+    /// # Examples
+    ///
+    /// Note that this function is polymorphic with respect to the replacement.
+    /// In typical usage, this can just be a normal string:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!("[^01]+");
+    /// assert_eq!(re.replace("1078910", "").as_slice(), "1010");
+    /// # }
+    /// ```
+    ///
+    /// But anything satisfying the `Replacer` trait will work. For example,
+    /// a closure of type `|&Captures| -> ~str` provides direct access to the
+    /// captures corresponding to a match. This allows one to access
+    /// submatches easily:
     ///
     /// ```rust
     /// # #![feature(phase)]
@@ -420,78 +2490,638 @@ impl Regex {
     /// # }
     /// ```
     ///
-    /// But this is a bit cumbersome to use all the time. Instead, a simple
-    /// syntax is supported that expands `$name` into the corresponding capture
-    /// group. Here's the last example, but using this expansion technique
-    /// with named capture groups:
+    /// The captures given to the closure are found by the same search that
+    /// finds each match, so there's no need to search the match again inside
+    /// the closure. The strings returned by `caps.at` are slices of `text`,
+    /// so no capture group is copied unless the closure copies it. A group
+    /// that didn't participate in the match has no position (`caps.pos`
+    /// returns `None`), which tells it apart from a group that matched the
+    /// empty string.
+    ///
+    /// But this is a bit cumbersome to use all the time. Instead, a simple
+    /// syntax is supported that expands `$name` into the corresponding capture
+    /// group. Here's the last example, but using this expansion technique
+    /// with named capture groups:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"(?P<last>[^,\s]+),\s+(?P<first>\S+)");
+    /// let result = re.replace("Springsteen, Bruce", "$first $last");
+    /// assert_eq!(result.as_slice(), "Bruce Springsteen");
+    /// # }
+    /// ```
+    ///
+    /// Note that using `$2` instead of `$first` or `$1` instead of `$last`
+    /// would produce the same result. To write a literal `$` use `$$`. See
+    /// `Template` for the rest of the syntax (such as defaults for groups
+    /// that didn't participate). A `Template` can also be parsed once and
+    /// given as the replacement, which saves parsing it for every match.
+    ///
+    /// Finally, sometimes you just want to replace a literal string with no
+    /// submatch expansion. This can be done by wrapping a string with
+    /// `NoExpand`:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// use regex::NoExpand;
+    ///
+    /// let re = regex!(r"(?P<last>[^,\s]+),\s+(\S+)");
+    /// let result = re.replace("Springsteen, Bruce", NoExpand("$2 $last"));
+    /// assert_eq!(result.as_slice(), "$2 $last");
+    /// # }
+    /// ```
+    pub fn replace<R: Replacer>(&self, text: &str, rep: R) -> StrBuf {
+        self.replacen(text, 1, rep)
+    }
+
+    /// Replaces all non-overlapping matches in `text` with the
+    /// replacement provided. This is the same as calling `replacen` with
+    /// `limit` set to `0`.
+    ///
+    /// See the documentation for `replace` for details on how to access
+    /// submatches in the replacement string.
+    pub fn replace_all<R: Replacer>(&self, text: &str, rep: R) -> StrBuf {
+        self.replacen(text, 0, rep)
+    }
+
+    /// Replaces all non-overlapping matches in `text` with `rep`, whose
+    /// literal text is cased like each match: upper case for a match in
+    /// upper case, title case for one in title case, and as written
+    /// otherwise. Groups in `rep` are expanded as usual, and what they
+    /// matched isn't changed. This is the same as calling `replace_all`
+    /// with `PreserveCase(rep)`, whose documentation says how the case of a
+    /// match is decided.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"(?i)the (\w+)");
+    /// let got = re.replace_all_preserving_case("THE CAT, The dog", "a $1");
+    /// assert_eq!(got.as_slice(), "A CAT, A dog");
+    /// # }
+    /// ```
+    pub fn replace_all_preserving_case(&self, text: &str, rep: &str)
+                                      -> StrBuf {
+        self.replace_all(text, template::PreserveCase(rep))
+    }
+
+    /// Replaces at most `limit` non-overlapping matches in `text` with the
+    /// replacement provided. If `limit` is 0, then all non-overlapping matches
+    /// are replaced.
+    ///
+    /// See the documentation for `replace` for details on how to access
+    /// submatches in the replacement string.
+    pub fn replacen<R: Replacer>
+                   (&self, text: &str, limit: uint, rep: R) -> StrBuf {
+        let (new, _) = self.replacen_count(text, limit, rep);
+        new
+    }
+
+    /// Replaces at most `limit` non-overlapping matches in `text` with the
+    /// replacement provided (or all of them, if `limit` is `0`), exactly
+    /// like `replacen`. Along with the new string, the number of matches
+    /// that were replaced is returned. (When it's `0`, the new string is a
+    /// copy of `text`, but a replacement may also leave the text as it was.)
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\d+");
+    /// let (new, n) = re.replacen_count("1 22 333", 2, "#");
+    /// assert_eq!((new.as_slice(), n), ("# # 333", 2));
+    /// # }
+    /// ```
+    pub fn replacen_count<R: Replacer>
+                         (&self, text: &str, limit: uint, rep: R)
+                         -> (StrBuf, uint) {
+        let mut new = StrBuf::with_capacity(text.len());
+        let n = self.push_replacen(&mut new, text, limit, rep);
+        (new, n)
+    }
+
+    /// Replaces all non-overlapping matches in `text` with the replacement
+    /// provided, and appends the result to `dst`. This is the same as
+    /// `replace_all`, except that the caller provides the buffer. When
+    /// several replacements are applied one after the other, reusing two
+    /// buffers avoids allocating a new one for every replacement.
+    ///
+    /// Note that `text` can't be a slice of `dst`, since `dst` must be
+    /// borrowed mutably.
+    ///
+    /// The number of matches replaced is returned.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let (mut a, mut b) = (StrBuf::from_str("a-b c-d"), StrBuf::new());
+    /// regex!(r"(\w)-(\w)").push_replace_all(&mut b, a.as_slice(), "$2$1");
+    /// a.truncate(0);
+    /// regex!(r" ").push_replace_all(&mut a, b.as_slice(), "+");
+    /// assert_eq!(a.as_slice(), "ba+dc");
+    /// # }
+    /// ```
+    pub fn push_replace_all<R: Replacer>
+                           (&self, dst: &mut StrBuf, text: &str, rep: R)
+                           -> uint {
+        self.push_replacen(dst, text, 0, rep)
+    }
+
+    /// Replaces at most `limit` non-overlapping matches in `text` with the
+    /// replacement provided, and appends the result to `dst`. If `limit` is
+    /// 0, then all non-overlapping matches are replaced.
+    ///
+    /// The number of matches replaced is returned.
+    pub fn push_replacen<R: Replacer>
+                        (&self, dst: &mut StrBuf, text: &str, limit: uint,
+                         mut rep: R) -> uint {
+        let mut last_match = 0u;
+        let mut i = 0;
+        // When the replacement doesn't depend on the match, there's no need
+        // to find capture groups.
+        match rep.no_expand() {
+            None => {}
+            Some(fixed) => {
+                for (s, e) in self.find_iter(text) {
+                    if limit > 0 && i >= limit {
+                        break
+                    }
+                    i += 1;
+                    dst.push_str(text.slice(last_match, s));
+                    dst.push_str(fixed.as_slice());
+                    last_match = e;
+                }
+                dst.push_str(text.slice(last_match, text.len()));
+                return i
+            }
+        }
+        for cap in self.captures_iter(text) {
+            // It'd be nicer to use the 'take' iterator instead, but it seemed
+            // awkward given that '0' => no limit.
+            if limit > 0 && i >= limit {
+                break
+            }
+            i += 1;
+
+            let (s, e) = cap.pos(0).unwrap(); // captures only reports matches
+            dst.push_str(text.slice(last_match, s));
+            dst.push_str(rep.reg_replace(&cap).as_slice());
+            last_match = e;
+        }
+        dst.push_str(text.slice(last_match, text.len()));
+        i
+    }
+
+    /// Returns `text` with every successive non-overlapping match replaced
+    /// by what `wrap` returns for it, which is usually the match surrounded
+    /// by markers (e.g., ANSI color codes or `<mark>` tags). `wrap` is given
+    /// the index of the group (`0` for the match) and its text.
+    ///
+    /// When `groups` is true, each capture group that took part in the match
+    /// is wrapped too, innermost first: the text given to `wrap` for a group
+    /// is its text with the groups inside it already wrapped. A group whose
+    /// span is the same as another's is inside it when its index is higher.
+    /// Groups that didn't take part in the match aren't wrapped, and neither
+    /// are groups that aren't inside the match (as with lookahead or `\K`)
+    /// or that cross the end of a group they start in.
+    ///
+    /// An empty match (or group) is wrapped too, so `wrap` is called with
+    /// the empty string where it matched.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"(\w+)=(\d*)").unwrap();
+    /// let got = re.annotate("a=1 b=", true, |i, s| {
+    ///     format!("\\{{}:{}\\}", i, s)
+    /// });
+    /// assert_eq!(got.as_slice(), "{0:{1:a}={2:1}} {0:{1:b}={2:}}");
+    /// ```
+    pub fn annotate(&self, text: &str, groups: bool,
+                    mut wrap: |uint, &str| -> ~str) -> StrBuf {
+        let mut dst = StrBuf::with_capacity(text.len());
+        let mut last_match = 0;
+        if !groups {
+            for (s, e) in self.find_iter(text) {
+                dst.push_str(text.slice(last_match, s));
+                dst.push_str(wrap(0, text.slice(s, e)).as_slice());
+                last_match = e;
+            }
+            dst.push_str(text.slice(last_match, text.len()));
+            return dst
+        }
+        let mut spans = vec!();
+        for cap in self.captures_iter(text) {
+            let (s, e) = cap.pos(0).unwrap();
+            spans.clear();
+            for i in range(0, cap.len()) {
+                match cap.pos(i) {
+                    Some((gs, ge)) if s <= gs && ge <= e => {
+                        spans.push((gs, ge, i))
+                    }
+                    _ => {}
+                }
+            }
+            // Outer groups come before the groups inside them.
+            spans.sort_by(|&(s1, e1, i1), &(s2, e2, i2)| {
+                (s1, e2, i1).cmp(&(s2, e1, i2))
+            });
+            dst.push_str(text.slice(last_match, s));
+            let mut k = 0;
+            dst.push_str(annotate_group(text, spans.as_slice(), &mut k,
+                                        &mut wrap).as_slice());
+            last_match = e;
+        }
+        dst.push_str(text.slice(last_match, text.len()));
+        dst
+    }
+
+    /// Returns `text` with `before` and `after` around every successive
+    /// non-overlapping match, like `annotate` without its groups.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"\d+").unwrap();
+    /// let got = re.annotate_markers("a1b22", "<mark>", "</mark>");
+    /// assert_eq!(got.as_slice(), "a<mark>1</mark>b<mark>22</mark>");
+    /// ```
+    pub fn annotate_markers(&self, text: &str, before: &str, after: &str)
+                           -> StrBuf {
+        self.annotate(text, false, |_, s| format!("{}{}{}", before, s, after))
+    }
+
+    /// Replaces all non-overlapping matches in the text read from `src` with
+    /// the replacement provided, and writes the result to `dst`. The result
+    /// is the same as calling `replace_all` on the entire text, but the text
+    /// is read and searched in chunks, so it's never held in memory all at
+    /// once. Matches are never missed or split between chunks, and `^`, `$`
+    /// and `\b` (in any mode) only match where they would in the entire
+    /// text.
+    ///
+    /// When the regex puts no bound on the length of its matches (e.g.,
+    /// `a.*b`), `max_len` bounds the text held to decide a match that may
+    /// still grow: once such a match may be longer than `max_len` bytes,
+    /// an error is returned rather than a match that may be cut short or
+    /// missed. (Such a regex can't use backreferences, `\K` or `\Z`.) For
+    /// every other regex, `max_len` is ignored.
+    ///
+    /// An error is returned if reading or writing fails, if the text read
+    /// isn't valid UTF-8, or if a match may be longer than `max_len`.
+    /// Otherwise, the number of matches replaced is returned.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// use std::io::{MemReader, MemWriter};
+    ///
+    /// let re = regex!(r"(?m)^(\w+)=(\w+)$");
+    /// let mut src = MemReader::new(Vec::from_slice(bytes!("a=b\nc=d\n")));
+    /// let mut dst = MemWriter::new();
+    /// let n = re.stream_replace_all(&mut src, &mut dst, 0, "$2=$1");
+    /// assert_eq!(n.unwrap(), 2);
+    /// assert_eq!(dst.unwrap().as_slice(), bytes!("b=a\nd=c\n"));
+    /// # }
+    /// ```
+    pub fn stream_replace_all<R: Replacer>
+                             (&self, src: &mut Reader, dst: &mut Writer,
+                              max_len: uint, mut rep: R) -> IoResult<uint> {
+        stream::scan(self, src, max_len, |_, text, caps| {
+            match caps {
+                None => dst.write_str(text),
+                Some(caps) => dst.write_str(rep.reg_replace(caps).as_slice()),
+            }
+        })
+    }
+
+    /// Returns true if and only if the regex matches somewhere in the text
+    /// read from `src`, as if `is_match` had been called on all of it.
+    /// Reading stops as soon as a match is found.
+    ///
+    /// The text is read in large chunks and each chunk is searched with the
+    /// same engines as `is_match`, so this is nearly as fast as searching
+    /// the text in memory. Only enough of the previous chunk to finish the
+    /// matches that might start in it is kept. `max_len` bounds the length
+    /// of a match when the regex doesn't, exactly like in
+    /// `stream_replace_all`.
+    ///
+    /// An error is returned if reading fails, if the text read isn't valid
+    /// UTF-8, or if a match may be longer than `max_len`.
+    pub fn is_match_reader(&self, src: &mut Reader, max_len: uint)
+                          -> IoResult<bool> {
+        stream::is_match(self, src, max_len)
+    }
+
+    /// Returns the start and end positions of the non-overlapping matches in
+    /// the text read from `src`, in the order in which they appear, exactly
+    /// as `find_iter` would on the entire text. The positions are byte
+    /// offsets from the start of the stream. If `limit` is greater than `0`,
+    /// then at most `limit` matches are found and reading stops after the
+    /// last one.
+    ///
+    /// The text is read and searched in chunks, and matches that span
+    /// chunks are found. `max_len` bounds the length of a match when the
+    /// regex doesn't, exactly like in `stream_replace_all`. In either case,
+    /// the amount of text that is held in memory is bounded.
+    ///
+    /// An error is returned if reading fails, if the text read isn't valid
+    /// UTF-8, or if a match may be longer than `max_len`.
+    pub fn find_reader_all(&self, src: &mut Reader, limit: uint,
+                           max_len: uint) -> IoResult<Vec<(uint, uint)>> {
+        let mut matches = vec!();
+        try!(self.scan_reader(src, max_len, |s, e, _| {
+            matches.push((s, e));
+            limit == 0 || matches.len() < limit
+        }));
+        Ok(matches)
+    }
+
+    /// Returns true if and only if the regex matches somewhere in the text
+    /// made of the bytes of `segs`, one after the other, as if `is_match`
+    /// had been called on their concatenation. This is useful when the text
+    /// is already in memory but not in one piece (e.g., in the segments of
+    /// a ring buffer), since the segments aren't copied into one buffer.
+    ///
+    /// Matches, characters and required literals may span segments. The
+    /// segments are searched like the text of `is_match_reader`: `max_len`
+    /// bounds the length of a match when the regex doesn't. An error is
+    /// returned if the text isn't valid UTF-8, or if a match may be longer
+    /// than `max_len`.
+    pub fn is_match_segments(&self, segs: &[&[u8]], max_len: uint)
+                            -> IoResult<bool> {
+        stream::is_match_segments(self, segs, max_len)
+    }
+
+    /// Returns the start and end positions of the non-overlapping matches in
+    /// the text made of the bytes of `segs`, one after the other, exactly as
+    /// `find_iter` would on their concatenation. The positions are byte
+    /// offsets from the start of the first segment. If `limit` is greater
+    /// than `0`, then at most `limit` matches are found.
+    ///
+    /// `max_len` bounds the length of a match when the regex doesn't,
+    /// exactly like in `find_reader_all`. An error is returned if the text
+    /// isn't valid UTF-8, or if a match may be longer than `max_len`.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// let re = regex!(r"\w+");
+    /// // The `é` is split between the segments.
+    /// let text = "ab cé d".as_bytes();
+    /// let segs = &[text.slice_to(5), text.slice_from(5)];
+    /// assert_eq!(re.find_segments(segs, 0, 64).unwrap(),
+    ///            vec!((0, 2), (3, 6), (7, 8)));
+    /// # }
+    /// ```
+    pub fn find_segments(&self, segs: &[&[u8]], limit: uint, max_len: uint)
+                        -> IoResult<Vec<(uint, uint)>> {
+        stream::find_segments(self, segs, limit, max_len)
+    }
+
+    /// Calls `f` with the start and end positions (in the stream) and the
+    /// text of every non-overlapping match in the text read from `src`. If
+    /// `f` returns false, then searching stops.
+    ///
+    /// This is the same as `find_reader_all`, except that the matches aren't
+    /// collected.
+    ///
+    /// # Example
+    ///
+    /// Print the line numbers of the lines containing `TODO`, without
+    /// reading the whole file into memory:
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// use std::io::MemReader;
+    ///
+    /// let text = bytes!("a\n// TODO\nb\nTODO: c\n");
+    /// let mut src = MemReader::new(Vec::from_slice(text));
+    /// let (re, mut lines) = (regex!(r"(?m)^.*TODO.*$|\n"), 1);
+    /// re.scan_reader(&mut src, 1024, |_, _, m| {
+    ///     if m == "\n" {
+    ///         lines += 1;
+    ///     } else {
+    ///         println!("{}: {}", lines, m);
+    ///     }
+    ///     true
+    /// }).unwrap();
+    /// # }
+    /// ```
+    pub fn scan_reader(&self, src: &mut Reader, max_len: uint,
+                       f: |uint, uint, &str| -> bool) -> IoResult<()> {
+        stream::scan_matches(self, src, max_len, f)
+    }
+
+    /// Calls `f` with the number (counting from 1), the text and the
+    /// location of the first match of every line read from `src` that this
+    /// regex matches, until `f` returns false. The number of lines that `f`
+    /// was called with is returned.
+    ///
+    /// Each line is searched on its own, without its `\n`, as if it was all
+    /// of the text, so `^` and `$` match at its start and end (and `\r` is
+    /// left at the end of a line that ends with `\r\n`). With
+    /// `opts.invert`, the lines that don't match are given to `f` instead
+    /// (like `grep -v`), without a location. A line that's longer than
+    /// `opts.max_line_len` bytes is dealt with as `opts.long_lines` says, so
+    /// the amount of text held in memory is bounded.
+    ///
+    /// The text is read and searched in large chunks. Where this regex
+    /// requires literals (see `explain_prefilter`), they're searched for
+    /// across each chunk, and only the lines that contain one are searched
+    /// by the regex. An error is returned if reading fails, if the text read
+    /// isn't valid UTF-8 or if a line is too long (unless it's skipped or
+    /// truncated).
+    ///
+    /// # Example
     ///
     /// ```rust
     /// # #![feature(phase)]
     /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
     /// # fn main() {
-    /// let re = regex!(r"(?P<last>[^,\s]+),\s+(?P<first>\S+)");
-    /// let result = re.replace("Springsteen, Bruce", "$first $last");
-    /// assert_eq!(result.as_slice(), "Bruce Springsteen");
+    /// use std::io::MemReader;
+    /// use regex::GrepOptions;
+    ///
+    /// let text = bytes!("a\n// TODO\nb\nTODO: c\n");
+    /// let mut src = MemReader::new(Vec::from_slice(text));
+    /// let mut found = vec!();
+    /// regex!("TODO").grep(&mut src, &GrepOptions::new(), |n, line, loc| {
+    ///     found.push((n, line.to_owned(), loc.unwrap()));
+    ///     true
+    /// }).unwrap();
+    /// assert_eq!(found, vec!((2, ~"// TODO", (3, 7)),
+    ///                        (4, ~"TODO: c", (0, 4))));
     /// # }
     /// ```
+    pub fn grep(&self, src: &mut Reader, opts: &grep::GrepOptions,
+                f: |uint, &str, Option<(uint, uint)>| -> bool)
+               -> IoResult<uint> {
+        grep::grep(self, src, opts, true, f)
+    }
+
+    /// Returns the number of lines read from `src` that this regex matches
+    /// (or doesn't, with `opts.invert`), like `grep -c`. The lines are
+    /// searched like they are by `grep`, except that only whether each line
+    /// matches is found.
+    pub fn grep_count(&self, src: &mut Reader, opts: &grep::GrepOptions)
+                     -> IoResult<uint> {
+        grep::grep(self, src, opts, false, |_, _, _| true)
+    }
+
+    /// Returns a matcher that's fed the text to search in pieces, as they
+    /// arrive (e.g., from a protocol parser that doesn't read from a
+    /// `Reader`), and tells after each piece whether the first match has
+    /// been found, whether one may still be found or whether there can't be
+    /// one (see `MatchStatus`). `Matcher::close` ends the text, which
+    /// decides matches that depend on it ending (like those of `a$`).
     ///
-    /// Note that using `$2` instead of `$first` or `$1` instead of `$last`
-    /// would produce the same result. To write a literal `$` use `$$`.
+    /// The match found is the one `find` would find in all of the text, and
+    /// its offsets are counted from the start of all of it. The matcher
+    /// keeps the state of the NFA simulation between pieces rather than the
+    /// text, so each byte is looked at once and at most one character (and
+    /// the bytes of a character cut off at the end of a piece) is kept, no
+    /// matter how far back the match starts. Since that's bounded, there's
+    /// no limit on the bytes kept to configure.
     ///
-    /// Finally, sometimes you just want to replace a literal string with no
-    /// submatch expansion. This can be done by wrapping a string with
-    /// `NoExpand`:
+    /// An error is returned for regexes that need more of the text than
+    /// that: those that use lookaround, backreferences, `\K`, `\Z`, `\b{g}`
+    /// or `\b{wb}`, and regexes compiled with the `regex!` macro.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::{Regex, Matched, NoMatchYet, CannotMatch};
+    /// let re = Regex::new(r"\bid=\d+$").unwrap();
+    /// let mut m = re.matcher().unwrap();
+    /// assert_eq!(m.feed(bytes!("x id=")), Ok(NoMatchYet));
+    /// assert_eq!(m.feed(bytes!("42")), Ok(NoMatchYet));
+    /// assert_eq!(m.close(), Ok(Matched(2, 7)));
+    ///
+    /// let mut m = Regex::new(r"^GET ").unwrap().matcher().unwrap();
+    /// assert_eq!(m.feed(bytes!("PUT /")), Ok(CannotMatch));
+    /// ```
+    pub fn matcher<'r>(&'r self)
+                      -> Result<matcher::Matcher<'r>, matcher::MatcherError> {
+        matcher::new(self)
+    }
+
+    /// Returns a reader of the substrings of the text read from `src` that
+    /// are delimited by a match of the regular expression. The fields are
+    /// exactly what `split` would yield for the entire text, but the text is
+    /// read and searched in chunks, so it's never held in memory all at
+    /// once. Delimiters that span chunks are found.
+    ///
+    /// `max_len` bounds the length of a delimiter when the regex doesn't,
+    /// exactly like in `stream_replace_all`. The length of a field is
+    /// bounded separately with `Splitter::set_max_field_len`.
+    ///
+    /// # Example
     ///
     /// ```rust
     /// # #![feature(phase)]
     /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
     /// # fn main() {
-    /// use regex::NoExpand;
+    /// use std::io::MemReader;
     ///
-    /// let re = regex!(r"(?P<last>[^,\s]+),\s+(\S+)");
-    /// let result = re.replace("Springsteen, Bruce", NoExpand("$2 $last"));
-    /// assert_eq!(result.as_slice(), "$2 $last");
+    /// let src = MemReader::new(Vec::from_slice(bytes!("a\r\nb\n\nc")));
+    /// let mut fields = regex!(r"\r?\n").splitter(src, 0);
+    /// assert_eq!(fields.next_field().unwrap(), "a".to_owned());
+    /// assert_eq!(fields.next_field().unwrap(), "b".to_owned());
+    /// assert_eq!(fields.next_field().unwrap(), "".to_owned());
+    /// assert_eq!(fields.next_field().unwrap(), "c".to_owned());
+    /// assert!(fields.next_field().is_err());
     /// # }
     /// ```
-    pub fn replace<R: Replacer>(&self, text: &str, rep: R) -> StrBuf {
-        self.replacen(text, 1, rep)
+    pub fn splitter<'r, R: Reader>(&'r self, src: R, max_len: uint)
+                                  -> Splitter<'r, R> {
+        stream::splitter(self, src, max_len, false)
     }
 
-    /// Replaces all non-overlapping matches in `text` with the
-    /// replacement provided. This is the same as calling `replacen` with
-    /// `limit` set to `0`.
+    /// Returns a reader of the matches of the regular expression in the text
+    /// read from `src`. It's like `splitter`, except that the fields are the
+    /// text of the matches that `find_iter` would find in the entire text,
+    /// and the text between them is dropped. Matches that span the chunks
+    /// in which the text is read are found.
     ///
-    /// See the documentation for `replace` for details on how to access
-    /// submatches in the replacement string.
-    pub fn replace_all<R: Replacer>(&self, text: &str, rep: R) -> StrBuf {
-        self.replacen(text, 0, rep)
+    /// When the regex doesn't bound the length of its matches, `max_len`
+    /// bounds it instead, exactly like in `stream_replace_all`: a token that
+    /// may be longer is an error rather than cut short. (For every other
+    /// regex, tokens of any length are found.) `Splitter::set_max_field_len`
+    /// bounds the length of the tokens returned whatever the regex.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// use std::io::MemReader;
+    ///
+    /// let src = MemReader::new(Vec::from_slice(bytes!("x = 12+y")));
+    /// let mut tokens = regex!(r"\w+|[=+]").tokenizer(src, 64);
+    /// tokens.set_max_field_len(64);
+    /// assert_eq!(tokens.next_field().unwrap(), "x".to_owned());
+    /// assert_eq!(tokens.next_field().unwrap(), "=".to_owned());
+    /// assert_eq!(tokens.next_field().unwrap(), "12".to_owned());
+    /// # }
+    /// ```
+    pub fn tokenizer<'r, R: Reader>(&'r self, src: R, max_len: uint)
+                                   -> Splitter<'r, R> {
+        stream::splitter(self, src, max_len, true)
     }
 
-    /// Replaces at most `limit` non-overlapping matches in `text` with the
-    /// replacement provided. If `limit` is 0, then all non-overlapping matches
-    /// are replaced.
+    /// Returns a writer that replaces all non-overlapping matches in the
+    /// text written to it with the replacement provided, and writes the
+    /// result to `w`. This is the incremental form of `stream_replace_all`:
+    /// it can be placed in a chain of writers, and the text can be given to
+    /// it in pieces of any size. Once all of the text has been written,
+    /// `finish` must be called to replace the matches in the text that it
+    /// held back, since it couldn't yet be decided whether a match there
+    /// would continue.
     ///
-    /// See the documentation for `replace` for details on how to access
-    /// submatches in the replacement string.
-    pub fn replacen<R: Replacer>
-                   (&self, text: &str, limit: uint, mut rep: R) -> StrBuf {
-        let mut new = StrBuf::with_capacity(text.len());
-        let mut last_match = 0u;
-        let mut i = 0;
-        for cap in self.captures_iter(text) {
-            // It'd be nicer to use the 'take' iterator instead, but it seemed
-            // awkward given that '0' => no limit.
-            if limit > 0 && i >= limit {
-                break
-            }
-            i += 1;
-
-            let (s, e) = cap.pos(0).unwrap(); // captures only reports matches
-            new.push_str(text.slice(last_match, s));
-            new.push_str(rep.reg_replace(&cap).as_slice());
-            last_match = e;
-        }
-        new.append(text.slice(last_match, text.len()))
+    /// `max_len` has the same meaning as in `stream_replace_all`.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # #![feature(phase)]
+    /// # extern crate regex; #[phase(syntax)] extern crate regex_macros;
+    /// # fn main() {
+    /// use std::io::MemWriter;
+    ///
+    /// let re = regex!(r"\bcat\b");
+    /// let mut w = re.replace_writer(MemWriter::new(), 0, "dog");
+    /// w.write_str("a c").unwrap();
+    /// w.write_str("at and a cats").unwrap();
+    /// assert_eq!(w.finish().unwrap(), 1);
+    /// assert_eq!(w.unwrap().unwrap().as_slice(), bytes!("a dog and a cats"));
+    /// # }
+    /// ```
+    pub fn replace_writer<'r, R: Replacer, W: Writer>
+                         (&'r self, w: W, max_len: uint, rep: R)
+                         -> ReplaceWriter<'r, R, W> {
+        stream::replace_writer(self, w, max_len, rep)
     }
 }
 
@@ -512,6 +3142,14 @@ pub trait Replacer {
     /// The `'a` lifetime refers to the lifetime of a borrowed string when
     /// a new owned string isn't needed (e.g., for `NoExpand`).
     fn reg_replace<'a>(&'a mut self, caps: &Captures) -> MaybeOwned<'a>;
+
+    /// Returns the replacement if it's the same for every match, in which
+    /// case the capture groups of the matches aren't computed at all.
+    ///
+    /// By default, this returns `None`.
+    fn no_expand<'a>(&'a mut self) -> Option<MaybeOwned<'a>> {
+        None
+    }
 }
 
 impl<'t> Replacer for NoExpand<'t> {
@@ -519,12 +3157,25 @@ impl<'t> Replacer for NoExpand<'t> {
         let NoExpand(s) = *self;
         Slice(s)
     }
+
+    fn no_expand<'a>(&'a mut self) -> Option<MaybeOwned<'a>> {
+        let NoExpand(s) = *self;
+        Some(Slice(s))
+    }
 }
 
 impl<'t> Replacer for &'t str {
     fn reg_replace<'a>(&'a mut self, caps: &Captures) -> MaybeOwned<'a> {
         Owned(caps.expand(*self).into_owned())
     }
+
+    fn no_expand<'a>(&'a mut self) -> Option<MaybeOwned<'a>> {
+        if self.contains_char('$') {
+            None
+        } else {
+            Some(Slice(*self))
+        }
+    }
 }
 
 impl<'a> Replacer for |&Captures|: 'a -> ~str {
@@ -592,6 +3243,71 @@ impl<'r, 't> Iterator<&'t str> for RegexSplitsN<'r, 't> {
     }
 }
 
+/// Yields the substrings delimited by a regular expression match, along with
+/// the text matched by the capture groups of each delimiter.
+///
+/// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
+/// of the string being split.
+pub struct RegexSplitsKeep<'r, 't> {
+    finder: FindCaptures<'r, 't>,
+    last: uint,
+    // The last delimiter, until all of its groups have been yielded.
+    delim: Option<Captures<'t>>,
+    // The next group of the last delimiter to yield.
+    group: uint,
+    // The number of substrings yielded.
+    cur: uint,
+    limit: Option<uint>,
+}
+
+impl<'r, 't> Iterator<&'t str> for RegexSplitsKeep<'r, 't> {
+    fn next(&mut self) -> Option<&'t str> {
+        let text = self.finder.search;
+        let group = match self.delim {
+            Some(ref caps) if self.group < caps.len() => {
+                Some(caps.at(self.group))
+            }
+            _ => None,
+        };
+        match group {
+            Some(group) => {
+                self.group += 1;
+                return Some(group)
+            }
+            None => self.delim = None,
+        }
+        match self.limit {
+            Some(limit) if self.cur >= limit => return None,
+            Some(limit) if self.cur + 1 == limit => {
+                self.cur += 1;
+                return Some(text.slice(self.last, text.len()))
+            }
+            _ => {}
+        }
+        match self.finder.next() {
+            None => {
+                if self.last >= text.len() {
+                    None
+                } else {
+                    let s = text.slice(self.last, text.len());
+                    self.last = text.len();
+                    self.cur += 1;
+                    Some(s)
+                }
+            }
+            Some(caps) => {
+                let (s, e) = caps.pos(0).unwrap();
+                let matched = text.slice(self.last, s);
+                self.last = e;
+                self.delim = Some(caps);
+                self.group = 1;
+                self.cur += 1;
+                Some(matched)
+            }
+        }
+    }
+}
+
 /// Captures represents a group of captured strings for a single match.
 ///
 /// The 0th capture always corresponds to the entire match. Each subsequent
@@ -606,7 +3322,9 @@ impl<'r, 't> Iterator<&'t str> for RegexSplitsN<'r, 't> {
 pub struct Captures<'t> {
     text: &'t str,
     locs: CaptureLocs,
-    named: Option<HashMap<~str, uint>>,
+    // The groups with each name. (Groups in different branches of an
+    // alternation may share a name.)
+    named: Option<HashMap<~str, Vec<uint>>>,
 }
 
 impl<'t> Captures<'t> {
@@ -625,7 +3343,8 @@ impl<'t> Captures<'t> {
                     match name {
                         &None => {},
                         &Some(ref name) => {
-                            named.insert(name.to_owned(), i);
+                            named.find_or_insert(name.to_owned(), vec!())
+                                 .push(i);
                         }
                     }
                 }
@@ -667,18 +3386,76 @@ impl<'t> Captures<'t> {
     /// Returns the matched string for the capture group named `name`.
     /// If `name` isn't a valid capture group or didn't match anything, then
     /// the empty string is returned.
+    ///
+    /// When groups in different branches share the name, the one that took
+    /// part in the match is used.
     pub fn name(&self, name: &str) -> &'t str {
-        match self.named {
+        match self.name_index(name) {
             None => "",
+            Some(i) => self.at(i),
+        }
+    }
+
+    /// Returns the index of the capture group named `name` that took part in
+    /// the match. If there is no such group, or it didn't take part in the
+    /// match, then `None` is returned.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::Regex;
+    /// let re = Regex::new(r"(?P<n>\d+)px|(?P<n>\d+)%").unwrap();
+    /// let caps = re.captures("50%").unwrap();
+    /// assert_eq!(caps.name_index("n"), Some(2));
+    /// assert_eq!(caps.name("n"), "50");
+    /// ```
+    pub fn name_index(&self, name: &str) -> Option<uint> {
+        match self.named {
+            None => None,
             Some(ref h) => {
                 match h.find_equiv(&name) {
-                    None => "",
-                    Some(i) => self.at(*i),
+                    None => None,
+                    Some(groups) => {
+                        groups.iter().map(|&i| i)
+                              .find(|&i| self.pos(i).is_some())
+                    }
                 }
             }
         }
     }
 
+    /// Returns the matched string for every named capture group that took
+    /// part in the match, keyed by name. Groups without a name are left out,
+    /// and so are named groups that didn't take part in the match. (A group
+    /// that matched the empty string is present.)
+    pub fn named_map(&self) -> HashMap<~str, &'t str> {
+        let mut map = HashMap::new();
+        for (name, (s, e)) in self.named_pos_map().move_iter() {
+            map.insert(name, self.text.slice(s, e));
+        }
+        map
+    }
+
+    /// Returns the start and end positions of every named capture group that
+    /// took part in the match, keyed by name, like `named_map`.
+    pub fn named_pos_map(&self) -> HashMap<~str, (uint, uint)> {
+        let mut map = HashMap::new();
+        match self.named {
+            None => {}
+            Some(ref h) => {
+                for name in h.keys() {
+                    match self.name_index(name.as_slice()) {
+                        None => {}
+                        Some(i) => {
+                            map.insert(name.clone(), self.pos(i).unwrap());
+                        }
+                    }
+                }
+            }
+        }
+        map
+    }
+
     /// Creates an iterator of all the capture groups in order of appearance
     /// in the regular expression.
     pub fn iter(&'t self) -> SubCaptures<'t> {
@@ -703,114 +3480,339 @@ impl<'t> Captures<'t> {
     /// If `name` isn't a valid capture group (whether the name doesn't exist or
     /// isn't a valid index), then it is replaced with the empty string.
     ///
-    /// To write a literal `$` use `$$`.
+    /// To write a literal `$` use `$$`. The rest of the syntax is described
+    /// by `Template`, except that malformed references (like `${name`) are
+    /// kept as they are.
     pub fn expand(&self, text: &str) -> StrBuf {
-        // How evil can you get?
-        // FIXME: Don't use regexes for this. It's completely unnecessary.
-        let re = Regex::new(r"(^|[^$]|\b)\$(\w+)").unwrap();
-        let text = re.replace_all(text, |refs: &Captures| -> ~str {
-            let (pre, name) = (refs.at(1), refs.at(2));
-            pre + match from_str::<uint>(name) {
-                None => self.name(name).to_owned(),
-                Some(i) => self.at(i).to_owned(),
+        template::parse_lenient(text).expand(self)
+    }
+}
+
+impl<'t> Container for Captures<'t> {
+    /// Returns the number of captured groups.
+    #[inline]
+    fn len(&self) -> uint {
+        self.locs.len() / 2
+    }
+}
+
+/// An iterator over capture groups for a particular match of a regular
+/// expression.
+///
+/// `'t` is the lifetime of the matched text.
+pub struct SubCaptures<'t> {
+    idx: uint,
+    caps: &'t Captures<'t>,
+}
+
+impl<'t> Iterator<&'t str> for SubCaptures<'t> {
+    fn next(&mut self) -> Option<&'t str> {
+        if self.idx < self.caps.len() {
+            self.idx += 1;
+            Some(self.caps.at(self.idx - 1))
+        } else {
+            None
+        }
+    }
+}
+
+/// An iterator over capture group positions for a particular match of a
+/// regular expression.
+///
+/// Positions are byte indices in terms of the original string matched.
+///
+/// `'t` is the lifetime of the matched text.
+pub struct SubCapturesPos<'t> {
+    idx: uint,
+    caps: &'t Captures<'t>,
+}
+
+impl<'t> Iterator<Option<(uint, uint)>> for SubCapturesPos<'t> {
+    fn next(&mut self) -> Option<Option<(uint, uint)>> {
+        if self.idx < self.caps.len() {
+            self.idx += 1;
+            Some(self.caps.pos(self.idx - 1))
+        } else {
+            None
+        }
+    }
+}
+
+/// An iterator that yields all non-overlapping capture groups matching a
+/// particular regular expression. The iterator stops when no more matches can
+/// be found.
+///
+/// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
+/// of the matched string.
+pub struct FindCaptures<'r, 't> {
+    re: &'r Regex,
+    search: &'t str,
+    last_match: Option<uint>,
+    last_end: uint,
+}
+
+impl<'r, 't> Iterator<Captures<'t>> for FindCaptures<'r, 't> {
+    fn next(&mut self) -> Option<Captures<'t>> {
+        match next_captures(self.re, self.search,
+                            &mut self.last_end, &mut self.last_match) {
+            None => None,
+            Some(caps) => Captures::new(self.re, self.search, caps),
+        }
+    }
+}
+
+/// A cursor over all non-overlapping capture groups matching a particular
+/// regular expression. It finds exactly the same matches as `FindCaptures`,
+/// but instead of yielding a new `Captures` value for each match, the
+/// locations of the current match are kept in the cursor itself and replaced
+/// when the cursor advances. This avoids building a map of capture group
+/// names for every match.
+///
+/// The cursor can be dropped at any time.
+///
+/// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
+/// of the matched string.
+pub struct CaptureCursor<'r, 't> {
+    re: &'r Regex,
+    search: &'t str,
+    last_match: Option<uint>,
+    last_end: uint,
+    locs: CaptureLocs,
+}
+
+impl<'r, 't> CaptureCursor<'r, 't> {
+    /// Advances the cursor to the next match. Returns `false` when there are
+    /// no more matches, after which the cursor has no current match.
+    pub fn advance(&mut self) -> bool {
+        self.locs.clear();
+        next_captures_into(self.re, self.search, &mut self.last_end,
+                           &mut self.last_match, &mut self.locs)
+    }
+
+    /// Returns the start and end positions of the Nth capture group of the
+    /// current match. Returns `None` if there is no current match, if `i` is
+    /// not a valid capture group or if the capture group did not match
+    /// anything.
+    pub fn pos(&self, i: uint) -> Option<(uint, uint)> {
+        let (s, e) = (i * 2, i * 2 + 1);
+        if e >= self.locs.len() || self.locs.get(s).is_none() {
+            return None
+        }
+        Some((self.locs.get(s).unwrap(), self.locs.get(e).unwrap()))
+    }
+
+    /// Returns the matched string for the capture group `i` of the current
+    /// match. If there is no current match, or `i` isn't a valid capture
+    /// group or didn't match anything, then the empty string is returned.
+    pub fn at(&self, i: uint) -> &'t str {
+        match self.pos(i) {
+            None => "",
+            Some((s, e)) => self.search.slice(s, e),
+        }
+    }
+
+    /// Returns the matched string for the capture group named `name` of the
+    /// current match. If `name` isn't a valid capture group or didn't match
+    /// anything, then the empty string is returned. Like `Captures::name`,
+    /// the group used is the one with the name that took part in the match.
+    pub fn name(&self, name: &str) -> &'t str {
+        for (i, n) in self.re.names.iter().enumerate() {
+            match *n {
+                Some(ref n) if n.as_slice() == name
+                               && self.pos(i).is_some() => {
+                    return self.at(i)
+                }
+                _ => {}
             }
-        });
-        let re = Regex::new(r"\$\$").unwrap();
-        re.replace_all(text.as_slice(), NoExpand("$"))
+        }
+        ""
     }
-}
 
-impl<'t> Container for Captures<'t> {
-    /// Returns the number of captured groups.
-    #[inline]
-    fn len(&self) -> uint {
+    /// Returns the number of captured groups of the current match, or `0` if
+    /// there is no current match.
+    pub fn len(&self) -> uint {
         self.locs.len() / 2
     }
 }
 
-/// An iterator over capture groups for a particular match of a regular
-/// expression.
-///
-/// `'t` is the lifetime of the matched text.
-pub struct SubCaptures<'t> {
-    idx: uint,
-    caps: &'t Captures<'t>,
+// Finds the capture groups of the next match for `FindCaptures`.
+fn next_captures(re: &Regex, search: &str,
+                 last_end: &mut uint, last_match: &mut Option<uint>)
+                -> Option<CaptureLocs> {
+    let mut caps = vec!();
+    if next_captures_into(re, search, last_end, last_match, &mut caps) {
+        Some(caps)
+    } else {
+        None
+    }
 }
 
-impl<'t> Iterator<&'t str> for SubCaptures<'t> {
-    fn next(&mut self) -> Option<&'t str> {
-        if self.idx < self.caps.len() {
-            self.idx += 1;
-            Some(self.caps.at(self.idx - 1))
-        } else {
-            None
+// Appends the capture groups of the next match to `caps` for
+// `next_captures`, `CaptureCursor` and `push_captures_all`, which share
+// their semantics. Returns false, with nothing appended, if there are no
+// more matches.
+fn next_captures_into(re: &Regex, search: &str, last_end: &mut uint,
+                      last_match: &mut Option<uint>, caps: &mut CaptureLocs)
+                     -> bool {
+    let base = caps.len();
+    loop {
+        if *last_end > search.len() {
+            return false
+        }
+
+        exec_slice_into(re, Submatches, search, *last_end, search.len(),
+                        caps);
+        let m = match (*caps.get(base), *caps.get(base + 1)) {
+            (Some(s), Some(e)) => (s, e),
+            _ => {
+                caps.truncate(base);
+                return false
+            }
+        };
+        if accept_match(search, m, last_end, last_match) {
+            return true
         }
+        caps.truncate(base);
     }
 }
 
-/// An iterator over capture group positions for a particular match of a
-/// regular expression.
+/// An iterator over all non-overlapping matches for a particular string.
 ///
-/// Positions are byte indices in terms of the original string matched.
+/// The iterator yields a tuple of integers corresponding to the start and end
+/// of the match. The indices are byte offsets. The iterator stops when no more
+/// matches can be found.
 ///
-/// `'t` is the lifetime of the matched text.
-pub struct SubCapturesPos<'t> {
-    idx: uint,
-    caps: &'t Captures<'t>,
+/// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
+/// of the matched string.
+pub struct FindMatches<'r, 't> {
+    re: &'r Regex,
+    search: &'t str,
+    last_match: Option<uint>,
+    last_end: uint,
 }
 
-impl<'t> Iterator<Option<(uint, uint)>> for SubCapturesPos<'t> {
-    fn next(&mut self) -> Option<Option<(uint, uint)>> {
-        if self.idx < self.caps.len() {
-            self.idx += 1;
-            Some(self.caps.pos(self.idx - 1))
-        } else {
-            None
+impl<'r, 't> Iterator<(uint, uint)> for FindMatches<'r, 't> {
+    fn next(&mut self) -> Option<(uint, uint)> {
+        while self.last_end <= self.search.len() {
+            let m = match find_at(self.re, self.search, self.last_end) {
+                None => return None,
+                Some(m) => m,
+            };
+            if accept_match(self.search, m, &mut self.last_end,
+                            &mut self.last_match) {
+                return Some(m)
+            }
         }
+        None
     }
 }
 
-/// An iterator that yields all non-overlapping capture groups matching a
-/// particular regular expression. The iterator stops when no more matches can
-/// be found.
+/// An iterator over all non-overlapping matches for a particular string,
+/// which yields their start and end positions as character offsets. See
+/// `Regex::find_iter_chars`.
 ///
 /// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
 /// of the matched string.
-pub struct FindCaptures<'r, 't> {
+pub struct FindCharMatches<'r, 't> {
+    matches: FindMatches<'r, 't>,
+    offsets: CharOffsets<'t>,
+}
+
+impl<'r, 't> Iterator<(uint, uint)> for FindCharMatches<'r, 't> {
+    fn next(&mut self) -> Option<(uint, uint)> {
+        self.matches.next().map(|(s, e)| {
+            let s = self.offsets.get(s);
+            (s, self.offsets.get(e))
+        })
+    }
+}
+
+/// An iterator over all non-overlapping matches for a particular string,
+/// which yields their start and end positions along with the alternate of
+/// the regex's top-level alternation that each comes from. See
+/// `Regex::find_branch_iter`.
+///
+/// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
+/// of the matched string.
+pub struct FindBranches<'r, 't> {
+    matches: FindMatches<'r, 't>,
+    prog: Arc<Program>,
+}
+
+impl<'r, 't> Iterator<((uint, uint), Option<uint>)> for FindBranches<'r, 't> {
+    fn next(&mut self) -> Option<((uint, uint), Option<uint>)> {
+        let (re, text) = (self.matches.re, self.matches.search);
+        let prog = &*self.prog;
+        self.matches.next().map(|(s, e)| ((s, e), re.branch(prog, text, s)))
+    }
+}
+
+/// An iterator over the capture groups of all non-overlapping matches for a
+/// particular string, which yields their locations as character offsets.
+/// See `Regex::captures_iter_chars`.
+///
+/// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
+/// of the matched string.
+pub struct FindCharCaptures<'r, 't> {
     re: &'r Regex,
     search: &'t str,
     last_match: Option<uint>,
     last_end: uint,
+    offsets: CharOffsets<'t>,
 }
 
-impl<'r, 't> Iterator<Captures<'t>> for FindCaptures<'r, 't> {
-    fn next(&mut self) -> Option<Captures<'t>> {
-        if self.last_end > self.search.len() {
-            return None
+impl<'r, 't> Iterator<Vec<Option<(uint, uint)>>> for FindCharCaptures<'r, 't> {
+    fn next(&mut self) -> Option<Vec<Option<(uint, uint)>>> {
+        let caps = match next_captures(self.re, self.search,
+                                       &mut self.last_end,
+                                       &mut self.last_match) {
+            None => return None,
+            Some(caps) => caps,
+        };
+        let mut locs = Vec::with_capacity(caps.len() / 2);
+        for i in range(0, caps.len() / 2) {
+            locs.push(match (*caps.get(i * 2), *caps.get(i * 2 + 1)) {
+                (Some(s), Some(e)) => {
+                    let s = self.offsets.get(s);
+                    Some((s, self.offsets.get(e)))
+                }
+                _ => None,
+            });
         }
+        Some(locs)
+    }
+}
 
-        let caps = exec_slice(self.re, Submatches, self.search,
-                              self.last_end, self.search.len());
-        let (s, e) =
-            if !has_match(&caps) {
-                return None
-            } else {
-                (caps.get(0).unwrap(), caps.get(1).unwrap())
-            };
+// Converts byte offsets into a string to character offsets. The last offset
+// converted is remembered, so that the characters before it aren't counted
+// again. Since matches are found from left to right, converting all of
+// their offsets counts each character about once.
+struct CharOffsets<'t> {
+    text: &'t str,
+    byte: uint,
+    chars: uint,
+}
+
+impl<'t> CharOffsets<'t> {
+    fn new(text: &'t str) -> CharOffsets<'t> {
+        CharOffsets { text: text, byte: 0, chars: 0 }
+    }
 
-        // Don't accept empty matches immediately following a match.
-        // i.e., no infinite loops please.
-        if e - s == 0 && Some(self.last_end) == self.last_match {
-            self.last_end += 1;
-            return self.next()
+    fn get(&mut self, byte: uint) -> uint {
+        if byte >= self.byte {
+            self.chars += self.text.slice(self.byte, byte).char_len();
+            self.byte = byte;
+            self.chars
+        } else {
+            // A group can start before the end of the previous one (or, with
+            // `\K`, before the start of the match).
+            self.chars - self.text.slice(byte, self.byte).char_len()
         }
-        self.last_end = e;
-        self.last_match = Some(self.last_end);
-        Captures::new(self.re, self.search, caps)
     }
 }
 
-/// An iterator over all non-overlapping matches for a particular string.
+/// An iterator over all matches for a particular string, including the ones
+/// that overlap (see `find_overlapping_iter`).
 ///
 /// The iterator yields a tuple of integers corresponding to the start and end
 /// of the match. The indices are byte offsets. The iterator stops when no more
@@ -818,37 +3820,295 @@ impl<'r, 't> Iterator<Captures<'t>> for FindCaptures<'r, 't> {
 ///
 /// `'r` is the lifetime of the compiled expression and `'t` is the lifetime
 /// of the matched string.
-pub struct FindMatches<'r, 't> {
+pub struct FindOverlapping<'r, 't> {
     re: &'r Regex,
     search: &'t str,
-    last_match: Option<uint>,
-    last_end: uint,
+    next_start: uint,
 }
 
-impl<'r, 't> Iterator<(uint, uint)> for FindMatches<'r, 't> {
+impl<'r, 't> Iterator<(uint, uint)> for FindOverlapping<'r, 't> {
     fn next(&mut self) -> Option<(uint, uint)> {
-        if self.last_end > self.search.len() {
+        if self.next_start > self.search.len() {
             return None
         }
+        match find_at(self.re, self.search, self.next_start) {
+            None => {
+                self.next_start = self.search.len() + 1;
+                None
+            }
+            Some((s, e)) => {
+                self.next_start = next_char(self.search, s);
+                Some((s, e))
+            }
+        }
+    }
+}
 
-        let caps = exec_slice(self.re, Location, self.search,
-                              self.last_end, self.search.len());
-        let (s, e) =
-            if !has_match(&caps) {
-                return None
-            } else {
-                (caps.get(0).unwrap(), caps.get(1).unwrap())
-            };
+/// LazyRegex is a regex derived from another one (such as the regex that
+/// only matches all of a text), which is compiled the first time it's needed.
+///
+/// It's exported to support the `regex!` syntax extension. Do not use.
+#[doc(hidden)]
+pub struct LazyRegex {
+    re: Mutex<Option<Arc<Regex>>>,
+}
+
+impl LazyRegex {
+    /// Creates a derived regex that hasn't been compiled yet.
+    pub fn new() -> LazyRegex {
+        LazyRegex { re: Mutex::new(None) }
+    }
+
+    // Returns the derived regex, if it has been compiled.
+    fn compiled(&self) -> Option<Arc<Regex>> {
+        let guard = self.re.lock();
+        (*guard).clone()
+    }
+
+    // Returns the derived regex, compiling it with `init` if it hasn't been
+    // compiled yet.
+    fn get(&self, init: || -> Regex) -> Arc<Regex> {
+        let mut guard = self.re.lock();
+        let re: &mut Option<Arc<Regex>> = &mut *guard;
+        if re.is_none() {
+            *re = Some(Arc::new(init()));
+        }
+        re.get_ref().clone()
+    }
+}
+
+impl Clone for LazyRegex {
+    /// Clones share the derived regex once it has been compiled.
+    fn clone(&self) -> LazyRegex {
+        let guard = self.re.lock();
+        LazyRegex { re: Mutex::new((*guard).clone()) }
+    }
+}
+
+/// LazyProgram is the program of a native regex's expression (which a native
+/// regex doesn't have otherwise), compiled the first time it's needed, such
+/// as to tell which alternate a match comes from.
+///
+/// It's exported to support the `regex!` syntax extension. Do not use.
+#[doc(hidden)]
+pub struct LazyProgram {
+    prog: Mutex<Option<Arc<Program>>>,
+}
+
+impl LazyProgram {
+    /// Creates a program that hasn't been compiled yet.
+    pub fn new() -> LazyProgram {
+        LazyProgram { prog: Mutex::new(None) }
+    }
+
+    // Returns the program of the expression `original`, compiling it if it
+    // hasn't been compiled yet.
+    fn get(&self, original: &str) -> Arc<Program> {
+        let mut guard = self.prog.lock();
+        let prog: &mut Option<Arc<Program>> = &mut *guard;
+        if prog.is_none() {
+            // The expression was compiled successfully before.
+            let alts = parse::parse_alternates(original, FLAG_EMPTY,
+                                               parse::CompileLimits::new())
+                       .unwrap();
+            let (p, _) = Program::with_alternates(alts);
+            *prog = Some(Arc::new(p));
+        }
+        prog.get_ref().clone()
+    }
+}
+
+impl Clone for LazyProgram {
+    /// Clones share the program once it has been compiled.
+    fn clone(&self) -> LazyProgram {
+        let guard = self.prog.lock();
+        LazyProgram { prog: Mutex::new((*guard).clone()) }
+    }
+}
+
+/// LazySyntax is the syntax tree that a regex was compiled from. A regex
+/// compiled by the `regex!` macro (or decoded from bytes) only has its
+/// expression, which is parsed the first time the tree is needed.
+///
+/// It's exported to support the `regex!` syntax extension. Do not use.
+#[doc(hidden)]
+pub struct LazySyntax {
+    ast: Mutex<Option<Arc<~Ast>>>,
+}
+
+impl LazySyntax {
+    /// Creates a syntax tree that hasn't been parsed yet.
+    pub fn new() -> LazySyntax {
+        LazySyntax { ast: Mutex::new(None) }
+    }
+
+    // Keeps the syntax tree given.
+    fn with(ast: ~Ast) -> LazySyntax {
+        LazySyntax { ast: Mutex::new(Some(Arc::new(ast))) }
+    }
+
+    // Returns the syntax tree of the expression `original`, parsing it if it
+    // hasn't been parsed yet.
+    fn get(&self, original: &str) -> Arc<~Ast> {
+        let mut guard = self.ast.lock();
+        let ast: &mut Option<Arc<~Ast>> = &mut *guard;
+        if ast.is_none() {
+            // The expression of a native regex was parsed by the macro, and
+            // that of a decoded one was compiled before it was encoded.
+            *ast = Some(Arc::new(parse::parse(original).unwrap()));
+        }
+        ast.get_ref().clone()
+    }
+}
+
+impl Clone for LazySyntax {
+    /// Clones share the syntax tree once it has been parsed.
+    fn clone(&self) -> LazySyntax {
+        let guard = self.ast.lock();
+        LazySyntax { ast: Mutex::new((*guard).clone()) }
+    }
+}
+
+/// Compiles a dynamic regular expression given its AST. `original` is the
+/// expression reported as the one the regex was compiled from.
+pub fn from_ast(original: ~str, ast: ~parse::Ast) -> Regex {
+    from_alternates(original, vec!(ast))
+}
+
+/// Compiles a dynamic regular expression given the alternates of its
+/// top-level alternation (see `parse::parse_alternates`), so that its
+/// program tells which of them a match comes from (see `find_branch`).
+pub fn from_alternates(original: ~str, alts: Vec<~parse::Ast>) -> Regex {
+    let ast = parse::join_alternates(alts.clone());
+    let meta = Metadata::new(&*ast);
+    let rprog = Program::new_reverse(ast.clone());
+    let (prog, names) = Program::with_alternates(alts);
+    let mut re = from_program(original, names, prog, Some(rprog));
+    re.meta = MetaCache::with(meta);
+    re.ast = LazySyntax::with(ast);
+    re
+}
+
+/// Builds a dynamic regular expression from its compiled programs (e.g.,
+/// ones decoded by `encode::decode_regex`).
+pub fn from_program(original: ~str, names: ~[Option<~str>], prog: Program,
+                    rprog: Option<Program>) -> Regex {
+    let dfa = match rprog {
+        None => DfaCache::new(),
+        Some(rprog) => DfaCache::with_reverse(rprog),
+    };
+    Regex {
+        original: original,
+        names: names,
+        p: Dynamic(Arc::new(prog)),
+        dfa: dfa,
+        full: LazyRegex::new(),
+        offset: LazyRegex::new(),
+        longest: LazyRegex::new(),
+        meta: MetaCache::new(),
+        program: LazyProgram::new(),
+        ast: LazySyntax::new(),
+    }
+}
+
+/// Returns the program of `re`. A native regex has none at run time, so one
+/// is compiled from its expression the first time it's needed.
+pub fn program(re: &Regex) -> Arc<Program> {
+    match re.p {
+        Dynamic(ref prog) => prog.clone(),
+        Native(_) => re.native_program(),
+    }
+}
+
+/// Returns the capture groups of a match of `re`, given the locations of its
+/// groups. The names of the groups are looked up in `re`.
+pub fn captures_from_locs<'t>(re: &Regex, text: &'t str, locs: CaptureLocs)
+                             -> Option<Captures<'t>> {
+    Captures::new(re, text, locs)
+}
+
+// Returns the location of the leftmost-first match starting at or after `s`.
+// Unlike `exec_slice`, this doesn't allocate when the DFA finds the match.
+// A search that exceeds the step limit finds nothing.
+fn find_at(re: &Regex, input: &str, s: uint) -> Option<(uint, uint)> {
+    match try_find_at(re, input, s, None) {
+        Ok(found) => found,
+        Err(_) => None,
+    }
+}
+
+// Returns true if there's a match starting at or after `s`. Like `find_at`,
+// this doesn't allocate when the DFA (or an engine that's faster still)
+// finds the answer.
+fn try_is_match_at(re: &Regex, input: &str, s: uint, cancel: Option<&Cancel>)
+                  -> Result<bool, StepLimitExceeded> {
+    if cancel.is_none() {
+        match exec_hooked(re, Exists, input, s) {
+            Some(caps) => return Ok(has_match(&caps)),
+            None => {}
+        }
+    }
+    match re.p {
+        Dynamic(ref prog) => {
+            re.dfa.is_match(&**prog, input, s, input.len(), cancel)
+        }
+        Native(exec) => Ok(has_match(&exec(Exists, input, s, input.len(),
+                                           false))),
+    }
+}
+
+// Calls `each` with the index of every text in `texts` that `re` matches, in
+// order, until it returns false. The texts are only taken from `texts` as
+// they're searched.
+pub fn filter<S: Str, I: Iterator<S>>(re: &Regex, texts: I,
+                                      each: |uint| -> bool) {
+    match re.p {
+        Dynamic(ref prog) if re.dfa.hook().is_none() => {
+            re.dfa.filter(&**prog, texts, each)
+        }
+        Dynamic(_) => {
+            for (i, text) in texts.enumerate() {
+                let text = text.as_slice();
+                if try_is_match_at(re, text, 0, None) == Ok(true) && !each(i) {
+                    return
+                }
+            }
+        }
+        Native(exec) => {
+            for (i, text) in texts.enumerate() {
+                let text = text.as_slice();
+                let caps = exec(Exists, text, 0, text.len(), false);
+                if has_match(&caps) && !each(i) {
+                    return
+                }
+            }
+        }
+    }
+}
 
-        // Don't accept empty matches immediately following a match.
-        // i.e., no infinite loops please.
-        if e - s == 0 && Some(self.last_end) == self.last_match {
-            self.last_end += 1;
-            return self.next()
+fn try_find_at(re: &Regex, input: &str, s: uint, cancel: Option<&Cancel>)
+              -> Result<Option<(uint, uint)>, StepLimitExceeded> {
+    if cancel.is_none() {
+        match exec_hooked(re, Location, input, s) {
+            Some(ref caps) if has_match(caps) => {
+                return Ok(Some((caps.get(0).unwrap(), caps.get(1).unwrap())))
+            }
+            Some(_) => return Ok(None),
+            None => {}
+        }
+    }
+    match re.p {
+        Dynamic(ref prog) => {
+            re.dfa.find(&**prog, input, s, input.len(), cancel)
+        }
+        Native(exec) => {
+            let caps = exec(Location, input, s, input.len(), false);
+            if has_match(&caps) {
+                Ok(Some((caps.get(0).unwrap(), caps.get(1).unwrap())))
+            } else {
+                Ok(None)
+            }
         }
-        self.last_end = e;
-        self.last_match = Some(self.last_end);
-        Some((s, e))
     }
 }
 
@@ -856,12 +4116,320 @@ fn exec(re: &Regex, which: MatchKind, input: &str) -> CaptureLocs {
     exec_slice(re, which, input, 0, input.len())
 }
 
+// Like `try_exec_slice`, except that a search that exceeds the step limit
+// finds nothing.
 fn exec_slice(re: &Regex, which: MatchKind,
               input: &str, s: uint, e: uint) -> CaptureLocs {
+    match try_exec_slice(re, which, input, s, e, None) {
+        Ok(caps) => caps,
+        Err(_) => vec![None, None],
+    }
+}
+
+// Like `exec_slice`, except that the capture locations are appended to
+// `caps`.
+fn exec_slice_into(re: &Regex, which: MatchKind, input: &str, s: uint,
+                   e: uint, caps: &mut CaptureLocs) {
+    match try_exec_slice_into(re, which, input, s, e, None, caps) {
+        Ok(()) => {}
+        Err(_) => {
+            caps.push(None);
+            caps.push(None);
+        }
+    }
+}
+
+fn try_exec_slice(re: &Regex, which: MatchKind, input: &str, s: uint,
+                  e: uint, cancel: Option<&Cancel>)
+                 -> Result<CaptureLocs, StepLimitExceeded> {
+    let mut caps = vec!();
+    try!(try_exec_slice_into(re, which, input, s, e, cancel, &mut caps));
+    Ok(caps)
+}
+
+// Like `try_exec_slice`, except that the capture locations are appended to
+// `caps`, so that its memory can be reused by the caller. Nothing is
+// appended if the search is abandoned.
+fn try_exec_slice_into(re: &Regex, which: MatchKind, input: &str, s: uint,
+                       e: uint, cancel: Option<&Cancel>,
+                       caps: &mut CaptureLocs)
+                      -> Result<(), StepLimitExceeded> {
+    if e == input.len() && cancel.is_none() {
+        match exec_hooked(re, which, input, s) {
+            Some(found) => {
+                vm::push_locs(caps, found);
+                return Ok(())
+            }
+            None => {}
+        }
+    }
     match re.p {
-        Dynamic(ref prog) => vm::run(which, prog, input, s, e),
-        Native(exec) => exec(which, input, s, e),
+        Dynamic(ref prog) => {
+            // The one-pass engine doesn't count its steps (or know about
+            // leftmost-longest matches).
+            let prog = &**prog;
+            let limited = re.dfa.step_limit().is_some() || cancel.is_some()
+                          || re.dfa.longest();
+            match (which, &prog.onepass) {
+                (Submatches, &Some(ref onepass))
+                        if e == input.len() && !limited => {
+                    let base = caps.len();
+                    onepass.exec_into(prog, input, s, caps);
+                    count_onepass(re, s, e, caps.get(base).is_some());
+                    Ok(())
+                }
+                _ => re.dfa.exec_into(which, prog, input, s, e, cancel, caps),
+            }
+        }
+        Native(exec) => {
+            vm::push_locs(caps, exec(which, input, s, e, false));
+            Ok(())
+        }
+    }
+}
+
+// Searches for the leftmost-first match starting at or after `s` only at
+// the positions given by the regex's prefilter hook. `None` is returned if
+// there's no hook, or if it can't be used.
+fn exec_hooked(re: &Regex, which: MatchKind, input: &str, s: uint)
+              -> Option<CaptureLocs> {
+    let hook = match (re.dfa.hook(), &re.p) {
+        (Some(hook), &Dynamic(ref prog))
+                if !prog.keep && !prog.search_start => hook,
+        _ => return None,
+    };
+    let found = prefilter::find(hook, input, s, |at| {
+        match re.dfa.stats() {
+            None => {}
+            Some(stats) => stats.candidate(),
+        }
+        let caps = run_anchored(re, which, input, at, false);
+        if has_match(&caps) { Some((at, caps)) } else { None }
+    });
+    // The candidates are counted as one search, which examined the text up
+    // to the end of the match it found (only the start of the match is
+    // known for `Exists`), or all of it. (A hook that can't be used leaves
+    // the counting to the search that runs without it.)
+    let (end, caps) = match found {
+        None => return None,
+        Some(None) => (input.len(), vec![None, None]),
+        Some(Some((at, caps))) => {
+            let end = match (which, *caps.get(1)) {
+                (Exists, _) | (_, None) => at,
+                (_, Some(e)) => e,
+            };
+            (end, caps)
+        }
+    };
+    match re.dfa.stats() {
+        None => {}
+        Some(stats) => stats.search(end - s, has_match(&caps)),
+    }
+    Some(caps)
+}
+
+// Counts a search between `s` and `e` with the one-pass engine (which
+// doesn't go through the regex's cache), if searches are counted.
+fn count_onepass(re: &Regex, s: uint, e: uint, matched: bool) {
+    match re.dfa.stats() {
+        None => {}
+        Some(stats) => {
+            stats.search(e - s, matched);
+            stats.engine(EngineOnePass);
+        }
+    }
+}
+
+// Like `exec_slice`, except that only a match starting at `s` is found.
+fn exec_anchored(re: &Regex, which: MatchKind,
+                 input: &str, s: uint) -> CaptureLocs {
+    run_anchored(re, which, input, s, true)
+}
+
+// Like `exec_anchored`, but the search is only counted (if searches are
+// counted) when `counted` is true. Otherwise, only the engine that runs it
+// is.
+fn run_anchored(re: &Regex, which: MatchKind, input: &str, s: uint,
+                counted: bool) -> CaptureLocs {
+    let caps = match re.p {
+        Dynamic(ref prog) => {
+            let prog = &**prog;
+            let limited = re.dfa.step_limit().is_some() || re.dfa.longest();
+            match (which, &prog.onepass) {
+                // A one-pass program is anchored already.
+                (Submatches, &Some(ref onepass)) if !limited => {
+                    let caps = onepass.exec(prog, input, s);
+                    match re.dfa.stats() {
+                        Some(stats) if !counted => stats.engine(EngineOnePass),
+                        _ => {
+                            count_onepass(re, s, input.len(), has_match(&caps))
+                        }
+                    }
+                    Ok(caps)
+                }
+                _ if counted => {
+                    re.dfa.exec_anchored(which, prog, input, s, input.len(),
+                                         None)
+                }
+                _ => {
+                    re.dfa.exec_candidate(which, prog, input, s, input.len(),
+                                          None)
+                }
+            }
+        }
+        Native(exec) => Ok(exec(which, input, s, input.len(), true)),
+    };
+    match caps {
+        Ok(caps) => caps,
+        Err(_) => vec![None, None],
+    }
+}
+
+// Returns the leftmost-longest match starting at or after `s`. A search that
+// exceeds the step limit finds nothing.
+fn exec_longest(re: &Regex, which: MatchKind, input: &str, s: uint)
+               -> CaptureLocs {
+    let caps = match re.p {
+        Dynamic(ref prog) => {
+            re.dfa.exec_longest(which, &**prog, input, s, input.len(), None)
+        }
+        Native(_) => {
+            let longest = re.longest();
+            return exec_slice(&*longest, which, input, s, input.len())
+        }
+    };
+    match caps {
+        Ok(caps) => caps,
+        Err(_) => vec![None, None],
+    }
+}
+
+// Like `try_find_at`, except that the search stops when `cancel` is set. A
+// search that exceeds the step limit finds nothing, like `find_at`.
+fn find_at_cancel(re: &Regex, input: &str, s: uint, cancel: &Cancel)
+                 -> Result<Option<(uint, uint)>, Cancelled> {
+    // Native regexes only see the flag between searches.
+    if cancel.is_cancelled() {
+        return Err(Cancelled)
+    }
+    match try_find_at(re, input, s, Some(cancel)) {
+        Ok(found) => Ok(found),
+        Err(_) if cancel.is_cancelled() => Err(Cancelled),
+        Err(_) => Ok(None),
+    }
+}
+
+// Like `find_at_cancel`, but returns the capture groups of the match.
+fn captures_at_cancel<'t>(re: &Regex, input: &'t str, s: uint,
+                          cancel: &Cancel)
+                         -> Result<Option<Captures<'t>>, Cancelled> {
+    if cancel.is_cancelled() {
+        return Err(Cancelled)
+    }
+    let len = input.len();
+    match try_exec_slice(re, Submatches, input, s, len, Some(cancel)) {
+        Ok(caps) => Ok(Captures::new(re, input, caps)),
+        Err(_) if cancel.is_cancelled() => Err(Cancelled),
+        Err(_) => Ok(None),
+    }
+}
+
+// Rewrites every `^` and `\A` in an expression so that they also match at
+// the start of the search (which is what `\G` matches), and every lookbehind
+// so that it can't see the text before the start of the search.
+fn begin_at_search(ast: ~Ast) -> ~Ast {
+    match ast {
+        ~Begin(flags) if flags & FLAG_SEARCH > 0 => ~Begin(flags),
+        ~Begin(flags) if flags & FLAG_MULTI > 0 => {
+            ~Alt(~Begin(FLAG_SEARCH), ~Begin(flags))
+        }
+        ~Begin(_) => ~Begin(FLAG_SEARCH),
+        ~Capture(i, name, x) => ~Capture(i, name, begin_at_search(x)),
+        ~Atomic(x) => ~Atomic(begin_at_search(x)),
+        ~Cat(xs) => ~Cat(xs.move_iter().map(|x| begin_at_search(x)).collect()),
+        ~Alt(x, y) => ~Alt(begin_at_search(x), begin_at_search(y)),
+        ~Rep(x, op, g) => ~Rep(begin_at_search(x), op, g),
+        ~Lookaround(x, flags) if flags & FLAG_BEHIND > 0 => {
+            ~Lookaround(begin_at_search(x), flags | FLAG_SEARCH)
+        }
+        ~Lookaround(x, flags) => ~Lookaround(begin_at_search(x), flags),
+        ~Nothing => ~Nothing,
+        ~Literal(c, flags) => ~Literal(c, flags),
+        ~Dot(flags) => ~Dot(flags),
+        ~Class(ranges, flags) => ~Class(ranges, flags),
+        ~End(flags) => ~End(flags),
+        ~WordBoundary(flags) => ~WordBoundary(flags),
+        ~SegmentBoundary(seg, flags) => ~SegmentBoundary(seg, flags),
+        ~Backref(i, flags) => ~Backref(i, flags),
+        ~Keep => ~Keep,
+    }
+}
+
+// Returns the text of the group `spans[*k]` with the groups inside it
+// wrapped, and then the group itself, as described by `Regex::annotate`.
+// The spans are sorted so that the groups inside a group come right after
+// it. When done, `*k` is the index of the first span after the group.
+fn annotate_group(text: &str, spans: &[(uint, uint, uint)], k: &mut uint,
+                  wrap: &mut |uint, &str| -> ~str) -> ~str {
+    let (s, e, i) = spans[*k];
+    *k += 1;
+    let mut inner = StrBuf::new();
+    let mut last = s;
+    while *k < spans.len() {
+        let (gs, ge, _) = spans[*k];
+        if ge <= e {
+            inner.push_str(text.slice(last, gs));
+            inner.push_str(annotate_group(text, spans, k, wrap).as_slice());
+            last = ge;
+        } else if gs < e {
+            // The group crosses the end of this one, so it's left out.
+            *k += 1;
+        } else {
+            break
+        }
+    }
+    inner.push_str(text.slice(last, e));
+    (*wrap)(i, inner.as_slice())
+}
+
+// Fails unless `start` is a valid position to start searching `text` at.
+fn check_start(text: &str, start: uint) {
+    if start > text.len() || !text.is_char_boundary(start) {
+        fail!("start of search {} is not a character boundary of the text",
+              start);
+    }
+}
+
+/// Returns the position of the character following the one at `i` (or one
+/// past the end of `text`). Searching resumes there after an empty match, so
+/// that matches never start in the middle of a character. Every way of
+/// finding successive matches advances with this, so that they all agree.
+#[inline]
+pub fn next_char(text: &str, i: uint) -> uint {
+    if i < text.len() {
+        text.char_range_at(i).next
+    } else {
+        i + 1
+    }
+}
+
+/// Decides whether the match from `s` to `e`, found by searching from
+/// `*last_end`, is the next of the non-overlapping matches of a regex, where
+/// `*last_match` is where the previous one ended (if there was one). An empty
+/// match right after a match isn't, or it would be found again and again, so
+/// `*last_end` moves to the next character and `false` is returned. Otherwise
+/// the search resumes at the end of the match. Every way of finding
+/// successive matches decides with this, so that they all agree.
+#[inline]
+pub fn accept_match(text: &str, (s, e): (uint, uint), last_end: &mut uint,
+                    last_match: &mut Option<uint>) -> bool {
+    if s == e && Some(*last_end) == *last_match {
+        *last_end = next_char(text, *last_end);
+        return false
     }
+    *last_end = e;
+    *last_match = Some(e);
+    true
 }
 
 #[inline]
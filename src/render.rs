@@ -0,0 +1,385 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// Renders a syntax tree as an expression, which parses to a tree that
+// matches exactly the same way. (It isn't always the same tree: flags that
+// don't change how a node matches, like `m` on a literal, are dropped, and
+// capture groups are numbered in the order they appear.)
+//
+// Flags are set with groups like `(?i)` just before the first node that
+// needs them, and are tracked as the parser would track them: they last
+// until the end of the enclosing group, including into later alternates.
+// Only the flags that change how a node matches are looked at, so most
+// expressions are rendered without any (e.g., `(?m)a` is rendered as `a`).
+
+use parse;
+use parse::{Ast, Nothing, Literal, Dot, Class, Begin, End, WordBoundary};
+use parse::{SegmentBoundary, Lookaround, Backref, Keep, Atomic, Capture};
+use parse::{Cat, Alt, Rep, ZeroOne, ZeroMore, OneMore};
+use parse::{Flags, FLAG_EMPTY, FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL};
+use parse::{FLAG_NEGATED, FLAG_SEARCH, FLAG_ASCII, FLAG_UWORD, FLAG_BEHIND};
+use parse::FLAG_FINALNL;
+use segment::{Grapheme, Word};
+
+// The flags that can be set by the groups rendered, and their letters.
+// Unicode is on by default, so `FLAG_ASCII` is set by `-u` instead.
+static FLAG_NAMES: &'static [(Flags, char)] = &[
+    (FLAG_NOCASE, 'i'), (FLAG_MULTI, 'm'), (FLAG_DOTNL, 's'),
+    (FLAG_UWORD, 'w'),
+];
+
+// An expression that matches the empty string, since neither an empty
+// expression nor an empty group or alternate can be parsed.
+static EMPTY: &'static str = "a{0}";
+
+/// Renders the syntax tree given as an expression that matches exactly like
+/// it. An error is returned if the tree has a backreference to a group that
+/// isn't in it, or to an unnamed group after the ninth, which can't be
+/// written. (Any other tree that the parser wouldn't produce is rendered,
+/// and is then an error when the expression is parsed.)
+pub fn render(ast: &Ast) -> Result<~str, parse::Error> {
+    let mut groups = vec!();
+    capture_groups(ast, &mut groups);
+    let mut r = Renderer {
+        out: StrBuf::new(),
+        flags: FLAG_EMPTY,
+        groups: groups,
+        backref_end: None,
+    };
+    try!(r.expr(ast));
+    Ok(r.out.into_owned())
+}
+
+struct Renderer {
+    out: StrBuf,
+    // The flags set where the expression ends so far.
+    flags: Flags,
+    // The index and name of every capture group in the tree, in the order
+    // that they're rendered.
+    groups: Vec<(uint, Option<~str>)>,
+    // Where the last backreference rendered ends, so that a digit after it
+    // isn't read as part of it.
+    backref_end: Option<uint>,
+}
+
+impl Renderer {
+    // Renders an expression that may be an alternation, at the top or as
+    // the contents of a group.
+    fn expr(&mut self, ast: &Ast) -> Result<(), parse::Error> {
+        match *ast {
+            Alt(ref x, ref y) => {
+                // Alternations nest to the right when they're parsed, so one
+                // on the left needs a group of its own.
+                match **x {
+                    Alt(_, _) => try!(self.group("(?:", &**x)),
+                    _ => try!(self.expr(&**x)),
+                }
+                self.out.push_char('|');
+                self.expr(&**y)
+            }
+            _ if is_empty(ast) => {
+                self.out.push_str(EMPTY);
+                Ok(())
+            }
+            _ => self.item(ast),
+        }
+    }
+
+    // Renders an expression that's concatenated with whatever surrounds it.
+    fn item(&mut self, ast: &Ast) -> Result<(), parse::Error> {
+        match *ast {
+            Nothing => {}
+            Literal(c, flags) => {
+                self.set_flags(case_flags(flags));
+                let after_backref = self.backref_end == Some(self.out.len());
+                if after_backref && c.is_digit() {
+                    self.push_hex(c);
+                } else {
+                    self.push_char(c, false);
+                }
+            }
+            Dot(flags) => {
+                self.set_flags((FLAG_DOTNL, flags & FLAG_DOTNL));
+                self.out.push_char('.');
+            }
+            Class(ref ranges, flags) => {
+                self.set_flags(case_flags(flags));
+                self.class(ranges.as_slice(), flags & FLAG_NEGATED > 0);
+            }
+            Begin(flags) if flags & FLAG_SEARCH > 0 => {
+                self.out.push_str("\\G")
+            }
+            Begin(flags) => self.anchor(flags, '^', "\\A"),
+            End(flags) if flags & FLAG_FINALNL > 0 => self.out.push_str("\\Z"),
+            End(flags) => self.anchor(flags, '$', "\\z"),
+            WordBoundary(flags) => {
+                if flags & FLAG_ASCII > 0 {
+                    self.set_flags((FLAG_ASCII, FLAG_ASCII));
+                } else {
+                    self.set_flags((FLAG_ASCII | FLAG_UWORD,
+                                    flags & FLAG_UWORD));
+                }
+                let negated = flags & FLAG_NEGATED > 0;
+                self.out.push_str(if negated { "\\B" } else { "\\b" });
+            }
+            SegmentBoundary(seg, flags) => {
+                let negated = flags & FLAG_NEGATED > 0;
+                self.out.push_str(if negated { "\\B" } else { "\\b" });
+                self.out.push_str(match seg {
+                    Grapheme => "{g}",
+                    Word => "{wb}",
+                });
+            }
+            Lookaround(ref x, flags) => {
+                let open = match (flags & FLAG_BEHIND > 0,
+                                  flags & FLAG_NEGATED > 0) {
+                    (false, false) => "(?=",
+                    (false, true) => "(?!",
+                    (true, false) => "(?<=",
+                    (true, true) => "(?<!",
+                };
+                try!(self.group(open, &**x))
+            }
+            Backref(group, flags) => try!(self.backref(group, flags)),
+            Keep => self.out.push_str("\\K"),
+            Atomic(ref x) => try!(self.group("(?>", &**x)),
+            Capture(_, ref name, ref x) => {
+                let open = match *name {
+                    None => ~"(",
+                    Some(ref name) => format!("(?P<{}>", name),
+                };
+                try!(self.group(open.as_slice(), &**x))
+            }
+            Cat(ref xs) => {
+                for x in xs.iter() {
+                    try!(self.item(&**x));
+                }
+            }
+            Alt(_, _) => try!(self.group("(?:", ast)),
+            Rep(ref x, rep, ref greed) => {
+                if is_atom(&**x) {
+                    try!(self.item(&**x));
+                } else {
+                    try!(self.group("(?:", &**x));
+                }
+                self.out.push_char(match rep {
+                    ZeroOne => '?',
+                    ZeroMore => '*',
+                    OneMore => '+',
+                });
+                if !greed.is_greedy() {
+                    self.out.push_char('?');
+                }
+            }
+        }
+        Ok(())
+    }
+
+    // Renders a group that starts with `open`, like `(?:`. The flags set
+    // inside of it are reset when it's closed.
+    fn group(&mut self, open: &str, ast: &Ast) -> Result<(), parse::Error> {
+        self.out.push_str(open);
+        let flags = self.flags;
+        try!(self.expr(ast));
+        self.out.push_char(')');
+        self.flags = flags;
+        Ok(())
+    }
+
+    // Renders `^` or `$`. When they'd match after or before a new line but
+    // the node doesn't, `\A` or `\z` is rendered instead of clearing `m`.
+    fn anchor(&mut self, flags: Flags, c: char, text: &str) {
+        if flags & FLAG_MULTI == 0 && self.flags & FLAG_MULTI > 0 {
+            self.out.push_str(text);
+        } else {
+            self.set_flags((FLAG_MULTI, flags & FLAG_MULTI));
+            self.out.push_char(c);
+        }
+    }
+
+    fn class(&mut self, ranges: &[(char, char)], negated: bool) {
+        self.out.push_char('[');
+        if ranges.len() == 0 {
+            // A class can't be empty, so one that matches nothing is the
+            // negation of one that matches everything (and vice versa).
+            if !negated {
+                self.out.push_char('^');
+            }
+            self.out.push_str("\\x00-\\x{10FFFF}]");
+            return
+        }
+        if negated {
+            self.out.push_char('^');
+        }
+        for &(start, end) in ranges.iter() {
+            self.push_char(start, true);
+            if end != start {
+                self.out.push_char('-');
+                self.push_char(end, true);
+            }
+        }
+        self.out.push_char(']');
+    }
+
+    // Renders a backreference by number when it's one digit, and otherwise
+    // by name.
+    fn backref(&mut self, group: uint, flags: Flags)
+              -> Result<(), parse::Error> {
+        let found = self.groups.iter().position(|&(i, _)| i == group);
+        let (number, name) = match found {
+            None => return self.err(format!(
+                "Backreference to group {}, which isn't in the syntax tree.",
+                group)),
+            Some(i) => {
+                let (_, ref name) = *self.groups.get(i);
+                (i + 1, name.clone())
+            }
+        };
+        if flags & FLAG_NOCASE > 0 {
+            self.set_flags((FLAG_NOCASE | FLAG_ASCII,
+                            flags & (FLAG_NOCASE | FLAG_ASCII)));
+        } else {
+            self.set_flags((FLAG_NOCASE, FLAG_EMPTY));
+        }
+        match name {
+            _ if number <= 9 => {
+                self.out.push_str(format!("\\\\{}", number).as_slice());
+            }
+            Some(name) => {
+                self.out.push_str(format!("\\\\k<{}>", name).as_slice())
+            }
+            None => return self.err(format!(
+                "Backreference to group {}, which can't be written since \
+                 it has no name and isn't one of the first nine groups.",
+                number)),
+        }
+        self.backref_end = Some(self.out.len());
+        Ok(())
+    }
+
+    // Sets the flags in `mask` to the ones in `want`, with a group like
+    // `(?i-s)`, unless they're already set that way.
+    fn set_flags(&mut self, (mask, want): (Flags, Flags)) {
+        let diff = (self.flags ^ want) & mask;
+        if diff == FLAG_EMPTY {
+            return
+        }
+        let (mut on, mut off) = (StrBuf::new(), StrBuf::new());
+        for &(flag, name) in FLAG_NAMES.iter() {
+            if diff & flag > 0 {
+                if want & flag > 0 { on.push_char(name) }
+                else { off.push_char(name) }
+            }
+        }
+        if diff & FLAG_ASCII > 0 {
+            if want & FLAG_ASCII > 0 { off.push_char('u') }
+            else { on.push_char('u') }
+        }
+        self.out.push_str("(?");
+        self.out.push_str(on.as_slice());
+        if off.len() > 0 {
+            self.out.push_char('-');
+            self.out.push_str(off.as_slice());
+        }
+        self.out.push_char(')');
+        self.flags = self.flags ^ diff;
+    }
+
+    // Renders a character, escaping it if it's special (in a class, if
+    // `class` is true) or a control character.
+    fn push_char(&mut self, c: char, class: bool) {
+        match c {
+            '\n' => self.out.push_str("\\n"),
+            '\r' => self.out.push_str("\\r"),
+            '\t' => self.out.push_str("\\t"),
+            c if c < ' ' || c == '\x7F' => self.push_hex(c),
+            '-' | '&' | '~' if class => {
+                self.out.push_char('\\');
+                self.out.push_char(c);
+            }
+            c if parse::is_punct(c) => {
+                self.out.push_char('\\');
+                self.out.push_char(c);
+            }
+            c => self.out.push_char(c),
+        }
+    }
+
+    fn push_hex(&mut self, c: char) {
+        self.out.push_str(format!("\\\\x\\{{:X}\\}", c as u32).as_slice());
+    }
+
+    fn err<T>(&self, msg: &str) -> Result<T, parse::Error> {
+        Err(parse::Error {
+            pos: self.out.as_slice().char_len(),
+            msg: msg.to_owned(),
+            kind: parse::SyntaxError,
+            start: self.out.len(),
+            end: self.out.len(),
+            expr: self.out.as_slice().to_owned(),
+        })
+    }
+}
+
+// Returns the flags that a literal or class needs set to match like it does:
+// `i` if it's case insensitive, and then Unicode too, since the parser only
+// folds ASCII letters otherwise.
+fn case_flags(flags: Flags) -> (Flags, Flags) {
+    if flags & FLAG_NOCASE > 0 {
+        (FLAG_NOCASE | FLAG_ASCII, FLAG_NOCASE)
+    } else {
+        (FLAG_NOCASE, FLAG_EMPTY)
+    }
+}
+
+// Returns true if the expression given only matches the empty string
+// because it's made of nothing, and so would be rendered as nothing.
+fn is_empty(ast: &Ast) -> bool {
+    match *ast {
+        Nothing => true,
+        Cat(ref xs) => xs.iter().all(|x| is_empty(&**x)),
+        _ => false,
+    }
+}
+
+// Returns true if the expression given is rendered as a single item that
+// can be repeated without a group around it.
+fn is_atom(ast: &Ast) -> bool {
+    match *ast {
+        Literal(_, _) | Dot(_) | Class(_, _) | Begin(_) | End(_)
+        | WordBoundary(_) | SegmentBoundary(_, _) | Lookaround(_, _)
+        | Backref(_, _) | Keep | Atomic(_) | Capture(_, _, _) => true,
+        Nothing | Cat(_) | Alt(_, _) | Rep(_, _, _) => false,
+    }
+}
+
+// Collects the index and name of every capture group in the expression
+// given, in the order that they're rendered (and so numbered when parsed).
+fn capture_groups(ast: &Ast, groups: &mut Vec<(uint, Option<~str>)>) {
+    match *ast {
+        Capture(i, ref name, ref x) => {
+            groups.push((i, name.clone()));
+            capture_groups(&**x, groups)
+        }
+        Lookaround(ref x, _) | Atomic(ref x) | Rep(ref x, _, _) => {
+            capture_groups(&**x, groups)
+        }
+        Cat(ref xs) => {
+            for x in xs.iter() {
+                capture_groups(&**x, groups)
+            }
+        }
+        Alt(ref x, ref y) => {
+            capture_groups(&**x, groups);
+            capture_groups(&**y, groups)
+        }
+        _ => {}
+    }
+}
@@ -0,0 +1,211 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// A multi-replacer applies several substitutions in a single scan of the
+// text. The expressions are combined into a single alternation in which each
+// expression is wrapped in a capture group of its own. The leftmost-first
+// semantics of the alternation pick the earliest match, and among matches
+// starting at the same position, the match of the expression listed first.
+// The wrapper group that participated in a match tells which expression
+// matched.
+//
+// The capture groups of each expression are renumbered so that they follow
+// its wrapper group, and their names are dropped (since they may clash with
+// the names used in other expressions). Before a replacement is expanded,
+// the groups of the match are mapped back to the numbering of the expression
+// that matched, so `$1` (or `$name`) means what it means in that expression.
+
+use std::cmp;
+use std::io::{IoResult, Writer};
+
+use parse;
+use parse::{Ast, Lookaround, Backref, Atomic, Capture, Cat, Alt, Rep};
+use re;
+use re::Regex;
+
+/// MultiReplacer replaces the matches of several regular expressions, each
+/// with its own replacement, in a single left-to-right scan of the text.
+///
+/// At every position, the earliest match wins. When matches of several
+/// expressions start at the same position, the expression given first wins.
+/// Text that has been replaced is never searched again, so a replacement
+/// can't be matched by another expression.
+///
+/// Replacements are expanded just like the replacements given to
+/// `Regex::replace_all`: `$1` and `$name` refer to the capture groups of the
+/// expression that matched.
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::MultiReplacer;
+/// let rep = MultiReplacer::new(&[("a", "b"), ("b", "a"),
+///                                (r"(\d)(\d)", "$2$1")]).unwrap();
+/// assert_eq!(rep.replace("abba 12").as_slice(), "baab 21");
+/// ```
+pub struct MultiReplacer {
+    // `None` when there are no expressions.
+    combined: Option<Regex>,
+    // Each expression compiled on its own, for the names of its groups.
+    res: Vec<Regex>,
+    reps: Vec<~str>,
+    // For each expression, the index of its wrapper group in the combined
+    // regex and its number of capture groups (excluding the zeroth).
+    groups: Vec<(uint, uint)>,
+}
+
+impl MultiReplacer {
+    /// Compiles a sequence of pairs of a regular expression and its
+    /// replacement.
+    ///
+    /// Each expression is parsed independently, so flags set in one
+    /// expression have no effect on any other expression.
+    ///
+    /// If any of the expressions is invalid, then an error is returned for
+    /// the first invalid expression.
+    pub fn new(pairs: &[(&str, &str)]) -> Result<MultiReplacer, parse::Error> {
+        let mut alts = Vec::with_capacity(pairs.len());
+        let mut res = Vec::with_capacity(pairs.len());
+        let mut groups = Vec::with_capacity(pairs.len());
+        let mut next = 1;
+        for &(re, _) in pairs.iter() {
+            let ast = try!(parse::parse(re));
+            let n = num_groups(&*ast);
+            alts.push(~Capture(next, None, renumber(ast, next)));
+            groups.push((next, n));
+            next += n + 1;
+            res.push(try!(Regex::new(re)));
+        }
+        let original = pairs.iter().map(|&(re, _)| format!("({})", re))
+                            .collect::<Vec<~str>>().as_slice().connect("|");
+        let combined = match alts.pop() {
+            None => None,
+            Some(mut ast) => {
+                loop {
+                    match alts.pop() {
+                        None => break,
+                        Some(x) => ast = ~Alt(x, ast),
+                    }
+                }
+                Some(re::from_ast(original, ast))
+            }
+        };
+        Ok(MultiReplacer {
+            combined: combined,
+            res: res,
+            reps: pairs.iter().map(|&(_, rep)| rep.to_owned()).collect(),
+            groups: groups,
+        })
+    }
+
+    /// Returns a copy of `text` in which every match is replaced.
+    pub fn replace(&self, text: &str) -> StrBuf {
+        let mut new = StrBuf::with_capacity(text.len());
+        self.push_replace(&mut new, text);
+        new
+    }
+
+    /// Appends a copy of `text` in which every match is replaced to `dst`.
+    /// The number of matches replaced is returned.
+    pub fn push_replace(&self, dst: &mut StrBuf, text: &str) -> uint {
+        let res = self.run(text, |s| {
+            dst.push_str(s);
+            Ok(())
+        });
+        res.unwrap()
+    }
+
+    /// Writes a copy of `text` in which every match is replaced to `w`.
+    /// The pieces of the result are written as soon as they're known, so the
+    /// result is never held in memory.
+    ///
+    /// The number of matches replaced is returned.
+    pub fn write_replace(&self, w: &mut Writer, text: &str) -> IoResult<uint> {
+        self.run(text, |s| w.write_str(s))
+    }
+
+    fn run(&self, text: &str, out: |&str| -> IoResult<()>) -> IoResult<uint> {
+        let combined = match self.combined {
+            None => {
+                try!(out(text));
+                return Ok(0)
+            }
+            Some(ref combined) => combined,
+        };
+        let (mut last, mut n) = (0, 0);
+        let mut cur = combined.captures_cursor(text);
+        while cur.advance() {
+            let (s, e) = cur.pos(0).unwrap();
+            let i = self.groups.iter().position(|&(w, _)| {
+                cur.pos(w).is_some()
+            }).unwrap();
+            try!(out(text.slice(last, s)));
+            let rep = self.reps.get(i).as_slice();
+            if rep.contains_char('$') {
+                let (w, ngroups) = *self.groups.get(i);
+                let mut locs = Vec::with_capacity(2 * (ngroups + 1));
+                for g in range(w, w + ngroups + 1) {
+                    match cur.pos(g) {
+                        None => locs.push_all(&[None, None]),
+                        Some((s, e)) => locs.push_all(&[Some(s), Some(e)]),
+                    }
+                }
+                let caps = re::captures_from_locs(self.res.get(i), text, locs);
+                try!(out(caps.unwrap().expand(rep).as_slice()));
+            } else {
+                try!(out(rep));
+            }
+            last = e;
+            n += 1;
+        }
+        try!(out(text.slice_from(last)));
+        Ok(n)
+    }
+}
+
+impl Container for MultiReplacer {
+    /// Returns the number of expressions.
+    fn len(&self) -> uint {
+        self.reps.len()
+    }
+}
+
+// Returns the number of capture groups in an expression, excluding the
+// zeroth.
+fn num_groups(ast: &Ast) -> uint {
+    match *ast {
+        Capture(i, _, ref x) => cmp::max(i, num_groups(&**x)),
+        Cat(ref xs) => {
+            xs.iter().fold(0, |n, x| cmp::max(n, num_groups(&**x)))
+        }
+        Alt(ref x, ref y) => cmp::max(num_groups(&**x), num_groups(&**y)),
+        Lookaround(ref x, _) | Atomic(ref x) | Rep(ref x, _, _) => {
+            num_groups(&**x)
+        }
+        _ => 0,
+    }
+}
+
+// Shifts the index of every capture group in an expression by `offset` and
+// drops their names.
+fn renumber(ast: ~Ast, offset: uint) -> ~Ast {
+    match ast {
+        ~Capture(i, _, x) => ~Capture(i + offset, None, renumber(x, offset)),
+        ~Cat(xs) => {
+            ~Cat(xs.move_iter().map(|x| renumber(x, offset)).collect())
+        }
+        ~Alt(x, y) => ~Alt(renumber(x, offset), renumber(y, offset)),
+        ~Rep(x, op, g) => ~Rep(renumber(x, offset), op, g),
+        ~Lookaround(x, flags) => ~Lookaround(renumber(x, offset), flags),
+        ~Backref(i, flags) => ~Backref(i + offset, flags),
+        ~Atomic(x) => ~Atomic(renumber(x, offset)),
+        ast => ast,
+    }
+}
@@ -0,0 +1,377 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module decodes the named capture groups of a match into a struct,
+// which saves copying each group into a field by hand. Any struct that's
+// `Decodable` can be decoded (usually with `#[deriving(Decodable)]`): each
+// field is decoded from the group with its name, or from the group whose
+// name is the same but for ASCII case when there's none.
+//
+// A group's text is parsed with `from_str` for the number types and `bool`.
+// Strings and characters are taken as they are, and a `Vec<u8>` gets the
+// bytes of the text. A field whose type has its own `Decodable` impl that
+// reads a string (with `read_str`) can parse the text however it likes.
+//
+// A field that's an `Option` is `None` when its group didn't take part in
+// the match (or there's no such group). Any other field is required, and
+// it's an error if its group didn't take part in the match.
+
+use serialize::{Decoder, Decodable};
+use std::ascii::StrAsciiExt;
+use std::fmt;
+use std::from_str::{FromStr, from_str};
+
+use re::{Regex, Captures};
+
+/// ScanError describes why a match couldn't be decoded into a struct.
+#[deriving(Clone)]
+pub struct ScanError {
+    /// A message describing the error, which names the group (or the field)
+    /// at fault.
+    pub msg: ~str,
+    /// What went wrong.
+    pub kind: ScanErrorKind,
+}
+
+/// ScanErrorKind tells the ways that decoding a match can fail apart.
+#[deriving(Clone, Eq, Show)]
+pub enum ScanErrorKind {
+    /// A field that isn't an `Option` has no group with its name.
+    ScanNoGroup,
+    /// The group of a field that isn't an `Option` didn't take part in the
+    /// match.
+    ScanMissingGroup,
+    /// The text of a group couldn't be parsed as the type of its field.
+    ScanInvalidValue,
+    /// The type can't be decoded from capture groups (e.g., it isn't a
+    /// struct, or one of its fields is an enum, a struct or a map).
+    ScanUnsupported,
+}
+
+impl fmt::Show for ScanError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "Regex scan error: {}", self.msg)
+    }
+}
+
+fn err<T>(kind: ScanErrorKind, msg: ~str) -> Result<T, ScanError> {
+    Err(ScanError { msg: msg, kind: kind })
+}
+
+/// Decodes the named capture groups of a match of `re` into a `T`, as
+/// described by `Regex::scan`.
+pub fn decode<'a, T: Decodable<CapturesDecoder<'a>, ScanError>>
+             (re: &'a Regex, caps: Captures<'a>) -> Result<T, ScanError> {
+    let mut d = CapturesDecoder {
+        re: re,
+        caps: caps,
+        field: None,
+        elt: None,
+    };
+    Decodable::decode(&mut d)
+}
+
+// The field that's being decoded.
+struct Field<'a> {
+    name: ~str,
+    // The name of its group, if there's one.
+    group: Option<~str>,
+    // The text of its group, if it took part in the match.
+    text: Option<&'a str>,
+}
+
+/// A decoder of the named capture groups of a match (see `Regex::scan`).
+///
+/// It's only useful as the type given to `Decodable`, like in
+/// `T: Decodable<CapturesDecoder<'a>, ScanError>`.
+pub struct CapturesDecoder<'a> {
+    re: &'a Regex,
+    caps: Captures<'a>,
+    field: Option<Field<'a>>,
+    // The index of the byte being decoded, inside a `Vec<u8>`.
+    elt: Option<uint>,
+}
+
+impl<'a> CapturesDecoder<'a> {
+    // Returns the name of the group for the field named, if there's one.
+    fn group(&self, field: &str) -> Option<~str> {
+        let names: Vec<&~str> =
+            self.re.capture_names().iter().filter_map(|n| n.as_ref())
+                .collect();
+        match names.iter().find(|n| n.as_slice() == field) {
+            Some(n) => return Some((**n).clone()),
+            None => {}
+        }
+        names.iter().find(|n| n.as_slice().eq_ignore_ascii_case(field))
+             .map(|n| (**n).clone())
+    }
+
+    // Returns the text of the group of the field that's being decoded.
+    fn text(&self) -> Result<&'a str, ScanError> {
+        let field = match self.field {
+            None => {
+                return err(ScanUnsupported,
+                           ~"A match can only be decoded into a struct.")
+            }
+            Some(ref field) => field,
+        };
+        if self.elt.is_some() {
+            return err(ScanUnsupported, format!(
+                "The field '{}' can only be a sequence of bytes.",
+                field.name))
+        }
+        match (&field.group, field.text) {
+            (_, Some(text)) => Ok(text),
+            (&None, None) => err(ScanNoGroup, format!(
+                "There's no capture group named '{}'.", field.name)),
+            (&Some(ref group), None) => err(ScanMissingGroup, format!(
+                "The capture group '{}' didn't take part in the match.",
+                group)),
+        }
+    }
+
+    // Parses the text of the group of the field that's being decoded.
+    fn parse<T: FromStr>(&self, what: &str) -> Result<T, ScanError> {
+        let text = try!(self.text());
+        match from_str(text) {
+            Some(v) => Ok(v),
+            None => {
+                let field = self.field.as_ref().unwrap();
+                err(ScanInvalidValue, format!(
+                    "The capture group '{}' matched '{}', which isn't {}.",
+                    field.group.as_ref().unwrap(), text, what))
+            }
+        }
+    }
+
+    fn unsupported<T>(&self, what: &str) -> Result<T, ScanError> {
+        let msg = match self.field {
+            None => format!("A match can't be decoded into {}.", what),
+            Some(ref field) => format!(
+                "The field '{}' is {}, which can't be decoded from a capture \
+                 group.", field.name, what),
+        };
+        err(ScanUnsupported, msg)
+    }
+}
+
+impl<'a> Decoder<ScanError> for CapturesDecoder<'a> {
+    fn read_nil(&mut self) -> Result<(), ScanError> { Ok(()) }
+
+    fn read_uint(&mut self) -> Result<uint, ScanError> {
+        self.parse("an unsigned integer")
+    }
+    fn read_u64(&mut self) -> Result<u64, ScanError> {
+        self.parse("an unsigned integer")
+    }
+    fn read_u32(&mut self) -> Result<u32, ScanError> {
+        self.parse("an unsigned integer")
+    }
+    fn read_u16(&mut self) -> Result<u16, ScanError> {
+        self.parse("an unsigned integer")
+    }
+    fn read_u8(&mut self) -> Result<u8, ScanError> {
+        match self.elt {
+            None => self.parse("an unsigned integer"),
+            Some(i) => {
+                self.elt = None;
+                let text = try!(self.text());
+                self.elt = Some(i);
+                Ok(text.as_bytes()[i])
+            }
+        }
+    }
+    fn read_int(&mut self) -> Result<int, ScanError> {
+        self.parse("an integer")
+    }
+    fn read_i64(&mut self) -> Result<i64, ScanError> {
+        self.parse("an integer")
+    }
+    fn read_i32(&mut self) -> Result<i32, ScanError> {
+        self.parse("an integer")
+    }
+    fn read_i16(&mut self) -> Result<i16, ScanError> {
+        self.parse("an integer")
+    }
+    fn read_i8(&mut self) -> Result<i8, ScanError> {
+        self.parse("an integer")
+    }
+    fn read_bool(&mut self) -> Result<bool, ScanError> {
+        self.parse("'true' or 'false'")
+    }
+    fn read_f64(&mut self) -> Result<f64, ScanError> {
+        self.parse("a number")
+    }
+    fn read_f32(&mut self) -> Result<f32, ScanError> {
+        self.parse("a number")
+    }
+
+    fn read_char(&mut self) -> Result<char, ScanError> {
+        let text = try!(self.text());
+        if text.char_len() != 1 {
+            let field = self.field.as_ref().unwrap();
+            return err(ScanInvalidValue, format!(
+                "The capture group '{}' matched '{}', which isn't one \
+                 character.", field.group.as_ref().unwrap(), text))
+        }
+        Ok(text.char_at(0))
+    }
+
+    fn read_str(&mut self) -> Result<~str, ScanError> {
+        Ok(try!(self.text()).to_owned())
+    }
+
+    fn read_enum<T>(&mut self, _: &str,
+                    _: |&mut CapturesDecoder<'a>| -> Result<T, ScanError>)
+                   -> Result<T, ScanError> {
+        self.unsupported("an enum")
+    }
+
+    fn read_enum_variant<T>(&mut self, _: &[&str],
+                            _: |&mut CapturesDecoder<'a>, uint|
+                                -> Result<T, ScanError>)
+                           -> Result<T, ScanError> {
+        self.unsupported("an enum")
+    }
+
+    fn read_enum_variant_arg<T>(&mut self, _: uint,
+                                _: |&mut CapturesDecoder<'a>|
+                                    -> Result<T, ScanError>)
+                               -> Result<T, ScanError> {
+        self.unsupported("an enum")
+    }
+
+    fn read_enum_struct_variant<T>(&mut self, _: &[&str],
+                                   _: |&mut CapturesDecoder<'a>, uint|
+                                       -> Result<T, ScanError>)
+                                  -> Result<T, ScanError> {
+        self.unsupported("an enum")
+    }
+
+    fn read_enum_struct_variant_field<T>(&mut self, _: &str, _: uint,
+                                         _: |&mut CapturesDecoder<'a>|
+                                             -> Result<T, ScanError>)
+                                        -> Result<T, ScanError> {
+        self.unsupported("an enum")
+    }
+
+    fn read_struct<T>(&mut self, _: &str, _: uint,
+                      f: |&mut CapturesDecoder<'a>| -> Result<T, ScanError>)
+                     -> Result<T, ScanError> {
+        // Only the struct that a match is decoded into has fields, since a
+        // group has no names inside it.
+        if self.field.is_some() {
+            return self.unsupported("a struct")
+        }
+        f(self)
+    }
+
+    fn read_struct_field<T>(&mut self, name: &str, _: uint,
+                            f: |&mut CapturesDecoder<'a>|
+                                -> Result<T, ScanError>)
+                           -> Result<T, ScanError> {
+        let group = self.group(name);
+        let text = match group {
+            None => None,
+            Some(ref group) => {
+                self.caps.name_index(group.as_slice())
+                    .map(|i| self.caps.at(i))
+            }
+        };
+        self.field = Some(Field {
+            name: name.to_owned(),
+            group: group,
+            text: text,
+        });
+        let v = f(self);
+        self.field = None;
+        v
+    }
+
+    fn read_tuple<T>(&mut self,
+                     _: |&mut CapturesDecoder<'a>, uint|
+                         -> Result<T, ScanError>)
+                    -> Result<T, ScanError> {
+        self.unsupported("a tuple")
+    }
+
+    fn read_tuple_arg<T>(&mut self, _: uint,
+                         _: |&mut CapturesDecoder<'a>|
+                             -> Result<T, ScanError>)
+                        -> Result<T, ScanError> {
+        self.unsupported("a tuple")
+    }
+
+    fn read_tuple_struct<T>(&mut self, _: &str,
+                            _: |&mut CapturesDecoder<'a>, uint|
+                                -> Result<T, ScanError>)
+                           -> Result<T, ScanError> {
+        self.unsupported("a tuple struct")
+    }
+
+    fn read_tuple_struct_arg<T>(&mut self, _: uint,
+                                _: |&mut CapturesDecoder<'a>|
+                                    -> Result<T, ScanError>)
+                               -> Result<T, ScanError> {
+        self.unsupported("a tuple struct")
+    }
+
+    fn read_option<T>(&mut self,
+                      f: |&mut CapturesDecoder<'a>, bool|
+                          -> Result<T, ScanError>)
+                     -> Result<T, ScanError> {
+        let some = match self.field {
+            None => return self.unsupported("an option"),
+            Some(ref field) => field.text.is_some(),
+        };
+        f(self, some)
+    }
+
+    fn read_seq<T>(&mut self,
+                   f: |&mut CapturesDecoder<'a>, uint| -> Result<T, ScanError>)
+                  -> Result<T, ScanError> {
+        // Only a `Vec<u8>` (or another sequence of bytes) can be decoded,
+        // from the bytes of the text.
+        if self.elt.is_some() {
+            return self.unsupported("a sequence of sequences")
+        }
+        let len = try!(self.text()).len();
+        f(self, len)
+    }
+
+    fn read_seq_elt<T>(&mut self, i: uint,
+                       f: |&mut CapturesDecoder<'a>| -> Result<T, ScanError>)
+                      -> Result<T, ScanError> {
+        self.elt = Some(i);
+        let v = f(self);
+        self.elt = None;
+        v
+    }
+
+    fn read_map<T>(&mut self,
+                   _: |&mut CapturesDecoder<'a>, uint| -> Result<T, ScanError>)
+                  -> Result<T, ScanError> {
+        self.unsupported("a map")
+    }
+
+    fn read_map_elt_key<T>(&mut self, _: uint,
+                           _: |&mut CapturesDecoder<'a>|
+                               -> Result<T, ScanError>)
+                          -> Result<T, ScanError> {
+        self.unsupported("a map")
+    }
+
+    fn read_map_elt_val<T>(&mut self, _: uint,
+                           _: |&mut CapturesDecoder<'a>|
+                               -> Result<T, ScanError>)
+                          -> Result<T, ScanError> {
+        self.unsupported("a map")
+    }
+}
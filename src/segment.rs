@@ -0,0 +1,351 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// The boundaries between grapheme clusters (`\b{g}`) and between words
+// (`\b{wb}`) defined by Unicode Standard Annex #29, "Unicode Text
+// Segmentation" (http://www.unicode.org/reports/tr29/).
+//
+// Unlike `\b`, whose word characters only depend on the characters on either
+// side of a position, these rules look further: back over any number of
+// characters (e.g., to count regional indicators or to find the emoji that
+// a ZWJ sequence starts with) and ahead by one character more. So they're
+// evaluated on the input itself, at the index of the position, which only
+// the engines that know where they are in the input can do. (The DFA and
+// searches of streams don't.)
+//
+// Counting the regional indicators before a position, or finding the
+// consonant that a run of conjunct linkers and extenders follows, looks back
+// over the whole run, so searching a long run would take time quadratic in
+// its length. Those scans stop after `MAX_LOOKBACK` characters instead: in a
+// longer run of regional indicators, the ones that follow the first
+// `MAX_LOOKBACK` aren't paired anymore, and a consonant following a longer
+// run of linkers and extenders starts a cluster of its own. (No text in any
+// script comes close to either.)
+//
+// The rules are numbered as they are in the annex (e.g., GB9c and WB6).
+
+use parse::unicode::{GRAPHEME_CATS, WORD_CATS};
+
+// The number of characters that the scans back over runs of regional
+// indicators and conjunct linkers look at. It's even, so that the regional
+// indicators counted pair up.
+static MAX_LOOKBACK: uint = 64;
+
+/// The kinds of text segments whose boundaries can be matched.
+#[deriving(Clone, Eq, Show)]
+pub enum Segment {
+    /// Extended grapheme clusters, matched by `\b{g}`.
+    Grapheme,
+    /// Words, matched by `\b{wb}`.
+    Word,
+}
+
+// The values of the Grapheme_Cluster_Break property, with the
+// Extended_Pictographic characters and the Indic_Conjunct_Break (InCB)
+// consonants, linkers and extenders given categories of their own.
+#[deriving(Clone, Eq, Show)]
+pub enum GraphemeCat {
+    GOther,
+    GCR,
+    GLF,
+    GControl,
+    // Extend, with InCB=None.
+    GExtend,
+    // Extend, with InCB=Extend.
+    GConjunctExtend,
+    // Extend, with InCB=Linker.
+    GLinker,
+    GZWJ,
+    GRegionalIndicator,
+    GPrepend,
+    GSpacingMark,
+    GL,
+    GV,
+    GT,
+    GLV,
+    GLVT,
+    GExtPict,
+    // Other, with InCB=Consonant.
+    GConsonant,
+}
+
+// The values of the Word_Break property.
+#[deriving(Clone, Eq, Show)]
+pub enum WordCat {
+    WOther,
+    WCR,
+    WLF,
+    WNewline,
+    WExtend,
+    WZWJ,
+    WRegionalIndicator,
+    WFormat,
+    WKatakana,
+    WHebrewLetter,
+    WALetter,
+    WSingleQuote,
+    WDoubleQuote,
+    WMidNumLet,
+    WMidLetter,
+    WMidNum,
+    WNumeric,
+    WExtendNumLet,
+    WWSegSpace,
+}
+
+/// Returns true if and only if the byte index `pos` in `input` is a
+/// boundary between segments of the kind given. The beginning and the end
+/// of the input are always boundaries.
+pub fn is_boundary(seg: Segment, input: &str, pos: uint) -> bool {
+    match seg {
+        Grapheme => is_grapheme_boundary(input, pos),
+        Word => is_word_boundary(input, pos),
+    }
+}
+
+/// Returns true if and only if the byte index `pos` in `input` is a
+/// boundary between extended grapheme clusters.
+pub fn is_grapheme_boundary(input: &str, pos: uint) -> bool {
+    // GB1, GB2
+    if pos == 0 || pos >= input.len() {
+        return true
+    }
+    let before = input.char_range_at_reverse(pos);
+    match (grapheme_cat(before.ch), grapheme_cat(input.char_at(pos))) {
+        // GB3
+        (GCR, GLF) => false,
+        // GB4, GB5
+        (GCR, _) | (GLF, _) | (GControl, _) => true,
+        (_, GCR) | (_, GLF) | (_, GControl) => true,
+        // GB6, GB7, GB8
+        (GL, GL) | (GL, GV) | (GL, GLV) | (GL, GLVT) => false,
+        (GLV, GV) | (GLV, GT) | (GV, GV) | (GV, GT) => false,
+        (GLVT, GT) | (GT, GT) => false,
+        // GB9, GB9a, GB9b
+        (_, GExtend) | (_, GConjunctExtend) | (_, GLinker) | (_, GZWJ) => {
+            false
+        }
+        (_, GSpacingMark) | (GPrepend, _) => false,
+        // GB9c
+        (_, GConsonant) => !follows_conjunct_linker(input, pos),
+        // GB11
+        (GZWJ, GExtPict) => !follows_pictographic(input, before.next),
+        // GB12, GB13
+        (GRegionalIndicator, GRegionalIndicator) => {
+            count_regional_indicators(input, pos) % 2 == 0
+        }
+        // GB999
+        _ => true,
+    }
+}
+
+// Returns true if the characters preceding `pos` are an InCB consonant
+// followed by InCB linkers and extenders, at least one of which is a linker.
+// At most `MAX_LOOKBACK` linkers and extenders are looked at.
+fn follows_conjunct_linker(input: &str, mut pos: uint) -> bool {
+    let mut linked = false;
+    let mut n = 0;
+    while pos > 0 && n <= MAX_LOOKBACK {
+        let before = input.char_range_at_reverse(pos);
+        match grapheme_cat(before.ch) {
+            GLinker => linked = true,
+            GConjunctExtend | GZWJ => {}
+            GConsonant => return linked,
+            _ => return false,
+        }
+        pos = before.next;
+        n += 1;
+    }
+    false
+}
+
+// Returns true if the characters preceding `pos` are an
+// Extended_Pictographic character followed by any number of extenders.
+fn follows_pictographic(input: &str, mut pos: uint) -> bool {
+    while pos > 0 {
+        let before = input.char_range_at_reverse(pos);
+        match grapheme_cat(before.ch) {
+            GExtend | GConjunctExtend | GLinker => {}
+            GExtPict => return true,
+            _ => return false,
+        }
+        pos = before.next;
+    }
+    false
+}
+
+// Returns the number of regional indicators that immediately precede `pos`,
+// up to `MAX_LOOKBACK`.
+fn count_regional_indicators(input: &str, mut pos: uint) -> uint {
+    let mut n = 0;
+    while pos > 0 && n < MAX_LOOKBACK {
+        let before = input.char_range_at_reverse(pos);
+        if grapheme_cat(before.ch) != GRegionalIndicator {
+            break
+        }
+        n += 1;
+        pos = before.next;
+    }
+    n
+}
+
+/// Returns true if and only if the byte index `pos` in `input` is a
+/// boundary between words (in the sense of the annex, where the spaces and
+/// punctuation between words are segments too).
+pub fn is_word_boundary(input: &str, pos: uint) -> bool {
+    // WB1, WB2
+    if pos == 0 || pos >= input.len() {
+        return true
+    }
+    let before = input.char_range_at_reverse(pos);
+    let (prev, cur) = (word_cat(before.ch), word_cat(input.char_at(pos)));
+    match (prev, cur) {
+        // WB3
+        (WCR, WLF) => return false,
+        // WB3a, WB3b
+        (WNewline, _) | (WCR, _) | (WLF, _) => return true,
+        (_, WNewline) | (_, WCR) | (_, WLF) => return true,
+        // WB3c
+        (WZWJ, _) if grapheme_cat(input.char_at(pos)) == GExtPict => {
+            return false
+        }
+        // WB3d
+        (WWSegSpace, WWSegSpace) => return false,
+        // WB4
+        (_, WExtend) | (_, WFormat) | (_, WZWJ) => return false,
+        _ => {}
+    }
+    // By WB4, extenders, formats and ZWJs are skipped over, as if they
+    // weren't there, by all of the rules that follow.
+    let (prev, prev_pos) = word_before(input, pos);
+    let (prev2, _) = word_before(input, prev_pos);
+    let next = word_after(input, pos);
+    match (prev, cur) {
+        // WB5, WB6, WB7
+        (p, c) if is_ahletter(p) && is_ahletter(c) => false,
+        (p, c) if is_ahletter(p) && is_midletter(c) && is_ahletter(next) => {
+            false
+        }
+        (p, c) if is_ahletter(prev2) && is_midletter(p) && is_ahletter(c) => {
+            false
+        }
+        // WB7a, WB7b, WB7c
+        (WHebrewLetter, WSingleQuote) => false,
+        (WHebrewLetter, WDoubleQuote) if next == WHebrewLetter => false,
+        (WDoubleQuote, WHebrewLetter) if prev2 == WHebrewLetter => false,
+        // WB8, WB9, WB10
+        (WNumeric, WNumeric) => false,
+        (p, WNumeric) if is_ahletter(p) => false,
+        (WNumeric, c) if is_ahletter(c) => false,
+        // WB11, WB12
+        (p, WNumeric) if prev2 == WNumeric && is_midnum(p) => false,
+        (WNumeric, c) if is_midnum(c) && next == WNumeric => false,
+        // WB13, WB13a, WB13b
+        (WKatakana, WKatakana) => false,
+        (p, WExtendNumLet) if is_ahletter(p) || p == WNumeric
+                              || p == WKatakana || p == WExtendNumLet => {
+            false
+        }
+        (WExtendNumLet, c) if is_ahletter(c) || c == WNumeric
+                              || c == WKatakana => {
+            false
+        }
+        // WB15, WB16
+        (WRegionalIndicator, WRegionalIndicator) => {
+            count_word_regional_indicators(input, pos) % 2 == 0
+        }
+        // WB999
+        _ => true,
+    }
+}
+
+// Returns the category of the character preceding `pos`, skipping over the
+// characters that WB4 ignores, and the index at which it starts. When there
+// is no such character (or it's a line break, after which WB4 doesn't
+// apply), `WOther` is returned, since none of the rules that follow WB4
+// match it.
+fn word_before(input: &str, mut pos: uint) -> (WordCat, uint) {
+    while pos > 0 {
+        let before = input.char_range_at_reverse(pos);
+        match word_cat(before.ch) {
+            WExtend | WFormat | WZWJ => pos = before.next,
+            WNewline | WCR | WLF => return (WOther, before.next),
+            cat => return (cat, before.next),
+        }
+    }
+    (WOther, 0)
+}
+
+// Returns the category of the character following the one at `pos`,
+// skipping over the characters that WB4 ignores.
+fn word_after(input: &str, pos: uint) -> WordCat {
+    let mut pos = input.char_range_at(pos).next;
+    while pos < input.len() {
+        let cur = input.char_range_at(pos);
+        match word_cat(cur.ch) {
+            WExtend | WFormat | WZWJ => pos = cur.next,
+            cat => return cat,
+        }
+    }
+    WOther
+}
+
+// Returns the number of regional indicators that precede `pos`, up to
+// `MAX_LOOKBACK`, skipping over the characters that WB4 ignores.
+fn count_word_regional_indicators(input: &str, mut pos: uint) -> uint {
+    let mut n = 0;
+    while n < MAX_LOOKBACK {
+        let (cat, start) = word_before(input, pos);
+        if cat != WRegionalIndicator {
+            return n
+        }
+        n += 1;
+        pos = start;
+    }
+    n
+}
+
+fn is_ahletter(cat: WordCat) -> bool {
+    cat == WALetter || cat == WHebrewLetter
+}
+
+// MidLetter or MidNumLetQ.
+fn is_midletter(cat: WordCat) -> bool {
+    cat == WMidLetter || cat == WMidNumLet || cat == WSingleQuote
+}
+
+// MidNum or MidNumLetQ.
+fn is_midnum(cat: WordCat) -> bool {
+    cat == WMidNum || cat == WMidNumLet || cat == WSingleQuote
+}
+
+fn grapheme_cat(c: char) -> GraphemeCat {
+    match GRAPHEME_CATS.bsearch(|&(start, end, _)| cmp_range(c, start, end)) {
+        None => GOther,
+        Some(i) => { let (_, _, cat) = GRAPHEME_CATS[i]; cat }
+    }
+}
+
+fn word_cat(c: char) -> WordCat {
+    match WORD_CATS.bsearch(|&(start, end, _)| cmp_range(c, start, end)) {
+        None => WOther,
+        Some(i) => { let (_, _, cat) = WORD_CATS[i]; cat }
+    }
+}
+
+fn cmp_range(c: char, start: char, end: char) -> Ordering {
+    if c >= start && c <= end {
+        Equal
+    } else if start > c {
+        Greater
+    } else {
+        Less
+    }
+}
@@ -0,0 +1,537 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// A regex set is a sequence of regular expressions compiled into a single
+// program. The program has one entry point for each expression, and every
+// expression has its own `Match` instruction. This lets us run a single NFA
+// simulation over the search text while keeping track of which expressions
+// have matched.
+//
+// The simulation is the same as the one in vm.rs, except that every thread
+// is owned by exactly one expression. Leftmost-first semantics are therefore
+// applied *per expression*: when a thread of expression `i` matches, only the
+// lower priority threads of expression `i` are dropped. Threads belonging to
+// other expressions keep running.
+
+use std::mem;
+
+use compile::{
+    Program, Inst, InstIdx,
+    Match, EmptyBegin, EmptyEnd, EmptyWordBoundary, EmptySegmentBoundary,
+    EmptyLook, GroupRef, Save, Jump, Split,
+};
+use encode;
+use encode::{Encoder, Decoder, DecodeError};
+use parse;
+use parse::{FLAG_MULTI, FLAG_NEGATED};
+use vm;
+use vm::{CaptureLocs, CharReader, Threads, Location};
+
+// Returns the expression that owns each instruction of a set's program,
+// given the first instruction of each expression.
+fn owners(prog: &Program, starts: &[InstIdx]) -> Vec<uint> {
+    let mut owners = Vec::from_elem(prog.insts.len(), 0u);
+    for (i, &start) in starts.iter().enumerate() {
+        let end =
+            if i + 1 < starts.len() {
+                starts[i + 1]
+            } else {
+                prog.insts.len()
+            };
+        for pc in range(start, end) {
+            *owners.get_mut(pc) = i;
+        }
+    }
+    owners
+}
+
+/// A single match reported by a `RegexSet`.
+///
+/// It contains the index of the expression (in the order given to
+/// `RegexSet::new`) that matched, along with the start and end byte indices
+/// of its leftmost-first match.
+#[deriving(Show, Clone, Eq)]
+pub struct SetMatch {
+    /// The index of the expression that matched.
+    pub pattern: uint,
+    /// The byte index of the start of the match.
+    pub start: uint,
+    /// The byte index of the end of the match.
+    pub end: uint,
+}
+
+/// RegexSet is a collection of regular expressions compiled into a single
+/// program so that they can all be searched for in a *single* scan of the
+/// search text.
+///
+/// Each expression in a set is parsed independently, so flags set in one
+/// expression (like `(?i)`) have no effect on any other expression.
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::RegexSet;
+/// let set = RegexSet::new(&["[0-9]+", "(?i)foo", "bar"]).unwrap();
+/// assert_eq!(set.matches("FOO 123"), vec!(0, 1));
+/// ```
+#[deriving(Clone)]
+pub struct RegexSet {
+    originals: Vec<~str>,
+    names: Vec<~[Option<~str>]>,
+    prog: Program,
+    // The first instruction of each expression.
+    starts: Vec<InstIdx>,
+    // The expression that owns each instruction.
+    owners: Vec<uint>,
+    // Whether each expression must match at the beginning of the text.
+    anchored: Vec<bool>,
+}
+
+impl RegexSet {
+    /// Compiles a set of regular expressions. Once compiled, it can be used
+    /// repeatedly to find which of the expressions match a search text.
+    ///
+    /// An empty set of expressions is allowed, but it never matches
+    /// anything.
+    ///
+    /// If any of the expressions is invalid, then an error is returned for
+    /// the first invalid expression. Lookaround, backreferences and `\K`
+    /// aren't supported, since they can't be evaluated in a single scan, so
+    /// they're errors too.
+    pub fn new(res: &[&str]) -> Result<RegexSet, parse::Error> {
+        let mut asts = Vec::with_capacity(res.len());
+        for re in res.iter() {
+            asts.push(try!(parse::parse(*re)));
+        }
+        let (prog, starts, names) = Program::new_set(asts);
+        let owners = owners(&prog, starts.as_slice());
+        if prog.lookaround {
+            let pc = prog.insts.iter().position(|inst| {
+                match *inst { EmptyLook(_, _) => true, _ => false }
+            }).unwrap();
+            return Err(parse::Error {
+                pos: 0,
+                msg: format!("Lookaround is not supported in a RegexSet \
+                              (expression {}).", *owners.get(pc)),
+                kind: parse::SyntaxError,
+                start: 0,
+                end: 0,
+                expr: ~"",
+            })
+        }
+        if prog.backrefs {
+            let pc = prog.insts.iter().position(|inst| {
+                match *inst { GroupRef(_, _) => true, _ => false }
+            }).unwrap();
+            return Err(parse::Error {
+                pos: 0,
+                msg: format!("Backreferences are not supported in a RegexSet \
+                              (expression {}).", *owners.get(pc)),
+                kind: parse::SyntaxError,
+                start: 0,
+                end: 0,
+                expr: ~"",
+            })
+        }
+        if prog.keep {
+            // Every expression starts by saving slot 0, and so does `\K`.
+            let pc = prog.insts.iter().enumerate().position(|(pc, inst)| {
+                match *inst {
+                    Save(0) => !starts.contains(&pc),
+                    _ => false,
+                }
+            }).unwrap();
+            return Err(parse::Error {
+                pos: 0,
+                msg: format!("\\\\K is not supported in a RegexSet \
+                              (expression {}).", *owners.get(pc)),
+                kind: parse::SyntaxError,
+                start: 0,
+                end: 0,
+                expr: ~"",
+            })
+        }
+        let originals = res.iter().map(|re| (*re).to_owned()).collect();
+        Ok(RegexSet::from_parts(originals, names, prog, starts))
+    }
+
+    fn from_parts(originals: Vec<~str>, names: Vec<~[Option<~str>]>,
+                  prog: Program, starts: Vec<InstIdx>) -> RegexSet {
+        let owners = owners(&prog, starts.as_slice());
+        let anchored = starts.iter().map(|&start| {
+            match *prog.insts.get(start + 1) {
+                EmptyBegin(flags) if flags & FLAG_MULTI == 0 => true,
+                _ => false,
+            }
+        }).collect();
+        RegexSet {
+            originals: originals,
+            names: names,
+            prog: prog,
+            starts: starts,
+            owners: owners,
+            anchored: anchored,
+        }
+    }
+
+    /// Encodes the compiled set to bytes, which `from_bytes` decodes to an
+    /// identical set without parsing or compiling its expressions again.
+    pub fn to_bytes(&self) -> Vec<u8> {
+        let mut e = Encoder::new(encode::KIND_SET);
+        e.write_uint(self.originals.len());
+        for (re, names) in self.originals.iter().zip(self.names.iter()) {
+            e.write_str(re.as_slice());
+            encode::encode_names(&mut e, names.as_slice());
+        }
+        for &start in self.starts.iter() {
+            e.write_uint(start);
+        }
+        encode::encode_program(&mut e, &self.prog);
+        e.unwrap()
+    }
+
+    /// Decodes a set encoded by `to_bytes`.
+    ///
+    /// An error is returned if the bytes were encoded by a version of this
+    /// crate with an incompatible format, or if they aren't an encoded set
+    /// at all (see `Regex::from_bytes`).
+    pub fn from_bytes(bytes: &[u8]) -> Result<RegexSet, DecodeError> {
+        let mut d = try!(Decoder::new(bytes, encode::KIND_SET));
+        let n = try!(d.read_len());
+        let (mut originals, mut names) = (vec!(), vec!());
+        for _ in range(0, n) {
+            originals.push(try!(d.read_str()));
+            names.push(try!(encode::decode_names(&mut d)));
+        }
+        let mut starts = Vec::with_capacity(n);
+        for i in range(0, n) {
+            let start = try!(d.read_uint());
+            if i > 0 && start <= *starts.get(i - 1) {
+                return encode::invalid("The encoded set's expressions are \
+                                        out of order.")
+            }
+            starts.push(start);
+        }
+        let ncaps = names.iter().map(|names| names.len()).max().unwrap_or(0);
+        let mut prog = try!(encode::decode_program(&mut d, ncaps,
+                                                   starts.as_slice()));
+        try!(d.finish());
+        if prog.backtrack_only() {
+            return encode::invalid("The encoded set uses features that a \
+                                    RegexSet doesn't support.")
+        }
+        // There's no single entry point to analyze.
+        prog.onepass = None;
+        Ok(RegexSet::from_parts(originals, names, prog, starts))
+    }
+
+    /// Returns the indices of every expression in the set that matches
+    /// somewhere in `text`. The indices are returned in ascending order.
+    ///
+    /// This is faster than `find_all`, since the search can stop as soon as
+    /// every expression has matched (and no match locations are tracked).
+    pub fn matches(&self, text: &str) -> Vec<uint> {
+        self.exec(false, text).iter().enumerate()
+            .filter(|&(_, m)| m.is_some())
+            .map(|(i, _)| i)
+            .collect()
+    }
+
+    /// Returns true if and only if at least one expression in the set
+    /// matches somewhere in `text`.
+    pub fn is_match(&self, text: &str) -> bool {
+        self.matches(text).len() > 0
+    }
+
+    /// Returns the leftmost-first match of every expression in the set that
+    /// matches somewhere in `text`. Matches are ordered by the index of the
+    /// expression that produced them (*not* by their position in `text`).
+    ///
+    /// Each expression's match is exactly the one that `Regex::find` would
+    /// report for that expression on its own.
+    pub fn find_all(&self, text: &str) -> Vec<SetMatch> {
+        self.exec(true, text).iter().enumerate()
+            .filter_map(|(i, m)| (*m).map(|(s, e)| {
+                SetMatch { pattern: i, start: s, end: e }
+            }))
+            .collect()
+    }
+
+    /// Returns the match of the expression in the set that matches the
+    /// longest text starting exactly at the byte index `at`, or `None` if
+    /// none of them matches there. When several expressions match text of
+    /// the same length, the one with the lowest index wins, so the
+    /// expressions of a lexer's keywords should come before the one of its
+    /// identifiers.
+    ///
+    /// Like `Regex::find_prefix`, the anchoring applies to the search
+    /// position: zero-width assertions like `^` and `\b` are evaluated with
+    /// respect to all of `text`. `at` must be at a character boundary of
+    /// `text` (or equal to its length), otherwise this function fails.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::RegexSet;
+    /// let set = RegexSet::new(&["if", "[a-z]+", "[0-9]+", " +"]).unwrap();
+    /// let (text, mut at, mut tokens) = ("if ifs 12", 0, vec!());
+    /// while at < text.len() {
+    ///     let m = set.find_prefix(text, at).unwrap();
+    ///     tokens.push((m.pattern, text.slice(m.start, m.end)));
+    ///     at = m.end;
+    /// }
+    /// assert_eq!(tokens, vec!((0, "if"), (3, " "), (1, "ifs"), (3, " "),
+    ///                         (2, "12")));
+    /// ```
+    pub fn find_prefix(&self, text: &str, at: uint) -> Option<SetMatch> {
+        if at > text.len() || !text.is_char_boundary(at) {
+            fail!("start of search {} is not a character boundary of the text",
+                  at);
+        }
+        if self.len() == 0 {
+            return None
+        }
+        let found = SetNfa {
+            set: self,
+            locations: true,
+            ic: 0,
+            chars: CharReader::new(text),
+        }.run_prefix(at);
+        found.map(|(p, e)| SetMatch { pattern: p, start: at, end: e })
+    }
+
+    /// Returns the original expression at index `i`.
+    pub fn pattern<'a>(&'a self, i: uint) -> &'a str {
+        self.originals.get(i).as_slice()
+    }
+
+    /// Returns the capture group names of the expression at index `i`.
+    /// The names are indexed by capture group, so the first element (which
+    /// corresponds to the entire match) is always `None`.
+    pub fn names<'a>(&'a self, i: uint) -> &'a [Option<~str>] {
+        self.names.get(i).as_slice()
+    }
+
+    fn exec(&self, locations: bool, text: &str) -> Vec<Option<(uint, uint)>> {
+        if self.len() == 0 {
+            return vec!()
+        }
+        SetNfa {
+            set: self,
+            locations: locations,
+            ic: 0,
+            chars: CharReader::new(text),
+        }.run(text.len())
+    }
+}
+
+impl Container for RegexSet {
+    /// Returns the number of expressions in the set.
+    #[inline]
+    fn len(&self) -> uint {
+        self.starts.len()
+    }
+}
+
+struct SetNfa<'r, 't> {
+    set: &'r RegexSet,
+    // When false, we only care about which expressions match.
+    locations: bool,
+    ic: uint,
+    chars: CharReader<'t>,
+}
+
+impl<'r, 't> SetNfa<'r, 't> {
+    fn run(&mut self, end: uint) -> Vec<Option<(uint, uint)>> {
+        let npats = self.set.len();
+        let ninsts = self.set.prog.insts.len();
+        let mut clist = &mut Threads::new(Location, ninsts, 1);
+        let mut nlist = &mut Threads::new(Location, ninsts, 1);
+
+        let mut found: Vec<Option<(uint, uint)>> = Vec::from_elem(npats, None);
+        // `matched` is set as soon as an expression matches, which stops new
+        // threads from being started for that expression. `cut` is reset on
+        // every step and drops the lower priority threads of an expression
+        // once it has matched.
+        let mut matched = Vec::from_elem(npats, false);
+        let mut cut = Vec::from_elem(npats, false);
+        let mut nmatched = 0u;
+        let mut groups: CaptureLocs = vec![None, None];
+
+        self.ic = 0;
+        let mut next_ic = self.chars.set(0);
+        while self.ic <= end {
+            if clist.size == 0 {
+                // Every expression has either matched or can never match
+                // again since it's anchored at the beginning of the text.
+                if nmatched == npats || (self.ic > 0 && self.all_anchored(
+                                             matched.as_slice())) {
+                    break
+                }
+            }
+
+            // Simulate a preceding '.*?' for every expression that hasn't
+            // matched yet. Expressions are added in order so that earlier
+            // expressions get higher priority, but this only matters for
+            // threads belonging to the same expression.
+            for p in range(0, npats) {
+                if !*matched.get(p) {
+                    let start = *self.set.starts.get(p);
+                    self.add(clist, start, groups.as_mut_slice());
+                }
+            }
+
+            self.ic = next_ic;
+            next_ic = self.chars.advance();
+
+            for p in cut.mut_iter() {
+                *p = false;
+            }
+            let mut i = 0;
+            while i < clist.size {
+                let pc = clist.pc(i);
+                let p = *self.set.owners.get(pc);
+                i += 1;
+                if *cut.get(p) || (!self.locations && *matched.get(p)) {
+                    continue
+                }
+                match *self.set.prog.insts.get(pc) {
+                    Match => {
+                        let caps = clist.groups(i - 1);
+                        *found.get_mut(p) = Some((caps[0].unwrap(),
+                                                  caps[1].unwrap()));
+                        *cut.get_mut(p) = true;
+                        if !*matched.get(p) {
+                            *matched.get_mut(p) = true;
+                            nmatched += 1;
+                        }
+                        if !self.locations && nmatched == npats {
+                            return found
+                        }
+                    }
+                    ref inst => {
+                        if vm::char_matches(inst, self.chars.prev) {
+                            self.add(nlist, pc + 1, clist.groups(i - 1));
+                        }
+                    }
+                }
+            }
+            mem::swap(&mut clist, &mut nlist);
+            nlist.empty();
+        }
+        found
+    }
+
+    // Runs every expression anchored at `at`, and returns the one whose
+    // match is the longest (or the first of those whose matches are as long)
+    // together with where its match ends. Unlike `run`, no thread is dropped
+    // when its expression matches, since a longer match may follow.
+    fn run_prefix(&mut self, at: uint) -> Option<(uint, uint)> {
+        let ninsts = self.set.prog.insts.len();
+        let mut clist = &mut Threads::new(Location, ninsts, 1);
+        let mut nlist = &mut Threads::new(Location, ninsts, 1);
+        let mut groups: CaptureLocs = vec![None, None];
+        let mut best: Option<(uint, uint)> = None;
+
+        self.ic = at;
+        let mut next_ic = self.chars.set(at);
+        for p in range(0, self.set.len()) {
+            let start = *self.set.starts.get(p);
+            self.add(clist, start, groups.as_mut_slice());
+        }
+        while clist.size > 0 {
+            self.ic = next_ic;
+            next_ic = self.chars.advance();
+
+            let mut i = 0;
+            while i < clist.size {
+                let pc = clist.pc(i);
+                i += 1;
+                match *self.set.prog.insts.get(pc) {
+                    Match => {
+                        let p = *self.set.owners.get(pc);
+                        let end = clist.groups(i - 1)[1].unwrap();
+                        best = match best {
+                            Some((q, e)) if e > end || (e == end && q < p) => {
+                                best
+                            }
+                            _ => Some((p, end)),
+                        };
+                    }
+                    ref inst => {
+                        if vm::char_matches(inst, self.chars.prev) {
+                            self.add(nlist, pc + 1, clist.groups(i - 1));
+                        }
+                    }
+                }
+            }
+            mem::swap(&mut clist, &mut nlist);
+            nlist.empty();
+        }
+        best
+    }
+
+    // Returns true when every expression that hasn't matched yet is anchored
+    // to the beginning of the text.
+    fn all_anchored(&self, matched: &[bool]) -> bool {
+        self.set.anchored.iter().zip(matched.iter()).all(|(&a, &m)| a || m)
+    }
+
+    fn add(&self, nlist: &mut Threads, pc: uint, groups: &mut [Option<uint>]) {
+        if nlist.contains(pc) {
+            return
+        }
+        // See the corresponding comments in vm.rs for why states are added
+        // even for empty instructions.
+        match *self.set.prog.insts.get(pc) {
+            ref inst @ EmptyBegin(_) => self.add_empty(nlist, pc, inst, groups),
+            ref inst @ EmptyEnd(_) => self.add_empty(nlist, pc, inst, groups),
+            ref inst @ EmptyWordBoundary(_) =>
+                self.add_empty(nlist, pc, inst, groups),
+            EmptySegmentBoundary(seg, flags) => {
+                nlist.add(pc, groups, true);
+                let boundary = self.chars.is_segment_boundary(seg);
+                if boundary == !(flags & FLAG_NEGATED > 0) {
+                    self.add(nlist, pc + 1, groups)
+                }
+            }
+            Save(slot) => {
+                nlist.add(pc, groups, true);
+                if slot <= 1 {
+                    let old = groups[slot];
+                    groups[slot] = Some(self.ic);
+                    self.add(nlist, pc + 1, groups);
+                    groups[slot] = old;
+                } else {
+                    self.add(nlist, pc + 1, groups);
+                }
+            }
+            Jump(to) => {
+                nlist.add(pc, groups, true);
+                self.add(nlist, to, groups)
+            }
+            Split(x, y) => {
+                nlist.add(pc, groups, true);
+                self.add(nlist, x, groups);
+                self.add(nlist, y, groups);
+            }
+            _ => nlist.add(pc, groups, false),
+        }
+    }
+
+    fn add_empty(&self, nlist: &mut Threads, pc: uint, inst: &Inst,
+                 groups: &mut [Option<uint>]) {
+        nlist.add(pc, groups, true);
+        if vm::empty_matches(inst, self.chars.prev, self.chars.cur,
+                             self.chars.is_last()) {
+            self.add(nlist, pc + 1, groups)
+        }
+    }
+}
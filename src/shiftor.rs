@@ -0,0 +1,281 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module implements a bit-parallel matching engine for tiny expressions.
+// It's the "Shift-And" algorithm (the complement of Shift-Or), extended to
+// character classes and repetitions of single characters as described by
+// Navarro and Raffinot in "Flexible Pattern Matching in Strings".
+//
+// An expression qualifies if it's a concatenation of at most 64 items, where
+// each item matches a single character (a literal, a class or `.`) and may be
+// repeated with `?`, `*` or `+`. It may start with `^` and end with `$` (but
+// not in multi-line mode). Everything else (alternations, capture groups,
+// word boundaries, ...) is declined.
+//
+// The state of the search is a word in which bit `i` is set when the items
+// up to and including item `i` have just matched. Consuming a character `c`
+// shifts the state by one item and keeps the items that can match `c`. Items
+// that can repeat may also stay where they are, and items that are optional
+// may be skipped without consuming anything.
+//
+// Masks of the items matching a character are precomputed for ASCII. Other
+// characters are decoded from UTF-8 and tested against every item, so only
+// whole characters are ever consumed.
+//
+// Since all alternatives are tracked at once, the engine can't implement
+// leftmost-first semantics by itself. It finds out whether there's a match,
+// and if so, where the first match ends and a lower bound on where the
+// leftmost-first match starts (the last position at which the state was
+// empty). Finding the exact bounds of the match is left to the NFA.
+
+use std::mem;
+
+use charset::CharSet;
+use compile;
+use compile::{Inst, OneChar, CharClass, Any};
+use parse;
+use parse::{
+    Nothing, Literal, Dot, Class, Begin, End, Cat, Rep,
+    Repeater, ZeroOne, ZeroMore, OneMore,
+    FLAG_MULTI, FLAG_SEARCH, FLAG_FINALNL,
+};
+use encode;
+use encode::{Encoder, Decoder, DecodeError};
+use vm;
+
+/// The maximum number of items in an expression executed by `ShiftOr`.
+static MAX_ITEMS: uint = 64;
+
+/// ShiftOr is a bit-parallel matcher for a tiny expression.
+#[deriving(Clone)]
+pub struct ShiftOr {
+    // The instruction matching each item.
+    insts: Vec<Inst>,
+    // For every ASCII character, the items that match it.
+    masks: Vec<u64>,
+    // The items that may repeat.
+    repeat: u64,
+    // The items that may be skipped.
+    optional: u64,
+    // The bit of the last item.
+    last: u64,
+    // Whether the expression starts with `^` and ends with `$`.
+    begin: bool,
+    end: bool,
+}
+
+impl ShiftOr {
+    /// Returns a bit-parallel matcher for the expression given, or `None` if
+    /// the expression doesn't qualify.
+    pub fn new(ast: &parse::Ast) -> Option<ShiftOr> {
+        let mut so = ShiftOr {
+            insts: vec!(),
+            masks: Vec::from_elem(128, 0u64),
+            repeat: 0,
+            optional: 0,
+            last: 0,
+            begin: false,
+            end: false,
+        };
+        if !so.add(ast) || so.insts.len() == 0 {
+            return None
+        }
+        so.fill_masks();
+        Some(so)
+    }
+
+    // Computes the masks of the ASCII characters and the bit of the last
+    // item, once every item has been added.
+    fn fill_masks(&mut self) {
+        for (i, inst) in self.insts.iter().enumerate() {
+            for b in range(0u, 128) {
+                if vm::char_matches(inst, Some(b as u8 as char)) {
+                    *self.masks.get_mut(b) |= 1 << i;
+                }
+            }
+        }
+        self.last = 1 << (self.insts.len() - 1);
+    }
+
+    /// Encodes the items of this matcher. (Its masks are computed again
+    /// when it's decoded.)
+    pub fn encode(&self, e: &mut Encoder) {
+        e.write_uint(self.insts.len());
+        for inst in self.insts.iter() {
+            encode::encode_inst(e, inst);
+        }
+        e.write_u64(self.repeat);
+        e.write_u64(self.optional);
+        e.write_bool(self.begin);
+        e.write_bool(self.end);
+    }
+
+    /// Decodes a matcher encoded by `encode`. Its items must be between 1
+    /// and 64 single character instructions.
+    pub fn decode(d: &mut Decoder) -> Result<ShiftOr, DecodeError> {
+        let n = try!(d.read_len());
+        let mut insts = Vec::with_capacity(n);
+        for _ in range(0, n) {
+            let inst = try!(encode::decode_inst(d));
+            match inst {
+                OneChar(_, _) | CharClass(_, _) | Any(_) => insts.push(inst),
+                _ => return encode::invalid("The encoded bit-parallel \
+                                             matcher is invalid."),
+            }
+        }
+        if insts.len() == 0 || insts.len() > MAX_ITEMS {
+            return encode::invalid("The encoded bit-parallel matcher is \
+                                    invalid.")
+        }
+        let mut so = ShiftOr {
+            insts: insts,
+            masks: Vec::from_elem(128, 0u64),
+            repeat: try!(d.read_u64()),
+            optional: try!(d.read_u64()),
+            last: 0,
+            begin: try!(d.read_bool()),
+            end: try!(d.read_bool()),
+        };
+        so.fill_masks();
+        Ok(so)
+    }
+
+    // Adds the items of the expression given. Returns false if the
+    // expression doesn't qualify.
+    fn add(&mut self, ast: &parse::Ast) -> bool {
+        if self.end {
+            // Nothing may follow a `$`.
+            return match *ast { Nothing => true, _ => false }
+        }
+        match *ast {
+            Nothing => true,
+            Begin(flags) if flags & (FLAG_MULTI | FLAG_SEARCH) == 0 => {
+                if self.begin || self.insts.len() > 0 {
+                    return false
+                }
+                self.begin = true;
+                true
+            }
+            End(flags) if flags & (FLAG_MULTI | FLAG_FINALNL) == 0 => {
+                self.end = true;
+                true
+            }
+            Cat(ref xs) => xs.iter().all(|x| self.add(&**x)),
+            Rep(ref x, ref rep, _) => {
+                match item(&**x) {
+                    None => false,
+                    Some(inst) => self.push(inst, Some(rep.clone())),
+                }
+            }
+            ref x => {
+                match item(x) {
+                    None => false,
+                    Some(inst) => self.push(inst, None),
+                }
+            }
+        }
+    }
+
+    fn push(&mut self, inst: Inst, rep: Option<Repeater>) -> bool {
+        if self.insts.len() >= MAX_ITEMS {
+            return false
+        }
+        let bit = 1 << self.insts.len();
+        match rep {
+            None => {}
+            Some(ZeroOne) => self.optional |= bit,
+            Some(ZeroMore) => {
+                self.optional |= bit;
+                self.repeat |= bit;
+            }
+            Some(OneMore) => self.repeat |= bit,
+        }
+        self.insts.push(inst);
+        true
+    }
+
+    /// Searches the text between `start` and `end` in `input`. If there's a
+    /// match, a lower bound on where the leftmost-first match starts is
+    /// returned along with the end of the match that ends first.
+    pub fn exec(&self, input: &str, start: uint, end: uint)
+               -> Option<(uint, uint)> {
+        let mut state = 0u64;
+        let mut lower = start;
+        let mut ic = start;
+        loop {
+            // A new match may start here, unless the expression is anchored.
+            let init = if !self.begin || ic == 0 { 1 } else { 0 };
+            if state == 0 {
+                if init == 0 {
+                    return None
+                }
+                lower = ic;
+            }
+            let cur = self.skip_optional(state, init);
+            if cur & self.last > 0 && (!self.end || ic == input.len()) {
+                return Some((lower, ic))
+            }
+            if ic >= end {
+                return None
+            }
+            let next = input.char_range_at(ic);
+            let mask = self.mask(next.ch);
+            state = (((cur << 1) | init) & mask) | (cur & self.repeat & mask);
+            ic = next.next;
+        }
+    }
+
+    // Adds the items that can be reached from the state given by skipping
+    // optional items. `init` is `1` when a match may start at the current
+    // position.
+    #[inline]
+    fn skip_optional(&self, mut state: u64, init: u64) -> u64 {
+        loop {
+            let next = state | (((state << 1) | init) & self.optional);
+            if next == state {
+                return state
+            }
+            state = next;
+        }
+    }
+
+    // Returns the items matching the character given.
+    #[inline]
+    fn mask(&self, c: char) -> u64 {
+        if (c as u32) < 128 {
+            return *self.masks.get(c as uint)
+        }
+        let mut mask = 0;
+        for (i, inst) in self.insts.iter().enumerate() {
+            if vm::char_matches(inst, Some(c)) {
+                mask |= 1 << i;
+            }
+        }
+        mask
+    }
+
+    /// Returns the number of bytes that the matcher occupies on the heap.
+    pub fn heap_size(&self) -> uint {
+        compile::insts_size(&self.insts)
+        + self.masks.capacity() * mem::size_of::<u64>()
+    }
+}
+
+// Returns the instruction matching a single character expression.
+fn item(ast: &parse::Ast) -> Option<Inst> {
+    match *ast {
+        Literal(c, flags) => Some(OneChar(c, flags)),
+        Dot(flags) => Some(Any(flags)),
+        Class(ref ranges, flags) => {
+            Some(CharClass(CharSet::new(ranges.clone(), flags), flags))
+        }
+        _ => None,
+    }
+}
@@ -0,0 +1,158 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module counts what searches with a regex cost, for finding the
+// expensive ones among many regexes (e.g., ones given by users). Counting is
+// off unless a regex enables it, and then the counters are atomic, so that a
+// regex can be searched from many tasks at once and the counts read (or
+// reset) at any time.
+//
+// The counters are kept by the regex's `DfaCache`, which decides how most
+// searches are run, and they reach the engines along with the step budget
+// of a search (see `vm::Budget`). When counting is off, each place that
+// counts only checks that there are no counters.
+
+use std::sync::atomics::{AtomicUint, Relaxed};
+
+/// Engine names the ways in which a regex finds matches, whose uses are
+/// counted (see `Stats`).
+#[deriving(Clone, Eq, Show)]
+pub enum Engine {
+    /// The prefilter finds matches by itself, since the regex is a set of
+    /// literals.
+    EngineLiterals,
+    /// A DFA compiled ahead of time (see `Regex::compile_dfa`).
+    EngineFullDfa,
+    /// The bit-parallel engine, which finds matches of short regexes.
+    EngineShiftOr,
+    /// The lazy DFA, which finds where matches end (and then, running the
+    /// regex in reverse, where they start).
+    EngineDfa,
+    /// The NFA simulation.
+    EngineNfa,
+    /// The backtracking engine, for short texts and for regexes that only it
+    /// can match (e.g., ones with lookaround).
+    EngineBacktrack,
+    /// The one-pass engine, which finds capture groups in a single scan.
+    EngineOnePass,
+    /// The engine that finds capture groups by the POSIX rules.
+    EnginePosix,
+}
+
+static NUM_ENGINES: uint = 8;
+
+/// Stats are the counts of what searches with a regex cost, as returned by
+/// `Regex::stats`. Each count is of all the searches since counting was
+/// enabled (or since the counts were last taken with `Regex::take_stats`).
+#[deriving(Clone, Eq, Show)]
+pub struct Stats {
+    /// The number of searches.
+    pub searches: uint,
+    /// The number of bytes between the start and the end of the searches.
+    /// (A search may stop before its end, or look at no byte at all.)
+    pub bytes: uint,
+    /// The number of searches that found a match.
+    pub matches: uint,
+    /// The number of positions at which the prefilter said that a match
+    /// could start, and so an engine had to look.
+    pub candidates: uint,
+    /// The number of times the states of a lazy DFA were thrown away,
+    /// because they took more memory than the DFA size limit allows.
+    pub dfa_clears: uint,
+    engines: Vec<uint>,
+}
+
+impl Stats {
+    /// Returns the number of times that the engine given ran. A search can
+    /// run more than one (e.g., the lazy DFA finds where a match is, and
+    /// then the NFA finds its capture groups).
+    pub fn runs(&self, engine: Engine) -> uint {
+        *self.engines.get(engine as uint)
+    }
+}
+
+/// The counters of a regex whose searches are counted.
+pub struct Counters {
+    searches: AtomicUint,
+    bytes: AtomicUint,
+    matches: AtomicUint,
+    candidates: AtomicUint,
+    dfa_clears: AtomicUint,
+    engines: Vec<AtomicUint>,
+}
+
+impl Counters {
+    /// Returns counters that are all zero.
+    pub fn new() -> Counters {
+        Counters {
+            searches: AtomicUint::new(0),
+            bytes: AtomicUint::new(0),
+            matches: AtomicUint::new(0),
+            candidates: AtomicUint::new(0),
+            dfa_clears: AtomicUint::new(0),
+            engines: Vec::from_fn(NUM_ENGINES, |_| AtomicUint::new(0)),
+        }
+    }
+
+    /// Counts a search of `len` bytes, and whether it found a match.
+    #[inline]
+    pub fn search(&self, len: uint, matched: bool) {
+        self.searches.fetch_add(1, Relaxed);
+        self.bytes.fetch_add(len, Relaxed);
+        if matched {
+            self.matches.fetch_add(1, Relaxed);
+        }
+    }
+
+    /// Counts a position reported by a prefilter.
+    #[inline]
+    pub fn candidate(&self) {
+        self.candidates.fetch_add(1, Relaxed);
+    }
+
+    /// Counts the states of a lazy DFA being thrown away.
+    #[inline]
+    pub fn dfa_clear(&self) {
+        self.dfa_clears.fetch_add(1, Relaxed);
+    }
+
+    /// Counts a run of the engine given.
+    #[inline]
+    pub fn engine(&self, engine: Engine) {
+        self.engines.get(engine as uint).fetch_add(1, Relaxed);
+    }
+
+    /// Returns the counts.
+    pub fn load(&self) -> Stats {
+        self.read(|n| n.load(Relaxed))
+    }
+
+    /// Returns the counts and sets them to zero. A count that goes up while
+    /// they're taken is either in the counts returned or left for the next
+    /// ones, but never lost.
+    pub fn take(&self) -> Stats {
+        self.read(|n| n.swap(0, Relaxed))
+    }
+
+    fn read(&self, get: |&AtomicUint| -> uint) -> Stats {
+        let mut engines = Vec::with_capacity(NUM_ENGINES);
+        for n in self.engines.iter() {
+            engines.push(get(n));
+        }
+        Stats {
+            searches: get(&self.searches),
+            bytes: get(&self.bytes),
+            matches: get(&self.matches),
+            candidates: get(&self.candidates),
+            dfa_clears: get(&self.dfa_clears),
+            engines: engines,
+        }
+    }
+}
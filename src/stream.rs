@@ -0,0 +1,868 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// This module searches text read from a `Reader` (or written to a `Writer`)
+// without holding all of it in memory. The text is searched in a sliding
+// window.
+//
+// The difficulty is deciding when a match found in the window is final,
+// since the text that hasn't been read yet could change it. If no match is
+// longer than `width` bytes, then every match starting at position `s` ends
+// by `s + width`. Deciding whether it matches there may require looking at
+// one more character (for `$` and `\b`). So if the window extends at least
+// `width` bytes and one character beyond `s`, then every match starting at
+// or before `s` is known, and in particular, so is the leftmost-first one.
+//
+// When the longest match can't be computed (e.g., for `a.*b`), whether a
+// match is final is decided by running the simulation of matcher.rs over the
+// window, as if more text followed it. A match found is final once no thread
+// that would be preferred to it is alive at the end of the window, and no
+// match can start before the earliest start of a thread that is. The window
+// grows to keep the text from there, up to the caller's maximum length of a
+// match, past which searching stops with an error rather than cutting a
+// match short or missing it.
+//
+// Once the window has been searched up to the point at which matches are no
+// longer final, the text before that point is given to the caller and
+// dropped from the window. The character just before it is kept, so that
+// `^`, `\b` and `\B` still see what precedes the rest of the window.
+//
+// The matches found are the ones that `find_iter` finds in all of the text,
+// including how empty matches are handled, unless searching stops with an
+// error.
+//
+// Text that's already in memory in segments is searched where it is. Only
+// the bytes of windows spanning the end of a segment are copied.
+
+use collections::{Deque, RingBuf};
+use std::cmp;
+use std::io::{IoResult, IoError, Reader, Writer};
+use std::io::{EndOfFile, InvalidInput, OtherIoError, standard_error};
+use std::mem;
+use std::str;
+use std::uint;
+use sync::Arc;
+
+use compile::Program;
+use matcher;
+use re;
+use re::{Regex, Captures, Replacer, accept_match};
+
+/// The number of bytes read from a stream at a time.
+pub static CHUNK_SIZE: uint = 64 * 1024;
+
+// The number of bytes following a match needed to decide it: one character
+// (or, for `\Z`, a new line and whether any byte follows it).
+static LOOKAHEAD: uint = 4;
+
+/// Searches all of the text read from `src` for non-overlapping matches of
+/// `re`, in the same way as `find_iter`. `max_len` bounds the length of a
+/// match when the regex doesn't, and a match that may be longer is an error
+/// (see `Regex::stream_replace_all`).
+///
+/// The text is given to `each` in order and in pieces. Each piece is
+/// either text that isn't part of a match (with `None`) or the text of a
+/// match along with its capture groups. The positions of the capture groups
+/// are relative to the window, whose position in the stream is given as the
+/// first argument.
+///
+/// The number of matches is returned. It's an error if the stream isn't
+/// valid UTF-8.
+pub fn scan(re: &Regex, src: &mut Reader, max_len: uint,
+            each: |uint, &str, Option<&Captures>| -> IoResult<()>)
+           -> IoResult<uint> {
+    let mut win = Window::new(re, max_len);
+    let mut chunk = Vec::from_elem(CHUNK_SIZE, 0u8);
+    let mut n = 0;
+    loop {
+        let eof = try!(fill(&mut win, src, chunk.as_mut_slice()));
+        n += try!(win.search(re, eof, |base, text, caps| {
+            each(base, text, caps)
+        }));
+        if eof {
+            return Ok(n)
+        }
+    }
+}
+
+/// Searches the text read from `src` for non-overlapping matches of `re`,
+/// like `scan`, and gives the position of each match in the stream and its
+/// text to `each`. Searching stops when `each` returns false.
+pub fn scan_matches(re: &Regex, src: &mut Reader, max_len: uint,
+                    each: |uint, uint, &str| -> bool) -> IoResult<()> {
+    let mut win = Window::new(re, max_len);
+    let mut chunk = Vec::from_elem(CHUNK_SIZE, 0u8);
+    let mut done = false;
+    while !done {
+        let eof = try!(fill(&mut win, src, chunk.as_mut_slice()));
+        try!(win.search(re, eof, |base, text, caps| {
+            match caps {
+                Some(caps) if !done => {
+                    let (s, e) = caps.pos(0).unwrap();
+                    done = !each(base + s, base + e, text);
+                }
+                _ => {}
+            }
+            Ok(())
+        }));
+        done = done || eof;
+    }
+    Ok(())
+}
+
+/// Returns true if there's a match of `re` in the text made of the bytes of
+/// `segs`, one after the other, like `is_match`. A character may be split
+/// between segments.
+pub fn is_match_segments(re: &Regex, segs: &[&[u8]], max_len: uint)
+                        -> IoResult<bool> {
+    let mut win = Window::new(re, max_len);
+    let mut found = false;
+    try!(each_window(&mut win, segs, |win, bytes, eof| {
+        let (matched, keep) = try!(win.is_match_bytes(re, bytes, eof));
+        found = matched;
+        Ok(if found { None } else { Some(keep) })
+    }));
+    Ok(found)
+}
+
+/// Returns the positions of the non-overlapping matches of `re` in the text
+/// made of the bytes of `segs`, like `scan_matches`. The positions are
+/// offsets from the start of the first segment. At most `limit` matches are
+/// found, unless it's `0`.
+pub fn find_segments(re: &Regex, segs: &[&[u8]], limit: uint, max_len: uint)
+                    -> IoResult<Vec<(uint, uint)>> {
+    let mut win = Window::new(re, max_len);
+    let mut matches = vec!();
+    try!(each_window(&mut win, segs, |win, bytes, eof| {
+        let (_, keep) = try!(win.search_bytes(re, bytes, eof,
+                                              |base, _, caps| {
+            match caps {
+                Some(caps) if limit == 0 || matches.len() < limit => {
+                    let (s, e) = caps.pos(0).unwrap();
+                    matches.push((base + s, base + e));
+                }
+                _ => {}
+            }
+            Ok(())
+        }));
+        Ok(if limit == 0 || matches.len() < limit { keep } else { None })
+    }));
+    Ok(matches)
+}
+
+// Searches the text made of the bytes of `segs` a window at a time, by
+// calling `search` with the bytes of each window and whether the text ends
+// with them. `search` returns the position in the window before which the
+// text has been searched completely, or `None` to stop.
+//
+// A window that's within a segment is searched where it is. One that spans
+// the end of a segment is copied into a buffer along with the start of the
+// segments that follow, until searching moves past the end.
+fn each_window(win: &mut Window, segs: &[&[u8]],
+               search: |&mut Window, &[u8], bool| -> IoResult<Option<uint>>)
+              -> IoResult<()> {
+    // The bytes of a window that spans the end of a segment.
+    let mut carry: Vec<u8> = vec!();
+    for (i, seg) in segs.iter().enumerate() {
+        let last = i + 1 == segs.len();
+        // The position in `seg` of the end of the window, while it spans
+        // the end of a segment, and of its start after that.
+        let mut pos = 0;
+        while carry.len() > 0 {
+            let want = win.wants();
+            let n =
+                if carry.len() < want {
+                    cmp::min(seg.len() - pos, want - carry.len())
+                } else {
+                    0
+                };
+            carry.push_all(seg.slice(pos, pos + n));
+            pos += n;
+            let eof = last && pos == seg.len();
+            if !eof && carry.len() < want {
+                // The bytes of the window continue in the next segment.
+                break
+            }
+            let keep = match try!(search(&mut *win, carry.as_slice(), eof)) {
+                Some(keep) if !eof => keep,
+                _ => return Ok(()),
+            };
+            let start = context_start(carry.as_slice(), keep);
+            win.advance(keep, start);
+            let rest = carry.len() - start;
+            if rest <= pos {
+                // The window is within this segment now.
+                pos -= rest;
+                carry.truncate(0);
+            } else {
+                carry = Vec::from_slice(carry.slice_from(start));
+            }
+        }
+        while carry.len() == 0 {
+            let end = cmp::min(seg.len(), pos + win.wants());
+            let eof = last && end == seg.len();
+            if end == seg.len() && !eof {
+                carry.push_all(seg.slice_from(pos));
+                break
+            }
+            let bytes = seg.slice(pos, end);
+            let keep = match try!(search(&mut *win, bytes, eof)) {
+                Some(keep) if !eof => keep,
+                _ => return Ok(()),
+            };
+            let start = context_start(bytes, keep);
+            win.advance(keep, start);
+            pos += start;
+        }
+    }
+    // Only an empty list of segments gets here.
+    try!(search(win, carry.as_slice(), true));
+    Ok(())
+}
+
+// Reads from `src` until the window is full or the stream ends. Returns
+// true if the stream ended.
+fn fill(win: &mut Window, src: &mut Reader, chunk: &mut [u8])
+       -> IoResult<bool> {
+    while !win.is_full() {
+        match src.read(chunk) {
+            Ok(k) => win.push(chunk.slice_to(k)),
+            Err(ref err) if err.kind == EndOfFile => return Ok(true),
+            Err(err) => return Err(err),
+        }
+    }
+    Ok(false)
+}
+
+/// Window is the text of a stream that hasn't been searched completely yet.
+pub struct Window {
+    buf: Vec<u8>,
+    // The length of the longest match.
+    width: uint,
+    // The program of the regex when it doesn't bound the length of its
+    // matches, so that `width` is the caller's bound, and whether matches
+    // are final is decided by `matcher::settle`.
+    unbounded: Option<Arc<Program>>,
+    longest: bool,
+    // The number of bytes at the start of `buf` that were already searched.
+    // They're only kept for assertions.
+    ctx: uint,
+    // The position of the start of `buf` in the stream.
+    base: uint,
+    // Where the last match ended, relative to `buf`.
+    last_match: Option<uint>,
+    // Whether the regex uses `\G`, which can't be searched for in a
+    // window (since the window moves, not the search).
+    search_start: bool,
+    // Whether the regex uses `\b{g}` or `\b{wb}`, which can depend on more
+    // of the text before a position than the window keeps.
+    segments: bool,
+    // Whether the regex uses lookahead or lookbehind, which can depend on
+    // more of the text around a match than the window keeps.
+    lookaround: bool,
+}
+
+impl Window {
+    /// Returns an empty window for searching a stream with `re`. `max_len`
+    /// bounds the length of a match when the regex doesn't, like in `scan`.
+    pub fn new(re: &Regex, max_len: uint) -> Window {
+        let prog = re::program(re);
+        let (width, unbounded) = match re.max_match_len() {
+            Some(width) => (width, None),
+            None => (max_len, Some(prog.clone())),
+        };
+        Window {
+            buf: Vec::with_capacity(width + LOOKAHEAD + 2 * CHUNK_SIZE),
+            width: width,
+            unbounded: unbounded,
+            longest: re.is_longest(),
+            ctx: 0,
+            base: 0,
+            last_match: None,
+            search_start: prog.search_start,
+            segments: prog.segments,
+            lookaround: prog.lookaround,
+        }
+    }
+
+    /// Appends text read from the stream.
+    pub fn push(&mut self, bytes: &[u8]) {
+        self.buf.push_all(bytes)
+    }
+
+    // Returns an error if the regex uses an assertion that can't be
+    // evaluated in a window.
+    fn supported(&self) -> IoResult<()> {
+        if self.search_start {
+            return Err(search_start_unsupported())
+        }
+        if self.segments {
+            return Err(segments_unsupported())
+        }
+        if self.lookaround {
+            return Err(lookaround_unsupported())
+        }
+        match self.unbounded {
+            Some(ref prog) if !matcher::can_settle(&**prog) => {
+                return Err(unbounded_unsupported())
+            }
+            _ => {}
+        }
+        Ok(())
+    }
+
+    /// Returns true when the window holds enough text to be worth
+    /// searching. Searching less text is correct but slow, since any text
+    /// that might be the start of a match is searched again.
+    pub fn is_full(&self) -> bool {
+        self.buf.len() >= self.wants()
+    }
+
+    // Returns the number of bytes that a full window holds.
+    fn wants(&self) -> uint {
+        self.ctx + self.width + LOOKAHEAD + CHUNK_SIZE
+    }
+
+    // Returns what `matcher::settle` decides about the first match starting
+    // at or after `from` in `text`, or `None` if `width` decides it instead
+    // (because it's the length of the longest match, or no text follows).
+    fn settle(&self, text: &str, from: uint, eof: bool)
+             -> Option<Result<Option<(uint, uint)>, (uint, bool)>> {
+        match self.unbounded {
+            Some(ref prog) if !eof => {
+                Some(matcher::settle(&**prog, self.longest, text, from))
+            }
+            _ => None,
+        }
+    }
+
+    // Returns an error if no match can start before `safe` in a window of
+    // `len` bytes, and a match that starts there may be longer than the
+    // caller's bound.
+    fn check_pending(&self, len: uint, safe: uint) -> IoResult<()> {
+        if self.unbounded.is_some() && len - safe > self.width + LOOKAHEAD {
+            return Err(match_too_long())
+        }
+        Ok(())
+    }
+
+    /// Forgets the stream searched so far.
+    pub fn reset(&mut self) {
+        self.buf.truncate(0);
+        self.ctx = 0;
+        self.base = 0;
+        self.last_match = None;
+    }
+
+    /// Searches the window for matches that can't be changed by the text
+    /// that follows, and gives the text up to the last of them to `each`,
+    /// exactly like `scan`. If `eof` is true, then no text follows, so all
+    /// of the text is given to `each` and the window is reset.
+    ///
+    /// The number of matches found is returned. It's an error if the text
+    /// isn't valid UTF-8, if the regex uses `\G`, `\b{g}` or `\b{wb}`, or
+    /// if it doesn't bound the length of its matches and one may be longer
+    /// than the maximum given to `new`.
+    pub fn search(&mut self, re: &Regex, eof: bool,
+                  each: |uint, &str, Option<&Captures>| -> IoResult<()>)
+                 -> IoResult<uint> {
+        let buf = mem::replace(&mut self.buf, vec!());
+        let searched = self.search_bytes(re, buf.as_slice(), eof, each);
+        self.buf = buf;
+        let (n, keep) = try!(searched);
+        match keep {
+            None => self.reset(),
+            Some(keep) => self.discard(keep),
+        }
+        Ok(n)
+    }
+
+    // Searches `bytes`, which hold the text of the window, like `search`,
+    // and returns the number of matches found and the position before
+    // which the text has been searched completely (or `None` if `eof` is
+    // true). The window isn't moved.
+    fn search_bytes(&mut self, re: &Regex, bytes: &[u8], eof: bool,
+                    each: |uint, &str, Option<&Captures>| -> IoResult<()>)
+                   -> IoResult<(uint, Option<uint>)> {
+        try!(self.supported())
+        let base = self.base;
+        let mut n = 0;
+        let text = try!(window_text(bytes, eof));
+        let mut safe = final_before(text.len(), self.width, eof);
+        let (mut written, mut last_end) = (self.ctx, self.ctx);
+        while last_end <= text.len() {
+            match self.settle(text, last_end, eof) {
+                None => {}
+                Some(Ok(_)) => safe = text.len() + 1,
+                Some(Err((start, _))) => safe = start,
+            }
+            let caps = match re.captures_at(text, last_end) {
+                None => break,
+                Some(caps) => caps,
+            };
+            let (s, e) = caps.pos(0).unwrap();
+            if s >= safe {
+                break
+            }
+            if !accept_match(text, (s, e), &mut last_end,
+                             &mut self.last_match) {
+                continue
+            }
+            try!(each(base, text.slice(written, s), None));
+            try!(each(base, text.slice(s, e), Some(&caps)));
+            written = e;
+            n += 1;
+        }
+        if eof {
+            try!(each(base, text.slice_from(written), None));
+            return Ok((n, None))
+        }
+        // No match can start before `keep` anymore.
+        let keep = cmp::max(floor_char(text, safe),
+                            cmp::max(written, last_end));
+        try!(each(base, text.slice(written, keep), None));
+        try!(self.check_pending(text.len(), keep));
+        Ok((n, Some(keep)))
+    }
+
+    /// Returns true if there's a match that starts in the window and can't
+    /// be changed by the text that follows. If `eof` is true, then no text
+    /// follows, and the window is reset. Otherwise, the text that has been
+    /// searched completely is dropped.
+    ///
+    /// It's an error if the text isn't valid UTF-8, if the regex uses `\G`,
+    /// `\b{g}` or `\b{wb}`, or if it doesn't bound the length of its
+    /// matches and one may be longer than the maximum given to `new`.
+    pub fn is_match(&mut self, re: &Regex, eof: bool) -> IoResult<bool> {
+        let buf = mem::replace(&mut self.buf, vec!());
+        let searched = self.is_match_bytes(re, buf.as_slice(), eof);
+        self.buf = buf;
+        let (found, keep) = try!(searched);
+        if eof {
+            self.reset()
+        } else {
+            self.discard(keep)
+        }
+        Ok(found)
+    }
+
+    // Searches `bytes`, which hold the text of the window, like `is_match`,
+    // and returns whether there's a match and the position before which
+    // the text has been searched completely. The window isn't moved.
+    fn is_match_bytes(&mut self, re: &Regex, bytes: &[u8], eof: bool)
+                     -> IoResult<(bool, uint)> {
+        try!(self.supported())
+        let text = try!(window_text(bytes, eof));
+        let (found, safe) = match self.settle(text, self.ctx, eof) {
+            None => {
+                let safe = final_before(text.len(), self.width, eof);
+                // If the leftmost match starts at `safe` or later, then no
+                // match can start before `safe`.
+                let found = match re.find_at(text, self.ctx) {
+                    None => false,
+                    Some((s, _)) => s < safe,
+                };
+                (found, safe)
+            }
+            Some(Ok(m)) => (m.is_some(), text.len()),
+            // A match that's found is a match, even if it may still change.
+            Some(Err((start, matched))) => (matched, start),
+        };
+        let keep = cmp::max(floor_char(text, safe), self.ctx);
+        if !found && !eof {
+            try!(self.check_pending(text.len(), keep));
+        }
+        self.last_match = None;
+        Ok((found, keep))
+    }
+
+    // Drops the text before `keep`, other than the character preceding it.
+    fn discard(&mut self, keep: uint) {
+        let start = context_start(self.buf.as_slice(), keep);
+        self.advance(keep, start);
+        // The text kept is moved to the start of the buffer, rather than
+        // copied to a new one.
+        let rest = self.buf.len() - start;
+        for i in range(0, rest) {
+            let b = *self.buf.get(start + i);
+            *self.buf.get_mut(i) = b;
+        }
+        self.buf.truncate(rest);
+    }
+
+    // Moves the start of the window to `start`, where the text before
+    // `keep` has been searched completely.
+    fn advance(&mut self, keep: uint, start: uint) {
+        self.last_match = match self.last_match {
+            Some(e) if e == keep => Some(keep - start),
+            _ => None,
+        };
+        self.ctx = keep - start;
+        self.base += start;
+    }
+}
+
+// Returns the position in `bytes` of the character preceding `keep`, which
+// a window that drops the text before `keep` starts with.
+fn context_start(bytes: &[u8], keep: uint) -> uint {
+    if keep == 0 {
+        return 0
+    }
+    let text = str::from_utf8(bytes.slice_to(keep)).unwrap();
+    text.char_range_at_reverse(keep).next
+}
+
+/// Returns true if there's a match of `re` in the text read from `src`.
+/// Reading stops as soon as a match is found. `max_len` bounds the length
+/// of a match when the regex doesn't, like in `scan`.
+pub fn is_match(re: &Regex, src: &mut Reader, max_len: uint)
+               -> IoResult<bool> {
+    let mut win = Window::new(re, max_len);
+    let mut chunk = Vec::from_elem(CHUNK_SIZE, 0u8);
+    loop {
+        let eof = try!(fill(&mut win, src, chunk.as_mut_slice()));
+        if try!(win.is_match(re, eof)) {
+            return Ok(true)
+        }
+        if eof {
+            return Ok(false)
+        }
+    }
+}
+
+/// Splitter reads the fields of a stream that are delimited by the matches
+/// of a regex. It's created by `Regex::splitter`, or by `Regex::tokenizer`,
+/// in which case the fields are the matches themselves.
+///
+/// The fields are exactly the substrings that `Regex::split` would yield for
+/// the entire text of the stream. In particular, an empty match splits the
+/// text between characters (after which searching resumes one character
+/// later), a delimiter at the start of the stream yields an empty field
+/// first, and no empty field follows a delimiter at the end of the stream.
+/// The fields of a tokenizer are exactly the matches that
+/// `Regex::find_iter` would find.
+///
+/// `'r` is the lifetime of the compiled expression and `R` is the type of
+/// the reader.
+pub struct Splitter<'r, R> {
+    re: &'r Regex,
+    src: R,
+    win: Window,
+    chunk: Vec<u8>,
+    // The part of the current field that has been read.
+    field: StrBuf,
+    // Fields that are complete but haven't been returned yet.
+    ready: RingBuf<~str>,
+    // Whether the fields are the matches rather than the text between them.
+    matches: bool,
+    max_field_len: uint,
+    // Whether a field longer than the maximum was found.
+    long: bool,
+    eof: bool,
+}
+
+impl<'r, R: Reader> Splitter<'r, R> {
+    /// Returns the next field of the stream. When there are no more fields,
+    /// an error of kind `EndOfFile` is returned (just like
+    /// `Buffer::read_line`).
+    ///
+    /// An error is also returned if reading fails, if the text read isn't
+    /// valid UTF-8, or if a field is longer than the maximum field length.
+    pub fn next_field(&mut self) -> IoResult<~str> {
+        loop {
+            match self.ready.pop_front() {
+                Some(field) => return Ok(field),
+                None => {}
+            }
+            if self.long {
+                return Err(field_too_long())
+            }
+            if self.eof {
+                return Err(standard_error(EndOfFile))
+            }
+            let eof = try!(fill(&mut self.win, &mut self.src as &mut Reader,
+                                self.chunk.as_mut_slice()));
+            let max = self.max_field_len;
+            let mut long = false;
+            let (field, ready) = (&mut self.field, &mut self.ready);
+            let matches = self.matches;
+            try!(self.win.search(self.re, eof, |_, text, caps| {
+                if long {
+                    return Ok(())
+                }
+                match caps {
+                    None if matches => {}
+                    None => field.push_str(text),
+                    Some(_) if matches => {
+                        long = text.len() > max;
+                        ready.push_back(text.to_owned())
+                    }
+                    Some(_) => {
+                        let done = mem::replace(field, StrBuf::new());
+                        ready.push_back(done.into_owned())
+                    }
+                }
+                long = long || field.len() > max;
+                Ok(())
+            }));
+            if long {
+                // The fields before the long one are still returned.
+                if matches {
+                    ready.pop_back();
+                }
+                self.eof = true;
+                self.long = true;
+            } else if eof {
+                self.eof = true;
+                if field.len() > 0 {
+                    let done = mem::replace(field, StrBuf::new());
+                    ready.push_back(done.into_owned());
+                }
+            }
+        }
+    }
+
+    /// Returns the maximum length in bytes of a field.
+    pub fn max_field_len(&self) -> uint {
+        self.max_field_len
+    }
+
+    /// Sets the maximum length in bytes of a field. When a longer field is
+    /// read, `next_field` returns an error instead of holding all of it in
+    /// memory. There's no maximum by default.
+    pub fn set_max_field_len(&mut self, max: uint) {
+        self.max_field_len = max
+    }
+
+    /// Returns the underlying reader.
+    pub fn unwrap(self) -> R {
+        self.src
+    }
+}
+
+/// Returns a reader of the fields delimited by `re` in the text read from
+/// `src`, or of the matches of `re` if `matches` is true. See
+/// `Regex::splitter` and `Regex::tokenizer`.
+pub fn splitter<'r, R: Reader>(re: &'r Regex, src: R, max_len: uint,
+                               matches: bool) -> Splitter<'r, R> {
+    Splitter {
+        re: re,
+        src: src,
+        win: Window::new(re, max_len),
+        chunk: Vec::from_elem(CHUNK_SIZE, 0u8),
+        field: StrBuf::new(),
+        ready: RingBuf::new(),
+        matches: matches,
+        max_field_len: uint::MAX,
+        long: false,
+        eof: false,
+    }
+}
+
+// Returns the text in a window. Unless the stream has ended, a character
+// at the end of the window may be incomplete, so it's left out.
+fn window_text<'a>(buf: &'a [u8], eof: bool) -> IoResult<&'a str> {
+    let valid = if eof { buf.len() } else { utf8_prefix(buf) };
+    match str::from_utf8(buf.slice_to(valid)) {
+        None => Err(invalid_utf8()),
+        Some(text) => Ok(text),
+    }
+}
+
+// Returns the position before which every match starting in a window of
+// `len` bytes is final.
+fn final_before(len: uint, width: uint, eof: bool) -> uint {
+    if eof {
+        len + 1
+    } else if len > width + LOOKAHEAD {
+        len - width - LOOKAHEAD
+    } else {
+        0
+    }
+}
+
+// Returns the last character boundary in `text` at or before `i`.
+pub fn floor_char(text: &str, i: uint) -> uint {
+    let mut i = cmp::min(i, text.len());
+    while !text.is_char_boundary(i) {
+        i -= 1;
+    }
+    i
+}
+
+/// ReplaceWriter is a writer that replaces all non-overlapping matches of a
+/// regex in the text written to it, and writes the result to another
+/// writer. It's created by `Regex::replace_writer`.
+///
+/// Text that might still be part of a match is held back until enough text
+/// follows it (or `finish` is called), so writes to the underlying writer
+/// lag behind. In particular, `flush` only flushes the text that has been
+/// written to the underlying writer. Text written to a `ReplaceWriter` must
+/// be valid UTF-8, but a character may be split between writes.
+///
+/// `'r` is the lifetime of the compiled expression, `R` is the type of the
+/// replacement and `W` is the type of the underlying writer.
+pub struct ReplaceWriter<'r, R, W> {
+    re: &'r Regex,
+    rep: R,
+    w: W,
+    win: Window,
+    n: uint,
+}
+
+impl<'r, R: Replacer, W: Writer> ReplaceWriter<'r, R, W> {
+    /// Replaces the matches in all of the text held back, as if no more
+    /// text followed it. The number of matches replaced since the writer
+    /// was created (or reset) is returned.
+    ///
+    /// Text written after calling `finish` starts a new stream, so `^` may
+    /// match at its start.
+    pub fn finish(&mut self) -> IoResult<uint> {
+        try!(self.replace(true));
+        let n = self.n;
+        self.n = 0;
+        Ok(n)
+    }
+
+    /// Drops the text held back and forgets all partial matches, so that
+    /// the text written next starts a new stream.
+    pub fn reset(&mut self) {
+        self.win.reset();
+        self.n = 0;
+    }
+
+    /// Returns a reference to the underlying writer.
+    pub fn get_ref<'a>(&'a self) -> &'a W {
+        &self.w
+    }
+
+    /// Returns the underlying writer. Text that is held back is lost, so
+    /// `finish` should be called first.
+    pub fn unwrap(self) -> W {
+        self.w
+    }
+
+    fn replace(&mut self, eof: bool) -> IoResult<()> {
+        let (w, rep) = (&mut self.w, &mut self.rep);
+        self.n += try!(self.win.search(self.re, eof, |_, text, caps| {
+            match caps {
+                None => w.write_str(text),
+                Some(caps) => w.write_str(rep.reg_replace(caps).as_slice()),
+            }
+        }));
+        Ok(())
+    }
+}
+
+impl<'r, R: Replacer, W: Writer> Writer for ReplaceWriter<'r, R, W> {
+    fn write(&mut self, buf: &[u8]) -> IoResult<()> {
+        self.win.push(buf);
+        if self.win.is_full() {
+            try!(self.replace(false));
+        }
+        Ok(())
+    }
+
+    fn flush(&mut self) -> IoResult<()> {
+        self.w.flush()
+    }
+}
+
+/// Returns a writer that replaces the matches of `re` in the text written
+/// to it. See `Regex::replace_writer`.
+pub fn replace_writer<'r, R: Replacer, W: Writer>
+                     (re: &'r Regex, w: W, max_len: uint, rep: R)
+                     -> ReplaceWriter<'r, R, W> {
+    ReplaceWriter {
+        re: re,
+        rep: rep,
+        w: w,
+        win: Window::new(re, max_len),
+        n: 0,
+    }
+}
+
+// Returns the length of the longest prefix of `bytes` that doesn't end in
+// the middle of a UTF-8 encoded character.
+pub fn utf8_prefix(bytes: &[u8]) -> uint {
+    let len = bytes.len();
+    let mut i = len;
+    while i > 0 && len - i < 4 {
+        i -= 1;
+        let b = bytes[i];
+        if b & 0xC0 != 0x80 {
+            // This byte starts a character.
+            let width =
+                if b < 0x80 { 1 }
+                else if b >= 0xF0 { 4 }
+                else if b >= 0xE0 { 3 }
+                else { 2 };
+            return if i + width > len { i } else { len }
+        }
+    }
+    len
+}
+
+fn field_too_long() -> IoError {
+    IoError {
+        kind: OtherIoError,
+        desc: "field is longer than the maximum field length",
+        detail: None,
+    }
+}
+
+fn search_start_unsupported() -> IoError {
+    IoError {
+        kind: InvalidInput,
+        desc: "\\G is not supported when searching streams",
+        detail: None,
+    }
+}
+
+fn segments_unsupported() -> IoError {
+    IoError {
+        kind: InvalidInput,
+        desc: "\\b{g} and \\b{wb} are not supported when searching streams",
+        detail: None,
+    }
+}
+
+fn lookaround_unsupported() -> IoError {
+    IoError {
+        kind: InvalidInput,
+        desc: "lookaround is not supported when searching streams",
+        detail: None,
+    }
+}
+
+fn unbounded_unsupported() -> IoError {
+    IoError {
+        kind: InvalidInput,
+        desc: "backreferences, \\K and \\Z are not supported when searching \
+               streams for matches of unbounded length",
+        detail: None,
+    }
+}
+
+fn match_too_long() -> IoError {
+    IoError {
+        kind: OtherIoError,
+        desc: "a match may be longer than the maximum length of a match",
+        detail: None,
+    }
+}
+
+pub fn invalid_utf8() -> IoError {
+    IoError {
+        kind: InvalidInput,
+        desc: "stream is not valid UTF-8",
+        detail: None,
+    }
+}
@@ -0,0 +1,383 @@
+// Copyright 2014 The Rust Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// http://rust-lang.org/COPYRIGHT.
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0> or the MIT license
+// <LICENSE-MIT or http://opensource.org/licenses/MIT>, at your
+// option. This file may not be copied, modified, or distributed
+// except according to those terms.
+
+// Replacement templates are parsed once into a sequence of literal text and
+// references to capture groups, so that expanding a template for every match
+// doesn't parse it again.
+//
+// `Template::parse` rejects malformed references like `${name` or `${}`.
+// `Captures::expand` (and every replacement given as a string) parses its
+// template leniently instead, keeping malformed references as literal text,
+// since it has no way to report an error.
+//
+// A replacement that preserves case (see `PreserveCase`) is expanded the same
+// way, except that its literal text is cased like the match. Only the literal
+// text is changed: what a group matched is already cased like the text.
+
+use std::fmt;
+use std::from_str::from_str;
+use std::str;
+use std::str::{MaybeOwned, Owned};
+
+use re::{Captures, Replacer};
+
+/// Template is a parsed replacement template, which can be expanded for many
+/// matches without being parsed again.
+///
+/// In a template, `$name` and `${name}` are replaced by the capture group
+/// `name`, which is either the index of a group (e.g., `$1`) or its name (of
+/// letters, digits and underscores). The braces separate the name from the
+/// text that follows, as in `${1}a`. `${name:-default}` is replaced by
+/// `default` when the group didn't take part in the match (the default
+/// ends at the first `}`). `$$` is a literal `$`, as is a `$` that isn't
+/// followed by a name or `{`.
+///
+/// A reference to a group that doesn't exist is replaced by the empty string
+/// (or its default).
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::{Regex, Template};
+/// let re = Regex::new(r"(?P<key>\w+)(=(?P<val>\w+))?").unwrap();
+/// let tmpl = Template::parse("${key}: ${val:-none}").unwrap();
+/// let caps = re.captures("debug").unwrap();
+/// assert_eq!(tmpl.expand(&caps).as_slice(), "debug: none");
+/// assert_eq!(re.replace_all("a=1 b", &tmpl).as_slice(), "a: 1 b: none");
+/// ```
+#[deriving(Clone, Show)]
+pub struct Template {
+    pieces: Vec<Piece>,
+}
+
+#[deriving(Clone, Show)]
+enum Piece {
+    Text(~str),
+    // The group (as it was written) and its default, if any.
+    Group(~str, Option<~str>),
+}
+
+/// TemplateError describes a malformed replacement template.
+#[deriving(Clone)]
+pub struct TemplateError {
+    /// The character index of where the malformed reference starts.
+    pub pos: uint,
+    /// A message describing the error.
+    pub msg: ~str,
+}
+
+impl fmt::Show for TemplateError {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f.buf, "Template error near position {}: {}",
+               self.pos, self.msg)
+    }
+}
+
+impl Template {
+    /// Parses a replacement template. An error is returned if a reference
+    /// within braces is malformed (e.g., it isn't closed or the name is
+    /// empty).
+    pub fn parse(text: &str) -> Result<Template, TemplateError> {
+        parse(text, true)
+    }
+
+    /// Expands the template for a match with the capture groups `caps`.
+    pub fn expand(&self, caps: &Captures) -> StrBuf {
+        let mut dst = StrBuf::new();
+        self.push_expand(caps, &mut dst);
+        dst
+    }
+
+    /// Expands the template like `expand`, except that the expansion is
+    /// pushed on to `dst`.
+    pub fn push_expand(&self, caps: &Captures, dst: &mut StrBuf) {
+        self.push_expand_with(caps, dst, |_, value| value.to_owned())
+    }
+
+    /// Expands the template like `expand`, except that every group replaced
+    /// is passed through `f` first. `f` is given the group as it's written
+    /// in the template (e.g., `1` or `name`) and the text it matched (or its
+    /// default, or the empty string if it has none), and returns the text to
+    /// replace it with. This is useful for escaping what was matched.
+    ///
+    /// # Example
+    ///
+    /// ```rust
+    /// # use regex::{Regex, Template};
+    /// let re = Regex::new(r"(\w+)=(\S*)").unwrap();
+    /// let tmpl = Template::parse("$1=[$2]").unwrap();
+    /// let caps = re.captures("q=a&b").unwrap();
+    /// let got = tmpl.expand_with(&caps, |group, value| {
+    ///     match group {
+    ///         "2" => value.replace("&", "%26"),
+    ///         _ => value.to_owned(),
+    ///     }
+    /// });
+    /// assert_eq!(got.as_slice(), "q=[a%26b]");
+    /// ```
+    pub fn expand_with(&self, caps: &Captures, f: |&str, &str| -> ~str)
+                      -> StrBuf {
+        let mut dst = StrBuf::new();
+        self.push_expand_with(caps, &mut dst, f);
+        dst
+    }
+
+    /// Expands the template like `expand_with`, except that the expansion is
+    /// pushed on to `dst`.
+    pub fn push_expand_with(&self, caps: &Captures, dst: &mut StrBuf,
+                            f: |&str, &str| -> ~str) {
+        for piece in self.pieces.iter() {
+            match *piece {
+                Text(ref text) => dst.push_str(text.as_slice()),
+                Group(ref group, ref default) => {
+                    let value = value(caps, group.as_slice(), default);
+                    dst.push_str(f(group.as_slice(), value).as_slice());
+                }
+            }
+        }
+    }
+
+    /// Expands the template like `expand`, except that its literal text is
+    /// cased like the text of the whole match (see `PreserveCase`).
+    pub fn expand_preserving_case(&self, caps: &Captures) -> StrBuf {
+        let mut dst = StrBuf::new();
+        self.push_expand_preserving_case(caps, &mut dst);
+        dst
+    }
+
+    /// Expands the template like `expand_preserving_case`, except that the
+    /// expansion is pushed on to `dst`.
+    pub fn push_expand_preserving_case(&self, caps: &Captures,
+                                       dst: &mut StrBuf) {
+        let case = case_of(caps.at(0));
+        // Whether no letter has been pushed yet, which title case changes
+        // the first of.
+        let mut first = true;
+        for piece in self.pieces.iter() {
+            match *piece {
+                Text(ref text) => {
+                    for c in text.chars() {
+                        let c = match case {
+                            AsWritten => c,
+                            Upper => c.to_uppercase(),
+                            Title if first => c.to_uppercase(),
+                            Title => c.to_lowercase(),
+                        };
+                        first = first && !is_cased(c);
+                        dst.push_char(c);
+                    }
+                }
+                Group(ref group, ref default) => {
+                    let value = value(caps, group.as_slice(), default);
+                    first = first && !value.chars().any(is_cased);
+                    dst.push_str(value);
+                }
+            }
+        }
+    }
+}
+
+impl<'t> Replacer for &'t Template {
+    fn reg_replace<'a>(&'a mut self, caps: &Captures) -> MaybeOwned<'a> {
+        Owned(self.expand(caps).into_owned())
+    }
+}
+
+/// PreserveCase is a replacement whose literal text is cased like each
+/// match it replaces, for replacing words whatever their case (e.g., with a
+/// regex that ignores case). The replacement is expanded like any other
+/// (see `Template`), and then:
+///
+/// * If every letter of the match is upper case (and there's more than one),
+///   the literal text of the replacement is made upper case.
+/// * If the first letter of the match is upper case and the others are lower
+///   case, the first letter of the replacement is made upper case and the
+///   others lower case, as long as they're in its literal text.
+/// * Otherwise (the match is lower case or of mixed case, or has no letters),
+///   the replacement is used as written.
+///
+/// Characters that aren't letters (like digits and punctuation) are
+/// skipped when the case of the match is decided, so `'Colour` is title
+/// case. The text of the groups in the replacement is never changed.
+///
+/// Letters are those that Unicode gives a case to, and each is changed by its
+/// simple Unicode case mapping. So a letter that becomes several letters in
+/// another case keeps its own (e.g., `ß` isn't made `SS`), the mapping doesn't
+/// depend on the language (e.g., the Turkish dotted and dotless `i`), and
+/// title case letters like `ǅ` are neither upper nor lower case here.
+///
+/// # Example
+///
+/// ```rust
+/// # use regex::{Regex, PreserveCase};
+/// let re = Regex::new(r"(?i)colour").unwrap();
+/// let got = re.replace_all("colour Colour COLOUR", PreserveCase("color"));
+/// assert_eq!(got.as_slice(), "color Color COLOR");
+/// ```
+pub struct PreserveCase<'t>(pub &'t str);
+
+impl<'t> Replacer for PreserveCase<'t> {
+    fn reg_replace<'a>(&'a mut self, caps: &Captures) -> MaybeOwned<'a> {
+        let PreserveCase(s) = *self;
+        let tmpl = parse_lenient(s);
+        Owned(tmpl.expand_preserving_case(caps).into_owned())
+    }
+}
+
+// The ways in which the literal text of a replacement is cased.
+enum Case {
+    AsWritten,
+    Upper,
+    Title,
+}
+
+// Returns the way a replacement is cased for a match of the text given.
+fn case_of(text: &str) -> Case {
+    let mut letters = text.chars().filter(|&c| is_cased(c));
+    match letters.next() {
+        Some(c) if c.is_uppercase() => {}
+        _ => return AsWritten,
+    }
+    let (mut upper, mut lower) = (0u, 0u);
+    for c in letters {
+        if c.is_uppercase() { upper += 1 } else { lower += 1 }
+    }
+    if upper == 0 {
+        Title
+    } else if lower == 0 {
+        Upper
+    } else {
+        AsWritten
+    }
+}
+
+fn is_cased(c: char) -> bool {
+    c.is_uppercase() || c.is_lowercase()
+}
+
+/// Parses a template like `Template::parse`, except that malformed
+/// references are kept as literal text.
+pub fn parse_lenient(text: &str) -> Template {
+    match parse(text, false) {
+        Ok(tmpl) => tmpl,
+        Err(_) => unreachable!(),
+    }
+}
+
+// Returns the text matched by `group` (an index or a name) in `caps`, if it
+// took part in the match.
+fn find<'t>(caps: &Captures<'t>, group: &str) -> Option<&'t str> {
+    let i = match from_str::<uint>(group) {
+        Some(i) => Some(i),
+        None => caps.name_index(group),
+    };
+    match i {
+        None => None,
+        Some(i) => caps.pos(i).map(|_| caps.at(i)),
+    }
+}
+
+// Returns what replaces a reference to `group` with the default given: the
+// text the group matched, if it took part in the match, or its default.
+fn value<'a>(caps: &'a Captures, group: &str, default: &'a Option<~str>)
+            -> &'a str {
+    match (find(caps, group), default) {
+        (Some(value), _) => value,
+        (None, &Some(ref default)) => default.as_slice(),
+        (None, &None) => "",
+    }
+}
+
+fn parse(text: &str, strict: bool) -> Result<Template, TemplateError> {
+    let chars: Vec<char> = text.chars().collect();
+    let mut pieces = vec!();
+    let mut lit = StrBuf::new();
+    let mut i = 0;
+    while i < chars.len() {
+        let c = *chars.get(i);
+        if c != '$' || i + 1 == chars.len() {
+            lit.push_char(c);
+            i += 1;
+            continue
+        }
+        let (group, next) = match *chars.get(i + 1) {
+            '$' => {
+                lit.push_char('$');
+                i += 2;
+                continue
+            }
+            '{' => match braced(chars.as_slice(), i) {
+                Ok((group, default, next)) => (Group(group, default), next),
+                Err(msg) => {
+                    if strict {
+                        return Err(TemplateError { pos: i, msg: msg })
+                    }
+                    lit.push_char(c);
+                    i += 1;
+                    continue
+                }
+            },
+            _ => {
+                let end = name_end(chars.as_slice(), i + 1);
+                if end == i + 1 {
+                    lit.push_char(c);
+                    i += 1;
+                    continue
+                }
+                let name = str::from_chars(chars.slice(i + 1, end));
+                (Group(name, None), end)
+            }
+        };
+        if lit.len() > 0 {
+            pieces.push(Text(lit.into_owned()));
+            lit = StrBuf::new();
+        }
+        pieces.push(group);
+        i = next;
+    }
+    if lit.len() > 0 {
+        pieces.push(Text(lit.into_owned()));
+    }
+    Ok(Template { pieces: pieces })
+}
+
+// Parses the reference within braces that starts with the `$` at `start`.
+// Returns the group, its default and the index following the closing brace.
+fn braced(chars: &[char], start: uint)
+         -> Result<(~str, Option<~str>, uint), ~str> {
+    let end = name_end(chars, start + 2);
+    if end == start + 2 {
+        return Err(~"Expected a group name after '${'.")
+    }
+    let name = str::from_chars(chars.slice(start + 2, end));
+    if end < chars.len() && chars[end] == '}' {
+        return Ok((name, None, end + 1))
+    }
+    if end + 1 < chars.len() && chars[end] == ':' && chars[end + 1] == '-' {
+        for j in range(end + 2, chars.len()) {
+            if chars[j] == '}' {
+                let default = str::from_chars(chars.slice(end + 2, j));
+                return Ok((name, Some(default), j + 1))
+            }
+        }
+        return Err(format!("The default of '{}' must end with '\\}'.", name))
+    }
+    Err(format!("Expected '\\}' or ':-' after group name '{}'.", name))
+}
+
+// Returns the index following the longest name starting at `start`.
+fn name_end(chars: &[char], start: uint) -> uint {
+    let mut end = start;
+    while end < chars.len()
+          && (chars[end] == '_' || chars[end].is_alphanumeric()) {
+        end += 1;
+    }
+    end
+}
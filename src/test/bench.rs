@@ -10,7 +10,9 @@
 
 use rand::{Rng, task_rng};
 use stdtest::Bencher;
+use std::io::BufReader;
 use std::str;
+use sync::Arc;
 use regex::{Regex, NoExpand};
 
 fn bench_assert_match(b: &mut Bencher, re: Regex, text: &str) {
@@ -177,3 +179,452 @@ throughput!(hard_32, hard(), 32)
 throughput!(hard_1K, hard(), 1<<10)
 throughput!(hard_32K,hard(), 32<<10)
 
+
+// The following compare the lazy DFA with the NFA simulation on the same
+// dynamic regex. A DFA size limit of `0` disables the DFA.
+macro_rules! engine_throughput(
+    ($name:ident, $regex:expr, $size:expr, $limit:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let text = gen_text($size);
+            let mut re = Regex::new($regex).unwrap();
+            re.set_dfa_size_limit($limit);
+            b.bytes = $size;
+            b.iter(|| if re.is_match(text) { fail!("match") });
+        }
+    );
+)
+
+macro_rules! engine_find_iter(
+    ($name:ident, $regex:expr, $size:expr, $limit:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let text = gen_text($size);
+            let mut re = Regex::new($regex).unwrap();
+            re.set_dfa_size_limit($limit);
+            b.bytes = $size;
+            b.iter(|| re.find_iter(text).count());
+        }
+    );
+)
+
+static DFA_LIMIT: uint = 2 * (1 << 20);
+
+engine_throughput!(nfa_medium_1M, "[XYZ]ABCDEFGHIJKLMNOPQRSTUVWXYZ$", 1<<20, 0)
+engine_throughput!(dfa_medium_1M, "[XYZ]ABCDEFGHIJKLMNOPQRSTUVWXYZ$", 1<<20,
+                   DFA_LIMIT)
+engine_throughput!(nfa_hard_1M, "[ -~]*ABCDEFGHIJKLMNOPQRSTUVWXYZ$", 1<<20, 0)
+engine_throughput!(dfa_hard_1M, "[ -~]*ABCDEFGHIJKLMNOPQRSTUVWXYZ$", 1<<20,
+                   DFA_LIMIT)
+engine_find_iter!(nfa_find_iter_1M, r"[aeiou][0-9]\b", 1<<20, 0)
+engine_find_iter!(dfa_find_iter_1M, r"[aeiou][0-9]\b", 1<<20, DFA_LIMIT)
+
+#[bench]
+fn literal_prefix_sparse_1M(b: &mut Bencher) {
+    let text = gen_text(1<<20);
+    let re = regex!("ZQXJ[0-9]");
+    b.bytes = 1<<20;
+    b.iter(|| re.find_iter(text).count());
+}
+
+fn gen_dna(n: uint) -> ~str {
+    let mut rng = task_rng();
+    let dna = ['a', 'c', 'g', 't'];
+    range(0, n).map(|_| dna[rng.gen_range(0u, 4)]).collect()
+}
+
+// The variants from the regex-dna benchmark, which are all alternations of
+// literals.
+macro_rules! dna_variant(
+    ($name:ident, $regex:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let text = gen_dna(1<<20);
+            let re = regex!($regex);
+            b.bytes = 1<<20;
+            b.iter(|| re.count(text));
+        }
+    );
+)
+
+dna_variant!(dna_variant1, "agggtaaa|tttaccct")
+dna_variant!(dna_variant2, "[cgt]gggtaaa|tttaccc[acg]")
+dna_variant!(dna_variant3, "a[act]ggtaaa|tttacc[agt]t")
+dna_variant!(dna_variant4, "ag[act]gtaaa|tttac[agt]ct")
+dna_variant!(dna_variant5, "agg[act]taaa|ttta[agt]cct")
+dna_variant!(dna_variant6, "aggg[acg]aaa|ttt[cgt]ccct")
+dna_variant!(dna_variant7, "agggt[cgt]aa|tt[acg]accct")
+dna_variant!(dna_variant8, "agggta[cgt]a|t[acg]taccct")
+dna_variant!(dna_variant9, "agggtaa[cgt]|[acg]ttaccct")
+
+// A handful of literals is searched with Teddy, while many literals are
+// searched with Aho-Corasick.
+#[bench]
+fn literals_few_5M(b: &mut Bencher) {
+    let text = gen_dna(5<<20);
+    let re = regex!("agggtaaa|tttaccct|cgggtaaa|tttacccg|tgggtaaa|tttaccca");
+    b.bytes = 5<<20;
+    b.iter(|| re.find_iter(text).count());
+}
+
+#[bench]
+fn literals_many_5M(b: &mut Bencher) {
+    let text = gen_dna(5<<20);
+    let lits: Vec<~str> = range(0, 40).map(|_| gen_dna(8)).collect();
+    let re = Regex::new(lits.as_slice().connect("|").as_slice()).unwrap();
+    b.bytes = 5<<20;
+    b.iter(|| re.find_iter(text).count());
+}
+
+// The lines of a log, in which a few mention errors (in various cases).
+fn gen_log(n: uint) -> ~str {
+    let mut rng = task_rng();
+    let mut log = StrBuf::with_capacity(n + 100);
+    while log.len() < n {
+        let level = match rng.gen_range(0u, 100) {
+            0 => "ERROR",
+            1 => "Error",
+            _ => "INFO",
+        };
+        log.push_str(format!("2014-05-{:02u} 12:{:02u}:00 {} request served \
+                              in {}ms\n", rng.gen_range(1u, 29),
+                             rng.gen_range(0u, 60), level,
+                             rng.gen_range(1u, 500)));
+    }
+    log.into_owned()
+}
+
+// An ASCII case insensitive literal is found by a prefilter, just like one
+// that's case sensitive.
+macro_rules! log_literal(
+    ($name:ident, $regex:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let text = gen_log(1<<20);
+            let re = Regex::new($regex).unwrap();
+            b.bytes = 1<<20;
+            b.iter(|| re.find_iter(text).count());
+        }
+    );
+)
+
+log_literal!(log_literal_1M, "ERROR")
+log_literal!(log_literal_casei_1M, "(?i)error")
+log_literal!(log_literal_casei_suffix_1M, r"(?i)error request \w+")
+log_literal!(log_literal_classes_1M, "[eE][rR][rR][oO][rR]")
+
+// A single long line with a match near its end. Finding the start of the
+// match with the reverse DFA avoids running the NFA over the line.
+fn long_line(n: uint) -> ~str {
+    let mut line = StrBuf::with_capacity(n + 10);
+    while line.len() < n {
+        line.push_str("xyzzy plugh ");
+    }
+    line.push_str("sesame123!");
+    line.into_owned()
+}
+
+#[bench]
+fn find_long_line_nfa(b: &mut Bencher) {
+    let text = long_line(1<<20);
+    let mut re = Regex::new(r"[a-z]+[0-9]+!").unwrap();
+    re.set_dfa_size_limit(0);
+    b.bytes = 1<<20;
+    b.iter(|| re.find(text));
+}
+
+#[bench]
+fn find_long_line_dfa(b: &mut Bencher) {
+    let text = long_line(1<<20);
+    let re = Regex::new(r"[a-z]+[0-9]+!").unwrap();
+    b.bytes = 1<<20;
+    b.iter(|| re.find(text));
+}
+
+// Capture groups of matches that are few and far between. With the DFA, the
+// groups are only searched for in the text of each match.
+macro_rules! sparse_captures(
+    ($name:ident, $limit:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let text = long_line(1<<20);
+            let mut re = Regex::new(r"([a-z]+)([0-9]+)!").unwrap();
+            re.set_dfa_size_limit($limit);
+            b.bytes = 1<<20;
+            b.iter(|| re.captures_iter(text).count());
+        }
+    );
+)
+
+sparse_captures!(captures_iter_sparse_nfa_1M, 0)
+sparse_captures!(captures_iter_sparse_dfa_1M, DFA_LIMIT)
+
+// Anchored expressions with captures are resolved by the one-pass engine.
+macro_rules! onepass_captures(
+    ($name:ident, $re:expr, $text:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let re = Regex::new($re).unwrap();
+            let text = $text;
+            b.bytes = text.len() as u64;
+            b.iter(|| re.captures(text));
+        }
+    );
+)
+
+onepass_captures!(onepass_captures_key_value, r"^(\w+):(\d+)$",
+                  "timeout:30000")
+onepass_captures!(onepass_captures_log_line,
+                  r"^(\d{4})-(\d{2})-(\d{2}) ([A-Z]+) (.*)$",
+                  "2014-05-12 ERROR connection refused by peer")
+onepass_captures!(onepass_captures_optional, r"^([a-z]+)(?:\.([a-z]+))?=(.*)$",
+                  "core.editor=vim")
+
+// Capture groups on a single short line, where the bounded backtracker is
+// used instead of the NFA.
+macro_rules! backtrack_captures(
+    ($name:ident, $limit:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let mut re = Regex::new(r"(\w+)@(\w+)\.(com|org|net)").unwrap();
+            re.set_backtrack_limit($limit);
+            let text = "From: Jane Doe <jane.doe@example.org> (work)";
+            b.bytes = text.len() as u64;
+            b.iter(|| re.captures(text));
+        }
+    );
+)
+
+backtrack_captures!(captures_short_line_nfa, 0)
+backtrack_captures!(captures_short_line_backtrack, 1 << 20)
+
+// A tiny expression qualifies for the bit-parallel engine, which is disabled
+// along with the DFA.
+fn gen_dates(n: uint) -> ~str {
+    let mut text = StrBuf::with_capacity(n + 20);
+    while text.len() < n {
+        text.push_str("2014-5-12 14/05/12 ");
+    }
+    text.push_str("2014-05-12");
+    text.into_owned()
+}
+
+macro_rules! date_match(
+    ($name:ident, $limit:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let mut re = Regex::new(r"[0-9]{4}-[0-9]{2}-[0-9]{2}").unwrap();
+            re.set_dfa_size_limit($limit);
+            let text = gen_dates(1<<15);
+            b.bytes = text.len() as u64;
+            b.iter(|| if !re.is_match(text) { fail!("no match") });
+        }
+    );
+)
+
+date_match!(match_date_nfa_32K, 0)
+date_match!(match_date_shiftor_32K, DFA_LIMIT)
+
+// Many matches with named capture groups. The cursor doesn't build a map of
+// names for every match.
+fn gen_pairs(n: uint) -> ~str {
+    let mut text = StrBuf::with_capacity(n + 10);
+    while text.len() < n {
+        text.push_str("key=val ");
+    }
+    text.into_owned()
+}
+
+#[bench]
+fn captures_iter_named_32K(b: &mut Bencher) {
+    let re = regex!(r"(?P<key>\w+)=(?P<val>\w+)");
+    let text = gen_pairs(1<<15);
+    b.bytes = text.len() as u64;
+    b.iter(|| re.captures_iter(text).count());
+}
+
+#[bench]
+fn captures_cursor_named_32K(b: &mut Bencher) {
+    let re = regex!(r"(?P<key>\w+)=(?P<val>\w+)");
+    let text = gen_pairs(1<<15);
+    b.bytes = text.len() as u64;
+    b.iter(|| {
+        let mut cur = re.captures_cursor(text);
+        let mut n = 0;
+        while cur.advance() {
+            n += 1;
+        }
+        n
+    });
+}
+
+// Searching a stream should be nearly as fast as searching the same text in
+// memory.
+macro_rules! reader_throughput(
+    ($name:ident, $regex:expr, $reader:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let re = $regex;
+            let text = gen_text(1<<20);
+            b.bytes = text.len() as u64;
+            b.iter(|| {
+                let found =
+                    if $reader {
+                        let mut src = BufReader::new(text.as_bytes());
+                        re.is_match_reader(&mut src, 0).unwrap()
+                    } else {
+                        re.is_match(text)
+                    };
+                if found { fail!("match") }
+            });
+        }
+    );
+)
+
+reader_throughput!(easy1_memory_1M, easy1(), false)
+reader_throughput!(easy1_reader_1M, easy1(), true)
+reader_throughput!(medium_memory_1M, medium(), false)
+reader_throughput!(medium_reader_1M, medium(), true)
+
+// Searches a dynamic regex (which uses the lazy DFA) that's shared by
+// `tasks` tasks. There are always 64 searches per iteration, split between
+// the tasks, so the time per iteration drops as tasks are added when
+// searches of a shared regex scale with the number of cores.
+fn bench_shared(b: &mut Bencher, tasks: uint) {
+    let re = Arc::new(Regex::new("[XYZ]ABCDEFGHIJKLMNOPQRSTUVWXYZ$").unwrap());
+    let text = Arc::new(gen_text(4 * 1024));
+    let searches = 64 / tasks;
+    b.iter(|| {
+        let (tx, rx) = channel();
+        for _ in range(0, tasks) {
+            let (re, text, tx) = (re.clone(), text.clone(), tx.clone());
+            spawn(proc() {
+                for _ in range(0, searches) {
+                    tx.send(re.is_match(text.as_slice()));
+                }
+            });
+        }
+        for _ in range(0, tasks * searches) {
+            rx.recv();
+        }
+    });
+    b.bytes = (64 * 4 * 1024) as u64;
+}
+
+#[bench] fn shared_1_task(b: &mut Bencher) { bench_shared(b, 1) }
+#[bench] fn shared_4_tasks(b: &mut Bencher) { bench_shared(b, 4) }
+#[bench] fn shared_16_tasks(b: &mut Bencher) { bench_shared(b, 16) }
+
+// Searches non-ASCII text, whose transitions the lazy DFA computes every
+// time (it only caches those of ASCII characters), with the lazy DFA or with
+// the tables of the DFA compiled ahead of time.
+fn bench_full_dfa(b: &mut Bencher, full: bool) {
+    let mut re = Regex::new(r"(\p{Greek}|\p{Cyrillic})+[0-9]").unwrap();
+    if full {
+        re.compile_dfa(1 << 20).unwrap();
+    }
+    let text = "αβγδ жзий ".repeat(1024);
+    b.iter(|| if re.is_match(text.as_slice()) { fail!("match") });
+    b.bytes = text.len() as u64;
+}
+
+#[bench] fn lazy_dfa_non_ascii(b: &mut Bencher) { bench_full_dfa(b, false) }
+#[bench] fn full_dfa_non_ascii(b: &mut Bencher) { bench_full_dfa(b, true) }
+
+// Mixed ASCII and Unicode text, with an email address every so often.
+fn gen_mixed(n: uint) -> ~str {
+    let words = ["hello ", "Grüße ", "δοκιμή ", "проверка ", "x.y+z@例え.jp ",
+                 "42 ", "naïve ", "joe@example.com\n"];
+    let mut rng = task_rng();
+    let mut text = StrBuf::new();
+    while text.len() < n {
+        text.push_str(words[rng.gen_range(0u, words.len())]);
+    }
+    text.into_owned()
+}
+
+// Class-heavy searches, where every step of the NFA (and every transition
+// on a character that isn't ASCII in the DFA) matches a character against
+// a class.
+macro_rules! class_find_iter(
+    ($name:ident, $regex:expr, $limit:expr) => (
+        #[bench]
+        fn $name(b: &mut Bencher) {
+            let text = gen_mixed(1<<16);
+            let mut re = Regex::new($regex).unwrap();
+            re.set_dfa_size_limit($limit);
+            b.bytes = text.len() as u64;
+            b.iter(|| re.find_iter(text).count());
+        }
+    );
+)
+
+class_find_iter!(nfa_class_letters, r"\p{L}+", 0)
+class_find_iter!(dfa_class_letters, r"\p{L}+", DFA_LIMIT)
+class_find_iter!(nfa_class_email, r"[\w.+-]+@[\w.-]+", 0)
+class_find_iter!(dfa_class_email, r"[\w.+-]+@[\w.-]+", DFA_LIMIT)
+
+// An alternation of 10,000 words (which share many prefixes), as in a
+// dictionary.
+fn dictionary() -> ~str {
+    let letters = "etaoinshrdlucmfw".as_bytes();
+    let words: Vec<~str> = range(0u, 10000).map(|mut n| {
+        let mut word = StrBuf::new();
+        while n > 0 || word.len() < 3 {
+            word.push_char(letters[n % letters.len()] as char);
+            n /= letters.len();
+        }
+        word.into_owned()
+    }).collect();
+    words.connect("|")
+}
+
+#[bench]
+fn dictionary_compile(b: &mut Bencher) {
+    let pattern = dictionary();
+    b.iter(|| Regex::new(pattern.as_slice()).unwrap());
+}
+
+#[bench]
+fn dictionary_find_iter(b: &mut Bencher) {
+    let re = Regex::new(dictionary().as_slice()).unwrap();
+    let text = gen_text(1<<16);
+    b.bytes = 1<<16;
+    b.iter(|| re.find_iter(text).count());
+}
+
+// Counts the matches in a big text with a number of tasks.
+fn bench_count_parallel(b: &mut Bencher, workers: uint) {
+    let re = Regex::new(r"[aeiou][0-9]\b").unwrap();
+    let text = gen_text(4<<20);
+    b.bytes = 4<<20;
+    b.iter(|| re.count_parallel(text, workers));
+}
+
+#[bench] fn parallel_1_worker(b: &mut Bencher) { bench_count_parallel(b, 1) }
+#[bench] fn parallel_4_workers(b: &mut Bencher) { bench_count_parallel(b, 4) }
+
+// Ten thousand short lines, like the names of files or the lines of a log.
+fn gen_lines() -> Vec<~str> {
+    let mut rng = task_rng();
+    Vec::from_fn(10000, |_| {
+        let n = rng.gen_range(8u, 40);
+        rng.gen_ascii_str(n)
+    })
+}
+
+#[bench]
+fn filter_loop(b: &mut Bencher) {
+    let re = Regex::new(r"[a-z]{3}[0-9]+[A-Z]").unwrap();
+    let lines = gen_lines();
+    let lines: Vec<&str> = lines.iter().map(|l| l.as_slice()).collect();
+    b.iter(|| lines.iter().filter(|l| re.is_match(**l)).count());
+}
+
+#[bench]
+fn filter_batched(b: &mut Bencher) {
+    let re = Regex::new(r"[a-z]{3}[0-9]+[A-Z]").unwrap();
+    let lines = gen_lines();
+    let lines: Vec<&str> = lines.iter().map(|l| l.as_slice()).collect();
+    b.iter(|| re.filter(lines.as_slice()).len());
+}
@@ -10,7 +10,27 @@
 
 // ignore-tidy-linelength
 
-use regex::{Regex, NoExpand};
+use regex::{Regex, NoExpand, RegexSet, SetMatch, Captures, MultiReplacer};
+use regex::PreserveCase;
+use regex::{StartAnchorAtBeginning, StartAnchorAtOffset, StepLimitExceeded};
+use regex::{Cancel, Cancelled};
+use regex::{CompileLimits, SyntaxError, ProgramTooLarge, NestingTooDeep};
+use regex::BackrefsForbidden;
+use regex::{Options, Template, ByteRegex, canonicalize};
+use regex::{DfaTooLarge, DfaUnsupported};
+use regex::Prefilter;
+use regex::{translate_pcre, PcreRecursion, PcreConditional, PcreControl};
+use regex::{PcreUnsupported, PcreSyntax};
+use regex::{GrepOptions, LongLinesSkip, LongLinesTruncate};
+use regex::{ScanError, ScanNoGroup, ScanMissingGroup, ScanInvalidValue};
+use regex::ScanUnsupported;
+use regex::{EngineDfa, EngineNfa};
+use regex::MemoryEstimate;
+use regex::{Matched, NoMatchYet, CannotMatch};
+use regex::{MatcherUnsupported, MatcherInvalidUtf8};
+use serialize::{Decoder, Decodable};
+use std::num;
+use sync::{Arc, Mutex};
 
 #[test]
 fn splitn() {
@@ -28,6 +48,446 @@ fn split() {
     assert_eq!(subs, vec!("cauchy", "plato", "tyler", "binx"));
 }
 
+#[test]
+fn split_keep() {
+    let re = regex!(r"(\d)");
+    let subs: Vec<&str> = re.split_keep("a1b2c").collect();
+    assert_eq!(subs, vec!("a", "1", "b", "2", "c"));
+    let subs: Vec<&str> = re.split_keep("1a2").collect();
+    assert_eq!(subs, vec!("", "1", "a", "2"));
+}
+
+#[test]
+fn split_keep_groups() {
+    let re = regex!(r"(,)|(;)");
+    let subs: Vec<&str> = re.split_keep("a,b;c").collect();
+    assert_eq!(subs, vec!("a", ",", "", "b", "", ";", "c"));
+    let re = regex!(r",");
+    let subs: Vec<&str> = re.split_keep("a,b").collect();
+    assert_eq!(subs, vec!("a", "b"));
+}
+
+#[test]
+fn split_keep_empty_matches() {
+    let re = regex!(r"()");
+    let subs: Vec<&str> = re.split_keep("aδ").collect();
+    assert_eq!(subs, vec!("", "", "a", "", "δ", ""));
+}
+
+#[test]
+fn splitn_keep() {
+    let re = regex!(r"(\d)");
+    let subs: Vec<&str> = re.splitn_keep("a1b2c", 2).collect();
+    assert_eq!(subs, vec!("a", "1", "b2c"));
+    assert_eq!(re.splitn_keep("a1b2c", 0).count(), 0);
+}
+
+#[test]
+fn set_matches() {
+    let set = RegexSet::new(&[r"\d+", "(?i)abc", "abc", "^xyz", "z$"]).unwrap();
+    assert_eq!(set.matches("ABC 123 z"), vec!(0, 1, 4));
+    assert_eq!(set.matches("xyz abc"), vec!(1, 2, 3));
+    assert_eq!(set.matches("nothing"), vec!());
+}
+
+#[test]
+fn set_find_all() {
+    let set = RegexSet::new(&["a+", "b+|a", "(?i)C"]).unwrap();
+    assert_eq!(set.find_all("xbaac"), vec!(
+        SetMatch { pattern: 0, start: 2, end: 4 },
+        SetMatch { pattern: 1, start: 1, end: 2 },
+        SetMatch { pattern: 2, start: 4, end: 5 },
+    ));
+}
+
+#[test]
+fn set_agrees_with_find() {
+    let res: &[&str] = &["agggtaaa|tttaccct", "[cgt]gggtaaa|tttaccc[acg]",
+                         r"\bt\w+", "a*", "$"];
+    let text = "gggtaaa tttacccg agggtaaa";
+    let set = RegexSet::new(res).unwrap();
+    for m in set.find_all(text).iter() {
+        let re = Regex::new(res[m.pattern]).unwrap();
+        assert_eq!(re.find(text), Some((m.start, m.end)));
+    }
+    assert_eq!(set.find_all(text).len(), res.len());
+}
+
+#[test]
+fn set_empty_and_single() {
+    let set = RegexSet::new(&[]).unwrap();
+    assert_eq!(set.len(), 0);
+    assert_eq!(set.matches("abc"), vec!());
+    assert!(!set.is_match(""));
+
+    let set = RegexSet::new(&["b"]).unwrap();
+    assert_eq!(set.matches("abc"), vec!(0));
+    assert_eq!(set.find_all("abc"),
+               vec!(SetMatch { pattern: 0, start: 1, end: 2 }));
+}
+
+#[test]
+fn set_names() {
+    let set = RegexSet::new(&["(?P<a>x)", "(y)(?P<b>z)"]).unwrap();
+    assert_eq!(set.names(0)[1], Some(~"a"));
+    assert_eq!(set.names(1)[1], None);
+    assert_eq!(set.names(1)[2], Some(~"b"));
+    assert_eq!(set.pattern(1), "(y)(?P<b>z)");
+}
+
+#[test]
+fn set_parse_error() {
+    assert!(RegexSet::new(&["a", "(b"]).is_err());
+}
+
+#[test]
+fn encode_set() {
+    let set = RegexSet::new(&["[0-9]+", "(?i)foo", "(b)(?P<x>c)"]).unwrap();
+    let decoded = RegexSet::from_bytes(set.to_bytes().as_slice()).unwrap();
+    let text = "FOO 123 bc";
+    assert_eq!(decoded.matches(text), set.matches(text));
+    assert_eq!(decoded.find_all(text), set.find_all(text));
+    assert_eq!(decoded.names(2), set.names(2));
+    assert_eq!(decoded.pattern(1), "(?i)foo");
+
+    let set = RegexSet::new(&[]).unwrap();
+    let decoded = RegexSet::from_bytes(set.to_bytes().as_slice()).unwrap();
+    assert_eq!(decoded.len(), 0);
+    assert!(!decoded.is_match("a"));
+}
+
+#[test]
+fn encode_backtrack_only() {
+    let res: &[&str] = &[r"(?<=a)b", r"(a)\1", r"a\Kb", r"(?>a+)ab|a+b",
+                         r"(?<!x)(?i:B)(?=c)"];
+    let text = "xbaab abc aab";
+    for re in res.iter() {
+        let re = Regex::new(*re).unwrap();
+        let decoded = Regex::from_bytes(re.to_bytes().as_slice()).unwrap();
+        let found: Vec<(uint, uint)> = decoded.find_iter(text).collect();
+        assert_eq!(found, re.find_iter(text).collect());
+        assert!(decoded.has_lookaround() == re.has_lookaround());
+        assert!(decoded.has_backrefs() == re.has_backrefs());
+    }
+}
+
+#[test]
+fn encode_options() {
+    let opts = Options { longest: true, step_limit: Some(100),
+                         ..Options::new() };
+    let re = Regex::with_options("a|ab", opts).unwrap();
+    let decoded = Regex::from_bytes(re.to_bytes().as_slice()).unwrap();
+    assert_eq!(decoded.find("abc"), Some((0, 2)));
+    assert_eq!(decoded.find_branch("abc"), Some(((0, 2), Some(1))));
+    assert_eq!(decoded.step_limit(), Some(100));
+    assert_eq!(decoded.dfa_size_limit(), re.dfa_size_limit());
+    assert_eq!(format!("{}", decoded), format!("{}", re));
+    // The derived regexes are compiled from the expression.
+    assert!(decoded.is_full_match("ab"));
+}
+
+// Replaces the hash that ends an encoding, so that a change to it is
+// checked by what follows the hash.
+fn rehash(bytes: &mut Vec<u8>) {
+    let end = bytes.len() - 8;
+    let mut hash = 0xcbf29ce484222325u64;
+    for &b in bytes.slice_to(end).iter() {
+        hash ^= b as u64;
+        hash *= 0x100000001b3;
+    }
+    for i in range(0u, 8) {
+        *bytes.get_mut(end + i) = (hash >> (8 * i)) as u8;
+    }
+}
+
+#[test]
+fn decode_errors() {
+    use regex::{UnsupportedVersion, InvalidEncoding};
+
+    let bytes = Regex::new("a*").unwrap().to_bytes();
+    assert!(Regex::from_bytes(bytes.as_slice()).is_ok());
+    assert_eq!(Regex::from_bytes(&[]).unwrap_err().kind, InvalidEncoding);
+    let truncated = bytes.slice_to(bytes.len() - 1);
+    assert_eq!(Regex::from_bytes(truncated).unwrap_err().kind,
+               InvalidEncoding);
+    assert!(RegexSet::from_bytes(bytes.as_slice()).is_err());
+
+    let mut newer = bytes.clone();
+    *newer.get_mut(4) += 1;
+    let err = Regex::from_bytes(newer.as_slice()).unwrap_err();
+    assert_eq!(err.kind, UnsupportedVersion);
+
+    // Every corrupt byte is caught by the hash.
+    for i in range(4, bytes.len()) {
+        let mut corrupt = bytes.clone();
+        *corrupt.get_mut(i) ^= 0x40;
+        assert!(Regex::from_bytes(corrupt.as_slice()).is_err());
+    }
+
+    // A program that jumps outside of itself is rejected even when the hash
+    // matches. `a*` starts with `Save(0)` followed by `Split(2, 4)`.
+    let split = [14u8, 2, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0];
+    let at = range(0, bytes.len() - split.len()).find(|&i| {
+        bytes.slice(i, i + split.len()) == split.as_slice()
+    }).unwrap();
+    let mut outside = bytes.clone();
+    *outside.get_mut(at + 9) = 200;
+    rehash(&mut outside);
+    let err = Regex::from_bytes(outside.as_slice()).unwrap_err();
+    assert_eq!(err.kind, InvalidEncoding);
+
+    // So is one that loops without consuming anything: the `Jump(1)` after
+    // the `a` is made to jump to itself.
+    let jump = [13u8, 1, 0, 0, 0, 0, 0, 0, 0];
+    let at = range(0, bytes.len() - jump.len()).find(|&i| {
+        bytes.slice(i, i + jump.len()) == jump.as_slice()
+    }).unwrap();
+    let mut looping = bytes.clone();
+    *looping.get_mut(at + 1) = 3;
+    rehash(&mut looping);
+    let err = Regex::from_bytes(looping.as_slice()).unwrap_err();
+    assert_eq!(err.kind, InvalidEncoding);
+    assert!(err.msg.as_slice().contains("loops"));
+}
+
+#[test]
+fn syntax_tree() {
+    use regex::syntax::{Literal, Cat, Capture, Rep, OneMore, Greedy};
+    use regex::syntax::{FLAG_EMPTY, FLAG_NOCASE};
+
+    let re = regex!("(?i)a(b)+");
+    let b = ~Capture(1, None, ~Literal('b', FLAG_NOCASE));
+    assert_eq!(re.syntax(), ~Cat(vec!(~Literal('a', FLAG_NOCASE),
+                                      ~Rep(b, OneMore, Greedy))));
+
+    let opts = Options { case_insensitive: true, posix: true,
+                         ..Options::new() };
+    let re = Regex::with_options("a", opts).unwrap();
+    assert_eq!(re.syntax(), ~Literal('a', FLAG_NOCASE));
+    assert!(re.is_longest() && re.is_posix());
+    assert!(!Regex::new("a").unwrap().is_posix());
+
+    let re = ByteRegex::new("é").unwrap();
+    assert_eq!(re.syntax(), ~Cat(vec!(~Literal('\xC3', FLAG_EMPTY),
+                                      ~Literal('\xA9', FLAG_EMPTY))));
+}
+
+#[test]
+fn from_syntax() {
+    use regex::syntax::{Nothing, Literal, Class, Begin, WordBoundary, Backref};
+    use regex::syntax::{Capture, Cat, Alt, Rep, ZeroMore, Greedy};
+    use regex::syntax::{FLAG_EMPTY, FLAG_NOCASE, FLAG_ASCII};
+
+    let res: &[&str] = &[r"(?i)a(b)+", r"(?m)^\w+$", r"a|(?s:.)c|[^x-z]",
+                         r"(?-u:\b)(?i)k\b", r"(a)\1(?i:\1)0",
+                         r"(?P<n>x)(?<=x)y+?\k<n>", r"\.\*\[[\]\-&~^]",
+                         r"(?U)a+b", r"(?>a|ab)c", r"\G\w"];
+    let text = "aBbb\nfoo\nKk xXxyyx aAa0 *.*[]-&~^ ab abc";
+    let caps = |re: &Regex| -> Vec<Vec<Option<(uint, uint)>>> {
+        re.captures_iter(text).map(|c| c.iter_pos().collect()).collect()
+    };
+    for &expr in res.iter() {
+        let re = Regex::new(expr).unwrap();
+        let copy = Regex::from_syntax(&*re.syntax(), false).unwrap();
+        assert_eq!(caps(&re), caps(&copy));
+        let again = Regex::from_syntax(&*copy.syntax(), false).unwrap();
+        assert_eq!(format!("{}", again), format!("{}", copy));
+    }
+
+    // Flags are set where they're needed, and groups are renumbered.
+    let ast = Cat(vec!(~WordBoundary(FLAG_ASCII), ~Literal('k', FLAG_NOCASE)));
+    let re = Regex::from_syntax(&ast, false).unwrap();
+    assert_eq!(format!("{}", re), ~r"(?-u)\b(?iu)k");
+    assert!(re.is_match("\u212A"));
+    let ast = Cat(vec!(~Capture(5, None, ~Literal('a', FLAG_NOCASE)),
+                       ~Backref(5, FLAG_EMPTY), ~Literal('0', FLAG_EMPTY)));
+    let re = Regex::from_syntax(&ast, false).unwrap();
+    assert_eq!(format!("{}", re), ~r"((?i)a)\1\x{30}");
+    assert_eq!(re.find("aAA0"), Some((1, 4)));
+
+    // Trees that no expression parses to are still rendered.
+    let re = Regex::from_syntax(&Alt(~Nothing, ~Literal('b', FLAG_EMPTY)),
+                                false).unwrap();
+    assert_eq!(re.find("b"), Some((0, 0)));
+    let re = Regex::from_syntax(&Class(vec!(), FLAG_EMPTY), false).unwrap();
+    assert!(!re.is_match("abc"));
+
+    let re = Regex::new("a|ab").unwrap();
+    let longest = Regex::from_syntax(&*re.syntax(), true).unwrap();
+    assert!(longest.is_longest());
+    assert_eq!(longest.find("ab"), Some((0, 2)));
+
+    let bad = ~Rep(~Begin(FLAG_EMPTY), ZeroMore, Greedy);
+    assert!(Regex::from_syntax(&*bad, false).is_err());
+    assert!(Regex::from_syntax(&Backref(1, FLAG_EMPTY), false).is_err());
+    let bad = Capture(1, Some(~"a-b"), ~Literal('a', FLAG_EMPTY));
+    assert!(Regex::from_syntax(&bad, false).is_err());
+}
+
+#[test]
+fn canonical() {
+    let res: &[&str] = &[r"(?i)a(b)+", r"(?m)^\w+$", "a{2,4}b{3,}c{1}",
+                         "(?:a|b)|c", "(?i:x)y(?s).", r"(?x) [a-c\-z] # z",
+                         r"(?P<n>x)(?<=x)\k<n>\d", "(?U)a+?b*", r"\x61\(",
+                         "δ(?i)δ"];
+    let text = "aBbb\nfoo\naabbbbc bc xY\nXy\n -z-c (a(a δΔ";
+    let caps = |re: &Regex| -> Vec<Vec<Option<(uint, uint)>>> {
+        re.captures_iter(text).map(|c| c.iter_pos().collect()).collect()
+    };
+    for &expr in res.iter() {
+        let re = Regex::new(expr).unwrap();
+        let canonical = re.canonical();
+        assert_eq!(canonicalize(expr).unwrap(), canonical);
+        let copy = Regex::new(canonical.as_slice()).unwrap();
+        assert_eq!((expr, caps(&re)), (expr, caps(&copy)));
+        assert_eq!((expr, copy.canonical()), (expr, canonical.clone()));
+    }
+    assert_eq!(canonicalize("(?s)x(?:(?:y))").unwrap(), ~"xy");
+    assert_eq!(regex!("(?m:a)[ba]").canonical(), ~"a[a-b]");
+    assert!(canonicalize("a(").is_err());
+}
+
+#[test]
+fn debug_introspection() {
+    use std::io::MemWriter;
+    use std::str;
+
+    let summary = Regex::new(r"^(\w+)@(\w+)$").unwrap().explain();
+    assert!(summary.contains("captures: onepass"));
+    assert!(summary.contains("anchored: yes"));
+    let summary = Regex::new("abc|xyz").unwrap().explain();
+    assert!(summary.contains("engine: literals"));
+    assert!(summary.contains("anchored: no"));
+    let summary = Regex::new("(?=a)a").unwrap().explain();
+    assert!(summary.contains("engine: backtrack"));
+
+    let dump = Regex::new("a|(?i)b").unwrap().dump_program();
+    let listing: Vec<&str> = dump.as_slice().lines().skip_while(|l| {
+        !l.starts_with("0000")
+    }).collect();
+    assert_eq!(listing, vec!("0000 save 0", "0001 split 2, 4", "0002 char 'a'",
+                             "0003 jump 5", "0004 char 'b' (i)",
+                             "0005 save 1", "0006 match"));
+
+    let trace = |re: &Regex, text: &str| -> (Option<(uint, uint)>, ~str) {
+        let mut w = MemWriter::new();
+        let found = re.trace(text, &mut w).unwrap();
+        (found, str::from_utf8(w.get_ref()).unwrap().to_owned())
+    };
+    let (found, transcript) = trace(&Regex::new("ab").unwrap(), "xab");
+    assert_eq!(found, Some((1, 3)));
+    assert!(transcript.contains("prefilter skips to 1\n"));
+    assert!(transcript.contains("at 1 ('a'): 2 threads\n"));
+    assert!(transcript.contains("  0002 char 'b': matched\n"));
+    assert!(transcript.contains("  0004 match\n"));
+    let (found, transcript) = trace(&Regex::new(r"(a)\1").unwrap(), "baa");
+    assert_eq!(found, Some((1, 3)));
+    assert!(transcript.contains("backtracker"));
+}
+
+#[test]
+fn metadata() {
+    for &(re, min, max) in [
+        ("abc", 3u, Some(3u)), (r"^\bfoo(?=bar)$", 3, Some(3)),
+        ("(a|bc){2,3}", 2, Some(6)), ("((ab)+c)*d", 1, None),
+        ("(?i)ab", 2, Some(8)), ("é[x]", 3, Some(3)), (r"a\Kbc$", 0, Some(3)),
+        ("a{0}", 0, Some(0)), (r"(a)\1", 1, None), ("[β-ω]|.", 1, Some(4)),
+    ].iter() {
+        let re = Regex::new(re).unwrap();
+        assert_eq!((re.to_str(), re.min_match_len(), re.max_match_len()),
+                   (re.to_str(), min, max));
+    }
+
+    for &(re, lit) in [("abc", Some("abc")), ("é(x)[y]", Some("éxy")),
+                       ("a{0}", Some("")), ("a$", None), ("(?i)a", None),
+                       ("a|b", None), ("a+", None)].iter() {
+        let re = Regex::new(re).unwrap();
+        assert_eq!((re.to_str(), re.literal()),
+                   (re.to_str(), lit.map(|s| s.to_owned())));
+    }
+
+    for &(re, start, end) in [
+        ("^a$", true, true), (r"\A(?=x)\bx\z", true, true),
+        ("(?m)^a$", false, false), (r"\Ga", false, false),
+        ("^a|^b", true, false), ("^a|b$", false, false),
+        ("(^a)+b$", true, true), ("a^", false, false),
+        (r"a\Kb$", false, true),
+    ].iter() {
+        let re = Regex::new(re).unwrap();
+        assert_eq!((re.to_str(), re.is_anchored_start(), re.is_anchored_end()),
+                   (re.to_str(), start, end));
+    }
+
+    let cases: &[(&str, &[&str])] = &[
+        ("abc", &["abc"]), ("a(b(c))d", &["abcd"]),
+        (r"\bfoo(?=bar)\b", &["foo"]), ("x(?:ab|cab)+y", &["x", "y"]),
+        ("(foo|bar)baz", &["baz"]), ("(x|y)z(x|y)z", &["z"]),
+        ("[a]b+c", &["a", "b", "c"]), ("(?i)ab", &[]), (r"ab\Kc", &[]),
+        ("a*b?", &[]), (r"(foo)\1", &["foo"]), ("a(b|b)", &["a", "b"]),
+    ];
+    for &(re, lits) in cases.iter() {
+        let re = Regex::new(re).unwrap();
+        let expected: Vec<~str> = lits.iter().map(|s| s.to_owned()).collect();
+        assert_eq!((re.to_str(), re.required_literals()),
+                   (re.to_str(), expected));
+    }
+
+    // The metadata is computed the same way for native regexes.
+    let re = regex!(r"(\w+)@(mail|post)\.example\.com$");
+    assert_eq!(re.required_literals(), vec!(~"@", ~".example.com"));
+    assert!(re.is_anchored_end() && !re.is_anchored_start());
+}
+
+#[test]
+fn dfa_agrees_with_nfa() {
+    let res: &[&str] = &["a+", r"\bfoo\b", "(?m)^b$", "a|ab", "ab|a",
+                         "x*", r"\B", "(?i)δ+", "[^a]$", "z"];
+    let text = "aab foo\nb\nab a\u0394\u03b4x xx";
+    for re in res.iter() {
+        let dfa = Regex::new(*re).unwrap();
+        let mut nfa = Regex::new(*re).unwrap();
+        nfa.set_dfa_size_limit(0);
+        assert_eq!(dfa.is_match(text), nfa.is_match(text));
+        assert_eq!(dfa.find_iter(text).collect::<Vec<(uint, uint)>>(),
+                   nfa.find_iter(text).collect::<Vec<(uint, uint)>>());
+    }
+}
+
+#[test]
+fn end_before_final_newline() {
+    let re = regex!(r"\w\Z");
+    assert_eq!(re.find("ab\n"), Some((1, 2)));
+    assert_eq!(re.find("ab"), Some((1, 2)));
+    assert_eq!(re.find("ab\n\n"), None);
+    assert_eq!(re.find("a\nb\n"), Some((2, 3)));
+    let caps = regex!(r"^(\w+)\Z").captures("ab\n").unwrap();
+    assert_eq!(caps.pos(1), Some((0, 2)));
+    assert_eq!(regex!(r"\Z").find_iter("a\n").collect::<Vec<(uint, uint)>>(),
+               vec!((1, 1), (2, 2)));
+
+    let texts = ["ab\n", "ab\n\n", "a\nb", "\n", ""];
+    for &pattern in [r"\w+\Z", r"\n?\Z", r"(?m)b$|\Z"].iter() {
+        let dfa = Regex::new(pattern).unwrap();
+        let mut nfa = Regex::new(pattern).unwrap();
+        nfa.set_dfa_size_limit(0);
+        for &text in texts.iter() {
+            assert_eq!(dfa.find_iter(text).collect::<Vec<(uint, uint)>>(),
+                       nfa.find_iter(text).collect::<Vec<(uint, uint)>>());
+        }
+    }
+}
+
+#[test]
+fn dfa_size_limit_fallback() {
+    // A DFA this small gives up immediately, so the NFA is always used.
+    let mut re = Regex::new(r"[a-z]+[0-9]").unwrap();
+    re.set_dfa_size_limit(1);
+    assert_eq!(re.dfa_size_limit(), 1);
+    assert_eq!(re.find("ABC abc1"), Some((4, 8)));
+    assert!(re.is_match("x9"));
+    assert!(!re.is_match("xyz"));
+}
+
 macro_rules! replace(
     ($name:ident, $which:ident, $re:expr,
      $search:expr, $replace:expr, $result:expr) => (
@@ -52,6 +512,10 @@ replace!(rep_named, replace_all,
          "w1 w2 w3 w4", "$last $first$space", "w2 w1 w4 w3")
 replace!(rep_trim, replace_all, "^[ \t]+|[ \t]+$", " \t  trim me\t   \t",
          "", "trim me")
+replace!(rep_braces, replace, r"(\S+)\s+(\S+)", "w1 w2", "${2}x${1}", "w2xw1")
+replace!(rep_default, replace_all, r"(\w)(\d)?", "a1b",
+         "${2:-?}", "1?")
+replace!(rep_malformed_kept, replace, r"(\w)", "a", "${1 $1", "${1 a")
 
 macro_rules! noparse(
     ($name:ident, $re:expr) => (
@@ -75,6 +539,44 @@ noparse!(fail_class_not_closed, "[A")
 noparse!(fail_class_no_begin, r"[\A]")
 noparse!(fail_class_no_end, r"[\z]")
 noparse!(fail_class_no_boundary, r"[\b]")
+noparse!(fail_class_no_segment, r"[\b{g}]")
+noparse!(fail_segment_unknown, r"\b{x}")
+noparse!(fail_segment_no_close, r"\b{wb")
+noparse!(fail_segment_repeat, r"\b{g}*")
+noparse!(fail_lookahead_repeat, "(?=a)*")
+noparse!(fail_lookahead_neg_repeat, "(?!a)+")
+noparse!(fail_lookahead_unclosed, "(?=a")
+noparse!(fail_lookbehind_plus, "(?<=a+)b")
+noparse!(fail_lookbehind_star, "(?<!a*)")
+noparse!(fail_lookbehind_unbounded, "(?<=a{2,})b")
+noparse!(fail_lookbehind_repeat, "(?<=a)*")
+noparse!(fail_lookbehind_unclosed, "(?<=a")
+noparse!(fail_backref_undefined, r"(a)\2")
+noparse!(fail_backref_forward, r"\1(a)")
+noparse!(fail_backref_open, r"(a\1)")
+noparse!(fail_backref_class, r"(a)[\1]")
+noparse!(fail_backref_name_undefined, r"\k<a>(?P<a>x)")
+noparse!(fail_backref_name_unclosed, r"(?P<a>x)\k<a")
+noparse!(fail_backref_lookbehind, r"(a)(?<=\1)")
+noparse!(fail_atomic_unclosed, "(?>a")
+noparse!(fail_atomic_empty, "(?>)")
+noparse!(fail_possessive_double, "a*++")
+noparse!(fail_keep_class, r"[a\K]")
+noparse!(fail_keep_lookahead, r"(?=a\K)")
+noparse!(fail_keep_lookbehind, r"(?<=a\K)b")
+noparse!(fail_keep_repeat, r"a\K*")
+noparse!(fail_class_no_grapheme, r"[a\X]")
+noparse!(fail_unicode_unknown, r"\p{Lettr}")
+noparse!(fail_unicode_bad_key, r"\p{foo=Greek}")
+noparse!(fail_unicode_bad_script, r"\p{sc=Lu}")
+noparse!(fail_unicode_bad_value, r"\p{Alpha=maybe}")
+noparse!(fail_class_no_line_break, r"[\R]")
+noparse!(fail_class_nested_open, r"[a[b]")
+noparse!(fail_class_op_no_left, r"[&&a]")
+noparse!(fail_class_op_no_right, r"[a~~]")
+noparse!(fail_class_op_literal, r"[a&&b]")
+noparse!(fail_class_op_range, r"[+--]")
+noparse!(fail_class_op_empty, r"[a--[a-z]]")
 noparse!(fail_open_paren, "(")
 noparse!(fail_close_paren, ")")
 noparse!(fail_invalid_range, "[a-Z]")
@@ -93,12 +595,236 @@ noparse!(fail_octal_digit, r"\8")
 noparse!(fail_hex_digit, r"\xG0")
 noparse!(fail_hex_short, r"\xF")
 noparse!(fail_hex_long_digits, r"\x{fffg}")
+noparse!(fail_char_name_unknown, r"\N{NOT A CHARACTER}")
+noparse!(fail_char_name_no_braces, r"\Na")
+noparse!(fail_char_name_unclosed, r"\N{SNOWMAN")
+noparse!(fail_char_name_surrogate, r"\N{U+D800}")
+noparse!(fail_char_name_long_code, r"\N{U+0000041}")
+noparse!(fail_char_name_cjk_padded, r"\N{CJK UNIFIED IDEOGRAPH-04E00}")
+noparse!(fail_char_name_cjk_range, r"\N{CJK UNIFIED IDEOGRAPH-41}")
 noparse!(fail_flag_bad, "(?a)")
 noparse!(fail_flag_empty, "(?)")
 noparse!(fail_double_neg, "(?-i-i)")
 noparse!(fail_neg_empty, "(?i-)")
 noparse!(fail_empty_group, "()")
 noparse!(fail_dupe_named, "(?P<a>.)(?P<a>.)")
+noparse!(fail_dupe_named_nested, "(?P<a>(?P<a>.)|b)")
+noparse!(fail_dupe_named_same_branch, "x|(?P<a>.)(?P<a>.)")
+noparse!(fail_dupe_named_after_alt, "(?:(?P<a>x)|y)(?P<a>z)")
+
+#[test]
+fn unicode_class_suggestions() {
+    let err = Regex::new(r"\p{Lettr}").unwrap_err();
+    assert!(err.msg.as_slice().contains("Did you mean 'Letter'?"));
+    let err = Regex::new(r"\p{Alpha=maybe}").unwrap_err();
+    assert!(err.msg.as_slice().contains("Did you mean 'Yes', 'No'?"));
+    let err = Regex::new(r"\p{Xyzzy}").unwrap_err();
+    assert!(!err.msg.as_slice().contains("Did you mean"));
+}
+
+#[test]
+fn compile_limits_default() {
+    // The defaults accept everything that compiles today.
+    let re = Regex::with_limits(r"((((a)))){1000}", CompileLimits::new());
+    assert!(re.is_ok());
+    let err = Regex::with_limits(r"a**", CompileLimits::new()).unwrap_err();
+    assert_eq!(err.kind, SyntaxError);
+}
+
+#[test]
+fn compile_limits_program_size() {
+    let limits = CompileLimits { max_insts: 20, ..CompileLimits::new() };
+    // 17 classes plus the 3 instructions around every program.
+    assert!(Regex::with_limits(r"\w{17}", limits).is_ok());
+    let err = Regex::with_limits(r"\w{18}", limits).unwrap_err();
+    assert_eq!((err.kind, err.pos), (ProgramTooLarge, 5));
+    let err = Regex::with_limits(r"(a|b){1000}{1000}", limits).unwrap_err();
+    assert_eq!((err.kind, err.pos), (ProgramTooLarge, 10));
+    // Without counted repetition, the whole program is measured.
+    let err = Regex::with_limits("a".repeat(18).as_slice(), limits);
+    assert_eq!(err.unwrap_err().kind, ProgramTooLarge);
+}
+
+#[test]
+fn compile_limits_nesting() {
+    let limits = CompileLimits { max_depth: 2, ..CompileLimits::new() };
+    assert!(Regex::with_limits(r"((a))(b)(?:c)", limits).is_ok());
+    let err = Regex::with_limits(r"((a)(?:(b)))", limits).unwrap_err();
+    assert_eq!((err.kind, err.pos), (NestingTooDeep, 7));
+    let err = Regex::with_limits(r"(?P<x>(?:(c)))", limits).unwrap_err();
+    assert_eq!(err.kind, NestingTooDeep);
+}
+
+#[test]
+fn compile_limits_backrefs() {
+    let limits = CompileLimits { backrefs: false, ..CompileLimits::new() };
+    assert!(Regex::with_limits(r"(a)\001", limits).is_ok());
+    let err = Regex::with_limits(r"(a)\1", limits).unwrap_err();
+    assert_eq!((err.kind, err.pos), (BackrefsForbidden, 4));
+    let err = Regex::with_limits(r"(?P<a>a)\k<a>", limits).unwrap_err();
+    assert_eq!(err.kind, BackrefsForbidden);
+}
+
+#[test]
+fn options_flags() {
+    let opts = Options { case_insensitive: true, ..Options::new() };
+    let re = Regex::with_options("a(?-i)b", opts).unwrap();
+    assert!(re.is_match("Ab") && !re.is_match("AB"));
+    assert_eq!(format!("{}", re), ~"(?i)a(?-i)b");
+
+    let opts = Options { multi_line: true, ..Options::new() };
+    let re = Regex::with_options("^b$", opts).unwrap();
+    assert_eq!(re.find("a\nb\nc"), Some((2, 3)));
+
+    // Clearing one flag keeps the others.
+    let opts = Options { case_insensitive: true, dot_matches_new_line: true,
+                         ..Options::new() };
+    let re = Regex::with_options("a(?-i)b.", opts).unwrap();
+    assert!(re.is_match("Ab\n") && !re.is_match("AB\n"));
+    assert!(re.is_full_match("Ab\n"));
+
+    let opts = Options { unicode_word_boundary: true, ..Options::new() };
+    let re = Regex::with_options(r"a\b", opts).unwrap();
+    assert_eq!(re.find("a\u0663 a"), Some((4, 5)));
+    assert_eq!(format!("{}", re), ~"(?w)a\\b");
+
+    let opts = Options { ignore_whitespace: true, ..Options::new() };
+    let re = Regex::with_options("a b # c", opts).unwrap();
+    assert_eq!(re.find("a b ab"), Some((4, 6)));
+    assert_eq!(format!("{}", re), ~"(?x)a b # c");
+    let opts = Options { ignore_whitespace: true, literal: true,
+                         ..Options::new() };
+    let re = Regex::with_options("a b", opts).unwrap();
+    assert_eq!(re.find("ab a b"), Some((3, 6)));
+
+    let opts = Options { case_insensitive: true, full_case_folding: true,
+                         ..Options::new() };
+    let re = Regex::with_options("maße", opts).unwrap();
+    assert!(re.is_match("MASSE") && re.is_full_match("masse"));
+    assert_eq!(format!("{}", re), ~"(?if)maße");
+    assert!(ByteRegex::new("(?if)ß").is_err());
+}
+
+#[test]
+fn options_literal() {
+    let opts = Options { literal: true, ..Options::new() };
+    let re = Regex::with_options("a.b(", opts).unwrap();
+    assert!(!re.is_match("axb("));
+    assert_eq!(re.find("xa.b("), Some((1, 5)));
+
+    let opts = Options { literal: true, case_insensitive: true,
+                         ..Options::new() };
+    let re = Regex::with_options("A+", opts).unwrap();
+    assert!(re.is_match("a+") && !re.is_match("aa"));
+}
+
+#[test]
+fn options_longest() {
+    let opts = Options { longest: true, ..Options::new() };
+    let re = Regex::with_options("a|ab", opts).unwrap();
+    assert_eq!(re.find("abc"), Some((0, 2)));
+    let got: Vec<(uint, uint)> = re.find_iter("abaab").collect();
+    assert_eq!(got, vec![(0, 2), (2, 3), (3, 5)]);
+    assert_eq!(re.captures("xab").unwrap().pos(0), Some((1, 3)));
+
+    // The leftmost match still wins over a longer one further right.
+    let re = Regex::with_options("b|abc|bcdef", opts).unwrap();
+    assert_eq!(re.find("abcdef"), Some((0, 3)));
+    let re = Regex::with_options("a+?", opts).unwrap();
+    assert_eq!(re.find("baaa"), Some((1, 4)));
+}
+
+#[test]
+fn longest_per_call() {
+    let re = Regex::new("a|ab").unwrap();
+    assert!(!re.is_longest());
+    assert_eq!(re.find("abc"), Some((0, 1)));
+    assert_eq!(re.find_longest("abc"), Some((0, 2)));
+    assert_eq!(re.find_at_longest("xxabc", 1), Some((2, 4)));
+
+    let re = regex!("(a|ab)(c|bcd)?");
+    assert_eq!(re.captures("abcd").unwrap().pos(0), Some((0, 4)));
+    let caps = re.captures_longest("abcd").unwrap();
+    assert_eq!((caps.pos(1), caps.pos(2)), (Some((0, 1)), Some((1, 4))));
+    assert_eq!(re.find_longest("ab"), Some((0, 2)));
+}
+
+#[test]
+fn longest_per_call_shared() {
+    let re = Arc::new(Regex::new("a|ab").unwrap());
+    let (tx, rx) = channel();
+    for i in range(0u, 8) {
+        let (re, tx) = (re.clone(), tx.clone());
+        spawn(proc() {
+            let mut ok = true;
+            for _ in range(0u, 100) {
+                ok = ok && if i % 2 == 0 {
+                    re.find("abc") == Some((0, 1))
+                } else {
+                    re.find_longest("abc") == Some((0, 2))
+                };
+            }
+            tx.send(ok);
+        });
+    }
+    for _ in range(0u, 8) {
+        assert!(rx.recv());
+    }
+}
+
+#[test]
+fn posix_submatches() {
+    // Cases from the POSIX rationale and Glenn Fowler's testregex.
+    let cases = [
+        ("(a*)(a*)", "aaa", vec![Some((0, 3)), Some((0, 3)), Some((3, 3))]),
+        ("(a|ab)(c|bcd)(d*)", "abcd",
+         vec![Some((0, 4)), Some((0, 2)), Some((2, 3)), Some((3, 4))]),
+        ("(a|ab)(bc|c)", "abc",
+         vec![Some((0, 3)), Some((0, 2)), Some((2, 3))]),
+        ("(a*)*", "a", vec![Some((0, 1)), Some((0, 1))]),
+        ("(a*)+", "b", vec![Some((0, 0)), Some((0, 0))]),
+        ("(.*)(.*)", "ab", vec![Some((0, 2)), Some((0, 2)), Some((2, 2))]),
+        ("(a*)(ab)*(b*)", "abb",
+         vec![Some((0, 3)), Some((0, 1)), None, Some((1, 3))]),
+        ("(a|ab)(c|bcd)?", "xabcd",
+         vec![Some((1, 5)), Some((1, 2)), Some((2, 5))]),
+    ];
+    let opts = Options { posix: true, ..Options::new() };
+    for &(re, text, ref expected) in cases.iter() {
+        let re = Regex::with_options(re, opts).unwrap();
+        let caps = re.captures(text).unwrap();
+        let got: Vec<Option<(uint, uint)>> = caps.iter_pos().collect();
+        assert_eq!((re.to_str(), got), (re.to_str(), expected.clone()));
+    }
+
+    // Every match of `(a|a)*` can be taken two ways for each `a`, so the
+    // groups are given up on after a bounded number of steps (without a step
+    // limit). The match itself is still found.
+    let re = Regex::with_options("(a|a)*", opts).unwrap();
+    let text = "a".repeat(60);
+    let caps = re.try_captures(text.as_slice());
+    assert_eq!(caps.map(|caps| caps.is_some()), Err(StepLimitExceeded));
+    assert_eq!(re.find(text.as_slice()), Some((0, 60)));
+
+    // The overall match is the same without the POSIX rules, but not the
+    // groups.
+    let opts = Options { longest: true, ..Options::new() };
+    let re = Regex::with_options("(a|ab)(c|bcd)(d*)", opts).unwrap();
+    assert_eq!(re.captures("abcd").unwrap().pos(1), Some((0, 1)));
+}
+
+#[test]
+fn options_limits() {
+    let opts = Options { step_limit: Some(100), ..Options::new() };
+    let re = Regex::with_options("(a|b|ab)*c", opts).unwrap();
+    let text = "ab".repeat(1000) + "c";
+    assert!(re.try_is_match(text.as_slice()).is_err());
+
+    let limits = CompileLimits { max_insts: 10, ..CompileLimits::new() };
+    let opts = Options { limits: limits, ..Options::new() };
+    let err = Regex::with_options(r"\w{10}", opts).unwrap_err();
+    assert_eq!(err.kind, ProgramTooLarge);
+}
 
 macro_rules! mat(
     ($name:ident, $re:expr, $text:expr, $($loc:tt)+) => (
@@ -121,6 +847,23 @@ macro_rules! mat(
                 fail!("For RE '{}' against '{}', expected '{}' but got '{}'",
                       $re, text, sexpect, sgot);
             }
+            // Searching without capture groups may use a different
+            // matching engine, which must agree on the overall match.
+            let found = r.find(text);
+            if found != *expected.get(0) {
+                fail!("For RE '{}' against '{}', expected '{}' but found '{}'",
+                      $re, text, expected.get(0), found);
+            }
+            // The regex decoded from an encoding must match the same way.
+            let decoded = Regex::from_bytes(r.to_bytes().as_slice()).unwrap();
+            let dgot = match decoded.captures(text) {
+                Some(c) => c.iter_pos().collect::<Vec<Option<(uint, uint)>>>(),
+                None => vec!(None),
+            };
+            if dgot != got {
+                fail!("For RE '{}' against '{}', the decoded regex got '{}' \
+                       but the regex got '{}'", $re, text, dgot, got);
+            }
         }
     );
 )
@@ -164,6 +907,8 @@ mat!(match_flag_multi, "(?m)(?:^\\d+$\n?)+", "123\n456\n789", Some((0, 11)))
 mat!(match_flag_ungreedy, "(?U)a+", "aa", Some((0, 1)))
 mat!(match_flag_ungreedy_greedy, "(?U)a+?", "aa", Some((0, 2)))
 mat!(match_flag_ungreedy_noop, "(?U)(?-U)a+", "aa", Some((0, 2)))
+mat!(match_flag_set_and_clear, "(?s)(?i-s)a.", "Ab", Some((0, 2)))
+mat!(match_flag_set_and_clear_not, "(?s)(?i-s)a.", "A\n", None)
 
 // Some Unicode tests.
 mat!(uni_literal, r"Ⅰ", "Ⅰ", Some((0, 3)))
@@ -174,11 +919,51 @@ mat!(uni_not_class, r"[\PN]+", "abⅠ", Some((0, 2)))
 mat!(uni_not_class_neg, r"[^\PN]+", "abⅠ", Some((2, 5)))
 mat!(uni_case, r"(?i)Δ", "δ", Some((0, 2)))
 mat!(uni_case_not, r"Δ", "δ", None)
+mat!(uni_case_simple_fold, r"(?i)straße", "STRASSE", None)
+
+// Full case folding, with the 'f' flag.
+mat!(fullcase_sharp_s, r"(?if)straße", "STRASSE", Some((0, 7)))
+mat!(fullcase_sharp_s_text, r"(?if)strasse", "STRAßE", Some((0, 7)))
+mat!(fullcase_capital_sharp_s, r"(?if)ß", "ẞ", Some((0, 3)))
+mat!(fullcase_ligature, r"(?if)ﬁle", "FILE", Some((0, 4)))
+mat!(fullcase_ligature_text, r"(?if)file", "ﬁle", Some((0, 5)))
+mat!(fullcase_three, r"(?if)ﬃ", "FFI", Some((0, 3)))
+mat!(fullcase_three_part, r"(?if)ffi", "fﬁ", Some((0, 4)))
+mat!(fullcase_dotted_i, r"(?if)İ", "i\u0307", Some((0, 3)))
+mat!(fullcase_overlap_left, r"(?if)sss", "ßs", Some((0, 3)))
+mat!(fullcase_overlap_right, r"(?if)sss", "sß", Some((0, 3)))
+mat!(fullcase_long_run, r"(?if)ssssssssss", "ßßßßß", Some((0, 10)))
+mat!(fullcase_repeat, r"(?if)ß+", "ssßSs", Some((0, 6)))
+mat!(fullcase_group, r"(?if)(ß)x", "SSX", Some((0, 3)), Some((0, 2)))
+mat!(fullcase_needs_i, r"(?f)ß", "ss", None)
+mat!(fullcase_cleared, r"(?if)s(?-f)s", "ß", None)
+mat!(fullcase_class_simple, r"(?if)[ß]", "ss", None)
 mat!(uni_case_upper, r"\p{Lu}+", "ΛΘΓΔα", Some((0, 8)))
 mat!(uni_case_upper_nocase_flag, r"(?i)\p{Lu}+", "ΛΘΓΔα", Some((0, 10)))
 mat!(uni_case_upper_nocase, r"\p{L}+", "ΛΘΓΔα", Some((0, 10)))
 mat!(uni_case_lower, r"\p{Ll}+", "ΛΘΓΔα", Some((8, 10)))
 
+// Test loose Unicode property names, binary properties and scripts.
+mat!(uni_loose_long_name, r"\p{Uppercase_Letter}+", "ΛΘα", Some((0, 4)))
+mat!(uni_loose_spaces, r"\p{uppercase letter}+", "ΛΘα", Some((0, 4)))
+mat!(uni_loose_is, r"\p{IsGreek}+", "aαβ", Some((1, 5)))
+mat!(uni_gc_key, r"\p{gc=Lu}", "aB", Some((1, 2)))
+mat!(uni_gc_long_key, r"\p{General_Category:Decimal Number}+", "x42",
+     Some((1, 3)))
+mat!(uni_sc_alias, r"\p{sc=Grek}", "aα", Some((1, 3)))
+mat!(uni_sc_not_scx, r"\p{sc=Greek}", "a͂", None)
+mat!(uni_scx, r"\p{scx=Greek}", "a͂", Some((1, 3)))
+mat!(uni_any, r"\p{Any}+", "a\nα", Some((0, 4)))
+mat!(uni_not_any, r"\P{Any}", "a\nα", None)
+mat!(uni_ascii, r"\p{ASCII}+", "abé", Some((0, 2)))
+mat!(uni_assigned, r"\P{Assigned}", "a\U000E0080", Some((1, 5)))
+mat!(uni_unassigned, r"\p{Cn}", "a\U000E0080", Some((1, 5)))
+mat!(uni_binary, r"\p{Alphabetic}+", "aé1", Some((0, 3)))
+mat!(uni_binary_alias, r"\p{WSpace}+", "a 　", Some((1, 5)))
+mat!(uni_binary_no, r"\p{Alpha=No}+", "a12b", Some((1, 3)))
+mat!(uni_binary_not_no, r"\P{Alpha=No}+", "a12b", Some((0, 1)))
+mat!(uni_binary_class, r"[\p{Hex_Digit}--\d]+", "x1aF", Some((2, 4)))
+
 // Test the Unicode friendliness of Perl character classes.
 mat!(uni_perl_w, r"\w+", "dδd", Some((0, 4)))
 mat!(uni_perl_w_not, r"\w+", "Ⅱ", None)
@@ -194,6 +979,3043 @@ mat!(uni_perl_s_neg, r"\S+", "☃", Some((0, 3)))
 mat!(uni_boundary_none, r"\d\b", "6δ", None)
 mat!(uni_boundary_ogham, r"\d\b", "6 ", Some((0, 1)))
 
+// With Unicode off, classes, case folding and word boundaries are ASCII only.
+mat!(ascii_perl_w, r"(?-u)\w+", "dδd", Some((0, 1)))
+mat!(ascii_perl_d, r"(?-u)\d+", "1२३9", Some((0, 1)))
+mat!(ascii_perl_s_neg, r"(?-u)\S+", "\u3000", Some((0, 3)))
+mat!(ascii_case, r"(?i-u)δ", "Δ", None)
+mat!(ascii_case_class, r"(?i-u)[a-c]+x", "aBCX", Some((0, 4)))
+mat!(ascii_boundary, r"(?-u)\d\b", "6δ", Some((0, 1)))
+mat!(ascii_boundary_word, r"(?-u)\bx", "δx", Some((2, 3)))
+mat!(ascii_boundary_not, r"(?-u)δ\B", "δ ", Some((0, 2)))
+mat!(ascii_unicode_again, r"(?-u)\w(?u)\w", "aδ", Some((0, 3)))
+mat!(class_escaped_range, r"[\x41-\x{43}\x2D-\x2E]+", "xAC-.B", Some((1, 6)))
+mat!(char_name, r"\N{LATIN SMALL LETTER A WITH DIAERESIS}\N{U+E4}", "xää",
+     Some((1, 5)))
+mat!(char_name_case, r"\N{Snowman}", "☃", Some((0, 3)))
+mat!(char_name_class, r"[\N{SNOWMAN}\N{U+61}-b]+", "x☃ab", Some((1, 6)))
+mat!(char_name_cjk, r"\N{CJK UNIFIED IDEOGRAPH-4E00}", "一", Some((0, 3)))
+mat!(char_name_hangul, r"\N{HANGUL SYLLABLE GAG}\N{hangul syllable a}",
+     "각아", Some((0, 6)))
+
+// Free-spacing mode.
+mat!(x_space, r"(?x)a b  c", "abc", Some((0, 3)))
+mat!(x_tab_new_line, "(?x)a\t\n b", "ab", Some((0, 2)))
+mat!(x_comment, "(?x)a # a comment\nb", "ab", Some((0, 2)))
+mat!(x_comment_end, r"(?x)a#b", "a#b", Some((0, 1)))
+mat!(x_comment_paren, "(?x)a # (\nb", "ab", Some((0, 2)))
+mat!(x_class_space, r"(?x)[ ]a", " a", Some((0, 2)))
+mat!(x_class_hash, "(?x)[a#]\nb", "#b", Some((0, 2)))
+mat!(x_escaped_space, r"(?x)a\ b", "a b", Some((0, 3)))
+mat!(x_escaped_hash, r"(?x)a\#b", "a#b", Some((0, 3)))
+mat!(x_repeat, r"(?x)a +", "aa", Some((0, 2)))
+mat!(x_counted, r"(?x)a {2}", "aaa", Some((0, 2)))
+mat!(x_alternate, r"(?x) a | b ", "b", Some((0, 1)))
+mat!(x_capture, r"(?x) ( a ) ( b )", "ab", Some((0, 2)), Some((0, 1)),
+     Some((1, 2)))
+mat!(x_off, r"(?x)a(?-x) b", "a b", Some((0, 3)))
+mat!(x_group, r"(?x:a b) c", "ab c", Some((0, 4)))
+mat!(x_group_scope, r"((?x)a b) c", "ab c", Some((0, 4)))
+mat!(x_group_off, r"(?x)a(?-x: b )c", "a b c", Some((0, 5)))
+mat!(x_default, r"a b#c", "a b#c", Some((0, 5)))
+
+// Nested classes and class set operations.
+mat!(class_nested, r"[a[0-9]]+", "x1a2", Some((1, 4)))
+mat!(class_nested_negated, r"[[^a-z]x]+", "ax9b", Some((1, 3)))
+mat!(class_negated_union, r"[^\da]", "1a2b", Some((3, 4)))
+mat!(class_negated_nested, r"[^[0-9]a]", "1a2b", Some((3, 4)))
+mat!(class_intersect, r"[a-z&&[^aeiou]]+", "aebcdi", Some((2, 5)))
+mat!(class_intersect_unicode, r"[\pL&&\p{Greek}]+", "aδλb", Some((1, 5)))
+mat!(class_difference, r"[\pL--\p{Latin}]+", "abδλc", Some((2, 6)))
+mat!(class_symmetric, r"[a-c~~b-d]+", "bad", Some((1, 3)))
+mat!(class_op_left_assoc, r"^[a-z&&[^x]--b]+$", "acy", Some((0, 3)))
+mat!(class_op_left_assoc_no, r"[a-z&&[^x]--b]", "xb", None)
+mat!(class_op_union_first, r"[ab&&bc]", "ab", Some((1, 2)))
+mat!(class_op_negated, r"[^a-z&&b]+", "bac", Some((1, 3)))
+mat!(class_op_casei, r"(?i)[a-z&&[^aeiou]]+", "AEbCDI", Some((2, 5)))
+mat!(class_op_escaped, r"[a\&\&b]+", "x&ab", Some((1, 4)))
+mat!(class_op_escaped_range, r"[!-\-]+", "a+-!", Some((1, 4)))
+mat!(class_op_leading_dash, r"[--a]+", "x-a", Some((1, 3)))
+mat!(class_escaped_bracket, r"[\[a]+", "x[a", Some((1, 3)))
+mat!(class_nested_was_literal, r"[[x]y]", "[y]", Some((1, 2)))
+mat!(class_nested_old_meaning, r"[\[x]y\]", "[y]", Some((0, 3)))
+
+// Unicode word boundaries with the `w` flag.
+mat!(uword_mark, r"(?w)\w+\b", "cafe\u0301 x", Some((7, 8)))
+mat!(uword_mark_default, r"\w+\b", "cafe\u0301 x", Some((0, 4)))
+mat!(uword_not_mark, r"(?w)e\B", "cafe\u0301", Some((3, 4)))
+mat!(uword_digit, r"(?w)a\b", "a\u0663 a", Some((4, 5)))
+mat!(uword_digit_default, r"a\b", "a\u0663 a", Some((0, 1)))
+mat!(uword_connector, r"(?w)a\b", "a\u203Fb", None)
+mat!(uword_cyrillic, r"(?w)\bслово\b", "словоx слово", Some((12, 22)))
+mat!(uword_ascii, r"(?w-u)e\b", "e\u0301", Some((0, 1)))
+mat!(uword_off, r"(?w)a(?-w:\b)", "a\u0663", Some((0, 1)))
+
+// Grapheme cluster and word boundaries from UAX #29.
+mat!(seg_g_mark, r"(?s).+?\b{g}", "e\u0301x", Some((0, 3)))
+mat!(seg_g_not_mark, r"e\B{g}", "e\u0301", Some((0, 1)))
+mat!(seg_g_crlf, r"(?s).+?\b{g}", "\r\nx", Some((0, 2)))
+mat!(seg_g_zwj, r"(?s).+?\b{g}", "👩\u200D👩\u200D👧x", Some((0, 18)))
+mat!(seg_g_skin_tone, r"(?s).+?\b{g}", "👍🏽x", Some((0, 8)))
+mat!(seg_g_flags, r"(?s).+?\b{g}", "🇫🇷🇩🇪", Some((0, 8)))
+mat!(seg_g_flags_inside, r"\B{g}", "🇫🇷🇩🇪", Some((4, 4)))
+
+#[test]
+fn seg_g_flags_long_run() {
+    // Only the first 64 regional indicators of a run are paired.
+    let re = regex!(r"\X");
+    let text = "🇫".repeat(70);
+    let lens: Vec<uint> = re.find_iter(text.as_slice()).map(|(s, e)| e - s)
+                            .collect();
+    let mut want = Vec::from_elem(32, 8u);
+    want.grow(6, &4u);
+    assert_eq!(lens, want);
+    let re = regex!(r"\b{wb}.+?\b{wb}");
+    assert_eq!(re.find_iter(text.as_slice()).count(), 38);
+}
+mat!(seg_g_spacing_mark, r"(?s).+?\b{g}", "निx", Some((0, 6)))
+mat!(seg_g_conjunct, r"(?s).+?\b{g}", "क्षx", Some((0, 9)))
+mat!(seg_g_hangul, r"(?s).+?\b{g}", "\u1100\u1161\u11A8x", Some((0, 9)))
+mat!(seg_wb_apostrophe, r"\b{wb}.+?\b{wb}", "can't x", Some((0, 5)))
+mat!(seg_wb_decimal, r"\b{wb}\d.+?\b{wb}", "pi 3.14.", Some((3, 7)))
+mat!(seg_wb_letter_digit, r"\b{wb}.+?\b{wb}", "a1b c", Some((0, 3)))
+mat!(seg_wb_spaces, r"\b{wb} \b{wb}", "a  b", None)
+mat!(seg_wb_mark, r"\b{wb}.+?\b{wb}", "e\u0301t x", Some((0, 4)))
+mat!(seg_wb_hebrew, r"\b{wb}.+?\b{wb}", "\u05D0\"\u05D1 x", Some((0, 5)))
+mat!(seg_wb_katakana, r"\b{wb}.+?\b{wb}", "カタカナ x", Some((0, 12)))
+mat!(seg_wb_flags, r"\b{wb}.+?\b{wb}", "🇫🇷🇩🇪", Some((0, 8)))
+mat!(seg_wb_not, r"\B{wb}", "ab", Some((1, 1)))
+mat!(grapheme_mark, r"\X", "e\u0301x", Some((0, 3)))
+mat!(grapheme_zwj, r"^\X$", "👩\u200D👩\u200D👧", Some((0, 18)))
+mat!(grapheme_one, r"^\X$", "ab", None)
+mat!(grapheme_crlf, r"\X", "\r\n", Some((0, 2)))
+mat!(grapheme_counted, r"\X{3}", "e\u0301🇫🇷n\u0303x", Some((0, 14)))
+mat!(grapheme_group, r"(\X)(\X)", "🇫🇷🇩🇪", Some((0, 16)), Some((0, 8)),
+     Some((8, 16)))
+mat!(grapheme_lazy, r"\X+?b", "a\u0301b", Some((0, 4)))
+
+// Line breaks.
+mat!(line_break_crlf, r"\R", "a\r\nb", Some((1, 3)))
+mat!(line_break_cr, r"\R", "a\rb", Some((1, 2)))
+mat!(line_break_cr_end, r"a\R$", "a\r", Some((0, 2)))
+mat!(line_break_alt, r"\R\n", "\r\n", Some((0, 2)))
+mat!(line_break_atomic, r"\R\n(?!x)", "\r\n", None)
+mat!(line_break_atomic_alt, r"^\R(?:\n|x)", "\r\nx", Some((0, 3)))
+mat!(line_break_unicode, r"\R+", "a\x85\u2028\x0B\x0C\nb", Some((1, 9)))
+mat!(line_break_ascii, r"(?-u)\R", "\u2029\n", Some((3, 4)))
+mat!(line_break_repeat, r"^(?:a\R){3}$", "a\r\na\na\r", Some((0, 7)))
+mat!(line_break_group, r"(\R)(\R)", "\r\r\n", Some((0, 3)), Some((0, 1)),
+     Some((1, 3)))
+
 // A whole mess of tests from Glenn Fowler's regex test suite.
 // Generated by the 'src/etc/regex-match-tests' program.
 mod matches;
+
+// Regexes with a literal prefix skip ahead to candidate positions.
+mat!(prefix_long, r"abcd[0-9]", "abc abcd abcd7", Some((9, 14)))
+mat!(prefix_overlap, r"aab", "aaaab", Some((2, 5)))
+mat!(prefix_none, r"xyz\d", "xyz xyzz xy1", None)
+mat!(prefix_not_word, r"foo\b", "foobar foo", Some((7, 10)))
+mat!(prefix_unicode, r"☃δ+", "☃ ☃δδ", Some((4, 11)))
+mat!(prefix_casei, r"(?i)abc", "xxABC", Some((2, 5)))
+mat!(prefix_casei_partial, r"a(?i)bc", "ABC aBC", Some((4, 7)))
+
+// Alternations of literals are found with a multi-literal prefilter.
+mat!(literals_alt, r"agggtaaa|tttaccct", "xtttaccctagggtaaa", Some((1, 9)))
+mat!(literals_alt_overlap, r"bc|abcd", "abcd", Some((0, 4)))
+mat!(literals_alt_first1, r"a|ab", "xab", Some((1, 2)))
+mat!(literals_alt_first2, r"ab|a", "xab", Some((1, 3)))
+mat!(literals_alt_none, r"foo|bar|quux", "fobaquu", None)
+mat!(literals_class, r"[cgt]gggtaaa|tttaccc[acg]", "agggtaaa tttacccg",
+     Some((9, 17)))
+mat!(literals_captures, r"(foo|ba(r|z))+", "xbazbarfoo!",
+     Some((1, 10)), Some((7, 10)))
+mat!(literals_prefix_only, r"(?:ab|cd)\w*\b", "xx cdef ab", Some((3, 7)))
+mat!(literals_unicode, r"δ|☃x", "aa☃☃xδ", Some((5, 9)))
+
+// Literals that must appear after the start of every match.
+mat!(inner_suffix, r"[a-z]+@example\.com", "x A@example.com bob@example.com",
+     Some((16, 31)))
+mat!(inner_suffix_none, r"[a-z]+@example\.com", "bob@example.org", None)
+mat!(inner_suffix_leftmost, r"[a-z]+\.com", "a.co b.com c.com", Some((5, 10)))
+mat!(inner_bounded, r"\d{2}-abc", "1-abc 12abc 34-abc", Some((12, 18)))
+mat!(inner_bounded_overlap, r"a?a?a?aab", "aaaaab", Some((0, 6)))
+mat!(inner_bounded_overlap2, r"a?a?aab", "aaaaab", Some((1, 6)))
+mat!(inner_bounded_unicode, r"\w?\w?xyz", "δδδxyz", Some((2, 9)))
+mat!(inner_captures, r"(\w+) (\w+)@(\w+)", "a b@c", Some((0, 5)),
+     Some((0, 1)), Some((2, 3)), Some((4, 5)))
+
+#[test]
+fn explain_prefilter() {
+    let explain = |re: &str| Regex::new(re).unwrap().explain_prefilter();
+    assert_eq!(explain(r"\w+"), ~"none");
+    assert_eq!(explain("abc"), ~"complete literals [\"abc\"] using memchr");
+    assert_eq!(explain("abc|xyz"),
+               ~"complete literals [\"abc\", \"xyz\"] using teddy");
+    assert_eq!(explain(r"ab\w"), ~"prefix literals [\"ab\"] using memchr");
+    assert_eq!(explain(r"\w\wfoo"),
+               ~"inner (at most 8 bytes in) literals [\"foo\"] using memchr");
+    assert_eq!(explain(r"[a-z]+@example\.com"),
+               ~"inner (unbounded) literals [\"@example.com\"] using memchr");
+    assert_eq!(explain(r"(?i)Error"), ~"complete literals [\"error\"] \
+                                        (ASCII case insensitive) using teddy");
+    assert_eq!(explain(r"a(?i)b\b"), ~"prefix literals [\"ab\"] \
+                                       (ASCII case insensitive) using teddy");
+}
+
+#[test]
+fn prefilter_info() {
+    let info = |re: &str| Regex::new(re).unwrap().prefilter_info();
+    let lits = |lits: &[&str]| -> Vec<Vec<u8>> {
+        lits.iter().map(|l| Vec::from_slice(l.as_bytes())).collect()
+    };
+    let i = info(r"\w+");
+    assert!(i.prefixes.is_empty() && i.inner.is_empty());
+    assert!(!i.anchored && !i.exact);
+    let i = info("abc|xyz");
+    assert_eq!(i.prefixes, lits(&["abc", "xyz"]));
+    assert!(i.exact && !i.anchored && !i.nocase);
+    let i = info(r"^(?i)Ab\d");
+    assert_eq!(i.prefixes, lits(&["ab"]));
+    assert!(i.anchored && i.nocase && !i.exact);
+    assert!(!info(r"(?m)^ab").anchored);
+    assert!(!info(r"\Gab").anchored);
+    let i = info(r"\w\wfoo");
+    assert_eq!((i.prefixes.len(), i.inner.clone(), i.inner_offset),
+               (0, lits(&["foo"]), Some(8)));
+    let i = info(r"[a-z]+@example\.com");
+    assert_eq!((i.inner.clone(), i.inner_offset),
+               (lits(&["@example.com"]), None));
+
+    // The prefixes are the ones `literal_prefixes` reports, except that a
+    // literal cut short isn't cut again at a character boundary.
+    let long = format!("{}é", "a".repeat(31));
+    let re = Regex::new(long.as_slice()).unwrap();
+    assert_eq!(re.prefilter_info().prefixes,
+               vec!(Vec::from_slice(long.as_bytes().slice_to(32))));
+    assert_eq!(re.literal_prefixes(), Some((vec!("a".repeat(31)), false)));
+}
+
+// Case insensitive ASCII literals are found by prefilters that ignore case,
+// except for the letters that non-ASCII characters fold to.
+mat!(literals_casei, r"(?i)error", "an eRRor: ERROR", Some((3, 8)))
+mat!(literals_casei_alt, r"(?i)foo|bar|x", "xfoO BAR", Some((0, 1)))
+mat!(literals_casei_mixed, r"a(?i)bc|(?-i)é", "ABC É aBC é", Some((7, 10)))
+mat!(literals_casei_inner, r"\w+(?i)@EXAMPLE", "a@b bob@Example",
+     Some((4, 15)))
+mat!(literals_casei_kelvin, r"(?i)kelvin", "KELVIN", Some((0, 6)))
+
+#[test]
+fn casei_literal_prefilters() {
+    let re = Regex::new(r"(?i)abc: ").unwrap();
+    assert_eq!(re.literal_prefixes(), Some((vec!(~"abc: "), true)));
+    assert_eq!(Regex::new(r"(?i)x?abc").unwrap().literal_prefixes(), None);
+    // `k` could match the Kelvin sign, so the literal starts after it.
+    let re = Regex::new(r"(?i)kelp").unwrap();
+    assert_eq!(re.literal_prefixes(), None);
+    assert!(re.explain_prefilter().contains("[\"elp\"]"));
+    let re = Regex::new(r"(?i)ssé").unwrap();
+    assert_eq!(re.literal_prefixes(), None);
+
+    // Every scanner ignores case the same way: one literal and a few are
+    // searched with Teddy, and many with Aho-Corasick.
+    let many: Vec<~str> = range(0, 40).map(|i| format!("a{}B", i)).collect();
+    let many = format!("(?i){}", many.as_slice().connect("|"));
+    let tests = [
+        (r"(?i)error", r"[eE][rR][rR][oO][rR]"),
+        (r"(?i)ab|xyz|q", r"[aA][bB]|[xX][yY][zZ]|[qQ]"),
+        (r"(?i)a(?-i)B|c", r"[aA]B|c"),
+        (many.as_slice(), r"[aA](?:[0-9]|[1-3][0-9])[bB]"),
+    ];
+    let text = "ErRoR eRror A1B ab AB a22b Ab XyZ q Q aB cc a39B a40b";
+    for &(casei, classes) in tests.iter() {
+        let (casei, classes) = (Regex::new(casei).unwrap(),
+                                Regex::new(classes).unwrap());
+        assert!(casei.explain_prefilter().contains("case insensitive"));
+        assert_eq!(casei.find_iter(text).collect::<Vec<(uint, uint)>>(),
+                   classes.find_iter(text).collect::<Vec<(uint, uint)>>());
+        let decoded = Regex::from_bytes(casei.to_bytes().as_slice()).unwrap();
+        assert_eq!(decoded.explain_prefilter(), casei.explain_prefilter());
+    }
+}
+
+#[test]
+fn teddy_agrees_with_aho_corasick() {
+    // Up to 32 literals are searched with Teddy, and more with
+    // Aho-Corasick. With literals that aren't in the text added, both find
+    // the matches that trying each literal at every position finds.
+    let few = ["a1b", "a22b", "xa", "b9", "a39b", "a33b", "a3"];
+    let padding: Vec<~str> = range(0, 30).map(|i| format!("q{}z", i)).collect();
+    let mut many: Vec<&str> = Vec::from_slice(few.as_slice());
+    for lit in padding.iter() {
+        many.push(lit.as_slice());
+    }
+    let text = "a3 2a22 a39 a39b xy a33bb9 xa1b";
+    let expected = naive_find_iter(few.as_slice(), text);
+    assert_eq!(expected, vec!((0, 2), (8, 10), (12, 16), (20, 24), (24, 26),
+                              (27, 29)));
+    assert_eq!(naive_find_iter(many.as_slice(), text), expected);
+
+    let teddy = Regex::new(few.as_slice().connect("|")).unwrap();
+    let aho = Regex::new(many.as_slice().connect("|")).unwrap();
+    assert!(teddy.explain_prefilter().ends_with("teddy"));
+    assert!(aho.explain_prefilter().ends_with("aho-corasick"));
+    assert_eq!(teddy.find_iter(text).collect::<Vec<(uint, uint)>>(), expected);
+    assert_eq!(aho.find_iter(text).collect::<Vec<(uint, uint)>>(), expected);
+}
+
+// Returns the matches of the alternation of `lits` in `text` (which is
+// ASCII), by trying each literal in turn at every position.
+fn naive_find_iter(lits: &[&str], text: &str) -> Vec<(uint, uint)> {
+    let mut found = vec!();
+    let mut i = 0;
+    while i < text.len() {
+        let rest = text.slice_from(i);
+        match lits.iter().find(|lit| rest.starts_with(**lit)) {
+            Some(lit) => {
+                found.push((i, i + lit.len()));
+                i += lit.len();
+            }
+            None => i += 1,
+        }
+    }
+    found
+}
+
+// The start of a match is found by scanning backwards from its end, which
+// requires evaluating assertions in reverse.
+mat!(reverse_begin, r"^a+", "aaab", Some((0, 3)))
+mat!(reverse_begin_multi, r"(?m)^a+b", "aab\nab", Some((0, 3)))
+mat!(reverse_begin_multi2, r"(?m)^a+b", "xab\naab", Some((4, 7)))
+mat!(reverse_end_multi, r"(?m)a+$", "xaa\nb", Some((1, 3)))
+mat!(reverse_word, r"\ba+", "baa aax", Some((4, 6)))
+mat!(reverse_not_word, r"\Ba+z", "az baaz", Some((4, 7)))
+mat!(reverse_leftmost, r"[a-c]+d", "xxabcabcd", Some((2, 9)))
+mat!(reverse_empty, r"x*", "aaa", Some((0, 0)))
+mat!(reverse_unicode, r"\w+δ", "a ☃bδδ", Some((5, 10)))
+
+#[test]
+fn reverse_find_iter_context() {
+    // Later searches start in the middle of the text, so the assertions
+    // must look at the text before the start of the search.
+    let re = regex!(r"\b\w+\b|(?m)^x");
+    let text = "ab cd\nx";
+    assert_eq!(re.find_iter(text).collect::<Vec<(uint, uint)>>(),
+               vec!((0, 2), (3, 5), (6, 7)));
+    let re = regex!(r"\Bb+");
+    assert_eq!(re.find_iter("abb bb abab").collect::<Vec<(uint, uint)>>(),
+               vec!((1, 3), (5, 6), (8, 9), (10, 11)));
+}
+
+// Anchored expressions with captures are executed by the one-pass engine,
+// which must agree with the VM on every capture group.
+mat!(onepass_simple, r"^(\w+):(\d+)$", "abc:123", Some((0, 7)),
+     Some((0, 3)), Some((4, 7)))
+mat!(onepass_no_match, r"^(\w+):(\d+)$", "abc:12x", None)
+mat!(onepass_not_at_start, r"^(\w+):(\d+)$", "!abc:123", None)
+mat!(onepass_empty_groups, r"^(a*)(b?)$", "", Some((0, 0)),
+     Some((0, 0)), Some((0, 0)))
+mat!(onepass_nested_optional, r"^(a(b)?)?c$", "ac", Some((0, 2)),
+     Some((0, 1)), None)
+mat!(onepass_nested_optional2, r"^(a(b)?)?c$", "c", Some((0, 1)), None, None)
+mat!(onepass_nested_optional3, r"^(a(b)?)?c$", "abc", Some((0, 3)),
+     Some((0, 2)), Some((1, 2)))
+mat!(onepass_negated_class, r"^([^:]+):(.*)$", "key:value:x", Some((0, 11)),
+     Some((0, 3)), Some((4, 11)))
+mat!(onepass_trailing, r"^(a+)(b+)", "aabbc", Some((0, 4)),
+     Some((0, 2)), Some((2, 4)))
+mat!(onepass_lazy, r"^(a+?)b", "aaab", Some((0, 4)), Some((0, 3)))
+mat!(onepass_multi_end, r"(?m)^(\d+)$", "12\n34", Some((0, 2)), Some((0, 2)))
+mat!(onepass_alternation, r"^(?:(a)|(b))c$", "bc", Some((0, 2)),
+     None, Some((0, 1)))
+mat!(onepass_unicode, r"^(\w+)☃(x?)$", "aβ☃", Some((0, 6)),
+     Some((0, 3)), Some((6, 6)))
+
+#[test]
+fn backtrack_agrees_with_nfa() {
+    let res: &[&str] = &[r"(a*)*b", r"(a|ab)(c|bcd)(d*)", r"(\w+)\s+(\w+)?",
+                         r"\b(fo+)\b", r"(?m)^(b)$", r"(x*)", r"(a+?)(a*)",
+                         r"(?i)(δ+)(\w)"];
+    let text = "aab foo\nb\nabcd aΔδx xx";
+    for re in res.iter() {
+        let mut bt = Regex::new(*re).unwrap();
+        bt.set_dfa_size_limit(0);
+        bt.set_backtrack_limit(1 << 20);
+        let mut nfa = Regex::new(*re).unwrap();
+        nfa.set_dfa_size_limit(0);
+        nfa.set_backtrack_limit(0);
+        assert_eq!(bt.is_match(text), nfa.is_match(text));
+        assert_eq!(bt.find_iter(text).collect::<Vec<(uint, uint)>>(),
+                   nfa.find_iter(text).collect::<Vec<(uint, uint)>>());
+        let bt_caps: Vec<Vec<Option<(uint, uint)>>> =
+            bt.captures_iter(text).map(|c| c.iter_pos().collect()).collect();
+        let nfa_caps: Vec<Vec<Option<(uint, uint)>>> =
+            nfa.captures_iter(text).map(|c| c.iter_pos().collect()).collect();
+        assert_eq!(bt_caps, nfa_caps);
+    }
+}
+
+#[test]
+fn backtrack_no_exponential() {
+    // Without memoization, each of these would take exponential time.
+    let text = "a".repeat(30);
+    let re = Regex::new(r"(a*)*b").unwrap();
+    assert!(re.captures(text).is_none());
+    let re = Regex::new(r"(a|aa)*(a|aa)*c").unwrap();
+    assert!(re.backtrack_limit() > 0);
+    assert!(re.captures(text).is_none());
+    let text = text + "b";
+    let re = Regex::new(r"(a*)*b").unwrap();
+    let caps = re.captures(text).unwrap();
+    assert_eq!(caps.pos(0), Some((0, 31)));
+    assert_eq!(caps.pos(1), Some((0, 30)));
+}
+
+// Tiny expressions are searched with a bit-parallel engine.
+mat!(shiftor_date, r"[0-9]{4}-[0-9]{2}-[0-9]{2}", "on 2014-5-12 or 2014-05-12",
+     Some((16, 26)))
+mat!(shiftor_optional, r"ab?c*d", "acd abd ad", Some((0, 3)))
+mat!(shiftor_all_optional, r"a?b*", "xab", Some((0, 0)))
+mat!(shiftor_repeat, r"a+b", "aac aaab", Some((4, 8)))
+mat!(shiftor_anchored, r"^a+b", "aab", Some((0, 3)))
+mat!(shiftor_anchored_none, r"^a+b", "xaab", None)
+mat!(shiftor_end, r"a+$", "aa baa", Some((4, 6)))
+mat!(shiftor_end_none, r"a+$", "aa bab", None)
+mat!(shiftor_unicode, r"δ.☃", "δaδ☃☃", Some((3, 11)))
+mat!(shiftor_unicode_class, r"[^a]b", "abδb", Some((2, 5)))
+mat!(shiftor_casei, r"(?i)ab+", "xABBb", Some((1, 5)))
+
+#[test]
+fn shiftor_agrees_with_nfa() {
+    let res: &[&str] = &[r"a+", r"ab?c*d", r"x*", r"^a*", r"a*$", r"δ+",
+                         r"[^a]b", r"(?i)δ", r"a.b", r"(?s)a.b", r"\d+-\d+",
+                         r"a+?", r"b??a"];
+    let text = "aab acd\nb\nab aΔδx xx 12-3 a\nb";
+    for re in res.iter() {
+        let so = Regex::new(*re).unwrap();
+        let mut nfa = Regex::new(*re).unwrap();
+        nfa.set_dfa_size_limit(0);
+        assert_eq!(so.is_match(text), nfa.is_match(text));
+        assert_eq!(so.find_iter(text).collect::<Vec<(uint, uint)>>(),
+                   nfa.find_iter(text).collect::<Vec<(uint, uint)>>());
+    }
+}
+
+#[test]
+fn count_agrees_with_find_iter() {
+    let text = "aab foo\nb\nab aΔδx xx";
+    let res: &[&str] = &["a+", "x*", r"\b", "(?m)^", "a|ab", "[^a]", "zzz",
+                         r"\w+", "a+b"];
+    for re in res.iter() {
+        let re = Regex::new(*re).unwrap();
+        assert_eq!(re.count(text), re.find_iter(text).count());
+    }
+    // The empty match at 3 follows the match that ends there, so it's
+    // skipped.
+    let re = regex!("x*");
+    assert_eq!(re.find_iter("axxbx").collect::<Vec<(uint, uint)>>(),
+               vec!((0, 0), (1, 3), (4, 5)));
+    assert_eq!(re.count("axxbx"), 3);
+    assert_eq!(regex!("a").count(""), 0);
+    assert_eq!(regex!("").count("abc"), 4);
+}
+
+#[test]
+fn find_overlapping() {
+    let re = regex!("aa");
+    assert_eq!(re.find_overlapping_iter("aaaa").collect::<Vec<(uint, uint)>>(),
+               vec!((0, 2), (1, 3), (2, 4)));
+    assert_eq!(re.count_overlapping("aaaa"), 3);
+    assert_eq!(re.find_overlapping_iter("aaaa").take(2).count(), 2);
+    assert_eq!(re.count_overlapping("a"), 0);
+
+    // Empty matches advance by a character, like in `find_iter`.
+    let re = regex!("x*");
+    assert_eq!(re.find_overlapping_iter("xxδ").collect::<Vec<(uint, uint)>>(),
+               vec!((0, 2), (1, 2), (2, 2), (4, 4)));
+
+    // Each match is searched for in the context of the whole text.
+    let re = regex!(r"\w+");
+    assert_eq!(re.find_overlapping_iter("ab cd").collect::<Vec<(uint, uint)>>(),
+               vec!((0, 2), (3, 5)));
+    let re = regex!("GG[ACGT]|[ACGT]GG");
+    assert_eq!(re.count_overlapping("AGGGA"), 3);
+}
+
+#[test]
+fn empty_matches_advance_by_character() {
+    let re = regex!("x*");
+    assert_eq!(re.find_iter("aδb").collect::<Vec<(uint, uint)>>(),
+               vec!((0, 0), (1, 1), (3, 3), (4, 4)));
+    assert_eq!(re.count("δδ"), 3);
+}
+
+#[test]
+fn captures_map() {
+    let re = regex!(r"(?P<key>\w*)=(\d+)(?P<unit>px)?(?P<rest>x*)");
+    let map = re.captures_map("a =12").unwrap();
+    assert_eq!(map.len(), 2);
+    assert_eq!(*map.get(&~"key"), "");
+    assert_eq!(*map.get(&~"rest"), "");
+    assert!(!map.contains_key(&~"unit"));
+
+    let map = re.captures_pos_map("w=3pxx").unwrap();
+    assert_eq!(*map.get(&~"key"), (0, 1));
+    assert_eq!(*map.get(&~"unit"), (3, 5));
+    assert_eq!(*map.get(&~"rest"), (5, 6));
+
+    assert!(re.captures_map("none").is_none());
+    assert!(regex!(r"(\d)").captures_map("1").unwrap().is_empty());
+}
+
+#[test]
+fn annotate() {
+    let annotate = |re: &str, text: &str| -> ~str {
+        let re = Regex::new(re).unwrap();
+        re.annotate(text, true, |i, s| format!("\\{{}:{}\\}", i, s))
+          .into_owned()
+    };
+    assert_eq!(annotate(r"(\w+)=(\d*)", "a=1 b="),
+               ~"{0:{1:a}={2:1}} {0:{1:b}={2:}}");
+    // Groups that didn't take part in the match aren't wrapped.
+    assert_eq!(annotate(r"(a)|(b)", "ba"), ~"{0:{2:b}}{0:{1:a}}");
+    // Groups with the same span nest in order.
+    assert_eq!(annotate(r"((a))", "a"), ~"{0:{1:{2:a}}}");
+    assert_eq!(annotate(r"(?=(ab))(abc)", "abc"), ~"{0:{2:{1:ab}c}}");
+    // Groups outside of the match, or crossing the end of the group they
+    // start in, are left out.
+    assert_eq!(annotate(r"a(?=(bc))", "abc"), ~"{0:a}bc");
+    assert_eq!(annotate(r"(a(?=(bc))b)c", "abc"), ~"{0:{1:ab}c}");
+    assert_eq!(annotate(r"a\K(b)", "ab"), ~"a{0:{1:b}}");
+    // An empty match is wrapped where it matched.
+    assert_eq!(annotate(r"(x?)", "ab"), ~"{0:{1:}}a{0:{1:}}b{0:{1:}}");
+
+    // Matches are where `replace_all` finds them, even when empty.
+    for &(re, text) in [(r"a*", "baab"), (r"\b", "ab cd"), (r"", "δx"),
+                         (r"x|", "axxb")].iter() {
+        let re = Regex::new(re).unwrap();
+        assert_eq!(re.annotate_markers(text, "[", "]").as_slice(),
+                   re.replace_all(text, "[$0]").as_slice());
+        let got = re.annotate(text, true, |i, s| format!("<{}{}>", i, s));
+        assert_eq!(got.as_slice(), re.replace_all(text, "<0$0>").as_slice());
+    }
+}
+
+// A type that parses the text of its group itself.
+#[deriving(Eq, Show)]
+struct Hex(u32);
+
+impl<D: Decoder<E>, E> Decodable<D, E> for Hex {
+    fn decode(d: &mut D) -> Result<Hex, E> {
+        let s = try!(d.read_str());
+        Ok(Hex(num::from_str_radix(s.as_slice(), 16).unwrap()))
+    }
+}
+
+#[test]
+fn scan_struct() {
+    #[deriving(Decodable, Eq, Show)]
+    struct Scanned {
+        key: ~str,
+        num: i32,
+        ratio: f64,
+        on: bool,
+        flag: char,
+        raw: Vec<u8>,
+        unit: Option<~str>,
+        missing: Option<uint>,
+        color: Hex,
+    }
+    let re = Regex::new(r"(?x)
+        (?P<Key>\w+) = (?P<num>-?\d+) / (?P<ratio>[\d.]+) / (?P<on>\w+)
+        / (?P<flag>.) / (?P<raw>\w*) (?P<unit>px)? \x20\# (?P<color>[0-9a-f]+)
+    ").unwrap();
+    let got: Option<Scanned> =
+        re.scan("- w=-12/0.5/true/é/ab #ff8 -").unwrap();
+    assert_eq!(got, Some(Scanned {
+        key: ~"w",
+        num: -12,
+        ratio: 0.5,
+        on: true,
+        flag: 'é',
+        raw: vec!('a' as u8, 'b' as u8),
+        unit: None,
+        missing: None,
+        color: Hex(0xff8),
+    }));
+    let got: Option<Scanned> = re.scan("none").unwrap();
+    assert_eq!(got, None);
+
+    #[deriving(Decodable, Show)]
+    struct Unit { unit: ~str }
+    let re = Regex::new(r"(?P<n>\d+)(?P<unit>px)?").unwrap();
+    let got: Result<Option<Unit>, ScanError> = re.scan("1");
+    let err = got.unwrap_err();
+    assert_eq!(err.kind, ScanMissingGroup);
+    assert!(err.msg.contains("'unit'"));
+    assert_eq!(re.scan("1px").unwrap().map(|u: Unit| u.unit), Some(~"px"));
+
+    #[deriving(Decodable, Show)]
+    struct Size { size: uint }
+    let got: Result<Option<Size>, ScanError> = re.scan("1");
+    assert_eq!(got.unwrap_err().kind, ScanNoGroup);
+    #[deriving(Decodable, Show)]
+    struct Num { n: u8 }
+    let got: Result<Option<Num>, ScanError> = re.scan("256");
+    let err = got.unwrap_err();
+    assert_eq!(err.kind, ScanInvalidValue);
+    assert!(err.msg.contains("'n' matched '256'"));
+    let got: Result<Option<uint>, ScanError> = re.scan("1");
+    assert_eq!(got.unwrap_err().kind, ScanUnsupported);
+    #[deriving(Decodable, Show)]
+    struct Nested { n: Num }
+    let got: Result<Option<Nested>, ScanError> = re.scan("1");
+    assert_eq!(got.unwrap_err().kind, ScanUnsupported);
+
+    let all: Vec<Num> = re.scan_all("1 22px 3").unwrap();
+    assert_eq!(all.iter().map(|x| x.n).collect::<Vec<u8>>(), vec!(1, 22, 3));
+    let all: Result<Vec<Num>, ScanError> = re.scan_all("1 999");
+    assert_eq!(all.unwrap_err().kind, ScanInvalidValue);
+}
+
+#[test]
+fn stats() {
+    let mut re = Regex::new(r"(ab|cd)+\d").unwrap();
+    assert!(re.stats().is_none());
+    re.enable_stats();
+    assert!(re.is_match("xx abcd1"));
+    assert_eq!(re.find("cd cd"), None);
+    let stats = re.take_stats().unwrap();
+    assert_eq!((stats.searches, stats.bytes, stats.matches), (2, 13, 1));
+    assert!(stats.runs(EngineDfa) >= 2);
+    assert_eq!(stats.runs(EngineNfa), 0);
+    let stats = re.stats().unwrap();
+    assert_eq!((stats.searches, stats.runs(EngineDfa)), (0, 0));
+
+    // Without the DFA (or the backtracker), the NFA looks at every position
+    // that the prefilter reports.
+    re.set_dfa_size_limit(0);
+    re.set_backtrack_limit(0);
+    let clone = re.clone();
+    assert_eq!(clone.find("ab ab cd7"), Some((6, 9)));
+    let stats = re.take_stats().unwrap();
+    assert_eq!((stats.searches, stats.matches), (1, 1));
+    assert_eq!(stats.runs(EngineNfa), 1);
+    assert!(stats.candidates >= 3);
+
+    // With a prefilter hook, the search from each candidate it gives is part
+    // of one search, which only examines the text up to the match.
+    re.set_prefilter(~BytesPrefilter {
+        bytes: "c".as_bytes().to_owned(),
+        end: false,
+        calls: Arc::new(Mutex::new(0u)),
+    });
+    assert_eq!(re.find("cd cd7 xx"), Some((3, 6)));
+    let stats = re.take_stats().unwrap();
+    assert_eq!((stats.searches, stats.bytes, stats.matches), (1, 6, 1));
+    assert_eq!((stats.candidates, stats.runs(EngineNfa)), (2, 2));
+    re.remove_prefilter();
+
+    // A DFA this small gives up, so its states are thrown away.
+    re.set_dfa_size_limit(1);
+    assert!(re.is_match("abab5"));
+    assert!(re.stats().unwrap().dfa_clears >= 1);
+
+    re.disable_stats();
+    assert!(re.is_match("ab1"));
+    assert!(re.stats().is_none());
+    assert!(re.take_stats().is_none());
+}
+
+#[test]
+fn matcher_byte_at_a_time() {
+    // Feeding the text a byte at a time (which cuts its characters apart)
+    // finds what `find` finds in all of it.
+    let res = [r"a+b", r"ab|abcd", r"\bδ\w*", r"x$", r"(?m)^b", r"(?i)É+",
+               r"\Bc", r"", r"\Gz", r"[^a]{2}\B"];
+    let texts = ["xaab abcd", "δ aδb", "aéÉ\nb x", "zcc", ""];
+    for expr in res.iter() {
+        let re = Regex::new(*expr).unwrap();
+        for text in texts.iter() {
+            let mut m = re.matcher().unwrap();
+            for &b in text.as_bytes().iter() {
+                m.feed(&[b]).unwrap();
+            }
+            let want = match re.find(*text) {
+                Some((s, e)) => Matched(s, e),
+                None => CannotMatch,
+            };
+            assert_eq!((*expr, *text, m.close().unwrap()),
+                       (*expr, *text, want));
+        }
+    }
+}
+
+#[test]
+fn matcher() {
+    let re = Regex::new(r"a+b").unwrap();
+    let mut m = re.matcher().unwrap();
+    assert_eq!(m.feed(bytes!("xaa")), Ok(NoMatchYet));
+    assert_eq!(m.feed(bytes!("ab c")), Ok(Matched(1, 5)));
+    // Once the match is known, the rest of the text is ignored.
+    assert_eq!(m.feed(&[0xff]), Ok(Matched(1, 5)));
+    assert_eq!(m.close(), Ok(Matched(1, 5)));
+
+    // A higher priority alternative (or a longer match, for
+    // leftmost-longest matches) replaces a match.
+    let mut m = Regex::new(r"ab|a").unwrap().matcher().unwrap();
+    m.feed(bytes!("xa")).unwrap();
+    assert_eq!(m.feed(bytes!("bcd")), Ok(Matched(1, 3)));
+    let opts = Options { longest: true, ..Options::new() };
+    let re = Regex::with_options("a|ab", opts).unwrap();
+    let mut m = re.matcher().unwrap();
+    m.feed(bytes!("xa")).unwrap();
+    assert_eq!(m.feed(bytes!("bcd")), Ok(Matched(1, 3)));
+
+    let mut m = Regex::new(r"^ab").unwrap().matcher().unwrap();
+    assert_eq!(m.feed(bytes!("a")), Ok(NoMatchYet));
+    assert_eq!(m.feed(bytes!("c")), Ok(NoMatchYet));
+    assert_eq!(m.feed(bytes!("ab")), Ok(CannotMatch));
+    let mut m = Regex::new(r"a$").unwrap().matcher().unwrap();
+    assert_eq!(m.feed(bytes!("aa")), Ok(NoMatchYet));
+    assert_eq!(m.close(), Ok(Matched(1, 2)));
+    assert_eq!(m.status(), Matched(1, 2));
+}
+
+#[test]
+fn matcher_errors() {
+    let re = Regex::new(r"b\w").unwrap();
+    let mut m = re.matcher().unwrap();
+    assert_eq!(m.feed(&[0xff, 'a' as u8]).unwrap_err().kind,
+               MatcherInvalidUtf8);
+    // The piece was ignored.
+    assert_eq!(m.feed(&['a' as u8, 'b' as u8, 0xce]), Ok(NoMatchYet));
+    assert_eq!(m.feed(&[0xb4]), Ok(NoMatchYet));
+    assert_eq!(m.close(), Ok(Matched(1, 4)));
+    let mut m = re.matcher().unwrap();
+    m.feed(&[0xce]).unwrap();
+    assert_eq!(m.close().unwrap_err().kind, MatcherInvalidUtf8);
+    // A byte that can't start a character isn't kept for the next piece.
+    let mut m = Regex::new("b").unwrap().matcher().unwrap();
+    assert_eq!(m.feed(&['a' as u8, 0xff]).unwrap_err().kind,
+               MatcherInvalidUtf8);
+    assert_eq!(m.feed(bytes!("b")), Ok(NoMatchYet));
+    assert_eq!(m.close(), Ok(Matched(0, 1)));
+
+    for re in [r"a(?=b)", r"(a)\1", r"a\Kb", r"\b{g}", r"a\Z"].iter() {
+        let re = Regex::new(*re).unwrap();
+        assert_eq!(re.matcher().unwrap_err().kind, MatcherUnsupported);
+    }
+}
+
+#[test]
+fn program_size() {
+    // `Save`, the expression, `Save` and `Match`.
+    assert_eq!(Regex::new("a(b)c*").unwrap().program_size(), 10);
+    assert_eq!(regex!("a(b)c*").program_size(), 10);
+}
+
+#[test]
+fn memory_estimate() {
+    let res = ["a", r"\w+@\w+", "(foo|bar|baz)+[0-9]{2,5}", r"(\pL+\s*){20}"];
+    let ests: Vec<MemoryEstimate> = res.iter().map(|re| {
+        Regex::new(*re).unwrap().memory_estimate()
+    }).collect();
+    // Larger programs occupy more, and searches with them take more.
+    for (x, y) in ests.iter().zip(ests.iter().skip(1)) {
+        assert!(x.program < y.program);
+        assert!(x.threads < y.threads);
+    }
+    let est = ests.get(3);
+    assert!(est.fixed() >= est.program + est.prefilter);
+    assert!(est.program >= Regex::new(res[3]).unwrap().program_size());
+    // Short texts are searched by the backtracker, whose visited set grows
+    // with the text, and long ones by the NFA simulation.
+    assert!(est.per_search(0) < est.per_search(10));
+    assert_eq!(est.per_search(1 << 20), est.threads);
+    // Only the backtracker runs lookaround, whatever the length of the text,
+    // with a visited set that's bounded.
+    let est = Regex::new(r"(?=a)\w").unwrap().memory_estimate();
+    assert!(est.per_search(1 << 20) > est.per_search(10));
+    assert_eq!(est.per_search(1 << 30), est.per_search(1 << 31));
+
+    let mut re = Regex::new(r"(a|b)*a(a|b){3}").unwrap();
+    re.set_dfa_size_limit(1000);
+    assert_eq!(re.memory_estimate().full_dfa, 0);
+    assert_eq!(re.memory_estimate().lazy_dfa, 16 * 1000);
+    re.compile_dfa(1 << 20).unwrap();
+    let est = re.memory_estimate();
+    assert_eq!(est.full_dfa, re.full_dfa_size());
+    assert!(est.fixed() > est.full_dfa);
+}
+
+#[test]
+fn free_spacing_error_pos() {
+    // Positions are in the expression as written, whitespace and all.
+    for &(bad, pos) in [("(?x)a   \\q", 9u), ("(?x)# [\n\\q", 9)].iter() {
+        let err = Regex::new(bad).unwrap_err();
+        assert_eq!((bad, err.pos), (bad, pos));
+    }
+}
+
+#[test]
+fn error_span() {
+    // Spans are byte ranges, even after multibyte characters, comments and
+    // flag groups.
+    for &(bad, snippet, start) in [
+        ("(?x)a   \\q", "\\q", 8u),
+        ("(?x)# é[\n\\q", "\\q", 10),
+        ("(?i)δ(?s)x\\y", "\\y", 11),
+        ("a(b(c)", "(", 1),
+        ("é(?P<a-b>x)", "a-b", 6),
+        ("(?i--m)", "(?i--", 0),
+        ("x{2,", "{2,", 1),
+        ("é\\N{NOPE}", "NOPE", 5),
+        ("a\\Nx", "\\N", 1),
+    ].iter() {
+        let err = Regex::new(bad).unwrap_err();
+        assert_eq!((bad, err.snippet(), err.start), (bad, snippet, start));
+        assert_eq!(err.expr.as_slice(), bad);
+    }
+    let err = Regex::new("ab(c").unwrap_err();
+    assert_eq!(err.format(), ~"ab(c\n  ^\nUnclosed parenthesis.");
+    let err = Regex::new("(?x)a\n\t  é\\q  # z").unwrap_err();
+    assert_eq!(err.format(),
+               format!("(?x)a\n\t  é\\q  # z\n\t   ^^\n{}", err.msg));
+}
+
+#[test]
+fn template_parse() {
+    assert!(Template::parse("$1 ${name} ${x:-} $$ $").is_ok());
+    for &(bad, pos) in [("${", 0u), ("a ${}", 2), ("${x", 0), ("${x:-y", 0),
+                        ("${x-y}", 0), ("$$ ${x:y}", 3)].iter() {
+        let err = Template::parse(bad).unwrap_err();
+        assert_eq!((bad, err.pos), (bad, pos));
+    }
+}
+
+#[test]
+fn template_expand() {
+    let re = regex!(r"(?P<key>\w+)(=(?P<val>\w*))?");
+    let tmpl = Template::parse("<${key}|${val:-none}|$3$$>").unwrap();
+    let caps = re.captures("k=").unwrap();
+    assert_eq!(tmpl.expand(&caps).as_slice(), "<k|||$>");
+    let caps = re.captures("k").unwrap();
+    assert_eq!(tmpl.expand(&caps).as_slice(), "<k|none||$>");
+    assert_eq!(re.replace_all("a=1 b", &tmpl).as_slice(),
+               "<a|1|1$> <b|none||$>");
+
+    let mut seen = vec!();
+    let got = tmpl.expand_with(&caps, |group, value| {
+        seen.push(group.to_owned());
+        value.to_ascii_upper()
+    });
+    assert_eq!(got.as_slice(), "<K|NONE||$>");
+    assert_eq!(seen, vec!(~"key", ~"val", ~"3"));
+
+    let mut dst = StrBuf::from_str("> ");
+    tmpl.push_expand(&caps, &mut dst);
+    assert_eq!(dst.as_slice(), "> <k|none||$>");
+}
+
+#[test]
+fn byte_regex() {
+    // Every byte is matched on its own, whether or not it's valid UTF8.
+    let re = ByteRegex::new(r"a.b").unwrap();
+    assert_eq!(re.find(&[0x61, 0xFF, 0x62]), Some((0, 3)));
+    assert_eq!(re.find(&[0x61, 0xCE, 0x94, 0x62]), None);
+    let re = ByteRegex::new(r"[\x80-\xFF]+").unwrap();
+    assert_eq!(re.find_all(&[0x61, 0x80, 0xC3, 0x62, 0xFF]),
+               vec!((1, 3), (4, 5)));
+    assert_eq!(ByteRegex::new(r"(a)|b").unwrap().captures_pos(&[0xFF, 0x62]),
+               Some(vec!(Some((1, 2)), None)));
+    assert_eq!(ByteRegex::new(r"\x00+").unwrap()
+                        .replace_all(&[0x61, 0, 0, 0x62, 0], &[0x2C]),
+               vec!(0x61, 0x2C, 0x62, 0x2C));
+
+    // Searches resume at byte positions, whether or not the bytes are ASCII.
+    let re = ByteRegex::new(r"\bb").unwrap();
+    assert_eq!(re.find_at(&[0x62, 0x20, 0x62], 1), Some((2, 3)));
+    assert_eq!(re.find_at(&[0xFF, 0x62, 0x20, 0x62], 1), Some((1, 2)));
+    assert_eq!(re.find_at(&[0xFF, 0x62, 0x20, 0x62], 2), Some((3, 4)));
+    let mut it = re.find_iter(&[0x62, 0xFF, 0x62, 0x62]);
+    assert_eq!(it.next(), Some((0, 1)));
+    assert_eq!(it.next(), Some((2, 3)));
+    assert_eq!(it.next(), None);
+    let re = ByteRegex::new(r"\xFF").unwrap();
+    let texts: &[&[u8]] = &[&[0x61], &[0x62, 0xFF], &[0xFF]];
+    assert_eq!(re.filter(texts), vec!(1, 2));
+    assert!(re.is_match_any(texts));
+    assert!(!re.is_match_any(texts.slice_to(1)));
+
+    // A non-ASCII character in the expression matches its UTF8 encoding.
+    let re = ByteRegex::new(r"é+").unwrap();
+    assert_eq!(re.find("xéé".as_bytes()), Some((1, 5)));
+
+    // Case folding, classes and word boundaries are ASCII only.
+    assert!(ByteRegex::new(r"(?i)\bab\b").unwrap().is_match(&[0xE9, 0x41, 0x62]));
+    assert!(!ByteRegex::new(r"(?i)\xE9").unwrap().is_match(&[0xC9]));
+    assert!(!ByteRegex::new(r"\w").unwrap().is_match(&[0xE9]));
+}
+
+#[test]
+fn byte_regex_errors() {
+    for &re in [r"[é]", r"[a-é]", r"\x{100}", r"\pL", r"(?u)\b"].iter() {
+        assert!(ByteRegex::new(re).is_err(), "{}", re);
+    }
+    assert!(ByteRegex::with_unicode(r"\B").is_err());
+    assert!(ByteRegex::with_unicode(r"\b{g}").is_err());
+    assert!(ByteRegex::with_unicode(r"\X").is_err());
+    assert!(ByteRegex::new(r"(?u)[é]\x{100}\pL(?-u:\b)").is_ok());
+    // These are fine in a regex that matches text, even with Unicode off.
+    assert!(Regex::new(r"(?-u)[é]\x{100}\pL").is_ok());
+}
+
+#[test]
+fn byte_regex_line_break() {
+    let re = ByteRegex::new(r"a\R").unwrap();
+    assert_eq!(re.find_all(&[0x61, 0x0D, 0x0A, 0x61, 0x0D, 0x61, 0x85]),
+               vec!((0, 3), (3, 5)));
+    let re = ByteRegex::with_unicode(r"\R").unwrap();
+    assert_eq!(re.find(&[0xFF, 0xC2, 0x85]), Some((1, 3)));
+    assert_eq!(re.find(&[0x0D, 0x0A]), Some((0, 2)));
+}
+
+#[test]
+fn byte_regex_unicode() {
+    // With Unicode on, bytes that aren't valid UTF8 only match where it's
+    // turned off.
+    let re = ByteRegex::with_unicode(r".").unwrap();
+    assert_eq!(re.find(&[0xFF, 0xCE, 0xCE, 0x94]), Some((2, 4)));
+    assert!(!re.is_match(&[0xFF, 0x80, 0xED, 0xA0, 0x80]));
+    let re = ByteRegex::with_unicode(r"[^a]").unwrap();
+    assert_eq!(re.find(&[0xFF, 0x62]), Some((1, 2)));
+    let re = ByteRegex::with_unicode(r"\xFF").unwrap();
+    assert_eq!(re.find("ÿ".as_bytes()), Some((0, 2)));
+    assert!(!re.is_match(&[0xFF]));
+    assert!(ByteRegex::with_unicode(r"(?-u:.)").unwrap().is_match(&[0xFF]));
+    assert!(ByteRegex::with_unicode(r"^\pL$").unwrap().is_match("é".as_bytes()));
+    assert!(ByteRegex::with_unicode(r"^\w(?-u:\b)a").unwrap().is_match("éa".as_bytes()));
+    let re = ByteRegex::with_unicode(r"(?i)δ+").unwrap();
+    assert_eq!(re.find("xΔδΔ".as_bytes()), Some((1, 7)));
+    let re = ByteRegex::with_unicode(r"[^\x00-\x{10FFFF}]").unwrap();
+    assert!(!re.is_match("abc".as_bytes()));
+}
+
+#[test]
+fn byte_regex_empty_matches() {
+    // Empty matches advance one byte past every byte that isn't valid UTF8,
+    // and past whole characters otherwise, with Unicode on or off.
+    let bytes = &[0x61, 0xFF, 0xCE, 0x94, 0xCE];
+    let re = ByteRegex::new(r"").unwrap();
+    assert_eq!(re.find_all(bytes), vec!((0, 0), (1, 1), (2, 2), (4, 4), (5, 5)));
+    let re = ByteRegex::with_unicode(r"").unwrap();
+    assert_eq!(re.find_all(bytes), vec!((0, 0), (1, 1), (2, 2), (4, 4), (5, 5)));
+    let re = ByteRegex::with_unicode(r"\w*").unwrap();
+    assert_eq!(re.find_all(bytes), vec!((0, 1), (2, 4), (5, 5)));
+    let re = ByteRegex::new(r"x*").unwrap();
+    assert_eq!(re.replace_all(bytes, &[0x2D]),
+               vec!(0x2D, 0x61, 0x2D, 0xFF, 0x2D, 0xCE, 0x94, 0x2D, 0xCE,
+                    0x2D));
+    // A non-empty match may still end in the middle of a character, but no
+    // empty match follows it there.
+    let re = ByteRegex::new(r"\xCE|x*").unwrap();
+    assert_eq!(re.find_all(bytes), vec!((0, 0), (1, 1), (2, 3), (4, 5)));
+}
+
+#[test]
+fn empty_matches_around_invalid_utf8() {
+    // An empty match after a byte that isn't valid UTF8 is found once,
+    // whether or not the match before that byte was empty.
+    let tests: Vec<(Vec<u8>, Vec<(uint, uint)>)> = vec!(
+        (vec!(0xFF, 0x61, 0xFF, 0xFF, 0x61, 0x61, 0xFF),
+         vec!((0, 0), (1, 2), (3, 3), (4, 6), (7, 7))),
+        (vec!(0xFF, 0xFF), vec!((0, 0), (1, 1), (2, 2))),
+        (vec!(0xCE, 0xFF), vec!((0, 0), (1, 1), (2, 2))),
+        (vec!(0xCE, 0x94, 0xFF, 0x61), vec!((0, 0), (2, 2), (3, 4))),
+        (vec!(0x61, 0xE2, 0x82), vec!((0, 1), (2, 2), (3, 3))),
+    );
+    for &(ref bytes, ref want) in tests.iter() {
+        for re in [ByteRegex::new(r"a*").unwrap(),
+                   ByteRegex::with_unicode(r"a*").unwrap()].iter() {
+            assert_eq!(re.find_all(bytes.as_slice()), *want);
+        }
+    }
+    let re = ByteRegex::new(r"a*").unwrap();
+    assert_eq!(re.replace_all(&[0xFF, 0x61, 0xFF, 0xFF, 0x61, 0x61, 0xFF],
+                              &[0x2D]),
+               vec!(0x2D, 0xFF, 0x2D, 0xFF, 0x2D, 0xFF, 0x2D, 0xFF, 0x2D));
+}
+
+#[test]
+fn empty_match_advance() {
+    // Every way of finding successive matches agrees on where the empty
+    // matches are, and none of them is in the middle of a character.
+    let pats = ["", "x*", "a*", "a*?", "(?:|a)", "é*", r"(?-u:\b)",
+                r"a|(?-u:\B)"];
+    let texts = ["", "a", "aé", "éa", "δaδδ", "aaéa", "💩x"];
+    for &pat in pats.iter() {
+        let re = Regex::new(pat).unwrap();
+        let bres = [ByteRegex::new(pat).unwrap(),
+                    ByteRegex::with_unicode(pat).unwrap()];
+        for &text in texts.iter() {
+            let found: Vec<(uint, uint)> = re.find_iter(text).collect();
+            for &(s, e) in found.iter() {
+                assert!(text.is_char_boundary(s) && text.is_char_boundary(e),
+                        "/{}/ found ({}, {}) in {}", pat, s, e, text);
+            }
+            let caps: Vec<(uint, uint)> =
+                re.captures_iter(text).map(|c| c.pos(0).unwrap()).collect();
+            assert_eq!(caps, found);
+            assert_eq!(re.find_all_cancel(text, &Cancel::new()).unwrap(),
+                       found);
+
+            let (mut want, mut pieces, mut last) = (StrBuf::new(), vec!(), 0);
+            for &(s, e) in found.iter() {
+                want.push_str(text.slice(last, s));
+                want.push_char('-');
+                pieces.push(text.slice(last, s));
+                last = e;
+            }
+            want.push_str(text.slice_from(last));
+            if last < text.len() {
+                pieces.push(text.slice_from(last));
+            }
+            let want = want.as_slice();
+            assert_eq!(re.replace_all(text, NoExpand("-")).as_slice(), want);
+            assert_eq!(re.replace_all(text, "-").as_slice(), want);
+            let got = re.replace_all(text, |_: &Captures| ~"-");
+            assert_eq!(got.as_slice(), want);
+            let got = re.replace_all_cancel(text, "-", &Cancel::new());
+            assert_eq!(got.unwrap().as_slice(), want);
+            assert_eq!(re.split(text).collect::<Vec<&str>>(), pieces);
+
+            let bytes = text.as_bytes();
+            let pieces: Vec<&[u8]> = pieces.iter().map(|p| p.as_bytes())
+                                           .collect();
+            for bre in bres.iter() {
+                assert_eq!(bre.find_all(bytes), found);
+                assert_eq!(bre.replace_all(bytes, "-".as_bytes()).as_slice(),
+                           want.as_bytes());
+                assert_eq!(bre.split(bytes), pieces);
+            }
+        }
+
+        // With invalid UTF8, empty matches are still never found in the
+        // middle of the character at 2, and the other methods agree with
+        // `find_all`.
+        let bytes = &[0x61, 0xFF, 0xCE, 0x94, 0xE2, 0x82, 0x61];
+        for bre in bres.iter() {
+            let found = bre.find_all(bytes);
+            let (mut want, mut pieces, mut last) = (vec!(), vec!(), 0);
+            for &(s, e) in found.iter() {
+                assert!(s != e || s != 3, "/{}/ found ({}, {})", pat, s, e);
+                want.push_all(bytes.slice(last, s));
+                want.push(0x2D);
+                pieces.push(bytes.slice(last, s));
+                last = e;
+            }
+            want.push_all(bytes.slice_from(last));
+            if last < bytes.len() {
+                pieces.push(bytes.slice_from(last));
+            }
+            assert_eq!(bre.replace_all(bytes, &[0x2D]), want);
+            assert_eq!(bre.split(bytes), pieces);
+        }
+    }
+}
+
+#[test]
+fn duplicate_names_in_branches() {
+    let re = regex!(r"(?P<val>\d+)px|(?P<val>\d+)%");
+    let caps = re.captures("w: 50%").unwrap();
+    assert_eq!((caps.name_index("val"), caps.name("val")), (Some(2), "50"));
+    let caps = re.captures("w: 7px").unwrap();
+    assert_eq!((caps.name_index("val"), caps.name("val")), (Some(1), "7"));
+    assert_eq!(caps.name_index("nope"), None);
+    assert_eq!(re.replace_all("1px 2%", "<$val>"), StrBuf::from_str("<1> <2>"));
+    assert_eq!(*re.captures_map("2%").unwrap().get(&~"val"), "2");
+    assert_eq!(re.capture_names().to_owned(),
+               ~[None, Some(~"val"), Some(~"val")]);
+
+    let mut cur = re.captures_cursor("3% 4px");
+    assert!(cur.advance());
+    assert_eq!(cur.name("val"), "3");
+    assert!(cur.advance());
+    assert_eq!(cur.name("val"), "4");
+
+    let re = regex!(r"(?:(?P<a>x)|(?P<a>y)|z)(?P<b>.)");
+    let caps = re.captures("y!").unwrap();
+    assert_eq!((caps.name("a"), caps.name("b")), ("y", "!"));
+    let caps = re.captures("z!").unwrap();
+    assert_eq!(caps.name_index("a"), None);
+}
+
+#[test]
+fn captures_cursor_agrees_with_captures_iter() {
+    let re = regex!(r"(?P<key>\w*)=(\d)?");
+    let text = "a=1 =2 bc= d=4";
+    let expected: Vec<Vec<Option<(uint, uint)>>> =
+        re.captures_iter(text).map(|c| c.iter_pos().collect()).collect();
+    let mut got = vec!();
+    let mut cur = re.captures_cursor(text);
+    while cur.advance() {
+        got.push(range(0, cur.len()).map(|i| cur.pos(i)).collect());
+    }
+    assert_eq!(got, expected);
+    assert!(!cur.advance());
+    assert_eq!(cur.pos(0), None);
+    assert_eq!(cur.len(), 0);
+}
+
+#[test]
+fn captures_cursor_names() {
+    let re = regex!(r"(?P<key>\w+)=(?P<val>\w+)?");
+    let mut cur = re.captures_cursor("a=1 b= c=3");
+    assert_eq!(cur.name("key"), "");
+    assert!(cur.advance());
+    assert_eq!((cur.name("key"), cur.name("val"), cur.at(0)), ("a", "1", "a=1"));
+    assert!(cur.advance());
+    assert_eq!((cur.name("key"), cur.name("val")), ("b", ""));
+    assert_eq!(cur.name("nope"), "");
+    // The cursor may be abandoned halfway through.
+}
+
+// Searching from an offset evaluates assertions against the whole text.
+#[test]
+fn find_at_assertions() {
+    let re = regex!(r"\bfoo");
+    assert_eq!(re.find_at("xfoo foo", 1), Some((5, 8)));
+    assert_eq!(re.find_at("xfoo foo", 5), Some((5, 8)));
+    assert_eq!(re.find_at("xfoo foo", 6), None);
+    let re = regex!(r"^a");
+    assert_eq!(re.find_at("aa", 1), None);
+    let re = regex!(r"(?m)^a");
+    assert_eq!(re.find_at("a\na", 1), Some((2, 3)));
+    let re = regex!(r"\Bb");
+    assert_eq!(re.find_at("ab", 1), Some((1, 2)));
+    assert_eq!(regex!("a*").find_at("baa", 3), Some((3, 3)));
+}
+
+#[test]
+fn captures_at_assertions() {
+    let re = regex!(r"\b(\w)(\w)");
+    let caps = re.captures_at("abc de", 1).unwrap();
+    assert_eq!(caps.pos(0), Some((4, 6)));
+    assert_eq!(caps.at(2), "e");
+    assert!(re.captures_at("abc de", 5).is_none());
+}
+
+#[test]
+#[should_fail]
+fn find_at_not_char_boundary() {
+    regex!("a").find_at("δa", 1);
+}
+
+#[test]
+fn find_anchored() {
+    let re = regex!(r"[a-z]+|[0-9]+");
+    assert_eq!(re.find_anchored("ab 12", 0), Some((0, 2)));
+    assert_eq!(re.find_anchored("ab 12", 1), Some((1, 2)));
+    assert_eq!(re.find_anchored("ab 12", 2), None);
+    assert_eq!(re.find_anchored("ab 12", 3), Some((3, 5)));
+    assert_eq!(re.find_anchored("ab 12", 5), None);
+    // The prefix literal of an expression must not skip ahead.
+    assert_eq!(regex!(r"abc").find_anchored("xabc", 0), None);
+    assert_eq!(regex!(r"abc").find_anchored("xabc", 1), Some((1, 4)));
+}
+
+#[test]
+fn find_anchored_assertions() {
+    // Anchoring doesn't make `^` match at the search position.
+    assert_eq!(regex!(r"^a").find_anchored("aa", 1), None);
+    assert_eq!(regex!(r"(?m)^a").find_anchored("\na", 1), Some((1, 2)));
+    assert_eq!(regex!(r"\bb").find_anchored("ab b", 1), None);
+    assert_eq!(regex!(r"\bb").find_anchored("ab b", 3), Some((3, 4)));
+    assert_eq!(regex!(r"x*").find_anchored("ab", 1), Some((1, 1)));
+}
+
+#[test]
+fn find_prefix() {
+    // The longest match wins, even over the one `find_anchored` finds.
+    let re = regex!(r"a|ab|abc");
+    assert_eq!(re.find_anchored("xabcd", 1), Some((1, 2)));
+    assert_eq!(re.find_prefix("xabcd", 1), Some(4));
+    assert_eq!(re.find_prefix("xabcd", 0), None);
+    let re = Regex::new(r"\bb+|^x*").unwrap();
+    assert_eq!(re.find_prefix("ab bb", 1), None);
+    assert_eq!(re.find_prefix("ab bb", 3), Some(5));
+    assert_eq!(re.find_prefix("xx", 0), Some(2));
+    assert_eq!(re.find_prefix("xx", 1), None);
+}
+
+#[test]
+fn set_find_prefix() {
+    let set = RegexSet::new(&["a|ab", "ab|abc", "^b", r"\bc", "x*"]).unwrap();
+    let ends = |at: uint| {
+        set.find_prefix("abc", at).map(|m| (m.pattern, m.end))
+    };
+    assert_eq!(ends(0), Some((1, 3)));
+    // `^` and `\b` see all of the text, so only `x*` matches after its start.
+    assert_eq!(ends(1), Some((4, 1)));
+    assert_eq!(ends(2), Some((4, 2)));
+    assert_eq!(ends(3), Some((4, 3)));
+    // The expression that comes first wins a tie.
+    let set = RegexSet::new(&["ab", "a|ab"]).unwrap();
+    assert_eq!(set.find_prefix("ab", 0).map(|m| m.pattern), Some(0));
+    assert_eq!(set.find_prefix("ab", 1), None);
+    assert!(RegexSet::new(&[]).unwrap().find_prefix("ab", 0).is_none());
+}
+
+#[test]
+fn set_find_prefix_lexer() {
+    // The rules of a lexer, with each keyword before the rule that matches
+    // it as an identifier.
+    let rules = [("let", "let"), ("ident", r"[a-z_]\w*"), ("int", r"\d+"),
+                 ("op", r"==|[=+]"), ("ws", r"\s+")];
+    let res: Vec<&str> = rules.iter().map(|&(_, re)| re).collect();
+    let set = RegexSet::new(res.as_slice()).unwrap();
+    let (text, mut at, mut tokens) = ("let letter = x+1==2", 0, vec!());
+    while at < text.len() {
+        let m = set.find_prefix(text, at).expect("no token");
+        let (kind, _) = rules[m.pattern];
+        if kind != "ws" {
+            tokens.push((kind, text.slice(m.start, m.end)));
+        }
+        at = m.end;
+    }
+    assert_eq!(tokens, vec!(("let", "let"), ("ident", "letter"), ("op", "="),
+                            ("ident", "x"), ("op", "+"), ("int", "1"),
+                            ("op", "=="), ("int", "2")));
+}
+
+#[test]
+fn find_branch() {
+    // Three literals or more in a row are merged into a trie when compiled,
+    // but they keep their numbers.
+    let re = regex!(r"foobar|foo|bar|fo|[0-9]+");
+    let found: Vec<((uint, uint), Option<uint>)> =
+        re.find_branch_iter("foobar foo bar fo 42 f").collect();
+    assert_eq!(found, vec!(((0, 6), Some(0)), ((7, 10), Some(1)),
+                           ((11, 14), Some(2)), ((15, 17), Some(3)),
+                           ((18, 20), Some(4))));
+    assert_eq!(re.find_branch("xyz"), None);
+    // Alternations in groups aren't top-level.
+    assert_eq!(regex!(r"(?:a|b)").find_branch("b"), Some(((0, 1), None)));
+    assert_eq!(regex!(r"x(a|b)|(c|d)y").find_branch("dy"),
+               Some(((0, 2), Some(1))));
+    // Assertions see the text before the match.
+    let re = regex!(r"\bfoo|foo");
+    assert_eq!(re.find_branch("xfoo"), Some(((1, 4), Some(1))));
+    assert_eq!(re.find_branch("x foo"), Some(((2, 5), Some(0))));
+}
+
+#[test]
+fn find_branch_longest() {
+    let opts = Options { longest: true, ..Options::new() };
+    let re = Regex::with_options(r"foo|foobar|(?:foo)+", opts).unwrap();
+    assert_eq!(re.find_branch("foobar"), Some(((0, 6), Some(1))));
+    // Of matches that are as long, the first alternate's wins.
+    assert_eq!(re.find_branch("foo"), Some(((0, 3), Some(0))));
+    assert_eq!(re.find_branch("foofoo"), Some(((0, 6), Some(2))));
+}
+
+#[test]
+fn find_branch_backtrack_only() {
+    // Alternates with lookaround are run by the backtracker.
+    let re = Regex::new(r"(?=ab)a|a(?=c)|b+").unwrap();
+    let found: Vec<((uint, uint), Option<uint>)> =
+        re.find_branch_iter("ab ac bb").collect();
+    assert_eq!(found, vec!(((0, 1), Some(0)), ((1, 2), Some(2)),
+                           ((3, 4), Some(1)), ((6, 8), Some(2))));
+    // With backreferences or `\K`, the alternate isn't known.
+    let re = Regex::new(r"(b)\1|(c)\2").unwrap();
+    assert_eq!(re.find_branch("cc"), Some(((0, 2), None)));
+    let re = Regex::new(r"a\Kb|c").unwrap();
+    assert_eq!(re.find_branch("ab"), Some(((1, 2), None)));
+}
+
+#[test]
+fn captures_anchored() {
+    let re = regex!(r"(\w+)=(\w+)?");
+    let caps = re.captures_anchored("; a=1 b=", 2).unwrap();
+    assert_eq!((caps.at(1), caps.at(2)), ("a", "1"));
+    let caps = re.captures_anchored("; a=1 b=", 6).unwrap();
+    assert_eq!((caps.pos(0), caps.pos(2)), (Some((6, 8)), None));
+    assert!(re.captures_anchored("; a=1 b=", 0).is_none());
+}
+
+#[test]
+fn find_anchored_lexer() {
+    let rules = [regex!(r"[a-z]+"), regex!(r"[0-9]+"), regex!(r"\s+"),
+                 regex!(r"[=+]")];
+    let (text, mut at, mut kinds) = ("x = y+12", 0, vec!());
+    while at < text.len() {
+        let (kind, end) = rules.iter().enumerate().filter_map(|(i, re)| {
+            re.find_anchored(text, at).map(|(_, e)| (i, e))
+        }).next().unwrap();
+        kinds.push(kind);
+        at = end;
+    }
+    assert_eq!(kinds, vec!(0, 2, 3, 2, 0, 3, 1));
+}
+
+#[test]
+fn search_start_iter() {
+    let re = regex!(r"\G\w");
+    let got: Vec<(uint, uint)> = re.find_iter("ab c").collect();
+    assert_eq!(got, vec!((0, 1), (1, 2)));
+    let got: Vec<(uint, uint)> = regex!(r"\G").find_iter("ab").collect();
+    assert_eq!(got, vec!((0, 0), (1, 1), (2, 2)));
+}
+
+#[test]
+fn search_start_at() {
+    assert_eq!(regex!(r"\Ga").find_at("ba", 1), Some((1, 2)));
+    assert_eq!(regex!(r"^a").find_at("ba", 1), None);
+    assert_eq!(regex!(r"(?m)\Ga").find_at("\na", 0), None);
+    assert_eq!(regex!(r"x|\Gb").find("ab"), None);
+    assert_eq!(regex!(r"x|\Gb").find_at("ab", 1), Some((1, 2)));
+    let caps = regex!(r"\G(\w)").captures_at("ab", 1).unwrap();
+    assert_eq!(caps.at(1), "b");
+}
+
+#[test]
+fn start_anchor_at_offset() {
+    let re = regex!(r"^\w+");
+    assert_eq!(re.find_at_with("key=value", 4, StartAnchorAtBeginning), None);
+    assert_eq!(re.find_at_with("key=value", 4, StartAnchorAtOffset),
+               Some((4, 9)));
+    assert_eq!(re.find_at_with("key=value", 0, StartAnchorAtOffset),
+               Some((0, 3)));
+    let re = regex!(r"\Ab");
+    assert_eq!(re.find_at_with("ab", 1, StartAnchorAtBeginning), None);
+    assert_eq!(re.find_at_with("ab", 1, StartAnchorAtOffset), Some((1, 2)));
+}
+
+#[test]
+fn start_anchor_at_offset_word_boundary() {
+    // `\b` and `\B` look at the character before the offset either way.
+    let (b, nb) = (regex!(r"^\bx"), regex!(r"^\Bx"));
+    assert_eq!(b.find_at_with("ax", 1, StartAnchorAtOffset), None);
+    assert_eq!(nb.find_at_with("ax", 1, StartAnchorAtOffset), Some((1, 2)));
+    assert_eq!(b.find_at_with(" x", 1, StartAnchorAtOffset), Some((1, 2)));
+    assert_eq!(nb.find_at_with(" x", 1, StartAnchorAtBeginning), None);
+}
+
+#[test]
+fn start_anchor_at_offset_multi_line() {
+    let re = regex!(r"(?m)^\w");
+    let text = "ab\ncd";
+    assert_eq!(re.find_at_with(text, 1, StartAnchorAtBeginning), Some((3, 4)));
+    assert_eq!(re.find_at_with(text, 1, StartAnchorAtOffset), Some((1, 2)));
+    assert_eq!(re.find_at_with(text, 3, StartAnchorAtOffset), Some((3, 4)));
+    let re = regex!(r"(?m)^d");
+    assert_eq!(re.find_at_with(text, 4, StartAnchorAtBeginning), None);
+    assert_eq!(re.find_at_with(text, 4, StartAnchorAtOffset), Some((4, 5)));
+    let caps = regex!(r"(?m)^(\w)(\w)?$")
+               .captures_at_with(text, 1, StartAnchorAtOffset).unwrap();
+    assert_eq!((caps.at(1), caps.pos(2)), ("b", None));
+}
+
+#[test]
+fn search_start_stream() {
+    let re = regex!(r"\Ga");
+    let mut src = ::std::io::MemReader::new(Vec::from_slice(bytes!("aa")));
+    assert!(re.is_match_reader(&mut src, 0).is_err());
+}
+
+#[test]
+fn segment_iter() {
+    let re = regex!(r"(?s).+?\b{g}");
+    let got: Vec<(uint, uint)> = re.find_iter("e\u0301🇫🇷").collect();
+    assert_eq!(got, vec!((0, 3), (3, 11)));
+    let re = regex!(r"\b{wb}.+?\b{wb}");
+    let got: Vec<(uint, uint)> = re.find_iter("can't 3.14").collect();
+    assert_eq!(got, vec!((0, 5), (5, 6), (6, 10)));
+    let got: Vec<(uint, uint)> = regex!(r"\b{wb}").find_iter("a b").collect();
+    assert_eq!(got, vec!((0, 0), (1, 1), (2, 2), (3, 3)));
+}
+
+#[test]
+fn grapheme_iter() {
+    // Every grapheme cluster is found, as a grapheme iterator would.
+    let text = "e\u0301👩\u200D👩\u200D👧\r\n🇫🇷🇩🇪क्षa\u1100\u1161  ";
+    let got: Vec<&str> = regex!(r"\X").find_iter(text)
+                                        .map(|(s, e)| text.slice(s, e))
+                                        .collect();
+    assert_eq!(got, vec!("e\u0301", "👩\u200D👩\u200D👧", "\r\n", "🇫🇷",
+                         "🇩🇪", "क्ष", "a", "\u1100\u1161", " ", " "));
+}
+
+#[test]
+fn segment_stream() {
+    let re = regex!(r"\b{wb}a");
+    let mut src = ::std::io::MemReader::new(Vec::from_slice(bytes!("aa")));
+    assert!(re.is_match_reader(&mut src, 0).is_err());
+}
+
+// Lookaround isn't supported by `regex!`, so the regexes of these tests are
+// compiled with `Regex::new`.
+#[test]
+fn lookahead_find() {
+    let tests = [
+        (r"foo(?=bar)", "foobaz foobar", Some((7, 10))),
+        (r"foo(?!bar)", "foobar foobaz", Some((7, 10))),
+        (r"(?=\d{3})\d", "12 345", Some((3, 4))),
+        (r"\w+(?=\.$)", "x.y.", Some((2, 3))),
+        (r"(?i)foo(?=BAR)", "FOObar", Some((0, 3))),
+        (r"(?=a)", "ba", Some((1, 1))),
+        (r"^(?!.*x).*$", "abc", Some((0, 3))),
+        (r"^(?!.*x).*$", "axc", None),
+        (r"(?=a)b", "ab", None),
+        (r"a(?=b(?=c))", "abd abc", Some((4, 5))),
+        (r"a(?=b(?!c))", "abc abd", Some((4, 5))),
+        (r"a(?!b(?=c))", "abc abd", Some((4, 5))),
+        (r"(?=(?!a)\w)\w+", "ab cd", Some((1, 2))),
+    ];
+    for &(re, text, expected) in tests.iter() {
+        let got = Regex::new(re).unwrap().find(text);
+        assert_eq!((re, text, got), (re, text, expected));
+    }
+}
+
+#[test]
+fn lookahead_captures() {
+    let re = Regex::new(r"(\w+)(?=(\d+))").unwrap();
+    let caps = re.captures("abc123").unwrap();
+    assert_eq!(caps.iter_pos().collect::<Vec<Option<(uint, uint)>>>(),
+               vec!(Some((0, 5)), Some((0, 5)), Some((5, 6))));
+
+    // Nested groups are set by nested lookaheads.
+    let re = Regex::new(r"(?=(a(?=(b))))").unwrap();
+    let caps = re.captures("xab").unwrap();
+    assert_eq!(caps.iter_pos().collect::<Vec<Option<(uint, uint)>>>(),
+               vec!(Some((1, 1)), Some((1, 2)), Some((2, 3))));
+
+    // Groups in a negative lookahead are never set.
+    let re = Regex::new(r"a(?!(b))").unwrap();
+    assert_eq!(re.captures("abac").unwrap().pos(1), None);
+
+    // Groups set by a lookahead are unset when the path after it fails.
+    let re = Regex::new(r"(?=(\w))\w\d|(\w)").unwrap();
+    let caps = re.captures("ab").unwrap();
+    assert_eq!(caps.iter_pos().collect::<Vec<Option<(uint, uint)>>>(),
+               vec!(Some((0, 1)), None, Some((0, 1))));
+}
+
+#[test]
+fn lookahead_iter() {
+    let re = Regex::new(r"\d(?=(\d{3})+\b)").unwrap();
+    assert_eq!(re.replace_all("1234567", "$0,").as_slice(), "1,234,567");
+    let got: Vec<(uint, uint)> = re.find_iter("12345 1234").collect();
+    assert_eq!(got, vec!((1, 2), (6, 7)));
+
+    let re = Regex::new(r"(?=\d)").unwrap();
+    let got: Vec<(uint, uint)> = re.find_iter("a12").collect();
+    assert_eq!(got, vec!((1, 1), (2, 2)));
+    let got: Vec<&str> = Regex::new(r",(?! )").unwrap()
+                                             .split("a,b, c,d").collect();
+    assert_eq!(got, vec!("a", "b, c", "d"));
+}
+
+#[test]
+fn lookahead_engines() {
+    let re = Regex::new(r"a(?=b)").unwrap();
+    assert!(re.has_lookaround());
+    assert!(!Regex::new(r"a(?:b)").unwrap().has_lookaround());
+
+    // The backtracker is used however long the text is.
+    let mut re = Regex::new(r"foo(?=bar)").unwrap();
+    re.set_backtrack_limit(0);
+    let text = "x".repeat(100000) + "foobaz foobar";
+    assert_eq!(re.find(text.as_slice()), Some((100007, 100010)));
+    assert!(!re.is_full_match("foo"));
+
+    // Only the text that a search reaches takes memory, and a search that
+    // reaches too far fails.
+    let re = Regex::new(r"(?<=a)b").unwrap();
+    let text = "ab".repeat(50000);
+    assert_eq!(re.find_iter(text.as_slice()).count(), 50000);
+    let re = Regex::new(r"a(?=\w*\d)").unwrap();
+    let text = "a".repeat(1 << 21);
+    assert!(re.try_find(text.as_slice()).is_err());
+    assert_eq!(re.find(text.as_slice()), None);
+    assert_eq!(re.find((text + "1").as_slice()), None);
+
+    let opts = Options { longest: true, ..Options::new() };
+    let re = Regex::with_options(r"a(?=b)|ab(?=c)", opts).unwrap();
+    assert_eq!(re.find("abc"), Some((0, 2)));
+    assert_eq!(Regex::new(r"a(?=b)|ab(?=c)").unwrap().find("abc"),
+               Some((0, 1)));
+
+    let re = ByteRegex::new(r"a(?=\xFF)").unwrap();
+    assert_eq!(re.find(&[0x61, 0x61, 0xFF]), Some((1, 2)));
+}
+
+#[test]
+fn lookbehind_find() {
+    let tests = [
+        (r"(?<=\$)\d+", "a1 $25", Some((4, 6))),
+        (r"(?<!-)\b\d+", "-1 2", Some((3, 4))),
+        (r"(?<=a)", "a", Some((1, 1))),
+        (r"(?<=a)b", "b", None),
+        (r"(?<!a)b", "b", Some((0, 1))),
+        (r"(?<=^)a", "ab", Some((0, 1))),
+        (r"(?<=é)x", "éx", Some((2, 3))),
+        (r"(?<=a{1,3})b", "aab", Some((2, 3))),
+        (r"(?<=ab|c)d", "abd", Some((2, 3))),
+        (r"(?i)(?<=A)b", "aB", Some((1, 2))),
+        (r"(?<=a(?<=ba))c", "xac bac", Some((6, 7))),
+        (r"(?<=a(?=b))b", "ab", Some((1, 2))),
+        (r"(?<=\d\.?)\d", "a.1.2", Some((4, 5))),
+    ];
+    for &(re, text, expected) in tests.iter() {
+        let got = Regex::new(re).unwrap().find(text);
+        assert_eq!((re, text, got), (re, text, expected));
+    }
+}
+
+#[test]
+fn lookbehind_captures() {
+    let re = Regex::new(r"(?<=(\w)(\d))x").unwrap();
+    let caps = re.captures("a1x").unwrap();
+    assert_eq!(caps.iter_pos().collect::<Vec<Option<(uint, uint)>>>(),
+               vec!(Some((2, 3)), Some((0, 1)), Some((1, 2))));
+    let re = Regex::new(r"(?<!(a))b").unwrap();
+    assert_eq!(re.captures("ab cb").unwrap().pos(1), None);
+}
+
+#[test]
+fn lookbehind_iter() {
+    let re = Regex::new(r"(?<=\d)(?=(\d{3})+\b)").unwrap();
+    assert_eq!(re.replace_all("1234567", ",").as_slice(), "1,234,567");
+    // The text of a match is seen by the lookbehind of the next one.
+    let re = Regex::new(r"(?<=a)a").unwrap();
+    assert_eq!(re.replace_all("aaaa", "b").as_slice(), "abbb");
+    assert!(re.has_lookaround());
+}
+
+#[test]
+fn lookbehind_offset() {
+    let re = Regex::new(r"(?<=a)b").unwrap();
+    assert_eq!(re.find_at("ab", 1), Some((1, 2)));
+    assert_eq!(re.find_at_with("ab", 1, StartAnchorAtBeginning), Some((1, 2)));
+    assert_eq!(re.find_at_with("ab", 1, StartAnchorAtOffset), None);
+    assert_eq!(re.find_at_with("aab", 1, StartAnchorAtOffset), Some((2, 3)));
+    let re = Regex::new(r"(?<!a)b").unwrap();
+    assert_eq!(re.find_at_with("ab", 1, StartAnchorAtOffset), Some((1, 2)));
+}
+
+#[test]
+fn lookbehind_unbounded() {
+    let err = Regex::new(r"x(?<=a|b+)").unwrap_err();
+    assert_eq!(err.pos, 1);
+    assert!(err.msg.contains("(?<=a|b+)"));
+}
+
+#[test]
+fn backref_find() {
+    let tests = [
+        (r"(\w+)\s+\1", "the cat cat sat", Some((4, 11))),
+        (r"(a)\1", "aA", None),
+        (r"(?i)(a)\1", "aA", Some((0, 2))),
+        (r"(?i)(é)\1", "éÉ", Some((0, 4))),
+        (r"(?i-u)(k)\1", "kK", Some((0, 2))),
+        (r"(a)?b\1", "b", None),
+        (r"(a)?b\1", "aba", Some((0, 3))),
+        (r"(a)|b\1", "b", None),
+        (r"(a*)b\1$", "aaba", Some((1, 4))),
+        (r"(a)(b)\2\1", "abba", Some((0, 4))),
+        (r"(a)\10", "aa0", None),
+        (r"(a)\12", "a\n", Some((0, 2))),
+        (r"(a)(?=\1)", "aa", Some((0, 1))),
+        (r"(?P<x>a|b)\k<x>", "abb", Some((1, 3))),
+        (r"(?:(?P<x>a)|(?P<x>b))\k<x>", "abb", Some((1, 3))),
+    ];
+    for &(re, text, expected) in tests.iter() {
+        let got = Regex::new(re).unwrap().find(text);
+        assert_eq!((re, text, got), (re, text, expected));
+    }
+}
+
+#[test]
+fn backref_captures() {
+    let re = Regex::new(r"<(\w+)>.*?</\1>").unwrap();
+    let text = "<a><b>x</b></a>";
+    assert_eq!(re.find(text), Some((0, 15)));
+    assert_eq!(re.captures(text).unwrap().at(1), "a");
+    let re = Regex::new(r"(\d)\1").unwrap();
+    let got: Vec<(uint, uint)> = re.find_iter("1122 3").collect();
+    assert_eq!(got, vec!((0, 2), (2, 4)));
+    assert_eq!(re.replace_all("a11b", "<$1>").as_slice(), "a<1>b");
+}
+
+#[test]
+fn backref_engines() {
+    let mut re = Regex::new(r"(a)\1").unwrap();
+    assert!(re.has_backrefs() && !re.has_lookaround());
+    assert!(!Regex::new(r"(a)\001").unwrap().has_backrefs());
+
+    // The backtracker is used however long the text is.
+    re.set_backtrack_limit(0);
+    let text = "ab".repeat(10000) + "aa";
+    assert_eq!(re.find(text.as_slice()), Some((20000, 20002)));
+
+    let opts = Options { longest: true, ..Options::new() };
+    let re = Regex::with_options(r"a|(a)\1", opts).unwrap();
+    assert_eq!(re.find("aa"), Some((0, 2)));
+    assert_eq!(Regex::new(r"a|(a)\1").unwrap().find("aa"), Some((0, 1)));
+
+    let re = ByteRegex::new(r"(\xFF)\1").unwrap();
+    assert_eq!(re.find(&[0x00, 0xFF, 0xFF]), Some((1, 3)));
+
+    let re = Regex::new(r"(a)\1").unwrap();
+    let mut src = ::std::io::MemReader::new(Vec::from_slice(bytes!("xaa")));
+    assert!(re.is_match_reader(&mut src, 0).unwrap());
+    let err = RegexSet::new(&["a", r"(b)\1"]).unwrap_err();
+    assert_eq!(err.kind, SyntaxError);
+}
+
+#[test]
+fn backref_step_limit() {
+    // Nothing is remembered about the paths that failed, so this takes
+    // time exponential in the length of the text.
+    let mut re = Regex::new(r"(a|a)*\1b").unwrap();
+    re.set_step_limit(Some(100000));
+    let text = "a".repeat(40);
+    assert_eq!(re.try_is_match(text.as_slice()), Err(StepLimitExceeded));
+    assert_eq!(re.try_find("aab"), Ok(Some((0, 3))));
+}
+
+// Without lookaround or backreferences, atomic groups and possessive
+// repetition are plain groups and greedy repetition.
+mat!(atomic_plain, r"(?>a|ab)c", "abc", Some((0, 3)))
+mat!(atomic_plain_caps, r"(?>(a+))a", "aa", Some((0, 2)), Some((0, 1)))
+mat!(possessive_star_plain, r"a*+a", "aaa", Some((0, 3)))
+mat!(possessive_plus_plain, r"a++b", "aab", Some((0, 3)))
+mat!(possessive_counted_plain, r"a{1,3}+a", "aaa", Some((0, 3)))
+mat!(possessive_lazy_flag, r"(?U)a*+", "aa", Some((0, 2)))
+
+#[test]
+fn atomic_cut() {
+    let tests = [
+        (r"(?>a|ab)c(?!x)", "abc", None),
+        (r"(?>ab|a)c(?!x)", "abc", Some((0, 3))),
+        (r"a*+a(?!x)", "aaa", None),
+        (r"a++b(?!x)", "aab", Some((0, 3))),
+        (r"a?+a(?!x)", "a", None),
+        (r"a{1,3}+a(?!x)", "aaa", None),
+        (r"a{1,3}+a(?!x)", "aaaa", Some((0, 4))),
+        (r"(?>(a+))b\1", "aaba", Some((1, 4))),
+        (r"(?=a)(?>a*)*b", "aab", Some((0, 3))),
+    ];
+    for &(re, text, expected) in tests.iter() {
+        let got = Regex::new(re).unwrap().find(text);
+        assert_eq!((re, text, got), (re, text, expected));
+    }
+}
+
+#[test]
+fn atomic_captures() {
+    let re = Regex::new(r"(?>(a|ab))(c|bcd)(?!x)").unwrap();
+    let caps = re.captures("abcd").unwrap();
+    assert_eq!(caps.iter_pos().collect::<Vec<Option<(uint, uint)>>>(),
+               vec!(Some((0, 4)), Some((0, 1)), Some((1, 4))));
+}
+
+#[test]
+fn atomic_step_limit() {
+    // Without the atomic group, this takes time exponential in the length
+    // of the text (see `backref_step_limit`).
+    let mut re = Regex::new(r"(?>(a|a)*)\1b").unwrap();
+    re.set_step_limit(Some(100000));
+    let text = "a".repeat(40);
+    assert_eq!(re.try_is_match(text.as_slice()), Ok(false));
+}
+
+#[test]
+fn keep_find() {
+    let tests = [
+        (r"a\Kb", "ab", Some((1, 2))),
+        (r"\Ka", "ba", Some((1, 2))),
+        (r"a\K", "ba", Some((2, 2))),
+        (r"a\Kb\Kc", "abc", Some((2, 3))),
+        (r"(?<=a)b\Kc", "abc", Some((2, 3))),
+        // Only the `\K` of the branch that matches counts.
+        (r"(?:a\K|b)c", "ac", Some((1, 2))),
+        (r"(?:a\K|b)c", "bc", Some((0, 2))),
+        (r"(?:a\Kx|ab)", "ab", Some((0, 2))),
+        // Under a repetition, the last `\K` crossed counts.
+        (r"(?:a\K)*b", "aaab", Some((3, 4))),
+        (r"(?:a\K)*b", "b", Some((0, 1))),
+        (r"(?:a\K|x)+b", "aaxb", Some((2, 4))),
+        (r"(?:a\K)+?b", "aab", Some((2, 3))),
+        (r"(a\K)?b", "b", Some((0, 1))),
+        (r"(?:a\K){2}", "aaa", Some((2, 2))),
+    ];
+    for &(re, text, expected) in tests.iter() {
+        let got = Regex::new(re).unwrap().find(text);
+        assert_eq!((re, text, got), (re, text, expected));
+    }
+}
+
+#[test]
+fn keep_captures() {
+    let re = Regex::new(r"(\w+)=\K(\d+)").unwrap();
+    let caps = re.captures("x=12").unwrap();
+    assert_eq!(caps.iter_pos().collect::<Vec<Option<(uint, uint)>>>(),
+               vec!(Some((2, 4)), Some((0, 1)), Some((2, 4))));
+    assert_eq!(re.replace_all("w=1, h=22", "0").as_slice(), "w=0, h=0");
+    assert_eq!(re.replace_all("x=1", "$1").as_slice(), "x=x");
+
+    let re = Regex::new(r"a\K").unwrap();
+    let got: Vec<(uint, uint)> = re.find_iter("aa").collect();
+    assert_eq!(got, vec!((1, 1), (2, 2)));
+    assert_eq!(re.replace_all("aab", "-").as_slice(), "a-a-b");
+}
+
+#[test]
+fn keep_engines() {
+    // The backtracker is used however long the text is.
+    let mut re = Regex::new(r"x\Ky").unwrap();
+    assert!(!re.has_backrefs() && !re.has_lookaround());
+    re.set_backtrack_limit(0);
+    let text = "a".repeat(20000) + "xy";
+    assert_eq!(re.find(text.as_slice()), Some((20001, 20002)));
+
+    // The longest match is compared by where it ends.
+    let opts = Options { longest: true, ..Options::new() };
+    let re = Regex::with_options(r"a\K|ab", opts).unwrap();
+    assert_eq!(re.find("ab"), Some((0, 2)));
+    assert_eq!(Regex::new(r"a\K|ab").unwrap().find("ab"), Some((1, 1)));
+
+    // Atomic groups cut, as they do with lookaround.
+    assert_eq!(Regex::new(r"(?>a|ab)c\K").unwrap().find("abc"), None);
+
+    let re = ByteRegex::new(r"\xFF\K\x00").unwrap();
+    assert_eq!(re.find(&[0xFF, 0x00]), Some((1, 2)));
+    let err = RegexSet::new(&["a", r"a\Kb"]).unwrap_err();
+    assert_eq!(err.kind, SyntaxError);
+    assert!(err.msg.contains("expression 1"));
+}
+
+#[test]
+fn lookahead_unsupported() {
+    let err = RegexSet::new(&["a", "b(?=c)"]).unwrap_err();
+    assert_eq!(err.kind, SyntaxError);
+    let re = Regex::new(r"a(?=a)").unwrap();
+    let mut src = ::std::io::MemReader::new(Vec::from_slice(bytes!("aa")));
+    assert!(re.is_match_reader(&mut src, 0).is_err());
+}
+
+#[test]
+fn clone_isolates_configuration() {
+    let re = Regex::new(r"(a|b|ab)*c").unwrap();
+    let text = "ab".repeat(1000) + "c";
+    let mut copy = re.clone();
+    copy.set_step_limit(Some(1000));
+    copy.set_dfa_size_limit(0);
+    copy.set_backtrack_limit(0);
+    assert_eq!(copy.try_find(text.as_slice()), Err(StepLimitExceeded));
+    assert_eq!(re.try_find(text.as_slice()), Ok(Some((0, 2001))));
+    assert_eq!(re.step_limit(), None);
+    assert!(re.dfa_size_limit() > 0 && re.backtrack_limit() > 0);
+
+    // Each has DFAs of its own.
+    assert!(re.is_match(text.as_slice()));
+    assert!(re.dfa_size() > 0);
+    let other = re.clone();
+    assert_eq!(other.dfa_size(), 0);
+    assert_eq!(copy.dfa_size(), 0);
+
+    // Clones can be configured (and searched) from many tasks at once.
+    let template = Arc::new(re);
+    let (tx, rx) = channel();
+    for i in range(0u, 4) {
+        let (template, tx, text) = (template.clone(), tx.clone(), text.clone());
+        spawn(proc() {
+            let mut re = (*template).clone();
+            re.set_step_limit(if i % 2 == 0 { Some(1000) } else { None });
+            tx.send((i, re.try_find(text.as_slice()).is_ok()));
+        });
+    }
+    for _ in range(0u, 4) {
+        let (i, ok) = rx.recv();
+        assert_eq!((i, ok), (i, i % 2 == 1));
+    }
+    assert_eq!(template.step_limit(), None);
+}
+
+#[test]
+fn step_limit() {
+    let mut re = Regex::new(r"(a|b|ab)*c").unwrap();
+    let text = "ab".repeat(1000) + "c";
+    assert_eq!(re.try_find(text.as_slice()), Ok(Some((0, 2001))));
+    re.set_step_limit(Some(1000));
+    assert_eq!(re.step_limit(), Some(1000));
+    assert_eq!(re.try_find(text.as_slice()), Err(StepLimitExceeded));
+    assert_eq!(re.try_is_match(text.as_slice()), Err(StepLimitExceeded));
+    assert!(re.try_captures(text.as_slice()).is_err());
+    assert_eq!(re.find(text.as_slice()), None);
+    assert!(!re.is_full_match(text.as_slice()));
+    // A short search fits in the budget.
+    assert_eq!(re.try_find("abc"), Ok(Some((0, 3))));
+    assert_eq!(re.captures("abc").unwrap().at(1), "b");
+    // Every search gets a budget of its own.
+    assert_eq!(re.try_find_at("xxabc", 1), Ok(Some((2, 5))));
+    re.set_step_limit(None);
+    assert!(re.try_captures(text.as_slice()).unwrap().is_some());
+}
+
+#[test]
+fn cancel_agrees_with_uncancelled() {
+    let re = regex!(r"\d*");
+    let (text, cancel) = ("a1 22é", Cancel::new());
+    let got = re.find_all_cancel(text, &cancel).unwrap();
+    assert_eq!(got, re.find_iter(text).collect::<Vec<(uint, uint)>>());
+    let got = re.replace_all_cancel(text, "[$0]", &cancel).unwrap();
+    assert_eq!(got, re.replace_all(text, "[$0]"));
+    assert_eq!(re.is_match_cancel(text, &cancel), Ok(true));
+    cancel.cancel();
+    assert_eq!(re.find_all_cancel(text, &cancel), Err(Cancelled));
+    assert!(re.replace_all_cancel(text, "", &cancel).is_err());
+}
+
+#[test]
+fn cancel_large_scan() {
+    // Without the DFA, this takes the NFA several seconds, so the task
+    // below cancels the searches long before they could finish.
+    let mut re = Regex::new(r"(a|b|ab)*c").unwrap();
+    re.set_dfa_size_limit(0);
+    let text = "ab".repeat(1 << 22);
+    let cancel = Cancel::new();
+    let other = cancel.clone();
+    spawn(proc() other.cancel());
+    assert_eq!(re.is_match_cancel(text.as_slice(), &cancel), Err(Cancelled));
+    assert_eq!(re.find_all_cancel(text.as_slice(), &cancel), Err(Cancelled));
+    assert!(re.replace_all_cancel(text.as_slice(), "", &cancel).is_err());
+}
+
+#[test]
+fn differential() {
+    use regex::difftest::{Harness, Generator, EngineDfa, EngineCanonical};
+
+    let harness = Harness::new();
+    for seed in range(0u64, 4) {
+        match harness.run(&mut Generator::new(seed), 150) {
+            Ok(searches) => assert_eq!(searches, 600),
+            Err(div) => fail!("{}", div),
+        }
+    }
+    let empty_width = [r"(a*)*", r"(a|\b)+b", r"(?m)^$", r"(|a)*?\B",
+                       r"(?i)(δ|Δ)+", r"\pL{2}|\d"];
+    for &re in empty_width.iter() {
+        assert!(harness.check(re, "aab\nδΔ a1").is_ok());
+    }
+    assert!(harness.check("a(", "a(").is_ok());
+    assert!(harness.fuzz(bytes!("a+|b", 0u8, "baab")).is_ok());
+    assert!(harness.fuzz(bytes!("a", 0xFFu8, 0u8, "a")).is_ok());
+
+    // The same seed generates the same patterns and texts.
+    let (mut x, mut y) = (Generator::new(7), Generator::new(7));
+    for _ in range(0, 10) {
+        assert_eq!((x.pattern(), x.text()), (y.pattern(), y.text()));
+    }
+
+    let mut allowing = Harness::new();
+    assert!(allowing.allow(EngineDfa, r"\b").is_ok());
+    assert!(allowing.allow(EngineCanonical, "(").is_err());
+}
+
+#[test]
+fn dfa_size_limit_bounds_states() {
+    // The DFA for this regex needs a state for every combination of the
+    // last 17 characters, which is far more than fits in the limit.
+    let limit = 1 << 16;
+    let mut re = Regex::new(r"(a|b)*a(a|b){16}c").unwrap();
+    re.set_dfa_size_limit(limit);
+    let mut nfa = re.clone();
+    nfa.set_dfa_size_limit(0);
+
+    let (mut text, mut x) = (StrBuf::new(), 1u32);
+    for i in range(0, 200000) {
+        // A linear congruential generator is random enough.
+        x = x * 1103515245 + 12345;
+        text.push_char(if (x >> 16) & 1 == 0 { 'a' } else { 'b' });
+        if i % 5000 == 4999 {
+            text.push_char('c');
+        }
+    }
+    let text = text.as_slice();
+    let got: Vec<(uint, uint)> = re.find_iter(text).collect();
+    let want: Vec<(uint, uint)> = nfa.find_iter(text).collect();
+    assert!(got.len() > 0);
+    assert_eq!(got, want);
+    assert!(re.dfa_size() <= 2 * limit);
+    assert_eq!(nfa.dfa_size(), 0);
+}
+
+#[test]
+fn cut() {
+    let re = regex!(r"[0-9]+");
+    assert_eq!(re.cut("ab12cd34"), Some(("ab", "12", "cd34")));
+    assert_eq!(re.cut("abcd"), None);
+    assert_eq!(regex!(r"x*").cut("ab"), Some(("", "", "ab")));
+}
+
+#[test]
+fn cut_captures() {
+    let re = regex!(r"(\w+)@(\w+)");
+    let (before, caps, after) = re.cut_captures("to: me@home!").unwrap();
+    assert_eq!((before, caps.at(1), caps.at(2), after),
+               ("to: ", "me", "home", "!"));
+    assert!(re.cut_captures("nobody").is_none());
+}
+
+#[test]
+fn push_captures_locations() {
+    let re = regex!(r"(a*)(b)?");
+    let mut locs = vec!(Some(42));
+    // A match in which every group is empty is still a match.
+    assert!(re.push_captures("xyz", &mut locs));
+    assert_eq!(locs, vec!(Some(42), Some(0), Some(0), Some(0), Some(0),
+                          None, None));
+    let re = regex!(r"(a)(b)");
+    assert!(!re.push_captures("xyz", &mut locs));
+    assert_eq!(locs.len(), 7);
+}
+
+#[test]
+fn push_captures_all_agrees_with_captures_iter() {
+    let re = regex!(r"(\w)(\d)?");
+    let text = "a1 b c3 δ";
+    let mut expected = vec!();
+    for caps in re.captures_iter(text) {
+        for pos in caps.iter_pos() {
+            match pos {
+                None => expected.push_all(&[None, None]),
+                Some((s, e)) => expected.push_all(&[Some(s), Some(e)]),
+            }
+        }
+    }
+    let mut locs = vec!();
+    assert_eq!(re.push_captures_all(text, &mut locs), 4);
+    assert_eq!(locs, expected);
+    assert_eq!(re.push_captures_all("!", &mut locs), 0);
+    assert_eq!(locs, expected);
+}
+
+#[test]
+fn replace_all_closure_groups() {
+    // Missing groups and empty groups are distinguished by their positions.
+    let re = regex!(r"(a)?(b*)");
+    let got = re.replace_all("ab xa", |caps: &Captures| {
+        let show = |i: uint| match caps.pos(i) {
+            None => ~"-",
+            Some(_) => format!("[{}]", caps.at(i)),
+        };
+        format!("<{}{}>", show(1), show(2))
+    });
+    assert_eq!(got.as_slice(), "<[a][b]> <-[]>x<[a][]>");
+}
+
+#[test]
+fn push_replace_all_reuses_buffers() {
+    let steps: &[(&str, &str)] = &[
+        ("tHa[Nt]", "<4>"), ("aND|caN|Ha[DS]|WaS", "<3>"),
+        ("a[NSt]|BY", "<2>"), ("<[^>]*>", "|"),
+    ];
+    let text = "tHaN aND caN HaD WaS BY tHat";
+    let (mut cur, mut next) = (StrBuf::from_str(text), StrBuf::new());
+    let mut expected = text.to_owned();
+    for &(re, rep) in steps.iter() {
+        let re = Regex::new(re).unwrap();
+        next.truncate(0);
+        re.push_replace_all(&mut next, cur.as_slice(), rep);
+        ::std::mem::swap(&mut cur, &mut next);
+        expected = re.replace_all(expected.as_slice(), rep).into_owned();
+    }
+    assert_eq!(cur.as_slice(), expected.as_slice());
+    assert_eq!(cur.as_slice(), "| | | | | | |");
+}
+
+#[test]
+fn push_replacen_expansion() {
+    let re = regex!(r"(\w+)=(\w*)");
+    let mut dst = StrBuf::from_str(">");
+    re.push_replacen(&mut dst, "a=1 b= c=3", 2, "$2:$1");
+    assert_eq!(dst.as_slice(), ">1:a :b c=3");
+    let mut dst = StrBuf::new();
+    regex!("x*").push_replace_all(&mut dst, "aδ", NoExpand("$"));
+    assert_eq!(dst.as_slice(), "$a$δ$");
+}
+
+#[test]
+fn replacen_count_matches() {
+    let re = regex!(r"(?P<d>\d)");
+    assert_eq!(re.replacen_count("a1b2c3", 0, "<$d>"),
+               (StrBuf::from_str("a<1>b<2>c<3>"), 3));
+    assert_eq!(re.replacen_count("a1b2c3", 2, "<$d>"),
+               (StrBuf::from_str("a<1>b<2>c3"), 2));
+    assert_eq!(re.replacen_count("abc", 0, "<$d>"),
+               (StrBuf::from_str("abc"), 0));
+    // Empty matches are counted just like in `find_iter`.
+    let re = regex!("x*");
+    assert_eq!(re.replacen_count("axxb", 0, "-"),
+               (StrBuf::from_str("-a-b-"), 3));
+    assert_eq!(re.replacen_count("axxb", 1, "-"),
+               (StrBuf::from_str("-axxb"), 1));
+}
+
+#[test]
+fn replace_preserving_case() {
+    let re = regex!(r"(?i)colour");
+    let got = re.replace_all_preserving_case(
+        "colour Colour COLOUR cOLOUR ColOUR", "color");
+    assert_eq!(got.as_slice(), "color Color COLOR color color");
+    // The replacement's own case is changed for upper and title case only.
+    let got = re.replace_all_preserving_case("colour Colour COLOUR", "HuE");
+    assert_eq!(got.as_slice(), "HuE Hue HUE");
+    // What groups matched is kept as is, and counts as the first letter.
+    let re = regex!(r"(?i)the (\w+)");
+    let got = re.replace_all_preserving_case("THE CAT, The dog", "a $1 TOO");
+    assert_eq!(got.as_slice(), "A CAT TOO, A dog too");
+    // The case of the whole match is what counts.
+    let got = re.replace_all_preserving_case("THE cat", "a $1");
+    assert_eq!(got.as_slice(), "a cat");
+    let got = re.replace_all("The dog", PreserveCase("$1 THE"));
+    assert_eq!(got.as_slice(), "dog the");
+}
+
+#[test]
+fn replace_preserving_case_non_letters() {
+    // Characters that aren't letters are skipped in the match and in the
+    // replacement.
+    let re = regex!(r"(?i)#\d*colou?r");
+    let got = re.replace_all_preserving_case("#1Colour #COLOR #2", "#-shade");
+    assert_eq!(got.as_slice(), "#-Shade #-SHADE #2");
+    // A single upper case letter is title case, and a match without letters
+    // leaves the replacement as written.
+    let re = regex!(r"(?i)\ba\b|\d+");
+    let got = re.replace_all_preserving_case("A a 12", "one");
+    assert_eq!(got.as_slice(), "One one one");
+}
+
+#[test]
+fn replace_preserving_case_unicode() {
+    let re = regex!(r"(?i)été");
+    let got = re.replace_all_preserving_case("été Été ÉTÉ", "ŝoŭo");
+    assert_eq!(got.as_slice(), "ŝoŭo Ŝoŭo ŜOŬO");
+    // `ß` has no simple upper case mapping, so it's kept.
+    let re = regex!(r"(?i)road");
+    let got = re.replace_all_preserving_case("ROAD Road", "straße");
+    assert_eq!(got.as_slice(), "STRAßE Straße");
+}
+
+#[test]
+fn multi_replacer_single_pass() {
+    // Replacements are never searched again.
+    let rep = MultiReplacer::new(&[("a", "b"), ("b", "c")]).unwrap();
+    assert_eq!(rep.replace("aabb").as_slice(), "bbcc");
+    // The earliest match wins, and ties go to the expression listed first.
+    let rep = MultiReplacer::new(&[("bc", "1"), ("abc", "2"),
+                                   ("ab", "3")]).unwrap();
+    assert_eq!(rep.replace("abc bc").as_slice(), "2 1");
+    let rep = MultiReplacer::new(&[("ab", "3"), ("abc", "2")]).unwrap();
+    assert_eq!(rep.replace("abc").as_slice(), "3c");
+}
+
+#[test]
+fn multi_replacer_groups() {
+    // Groups are numbered (and named) per expression.
+    let rep = MultiReplacer::new(&[(r"(?P<x>\d)-(\d)", "$2+$x"),
+                                   (r"(?P<x>[a-z])(\d)?", "<$x$2>")]).unwrap();
+    let mut dst = StrBuf::new();
+    assert_eq!(rep.push_replace(&mut dst, "1-2 a3 b"), 3);
+    assert_eq!(dst.as_slice(), "2+1 <a3> <b>");
+    assert_eq!(rep.len(), 2);
+}
+
+#[test]
+fn multi_replacer_empty() {
+    let rep = MultiReplacer::new(&[]).unwrap();
+    assert_eq!(rep.replace("abc").as_slice(), "abc");
+    let rep = MultiReplacer::new(&[("x*", "-")]).unwrap();
+    assert_eq!(rep.replace("axxb").as_slice(), "-a-b-");
+    assert!(MultiReplacer::new(&[("a", ""), ("(", "")]).is_err());
+}
+
+#[test]
+fn multi_replacer_write() {
+    let rep = MultiReplacer::new(&[("tHa[Nt]", "<4>"), ("a[NSt]|BY", "<2>")])
+                            .unwrap();
+    let mut w = ::std::io::MemWriter::new();
+    assert_eq!(rep.write_replace(&mut w, "tHaN aN BY").unwrap(), 3);
+    assert_eq!(w.unwrap(), Vec::from_slice(bytes!("<4> <2> <2>")));
+}
+
+// A reader that returns at most a few bytes at a time, so that characters
+// and matches are split between reads.
+struct Trickle {
+    r: ::std::io::MemReader,
+}
+
+impl ::std::io::Reader for Trickle {
+    fn read(&mut self, buf: &mut [u8]) -> ::std::io::IoResult<uint> {
+        let n = ::std::cmp::min(buf.len(), 7);
+        self.r.read(buf.mut_slice_to(n))
+    }
+}
+
+fn stream_replace(re: &Regex, text: &str, max_len: uint, rep: &str)
+                 -> (StrBuf, uint) {
+    let bytes = Vec::from_slice(text.as_bytes());
+    let mut src = Trickle { r: ::std::io::MemReader::new(bytes) };
+    let mut dst = ::std::io::MemWriter::new();
+    let n = re.stream_replace_all(&mut src, &mut dst, max_len, rep).unwrap();
+    let out = StrBuf::from_str(::std::str::from_utf8(dst.get_ref()).unwrap());
+    (out, n)
+}
+
+#[test]
+fn stream_replace_all_agrees_with_replace_all() {
+    let mut text = StrBuf::new();
+    for i in range(0u, 20000) {
+        text.push_str(["ab", "δxy", "cd\n", " x ", "Δ", "\n"][i % 6]);
+        if i % 7 == 0 {
+            text.push_str("xx");
+        }
+    }
+    let text = text.as_slice();
+    assert!(text.len() > 128 * 1024);
+    for &re in [r"(?m)^ab|cd$|δ[a-z]{2}|\bx\b", r"^ab|\n$", r"", r"x*",
+                r"\B", r"(?m)$"].iter() {
+        let re = Regex::new(re).unwrap();
+        let (got, n) = stream_replace(&re, text, 0, "<$0>");
+        assert_eq!(n, re.find_iter(text).count());
+        assert!(got.as_slice() == re.replace_all(text, "<$0>").as_slice(),
+                "stream and string replacements differ for {}", re);
+    }
+}
+
+#[test]
+fn stream_replace_all_unbounded() {
+    let re = regex!(r"\[[^\]]*\]");
+    let text = "[a] [bcdefgh] ".repeat(10000);
+    let (got, n) = stream_replace(&re, text.as_slice(), 16, "[]");
+    assert_eq!(n, 20000);
+    assert_eq!(got.as_slice(), "[] [] ".repeat(10000).as_slice());
+}
+
+#[test]
+fn stream_unbounded_match_too_long() {
+    // The match is longer than a window, so it's only found when it's
+    // allowed to be longer than `max_len`, and never cut short.
+    let text = format!("a{}b", "x".repeat(100000));
+    let text = text.as_slice();
+    let re = regex!(r"a.*b");
+    assert!(re.find_reader_all(&mut trickle(text), 0, 16).is_err());
+    assert_eq!(re.find_reader_all(&mut trickle(text), 0, 200000).unwrap(),
+               vec!((0, 100002)));
+    let segs: Vec<&[u8]> = text.as_bytes().chunks(1000).collect();
+    assert!(re.find_segments(segs.as_slice(), 0, 16).is_err());
+    assert!(re.is_match_segments(segs.as_slice(), 200000).unwrap());
+
+    // A match that starts near the end of a window is kept until it ends.
+    let text = format!("{}a{}b{}", "y".repeat(67000), "x".repeat(1000),
+                       "y".repeat(10000));
+    let text = text.as_slice();
+    let re = regex!(r"a[^b]*b");
+    assert_eq!(re.find_reader_all(&mut trickle(text), 0, 2000).unwrap(),
+               vec!((67000, 68002)));
+    let segs: Vec<&[u8]> = text.as_bytes().chunks(1000).collect();
+    assert_eq!(re.find_segments(segs.as_slice(), 0, 2000).unwrap(),
+               vec!((67000, 68002)));
+}
+
+#[test]
+fn stream_unbounded_backref() {
+    let re = Regex::new(r"(a)\1x*").unwrap();
+    assert!(re.is_match_reader(&mut trickle("aax"), 16).is_err());
+}
+
+#[test]
+fn stream_replace_all_invalid_utf8() {
+    let re = regex!(r"a");
+    let bytes = Vec::from_slice(bytes!("a", 0xFF, "a"));
+    let mut src = ::std::io::MemReader::new(bytes);
+    let mut dst = ::std::io::MemWriter::new();
+    assert!(re.stream_replace_all(&mut src, &mut dst, 0, "b").is_err());
+}
+
+#[test]
+fn replace_writer_agrees_with_replace_all() {
+    let re = regex!(r"(?m)^a|b$|\bδ+\b|[0-9]{3}");
+    let text = "a bδδ b\nab 1234 δ\nb".repeat(5000);
+    let mut w = re.replace_writer(::std::io::MemWriter::new(), 0, "<$0>");
+    // Write a few bytes at a time, splitting characters along the way.
+    for piece in text.as_bytes().chunks(5) {
+        w.write(piece).unwrap();
+    }
+    assert_eq!(w.finish().unwrap(), re.find_iter(text.as_slice()).count());
+    let got = ::std::str::from_utf8(w.get_ref().get_ref()).unwrap();
+    assert!(got == re.replace_all(text.as_slice(), "<$0>").as_slice());
+}
+
+#[test]
+fn replace_writer_reset() {
+    let re = regex!(r"^ab");
+    let mut w = re.replace_writer(::std::io::MemWriter::new(), 0, "X");
+    w.write_str("a").unwrap();
+    w.reset();
+    w.write_str("abab").unwrap();
+    assert_eq!(w.finish().unwrap(), 1);
+    w.write_str("ab").unwrap();
+    assert_eq!(w.finish().unwrap(), 1);
+    assert_eq!(w.unwrap().unwrap(), Vec::from_slice(bytes!("XabX")));
+}
+
+fn trickle(text: &str) -> Trickle {
+    Trickle { r: ::std::io::MemReader::new(Vec::from_slice(text.as_bytes())) }
+}
+
+#[test]
+fn find_reader_all_agrees_with_find_iter() {
+    let text = "xyzzy δ foo\nfoo xyz\n".repeat(8000);
+    for &re in [r"\bfoo\b|δ", r"(?m)^x|z$", r"y*"].iter() {
+        let re = Regex::new(re).unwrap();
+        let got = re.find_reader_all(&mut trickle(text.as_slice()), 0, 8);
+        let expected: Vec<(uint, uint)> =
+            re.find_iter(text.as_slice()).collect();
+        assert!(got.unwrap() == expected, "matches differ for {}", re);
+    }
+}
+
+#[test]
+fn find_reader_all_limit() {
+    let re = regex!(r"[0-9]+");
+    let got = re.find_reader_all(&mut trickle("a1 b22 c333"), 2, 16);
+    assert_eq!(got.unwrap(), vec!((1, 2), (4, 6)));
+}
+
+#[test]
+fn scan_reader_stops() {
+    let re = regex!(r"\w+");
+    let mut words = vec!();
+    re.scan_reader(&mut trickle("one two three"), 16, |_, _, m| {
+        words.push(m.to_owned());
+        words.len() < 2
+    }).unwrap();
+    assert_eq!(words, vec!("one".to_owned(), "two".to_owned()));
+}
+
+#[test]
+fn is_match_reader_agrees_with_is_match() {
+    let text = "abc δδ xyz\n".repeat(20000);
+    for &(re, expected) in [(r"xyz$", true), (r"(?m)^δ", false),
+                            (r"c\b δ", true), (r"[0-9]{3}", false)].iter() {
+        let re = Regex::new(re).unwrap();
+        let got = re.is_match_reader(&mut trickle(text.as_slice()), 0);
+        assert_eq!(got.unwrap(), expected);
+        assert_eq!(re.is_match(text.as_slice()), expected);
+    }
+}
+
+#[test]
+fn find_segments_agrees_with_find_iter() {
+    // Segments of odd lengths split characters and literals.
+    let text = "xyzzy δ foo\nfoo xyz\n".repeat(8000);
+    let bytes = text.as_bytes();
+    let segs: Vec<&[u8]> = bytes.chunks(7).collect();
+    for &re in [r"\bfoo\b|δ", r"(?m)^x|z$", r"y*", r"zy δ f"].iter() {
+        let re = Regex::new(re).unwrap();
+        let got = re.find_segments(segs.as_slice(), 0, 8);
+        let expected: Vec<(uint, uint)> =
+            re.find_iter(text.as_slice()).collect();
+        assert!(got.unwrap() == expected, "matches differ for {}", re);
+        let found = re.is_match_segments(segs.as_slice(), 8);
+        assert_eq!(found.unwrap(), !expected.is_empty());
+    }
+    let re = regex!(r"[0-9]+");
+    let segs = &[bytes!("a1 b2"), bytes!("2 c333")];
+    assert_eq!(re.find_segments(segs, 2, 16).unwrap(),
+               vec!((1, 2), (4, 6)));
+    assert!(re.is_match_segments(&[bytes!("a"), bytes!(""), bytes!("1")],
+                                 16).unwrap());
+    assert!(!re.is_match_segments(&[], 16).unwrap());
+    assert!(regex!(r"^$").is_match_segments(&[], 16).unwrap());
+}
+
+#[test]
+fn is_match_reader_invalid_utf8() {
+    let re = regex!(r"b");
+    let bytes = Vec::from_slice(bytes!("a", 0xFF));
+    let mut src = ::std::io::MemReader::new(bytes);
+    assert!(re.is_match_reader(&mut src, 0).is_err());
+}
+
+#[test]
+fn find_reader_all_unicode_word_boundary() {
+    // The characters around a boundary are split between reads.
+    let text = "cafe\u0301 a\u0663\u203Fb слово\n".repeat(4000);
+    for &re in [r"(?w)\b", r"(?w)e\B", r"(?w)\b\pM", r"(?w)\B\S"].iter() {
+        let re = Regex::new(re).unwrap();
+        let got = re.find_reader_all(&mut trickle(text.as_slice()), 0, 8);
+        let expected: Vec<(uint, uint)> =
+            re.find_iter(text.as_slice()).collect();
+        assert!(got.unwrap() == expected, "matches differ for {}", re);
+    }
+}
+
+fn read_fields<R: Reader>(fields: &mut ::regex::Splitter<R>) -> Vec<~str> {
+    let mut got = vec!();
+    loop {
+        match fields.next_field() {
+            Ok(field) => got.push(field),
+            Err(ref err) if err.kind == ::std::io::EndOfFile => return got,
+            Err(err) => fail!("{}", err),
+        }
+    }
+}
+
+#[test]
+fn splitter_agrees_with_split() {
+    let text = "rec δ one\n--\nrec two\n---\n\n-- rec".repeat(4000);
+    let re = regex!(r"\n-{2,3}\n|\s--\s");
+    let mut fields = re.splitter(trickle(text.as_slice()), 0);
+    let expected: Vec<~str> =
+        re.split(text.as_slice()).map(|s| s.to_owned()).collect();
+    assert!(read_fields(&mut fields) == expected);
+}
+
+#[test]
+fn splitter_trailing_field() {
+    let re = regex!(r",");
+    let mut fields = re.splitter(trickle(",a,,b"), 0);
+    assert_eq!(read_fields(&mut fields),
+               vec!("".to_owned(), "a".to_owned(), "".to_owned(),
+                    "b".to_owned()));
+    let mut fields = re.splitter(trickle("a,b,"), 0);
+    assert_eq!(read_fields(&mut fields), vec!("a".to_owned(), "b".to_owned()));
+    let mut fields = re.splitter(trickle(""), 0);
+    assert_eq!(read_fields(&mut fields), vec!());
+}
+
+#[test]
+fn splitter_empty_matches() {
+    let re = regex!(r"x*");
+    let mut fields = re.splitter(trickle("aδxb"), 0);
+    let expected: Vec<~str> =
+        re.split("aδxb").map(|s| s.to_owned()).collect();
+    assert_eq!(read_fields(&mut fields), expected);
+}
+
+#[test]
+fn splitter_max_field_len() {
+    let re = regex!(r"\n");
+    let mut fields = re.splitter(trickle("short\nmuch too long\n"), 0);
+    fields.set_max_field_len(8);
+    assert_eq!(fields.next_field().unwrap(), "short".to_owned());
+    assert!(fields.next_field().is_err());
+}
+
+#[test]
+fn tokenizer_agrees_with_find_iter() {
+    let text = "let x1 = (y + 22) * z;\n".repeat(5000);
+    let re = regex!(r"[a-z]\w*|[0-9]+|[=+*();]|$");
+    let mut tokens = re.tokenizer(trickle(text.as_slice()), 16);
+    let expected: Vec<~str> = re.find_iter(text.as_slice())
+                                .map(|(s, e)| text.slice(s, e).to_owned())
+                                .collect();
+    assert!(read_fields(&mut tokens) == expected);
+}
+
+#[test]
+fn tokenizer_long_tokens() {
+    // Tokens much longer than the chunks in which the text is read.
+    let (a, b) = ("a".repeat(100000), "b".repeat(70000));
+    let text = format!("{} {} {}", a, b, a);
+    let re = regex!(r"a+|b+");
+    let mut tokens = re.tokenizer(trickle(text.as_slice()), 200000);
+    assert_eq!(read_fields(&mut tokens), vec!(a.clone(), b, a));
+}
+
+#[test]
+fn tokenizer_max_field_len() {
+    let re = regex!(r"\w+");
+    let mut tokens = re.tokenizer(trickle("ab abcdefgh"), 64);
+    tokens.set_max_field_len(4);
+    assert_eq!(tokens.next_field().unwrap(), "ab".to_owned());
+    assert!(tokens.next_field().is_err());
+}
+
+#[test]
+fn is_full_match_not_leftmost_first() {
+    let re = regex!(r"a|ab");
+    assert_eq!(re.find("ab"), Some((0, 1)));
+    assert!(re.is_full_match("ab"));
+    assert!(re.is_full_match("a"));
+    assert!(!re.is_full_match("abc"));
+    assert!(!re.is_full_match("xab"));
+    assert!(regex!(r"a*").is_full_match(""));
+}
+
+#[test]
+fn is_full_match_multi_line() {
+    // `$` still matches at the end of every line, but all of the text must
+    // be matched.
+    let re = regex!(r"(?m)^\w+$");
+    assert!(re.is_full_match("abc"));
+    assert!(!re.is_full_match("abc\n"));
+    assert!(!re.is_full_match("abc\ndef"));
+    assert!(regex!(r"(?m)^\w+$\n^\w+$").is_full_match("abc\ndef"));
+}
+
+#[test]
+fn full_captures() {
+    let re = regex!(r"(?P<int>[0-9]+)(\.(?P<frac>[0-9]+))?|(x)");
+    let caps = re.full_captures("3.14").unwrap();
+    assert_eq!((caps.name("int"), caps.name("frac")), ("3", "14"));
+    assert_eq!(caps.pos(0), Some((0, 4)));
+    assert!(re.full_captures("3.14 ").is_none());
+}
+
+#[test]
+fn full_dfa() {
+    let patterns = [
+        r"(a|b)*a(a|b){3}", r"(?i)δ+\b", r"(?m)^\w+$", r"[a-cé]+(?:x|yz)",
+        r"\B\pL\d", r"(?i)k|ſ", r"\w+\Z",
+    ];
+    let texts = [
+        "abbaab baa", "ΔδΔ δ\nfoo", "foo\nbar baz\n", "éabx yz cyz",
+        "ab1 é2", "KK\u212Aſs",
+    ];
+    for &pattern in patterns.iter() {
+        let lazy = Regex::new(pattern).unwrap();
+        let mut full = Regex::new(pattern).unwrap();
+        full.compile_dfa(1 << 20).unwrap();
+        assert!(full.explain().contains("engine: full dfa"));
+        for &text in texts.iter() {
+            let found: Vec<(uint, uint)> = full.find_iter(text).collect();
+            assert_eq!(found, lazy.find_iter(text).collect());
+            assert_eq!(full.is_match(text), lazy.is_match(text));
+        }
+    }
+
+    let mut re = Regex::new(r"(a|b)*a(a|b){20}").unwrap();
+    assert_eq!(re.compile_dfa(1 << 16).unwrap_err().kind, DfaTooLarge);
+    assert_eq!(re.full_dfa_size(), 0);
+    let mut re = Regex::new(r"(a)\1").unwrap();
+    assert_eq!(re.compile_dfa(1 << 20).unwrap_err().kind, DfaUnsupported);
+
+    let mut re = Regex::new(r"\w+@\w+").unwrap();
+    re.compile_dfa(1 << 20).unwrap();
+    assert!(re.full_dfa_size() > 0 && re.full_dfa_size() <= 1 << 20);
+    assert_eq!(re.clone().full_dfa_size(), re.full_dfa_size());
+    re.set_dfa_size_limit(1 << 20);
+    assert_eq!(re.full_dfa_size(), 0);
+}
+
+#[test]
+fn match_steady_state() {
+    // Nothing in Rust counts allocations, so this checks what keeps the
+    // searches of the DFA from allocating: once it has computed the states
+    // that a search visits (including those reached by transitions that
+    // aren't cached), searching again computes no more.
+    let mut re = Regex::new(r"(\pL|\d)+@\w+").unwrap();
+    re.set_backtrack_limit(0);
+    let text = "žluťoučký kůň 42@δ ".repeat(100);
+    let text = text.as_slice();
+    let found: Vec<(uint, uint)> = re.find_iter(text).collect();
+    assert_eq!(found.len(), 100);
+    assert!(re.is_match(text));
+    let size = re.dfa_size();
+    assert!(size > 0);
+    for _ in range(0, 10) {
+        assert!(re.is_match(text));
+        assert_eq!(re.find_iter(text).collect::<Vec<(uint, uint)>>(), found);
+    }
+    assert_eq!(re.dfa_size(), size);
+
+    // Tiny expressions find where matches start and end with the DFA too.
+    let re = Regex::new(r"a+b").unwrap();
+    assert_eq!(re.find_iter("xaab ab aaa b").collect::<Vec<(uint, uint)>>(),
+               vec![(1, 4), (5, 7)]);
+    assert!(!re.is_match("aaa"));
+}
+
+#[test]
+fn class_bitmap() {
+    // Characters on either side of the last one in a class's bitmap (ÿ,
+    // U+00FF), with negated, case insensitive and big classes.
+    let cases: &[(&str, &str, &[&str])] = &[
+        ("[ÿĀ]+", "þÿĀā", &["ÿĀ"]),
+        ("[^ÿĀ]+", "þÿĀā", &["þ", "ā"]),
+        ("(?i)ÿ|[ÿ]", "ÿŸy", &["ÿ", "Ÿ"]),
+        (r"\pL+", "a1ÿ2δ", &["a", "ÿ", "δ"]),
+        (r"(?i)[a-cĀ-ą]+", "BĂx", &["BĂ"]),
+        (r"[^\pL\d]+", "a, ÿ; δ", &[", ", "; "]),
+    ];
+    for &(pattern, text, expected) in cases.iter() {
+        let re = Regex::new(pattern).unwrap();
+        let mut nfa = re.clone();
+        nfa.set_dfa_size_limit(0);
+        nfa.set_backtrack_limit(0);
+        for re in [re, nfa].iter() {
+            let found: Vec<&str> = re.find_iter(text).map(|(s, e)| {
+                text.slice(s, e)
+            }).collect();
+            assert_eq!(found.as_slice(), expected);
+        }
+    }
+}
+
+#[test]
+fn literal_trie() {
+    // Alternations of literals are compiled as tries, which must find the
+    // same matches as trying the alternates one after the other. Alternates
+    // that are groups aren't literals, so they're compiled as they are.
+    let cases: &[(&[&str], &str, &str)] = &[
+        (&["foo", "foobar", "food"], "", "foobar food fo foodbar"),
+        (&["foobar", "foo", "food"], "", "foobar food fo foodbar"),
+        (&["fooda", "foo", "foodb"], "b?", "foodb fooda foob foodab"),
+        (&["a", "ab", "abc", "b", "ab"], "c", "abcc abc bc ac"),
+        (&["abc", "ab", "a"], r"\b", "a ab abc abcd"),
+        (&["x", "y", "z", "xy"], "y*", "xyy zy yx"),
+    ];
+    for &(alts, suffix, text) in cases.iter() {
+        let groups: Vec<~str> =
+            alts.iter().map(|alt| format!("({})", alt)).collect();
+        let trie = Regex::new(format!("(?:{}){}", alts.connect("|"),
+                                      suffix).as_slice()).unwrap();
+        let plain = Regex::new(format!("(?:{}){}", groups.connect("|"),
+                                       suffix).as_slice()).unwrap();
+        let mut nfa = trie.clone();
+        nfa.set_dfa_size_limit(0);
+        nfa.set_backtrack_limit(0);
+        let expected: Vec<(uint, uint)> = plain.find_iter(text).collect();
+        assert_eq!(trie.find_iter(text).collect::<Vec<(uint, uint)>>(),
+                   expected);
+        assert_eq!(nfa.find_iter(text).collect::<Vec<(uint, uint)>>(),
+                   expected);
+        assert!(trie.dump_program().lines().count()
+                < plain.dump_program().lines().count());
+    }
+}
+
+#[test]
+fn find_parallel() {
+    use regex::difftest::Generator;
+
+    // Texts made of the generator's texts are big enough to be split, and
+    // its patterns match often (and are often empty).
+    let mut gen = Generator::new(7);
+    let mut text = StrBuf::new();
+    while text.len() < 20000 {
+        text.push_str(gen.text().as_slice());
+    }
+    let text = text.as_slice();
+    let mut patterns = vec!(~r"\w+", ~"", ~r"\b", ~"(?m)^|$", ~"a*",
+                            ~r"(?s).{0,3000}", ~r"[^\n]*\n", ~"δ|é+");
+    for _ in range(0, 20) {
+        patterns.push(gen.pattern());
+    }
+    for pattern in patterns.iter() {
+        let re = match Regex::new(pattern.as_slice()) {
+            Ok(re) => re,
+            Err(_) => continue,
+        };
+        let expected: Vec<(uint, uint)> = re.find_iter(text).collect();
+        for &workers in [1u, 2, 3, 5].iter() {
+            let found = re.find_iter_parallel(text, workers);
+            assert!(found == expected, "/{}/ with {} workers", pattern,
+                    workers);
+            assert_eq!(re.count_parallel(text, workers), expected.len());
+        }
+    }
+}
+
+#[test]
+fn char_offsets() {
+    fn chars(text: &str, byte: uint) -> uint {
+        text.slice_to(byte).char_len()
+    }
+    let text = "δé ábc\n日本 x=\nδ";
+    for &re in [r"\w+", r"", r"(?m)$", r"(\pL)(\pM)?|(\s)"].iter() {
+        let re = Regex::new(re).unwrap();
+        let expected: Vec<(uint, uint)> = re.find_iter(text)
+            .map(|(s, e)| (chars(text, s), chars(text, e))).collect();
+        let got: Vec<(uint, uint)> = re.find_iter_chars(text).collect();
+        assert!(got == expected, "matches differ for {}", re);
+
+        let expected: Vec<Vec<Option<(uint, uint)>>> =
+            re.captures_iter(text).map(|caps| {
+                caps.iter_pos().map(|pos| pos.map(|(s, e)| {
+                    (chars(text, s), chars(text, e))
+                })).collect()
+            }).collect();
+        let got: Vec<Vec<Option<(uint, uint)>>> =
+            re.captures_iter_chars(text).collect();
+        assert!(got == expected, "captures differ for {}", re);
+    }
+
+    let chars: Vec<char> = "x日本=語".chars().collect();
+    let re = regex!(r"(\w+)=(\w)");
+    assert_eq!(re.find_chars(chars.as_slice()), Some((0, 5)));
+    assert_eq!(re.captures_chars(chars.as_slice()),
+               Some(vec!(Some((0, 5)), Some((0, 3)), Some((4, 5)))));
+    assert_eq!(regex!(r"[0-9]").find_chars(chars.as_slice()), None);
+    // With `\K`, a group can start before the match.
+    let re = Regex::new(r"(é+)\Kx").unwrap();
+    assert_eq!(re.captures_chars(&['a', 'é', 'x']),
+               Some(vec!(Some((2, 3)), Some((1, 2)))));
+}
+
+#[test]
+fn filter_texts() {
+    let texts = &["", "a1", "b", "xyz 12", "δ3", "a\nb", "aaaa"];
+    for &re in [r"[0-9]", r"^a", r"(?m)^b$", r"", r"x{5}", r"\pL\d",
+                r"a|b"].iter() {
+        let mut re = Regex::new(re).unwrap();
+        for &limit in [0u, 10 * (1 << 20)].iter() {
+            re.set_dfa_size_limit(limit);
+            let expected: Vec<uint> = range(0, texts.len())
+                .filter(|&i| re.is_match(texts[i])).collect();
+            assert!(re.filter(texts) == expected, "texts differ for {}", re);
+            assert_eq!(re.is_match_any(texts), !expected.is_empty());
+        }
+    }
+    let re = regex!(r"b+");
+    assert_eq!(re.filter(texts), vec!(2, 5));
+    assert!(!re.is_match_any(&["a", "", "c"]));
+    assert!(!re.is_match_any(&[]));
+
+    let re = ByteRegex::new(r"\xFF").unwrap();
+    let texts: &[&[u8]] = &[bytes!("a"), &[0x61, 0xFF], &[0xC3, 0xBF]];
+    assert_eq!(re.filter(texts), vec!(1));
+    assert!(re.is_match_any(texts));
+    assert!(!re.is_match_any(texts.slice_to(1)));
+}
+
+// Gives every position at which one of the bytes given is found (and the
+// end of the text, if `end` is true), and counts how many times it's asked.
+struct BytesPrefilter {
+    bytes: ~[u8],
+    end: bool,
+    calls: Arc<Mutex<uint>>,
+}
+
+impl Prefilter for BytesPrefilter {
+    fn next_candidate(&self, text: &[u8], from: uint) -> Option<uint> {
+        let mut calls = self.calls.lock();
+        *calls += 1;
+        let found = range(from, text.len()).find(|&i| {
+            self.bytes.contains(&text[i])
+        });
+        match found {
+            None if self.end && from <= text.len() => Some(text.len()),
+            found => found,
+        }
+    }
+}
+
+// A prefilter that gives positions before the one it's asked about.
+struct BackwardsPrefilter;
+
+impl Prefilter for BackwardsPrefilter {
+    fn next_candidate(&self, _: &[u8], from: uint) -> Option<uint> {
+        if from > 0 { Some(from - 1) } else { Some(0) }
+    }
+}
+
+#[test]
+fn prefilter_hook() {
+    let text = "ab 12 δcd\n34 é5 -x-";
+    for &(re, bytes, end) in [(r"[0-9]+", "0123456789", false),
+                              (r"(?m)^\d|c(d)", "13c\n", false),
+                              (r"\b\w", "a1c35xδé", false),
+                              (r"(?<=-)x", "-x", false),
+                              (r"\d?", "ab 12δcd\n34é5-x", true)].iter() {
+        let mut re = Regex::new(re).unwrap();
+        let expected: Vec<Vec<Option<(uint, uint)>>> =
+            re.captures_iter(text).map(|c| c.iter_pos().collect()).collect();
+        let calls = Arc::new(Mutex::new(0u));
+        re.set_prefilter(~BytesPrefilter {
+            bytes: bytes.as_bytes().to_owned(),
+            end: end,
+            calls: calls.clone(),
+        });
+        let got: Vec<Vec<Option<(uint, uint)>>> =
+            re.captures_iter(text).map(|c| c.iter_pos().collect()).collect();
+        assert!(got == expected, "matches differ for {}", re);
+        let found: Vec<(uint, uint)> = re.find_iter(text).collect();
+        let bounds: Vec<(uint, uint)> =
+            expected.iter().map(|c| c.get(0).unwrap()).collect();
+        assert_eq!(found, bounds);
+        assert_eq!(re.is_match(text), !expected.is_empty());
+        assert!(*calls.lock() > 0);
+
+        re.set_prefilter(~BackwardsPrefilter);
+        let found: Vec<(uint, uint)> = re.find_iter(text).collect();
+        assert_eq!(found, bounds);
+        re.remove_prefilter();
+        let found: Vec<(uint, uint)> = re.find_iter(text).collect();
+        assert_eq!(found, bounds);
+    }
+
+    // A prefilter that skips the start of a match hides the match.
+    let mut re = Regex::new(r"[0-9]").unwrap();
+    re.set_prefilter(~BytesPrefilter {
+        bytes: "5".as_bytes().to_owned(),
+        end: false,
+        calls: Arc::new(Mutex::new(0u)),
+    });
+    assert_eq!(re.find(text), Some((16, 17)));
+    assert_eq!(re.clone().find(text), Some((16, 17)));
+    // Except when it can't be used.
+    let mut re = Regex::new(r"\d\K\d").unwrap();
+    re.set_prefilter(~BytesPrefilter {
+        bytes: "5".as_bytes().to_owned(),
+        end: false,
+        calls: Arc::new(Mutex::new(0u)),
+    });
+    assert_eq!(re.find(text), Some((4, 5)));
+}
+
+#[test]
+fn pcre_translation() {
+    for &(pcre, expected) in [
+        (r"(?<y>\d+)-(?'m'\d+)", r"(?P<y>[0-9]+)-(?P<m>[0-9]+)"),
+        (r"(?P<a>x)(?P=a)\k{a}\g{a}", r"(?P<a>x)\k<a>\k<a>\k<a>"),
+        (r"(a)(b)\g{-1}\g1\g{-2}0", r"(a)(b)\2\1(?:\1)0"),
+        (r"\Qa.b\E+\Z", r"a\.b+\Z"),
+        (r"a$|(?m)b$", r"a\Z|(?m)b$"),
+        (r"(?m:x$)$", r"(?m:x$)\Z"),
+        (r"\w\b\S", r"[0-9A-Za-z_](?-u:\b)[^\t\n\x0B\x0C\r\x20]"),
+        (r"(*UCP)\w\b\S", r"\w\b\S"),
+        (r"[\d\W\b]", r"[0-9[^0-9A-Za-z_]\x08]"),
+        (r"[][a&&b--c]", r"[\][a\&\&b\-\-c]"),
+        (r"[[:alpha:]-]", r"[[:alpha:]-]"),
+        (r"\e\cA\o{101}\x41\x\101", r"\x1B\x{1}\x{41}\x{41}\x{0}\x{41}"),
+        (r"\N{U+263A}\N", r"\x{263a}[^\n]"),
+        (r"\N{SNOWMAN}[\N{U+41}]", r"\N{SNOWMAN}[\x{41}]"),
+        (r"a{2}b{2,}c{,3}d{x}", r"a{2}b{2,}c\{,3\}d\{x\}"),
+        (r"\p{^L}\P{^Lu}\pN", r"\P{L}\p{Lu}\p{N}"),
+        (r"x(?#a comment)y", r"xy"),
+        (r"(?x) a # b ( c", r"(?x) a # b ( c"),
+        (r"(?x)[ a]", r"(?x)[\x{20}a]"),
+        (r"a++(?>b)(?<=c)\K\G\A\z", r"a++(?>b)(?<=c)\K\G\A\z"),
+        (r"[\v]", r"[\n\x0B\x0C\r\x{85}\x{2028}\x{2029}]"),
+        (r"\V", r"[^\n\x0B\x0C\r\x{85}\x{2028}\x{2029}]"),
+    ].iter() {
+        match translate_pcre(pcre) {
+            Ok((got, _)) => {
+                assert!(got.as_slice() == expected,
+                        "{} translated to {}, not {}", pcre, got, expected)
+            }
+            Err(err) => fail!("{} can't be translated: {}", pcre, err),
+        }
+    }
+
+    let (_, warnings) = translate_pcre(r"a{,3}").unwrap();
+    assert_eq!(warnings.len(), 1);
+    let (_, warnings) = translate_pcre(r"(a)\12").unwrap();
+    assert_eq!(warnings.len(), 1);
+    let (_, warnings) = translate_pcre(r"(a)\1[\1]").unwrap();
+    assert!(warnings.is_empty());
+
+    for &(pcre, kind, pos) in [
+        (r"a(?R)", PcreRecursion, 1),
+        (r"(a)(?1)", PcreRecursion, 3),
+        (r"(a)(?-1)", PcreRecursion, 3),
+        (r"(?&n)", PcreRecursion, 0),
+        (r"(?P>n)", PcreRecursion, 0),
+        (r"x\g<n>", PcreRecursion, 1),
+        (r"(?(1)a|b)", PcreConditional, 0),
+        (r"a(*SKIP)b", PcreControl, 1),
+        (r"(*CRLF)a", PcreControl, 0),
+        (r"(?C1)", PcreControl, 0),
+        (r"(?|(a)|(b))", PcreUnsupported, 0),
+        (r"a\C", PcreUnsupported, 1),
+        (r"(?J)", PcreUnsupported, 2),
+        (r"(a)(b)(c)(d)(e)(f)(g)(h)(i)(j)\10", PcreUnsupported, 30),
+        (r"\g{-2}(a)", PcreSyntax, 0),
+        (r"[a", PcreSyntax, 0),
+        (r"a\", PcreSyntax, 1),
+        (r"a\N{U+41", PcreSyntax, 1),
+    ].iter() {
+        match translate_pcre(pcre) {
+            Ok((got, _)) => fail!("{} translated to {}", pcre, got),
+            Err(err) => {
+                assert_eq!((pcre, err.kind, err.pos), (pcre, kind, pos));
+            }
+        }
+    }
+
+    let (re, _) = Regex::from_pcre(r"^(\w+)(?<sep>\h+)\1$").unwrap();
+    assert!(re.is_match("ab\u00A0ab\n"));
+    assert!(!re.is_match("ab ab\n\n"));
+    assert!(!re.is_match("éé éé"));
+    let (re, _) = Regex::from_pcre(r"\N{SNOWMAN}$").unwrap();
+    assert_eq!(re.find("a\u2603\n"), Some((1, 4)));
+    // An error in the translation is reported in the pattern.
+    let err = Regex::from_pcre(r"\Qa\E\p{Foo}").unwrap_err();
+    assert_eq!((err.kind, err.pos), (PcreSyntax, 5));
+}
+
+#[test]
+fn enumerate_strings() {
+    use regex::{EnumerateInfinite, EnumerateTooMany, EnumerateUnsupported};
+    use regex::difftest::Generator;
+
+    let tests = [
+        ("a|b|a", vec!("a", "b")),
+        ("x(ab?|c)", vec!("xab", "xa", "xc")),
+        ("x(ab??|c)", vec!("xa", "xab", "xc")),
+        ("[a-c]{2}", vec!("aa", "ab", "ac", "ba", "bb", "bc",
+                          "ca", "cb", "cc")),
+        ("(?i)δ", vec!("Δ", "δ")),
+        ("(a|)(a|)", vec!("aa", "a", "")),
+        (r"^a\b ?b$", vec!("a b")),
+        (r"(?>a|ab)c", vec!("ac")),
+        (r"[^\x00-\x{10FFFD}]", vec!("\U0010FFFE", "\U0010FFFF")),
+    ];
+    for &(pat, ref want) in tests.iter() {
+        let got = Regex::new(pat).unwrap().enumerate(100).unwrap();
+        assert_eq!(got.iter().map(|s| s.as_slice()).collect::<Vec<&str>>(),
+                   *want);
+    }
+
+    let kind = |pat: &str, limit: uint| {
+        Regex::new(pat).unwrap().enumerate(limit).unwrap_err().kind
+    };
+    assert_eq!(kind("ab*", 100), EnumerateInfinite);
+    assert_eq!(kind(r"\pL+", 100), EnumerateInfinite);
+    assert_eq!(kind(r"(a)\1", 100), EnumerateUnsupported);
+    assert_eq!(kind("[ab]{3}", 7), EnumerateTooMany);
+    assert_eq!(Regex::new("[ab]{3}").unwrap().enumerate(8).unwrap().len(), 8);
+    assert_eq!(kind(r"(?s).", 1000), EnumerateTooMany);
+    assert_eq!(kind(r"\pL{2,4}", 1 << 20), EnumerateTooMany);
+
+    // Every string listed is matched, and every text that's matched is
+    // listed.
+    let mut gen = Generator::new(80);
+    for _ in range(0, 300) {
+        let pat = gen.pattern();
+        let re = match Regex::new(pat.as_slice()) {
+            Ok(re) => re,
+            Err(_) => continue,
+        };
+        let strs = match re.enumerate(500) {
+            Ok(strs) => strs,
+            Err(_) => continue,
+        };
+        for s in strs.iter() {
+            assert!(re.is_full_match(s.as_slice()), "/{}/ lists {}", pat, s);
+        }
+        for _ in range(0, 20) {
+            let text = gen.text();
+            if re.is_full_match(text.as_slice()) {
+                assert!(strs.contains(&text), "/{}/ misses {}", pat, text);
+            }
+        }
+    }
+}
+
+#[test]
+fn generate_matches() {
+    use regex::GenerateOptions;
+    use regex::difftest::Generator;
+    use std::rand::{XorShiftRng, SeedableRng};
+
+    let mut rng: XorShiftRng = SeedableRng::from_seed([1, 2, 3, 4]);
+    let opts = GenerateOptions::new();
+    let tests = [
+        r"[a-z]{3,8}@example\.(com|org)",
+        r"(?i)select \* from \w+",
+        r"^\d{3}-\d{4}$",
+        r"(\pL+) \1",
+        r"\bfoo\b|bar",
+        r"(?s).{1,3}",
+        r"(?m)^a$\n^b$",
+        r"(?=a)[ab]",
+        r"(?>a|ab)c",
+        r"[^\x00-\x{10FFFD}]",
+    ];
+    for &pat in tests.iter() {
+        let re = Regex::new(pat).unwrap();
+        for _ in range(0, 50) {
+            let s = re.generate(&mut rng, &opts).unwrap();
+            assert!(re.is_full_match(s.as_slice()), "/{}/ gave {}", pat, s);
+        }
+    }
+
+    // Every alternate and every number of repetitions is picked.
+    let re = Regex::new("a|b|c").unwrap();
+    let mut seen = Vec::from_elem(3, false);
+    for _ in range(0, 100) {
+        let s = re.generate(&mut rng, &opts).unwrap();
+        *seen.get_mut(s.char_at(0) as uint - 'a' as uint) = true;
+    }
+    assert!(seen.iter().all(|&b| b));
+    let re = Regex::new("a*").unwrap();
+    let opts3 = GenerateOptions { max_repeat: 3, retries: 0 };
+    let mut seen = Vec::from_elem(4, false);
+    for _ in range(0, 100) {
+        *seen.get_mut(re.generate(&mut rng, &opts3).unwrap().len()) = true;
+    }
+    assert!(seen.iter().all(|&b| b));
+
+    for &pat in [r"a\bb", r"(?=a)b", r"a^b", r"(?!a)a"].iter() {
+        let re = Regex::new(pat).unwrap();
+        assert!(re.generate(&mut rng, &opts).is_err(), "/{}/", pat);
+    }
+
+    let mut gen = Generator::new(81);
+    for _ in range(0, 300) {
+        let pat = gen.pattern();
+        let re = match Regex::new(pat.as_slice()) {
+            Ok(re) => re,
+            Err(_) => continue,
+        };
+        match re.generate(&mut rng, &opts) {
+            Ok(s) => {
+                assert!(re.is_full_match(s.as_slice()), "/{}/ gave {}", pat, s)
+            }
+            Err(_) => {}
+        }
+    }
+}
+
+#[test]
+fn captures_found_within_match() {
+    // The DFA finds where each match is before its groups are found, so the
+    // groups have to come out as if the NFA had searched the whole text.
+    let res: &[&str] = &[r"(a|ab)(c|bcd)(d*)", r"(a+)(b)?", r"(a*)+",
+                         r"(\w+)\s*=\s*(\w+)?", r"(?:(a)|(ab))(c)?",
+                         r"(x*)(x*)", r"\b(\w+)\b", r"(a)$", r"(?m)^(b)$",
+                         r"(a+?)(b*)", r"(?i)(δ+)(\w)?", r"(\d)|(\d\d)x"];
+    let text = "abcd aab x = y\nb\nk=  aaac xx aΔδx 12x 3 a";
+    let locs = |re: &Regex| {
+        re.captures_iter(text).map(|c| c.iter_pos().collect())
+          .collect::<Vec<Vec<Option<(uint, uint)>>>>()
+    };
+    for &longest in [false, true].iter() {
+        let opts = Options { longest: longest, ..Options::new() };
+        for &pat in res.iter() {
+            let mut nfa = Regex::with_options(pat, opts).unwrap();
+            nfa.set_dfa_size_limit(0);
+            let expected = locs(&nfa);
+            for &limit in [0u, 1 << 20, 1 << 30].iter() {
+                let mut re = Regex::with_options(pat, opts).unwrap();
+                re.set_backtrack_limit(limit);
+                assert_eq!(locs(&re), expected);
+                for i in range(0, text.len() + 1) {
+                    if !text.is_char_boundary(i) {
+                        continue
+                    }
+                    let got: Option<Vec<Option<(uint, uint)>>> =
+                        re.captures_at(text, i)
+                            .map(|c| c.iter_pos().collect());
+                    let want: Option<Vec<Option<(uint, uint)>>> =
+                        nfa.captures_at(text, i)
+                             .map(|c| c.iter_pos().collect());
+                    assert_eq!(got, want);
+                }
+            }
+        }
+    }
+}
+
+fn grep_lines(re: &Regex, text: &str, opts: &GrepOptions)
+             -> Vec<(uint, ~str, Option<(uint, uint)>)> {
+    let bytes = Vec::from_slice(text.as_bytes());
+    let mut src = Trickle { r: ::std::io::MemReader::new(bytes) };
+    let mut found = vec!();
+    let n = re.grep(&mut src, opts, |n, line, loc| {
+        found.push((n, line.to_owned(), loc));
+        true
+    }).unwrap();
+    assert_eq!(n, found.len());
+    let mut src = ::std::io::MemReader::new(Vec::from_slice(text.as_bytes()));
+    assert_eq!(re.grep_count(&mut src, opts).unwrap(), n);
+    found
+}
+
+#[test]
+fn grep_agrees_with_lines() {
+    let mut text = StrBuf::new();
+    for i in range(0u, 30000) {
+        text.push_str(["ab", "δxy", "cd\n", " TODO ", "Error", "\n"][i % 6]);
+        if i % 7 == 0 {
+            text.push_str("\n12\n");
+        }
+    }
+    text.push_str("last TODO");
+    let text = text.as_slice();
+    assert!(text.len() > 128 * 1024);
+    let lines: Vec<&str> = text.split('\n').collect();
+    for &pat in ["TODO", r"(?i)error", "^ab", "y$", "^$", r"\d+", "x*", "δ",
+                 r"TODO \w+|\d\d$", "zzz"].iter() {
+        let re = Regex::new(pat).unwrap();
+        for &invert in [false, true].iter() {
+            let opts = GrepOptions { invert: invert, ..GrepOptions::new() };
+            let want: Vec<(uint, ~str, Option<(uint, uint)>)> =
+                lines.iter().enumerate().filter_map(|(i, &line)| {
+                    let loc = re.find(line);
+                    if loc.is_some() == invert {
+                        None
+                    } else {
+                        Some((i + 1, line.to_owned(), loc))
+                    }
+                }).collect();
+            assert!(grep_lines(&re, text, &opts) == want,
+                    "grep differs for /{}/ (inverted: {})", re, invert);
+        }
+    }
+
+    // Stop after the third line.
+    let re = regex!("a");
+    let bytes = Vec::from_slice(bytes!("a\na\na\na"));
+    let mut src = ::std::io::MemReader::new(bytes);
+    let mut n = 0;
+    assert_eq!(re.grep(&mut src, &GrepOptions::new(), |_, _, _| {
+        n += 1;
+        n < 3
+    }).unwrap(), 3);
+
+    let bytes = Vec::from_slice(bytes!("a", 0xFF));
+    let mut src = ::std::io::MemReader::new(bytes);
+    assert!(re.grep_count(&mut src, &GrepOptions::new()).is_err());
+}
+
+#[test]
+fn grep_long_lines() {
+    let long = "ab".repeat(100000);
+    let text = format!("a\n{}\nb a\n{}δ{}\n{}\n{}", long, "a".repeat(999),
+                       long, "ba".repeat(2500), long);
+    let text = text.as_slice();
+    let re = regex!("a");
+    let opts = GrepOptions { max_line_len: 1000, ..GrepOptions::new() };
+    let mut src = ::std::io::MemReader::new(Vec::from_slice(text.as_bytes()));
+    assert!(re.grep_count(&mut src, &opts).is_err());
+
+    let opts = GrepOptions { long_lines: LongLinesSkip, ..opts };
+    assert_eq!(grep_lines(&re, text, &opts),
+               vec!((1, ~"a", Some((0, 1))), (3, ~"b a", Some((2, 3)))));
+    let opts = GrepOptions { invert: true, ..opts };
+    assert_eq!(grep_lines(&re, text, &opts), vec!());
+
+    let opts = GrepOptions {
+        invert: false,
+        long_lines: LongLinesTruncate,
+        ..opts
+    };
+    let found = grep_lines(&re, text, &opts);
+    let lens: Vec<(uint, uint)> =
+        found.iter().map(|&(n, ref line, _)| (n, line.len())).collect();
+    // A line isn't cut in the middle of `δ`.
+    assert_eq!(lens, vec!((1, 1), (2, 1000), (3, 3), (4, 999), (5, 1000),
+                          (6, 1000)));
+}
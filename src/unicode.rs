@@ -9,9 +9,22 @@
 // except according to those terms.
 
 // DO NOT EDIT. Automatically generated by 'src/etc/regex-unicode-tables'
-// on 2014-04-23 00:13:04.445491.
+// on 2014-04-23 00:13:04.445491,
+// from version 6.3.0 of the Unicode Character Database (and version
+// 16.0.0 for the categories of grapheme cluster and word boundaries).
 
-use parse::{Class, NamedClasses};
+use parse::{Aliases, Class, NamedClasses};
+use segment::{GraphemeCat, WordCat};
+use segment::{
+    GCR, GLF, GControl, GExtend, GConjunctExtend, GLinker, GZWJ,
+    GRegionalIndicator, GPrepend, GSpacingMark, GL, GV, GT, GLV, GLVT,
+    GExtPict, GConsonant,
+};
+use segment::{
+    WCR, WLF, WNewline, WExtend, WZWJ, WRegionalIndicator, WFormat,
+    WKatakana, WHebrewLetter, WALetter, WSingleQuote, WDoubleQuote,
+    WMidNumLet, WMidLetter, WMidNum, WNumeric, WExtendNumLet, WWSegSpace,
+};
 
 pub static UNICODE_CLASSES: NamedClasses = &[
 
@@ -5535,3 +5548,7126 @@ pub static PERLW: Class = &[
     ('\U0002f800', '\U0002fa1d')
 ];
 
+pub static UNICODE_WORD: Class = &[
+    ('\U00000030', '\U00000039'),
+    ('\U00000041', '\U0000005a'),
+    ('\U0000005f', '\U0000005f'),
+    ('\U00000061', '\U0000007a'),
+    ('\U000000aa', '\U000000aa'),
+    ('\U000000b5', '\U000000b5'),
+    ('\U000000ba', '\U000000ba'),
+    ('\U000000c0', '\U000000d6'),
+    ('\U000000d8', '\U000000f6'),
+    ('\U000000f8', '\U000002c1'),
+    ('\U000002c6', '\U000002d1'),
+    ('\U000002e0', '\U000002e4'),
+    ('\U000002ec', '\U000002ec'),
+    ('\U000002ee', '\U000002ee'),
+    ('\U00000300', '\U00000374'),
+    ('\U00000376', '\U00000377'),
+    ('\U0000037a', '\U0000037d'),
+    ('\U00000386', '\U00000386'),
+    ('\U00000388', '\U0000038a'),
+    ('\U0000038c', '\U0000038c'),
+    ('\U0000038e', '\U000003a1'),
+    ('\U000003a3', '\U000003f5'),
+    ('\U000003f7', '\U00000481'),
+    ('\U00000483', '\U00000527'),
+    ('\U00000531', '\U00000556'),
+    ('\U00000559', '\U00000559'),
+    ('\U00000561', '\U00000587'),
+    ('\U00000591', '\U000005bd'),
+    ('\U000005bf', '\U000005bf'),
+    ('\U000005c1', '\U000005c2'),
+    ('\U000005c4', '\U000005c5'),
+    ('\U000005c7', '\U000005c7'),
+    ('\U000005d0', '\U000005ea'),
+    ('\U000005f0', '\U000005f2'),
+    ('\U00000610', '\U0000061a'),
+    ('\U00000620', '\U00000669'),
+    ('\U0000066e', '\U000006d3'),
+    ('\U000006d5', '\U000006dc'),
+    ('\U000006df', '\U000006e8'),
+    ('\U000006ea', '\U000006fc'),
+    ('\U000006ff', '\U000006ff'),
+    ('\U00000710', '\U0000074a'),
+    ('\U0000074d', '\U000007b1'),
+    ('\U000007c0', '\U000007f5'),
+    ('\U000007fa', '\U000007fa'),
+    ('\U00000800', '\U0000082d'),
+    ('\U00000840', '\U0000085b'),
+    ('\U000008a0', '\U000008a0'),
+    ('\U000008a2', '\U000008ac'),
+    ('\U000008e4', '\U000008fe'),
+    ('\U00000900', '\U00000963'),
+    ('\U00000966', '\U0000096f'),
+    ('\U00000971', '\U00000977'),
+    ('\U00000979', '\U0000097f'),
+    ('\U00000981', '\U00000983'),
+    ('\U00000985', '\U0000098c'),
+    ('\U0000098f', '\U00000990'),
+    ('\U00000993', '\U000009a8'),
+    ('\U000009aa', '\U000009b0'),
+    ('\U000009b2', '\U000009b2'),
+    ('\U000009b6', '\U000009b9'),
+    ('\U000009bc', '\U000009c4'),
+    ('\U000009c7', '\U000009c8'),
+    ('\U000009cb', '\U000009ce'),
+    ('\U000009d7', '\U000009d7'),
+    ('\U000009dc', '\U000009dd'),
+    ('\U000009df', '\U000009e3'),
+    ('\U000009e6', '\U000009f1'),
+    ('\U00000a01', '\U00000a03'),
+    ('\U00000a05', '\U00000a0a'),
+    ('\U00000a0f', '\U00000a10'),
+    ('\U00000a13', '\U00000a28'),
+    ('\U00000a2a', '\U00000a30'),
+    ('\U00000a32', '\U00000a33'),
+    ('\U00000a35', '\U00000a36'),
+    ('\U00000a38', '\U00000a39'),
+    ('\U00000a3c', '\U00000a3c'),
+    ('\U00000a3e', '\U00000a42'),
+    ('\U00000a47', '\U00000a48'),
+    ('\U00000a4b', '\U00000a4d'),
+    ('\U00000a51', '\U00000a51'),
+    ('\U00000a59', '\U00000a5c'),
+    ('\U00000a5e', '\U00000a5e'),
+    ('\U00000a66', '\U00000a75'),
+    ('\U00000a81', '\U00000a83'),
+    ('\U00000a85', '\U00000a8d'),
+    ('\U00000a8f', '\U00000a91'),
+    ('\U00000a93', '\U00000aa8'),
+    ('\U00000aaa', '\U00000ab0'),
+    ('\U00000ab2', '\U00000ab3'),
+    ('\U00000ab5', '\U00000ab9'),
+    ('\U00000abc', '\U00000ac5'),
+    ('\U00000ac7', '\U00000ac9'),
+    ('\U00000acb', '\U00000acd'),
+    ('\U00000ad0', '\U00000ad0'),
+    ('\U00000ae0', '\U00000ae3'),
+    ('\U00000ae6', '\U00000aef'),
+    ('\U00000b01', '\U00000b03'),
+    ('\U00000b05', '\U00000b0c'),
+    ('\U00000b0f', '\U00000b10'),
+    ('\U00000b13', '\U00000b28'),
+    ('\U00000b2a', '\U00000b30'),
+    ('\U00000b32', '\U00000b33'),
+    ('\U00000b35', '\U00000b39'),
+    ('\U00000b3c', '\U00000b44'),
+    ('\U00000b47', '\U00000b48'),
+    ('\U00000b4b', '\U00000b4d'),
+    ('\U00000b56', '\U00000b57'),
+    ('\U00000b5c', '\U00000b5d'),
+    ('\U00000b5f', '\U00000b63'),
+    ('\U00000b66', '\U00000b6f'),
+    ('\U00000b71', '\U00000b71'),
+    ('\U00000b82', '\U00000b83'),
+    ('\U00000b85', '\U00000b8a'),
+    ('\U00000b8e', '\U00000b90'),
+    ('\U00000b92', '\U00000b95'),
+    ('\U00000b99', '\U00000b9a'),
+    ('\U00000b9c', '\U00000b9c'),
+    ('\U00000b9e', '\U00000b9f'),
+    ('\U00000ba3', '\U00000ba4'),
+    ('\U00000ba8', '\U00000baa'),
+    ('\U00000bae', '\U00000bb9'),
+    ('\U00000bbe', '\U00000bc2'),
+    ('\U00000bc6', '\U00000bc8'),
+    ('\U00000bca', '\U00000bcd'),
+    ('\U00000bd0', '\U00000bd0'),
+    ('\U00000bd7', '\U00000bd7'),
+    ('\U00000be6', '\U00000bef'),
+    ('\U00000c01', '\U00000c03'),
+    ('\U00000c05', '\U00000c0c'),
+    ('\U00000c0e', '\U00000c10'),
+    ('\U00000c12', '\U00000c28'),
+    ('\U00000c2a', '\U00000c33'),
+    ('\U00000c35', '\U00000c39'),
+    ('\U00000c3d', '\U00000c44'),
+    ('\U00000c46', '\U00000c48'),
+    ('\U00000c4a', '\U00000c4d'),
+    ('\U00000c55', '\U00000c56'),
+    ('\U00000c58', '\U00000c59'),
+    ('\U00000c60', '\U00000c63'),
+    ('\U00000c66', '\U00000c6f'),
+    ('\U00000c82', '\U00000c83'),
+    ('\U00000c85', '\U00000c8c'),
+    ('\U00000c8e', '\U00000c90'),
+    ('\U00000c92', '\U00000ca8'),
+    ('\U00000caa', '\U00000cb3'),
+    ('\U00000cb5', '\U00000cb9'),
+    ('\U00000cbc', '\U00000cc4'),
+    ('\U00000cc6', '\U00000cc8'),
+    ('\U00000cca', '\U00000ccd'),
+    ('\U00000cd5', '\U00000cd6'),
+    ('\U00000cde', '\U00000cde'),
+    ('\U00000ce0', '\U00000ce3'),
+    ('\U00000ce6', '\U00000cef'),
+    ('\U00000cf1', '\U00000cf2'),
+    ('\U00000d02', '\U00000d03'),
+    ('\U00000d05', '\U00000d0c'),
+    ('\U00000d0e', '\U00000d10'),
+    ('\U00000d12', '\U00000d3a'),
+    ('\U00000d3d', '\U00000d44'),
+    ('\U00000d46', '\U00000d48'),
+    ('\U00000d4a', '\U00000d4e'),
+    ('\U00000d57', '\U00000d57'),
+    ('\U00000d60', '\U00000d63'),
+    ('\U00000d66', '\U00000d6f'),
+    ('\U00000d7a', '\U00000d7f'),
+    ('\U00000d82', '\U00000d83'),
+    ('\U00000d85', '\U00000d96'),
+    ('\U00000d9a', '\U00000db1'),
+    ('\U00000db3', '\U00000dbb'),
+    ('\U00000dbd', '\U00000dbd'),
+    ('\U00000dc0', '\U00000dc6'),
+    ('\U00000dca', '\U00000dca'),
+    ('\U00000dcf', '\U00000dd4'),
+    ('\U00000dd6', '\U00000dd6'),
+    ('\U00000dd8', '\U00000ddf'),
+    ('\U00000df2', '\U00000df3'),
+    ('\U00000e01', '\U00000e3a'),
+    ('\U00000e40', '\U00000e4e'),
+    ('\U00000e50', '\U00000e59'),
+    ('\U00000e81', '\U00000e82'),
+    ('\U00000e84', '\U00000e84'),
+    ('\U00000e87', '\U00000e88'),
+    ('\U00000e8a', '\U00000e8a'),
+    ('\U00000e8d', '\U00000e8d'),
+    ('\U00000e94', '\U00000e97'),
+    ('\U00000e99', '\U00000e9f'),
+    ('\U00000ea1', '\U00000ea3'),
+    ('\U00000ea5', '\U00000ea5'),
+    ('\U00000ea7', '\U00000ea7'),
+    ('\U00000eaa', '\U00000eab'),
+    ('\U00000ead', '\U00000eb9'),
+    ('\U00000ebb', '\U00000ebd'),
+    ('\U00000ec0', '\U00000ec4'),
+    ('\U00000ec6', '\U00000ec6'),
+    ('\U00000ec8', '\U00000ecd'),
+    ('\U00000ed0', '\U00000ed9'),
+    ('\U00000edc', '\U00000edf'),
+    ('\U00000f00', '\U00000f00'),
+    ('\U00000f18', '\U00000f19'),
+    ('\U00000f20', '\U00000f29'),
+    ('\U00000f35', '\U00000f35'),
+    ('\U00000f37', '\U00000f37'),
+    ('\U00000f39', '\U00000f39'),
+    ('\U00000f3e', '\U00000f47'),
+    ('\U00000f49', '\U00000f6c'),
+    ('\U00000f71', '\U00000f84'),
+    ('\U00000f86', '\U00000f97'),
+    ('\U00000f99', '\U00000fbc'),
+    ('\U00000fc6', '\U00000fc6'),
+    ('\U00001000', '\U00001049'),
+    ('\U00001050', '\U0000109d'),
+    ('\U000010a0', '\U000010c5'),
+    ('\U000010c7', '\U000010c7'),
+    ('\U000010cd', '\U000010cd'),
+    ('\U000010d0', '\U000010fa'),
+    ('\U000010fc', '\U00001248'),
+    ('\U0000124a', '\U0000124d'),
+    ('\U00001250', '\U00001256'),
+    ('\U00001258', '\U00001258'),
+    ('\U0000125a', '\U0000125d'),
+    ('\U00001260', '\U00001288'),
+    ('\U0000128a', '\U0000128d'),
+    ('\U00001290', '\U000012b0'),
+    ('\U000012b2', '\U000012b5'),
+    ('\U000012b8', '\U000012be'),
+    ('\U000012c0', '\U000012c0'),
+    ('\U000012c2', '\U000012c5'),
+    ('\U000012c8', '\U000012d6'),
+    ('\U000012d8', '\U00001310'),
+    ('\U00001312', '\U00001315'),
+    ('\U00001318', '\U0000135a'),
+    ('\U0000135d', '\U0000135f'),
+    ('\U00001380', '\U0000138f'),
+    ('\U000013a0', '\U000013f4'),
+    ('\U00001401', '\U0000166c'),
+    ('\U0000166f', '\U0000167f'),
+    ('\U00001681', '\U0000169a'),
+    ('\U000016a0', '\U000016ea'),
+    ('\U000016ee', '\U000016f0'),
+    ('\U00001700', '\U0000170c'),
+    ('\U0000170e', '\U00001714'),
+    ('\U00001720', '\U00001734'),
+    ('\U00001740', '\U00001753'),
+    ('\U00001760', '\U0000176c'),
+    ('\U0000176e', '\U00001770'),
+    ('\U00001772', '\U00001773'),
+    ('\U00001780', '\U000017d3'),
+    ('\U000017d7', '\U000017d7'),
+    ('\U000017dc', '\U000017dd'),
+    ('\U000017e0', '\U000017e9'),
+    ('\U0000180b', '\U0000180d'),
+    ('\U00001810', '\U00001819'),
+    ('\U00001820', '\U00001877'),
+    ('\U00001880', '\U000018aa'),
+    ('\U000018b0', '\U000018f5'),
+    ('\U00001900', '\U0000191c'),
+    ('\U00001920', '\U0000192b'),
+    ('\U00001930', '\U0000193b'),
+    ('\U00001946', '\U0000196d'),
+    ('\U00001970', '\U00001974'),
+    ('\U00001980', '\U000019ab'),
+    ('\U000019b0', '\U000019c9'),
+    ('\U000019d0', '\U000019d9'),
+    ('\U00001a00', '\U00001a1b'),
+    ('\U00001a20', '\U00001a5e'),
+    ('\U00001a60', '\U00001a7c'),
+    ('\U00001a7f', '\U00001a89'),
+    ('\U00001a90', '\U00001a99'),
+    ('\U00001aa7', '\U00001aa7'),
+    ('\U00001b00', '\U00001b4b'),
+    ('\U00001b50', '\U00001b59'),
+    ('\U00001b6b', '\U00001b73'),
+    ('\U00001b80', '\U00001bf3'),
+    ('\U00001c00', '\U00001c37'),
+    ('\U00001c40', '\U00001c49'),
+    ('\U00001c4d', '\U00001c7d'),
+    ('\U00001cd0', '\U00001cd2'),
+    ('\U00001cd4', '\U00001cf6'),
+    ('\U00001d00', '\U00001de6'),
+    ('\U00001dfc', '\U00001f15'),
+    ('\U00001f18', '\U00001f1d'),
+    ('\U00001f20', '\U00001f45'),
+    ('\U00001f48', '\U00001f4d'),
+    ('\U00001f50', '\U00001f57'),
+    ('\U00001f59', '\U00001f59'),
+    ('\U00001f5b', '\U00001f5b'),
+    ('\U00001f5d', '\U00001f5d'),
+    ('\U00001f5f', '\U00001f7d'),
+    ('\U00001f80', '\U00001fb4'),
+    ('\U00001fb6', '\U00001fbc'),
+    ('\U00001fbe', '\U00001fbe'),
+    ('\U00001fc2', '\U00001fc4'),
+    ('\U00001fc6', '\U00001fcc'),
+    ('\U00001fd0', '\U00001fd3'),
+    ('\U00001fd6', '\U00001fdb'),
+    ('\U00001fe0', '\U00001fec'),
+    ('\U00001ff2', '\U00001ff4'),
+    ('\U00001ff6', '\U00001ffc'),
+    ('\U0000203f', '\U00002040'),
+    ('\U00002054', '\U00002054'),
+    ('\U00002071', '\U00002071'),
+    ('\U0000207f', '\U0000207f'),
+    ('\U00002090', '\U0000209c'),
+    ('\U000020d0', '\U000020f0'),
+    ('\U00002102', '\U00002102'),
+    ('\U00002107', '\U00002107'),
+    ('\U0000210a', '\U00002113'),
+    ('\U00002115', '\U00002115'),
+    ('\U00002119', '\U0000211d'),
+    ('\U00002124', '\U00002124'),
+    ('\U00002126', '\U00002126'),
+    ('\U00002128', '\U00002128'),
+    ('\U0000212a', '\U0000212d'),
+    ('\U0000212f', '\U00002139'),
+    ('\U0000213c', '\U0000213f'),
+    ('\U00002145', '\U00002149'),
+    ('\U0000214e', '\U0000214e'),
+    ('\U00002160', '\U00002188'),
+    ('\U00002c00', '\U00002c2e'),
+    ('\U00002c30', '\U00002c5e'),
+    ('\U00002c60', '\U00002ce4'),
+    ('\U00002ceb', '\U00002cf3'),
+    ('\U00002d00', '\U00002d25'),
+    ('\U00002d27', '\U00002d27'),
+    ('\U00002d2d', '\U00002d2d'),
+    ('\U00002d30', '\U00002d67'),
+    ('\U00002d6f', '\U00002d6f'),
+    ('\U00002d7f', '\U00002d96'),
+    ('\U00002da0', '\U00002da6'),
+    ('\U00002da8', '\U00002dae'),
+    ('\U00002db0', '\U00002db6'),
+    ('\U00002db8', '\U00002dbe'),
+    ('\U00002dc0', '\U00002dc6'),
+    ('\U00002dc8', '\U00002dce'),
+    ('\U00002dd0', '\U00002dd6'),
+    ('\U00002dd8', '\U00002dde'),
+    ('\U00002de0', '\U00002dff'),
+    ('\U00002e2f', '\U00002e2f'),
+    ('\U00003005', '\U00003007'),
+    ('\U00003021', '\U0000302f'),
+    ('\U00003031', '\U00003035'),
+    ('\U00003038', '\U0000303c'),
+    ('\U00003041', '\U00003096'),
+    ('\U00003099', '\U0000309a'),
+    ('\U0000309d', '\U0000309f'),
+    ('\U000030a1', '\U000030fa'),
+    ('\U000030fc', '\U000030ff'),
+    ('\U00003105', '\U0000312d'),
+    ('\U00003131', '\U0000318e'),
+    ('\U000031a0', '\U000031ba'),
+    ('\U000031f0', '\U000031ff'),
+    ('\U00003400', '\U00003400'),
+    ('\U00004db5', '\U00004db5'),
+    ('\U00004e00', '\U00004e00'),
+    ('\U00009fcc', '\U00009fcc'),
+    ('\U0000a000', '\U0000a48c'),
+    ('\U0000a4d0', '\U0000a4fd'),
+    ('\U0000a500', '\U0000a60c'),
+    ('\U0000a610', '\U0000a62b'),
+    ('\U0000a640', '\U0000a672'),
+    ('\U0000a674', '\U0000a67d'),
+    ('\U0000a67f', '\U0000a697'),
+    ('\U0000a69f', '\U0000a6f1'),
+    ('\U0000a717', '\U0000a71f'),
+    ('\U0000a722', '\U0000a788'),
+    ('\U0000a78b', '\U0000a78e'),
+    ('\U0000a790', '\U0000a793'),
+    ('\U0000a7a0', '\U0000a7aa'),
+    ('\U0000a7f8', '\U0000a827'),
+    ('\U0000a840', '\U0000a873'),
+    ('\U0000a880', '\U0000a8c4'),
+    ('\U0000a8d0', '\U0000a8d9'),
+    ('\U0000a8e0', '\U0000a8f7'),
+    ('\U0000a8fb', '\U0000a8fb'),
+    ('\U0000a900', '\U0000a92d'),
+    ('\U0000a930', '\U0000a953'),
+    ('\U0000a960', '\U0000a97c'),
+    ('\U0000a980', '\U0000a9c0'),
+    ('\U0000a9cf', '\U0000a9d9'),
+    ('\U0000aa00', '\U0000aa36'),
+    ('\U0000aa40', '\U0000aa4d'),
+    ('\U0000aa50', '\U0000aa59'),
+    ('\U0000aa60', '\U0000aa76'),
+    ('\U0000aa7a', '\U0000aa7b'),
+    ('\U0000aa80', '\U0000aac2'),
+    ('\U0000aadb', '\U0000aadd'),
+    ('\U0000aae0', '\U0000aaef'),
+    ('\U0000aaf2', '\U0000aaf6'),
+    ('\U0000ab01', '\U0000ab06'),
+    ('\U0000ab09', '\U0000ab0e'),
+    ('\U0000ab11', '\U0000ab16'),
+    ('\U0000ab20', '\U0000ab26'),
+    ('\U0000ab28', '\U0000ab2e'),
+    ('\U0000abc0', '\U0000abea'),
+    ('\U0000abec', '\U0000abed'),
+    ('\U0000abf0', '\U0000abf9'),
+    ('\U0000ac00', '\U0000ac00'),
+    ('\U0000d7a3', '\U0000d7a3'),
+    ('\U0000d7b0', '\U0000d7c6'),
+    ('\U0000d7cb', '\U0000d7fb'),
+    ('\U0000f900', '\U0000fa6d'),
+    ('\U0000fa70', '\U0000fad9'),
+    ('\U0000fb00', '\U0000fb06'),
+    ('\U0000fb13', '\U0000fb17'),
+    ('\U0000fb1d', '\U0000fb28'),
+    ('\U0000fb2a', '\U0000fb36'),
+    ('\U0000fb38', '\U0000fb3c'),
+    ('\U0000fb3e', '\U0000fb3e'),
+    ('\U0000fb40', '\U0000fb41'),
+    ('\U0000fb43', '\U0000fb44'),
+    ('\U0000fb46', '\U0000fbb1'),
+    ('\U0000fbd3', '\U0000fd3d'),
+    ('\U0000fd50', '\U0000fd8f'),
+    ('\U0000fd92', '\U0000fdc7'),
+    ('\U0000fdf0', '\U0000fdfb'),
+    ('\U0000fe00', '\U0000fe0f'),
+    ('\U0000fe20', '\U0000fe26'),
+    ('\U0000fe33', '\U0000fe34'),
+    ('\U0000fe4d', '\U0000fe4f'),
+    ('\U0000fe70', '\U0000fe74'),
+    ('\U0000fe76', '\U0000fefc'),
+    ('\U0000ff10', '\U0000ff19'),
+    ('\U0000ff21', '\U0000ff3a'),
+    ('\U0000ff3f', '\U0000ff3f'),
+    ('\U0000ff41', '\U0000ff5a'),
+    ('\U0000ff66', '\U0000ffbe'),
+    ('\U0000ffc2', '\U0000ffc7'),
+    ('\U0000ffca', '\U0000ffcf'),
+    ('\U0000ffd2', '\U0000ffd7'),
+    ('\U0000ffda', '\U0000ffdc'),
+    ('\U00010000', '\U0001000b'),
+    ('\U0001000d', '\U00010026'),
+    ('\U00010028', '\U0001003a'),
+    ('\U0001003c', '\U0001003d'),
+    ('\U0001003f', '\U0001004d'),
+    ('\U00010050', '\U0001005d'),
+    ('\U00010080', '\U000100fa'),
+    ('\U00010140', '\U00010174'),
+    ('\U000101fd', '\U000101fd'),
+    ('\U00010280', '\U0001029c'),
+    ('\U000102a0', '\U000102d0'),
+    ('\U00010300', '\U0001031e'),
+    ('\U00010330', '\U0001034a'),
+    ('\U00010380', '\U0001039d'),
+    ('\U000103a0', '\U000103c3'),
+    ('\U000103c8', '\U000103cf'),
+    ('\U000103d1', '\U000103d5'),
+    ('\U00010400', '\U0001049d'),
+    ('\U000104a0', '\U000104a9'),
+    ('\U00010800', '\U00010805'),
+    ('\U00010808', '\U00010808'),
+    ('\U0001080a', '\U00010835'),
+    ('\U00010837', '\U00010838'),
+    ('\U0001083c', '\U0001083c'),
+    ('\U0001083f', '\U00010855'),
+    ('\U00010900', '\U00010915'),
+    ('\U00010920', '\U00010939'),
+    ('\U00010980', '\U000109b7'),
+    ('\U000109be', '\U000109bf'),
+    ('\U00010a00', '\U00010a03'),
+    ('\U00010a05', '\U00010a06'),
+    ('\U00010a0c', '\U00010a13'),
+    ('\U00010a15', '\U00010a17'),
+    ('\U00010a19', '\U00010a33'),
+    ('\U00010a38', '\U00010a3a'),
+    ('\U00010a3f', '\U00010a3f'),
+    ('\U00010a60', '\U00010a7c'),
+    ('\U00010b00', '\U00010b35'),
+    ('\U00010b40', '\U00010b55'),
+    ('\U00010b60', '\U00010b72'),
+    ('\U00010c00', '\U00010c48'),
+    ('\U00011000', '\U00011046'),
+    ('\U00011066', '\U0001106f'),
+    ('\U00011080', '\U000110ba'),
+    ('\U000110d0', '\U000110e8'),
+    ('\U000110f0', '\U000110f9'),
+    ('\U00011100', '\U00011134'),
+    ('\U00011136', '\U0001113f'),
+    ('\U00011180', '\U000111c4'),
+    ('\U000111d0', '\U000111d9'),
+    ('\U00011680', '\U000116b7'),
+    ('\U000116c0', '\U000116c9'),
+    ('\U00012000', '\U0001236e'),
+    ('\U00012400', '\U00012462'),
+    ('\U00013000', '\U0001342e'),
+    ('\U00016800', '\U00016a38'),
+    ('\U00016f00', '\U00016f44'),
+    ('\U00016f50', '\U00016f7e'),
+    ('\U00016f8f', '\U00016f9f'),
+    ('\U0001b000', '\U0001b001'),
+    ('\U0001d165', '\U0001d169'),
+    ('\U0001d16d', '\U0001d172'),
+    ('\U0001d17b', '\U0001d182'),
+    ('\U0001d185', '\U0001d18b'),
+    ('\U0001d1aa', '\U0001d1ad'),
+    ('\U0001d242', '\U0001d244'),
+    ('\U0001d400', '\U0001d454'),
+    ('\U0001d456', '\U0001d49c'),
+    ('\U0001d49e', '\U0001d49f'),
+    ('\U0001d4a2', '\U0001d4a2'),
+    ('\U0001d4a5', '\U0001d4a6'),
+    ('\U0001d4a9', '\U0001d4ac'),
+    ('\U0001d4ae', '\U0001d4b9'),
+    ('\U0001d4bb', '\U0001d4bb'),
+    ('\U0001d4bd', '\U0001d4c3'),
+    ('\U0001d4c5', '\U0001d505'),
+    ('\U0001d507', '\U0001d50a'),
+    ('\U0001d50d', '\U0001d514'),
+    ('\U0001d516', '\U0001d51c'),
+    ('\U0001d51e', '\U0001d539'),
+    ('\U0001d53b', '\U0001d53e'),
+    ('\U0001d540', '\U0001d544'),
+    ('\U0001d546', '\U0001d546'),
+    ('\U0001d54a', '\U0001d550'),
+    ('\U0001d552', '\U0001d6a5'),
+    ('\U0001d6a8', '\U0001d6c0'),
+    ('\U0001d6c2', '\U0001d6da'),
+    ('\U0001d6dc', '\U0001d6fa'),
+    ('\U0001d6fc', '\U0001d714'),
+    ('\U0001d716', '\U0001d734'),
+    ('\U0001d736', '\U0001d74e'),
+    ('\U0001d750', '\U0001d76e'),
+    ('\U0001d770', '\U0001d788'),
+    ('\U0001d78a', '\U0001d7a8'),
+    ('\U0001d7aa', '\U0001d7c2'),
+    ('\U0001d7c4', '\U0001d7cb'),
+    ('\U0001d7ce', '\U0001d7ff'),
+    ('\U0001ee00', '\U0001ee03'),
+    ('\U0001ee05', '\U0001ee1f'),
+    ('\U0001ee21', '\U0001ee22'),
+    ('\U0001ee24', '\U0001ee24'),
+    ('\U0001ee27', '\U0001ee27'),
+    ('\U0001ee29', '\U0001ee32'),
+    ('\U0001ee34', '\U0001ee37'),
+    ('\U0001ee39', '\U0001ee39'),
+    ('\U0001ee3b', '\U0001ee3b'),
+    ('\U0001ee42', '\U0001ee42'),
+    ('\U0001ee47', '\U0001ee47'),
+    ('\U0001ee49', '\U0001ee49'),
+    ('\U0001ee4b', '\U0001ee4b'),
+    ('\U0001ee4d', '\U0001ee4f'),
+    ('\U0001ee51', '\U0001ee52'),
+    ('\U0001ee54', '\U0001ee54'),
+    ('\U0001ee57', '\U0001ee57'),
+    ('\U0001ee59', '\U0001ee59'),
+    ('\U0001ee5b', '\U0001ee5b'),
+    ('\U0001ee5d', '\U0001ee5d'),
+    ('\U0001ee5f', '\U0001ee5f'),
+    ('\U0001ee61', '\U0001ee62'),
+    ('\U0001ee64', '\U0001ee64'),
+    ('\U0001ee67', '\U0001ee6a'),
+    ('\U0001ee6c', '\U0001ee72'),
+    ('\U0001ee74', '\U0001ee77'),
+    ('\U0001ee79', '\U0001ee7c'),
+    ('\U0001ee7e', '\U0001ee7e'),
+    ('\U0001ee80', '\U0001ee89'),
+    ('\U0001ee8b', '\U0001ee9b'),
+    ('\U0001eea1', '\U0001eea3'),
+    ('\U0001eea5', '\U0001eea9'),
+    ('\U0001eeab', '\U0001eebb'),
+    ('\U00020000', '\U00020000'),
+    ('\U0002a6d6', '\U0002a6d6'),
+    ('\U0002a700', '\U0002a700'),
+    ('\U0002b734', '\U0002b734'),
+    ('\U0002b740', '\U0002b740'),
+    ('\U0002b81d', '\U0002b81d'),
+    ('\U0002f800', '\U0002fa1d'),
+    ('\U000e0100', '\U000e01ef')
+];
+
+// The categories of grapheme cluster boundaries (`\b{g}`), from Unicode
+// 16.0.0. Characters that aren't listed are `GOther`.
+pub static GRAPHEME_CATS: &'static [(char, char, GraphemeCat)] = &[
+    ('\U00000000', '\U00000009', GControl),
+    ('\U0000000a', '\U0000000a', GLF),
+    ('\U0000000b', '\U0000000c', GControl),
+    ('\U0000000d', '\U0000000d', GCR),
+    ('\U0000000e', '\U0000001f', GControl),
+    ('\U0000007f', '\U0000009f', GControl),
+    ('\U000000a9', '\U000000a9', GExtPict),
+    ('\U000000ad', '\U000000ad', GControl),
+    ('\U000000ae', '\U000000ae', GExtPict),
+    ('\U00000300', '\U0000036f', GConjunctExtend),
+    ('\U00000483', '\U00000489', GConjunctExtend),
+    ('\U00000591', '\U000005bd', GConjunctExtend),
+    ('\U000005bf', '\U000005bf', GExtend),
+    ('\U000005c1', '\U000005c2', GConjunctExtend),
+    ('\U000005c4', '\U000005c5', GConjunctExtend),
+    ('\U000005c7', '\U000005c7', GConjunctExtend),
+    ('\U00000600', '\U00000605', GPrepend),
+    ('\U00000610', '\U0000061a', GConjunctExtend),
+    ('\U0000061c', '\U0000061c', GControl),
+    ('\U0000064b', '\U0000065f', GConjunctExtend),
+    ('\U00000670', '\U00000670', GConjunctExtend),
+    ('\U000006d6', '\U000006dc', GExtend),
+    ('\U000006dd', '\U000006dd', GPrepend),
+    ('\U000006df', '\U000006e4', GConjunctExtend),
+    ('\U000006e7', '\U000006e8', GConjunctExtend),
+    ('\U000006ea', '\U000006ed', GConjunctExtend),
+    ('\U0000070f', '\U0000070f', GPrepend),
+    ('\U00000711', '\U00000711', GConjunctExtend),
+    ('\U00000730', '\U0000074a', GConjunctExtend),
+    ('\U000007a6', '\U000007b0', GConjunctExtend),
+    ('\U000007eb', '\U000007f3', GExtend),
+    ('\U000007fd', '\U000007fd', GConjunctExtend),
+    ('\U00000816', '\U00000819', GConjunctExtend),
+    ('\U0000081b', '\U00000823', GConjunctExtend),
+    ('\U00000825', '\U00000827', GConjunctExtend),
+    ('\U00000829', '\U0000082d', GConjunctExtend),
+    ('\U00000859', '\U0000085b', GConjunctExtend),
+    ('\U00000890', '\U00000891', GPrepend),
+    ('\U00000897', '\U0000089f', GExtend),
+    ('\U000008ca', '\U000008e1', GConjunctExtend),
+    ('\U000008e2', '\U000008e2', GPrepend),
+    ('\U000008e3', '\U00000902', GConjunctExtend),
+    ('\U00000903', '\U00000903', GSpacingMark),
+    ('\U00000915', '\U00000939', GConsonant),
+    ('\U0000093a', '\U0000093a', GConjunctExtend),
+    ('\U0000093b', '\U0000093b', GSpacingMark),
+    ('\U0000093c', '\U0000093c', GConjunctExtend),
+    ('\U0000093e', '\U00000940', GSpacingMark),
+    ('\U00000941', '\U00000948', GConjunctExtend),
+    ('\U00000949', '\U0000094c', GSpacingMark),
+    ('\U0000094d', '\U0000094d', GLinker),
+    ('\U0000094e', '\U0000094f', GSpacingMark),
+    ('\U00000951', '\U00000957', GConjunctExtend),
+    ('\U00000958', '\U0000095f', GConsonant),
+    ('\U00000962', '\U00000963', GExtend),
+    ('\U00000978', '\U0000097f', GConsonant),
+    ('\U00000981', '\U00000981', GConjunctExtend),
+    ('\U00000982', '\U00000983', GSpacingMark),
+    ('\U00000995', '\U000009a8', GConsonant),
+    ('\U000009aa', '\U000009b0', GConsonant),
+    ('\U000009b2', '\U000009b2', GConsonant),
+    ('\U000009b6', '\U000009b9', GConsonant),
+    ('\U000009bc', '\U000009bc', GConjunctExtend),
+    ('\U000009be', '\U000009be', GConjunctExtend),
+    ('\U000009bf', '\U000009c0', GSpacingMark),
+    ('\U000009c1', '\U000009c4', GConjunctExtend),
+    ('\U000009c7', '\U000009c8', GSpacingMark),
+    ('\U000009cb', '\U000009cc', GSpacingMark),
+    ('\U000009cd', '\U000009cd', GLinker),
+    ('\U000009d7', '\U000009d7', GConjunctExtend),
+    ('\U000009dc', '\U000009dd', GConsonant),
+    ('\U000009df', '\U000009df', GConsonant),
+    ('\U000009e2', '\U000009e3', GConjunctExtend),
+    ('\U000009f0', '\U000009f1', GConsonant),
+    ('\U000009fe', '\U000009fe', GExtend),
+    ('\U00000a01', '\U00000a02', GConjunctExtend),
+    ('\U00000a03', '\U00000a03', GSpacingMark),
+    ('\U00000a3c', '\U00000a3c', GConjunctExtend),
+    ('\U00000a3e', '\U00000a40', GSpacingMark),
+    ('\U00000a41', '\U00000a42', GConjunctExtend),
+    ('\U00000a47', '\U00000a48', GConjunctExtend),
+    ('\U00000a4b', '\U00000a4d', GConjunctExtend),
+    ('\U00000a51', '\U00000a51', GConjunctExtend),
+    ('\U00000a70', '\U00000a71', GExtend),
+    ('\U00000a75', '\U00000a75', GConjunctExtend),
+    ('\U00000a81', '\U00000a82', GConjunctExtend),
+    ('\U00000a83', '\U00000a83', GSpacingMark),
+    ('\U00000a95', '\U00000aa8', GConsonant),
+    ('\U00000aaa', '\U00000ab0', GConsonant),
+    ('\U00000ab2', '\U00000ab3', GConsonant),
+    ('\U00000ab5', '\U00000ab9', GConsonant),
+    ('\U00000abc', '\U00000abc', GConjunctExtend),
+    ('\U00000abe', '\U00000ac0', GSpacingMark),
+    ('\U00000ac1', '\U00000ac5', GConjunctExtend),
+    ('\U00000ac7', '\U00000ac8', GConjunctExtend),
+    ('\U00000ac9', '\U00000ac9', GSpacingMark),
+    ('\U00000acb', '\U00000acc', GSpacingMark),
+    ('\U00000acd', '\U00000acd', GLinker),
+    ('\U00000ae2', '\U00000ae3', GConjunctExtend),
+    ('\U00000af9', '\U00000af9', GConsonant),
+    ('\U00000afa', '\U00000aff', GExtend),
+    ('\U00000b01', '\U00000b01', GConjunctExtend),
+    ('\U00000b02', '\U00000b03', GSpacingMark),
+    ('\U00000b15', '\U00000b28', GConsonant),
+    ('\U00000b2a', '\U00000b30', GConsonant),
+    ('\U00000b32', '\U00000b33', GConsonant),
+    ('\U00000b35', '\U00000b39', GConsonant),
+    ('\U00000b3c', '\U00000b3c', GConjunctExtend),
+    ('\U00000b3e', '\U00000b3f', GConjunctExtend),
+    ('\U00000b40', '\U00000b40', GSpacingMark),
+    ('\U00000b41', '\U00000b44', GConjunctExtend),
+    ('\U00000b47', '\U00000b48', GSpacingMark),
+    ('\U00000b4b', '\U00000b4c', GSpacingMark),
+    ('\U00000b4d', '\U00000b4d', GLinker),
+    ('\U00000b55', '\U00000b57', GConjunctExtend),
+    ('\U00000b5c', '\U00000b5d', GConsonant),
+    ('\U00000b5f', '\U00000b5f', GConsonant),
+    ('\U00000b62', '\U00000b63', GConjunctExtend),
+    ('\U00000b71', '\U00000b71', GConsonant),
+    ('\U00000b82', '\U00000b82', GExtend),
+    ('\U00000bbe', '\U00000bbe', GConjunctExtend),
+    ('\U00000bbf', '\U00000bbf', GSpacingMark),
+    ('\U00000bc0', '\U00000bc0', GConjunctExtend),
+    ('\U00000bc1', '\U00000bc2', GSpacingMark),
+    ('\U00000bc6', '\U00000bc8', GSpacingMark),
+    ('\U00000bca', '\U00000bcc', GSpacingMark),
+    ('\U00000bcd', '\U00000bcd', GConjunctExtend),
+    ('\U00000bd7', '\U00000bd7', GConjunctExtend),
+    ('\U00000c00', '\U00000c00', GConjunctExtend),
+    ('\U00000c01', '\U00000c03', GSpacingMark),
+    ('\U00000c04', '\U00000c04', GConjunctExtend),
+    ('\U00000c15', '\U00000c28', GConsonant),
+    ('\U00000c2a', '\U00000c39', GConsonant),
+    ('\U00000c3c', '\U00000c3c', GExtend),
+    ('\U00000c3e', '\U00000c40', GConjunctExtend),
+    ('\U00000c41', '\U00000c44', GSpacingMark),
+    ('\U00000c46', '\U00000c48', GConjunctExtend),
+    ('\U00000c4a', '\U00000c4c', GConjunctExtend),
+    ('\U00000c4d', '\U00000c4d', GLinker),
+    ('\U00000c55', '\U00000c56', GConjunctExtend),
+    ('\U00000c58', '\U00000c5a', GConsonant),
+    ('\U00000c62', '\U00000c63', GConjunctExtend),
+    ('\U00000c81', '\U00000c81', GConjunctExtend),
+    ('\U00000c82', '\U00000c83', GSpacingMark),
+    ('\U00000cbc', '\U00000cbc', GExtend),
+    ('\U00000cbe', '\U00000cbe', GSpacingMark),
+    ('\U00000cbf', '\U00000cc0', GConjunctExtend),
+    ('\U00000cc1', '\U00000cc1', GSpacingMark),
+    ('\U00000cc2', '\U00000cc2', GConjunctExtend),
+    ('\U00000cc3', '\U00000cc4', GSpacingMark),
+    ('\U00000cc6', '\U00000cc8', GConjunctExtend),
+    ('\U00000cca', '\U00000ccd', GConjunctExtend),
+    ('\U00000cd5', '\U00000cd6', GConjunctExtend),
+    ('\U00000ce2', '\U00000ce3', GConjunctExtend),
+    ('\U00000cf3', '\U00000cf3', GSpacingMark),
+    ('\U00000d00', '\U00000d01', GExtend),
+    ('\U00000d02', '\U00000d03', GSpacingMark),
+    ('\U00000d15', '\U00000d3a', GConsonant),
+    ('\U00000d3b', '\U00000d3c', GConjunctExtend),
+    ('\U00000d3e', '\U00000d3e', GConjunctExtend),
+    ('\U00000d3f', '\U00000d40', GSpacingMark),
+    ('\U00000d41', '\U00000d44', GConjunctExtend),
+    ('\U00000d46', '\U00000d48', GSpacingMark),
+    ('\U00000d4a', '\U00000d4c', GSpacingMark),
+    ('\U00000d4d', '\U00000d4d', GLinker),
+    ('\U00000d4e', '\U00000d4e', GPrepend),
+    ('\U00000d57', '\U00000d57', GConjunctExtend),
+    ('\U00000d62', '\U00000d63', GConjunctExtend),
+    ('\U00000d81', '\U00000d81', GConjunctExtend),
+    ('\U00000d82', '\U00000d83', GSpacingMark),
+    ('\U00000dca', '\U00000dca', GExtend),
+    ('\U00000dcf', '\U00000dcf', GConjunctExtend),
+    ('\U00000dd0', '\U00000dd1', GSpacingMark),
+    ('\U00000dd2', '\U00000dd4', GConjunctExtend),
+    ('\U00000dd6', '\U00000dd6', GConjunctExtend),
+    ('\U00000dd8', '\U00000dde', GSpacingMark),
+    ('\U00000ddf', '\U00000ddf', GConjunctExtend),
+    ('\U00000df2', '\U00000df3', GSpacingMark),
+    ('\U00000e31', '\U00000e31', GConjunctExtend),
+    ('\U00000e33', '\U00000e33', GSpacingMark),
+    ('\U00000e34', '\U00000e3a', GConjunctExtend),
+    ('\U00000e47', '\U00000e4e', GExtend),
+    ('\U00000eb1', '\U00000eb1', GConjunctExtend),
+    ('\U00000eb3', '\U00000eb3', GSpacingMark),
+    ('\U00000eb4', '\U00000ebc', GConjunctExtend),
+    ('\U00000ec8', '\U00000ece', GConjunctExtend),
+    ('\U00000f18', '\U00000f19', GConjunctExtend),
+    ('\U00000f35', '\U00000f35', GConjunctExtend),
+    ('\U00000f37', '\U00000f37', GConjunctExtend),
+    ('\U00000f39', '\U00000f39', GExtend),
+    ('\U00000f3e', '\U00000f3f', GSpacingMark),
+    ('\U00000f71', '\U00000f7e', GConjunctExtend),
+    ('\U00000f7f', '\U00000f7f', GSpacingMark),
+    ('\U00000f80', '\U00000f84', GConjunctExtend),
+    ('\U00000f86', '\U00000f87', GConjunctExtend),
+    ('\U00000f8d', '\U00000f97', GConjunctExtend),
+    ('\U00000f99', '\U00000fbc', GConjunctExtend),
+    ('\U00000fc6', '\U00000fc6', GConjunctExtend),
+    ('\U0000102d', '\U00001030', GExtend),
+    ('\U00001031', '\U00001031', GSpacingMark),
+    ('\U00001032', '\U00001037', GConjunctExtend),
+    ('\U00001039', '\U0000103a', GConjunctExtend),
+    ('\U0000103b', '\U0000103c', GSpacingMark),
+    ('\U0000103d', '\U0000103e', GConjunctExtend),
+    ('\U00001056', '\U00001057', GSpacingMark),
+    ('\U00001058', '\U00001059', GConjunctExtend),
+    ('\U0000105e', '\U00001060', GConjunctExtend),
+    ('\U00001071', '\U00001074', GConjunctExtend),
+    ('\U00001082', '\U00001082', GExtend),
+    ('\U00001084', '\U00001084', GSpacingMark),
+    ('\U00001085', '\U00001086', GConjunctExtend),
+    ('\U0000108d', '\U0000108d', GConjunctExtend),
+    ('\U0000109d', '\U0000109d', GConjunctExtend),
+    ('\U00001100', '\U0000115f', GL),
+    ('\U00001160', '\U000011a7', GV),
+    ('\U000011a8', '\U000011ff', GT),
+    ('\U0000135d', '\U0000135f', GConjunctExtend),
+    ('\U00001712', '\U00001715', GConjunctExtend),
+    ('\U00001732', '\U00001734', GConjunctExtend),
+    ('\U00001752', '\U00001753', GExtend),
+    ('\U00001772', '\U00001773', GConjunctExtend),
+    ('\U000017b4', '\U000017b5', GConjunctExtend),
+    ('\U000017b6', '\U000017b6', GSpacingMark),
+    ('\U000017b7', '\U000017bd', GConjunctExtend),
+    ('\U000017be', '\U000017c5', GSpacingMark),
+    ('\U000017c6', '\U000017c6', GConjunctExtend),
+    ('\U000017c7', '\U000017c8', GSpacingMark),
+    ('\U000017c9', '\U000017d3', GConjunctExtend),
+    ('\U000017dd', '\U000017dd', GConjunctExtend),
+    ('\U0000180b', '\U0000180d', GExtend),
+    ('\U0000180e', '\U0000180e', GControl),
+    ('\U0000180f', '\U0000180f', GConjunctExtend),
+    ('\U00001885', '\U00001886', GConjunctExtend),
+    ('\U000018a9', '\U000018a9', GConjunctExtend),
+    ('\U00001920', '\U00001922', GConjunctExtend),
+    ('\U00001923', '\U00001926', GSpacingMark),
+    ('\U00001927', '\U00001928', GConjunctExtend),
+    ('\U00001929', '\U0000192b', GSpacingMark),
+    ('\U00001930', '\U00001931', GSpacingMark),
+    ('\U00001932', '\U00001932', GConjunctExtend),
+    ('\U00001933', '\U00001938', GSpacingMark),
+    ('\U00001939', '\U0000193b', GExtend),
+    ('\U00001a17', '\U00001a18', GConjunctExtend),
+    ('\U00001a19', '\U00001a1a', GSpacingMark),
+    ('\U00001a1b', '\U00001a1b', GConjunctExtend),
+    ('\U00001a55', '\U00001a55', GSpacingMark),
+    ('\U00001a56', '\U00001a56', GConjunctExtend),
+    ('\U00001a57', '\U00001a57', GSpacingMark),
+    ('\U00001a58', '\U00001a5e', GConjunctExtend),
+    ('\U00001a60', '\U00001a60', GConjunctExtend),
+    ('\U00001a62', '\U00001a62', GConjunctExtend),
+    ('\U00001a65', '\U00001a6c', GExtend),
+    ('\U00001a6d', '\U00001a72', GSpacingMark),
+    ('\U00001a73', '\U00001a7c', GConjunctExtend),
+    ('\U00001a7f', '\U00001a7f', GConjunctExtend),
+    ('\U00001ab0', '\U00001ace', GConjunctExtend),
+    ('\U00001b00', '\U00001b03', GConjunctExtend),
+    ('\U00001b04', '\U00001b04', GSpacingMark),
+    ('\U00001b34', '\U00001b3d', GConjunctExtend),
+    ('\U00001b3e', '\U00001b41', GSpacingMark),
+    ('\U00001b42', '\U00001b44', GConjunctExtend),
+    ('\U00001b6b', '\U00001b73', GExtend),
+    ('\U00001b80', '\U00001b81', GConjunctExtend),
+    ('\U00001b82', '\U00001b82', GSpacingMark),
+    ('\U00001ba1', '\U00001ba1', GSpacingMark),
+    ('\U00001ba2', '\U00001ba5', GConjunctExtend),
+    ('\U00001ba6', '\U00001ba7', GSpacingMark),
+    ('\U00001ba8', '\U00001bad', GConjunctExtend),
+    ('\U00001be6', '\U00001be6', GConjunctExtend),
+    ('\U00001be7', '\U00001be7', GSpacingMark),
+    ('\U00001be8', '\U00001be9', GConjunctExtend),
+    ('\U00001bea', '\U00001bec', GSpacingMark),
+    ('\U00001bed', '\U00001bed', GConjunctExtend),
+    ('\U00001bee', '\U00001bee', GSpacingMark),
+    ('\U00001bef', '\U00001bf3', GExtend),
+    ('\U00001c24', '\U00001c2b', GSpacingMark),
+    ('\U00001c2c', '\U00001c33', GConjunctExtend),
+    ('\U00001c34', '\U00001c35', GSpacingMark),
+    ('\U00001c36', '\U00001c37', GConjunctExtend),
+    ('\U00001cd0', '\U00001cd2', GConjunctExtend),
+    ('\U00001cd4', '\U00001ce0', GConjunctExtend),
+    ('\U00001ce1', '\U00001ce1', GSpacingMark),
+    ('\U00001ce2', '\U00001ce8', GConjunctExtend),
+    ('\U00001ced', '\U00001ced', GConjunctExtend),
+    ('\U00001cf4', '\U00001cf4', GExtend),
+    ('\U00001cf7', '\U00001cf7', GSpacingMark),
+    ('\U00001cf8', '\U00001cf9', GConjunctExtend),
+    ('\U00001dc0', '\U00001dff', GConjunctExtend),
+    ('\U0000200b', '\U0000200b', GControl),
+    ('\U0000200c', '\U0000200c', GExtend),
+    ('\U0000200d', '\U0000200d', GZWJ),
+    ('\U0000200e', '\U0000200f', GControl),
+    ('\U00002028', '\U0000202e', GControl),
+    ('\U0000203c', '\U0000203c', GExtPict),
+    ('\U00002049', '\U00002049', GExtPict),
+    ('\U00002060', '\U0000206f', GControl),
+    ('\U000020d0', '\U000020f0', GConjunctExtend),
+    ('\U00002122', '\U00002122', GExtPict),
+    ('\U00002139', '\U00002139', GExtPict),
+    ('\U00002194', '\U00002199', GExtPict),
+    ('\U000021a9', '\U000021aa', GExtPict),
+    ('\U0000231a', '\U0000231b', GExtPict),
+    ('\U00002328', '\U00002328', GExtPict),
+    ('\U00002388', '\U00002388', GExtPict),
+    ('\U000023cf', '\U000023cf', GExtPict),
+    ('\U000023e9', '\U000023f3', GExtPict),
+    ('\U000023f8', '\U000023fa', GExtPict),
+    ('\U000024c2', '\U000024c2', GExtPict),
+    ('\U000025aa', '\U000025ab', GExtPict),
+    ('\U000025b6', '\U000025b6', GExtPict),
+    ('\U000025c0', '\U000025c0', GExtPict),
+    ('\U000025fb', '\U000025fe', GExtPict),
+    ('\U00002600', '\U00002605', GExtPict),
+    ('\U00002607', '\U00002612', GExtPict),
+    ('\U00002614', '\U00002685', GExtPict),
+    ('\U00002690', '\U00002705', GExtPict),
+    ('\U00002708', '\U00002712', GExtPict),
+    ('\U00002714', '\U00002714', GExtPict),
+    ('\U00002716', '\U00002716', GExtPict),
+    ('\U0000271d', '\U0000271d', GExtPict),
+    ('\U00002721', '\U00002721', GExtPict),
+    ('\U00002728', '\U00002728', GExtPict),
+    ('\U00002733', '\U00002734', GExtPict),
+    ('\U00002744', '\U00002744', GExtPict),
+    ('\U00002747', '\U00002747', GExtPict),
+    ('\U0000274c', '\U0000274c', GExtPict),
+    ('\U0000274e', '\U0000274e', GExtPict),
+    ('\U00002753', '\U00002755', GExtPict),
+    ('\U00002757', '\U00002757', GExtPict),
+    ('\U00002763', '\U00002767', GExtPict),
+    ('\U00002795', '\U00002797', GExtPict),
+    ('\U000027a1', '\U000027a1', GExtPict),
+    ('\U000027b0', '\U000027b0', GExtPict),
+    ('\U000027bf', '\U000027bf', GExtPict),
+    ('\U00002934', '\U00002935', GExtPict),
+    ('\U00002b05', '\U00002b07', GExtPict),
+    ('\U00002b1b', '\U00002b1c', GExtPict),
+    ('\U00002b50', '\U00002b50', GExtPict),
+    ('\U00002b55', '\U00002b55', GExtPict),
+    ('\U00002cef', '\U00002cf1', GConjunctExtend),
+    ('\U00002d7f', '\U00002d7f', GConjunctExtend),
+    ('\U00002de0', '\U00002dff', GExtend),
+    ('\U0000302a', '\U0000302f', GConjunctExtend),
+    ('\U00003030', '\U00003030', GExtPict),
+    ('\U0000303d', '\U0000303d', GExtPict),
+    ('\U00003099', '\U0000309a', GConjunctExtend),
+    ('\U00003297', '\U00003297', GExtPict),
+    ('\U00003299', '\U00003299', GExtPict),
+    ('\U0000a66f', '\U0000a672', GConjunctExtend),
+    ('\U0000a674', '\U0000a67d', GConjunctExtend),
+    ('\U0000a69e', '\U0000a69f', GConjunctExtend),
+    ('\U0000a6f0', '\U0000a6f1', GConjunctExtend),
+    ('\U0000a802', '\U0000a802', GExtend),
+    ('\U0000a806', '\U0000a806', GConjunctExtend),
+    ('\U0000a80b', '\U0000a80b', GConjunctExtend),
+    ('\U0000a823', '\U0000a824', GSpacingMark),
+    ('\U0000a825', '\U0000a826', GConjunctExtend),
+    ('\U0000a827', '\U0000a827', GSpacingMark),
+    ('\U0000a82c', '\U0000a82c', GConjunctExtend),
+    ('\U0000a880', '\U0000a881', GSpacingMark),
+    ('\U0000a8b4', '\U0000a8c3', GSpacingMark),
+    ('\U0000a8c4', '\U0000a8c5', GConjunctExtend),
+    ('\U0000a8e0', '\U0000a8f1', GConjunctExtend),
+    ('\U0000a8ff', '\U0000a8ff', GExtend),
+    ('\U0000a926', '\U0000a92d', GConjunctExtend),
+    ('\U0000a947', '\U0000a951', GConjunctExtend),
+    ('\U0000a952', '\U0000a952', GSpacingMark),
+    ('\U0000a953', '\U0000a953', GConjunctExtend),
+    ('\U0000a960', '\U0000a97c', GL),
+    ('\U0000a980', '\U0000a982', GConjunctExtend),
+    ('\U0000a983', '\U0000a983', GSpacingMark),
+    ('\U0000a9b3', '\U0000a9b3', GConjunctExtend),
+    ('\U0000a9b4', '\U0000a9b5', GSpacingMark),
+    ('\U0000a9b6', '\U0000a9b9', GConjunctExtend),
+    ('\U0000a9ba', '\U0000a9bb', GSpacingMark),
+    ('\U0000a9bc', '\U0000a9bd', GExtend),
+    ('\U0000a9be', '\U0000a9bf', GSpacingMark),
+    ('\U0000a9c0', '\U0000a9c0', GConjunctExtend),
+    ('\U0000a9e5', '\U0000a9e5', GConjunctExtend),
+    ('\U0000aa29', '\U0000aa2e', GConjunctExtend),
+    ('\U0000aa2f', '\U0000aa30', GSpacingMark),
+    ('\U0000aa31', '\U0000aa32', GConjunctExtend),
+    ('\U0000aa33', '\U0000aa34', GSpacingMark),
+    ('\U0000aa35', '\U0000aa36', GConjunctExtend),
+    ('\U0000aa43', '\U0000aa43', GConjunctExtend),
+    ('\U0000aa4c', '\U0000aa4c', GExtend),
+    ('\U0000aa4d', '\U0000aa4d', GSpacingMark),
+    ('\U0000aa7c', '\U0000aa7c', GConjunctExtend),
+    ('\U0000aab0', '\U0000aab0', GConjunctExtend),
+    ('\U0000aab2', '\U0000aab4', GConjunctExtend),
+    ('\U0000aab7', '\U0000aab8', GConjunctExtend),
+    ('\U0000aabe', '\U0000aabf', GConjunctExtend),
+    ('\U0000aac1', '\U0000aac1', GConjunctExtend),
+    ('\U0000aaeb', '\U0000aaeb', GSpacingMark),
+    ('\U0000aaec', '\U0000aaed', GExtend),
+    ('\U0000aaee', '\U0000aaef', GSpacingMark),
+    ('\U0000aaf5', '\U0000aaf5', GSpacingMark),
+    ('\U0000aaf6', '\U0000aaf6', GConjunctExtend),
+    ('\U0000abe3', '\U0000abe4', GSpacingMark),
+    ('\U0000abe5', '\U0000abe5', GConjunctExtend),
+    ('\U0000abe6', '\U0000abe7', GSpacingMark),
+    ('\U0000abe8', '\U0000abe8', GConjunctExtend),
+    ('\U0000abe9', '\U0000abea', GSpacingMark),
+    ('\U0000abec', '\U0000abec', GSpacingMark),
+    ('\U0000abed', '\U0000abed', GConjunctExtend),
+    ('\U0000ac00', '\U0000ac00', GLV),
+    ('\U0000ac01', '\U0000ac1b', GLVT),
+    ('\U0000ac1c', '\U0000ac1c', GLV),
+    ('\U0000ac1d', '\U0000ac37', GLVT),
+    ('\U0000ac38', '\U0000ac38', GLV),
+    ('\U0000ac39', '\U0000ac53', GLVT),
+    ('\U0000ac54', '\U0000ac54', GLV),
+    ('\U0000ac55', '\U0000ac6f', GLVT),
+    ('\U0000ac70', '\U0000ac70', GLV),
+    ('\U0000ac71', '\U0000ac8b', GLVT),
+    ('\U0000ac8c', '\U0000ac8c', GLV),
+    ('\U0000ac8d', '\U0000aca7', GLVT),
+    ('\U0000aca8', '\U0000aca8', GLV),
+    ('\U0000aca9', '\U0000acc3', GLVT),
+    ('\U0000acc4', '\U0000acc4', GLV),
+    ('\U0000acc5', '\U0000acdf', GLVT),
+    ('\U0000ace0', '\U0000ace0', GLV),
+    ('\U0000ace1', '\U0000acfb', GLVT),
+    ('\U0000acfc', '\U0000acfc', GLV),
+    ('\U0000acfd', '\U0000ad17', GLVT),
+    ('\U0000ad18', '\U0000ad18', GLV),
+    ('\U0000ad19', '\U0000ad33', GLVT),
+    ('\U0000ad34', '\U0000ad34', GLV),
+    ('\U0000ad35', '\U0000ad4f', GLVT),
+    ('\U0000ad50', '\U0000ad50', GLV),
+    ('\U0000ad51', '\U0000ad6b', GLVT),
+    ('\U0000ad6c', '\U0000ad6c', GLV),
+    ('\U0000ad6d', '\U0000ad87', GLVT),
+    ('\U0000ad88', '\U0000ad88', GLV),
+    ('\U0000ad89', '\U0000ada3', GLVT),
+    ('\U0000ada4', '\U0000ada4', GLV),
+    ('\U0000ada5', '\U0000adbf', GLVT),
+    ('\U0000adc0', '\U0000adc0', GLV),
+    ('\U0000adc1', '\U0000addb', GLVT),
+    ('\U0000addc', '\U0000addc', GLV),
+    ('\U0000addd', '\U0000adf7', GLVT),
+    ('\U0000adf8', '\U0000adf8', GLV),
+    ('\U0000adf9', '\U0000ae13', GLVT),
+    ('\U0000ae14', '\U0000ae14', GLV),
+    ('\U0000ae15', '\U0000ae2f', GLVT),
+    ('\U0000ae30', '\U0000ae30', GLV),
+    ('\U0000ae31', '\U0000ae4b', GLVT),
+    ('\U0000ae4c', '\U0000ae4c', GLV),
+    ('\U0000ae4d', '\U0000ae67', GLVT),
+    ('\U0000ae68', '\U0000ae68', GLV),
+    ('\U0000ae69', '\U0000ae83', GLVT),
+    ('\U0000ae84', '\U0000ae84', GLV),
+    ('\U0000ae85', '\U0000ae9f', GLVT),
+    ('\U0000aea0', '\U0000aea0', GLV),
+    ('\U0000aea1', '\U0000aebb', GLVT),
+    ('\U0000aebc', '\U0000aebc', GLV),
+    ('\U0000aebd', '\U0000aed7', GLVT),
+    ('\U0000aed8', '\U0000aed8', GLV),
+    ('\U0000aed9', '\U0000aef3', GLVT),
+    ('\U0000aef4', '\U0000aef4', GLV),
+    ('\U0000aef5', '\U0000af0f', GLVT),
+    ('\U0000af10', '\U0000af10', GLV),
+    ('\U0000af11', '\U0000af2b', GLVT),
+    ('\U0000af2c', '\U0000af2c', GLV),
+    ('\U0000af2d', '\U0000af47', GLVT),
+    ('\U0000af48', '\U0000af48', GLV),
+    ('\U0000af49', '\U0000af63', GLVT),
+    ('\U0000af64', '\U0000af64', GLV),
+    ('\U0000af65', '\U0000af7f', GLVT),
+    ('\U0000af80', '\U0000af80', GLV),
+    ('\U0000af81', '\U0000af9b', GLVT),
+    ('\U0000af9c', '\U0000af9c', GLV),
+    ('\U0000af9d', '\U0000afb7', GLVT),
+    ('\U0000afb8', '\U0000afb8', GLV),
+    ('\U0000afb9', '\U0000afd3', GLVT),
+    ('\U0000afd4', '\U0000afd4', GLV),
+    ('\U0000afd5', '\U0000afef', GLVT),
+    ('\U0000aff0', '\U0000aff0', GLV),
+    ('\U0000aff1', '\U0000b00b', GLVT),
+    ('\U0000b00c', '\U0000b00c', GLV),
+    ('\U0000b00d', '\U0000b027', GLVT),
+    ('\U0000b028', '\U0000b028', GLV),
+    ('\U0000b029', '\U0000b043', GLVT),
+    ('\U0000b044', '\U0000b044', GLV),
+    ('\U0000b045', '\U0000b05f', GLVT),
+    ('\U0000b060', '\U0000b060', GLV),
+    ('\U0000b061', '\U0000b07b', GLVT),
+    ('\U0000b07c', '\U0000b07c', GLV),
+    ('\U0000b07d', '\U0000b097', GLVT),
+    ('\U0000b098', '\U0000b098', GLV),
+    ('\U0000b099', '\U0000b0b3', GLVT),
+    ('\U0000b0b4', '\U0000b0b4', GLV),
+    ('\U0000b0b5', '\U0000b0cf', GLVT),
+    ('\U0000b0d0', '\U0000b0d0', GLV),
+    ('\U0000b0d1', '\U0000b0eb', GLVT),
+    ('\U0000b0ec', '\U0000b0ec', GLV),
+    ('\U0000b0ed', '\U0000b107', GLVT),
+    ('\U0000b108', '\U0000b108', GLV),
+    ('\U0000b109', '\U0000b123', GLVT),
+    ('\U0000b124', '\U0000b124', GLV),
+    ('\U0000b125', '\U0000b13f', GLVT),
+    ('\U0000b140', '\U0000b140', GLV),
+    ('\U0000b141', '\U0000b15b', GLVT),
+    ('\U0000b15c', '\U0000b15c', GLV),
+    ('\U0000b15d', '\U0000b177', GLVT),
+    ('\U0000b178', '\U0000b178', GLV),
+    ('\U0000b179', '\U0000b193', GLVT),
+    ('\U0000b194', '\U0000b194', GLV),
+    ('\U0000b195', '\U0000b1af', GLVT),
+    ('\U0000b1b0', '\U0000b1b0', GLV),
+    ('\U0000b1b1', '\U0000b1cb', GLVT),
+    ('\U0000b1cc', '\U0000b1cc', GLV),
+    ('\U0000b1cd', '\U0000b1e7', GLVT),
+    ('\U0000b1e8', '\U0000b1e8', GLV),
+    ('\U0000b1e9', '\U0000b203', GLVT),
+    ('\U0000b204', '\U0000b204', GLV),
+    ('\U0000b205', '\U0000b21f', GLVT),
+    ('\U0000b220', '\U0000b220', GLV),
+    ('\U0000b221', '\U0000b23b', GLVT),
+    ('\U0000b23c', '\U0000b23c', GLV),
+    ('\U0000b23d', '\U0000b257', GLVT),
+    ('\U0000b258', '\U0000b258', GLV),
+    ('\U0000b259', '\U0000b273', GLVT),
+    ('\U0000b274', '\U0000b274', GLV),
+    ('\U0000b275', '\U0000b28f', GLVT),
+    ('\U0000b290', '\U0000b290', GLV),
+    ('\U0000b291', '\U0000b2ab', GLVT),
+    ('\U0000b2ac', '\U0000b2ac', GLV),
+    ('\U0000b2ad', '\U0000b2c7', GLVT),
+    ('\U0000b2c8', '\U0000b2c8', GLV),
+    ('\U0000b2c9', '\U0000b2e3', GLVT),
+    ('\U0000b2e4', '\U0000b2e4', GLV),
+    ('\U0000b2e5', '\U0000b2ff', GLVT),
+    ('\U0000b300', '\U0000b300', GLV),
+    ('\U0000b301', '\U0000b31b', GLVT),
+    ('\U0000b31c', '\U0000b31c', GLV),
+    ('\U0000b31d', '\U0000b337', GLVT),
+    ('\U0000b338', '\U0000b338', GLV),
+    ('\U0000b339', '\U0000b353', GLVT),
+    ('\U0000b354', '\U0000b354', GLV),
+    ('\U0000b355', '\U0000b36f', GLVT),
+    ('\U0000b370', '\U0000b370', GLV),
+    ('\U0000b371', '\U0000b38b', GLVT),
+    ('\U0000b38c', '\U0000b38c', GLV),
+    ('\U0000b38d', '\U0000b3a7', GLVT),
+    ('\U0000b3a8', '\U0000b3a8', GLV),
+    ('\U0000b3a9', '\U0000b3c3', GLVT),
+    ('\U0000b3c4', '\U0000b3c4', GLV),
+    ('\U0000b3c5', '\U0000b3df', GLVT),
+    ('\U0000b3e0', '\U0000b3e0', GLV),
+    ('\U0000b3e1', '\U0000b3fb', GLVT),
+    ('\U0000b3fc', '\U0000b3fc', GLV),
+    ('\U0000b3fd', '\U0000b417', GLVT),
+    ('\U0000b418', '\U0000b418', GLV),
+    ('\U0000b419', '\U0000b433', GLVT),
+    ('\U0000b434', '\U0000b434', GLV),
+    ('\U0000b435', '\U0000b44f', GLVT),
+    ('\U0000b450', '\U0000b450', GLV),
+    ('\U0000b451', '\U0000b46b', GLVT),
+    ('\U0000b46c', '\U0000b46c', GLV),
+    ('\U0000b46d', '\U0000b487', GLVT),
+    ('\U0000b488', '\U0000b488', GLV),
+    ('\U0000b489', '\U0000b4a3', GLVT),
+    ('\U0000b4a4', '\U0000b4a4', GLV),
+    ('\U0000b4a5', '\U0000b4bf', GLVT),
+    ('\U0000b4c0', '\U0000b4c0', GLV),
+    ('\U0000b4c1', '\U0000b4db', GLVT),
+    ('\U0000b4dc', '\U0000b4dc', GLV),
+    ('\U0000b4dd', '\U0000b4f7', GLVT),
+    ('\U0000b4f8', '\U0000b4f8', GLV),
+    ('\U0000b4f9', '\U0000b513', GLVT),
+    ('\U0000b514', '\U0000b514', GLV),
+    ('\U0000b515', '\U0000b52f', GLVT),
+    ('\U0000b530', '\U0000b530', GLV),
+    ('\U0000b531', '\U0000b54b', GLVT),
+    ('\U0000b54c', '\U0000b54c', GLV),
+    ('\U0000b54d', '\U0000b567', GLVT),
+    ('\U0000b568', '\U0000b568', GLV),
+    ('\U0000b569', '\U0000b583', GLVT),
+    ('\U0000b584', '\U0000b584', GLV),
+    ('\U0000b585', '\U0000b59f', GLVT),
+    ('\U0000b5a0', '\U0000b5a0', GLV),
+    ('\U0000b5a1', '\U0000b5bb', GLVT),
+    ('\U0000b5bc', '\U0000b5bc', GLV),
+    ('\U0000b5bd', '\U0000b5d7', GLVT),
+    ('\U0000b5d8', '\U0000b5d8', GLV),
+    ('\U0000b5d9', '\U0000b5f3', GLVT),
+    ('\U0000b5f4', '\U0000b5f4', GLV),
+    ('\U0000b5f5', '\U0000b60f', GLVT),
+    ('\U0000b610', '\U0000b610', GLV),
+    ('\U0000b611', '\U0000b62b', GLVT),
+    ('\U0000b62c', '\U0000b62c', GLV),
+    ('\U0000b62d', '\U0000b647', GLVT),
+    ('\U0000b648', '\U0000b648', GLV),
+    ('\U0000b649', '\U0000b663', GLVT),
+    ('\U0000b664', '\U0000b664', GLV),
+    ('\U0000b665', '\U0000b67f', GLVT),
+    ('\U0000b680', '\U0000b680', GLV),
+    ('\U0000b681', '\U0000b69b', GLVT),
+    ('\U0000b69c', '\U0000b69c', GLV),
+    ('\U0000b69d', '\U0000b6b7', GLVT),
+    ('\U0000b6b8', '\U0000b6b8', GLV),
+    ('\U0000b6b9', '\U0000b6d3', GLVT),
+    ('\U0000b6d4', '\U0000b6d4', GLV),
+    ('\U0000b6d5', '\U0000b6ef', GLVT),
+    ('\U0000b6f0', '\U0000b6f0', GLV),
+    ('\U0000b6f1', '\U0000b70b', GLVT),
+    ('\U0000b70c', '\U0000b70c', GLV),
+    ('\U0000b70d', '\U0000b727', GLVT),
+    ('\U0000b728', '\U0000b728', GLV),
+    ('\U0000b729', '\U0000b743', GLVT),
+    ('\U0000b744', '\U0000b744', GLV),
+    ('\U0000b745', '\U0000b75f', GLVT),
+    ('\U0000b760', '\U0000b760', GLV),
+    ('\U0000b761', '\U0000b77b', GLVT),
+    ('\U0000b77c', '\U0000b77c', GLV),
+    ('\U0000b77d', '\U0000b797', GLVT),
+    ('\U0000b798', '\U0000b798', GLV),
+    ('\U0000b799', '\U0000b7b3', GLVT),
+    ('\U0000b7b4', '\U0000b7b4', GLV),
+    ('\U0000b7b5', '\U0000b7cf', GLVT),
+    ('\U0000b7d0', '\U0000b7d0', GLV),
+    ('\U0000b7d1', '\U0000b7eb', GLVT),
+    ('\U0000b7ec', '\U0000b7ec', GLV),
+    ('\U0000b7ed', '\U0000b807', GLVT),
+    ('\U0000b808', '\U0000b808', GLV),
+    ('\U0000b809', '\U0000b823', GLVT),
+    ('\U0000b824', '\U0000b824', GLV),
+    ('\U0000b825', '\U0000b83f', GLVT),
+    ('\U0000b840', '\U0000b840', GLV),
+    ('\U0000b841', '\U0000b85b', GLVT),
+    ('\U0000b85c', '\U0000b85c', GLV),
+    ('\U0000b85d', '\U0000b877', GLVT),
+    ('\U0000b878', '\U0000b878', GLV),
+    ('\U0000b879', '\U0000b893', GLVT),
+    ('\U0000b894', '\U0000b894', GLV),
+    ('\U0000b895', '\U0000b8af', GLVT),
+    ('\U0000b8b0', '\U0000b8b0', GLV),
+    ('\U0000b8b1', '\U0000b8cb', GLVT),
+    ('\U0000b8cc', '\U0000b8cc', GLV),
+    ('\U0000b8cd', '\U0000b8e7', GLVT),
+    ('\U0000b8e8', '\U0000b8e8', GLV),
+    ('\U0000b8e9', '\U0000b903', GLVT),
+    ('\U0000b904', '\U0000b904', GLV),
+    ('\U0000b905', '\U0000b91f', GLVT),
+    ('\U0000b920', '\U0000b920', GLV),
+    ('\U0000b921', '\U0000b93b', GLVT),
+    ('\U0000b93c', '\U0000b93c', GLV),
+    ('\U0000b93d', '\U0000b957', GLVT),
+    ('\U0000b958', '\U0000b958', GLV),
+    ('\U0000b959', '\U0000b973', GLVT),
+    ('\U0000b974', '\U0000b974', GLV),
+    ('\U0000b975', '\U0000b98f', GLVT),
+    ('\U0000b990', '\U0000b990', GLV),
+    ('\U0000b991', '\U0000b9ab', GLVT),
+    ('\U0000b9ac', '\U0000b9ac', GLV),
+    ('\U0000b9ad', '\U0000b9c7', GLVT),
+    ('\U0000b9c8', '\U0000b9c8', GLV),
+    ('\U0000b9c9', '\U0000b9e3', GLVT),
+    ('\U0000b9e4', '\U0000b9e4', GLV),
+    ('\U0000b9e5', '\U0000b9ff', GLVT),
+    ('\U0000ba00', '\U0000ba00', GLV),
+    ('\U0000ba01', '\U0000ba1b', GLVT),
+    ('\U0000ba1c', '\U0000ba1c', GLV),
+    ('\U0000ba1d', '\U0000ba37', GLVT),
+    ('\U0000ba38', '\U0000ba38', GLV),
+    ('\U0000ba39', '\U0000ba53', GLVT),
+    ('\U0000ba54', '\U0000ba54', GLV),
+    ('\U0000ba55', '\U0000ba6f', GLVT),
+    ('\U0000ba70', '\U0000ba70', GLV),
+    ('\U0000ba71', '\U0000ba8b', GLVT),
+    ('\U0000ba8c', '\U0000ba8c', GLV),
+    ('\U0000ba8d', '\U0000baa7', GLVT),
+    ('\U0000baa8', '\U0000baa8', GLV),
+    ('\U0000baa9', '\U0000bac3', GLVT),
+    ('\U0000bac4', '\U0000bac4', GLV),
+    ('\U0000bac5', '\U0000badf', GLVT),
+    ('\U0000bae0', '\U0000bae0', GLV),
+    ('\U0000bae1', '\U0000bafb', GLVT),
+    ('\U0000bafc', '\U0000bafc', GLV),
+    ('\U0000bafd', '\U0000bb17', GLVT),
+    ('\U0000bb18', '\U0000bb18', GLV),
+    ('\U0000bb19', '\U0000bb33', GLVT),
+    ('\U0000bb34', '\U0000bb34', GLV),
+    ('\U0000bb35', '\U0000bb4f', GLVT),
+    ('\U0000bb50', '\U0000bb50', GLV),
+    ('\U0000bb51', '\U0000bb6b', GLVT),
+    ('\U0000bb6c', '\U0000bb6c', GLV),
+    ('\U0000bb6d', '\U0000bb87', GLVT),
+    ('\U0000bb88', '\U0000bb88', GLV),
+    ('\U0000bb89', '\U0000bba3', GLVT),
+    ('\U0000bba4', '\U0000bba4', GLV),
+    ('\U0000bba5', '\U0000bbbf', GLVT),
+    ('\U0000bbc0', '\U0000bbc0', GLV),
+    ('\U0000bbc1', '\U0000bbdb', GLVT),
+    ('\U0000bbdc', '\U0000bbdc', GLV),
+    ('\U0000bbdd', '\U0000bbf7', GLVT),
+    ('\U0000bbf8', '\U0000bbf8', GLV),
+    ('\U0000bbf9', '\U0000bc13', GLVT),
+    ('\U0000bc14', '\U0000bc14', GLV),
+    ('\U0000bc15', '\U0000bc2f', GLVT),
+    ('\U0000bc30', '\U0000bc30', GLV),
+    ('\U0000bc31', '\U0000bc4b', GLVT),
+    ('\U0000bc4c', '\U0000bc4c', GLV),
+    ('\U0000bc4d', '\U0000bc67', GLVT),
+    ('\U0000bc68', '\U0000bc68', GLV),
+    ('\U0000bc69', '\U0000bc83', GLVT),
+    ('\U0000bc84', '\U0000bc84', GLV),
+    ('\U0000bc85', '\U0000bc9f', GLVT),
+    ('\U0000bca0', '\U0000bca0', GLV),
+    ('\U0000bca1', '\U0000bcbb', GLVT),
+    ('\U0000bcbc', '\U0000bcbc', GLV),
+    ('\U0000bcbd', '\U0000bcd7', GLVT),
+    ('\U0000bcd8', '\U0000bcd8', GLV),
+    ('\U0000bcd9', '\U0000bcf3', GLVT),
+    ('\U0000bcf4', '\U0000bcf4', GLV),
+    ('\U0000bcf5', '\U0000bd0f', GLVT),
+    ('\U0000bd10', '\U0000bd10', GLV),
+    ('\U0000bd11', '\U0000bd2b', GLVT),
+    ('\U0000bd2c', '\U0000bd2c', GLV),
+    ('\U0000bd2d', '\U0000bd47', GLVT),
+    ('\U0000bd48', '\U0000bd48', GLV),
+    ('\U0000bd49', '\U0000bd63', GLVT),
+    ('\U0000bd64', '\U0000bd64', GLV),
+    ('\U0000bd65', '\U0000bd7f', GLVT),
+    ('\U0000bd80', '\U0000bd80', GLV),
+    ('\U0000bd81', '\U0000bd9b', GLVT),
+    ('\U0000bd9c', '\U0000bd9c', GLV),
+    ('\U0000bd9d', '\U0000bdb7', GLVT),
+    ('\U0000bdb8', '\U0000bdb8', GLV),
+    ('\U0000bdb9', '\U0000bdd3', GLVT),
+    ('\U0000bdd4', '\U0000bdd4', GLV),
+    ('\U0000bdd5', '\U0000bdef', GLVT),
+    ('\U0000bdf0', '\U0000bdf0', GLV),
+    ('\U0000bdf1', '\U0000be0b', GLVT),
+    ('\U0000be0c', '\U0000be0c', GLV),
+    ('\U0000be0d', '\U0000be27', GLVT),
+    ('\U0000be28', '\U0000be28', GLV),
+    ('\U0000be29', '\U0000be43', GLVT),
+    ('\U0000be44', '\U0000be44', GLV),
+    ('\U0000be45', '\U0000be5f', GLVT),
+    ('\U0000be60', '\U0000be60', GLV),
+    ('\U0000be61', '\U0000be7b', GLVT),
+    ('\U0000be7c', '\U0000be7c', GLV),
+    ('\U0000be7d', '\U0000be97', GLVT),
+    ('\U0000be98', '\U0000be98', GLV),
+    ('\U0000be99', '\U0000beb3', GLVT),
+    ('\U0000beb4', '\U0000beb4', GLV),
+    ('\U0000beb5', '\U0000becf', GLVT),
+    ('\U0000bed0', '\U0000bed0', GLV),
+    ('\U0000bed1', '\U0000beeb', GLVT),
+    ('\U0000beec', '\U0000beec', GLV),
+    ('\U0000beed', '\U0000bf07', GLVT),
+    ('\U0000bf08', '\U0000bf08', GLV),
+    ('\U0000bf09', '\U0000bf23', GLVT),
+    ('\U0000bf24', '\U0000bf24', GLV),
+    ('\U0000bf25', '\U0000bf3f', GLVT),
+    ('\U0000bf40', '\U0000bf40', GLV),
+    ('\U0000bf41', '\U0000bf5b', GLVT),
+    ('\U0000bf5c', '\U0000bf5c', GLV),
+    ('\U0000bf5d', '\U0000bf77', GLVT),
+    ('\U0000bf78', '\U0000bf78', GLV),
+    ('\U0000bf79', '\U0000bf93', GLVT),
+    ('\U0000bf94', '\U0000bf94', GLV),
+    ('\U0000bf95', '\U0000bfaf', GLVT),
+    ('\U0000bfb0', '\U0000bfb0', GLV),
+    ('\U0000bfb1', '\U0000bfcb', GLVT),
+    ('\U0000bfcc', '\U0000bfcc', GLV),
+    ('\U0000bfcd', '\U0000bfe7', GLVT),
+    ('\U0000bfe8', '\U0000bfe8', GLV),
+    ('\U0000bfe9', '\U0000c003', GLVT),
+    ('\U0000c004', '\U0000c004', GLV),
+    ('\U0000c005', '\U0000c01f', GLVT),
+    ('\U0000c020', '\U0000c020', GLV),
+    ('\U0000c021', '\U0000c03b', GLVT),
+    ('\U0000c03c', '\U0000c03c', GLV),
+    ('\U0000c03d', '\U0000c057', GLVT),
+    ('\U0000c058', '\U0000c058', GLV),
+    ('\U0000c059', '\U0000c073', GLVT),
+    ('\U0000c074', '\U0000c074', GLV),
+    ('\U0000c075', '\U0000c08f', GLVT),
+    ('\U0000c090', '\U0000c090', GLV),
+    ('\U0000c091', '\U0000c0ab', GLVT),
+    ('\U0000c0ac', '\U0000c0ac', GLV),
+    ('\U0000c0ad', '\U0000c0c7', GLVT),
+    ('\U0000c0c8', '\U0000c0c8', GLV),
+    ('\U0000c0c9', '\U0000c0e3', GLVT),
+    ('\U0000c0e4', '\U0000c0e4', GLV),
+    ('\U0000c0e5', '\U0000c0ff', GLVT),
+    ('\U0000c100', '\U0000c100', GLV),
+    ('\U0000c101', '\U0000c11b', GLVT),
+    ('\U0000c11c', '\U0000c11c', GLV),
+    ('\U0000c11d', '\U0000c137', GLVT),
+    ('\U0000c138', '\U0000c138', GLV),
+    ('\U0000c139', '\U0000c153', GLVT),
+    ('\U0000c154', '\U0000c154', GLV),
+    ('\U0000c155', '\U0000c16f', GLVT),
+    ('\U0000c170', '\U0000c170', GLV),
+    ('\U0000c171', '\U0000c18b', GLVT),
+    ('\U0000c18c', '\U0000c18c', GLV),
+    ('\U0000c18d', '\U0000c1a7', GLVT),
+    ('\U0000c1a8', '\U0000c1a8', GLV),
+    ('\U0000c1a9', '\U0000c1c3', GLVT),
+    ('\U0000c1c4', '\U0000c1c4', GLV),
+    ('\U0000c1c5', '\U0000c1df', GLVT),
+    ('\U0000c1e0', '\U0000c1e0', GLV),
+    ('\U0000c1e1', '\U0000c1fb', GLVT),
+    ('\U0000c1fc', '\U0000c1fc', GLV),
+    ('\U0000c1fd', '\U0000c217', GLVT),
+    ('\U0000c218', '\U0000c218', GLV),
+    ('\U0000c219', '\U0000c233', GLVT),
+    ('\U0000c234', '\U0000c234', GLV),
+    ('\U0000c235', '\U0000c24f', GLVT),
+    ('\U0000c250', '\U0000c250', GLV),
+    ('\U0000c251', '\U0000c26b', GLVT),
+    ('\U0000c26c', '\U0000c26c', GLV),
+    ('\U0000c26d', '\U0000c287', GLVT),
+    ('\U0000c288', '\U0000c288', GLV),
+    ('\U0000c289', '\U0000c2a3', GLVT),
+    ('\U0000c2a4', '\U0000c2a4', GLV),
+    ('\U0000c2a5', '\U0000c2bf', GLVT),
+    ('\U0000c2c0', '\U0000c2c0', GLV),
+    ('\U0000c2c1', '\U0000c2db', GLVT),
+    ('\U0000c2dc', '\U0000c2dc', GLV),
+    ('\U0000c2dd', '\U0000c2f7', GLVT),
+    ('\U0000c2f8', '\U0000c2f8', GLV),
+    ('\U0000c2f9', '\U0000c313', GLVT),
+    ('\U0000c314', '\U0000c314', GLV),
+    ('\U0000c315', '\U0000c32f', GLVT),
+    ('\U0000c330', '\U0000c330', GLV),
+    ('\U0000c331', '\U0000c34b', GLVT),
+    ('\U0000c34c', '\U0000c34c', GLV),
+    ('\U0000c34d', '\U0000c367', GLVT),
+    ('\U0000c368', '\U0000c368', GLV),
+    ('\U0000c369', '\U0000c383', GLVT),
+    ('\U0000c384', '\U0000c384', GLV),
+    ('\U0000c385', '\U0000c39f', GLVT),
+    ('\U0000c3a0', '\U0000c3a0', GLV),
+    ('\U0000c3a1', '\U0000c3bb', GLVT),
+    ('\U0000c3bc', '\U0000c3bc', GLV),
+    ('\U0000c3bd', '\U0000c3d7', GLVT),
+    ('\U0000c3d8', '\U0000c3d8', GLV),
+    ('\U0000c3d9', '\U0000c3f3', GLVT),
+    ('\U0000c3f4', '\U0000c3f4', GLV),
+    ('\U0000c3f5', '\U0000c40f', GLVT),
+    ('\U0000c410', '\U0000c410', GLV),
+    ('\U0000c411', '\U0000c42b', GLVT),
+    ('\U0000c42c', '\U0000c42c', GLV),
+    ('\U0000c42d', '\U0000c447', GLVT),
+    ('\U0000c448', '\U0000c448', GLV),
+    ('\U0000c449', '\U0000c463', GLVT),
+    ('\U0000c464', '\U0000c464', GLV),
+    ('\U0000c465', '\U0000c47f', GLVT),
+    ('\U0000c480', '\U0000c480', GLV),
+    ('\U0000c481', '\U0000c49b', GLVT),
+    ('\U0000c49c', '\U0000c49c', GLV),
+    ('\U0000c49d', '\U0000c4b7', GLVT),
+    ('\U0000c4b8', '\U0000c4b8', GLV),
+    ('\U0000c4b9', '\U0000c4d3', GLVT),
+    ('\U0000c4d4', '\U0000c4d4', GLV),
+    ('\U0000c4d5', '\U0000c4ef', GLVT),
+    ('\U0000c4f0', '\U0000c4f0', GLV),
+    ('\U0000c4f1', '\U0000c50b', GLVT),
+    ('\U0000c50c', '\U0000c50c', GLV),
+    ('\U0000c50d', '\U0000c527', GLVT),
+    ('\U0000c528', '\U0000c528', GLV),
+    ('\U0000c529', '\U0000c543', GLVT),
+    ('\U0000c544', '\U0000c544', GLV),
+    ('\U0000c545', '\U0000c55f', GLVT),
+    ('\U0000c560', '\U0000c560', GLV),
+    ('\U0000c561', '\U0000c57b', GLVT),
+    ('\U0000c57c', '\U0000c57c', GLV),
+    ('\U0000c57d', '\U0000c597', GLVT),
+    ('\U0000c598', '\U0000c598', GLV),
+    ('\U0000c599', '\U0000c5b3', GLVT),
+    ('\U0000c5b4', '\U0000c5b4', GLV),
+    ('\U0000c5b5', '\U0000c5cf', GLVT),
+    ('\U0000c5d0', '\U0000c5d0', GLV),
+    ('\U0000c5d1', '\U0000c5eb', GLVT),
+    ('\U0000c5ec', '\U0000c5ec', GLV),
+    ('\U0000c5ed', '\U0000c607', GLVT),
+    ('\U0000c608', '\U0000c608', GLV),
+    ('\U0000c609', '\U0000c623', GLVT),
+    ('\U0000c624', '\U0000c624', GLV),
+    ('\U0000c625', '\U0000c63f', GLVT),
+    ('\U0000c640', '\U0000c640', GLV),
+    ('\U0000c641', '\U0000c65b', GLVT),
+    ('\U0000c65c', '\U0000c65c', GLV),
+    ('\U0000c65d', '\U0000c677', GLVT),
+    ('\U0000c678', '\U0000c678', GLV),
+    ('\U0000c679', '\U0000c693', GLVT),
+    ('\U0000c694', '\U0000c694', GLV),
+    ('\U0000c695', '\U0000c6af', GLVT),
+    ('\U0000c6b0', '\U0000c6b0', GLV),
+    ('\U0000c6b1', '\U0000c6cb', GLVT),
+    ('\U0000c6cc', '\U0000c6cc', GLV),
+    ('\U0000c6cd', '\U0000c6e7', GLVT),
+    ('\U0000c6e8', '\U0000c6e8', GLV),
+    ('\U0000c6e9', '\U0000c703', GLVT),
+    ('\U0000c704', '\U0000c704', GLV),
+    ('\U0000c705', '\U0000c71f', GLVT),
+    ('\U0000c720', '\U0000c720', GLV),
+    ('\U0000c721', '\U0000c73b', GLVT),
+    ('\U0000c73c', '\U0000c73c', GLV),
+    ('\U0000c73d', '\U0000c757', GLVT),
+    ('\U0000c758', '\U0000c758', GLV),
+    ('\U0000c759', '\U0000c773', GLVT),
+    ('\U0000c774', '\U0000c774', GLV),
+    ('\U0000c775', '\U0000c78f', GLVT),
+    ('\U0000c790', '\U0000c790', GLV),
+    ('\U0000c791', '\U0000c7ab', GLVT),
+    ('\U0000c7ac', '\U0000c7ac', GLV),
+    ('\U0000c7ad', '\U0000c7c7', GLVT),
+    ('\U0000c7c8', '\U0000c7c8', GLV),
+    ('\U0000c7c9', '\U0000c7e3', GLVT),
+    ('\U0000c7e4', '\U0000c7e4', GLV),
+    ('\U0000c7e5', '\U0000c7ff', GLVT),
+    ('\U0000c800', '\U0000c800', GLV),
+    ('\U0000c801', '\U0000c81b', GLVT),
+    ('\U0000c81c', '\U0000c81c', GLV),
+    ('\U0000c81d', '\U0000c837', GLVT),
+    ('\U0000c838', '\U0000c838', GLV),
+    ('\U0000c839', '\U0000c853', GLVT),
+    ('\U0000c854', '\U0000c854', GLV),
+    ('\U0000c855', '\U0000c86f', GLVT),
+    ('\U0000c870', '\U0000c870', GLV),
+    ('\U0000c871', '\U0000c88b', GLVT),
+    ('\U0000c88c', '\U0000c88c', GLV),
+    ('\U0000c88d', '\U0000c8a7', GLVT),
+    ('\U0000c8a8', '\U0000c8a8', GLV),
+    ('\U0000c8a9', '\U0000c8c3', GLVT),
+    ('\U0000c8c4', '\U0000c8c4', GLV),
+    ('\U0000c8c5', '\U0000c8df', GLVT),
+    ('\U0000c8e0', '\U0000c8e0', GLV),
+    ('\U0000c8e1', '\U0000c8fb', GLVT),
+    ('\U0000c8fc', '\U0000c8fc', GLV),
+    ('\U0000c8fd', '\U0000c917', GLVT),
+    ('\U0000c918', '\U0000c918', GLV),
+    ('\U0000c919', '\U0000c933', GLVT),
+    ('\U0000c934', '\U0000c934', GLV),
+    ('\U0000c935', '\U0000c94f', GLVT),
+    ('\U0000c950', '\U0000c950', GLV),
+    ('\U0000c951', '\U0000c96b', GLVT),
+    ('\U0000c96c', '\U0000c96c', GLV),
+    ('\U0000c96d', '\U0000c987', GLVT),
+    ('\U0000c988', '\U0000c988', GLV),
+    ('\U0000c989', '\U0000c9a3', GLVT),
+    ('\U0000c9a4', '\U0000c9a4', GLV),
+    ('\U0000c9a5', '\U0000c9bf', GLVT),
+    ('\U0000c9c0', '\U0000c9c0', GLV),
+    ('\U0000c9c1', '\U0000c9db', GLVT),
+    ('\U0000c9dc', '\U0000c9dc', GLV),
+    ('\U0000c9dd', '\U0000c9f7', GLVT),
+    ('\U0000c9f8', '\U0000c9f8', GLV),
+    ('\U0000c9f9', '\U0000ca13', GLVT),
+    ('\U0000ca14', '\U0000ca14', GLV),
+    ('\U0000ca15', '\U0000ca2f', GLVT),
+    ('\U0000ca30', '\U0000ca30', GLV),
+    ('\U0000ca31', '\U0000ca4b', GLVT),
+    ('\U0000ca4c', '\U0000ca4c', GLV),
+    ('\U0000ca4d', '\U0000ca67', GLVT),
+    ('\U0000ca68', '\U0000ca68', GLV),
+    ('\U0000ca69', '\U0000ca83', GLVT),
+    ('\U0000ca84', '\U0000ca84', GLV),
+    ('\U0000ca85', '\U0000ca9f', GLVT),
+    ('\U0000caa0', '\U0000caa0', GLV),
+    ('\U0000caa1', '\U0000cabb', GLVT),
+    ('\U0000cabc', '\U0000cabc', GLV),
+    ('\U0000cabd', '\U0000cad7', GLVT),
+    ('\U0000cad8', '\U0000cad8', GLV),
+    ('\U0000cad9', '\U0000caf3', GLVT),
+    ('\U0000caf4', '\U0000caf4', GLV),
+    ('\U0000caf5', '\U0000cb0f', GLVT),
+    ('\U0000cb10', '\U0000cb10', GLV),
+    ('\U0000cb11', '\U0000cb2b', GLVT),
+    ('\U0000cb2c', '\U0000cb2c', GLV),
+    ('\U0000cb2d', '\U0000cb47', GLVT),
+    ('\U0000cb48', '\U0000cb48', GLV),
+    ('\U0000cb49', '\U0000cb63', GLVT),
+    ('\U0000cb64', '\U0000cb64', GLV),
+    ('\U0000cb65', '\U0000cb7f', GLVT),
+    ('\U0000cb80', '\U0000cb80', GLV),
+    ('\U0000cb81', '\U0000cb9b', GLVT),
+    ('\U0000cb9c', '\U0000cb9c', GLV),
+    ('\U0000cb9d', '\U0000cbb7', GLVT),
+    ('\U0000cbb8', '\U0000cbb8', GLV),
+    ('\U0000cbb9', '\U0000cbd3', GLVT),
+    ('\U0000cbd4', '\U0000cbd4', GLV),
+    ('\U0000cbd5', '\U0000cbef', GLVT),
+    ('\U0000cbf0', '\U0000cbf0', GLV),
+    ('\U0000cbf1', '\U0000cc0b', GLVT),
+    ('\U0000cc0c', '\U0000cc0c', GLV),
+    ('\U0000cc0d', '\U0000cc27', GLVT),
+    ('\U0000cc28', '\U0000cc28', GLV),
+    ('\U0000cc29', '\U0000cc43', GLVT),
+    ('\U0000cc44', '\U0000cc44', GLV),
+    ('\U0000cc45', '\U0000cc5f', GLVT),
+    ('\U0000cc60', '\U0000cc60', GLV),
+    ('\U0000cc61', '\U0000cc7b', GLVT),
+    ('\U0000cc7c', '\U0000cc7c', GLV),
+    ('\U0000cc7d', '\U0000cc97', GLVT),
+    ('\U0000cc98', '\U0000cc98', GLV),
+    ('\U0000cc99', '\U0000ccb3', GLVT),
+    ('\U0000ccb4', '\U0000ccb4', GLV),
+    ('\U0000ccb5', '\U0000cccf', GLVT),
+    ('\U0000ccd0', '\U0000ccd0', GLV),
+    ('\U0000ccd1', '\U0000cceb', GLVT),
+    ('\U0000ccec', '\U0000ccec', GLV),
+    ('\U0000cced', '\U0000cd07', GLVT),
+    ('\U0000cd08', '\U0000cd08', GLV),
+    ('\U0000cd09', '\U0000cd23', GLVT),
+    ('\U0000cd24', '\U0000cd24', GLV),
+    ('\U0000cd25', '\U0000cd3f', GLVT),
+    ('\U0000cd40', '\U0000cd40', GLV),
+    ('\U0000cd41', '\U0000cd5b', GLVT),
+    ('\U0000cd5c', '\U0000cd5c', GLV),
+    ('\U0000cd5d', '\U0000cd77', GLVT),
+    ('\U0000cd78', '\U0000cd78', GLV),
+    ('\U0000cd79', '\U0000cd93', GLVT),
+    ('\U0000cd94', '\U0000cd94', GLV),
+    ('\U0000cd95', '\U0000cdaf', GLVT),
+    ('\U0000cdb0', '\U0000cdb0', GLV),
+    ('\U0000cdb1', '\U0000cdcb', GLVT),
+    ('\U0000cdcc', '\U0000cdcc', GLV),
+    ('\U0000cdcd', '\U0000cde7', GLVT),
+    ('\U0000cde8', '\U0000cde8', GLV),
+    ('\U0000cde9', '\U0000ce03', GLVT),
+    ('\U0000ce04', '\U0000ce04', GLV),
+    ('\U0000ce05', '\U0000ce1f', GLVT),
+    ('\U0000ce20', '\U0000ce20', GLV),
+    ('\U0000ce21', '\U0000ce3b', GLVT),
+    ('\U0000ce3c', '\U0000ce3c', GLV),
+    ('\U0000ce3d', '\U0000ce57', GLVT),
+    ('\U0000ce58', '\U0000ce58', GLV),
+    ('\U0000ce59', '\U0000ce73', GLVT),
+    ('\U0000ce74', '\U0000ce74', GLV),
+    ('\U0000ce75', '\U0000ce8f', GLVT),
+    ('\U0000ce90', '\U0000ce90', GLV),
+    ('\U0000ce91', '\U0000ceab', GLVT),
+    ('\U0000ceac', '\U0000ceac', GLV),
+    ('\U0000cead', '\U0000cec7', GLVT),
+    ('\U0000cec8', '\U0000cec8', GLV),
+    ('\U0000cec9', '\U0000cee3', GLVT),
+    ('\U0000cee4', '\U0000cee4', GLV),
+    ('\U0000cee5', '\U0000ceff', GLVT),
+    ('\U0000cf00', '\U0000cf00', GLV),
+    ('\U0000cf01', '\U0000cf1b', GLVT),
+    ('\U0000cf1c', '\U0000cf1c', GLV),
+    ('\U0000cf1d', '\U0000cf37', GLVT),
+    ('\U0000cf38', '\U0000cf38', GLV),
+    ('\U0000cf39', '\U0000cf53', GLVT),
+    ('\U0000cf54', '\U0000cf54', GLV),
+    ('\U0000cf55', '\U0000cf6f', GLVT),
+    ('\U0000cf70', '\U0000cf70', GLV),
+    ('\U0000cf71', '\U0000cf8b', GLVT),
+    ('\U0000cf8c', '\U0000cf8c', GLV),
+    ('\U0000cf8d', '\U0000cfa7', GLVT),
+    ('\U0000cfa8', '\U0000cfa8', GLV),
+    ('\U0000cfa9', '\U0000cfc3', GLVT),
+    ('\U0000cfc4', '\U0000cfc4', GLV),
+    ('\U0000cfc5', '\U0000cfdf', GLVT),
+    ('\U0000cfe0', '\U0000cfe0', GLV),
+    ('\U0000cfe1', '\U0000cffb', GLVT),
+    ('\U0000cffc', '\U0000cffc', GLV),
+    ('\U0000cffd', '\U0000d017', GLVT),
+    ('\U0000d018', '\U0000d018', GLV),
+    ('\U0000d019', '\U0000d033', GLVT),
+    ('\U0000d034', '\U0000d034', GLV),
+    ('\U0000d035', '\U0000d04f', GLVT),
+    ('\U0000d050', '\U0000d050', GLV),
+    ('\U0000d051', '\U0000d06b', GLVT),
+    ('\U0000d06c', '\U0000d06c', GLV),
+    ('\U0000d06d', '\U0000d087', GLVT),
+    ('\U0000d088', '\U0000d088', GLV),
+    ('\U0000d089', '\U0000d0a3', GLVT),
+    ('\U0000d0a4', '\U0000d0a4', GLV),
+    ('\U0000d0a5', '\U0000d0bf', GLVT),
+    ('\U0000d0c0', '\U0000d0c0', GLV),
+    ('\U0000d0c1', '\U0000d0db', GLVT),
+    ('\U0000d0dc', '\U0000d0dc', GLV),
+    ('\U0000d0dd', '\U0000d0f7', GLVT),
+    ('\U0000d0f8', '\U0000d0f8', GLV),
+    ('\U0000d0f9', '\U0000d113', GLVT),
+    ('\U0000d114', '\U0000d114', GLV),
+    ('\U0000d115', '\U0000d12f', GLVT),
+    ('\U0000d130', '\U0000d130', GLV),
+    ('\U0000d131', '\U0000d14b', GLVT),
+    ('\U0000d14c', '\U0000d14c', GLV),
+    ('\U0000d14d', '\U0000d167', GLVT),
+    ('\U0000d168', '\U0000d168', GLV),
+    ('\U0000d169', '\U0000d183', GLVT),
+    ('\U0000d184', '\U0000d184', GLV),
+    ('\U0000d185', '\U0000d19f', GLVT),
+    ('\U0000d1a0', '\U0000d1a0', GLV),
+    ('\U0000d1a1', '\U0000d1bb', GLVT),
+    ('\U0000d1bc', '\U0000d1bc', GLV),
+    ('\U0000d1bd', '\U0000d1d7', GLVT),
+    ('\U0000d1d8', '\U0000d1d8', GLV),
+    ('\U0000d1d9', '\U0000d1f3', GLVT),
+    ('\U0000d1f4', '\U0000d1f4', GLV),
+    ('\U0000d1f5', '\U0000d20f', GLVT),
+    ('\U0000d210', '\U0000d210', GLV),
+    ('\U0000d211', '\U0000d22b', GLVT),
+    ('\U0000d22c', '\U0000d22c', GLV),
+    ('\U0000d22d', '\U0000d247', GLVT),
+    ('\U0000d248', '\U0000d248', GLV),
+    ('\U0000d249', '\U0000d263', GLVT),
+    ('\U0000d264', '\U0000d264', GLV),
+    ('\U0000d265', '\U0000d27f', GLVT),
+    ('\U0000d280', '\U0000d280', GLV),
+    ('\U0000d281', '\U0000d29b', GLVT),
+    ('\U0000d29c', '\U0000d29c', GLV),
+    ('\U0000d29d', '\U0000d2b7', GLVT),
+    ('\U0000d2b8', '\U0000d2b8', GLV),
+    ('\U0000d2b9', '\U0000d2d3', GLVT),
+    ('\U0000d2d4', '\U0000d2d4', GLV),
+    ('\U0000d2d5', '\U0000d2ef', GLVT),
+    ('\U0000d2f0', '\U0000d2f0', GLV),
+    ('\U0000d2f1', '\U0000d30b', GLVT),
+    ('\U0000d30c', '\U0000d30c', GLV),
+    ('\U0000d30d', '\U0000d327', GLVT),
+    ('\U0000d328', '\U0000d328', GLV),
+    ('\U0000d329', '\U0000d343', GLVT),
+    ('\U0000d344', '\U0000d344', GLV),
+    ('\U0000d345', '\U0000d35f', GLVT),
+    ('\U0000d360', '\U0000d360', GLV),
+    ('\U0000d361', '\U0000d37b', GLVT),
+    ('\U0000d37c', '\U0000d37c', GLV),
+    ('\U0000d37d', '\U0000d397', GLVT),
+    ('\U0000d398', '\U0000d398', GLV),
+    ('\U0000d399', '\U0000d3b3', GLVT),
+    ('\U0000d3b4', '\U0000d3b4', GLV),
+    ('\U0000d3b5', '\U0000d3cf', GLVT),
+    ('\U0000d3d0', '\U0000d3d0', GLV),
+    ('\U0000d3d1', '\U0000d3eb', GLVT),
+    ('\U0000d3ec', '\U0000d3ec', GLV),
+    ('\U0000d3ed', '\U0000d407', GLVT),
+    ('\U0000d408', '\U0000d408', GLV),
+    ('\U0000d409', '\U0000d423', GLVT),
+    ('\U0000d424', '\U0000d424', GLV),
+    ('\U0000d425', '\U0000d43f', GLVT),
+    ('\U0000d440', '\U0000d440', GLV),
+    ('\U0000d441', '\U0000d45b', GLVT),
+    ('\U0000d45c', '\U0000d45c', GLV),
+    ('\U0000d45d', '\U0000d477', GLVT),
+    ('\U0000d478', '\U0000d478', GLV),
+    ('\U0000d479', '\U0000d493', GLVT),
+    ('\U0000d494', '\U0000d494', GLV),
+    ('\U0000d495', '\U0000d4af', GLVT),
+    ('\U0000d4b0', '\U0000d4b0', GLV),
+    ('\U0000d4b1', '\U0000d4cb', GLVT),
+    ('\U0000d4cc', '\U0000d4cc', GLV),
+    ('\U0000d4cd', '\U0000d4e7', GLVT),
+    ('\U0000d4e8', '\U0000d4e8', GLV),
+    ('\U0000d4e9', '\U0000d503', GLVT),
+    ('\U0000d504', '\U0000d504', GLV),
+    ('\U0000d505', '\U0000d51f', GLVT),
+    ('\U0000d520', '\U0000d520', GLV),
+    ('\U0000d521', '\U0000d53b', GLVT),
+    ('\U0000d53c', '\U0000d53c', GLV),
+    ('\U0000d53d', '\U0000d557', GLVT),
+    ('\U0000d558', '\U0000d558', GLV),
+    ('\U0000d559', '\U0000d573', GLVT),
+    ('\U0000d574', '\U0000d574', GLV),
+    ('\U0000d575', '\U0000d58f', GLVT),
+    ('\U0000d590', '\U0000d590', GLV),
+    ('\U0000d591', '\U0000d5ab', GLVT),
+    ('\U0000d5ac', '\U0000d5ac', GLV),
+    ('\U0000d5ad', '\U0000d5c7', GLVT),
+    ('\U0000d5c8', '\U0000d5c8', GLV),
+    ('\U0000d5c9', '\U0000d5e3', GLVT),
+    ('\U0000d5e4', '\U0000d5e4', GLV),
+    ('\U0000d5e5', '\U0000d5ff', GLVT),
+    ('\U0000d600', '\U0000d600', GLV),
+    ('\U0000d601', '\U0000d61b', GLVT),
+    ('\U0000d61c', '\U0000d61c', GLV),
+    ('\U0000d61d', '\U0000d637', GLVT),
+    ('\U0000d638', '\U0000d638', GLV),
+    ('\U0000d639', '\U0000d653', GLVT),
+    ('\U0000d654', '\U0000d654', GLV),
+    ('\U0000d655', '\U0000d66f', GLVT),
+    ('\U0000d670', '\U0000d670', GLV),
+    ('\U0000d671', '\U0000d68b', GLVT),
+    ('\U0000d68c', '\U0000d68c', GLV),
+    ('\U0000d68d', '\U0000d6a7', GLVT),
+    ('\U0000d6a8', '\U0000d6a8', GLV),
+    ('\U0000d6a9', '\U0000d6c3', GLVT),
+    ('\U0000d6c4', '\U0000d6c4', GLV),
+    ('\U0000d6c5', '\U0000d6df', GLVT),
+    ('\U0000d6e0', '\U0000d6e0', GLV),
+    ('\U0000d6e1', '\U0000d6fb', GLVT),
+    ('\U0000d6fc', '\U0000d6fc', GLV),
+    ('\U0000d6fd', '\U0000d717', GLVT),
+    ('\U0000d718', '\U0000d718', GLV),
+    ('\U0000d719', '\U0000d733', GLVT),
+    ('\U0000d734', '\U0000d734', GLV),
+    ('\U0000d735', '\U0000d74f', GLVT),
+    ('\U0000d750', '\U0000d750', GLV),
+    ('\U0000d751', '\U0000d76b', GLVT),
+    ('\U0000d76c', '\U0000d76c', GLV),
+    ('\U0000d76d', '\U0000d787', GLVT),
+    ('\U0000d788', '\U0000d788', GLV),
+    ('\U0000d789', '\U0000d7a3', GLVT),
+    ('\U0000d7b0', '\U0000d7c6', GV),
+    ('\U0000d7cb', '\U0000d7fb', GT),
+    ('\U0000fb1e', '\U0000fb1e', GConjunctExtend),
+    ('\U0000fe00', '\U0000fe0f', GConjunctExtend),
+    ('\U0000fe20', '\U0000fe2f', GExtend),
+    ('\U0000feff', '\U0000feff', GControl),
+    ('\U0000ff9e', '\U0000ff9f', GConjunctExtend),
+    ('\U0000fff0', '\U0000fffb', GControl),
+    ('\U000101fd', '\U000101fd', GConjunctExtend),
+    ('\U000102e0', '\U000102e0', GExtend),
+    ('\U00010376', '\U0001037a', GConjunctExtend),
+    ('\U00010a01', '\U00010a03', GConjunctExtend),
+    ('\U00010a05', '\U00010a06', GExtend),
+    ('\U00010a0c', '\U00010a0f', GConjunctExtend),
+    ('\U00010a38', '\U00010a3a', GConjunctExtend),
+    ('\U00010a3f', '\U00010a3f', GExtend),
+    ('\U00010ae5', '\U00010ae6', GConjunctExtend),
+    ('\U00010d24', '\U00010d27', GConjunctExtend),
+    ('\U00010d69', '\U00010d6d', GExtend),
+    ('\U00010eab', '\U00010eac', GConjunctExtend),
+    ('\U00010efc', '\U00010eff', GConjunctExtend),
+    ('\U00010f46', '\U00010f50', GExtend),
+    ('\U00010f82', '\U00010f85', GConjunctExtend),
+    ('\U00011000', '\U00011000', GSpacingMark),
+    ('\U00011001', '\U00011001', GConjunctExtend),
+    ('\U00011002', '\U00011002', GSpacingMark),
+    ('\U00011038', '\U00011046', GExtend),
+    ('\U00011070', '\U00011070', GConjunctExtend),
+    ('\U00011073', '\U00011074', GConjunctExtend),
+    ('\U0001107f', '\U00011081', GExtend),
+    ('\U00011082', '\U00011082', GSpacingMark),
+    ('\U000110b0', '\U000110b2', GSpacingMark),
+    ('\U000110b3', '\U000110b6', GConjunctExtend),
+    ('\U000110b7', '\U000110b8', GSpacingMark),
+    ('\U000110b9', '\U000110ba', GConjunctExtend),
+    ('\U000110bd', '\U000110bd', GPrepend),
+    ('\U000110c2', '\U000110c2', GExtend),
+    ('\U000110cd', '\U000110cd', GPrepend),
+    ('\U00011100', '\U00011102', GConjunctExtend),
+    ('\U00011127', '\U0001112b', GConjunctExtend),
+    ('\U0001112c', '\U0001112c', GSpacingMark),
+    ('\U0001112d', '\U00011134', GExtend),
+    ('\U00011145', '\U00011146', GSpacingMark),
+    ('\U00011173', '\U00011173', GConjunctExtend),
+    ('\U00011180', '\U00011181', GConjunctExtend),
+    ('\U00011182', '\U00011182', GSpacingMark),
+    ('\U000111b3', '\U000111b5', GSpacingMark),
+    ('\U000111b6', '\U000111be', GExtend),
+    ('\U000111bf', '\U000111bf', GSpacingMark),
+    ('\U000111c0', '\U000111c0', GConjunctExtend),
+    ('\U000111c2', '\U000111c3', GPrepend),
+    ('\U000111c9', '\U000111cc', GConjunctExtend),
+    ('\U000111ce', '\U000111ce', GSpacingMark),
+    ('\U000111cf', '\U000111cf', GExtend),
+    ('\U0001122c', '\U0001122e', GSpacingMark),
+    ('\U0001122f', '\U00011231', GConjunctExtend),
+    ('\U00011232', '\U00011233', GSpacingMark),
+    ('\U00011234', '\U00011237', GConjunctExtend),
+    ('\U0001123e', '\U0001123e', GExtend),
+    ('\U00011241', '\U00011241', GConjunctExtend),
+    ('\U000112df', '\U000112df', GConjunctExtend),
+    ('\U000112e0', '\U000112e2', GSpacingMark),
+    ('\U000112e3', '\U000112ea', GExtend),
+    ('\U00011300', '\U00011301', GConjunctExtend),
+    ('\U00011302', '\U00011303', GSpacingMark),
+    ('\U0001133b', '\U0001133c', GConjunctExtend),
+    ('\U0001133e', '\U0001133e', GExtend),
+    ('\U0001133f', '\U0001133f', GSpacingMark),
+    ('\U00011340', '\U00011340', GConjunctExtend),
+    ('\U00011341', '\U00011344', GSpacingMark),
+    ('\U00011347', '\U00011348', GSpacingMark),
+    ('\U0001134b', '\U0001134c', GSpacingMark),
+    ('\U0001134d', '\U0001134d', GConjunctExtend),
+    ('\U00011357', '\U00011357', GExtend),
+    ('\U00011362', '\U00011363', GSpacingMark),
+    ('\U00011366', '\U0001136c', GConjunctExtend),
+    ('\U00011370', '\U00011374', GConjunctExtend),
+    ('\U000113b8', '\U000113b8', GExtend),
+    ('\U000113b9', '\U000113ba', GSpacingMark),
+    ('\U000113bb', '\U000113c0', GConjunctExtend),
+    ('\U000113c2', '\U000113c2', GConjunctExtend),
+    ('\U000113c5', '\U000113c5', GExtend),
+    ('\U000113c7', '\U000113c9', GConjunctExtend),
+    ('\U000113ca', '\U000113ca', GSpacingMark),
+    ('\U000113cc', '\U000113cd', GSpacingMark),
+    ('\U000113ce', '\U000113d0', GConjunctExtend),
+    ('\U000113d1', '\U000113d1', GPrepend),
+    ('\U000113d2', '\U000113d2', GExtend),
+    ('\U000113e1', '\U000113e2', GConjunctExtend),
+    ('\U00011435', '\U00011437', GSpacingMark),
+    ('\U00011438', '\U0001143f', GConjunctExtend),
+    ('\U00011440', '\U00011441', GSpacingMark),
+    ('\U00011442', '\U00011444', GExtend),
+    ('\U00011445', '\U00011445', GSpacingMark),
+    ('\U00011446', '\U00011446', GConjunctExtend),
+    ('\U0001145e', '\U0001145e', GConjunctExtend),
+    ('\U000114b0', '\U000114b0', GExtend),
+    ('\U000114b1', '\U000114b2', GSpacingMark),
+    ('\U000114b3', '\U000114b8', GConjunctExtend),
+    ('\U000114b9', '\U000114b9', GSpacingMark),
+    ('\U000114ba', '\U000114ba', GConjunctExtend),
+    ('\U000114bb', '\U000114bc', GSpacingMark),
+    ('\U000114bd', '\U000114bd', GExtend),
+    ('\U000114be', '\U000114be', GSpacingMark),
+    ('\U000114bf', '\U000114c0', GConjunctExtend),
+    ('\U000114c1', '\U000114c1', GSpacingMark),
+    ('\U000114c2', '\U000114c3', GConjunctExtend),
+    ('\U000115af', '\U000115af', GExtend),
+    ('\U000115b0', '\U000115b1', GSpacingMark),
+    ('\U000115b2', '\U000115b5', GConjunctExtend),
+    ('\U000115b8', '\U000115bb', GSpacingMark),
+    ('\U000115bc', '\U000115bd', GConjunctExtend),
+    ('\U000115be', '\U000115be', GSpacingMark),
+    ('\U000115bf', '\U000115c0', GExtend),
+    ('\U000115dc', '\U000115dd', GConjunctExtend),
+    ('\U00011630', '\U00011632', GSpacingMark),
+    ('\U00011633', '\U0001163a', GConjunctExtend),
+    ('\U0001163b', '\U0001163c', GSpacingMark),
+    ('\U0001163d', '\U0001163d', GExtend),
+    ('\U0001163e', '\U0001163e', GSpacingMark),
+    ('\U0001163f', '\U00011640', GConjunctExtend),
+    ('\U000116ab', '\U000116ab', GConjunctExtend),
+    ('\U000116ac', '\U000116ac', GSpacingMark),
+    ('\U000116ad', '\U000116ad', GExtend),
+    ('\U000116ae', '\U000116af', GSpacingMark),
+    ('\U000116b0', '\U000116b7', GConjunctExtend),
+    ('\U0001171d', '\U0001171d', GConjunctExtend),
+    ('\U0001171e', '\U0001171e', GSpacingMark),
+    ('\U0001171f', '\U0001171f', GExtend),
+    ('\U00011722', '\U00011725', GConjunctExtend),
+    ('\U00011726', '\U00011726', GSpacingMark),
+    ('\U00011727', '\U0001172b', GConjunctExtend),
+    ('\U0001182c', '\U0001182e', GSpacingMark),
+    ('\U0001182f', '\U00011837', GExtend),
+    ('\U00011838', '\U00011838', GSpacingMark),
+    ('\U00011839', '\U0001183a', GConjunctExtend),
+    ('\U00011930', '\U00011930', GConjunctExtend),
+    ('\U00011931', '\U00011935', GSpacingMark),
+    ('\U00011937', '\U00011938', GSpacingMark),
+    ('\U0001193b', '\U0001193e', GExtend),
+    ('\U0001193f', '\U0001193f', GPrepend),
+    ('\U00011940', '\U00011940', GSpacingMark),
+    ('\U00011941', '\U00011941', GPrepend),
+    ('\U00011942', '\U00011942', GSpacingMark),
+    ('\U00011943', '\U00011943', GConjunctExtend),
+    ('\U000119d1', '\U000119d3', GSpacingMark),
+    ('\U000119d4', '\U000119d7', GConjunctExtend),
+    ('\U000119da', '\U000119db', GExtend),
+    ('\U000119dc', '\U000119df', GSpacingMark),
+    ('\U000119e0', '\U000119e0', GConjunctExtend),
+    ('\U000119e4', '\U000119e4', GSpacingMark),
+    ('\U00011a01', '\U00011a0a', GConjunctExtend),
+    ('\U00011a33', '\U00011a38', GExtend),
+    ('\U00011a39', '\U00011a39', GSpacingMark),
+    ('\U00011a3a', '\U00011a3a', GPrepend),
+    ('\U00011a3b', '\U00011a3e', GConjunctExtend),
+    ('\U00011a47', '\U00011a47', GConjunctExtend),
+    ('\U00011a51', '\U00011a56', GExtend),
+    ('\U00011a57', '\U00011a58', GSpacingMark),
+    ('\U00011a59', '\U00011a5b', GConjunctExtend),
+    ('\U00011a84', '\U00011a89', GPrepend),
+    ('\U00011a8a', '\U00011a96', GConjunctExtend),
+    ('\U00011a97', '\U00011a97', GSpacingMark),
+    ('\U00011a98', '\U00011a99', GExtend),
+    ('\U00011c2f', '\U00011c2f', GSpacingMark),
+    ('\U00011c30', '\U00011c36', GConjunctExtend),
+    ('\U00011c38', '\U00011c3d', GConjunctExtend),
+    ('\U00011c3e', '\U00011c3e', GSpacingMark),
+    ('\U00011c3f', '\U00011c3f', GExtend),
+    ('\U00011c92', '\U00011ca7', GConjunctExtend),
+    ('\U00011ca9', '\U00011ca9', GSpacingMark),
+    ('\U00011caa', '\U00011cb0', GConjunctExtend),
+    ('\U00011cb1', '\U00011cb1', GSpacingMark),
+    ('\U00011cb2', '\U00011cb3', GExtend),
+    ('\U00011cb4', '\U00011cb4', GSpacingMark),
+    ('\U00011cb5', '\U00011cb6', GConjunctExtend),
+    ('\U00011d31', '\U00011d36', GConjunctExtend),
+    ('\U00011d3a', '\U00011d3a', GExtend),
+    ('\U00011d3c', '\U00011d3d', GConjunctExtend),
+    ('\U00011d3f', '\U00011d45', GConjunctExtend),
+    ('\U00011d46', '\U00011d46', GPrepend),
+    ('\U00011d47', '\U00011d47', GExtend),
+    ('\U00011d8a', '\U00011d8e', GSpacingMark),
+    ('\U00011d90', '\U00011d91', GConjunctExtend),
+    ('\U00011d93', '\U00011d94', GSpacingMark),
+    ('\U00011d95', '\U00011d95', GConjunctExtend),
+    ('\U00011d96', '\U00011d96', GSpacingMark),
+    ('\U00011d97', '\U00011d97', GExtend),
+    ('\U00011ef3', '\U00011ef4', GConjunctExtend),
+    ('\U00011ef5', '\U00011ef6', GSpacingMark),
+    ('\U00011f00', '\U00011f01', GConjunctExtend),
+    ('\U00011f02', '\U00011f02', GPrepend),
+    ('\U00011f03', '\U00011f03', GSpacingMark),
+    ('\U00011f34', '\U00011f35', GSpacingMark),
+    ('\U00011f36', '\U00011f3a', GExtend),
+    ('\U00011f3e', '\U00011f3f', GSpacingMark),
+    ('\U00011f40', '\U00011f42', GConjunctExtend),
+    ('\U00011f5a', '\U00011f5a', GConjunctExtend),
+    ('\U00013430', '\U0001343f', GControl),
+    ('\U00013440', '\U00013440', GExtend),
+    ('\U00013447', '\U00013455', GConjunctExtend),
+    ('\U0001611e', '\U00016129', GConjunctExtend),
+    ('\U0001612a', '\U0001612c', GSpacingMark),
+    ('\U0001612d', '\U0001612f', GExtend),
+    ('\U00016af0', '\U00016af4', GConjunctExtend),
+    ('\U00016b30', '\U00016b36', GConjunctExtend),
+    ('\U00016d63', '\U00016d63', GV),
+    ('\U00016d67', '\U00016d6a', GV),
+    ('\U00016f4f', '\U00016f4f', GExtend),
+    ('\U00016f51', '\U00016f87', GSpacingMark),
+    ('\U00016f8f', '\U00016f92', GConjunctExtend),
+    ('\U00016fe4', '\U00016fe4', GConjunctExtend),
+    ('\U00016ff0', '\U00016ff1', GExtend),
+    ('\U0001bc9d', '\U0001bc9e', GConjunctExtend),
+    ('\U0001bca0', '\U0001bca3', GControl),
+    ('\U0001cf00', '\U0001cf2d', GConjunctExtend),
+    ('\U0001cf30', '\U0001cf46', GExtend),
+    ('\U0001d165', '\U0001d169', GConjunctExtend),
+    ('\U0001d16d', '\U0001d172', GConjunctExtend),
+    ('\U0001d173', '\U0001d17a', GControl),
+    ('\U0001d17b', '\U0001d182', GExtend),
+    ('\U0001d185', '\U0001d18b', GConjunctExtend),
+    ('\U0001d1aa', '\U0001d1ad', GConjunctExtend),
+    ('\U0001d242', '\U0001d244', GExtend),
+    ('\U0001da00', '\U0001da36', GConjunctExtend),
+    ('\U0001da3b', '\U0001da6c', GConjunctExtend),
+    ('\U0001da75', '\U0001da75', GExtend),
+    ('\U0001da84', '\U0001da84', GConjunctExtend),
+    ('\U0001da9b', '\U0001da9f', GConjunctExtend),
+    ('\U0001daa1', '\U0001daaf', GExtend),
+    ('\U0001e000', '\U0001e006', GConjunctExtend),
+    ('\U0001e008', '\U0001e018', GConjunctExtend),
+    ('\U0001e01b', '\U0001e021', GExtend),
+    ('\U0001e023', '\U0001e024', GConjunctExtend),
+    ('\U0001e026', '\U0001e02a', GConjunctExtend),
+    ('\U0001e08f', '\U0001e08f', GExtend),
+    ('\U0001e130', '\U0001e136', GConjunctExtend),
+    ('\U0001e2ae', '\U0001e2ae', GConjunctExtend),
+    ('\U0001e2ec', '\U0001e2ef', GExtend),
+    ('\U0001e4ec', '\U0001e4ef', GConjunctExtend),
+    ('\U0001e5ee', '\U0001e5ef', GConjunctExtend),
+    ('\U0001e8d0', '\U0001e8d6', GExtend),
+    ('\U0001e944', '\U0001e94a', GConjunctExtend),
+    ('\U0001f000', '\U0001f0ff', GExtPict),
+    ('\U0001f10d', '\U0001f10f', GExtPict),
+    ('\U0001f12f', '\U0001f12f', GExtPict),
+    ('\U0001f16c', '\U0001f171', GExtPict),
+    ('\U0001f17e', '\U0001f17f', GExtPict),
+    ('\U0001f18e', '\U0001f18e', GExtPict),
+    ('\U0001f191', '\U0001f19a', GExtPict),
+    ('\U0001f1ad', '\U0001f1e5', GExtPict),
+    ('\U0001f1e6', '\U0001f1ff', GRegionalIndicator),
+    ('\U0001f201', '\U0001f20f', GExtPict),
+    ('\U0001f21a', '\U0001f21a', GExtPict),
+    ('\U0001f22f', '\U0001f22f', GExtPict),
+    ('\U0001f232', '\U0001f23a', GExtPict),
+    ('\U0001f23c', '\U0001f23f', GExtPict),
+    ('\U0001f249', '\U0001f3fa', GExtPict),
+    ('\U0001f3fb', '\U0001f3ff', GConjunctExtend),
+    ('\U0001f400', '\U0001f53d', GExtPict),
+    ('\U0001f546', '\U0001f64f', GExtPict),
+    ('\U0001f680', '\U0001f6ff', GExtPict),
+    ('\U0001f774', '\U0001f77f', GExtPict),
+    ('\U0001f7d5', '\U0001f7ff', GExtPict),
+    ('\U0001f80c', '\U0001f80f', GExtPict),
+    ('\U0001f848', '\U0001f84f', GExtPict),
+    ('\U0001f85a', '\U0001f85f', GExtPict),
+    ('\U0001f888', '\U0001f88f', GExtPict),
+    ('\U0001f8ae', '\U0001f8ff', GExtPict),
+    ('\U0001f90c', '\U0001f93a', GExtPict),
+    ('\U0001f93c', '\U0001f945', GExtPict),
+    ('\U0001f947', '\U0001faff', GExtPict),
+    ('\U0001fc00', '\U0001fffd', GExtPict),
+    ('\U000e0000', '\U000e001f', GControl),
+    ('\U000e0020', '\U000e007f', GExtend),
+    ('\U000e0080', '\U000e00ff', GControl),
+    ('\U000e0100', '\U000e01ef', GConjunctExtend),
+    ('\U000e01f0', '\U000e0fff', GControl)
+];
+
+// The categories of word boundaries (`\b{wb}`), from Unicode 16.0.0.
+// Characters that aren't listed are `WOther`.
+pub static WORD_CATS: &'static [(char, char, WordCat)] = &[
+    ('\U0000000a', '\U0000000a', WLF),
+    ('\U0000000b', '\U0000000c', WNewline),
+    ('\U0000000d', '\U0000000d', WCR),
+    ('\U00000020', '\U00000020', WWSegSpace),
+    ('\U00000022', '\U00000022', WDoubleQuote),
+    ('\U00000027', '\U00000027', WSingleQuote),
+    ('\U0000002c', '\U0000002c', WMidNum),
+    ('\U0000002e', '\U0000002e', WMidNumLet),
+    ('\U00000030', '\U00000039', WNumeric),
+    ('\U0000003a', '\U0000003a', WMidLetter),
+    ('\U0000003b', '\U0000003b', WMidNum),
+    ('\U00000041', '\U0000005a', WALetter),
+    ('\U0000005f', '\U0000005f', WExtendNumLet),
+    ('\U00000061', '\U0000007a', WALetter),
+    ('\U00000085', '\U00000085', WNewline),
+    ('\U000000aa', '\U000000aa', WALetter),
+    ('\U000000ad', '\U000000ad', WFormat),
+    ('\U000000b5', '\U000000b5', WALetter),
+    ('\U000000b7', '\U000000b7', WMidLetter),
+    ('\U000000ba', '\U000000ba', WALetter),
+    ('\U000000c0', '\U000000d6', WALetter),
+    ('\U000000d8', '\U000000f6', WALetter),
+    ('\U000000f8', '\U000002d7', WALetter),
+    ('\U000002de', '\U000002ff', WALetter),
+    ('\U00000300', '\U0000036f', WExtend),
+    ('\U00000370', '\U00000374', WALetter),
+    ('\U00000376', '\U00000377', WALetter),
+    ('\U0000037a', '\U0000037d', WALetter),
+    ('\U0000037e', '\U0000037e', WMidNum),
+    ('\U0000037f', '\U0000037f', WALetter),
+    ('\U00000386', '\U00000386', WALetter),
+    ('\U00000387', '\U00000387', WMidLetter),
+    ('\U00000388', '\U0000038a', WALetter),
+    ('\U0000038c', '\U0000038c', WALetter),
+    ('\U0000038e', '\U000003a1', WALetter),
+    ('\U000003a3', '\U000003f5', WALetter),
+    ('\U000003f7', '\U00000481', WALetter),
+    ('\U00000483', '\U00000489', WExtend),
+    ('\U0000048a', '\U0000052f', WALetter),
+    ('\U00000531', '\U00000556', WALetter),
+    ('\U00000559', '\U0000055c', WALetter),
+    ('\U0000055e', '\U0000055e', WALetter),
+    ('\U0000055f', '\U0000055f', WMidLetter),
+    ('\U00000560', '\U00000588', WALetter),
+    ('\U00000589', '\U00000589', WMidNum),
+    ('\U0000058a', '\U0000058a', WALetter),
+    ('\U00000591', '\U000005bd', WExtend),
+    ('\U000005bf', '\U000005bf', WExtend),
+    ('\U000005c1', '\U000005c2', WExtend),
+    ('\U000005c4', '\U000005c5', WExtend),
+    ('\U000005c7', '\U000005c7', WExtend),
+    ('\U000005d0', '\U000005ea', WHebrewLetter),
+    ('\U000005ef', '\U000005f2', WHebrewLetter),
+    ('\U000005f3', '\U000005f3', WALetter),
+    ('\U000005f4', '\U000005f4', WMidLetter),
+    ('\U00000600', '\U00000605', WNumeric),
+    ('\U0000060c', '\U0000060d', WMidNum),
+    ('\U00000610', '\U0000061a', WExtend),
+    ('\U0000061c', '\U0000061c', WFormat),
+    ('\U00000620', '\U0000064a', WALetter),
+    ('\U0000064b', '\U0000065f', WExtend),
+    ('\U00000660', '\U00000669', WNumeric),
+    ('\U0000066b', '\U0000066b', WNumeric),
+    ('\U0000066c', '\U0000066c', WMidNum),
+    ('\U0000066e', '\U0000066f', WALetter),
+    ('\U00000670', '\U00000670', WExtend),
+    ('\U00000671', '\U000006d3', WALetter),
+    ('\U000006d5', '\U000006d5', WALetter),
+    ('\U000006d6', '\U000006dc', WExtend),
+    ('\U000006dd', '\U000006dd', WNumeric),
+    ('\U000006df', '\U000006e4', WExtend),
+    ('\U000006e5', '\U000006e6', WALetter),
+    ('\U000006e7', '\U000006e8', WExtend),
+    ('\U000006ea', '\U000006ed', WExtend),
+    ('\U000006ee', '\U000006ef', WALetter),
+    ('\U000006f0', '\U000006f9', WNumeric),
+    ('\U000006fa', '\U000006fc', WALetter),
+    ('\U000006ff', '\U000006ff', WALetter),
+    ('\U0000070f', '\U00000710', WALetter),
+    ('\U00000711', '\U00000711', WExtend),
+    ('\U00000712', '\U0000072f', WALetter),
+    ('\U00000730', '\U0000074a', WExtend),
+    ('\U0000074d', '\U000007a5', WALetter),
+    ('\U000007a6', '\U000007b0', WExtend),
+    ('\U000007b1', '\U000007b1', WALetter),
+    ('\U000007c0', '\U000007c9', WNumeric),
+    ('\U000007ca', '\U000007ea', WALetter),
+    ('\U000007eb', '\U000007f3', WExtend),
+    ('\U000007f4', '\U000007f5', WALetter),
+    ('\U000007f8', '\U000007f8', WMidNum),
+    ('\U000007fa', '\U000007fa', WALetter),
+    ('\U000007fd', '\U000007fd', WExtend),
+    ('\U00000800', '\U00000815', WALetter),
+    ('\U00000816', '\U00000819', WExtend),
+    ('\U0000081a', '\U0000081a', WALetter),
+    ('\U0000081b', '\U00000823', WExtend),
+    ('\U00000824', '\U00000824', WALetter),
+    ('\U00000825', '\U00000827', WExtend),
+    ('\U00000828', '\U00000828', WALetter),
+    ('\U00000829', '\U0000082d', WExtend),
+    ('\U00000840', '\U00000858', WALetter),
+    ('\U00000859', '\U0000085b', WExtend),
+    ('\U00000860', '\U0000086a', WALetter),
+    ('\U00000870', '\U00000887', WALetter),
+    ('\U00000889', '\U0000088e', WALetter),
+    ('\U00000890', '\U00000891', WNumeric),
+    ('\U00000897', '\U0000089f', WExtend),
+    ('\U000008a0', '\U000008c9', WALetter),
+    ('\U000008ca', '\U000008e1', WExtend),
+    ('\U000008e2', '\U000008e2', WNumeric),
+    ('\U000008e3', '\U00000903', WExtend),
+    ('\U00000904', '\U00000939', WALetter),
+    ('\U0000093a', '\U0000093c', WExtend),
+    ('\U0000093d', '\U0000093d', WALetter),
+    ('\U0000093e', '\U0000094f', WExtend),
+    ('\U00000950', '\U00000950', WALetter),
+    ('\U00000951', '\U00000957', WExtend),
+    ('\U00000958', '\U00000961', WALetter),
+    ('\U00000962', '\U00000963', WExtend),
+    ('\U00000966', '\U0000096f', WNumeric),
+    ('\U00000971', '\U00000980', WALetter),
+    ('\U00000981', '\U00000983', WExtend),
+    ('\U00000985', '\U0000098c', WALetter),
+    ('\U0000098f', '\U00000990', WALetter),
+    ('\U00000993', '\U000009a8', WALetter),
+    ('\U000009aa', '\U000009b0', WALetter),
+    ('\U000009b2', '\U000009b2', WALetter),
+    ('\U000009b6', '\U000009b9', WALetter),
+    ('\U000009bc', '\U000009bc', WExtend),
+    ('\U000009bd', '\U000009bd', WALetter),
+    ('\U000009be', '\U000009c4', WExtend),
+    ('\U000009c7', '\U000009c8', WExtend),
+    ('\U000009cb', '\U000009cd', WExtend),
+    ('\U000009ce', '\U000009ce', WALetter),
+    ('\U000009d7', '\U000009d7', WExtend),
+    ('\U000009dc', '\U000009dd', WALetter),
+    ('\U000009df', '\U000009e1', WALetter),
+    ('\U000009e2', '\U000009e3', WExtend),
+    ('\U000009e6', '\U000009ef', WNumeric),
+    ('\U000009f0', '\U000009f1', WALetter),
+    ('\U000009fc', '\U000009fc', WALetter),
+    ('\U000009fe', '\U000009fe', WExtend),
+    ('\U00000a01', '\U00000a03', WExtend),
+    ('\U00000a05', '\U00000a0a', WALetter),
+    ('\U00000a0f', '\U00000a10', WALetter),
+    ('\U00000a13', '\U00000a28', WALetter),
+    ('\U00000a2a', '\U00000a30', WALetter),
+    ('\U00000a32', '\U00000a33', WALetter),
+    ('\U00000a35', '\U00000a36', WALetter),
+    ('\U00000a38', '\U00000a39', WALetter),
+    ('\U00000a3c', '\U00000a3c', WExtend),
+    ('\U00000a3e', '\U00000a42', WExtend),
+    ('\U00000a47', '\U00000a48', WExtend),
+    ('\U00000a4b', '\U00000a4d', WExtend),
+    ('\U00000a51', '\U00000a51', WExtend),
+    ('\U00000a59', '\U00000a5c', WALetter),
+    ('\U00000a5e', '\U00000a5e', WALetter),
+    ('\U00000a66', '\U00000a6f', WNumeric),
+    ('\U00000a70', '\U00000a71', WExtend),
+    ('\U00000a72', '\U00000a74', WALetter),
+    ('\U00000a75', '\U00000a75', WExtend),
+    ('\U00000a81', '\U00000a83', WExtend),
+    ('\U00000a85', '\U00000a8d', WALetter),
+    ('\U00000a8f', '\U00000a91', WALetter),
+    ('\U00000a93', '\U00000aa8', WALetter),
+    ('\U00000aaa', '\U00000ab0', WALetter),
+    ('\U00000ab2', '\U00000ab3', WALetter),
+    ('\U00000ab5', '\U00000ab9', WALetter),
+    ('\U00000abc', '\U00000abc', WExtend),
+    ('\U00000abd', '\U00000abd', WALetter),
+    ('\U00000abe', '\U00000ac5', WExtend),
+    ('\U00000ac7', '\U00000ac9', WExtend),
+    ('\U00000acb', '\U00000acd', WExtend),
+    ('\U00000ad0', '\U00000ad0', WALetter),
+    ('\U00000ae0', '\U00000ae1', WALetter),
+    ('\U00000ae2', '\U00000ae3', WExtend),
+    ('\U00000ae6', '\U00000aef', WNumeric),
+    ('\U00000af9', '\U00000af9', WALetter),
+    ('\U00000afa', '\U00000aff', WExtend),
+    ('\U00000b01', '\U00000b03', WExtend),
+    ('\U00000b05', '\U00000b0c', WALetter),
+    ('\U00000b0f', '\U00000b10', WALetter),
+    ('\U00000b13', '\U00000b28', WALetter),
+    ('\U00000b2a', '\U00000b30', WALetter),
+    ('\U00000b32', '\U00000b33', WALetter),
+    ('\U00000b35', '\U00000b39', WALetter),
+    ('\U00000b3c', '\U00000b3c', WExtend),
+    ('\U00000b3d', '\U00000b3d', WALetter),
+    ('\U00000b3e', '\U00000b44', WExtend),
+    ('\U00000b47', '\U00000b48', WExtend),
+    ('\U00000b4b', '\U00000b4d', WExtend),
+    ('\U00000b55', '\U00000b57', WExtend),
+    ('\U00000b5c', '\U00000b5d', WALetter),
+    ('\U00000b5f', '\U00000b61', WALetter),
+    ('\U00000b62', '\U00000b63', WExtend),
+    ('\U00000b66', '\U00000b6f', WNumeric),
+    ('\U00000b71', '\U00000b71', WALetter),
+    ('\U00000b82', '\U00000b82', WExtend),
+    ('\U00000b83', '\U00000b83', WALetter),
+    ('\U00000b85', '\U00000b8a', WALetter),
+    ('\U00000b8e', '\U00000b90', WALetter),
+    ('\U00000b92', '\U00000b95', WALetter),
+    ('\U00000b99', '\U00000b9a', WALetter),
+    ('\U00000b9c', '\U00000b9c', WALetter),
+    ('\U00000b9e', '\U00000b9f', WALetter),
+    ('\U00000ba3', '\U00000ba4', WALetter),
+    ('\U00000ba8', '\U00000baa', WALetter),
+    ('\U00000bae', '\U00000bb9', WALetter),
+    ('\U00000bbe', '\U00000bc2', WExtend),
+    ('\U00000bc6', '\U00000bc8', WExtend),
+    ('\U00000bca', '\U00000bcd', WExtend),
+    ('\U00000bd0', '\U00000bd0', WALetter),
+    ('\U00000bd7', '\U00000bd7', WExtend),
+    ('\U00000be6', '\U00000bef', WNumeric),
+    ('\U00000c00', '\U00000c04', WExtend),
+    ('\U00000c05', '\U00000c0c', WALetter),
+    ('\U00000c0e', '\U00000c10', WALetter),
+    ('\U00000c12', '\U00000c28', WALetter),
+    ('\U00000c2a', '\U00000c39', WALetter),
+    ('\U00000c3c', '\U00000c3c', WExtend),
+    ('\U00000c3d', '\U00000c3d', WALetter),
+    ('\U00000c3e', '\U00000c44', WExtend),
+    ('\U00000c46', '\U00000c48', WExtend),
+    ('\U00000c4a', '\U00000c4d', WExtend),
+    ('\U00000c55', '\U00000c56', WExtend),
+    ('\U00000c58', '\U00000c5a', WALetter),
+    ('\U00000c5d', '\U00000c5d', WALetter),
+    ('\U00000c60', '\U00000c61', WALetter),
+    ('\U00000c62', '\U00000c63', WExtend),
+    ('\U00000c66', '\U00000c6f', WNumeric),
+    ('\U00000c80', '\U00000c80', WALetter),
+    ('\U00000c81', '\U00000c83', WExtend),
+    ('\U00000c85', '\U00000c8c', WALetter),
+    ('\U00000c8e', '\U00000c90', WALetter),
+    ('\U00000c92', '\U00000ca8', WALetter),
+    ('\U00000caa', '\U00000cb3', WALetter),
+    ('\U00000cb5', '\U00000cb9', WALetter),
+    ('\U00000cbc', '\U00000cbc', WExtend),
+    ('\U00000cbd', '\U00000cbd', WALetter),
+    ('\U00000cbe', '\U00000cc4', WExtend),
+    ('\U00000cc6', '\U00000cc8', WExtend),
+    ('\U00000cca', '\U00000ccd', WExtend),
+    ('\U00000cd5', '\U00000cd6', WExtend),
+    ('\U00000cdd', '\U00000cde', WALetter),
+    ('\U00000ce0', '\U00000ce1', WALetter),
+    ('\U00000ce2', '\U00000ce3', WExtend),
+    ('\U00000ce6', '\U00000cef', WNumeric),
+    ('\U00000cf1', '\U00000cf2', WALetter),
+    ('\U00000cf3', '\U00000cf3', WExtend),
+    ('\U00000d00', '\U00000d03', WExtend),
+    ('\U00000d04', '\U00000d0c', WALetter),
+    ('\U00000d0e', '\U00000d10', WALetter),
+    ('\U00000d12', '\U00000d3a', WALetter),
+    ('\U00000d3b', '\U00000d3c', WExtend),
+    ('\U00000d3d', '\U00000d3d', WALetter),
+    ('\U00000d3e', '\U00000d44', WExtend),
+    ('\U00000d46', '\U00000d48', WExtend),
+    ('\U00000d4a', '\U00000d4d', WExtend),
+    ('\U00000d4e', '\U00000d4e', WALetter),
+    ('\U00000d54', '\U00000d56', WALetter),
+    ('\U00000d57', '\U00000d57', WExtend),
+    ('\U00000d5f', '\U00000d61', WALetter),
+    ('\U00000d62', '\U00000d63', WExtend),
+    ('\U00000d66', '\U00000d6f', WNumeric),
+    ('\U00000d7a', '\U00000d7f', WALetter),
+    ('\U00000d81', '\U00000d83', WExtend),
+    ('\U00000d85', '\U00000d96', WALetter),
+    ('\U00000d9a', '\U00000db1', WALetter),
+    ('\U00000db3', '\U00000dbb', WALetter),
+    ('\U00000dbd', '\U00000dbd', WALetter),
+    ('\U00000dc0', '\U00000dc6', WALetter),
+    ('\U00000dca', '\U00000dca', WExtend),
+    ('\U00000dcf', '\U00000dd4', WExtend),
+    ('\U00000dd6', '\U00000dd6', WExtend),
+    ('\U00000dd8', '\U00000ddf', WExtend),
+    ('\U00000de6', '\U00000def', WNumeric),
+    ('\U00000df2', '\U00000df3', WExtend),
+    ('\U00000e31', '\U00000e31', WExtend),
+    ('\U00000e34', '\U00000e3a', WExtend),
+    ('\U00000e47', '\U00000e4e', WExtend),
+    ('\U00000e50', '\U00000e59', WNumeric),
+    ('\U00000eb1', '\U00000eb1', WExtend),
+    ('\U00000eb4', '\U00000ebc', WExtend),
+    ('\U00000ec8', '\U00000ece', WExtend),
+    ('\U00000ed0', '\U00000ed9', WNumeric),
+    ('\U00000f00', '\U00000f00', WALetter),
+    ('\U00000f18', '\U00000f19', WExtend),
+    ('\U00000f20', '\U00000f29', WNumeric),
+    ('\U00000f35', '\U00000f35', WExtend),
+    ('\U00000f37', '\U00000f37', WExtend),
+    ('\U00000f39', '\U00000f39', WExtend),
+    ('\U00000f3e', '\U00000f3f', WExtend),
+    ('\U00000f40', '\U00000f47', WALetter),
+    ('\U00000f49', '\U00000f6c', WALetter),
+    ('\U00000f71', '\U00000f84', WExtend),
+    ('\U00000f86', '\U00000f87', WExtend),
+    ('\U00000f88', '\U00000f8c', WALetter),
+    ('\U00000f8d', '\U00000f97', WExtend),
+    ('\U00000f99', '\U00000fbc', WExtend),
+    ('\U00000fc6', '\U00000fc6', WExtend),
+    ('\U0000102b', '\U0000103e', WExtend),
+    ('\U00001040', '\U00001049', WNumeric),
+    ('\U00001056', '\U00001059', WExtend),
+    ('\U0000105e', '\U00001060', WExtend),
+    ('\U00001062', '\U00001064', WExtend),
+    ('\U00001067', '\U0000106d', WExtend),
+    ('\U00001071', '\U00001074', WExtend),
+    ('\U00001082', '\U0000108d', WExtend),
+    ('\U0000108f', '\U0000108f', WExtend),
+    ('\U00001090', '\U00001099', WNumeric),
+    ('\U0000109a', '\U0000109d', WExtend),
+    ('\U000010a0', '\U000010c5', WALetter),
+    ('\U000010c7', '\U000010c7', WALetter),
+    ('\U000010cd', '\U000010cd', WALetter),
+    ('\U000010d0', '\U000010fa', WALetter),
+    ('\U000010fc', '\U00001248', WALetter),
+    ('\U0000124a', '\U0000124d', WALetter),
+    ('\U00001250', '\U00001256', WALetter),
+    ('\U00001258', '\U00001258', WALetter),
+    ('\U0000125a', '\U0000125d', WALetter),
+    ('\U00001260', '\U00001288', WALetter),
+    ('\U0000128a', '\U0000128d', WALetter),
+    ('\U00001290', '\U000012b0', WALetter),
+    ('\U000012b2', '\U000012b5', WALetter),
+    ('\U000012b8', '\U000012be', WALetter),
+    ('\U000012c0', '\U000012c0', WALetter),
+    ('\U000012c2', '\U000012c5', WALetter),
+    ('\U000012c8', '\U000012d6', WALetter),
+    ('\U000012d8', '\U00001310', WALetter),
+    ('\U00001312', '\U00001315', WALetter),
+    ('\U00001318', '\U0000135a', WALetter),
+    ('\U0000135d', '\U0000135f', WExtend),
+    ('\U00001380', '\U0000138f', WALetter),
+    ('\U000013a0', '\U000013f5', WALetter),
+    ('\U000013f8', '\U000013fd', WALetter),
+    ('\U00001401', '\U0000166c', WALetter),
+    ('\U0000166f', '\U0000167f', WALetter),
+    ('\U00001680', '\U00001680', WWSegSpace),
+    ('\U00001681', '\U0000169a', WALetter),
+    ('\U000016a0', '\U000016ea', WALetter),
+    ('\U000016ee', '\U000016f8', WALetter),
+    ('\U00001700', '\U00001711', WALetter),
+    ('\U00001712', '\U00001715', WExtend),
+    ('\U0000171f', '\U00001731', WALetter),
+    ('\U00001732', '\U00001734', WExtend),
+    ('\U00001740', '\U00001751', WALetter),
+    ('\U00001752', '\U00001753', WExtend),
+    ('\U00001760', '\U0000176c', WALetter),
+    ('\U0000176e', '\U00001770', WALetter),
+    ('\U00001772', '\U00001773', WExtend),
+    ('\U000017b4', '\U000017d3', WExtend),
+    ('\U000017dd', '\U000017dd', WExtend),
+    ('\U000017e0', '\U000017e9', WNumeric),
+    ('\U0000180b', '\U0000180d', WExtend),
+    ('\U0000180e', '\U0000180e', WFormat),
+    ('\U0000180f', '\U0000180f', WExtend),
+    ('\U00001810', '\U00001819', WNumeric),
+    ('\U00001820', '\U00001878', WALetter),
+    ('\U00001880', '\U00001884', WALetter),
+    ('\U00001885', '\U00001886', WExtend),
+    ('\U00001887', '\U000018a8', WALetter),
+    ('\U000018a9', '\U000018a9', WExtend),
+    ('\U000018aa', '\U000018aa', WALetter),
+    ('\U000018b0', '\U000018f5', WALetter),
+    ('\U00001900', '\U0000191e', WALetter),
+    ('\U00001920', '\U0000192b', WExtend),
+    ('\U00001930', '\U0000193b', WExtend),
+    ('\U00001946', '\U0000194f', WNumeric),
+    ('\U000019d0', '\U000019da', WNumeric),
+    ('\U00001a00', '\U00001a16', WALetter),
+    ('\U00001a17', '\U00001a1b', WExtend),
+    ('\U00001a55', '\U00001a5e', WExtend),
+    ('\U00001a60', '\U00001a7c', WExtend),
+    ('\U00001a7f', '\U00001a7f', WExtend),
+    ('\U00001a80', '\U00001a89', WNumeric),
+    ('\U00001a90', '\U00001a99', WNumeric),
+    ('\U00001ab0', '\U00001ace', WExtend),
+    ('\U00001b00', '\U00001b04', WExtend),
+    ('\U00001b05', '\U00001b33', WALetter),
+    ('\U00001b34', '\U00001b44', WExtend),
+    ('\U00001b45', '\U00001b4c', WALetter),
+    ('\U00001b50', '\U00001b59', WNumeric),
+    ('\U00001b6b', '\U00001b73', WExtend),
+    ('\U00001b80', '\U00001b82', WExtend),
+    ('\U00001b83', '\U00001ba0', WALetter),
+    ('\U00001ba1', '\U00001bad', WExtend),
+    ('\U00001bae', '\U00001baf', WALetter),
+    ('\U00001bb0', '\U00001bb9', WNumeric),
+    ('\U00001bba', '\U00001be5', WALetter),
+    ('\U00001be6', '\U00001bf3', WExtend),
+    ('\U00001c00', '\U00001c23', WALetter),
+    ('\U00001c24', '\U00001c37', WExtend),
+    ('\U00001c40', '\U00001c49', WNumeric),
+    ('\U00001c4d', '\U00001c4f', WALetter),
+    ('\U00001c50', '\U00001c59', WNumeric),
+    ('\U00001c5a', '\U00001c7d', WALetter),
+    ('\U00001c80', '\U00001c8a', WALetter),
+    ('\U00001c90', '\U00001cba', WALetter),
+    ('\U00001cbd', '\U00001cbf', WALetter),
+    ('\U00001cd0', '\U00001cd2', WExtend),
+    ('\U00001cd4', '\U00001ce8', WExtend),
+    ('\U00001ce9', '\U00001cec', WALetter),
+    ('\U00001ced', '\U00001ced', WExtend),
+    ('\U00001cee', '\U00001cf3', WALetter),
+    ('\U00001cf4', '\U00001cf4', WExtend),
+    ('\U00001cf5', '\U00001cf6', WALetter),
+    ('\U00001cf7', '\U00001cf9', WExtend),
+    ('\U00001cfa', '\U00001cfa', WALetter),
+    ('\U00001d00', '\U00001dbf', WALetter),
+    ('\U00001dc0', '\U00001dff', WExtend),
+    ('\U00001e00', '\U00001f15', WALetter),
+    ('\U00001f18', '\U00001f1d', WALetter),
+    ('\U00001f20', '\U00001f45', WALetter),
+    ('\U00001f48', '\U00001f4d', WALetter),
+    ('\U00001f50', '\U00001f57', WALetter),
+    ('\U00001f59', '\U00001f59', WALetter),
+    ('\U00001f5b', '\U00001f5b', WALetter),
+    ('\U00001f5d', '\U00001f5d', WALetter),
+    ('\U00001f5f', '\U00001f7d', WALetter),
+    ('\U00001f80', '\U00001fb4', WALetter),
+    ('\U00001fb6', '\U00001fbc', WALetter),
+    ('\U00001fbe', '\U00001fbe', WALetter),
+    ('\U00001fc2', '\U00001fc4', WALetter),
+    ('\U00001fc6', '\U00001fcc', WALetter),
+    ('\U00001fd0', '\U00001fd3', WALetter),
+    ('\U00001fd6', '\U00001fdb', WALetter),
+    ('\U00001fe0', '\U00001fec', WALetter),
+    ('\U00001ff2', '\U00001ff4', WALetter),
+    ('\U00001ff6', '\U00001ffc', WALetter),
+    ('\U00002000', '\U00002006', WWSegSpace),
+    ('\U00002008', '\U0000200a', WWSegSpace),
+    ('\U0000200c', '\U0000200c', WExtend),
+    ('\U0000200d', '\U0000200d', WZWJ),
+    ('\U0000200e', '\U0000200f', WFormat),
+    ('\U00002018', '\U00002019', WMidNumLet),
+    ('\U00002024', '\U00002024', WMidNumLet),
+    ('\U00002027', '\U00002027', WMidLetter),
+    ('\U00002028', '\U00002029', WNewline),
+    ('\U0000202a', '\U0000202e', WFormat),
+    ('\U0000202f', '\U0000202f', WExtendNumLet),
+    ('\U0000203f', '\U00002040', WExtendNumLet),
+    ('\U00002044', '\U00002044', WMidNum),
+    ('\U00002054', '\U00002054', WExtendNumLet),
+    ('\U0000205f', '\U0000205f', WWSegSpace),
+    ('\U00002060', '\U00002064', WFormat),
+    ('\U00002066', '\U0000206f', WFormat),
+    ('\U00002071', '\U00002071', WALetter),
+    ('\U0000207f', '\U0000207f', WALetter),
+    ('\U00002090', '\U0000209c', WALetter),
+    ('\U000020d0', '\U000020f0', WExtend),
+    ('\U00002102', '\U00002102', WALetter),
+    ('\U00002107', '\U00002107', WALetter),
+    ('\U0000210a', '\U00002113', WALetter),
+    ('\U00002115', '\U00002115', WALetter),
+    ('\U00002119', '\U0000211d', WALetter),
+    ('\U00002124', '\U00002124', WALetter),
+    ('\U00002126', '\U00002126', WALetter),
+    ('\U00002128', '\U00002128', WALetter),
+    ('\U0000212a', '\U0000212d', WALetter),
+    ('\U0000212f', '\U00002139', WALetter),
+    ('\U0000213c', '\U0000213f', WALetter),
+    ('\U00002145', '\U00002149', WALetter),
+    ('\U0000214e', '\U0000214e', WALetter),
+    ('\U00002160', '\U00002188', WALetter),
+    ('\U000024b6', '\U000024e9', WALetter),
+    ('\U00002c00', '\U00002ce4', WALetter),
+    ('\U00002ceb', '\U00002cee', WALetter),
+    ('\U00002cef', '\U00002cf1', WExtend),
+    ('\U00002cf2', '\U00002cf3', WALetter),
+    ('\U00002d00', '\U00002d25', WALetter),
+    ('\U00002d27', '\U00002d27', WALetter),
+    ('\U00002d2d', '\U00002d2d', WALetter),
+    ('\U00002d30', '\U00002d67', WALetter),
+    ('\U00002d6f', '\U00002d6f', WALetter),
+    ('\U00002d7f', '\U00002d7f', WExtend),
+    ('\U00002d80', '\U00002d96', WALetter),
+    ('\U00002da0', '\U00002da6', WALetter),
+    ('\U00002da8', '\U00002dae', WALetter),
+    ('\U00002db0', '\U00002db6', WALetter),
+    ('\U00002db8', '\U00002dbe', WALetter),
+    ('\U00002dc0', '\U00002dc6', WALetter),
+    ('\U00002dc8', '\U00002dce', WALetter),
+    ('\U00002dd0', '\U00002dd6', WALetter),
+    ('\U00002dd8', '\U00002dde', WALetter),
+    ('\U00002de0', '\U00002dff', WExtend),
+    ('\U00002e2f', '\U00002e2f', WALetter),
+    ('\U00003000', '\U00003000', WWSegSpace),
+    ('\U00003005', '\U00003005', WALetter),
+    ('\U0000302a', '\U0000302f', WExtend),
+    ('\U00003031', '\U00003035', WKatakana),
+    ('\U0000303b', '\U0000303c', WALetter),
+    ('\U00003099', '\U0000309a', WExtend),
+    ('\U0000309b', '\U0000309c', WKatakana),
+    ('\U000030a0', '\U000030fa', WKatakana),
+    ('\U000030fc', '\U000030ff', WKatakana),
+    ('\U00003105', '\U0000312f', WALetter),
+    ('\U00003131', '\U0000318e', WALetter),
+    ('\U000031a0', '\U000031bf', WALetter),
+    ('\U000031f0', '\U000031ff', WKatakana),
+    ('\U000032d0', '\U000032fe', WKatakana),
+    ('\U00003300', '\U00003357', WKatakana),
+    ('\U0000a000', '\U0000a48c', WALetter),
+    ('\U0000a4d0', '\U0000a4fd', WALetter),
+    ('\U0000a500', '\U0000a60c', WALetter),
+    ('\U0000a610', '\U0000a61f', WALetter),
+    ('\U0000a620', '\U0000a629', WNumeric),
+    ('\U0000a62a', '\U0000a62b', WALetter),
+    ('\U0000a640', '\U0000a66e', WALetter),
+    ('\U0000a66f', '\U0000a672', WExtend),
+    ('\U0000a674', '\U0000a67d', WExtend),
+    ('\U0000a67f', '\U0000a69d', WALetter),
+    ('\U0000a69e', '\U0000a69f', WExtend),
+    ('\U0000a6a0', '\U0000a6ef', WALetter),
+    ('\U0000a6f0', '\U0000a6f1', WExtend),
+    ('\U0000a708', '\U0000a7cd', WALetter),
+    ('\U0000a7d0', '\U0000a7d1', WALetter),
+    ('\U0000a7d3', '\U0000a7d3', WALetter),
+    ('\U0000a7d5', '\U0000a7dc', WALetter),
+    ('\U0000a7f2', '\U0000a801', WALetter),
+    ('\U0000a802', '\U0000a802', WExtend),
+    ('\U0000a803', '\U0000a805', WALetter),
+    ('\U0000a806', '\U0000a806', WExtend),
+    ('\U0000a807', '\U0000a80a', WALetter),
+    ('\U0000a80b', '\U0000a80b', WExtend),
+    ('\U0000a80c', '\U0000a822', WALetter),
+    ('\U0000a823', '\U0000a827', WExtend),
+    ('\U0000a82c', '\U0000a82c', WExtend),
+    ('\U0000a840', '\U0000a873', WALetter),
+    ('\U0000a880', '\U0000a881', WExtend),
+    ('\U0000a882', '\U0000a8b3', WALetter),
+    ('\U0000a8b4', '\U0000a8c5', WExtend),
+    ('\U0000a8d0', '\U0000a8d9', WNumeric),
+    ('\U0000a8e0', '\U0000a8f1', WExtend),
+    ('\U0000a8f2', '\U0000a8f7', WALetter),
+    ('\U0000a8fb', '\U0000a8fb', WALetter),
+    ('\U0000a8fd', '\U0000a8fe', WALetter),
+    ('\U0000a8ff', '\U0000a8ff', WExtend),
+    ('\U0000a900', '\U0000a909', WNumeric),
+    ('\U0000a90a', '\U0000a925', WALetter),
+    ('\U0000a926', '\U0000a92d', WExtend),
+    ('\U0000a930', '\U0000a946', WALetter),
+    ('\U0000a947', '\U0000a953', WExtend),
+    ('\U0000a960', '\U0000a97c', WALetter),
+    ('\U0000a980', '\U0000a983', WExtend),
+    ('\U0000a984', '\U0000a9b2', WALetter),
+    ('\U0000a9b3', '\U0000a9c0', WExtend),
+    ('\U0000a9cf', '\U0000a9cf', WALetter),
+    ('\U0000a9d0', '\U0000a9d9', WNumeric),
+    ('\U0000a9e5', '\U0000a9e5', WExtend),
+    ('\U0000a9f0', '\U0000a9f9', WNumeric),
+    ('\U0000aa00', '\U0000aa28', WALetter),
+    ('\U0000aa29', '\U0000aa36', WExtend),
+    ('\U0000aa40', '\U0000aa42', WALetter),
+    ('\U0000aa43', '\U0000aa43', WExtend),
+    ('\U0000aa44', '\U0000aa4b', WALetter),
+    ('\U0000aa4c', '\U0000aa4d', WExtend),
+    ('\U0000aa50', '\U0000aa59', WNumeric),
+    ('\U0000aa7b', '\U0000aa7d', WExtend),
+    ('\U0000aab0', '\U0000aab0', WExtend),
+    ('\U0000aab2', '\U0000aab4', WExtend),
+    ('\U0000aab7', '\U0000aab8', WExtend),
+    ('\U0000aabe', '\U0000aabf', WExtend),
+    ('\U0000aac1', '\U0000aac1', WExtend),
+    ('\U0000aae0', '\U0000aaea', WALetter),
+    ('\U0000aaeb', '\U0000aaef', WExtend),
+    ('\U0000aaf2', '\U0000aaf4', WALetter),
+    ('\U0000aaf5', '\U0000aaf6', WExtend),
+    ('\U0000ab01', '\U0000ab06', WALetter),
+    ('\U0000ab09', '\U0000ab0e', WALetter),
+    ('\U0000ab11', '\U0000ab16', WALetter),
+    ('\U0000ab20', '\U0000ab26', WALetter),
+    ('\U0000ab28', '\U0000ab2e', WALetter),
+    ('\U0000ab30', '\U0000ab69', WALetter),
+    ('\U0000ab70', '\U0000abe2', WALetter),
+    ('\U0000abe3', '\U0000abea', WExtend),
+    ('\U0000abec', '\U0000abed', WExtend),
+    ('\U0000abf0', '\U0000abf9', WNumeric),
+    ('\U0000ac00', '\U0000d7a3', WALetter),
+    ('\U0000d7b0', '\U0000d7c6', WALetter),
+    ('\U0000d7cb', '\U0000d7fb', WALetter),
+    ('\U0000fb00', '\U0000fb06', WALetter),
+    ('\U0000fb13', '\U0000fb17', WALetter),
+    ('\U0000fb1d', '\U0000fb1d', WHebrewLetter),
+    ('\U0000fb1e', '\U0000fb1e', WExtend),
+    ('\U0000fb1f', '\U0000fb28', WHebrewLetter),
+    ('\U0000fb2a', '\U0000fb36', WHebrewLetter),
+    ('\U0000fb38', '\U0000fb3c', WHebrewLetter),
+    ('\U0000fb3e', '\U0000fb3e', WHebrewLetter),
+    ('\U0000fb40', '\U0000fb41', WHebrewLetter),
+    ('\U0000fb43', '\U0000fb44', WHebrewLetter),
+    ('\U0000fb46', '\U0000fb4f', WHebrewLetter),
+    ('\U0000fb50', '\U0000fbb1', WALetter),
+    ('\U0000fbd3', '\U0000fd3d', WALetter),
+    ('\U0000fd50', '\U0000fd8f', WALetter),
+    ('\U0000fd92', '\U0000fdc7', WALetter),
+    ('\U0000fdf0', '\U0000fdfb', WALetter),
+    ('\U0000fe00', '\U0000fe0f', WExtend),
+    ('\U0000fe13', '\U0000fe13', WMidLetter),
+    ('\U0000fe20', '\U0000fe2f', WExtend),
+    ('\U0000fe33', '\U0000fe34', WExtendNumLet),
+    ('\U0000fe4d', '\U0000fe4f', WExtendNumLet),
+    ('\U0000fe50', '\U0000fe50', WMidNum),
+    ('\U0000fe52', '\U0000fe52', WMidNumLet),
+    ('\U0000fe54', '\U0000fe54', WMidNum),
+    ('\U0000fe55', '\U0000fe55', WMidLetter),
+    ('\U0000fe70', '\U0000fe74', WALetter),
+    ('\U0000fe76', '\U0000fefc', WALetter),
+    ('\U0000feff', '\U0000feff', WFormat),
+    ('\U0000ff07', '\U0000ff07', WMidNumLet),
+    ('\U0000ff0c', '\U0000ff0c', WMidNum),
+    ('\U0000ff0e', '\U0000ff0e', WMidNumLet),
+    ('\U0000ff10', '\U0000ff19', WNumeric),
+    ('\U0000ff1a', '\U0000ff1a', WMidLetter),
+    ('\U0000ff1b', '\U0000ff1b', WMidNum),
+    ('\U0000ff21', '\U0000ff3a', WALetter),
+    ('\U0000ff3f', '\U0000ff3f', WExtendNumLet),
+    ('\U0000ff41', '\U0000ff5a', WALetter),
+    ('\U0000ff66', '\U0000ff9d', WKatakana),
+    ('\U0000ff9e', '\U0000ff9f', WExtend),
+    ('\U0000ffa0', '\U0000ffbe', WALetter),
+    ('\U0000ffc2', '\U0000ffc7', WALetter),
+    ('\U0000ffca', '\U0000ffcf', WALetter),
+    ('\U0000ffd2', '\U0000ffd7', WALetter),
+    ('\U0000ffda', '\U0000ffdc', WALetter),
+    ('\U0000fff9', '\U0000fffb', WFormat),
+    ('\U00010000', '\U0001000b', WALetter),
+    ('\U0001000d', '\U00010026', WALetter),
+    ('\U00010028', '\U0001003a', WALetter),
+    ('\U0001003c', '\U0001003d', WALetter),
+    ('\U0001003f', '\U0001004d', WALetter),
+    ('\U00010050', '\U0001005d', WALetter),
+    ('\U00010080', '\U000100fa', WALetter),
+    ('\U00010140', '\U00010174', WALetter),
+    ('\U000101fd', '\U000101fd', WExtend),
+    ('\U00010280', '\U0001029c', WALetter),
+    ('\U000102a0', '\U000102d0', WALetter),
+    ('\U000102e0', '\U000102e0', WExtend),
+    ('\U00010300', '\U0001031f', WALetter),
+    ('\U0001032d', '\U0001034a', WALetter),
+    ('\U00010350', '\U00010375', WALetter),
+    ('\U00010376', '\U0001037a', WExtend),
+    ('\U00010380', '\U0001039d', WALetter),
+    ('\U000103a0', '\U000103c3', WALetter),
+    ('\U000103c8', '\U000103cf', WALetter),
+    ('\U000103d1', '\U000103d5', WALetter),
+    ('\U00010400', '\U0001049d', WALetter),
+    ('\U000104a0', '\U000104a9', WNumeric),
+    ('\U000104b0', '\U000104d3', WALetter),
+    ('\U000104d8', '\U000104fb', WALetter),
+    ('\U00010500', '\U00010527', WALetter),
+    ('\U00010530', '\U00010563', WALetter),
+    ('\U00010570', '\U0001057a', WALetter),
+    ('\U0001057c', '\U0001058a', WALetter),
+    ('\U0001058c', '\U00010592', WALetter),
+    ('\U00010594', '\U00010595', WALetter),
+    ('\U00010597', '\U000105a1', WALetter),
+    ('\U000105a3', '\U000105b1', WALetter),
+    ('\U000105b3', '\U000105b9', WALetter),
+    ('\U000105bb', '\U000105bc', WALetter),
+    ('\U000105c0', '\U000105f3', WALetter),
+    ('\U00010600', '\U00010736', WALetter),
+    ('\U00010740', '\U00010755', WALetter),
+    ('\U00010760', '\U00010767', WALetter),
+    ('\U00010780', '\U00010785', WALetter),
+    ('\U00010787', '\U000107b0', WALetter),
+    ('\U000107b2', '\U000107ba', WALetter),
+    ('\U00010800', '\U00010805', WALetter),
+    ('\U00010808', '\U00010808', WALetter),
+    ('\U0001080a', '\U00010835', WALetter),
+    ('\U00010837', '\U00010838', WALetter),
+    ('\U0001083c', '\U0001083c', WALetter),
+    ('\U0001083f', '\U00010855', WALetter),
+    ('\U00010860', '\U00010876', WALetter),
+    ('\U00010880', '\U0001089e', WALetter),
+    ('\U000108e0', '\U000108f2', WALetter),
+    ('\U000108f4', '\U000108f5', WALetter),
+    ('\U00010900', '\U00010915', WALetter),
+    ('\U00010920', '\U00010939', WALetter),
+    ('\U00010980', '\U000109b7', WALetter),
+    ('\U000109be', '\U000109bf', WALetter),
+    ('\U00010a00', '\U00010a00', WALetter),
+    ('\U00010a01', '\U00010a03', WExtend),
+    ('\U00010a05', '\U00010a06', WExtend),
+    ('\U00010a0c', '\U00010a0f', WExtend),
+    ('\U00010a10', '\U00010a13', WALetter),
+    ('\U00010a15', '\U00010a17', WALetter),
+    ('\U00010a19', '\U00010a35', WALetter),
+    ('\U00010a38', '\U00010a3a', WExtend),
+    ('\U00010a3f', '\U00010a3f', WExtend),
+    ('\U00010a60', '\U00010a7c', WALetter),
+    ('\U00010a80', '\U00010a9c', WALetter),
+    ('\U00010ac0', '\U00010ac7', WALetter),
+    ('\U00010ac9', '\U00010ae4', WALetter),
+    ('\U00010ae5', '\U00010ae6', WExtend),
+    ('\U00010b00', '\U00010b35', WALetter),
+    ('\U00010b40', '\U00010b55', WALetter),
+    ('\U00010b60', '\U00010b72', WALetter),
+    ('\U00010b80', '\U00010b91', WALetter),
+    ('\U00010c00', '\U00010c48', WALetter),
+    ('\U00010c80', '\U00010cb2', WALetter),
+    ('\U00010cc0', '\U00010cf2', WALetter),
+    ('\U00010d00', '\U00010d23', WALetter),
+    ('\U00010d24', '\U00010d27', WExtend),
+    ('\U00010d30', '\U00010d39', WNumeric),
+    ('\U00010d40', '\U00010d49', WNumeric),
+    ('\U00010d4a', '\U00010d65', WALetter),
+    ('\U00010d69', '\U00010d6d', WExtend),
+    ('\U00010d6f', '\U00010d85', WALetter),
+    ('\U00010e80', '\U00010ea9', WALetter),
+    ('\U00010eab', '\U00010eac', WExtend),
+    ('\U00010eb0', '\U00010eb1', WALetter),
+    ('\U00010ec2', '\U00010ec4', WALetter),
+    ('\U00010efc', '\U00010eff', WExtend),
+    ('\U00010f00', '\U00010f1c', WALetter),
+    ('\U00010f27', '\U00010f27', WALetter),
+    ('\U00010f30', '\U00010f45', WALetter),
+    ('\U00010f46', '\U00010f50', WExtend),
+    ('\U00010f70', '\U00010f81', WALetter),
+    ('\U00010f82', '\U00010f85', WExtend),
+    ('\U00010fb0', '\U00010fc4', WALetter),
+    ('\U00010fe0', '\U00010ff6', WALetter),
+    ('\U00011000', '\U00011002', WExtend),
+    ('\U00011003', '\U00011037', WALetter),
+    ('\U00011038', '\U00011046', WExtend),
+    ('\U00011066', '\U0001106f', WNumeric),
+    ('\U00011070', '\U00011070', WExtend),
+    ('\U00011071', '\U00011072', WALetter),
+    ('\U00011073', '\U00011074', WExtend),
+    ('\U00011075', '\U00011075', WALetter),
+    ('\U0001107f', '\U00011082', WExtend),
+    ('\U00011083', '\U000110af', WALetter),
+    ('\U000110b0', '\U000110ba', WExtend),
+    ('\U000110bd', '\U000110bd', WNumeric),
+    ('\U000110c2', '\U000110c2', WExtend),
+    ('\U000110cd', '\U000110cd', WNumeric),
+    ('\U000110d0', '\U000110e8', WALetter),
+    ('\U000110f0', '\U000110f9', WNumeric),
+    ('\U00011100', '\U00011102', WExtend),
+    ('\U00011103', '\U00011126', WALetter),
+    ('\U00011127', '\U00011134', WExtend),
+    ('\U00011136', '\U0001113f', WNumeric),
+    ('\U00011144', '\U00011144', WALetter),
+    ('\U00011145', '\U00011146', WExtend),
+    ('\U00011147', '\U00011147', WALetter),
+    ('\U00011150', '\U00011172', WALetter),
+    ('\U00011173', '\U00011173', WExtend),
+    ('\U00011176', '\U00011176', WALetter),
+    ('\U00011180', '\U00011182', WExtend),
+    ('\U00011183', '\U000111b2', WALetter),
+    ('\U000111b3', '\U000111c0', WExtend),
+    ('\U000111c1', '\U000111c4', WALetter),
+    ('\U000111c9', '\U000111cc', WExtend),
+    ('\U000111ce', '\U000111cf', WExtend),
+    ('\U000111d0', '\U000111d9', WNumeric),
+    ('\U000111da', '\U000111da', WALetter),
+    ('\U000111dc', '\U000111dc', WALetter),
+    ('\U00011200', '\U00011211', WALetter),
+    ('\U00011213', '\U0001122b', WALetter),
+    ('\U0001122c', '\U00011237', WExtend),
+    ('\U0001123e', '\U0001123e', WExtend),
+    ('\U0001123f', '\U00011240', WALetter),
+    ('\U00011241', '\U00011241', WExtend),
+    ('\U00011280', '\U00011286', WALetter),
+    ('\U00011288', '\U00011288', WALetter),
+    ('\U0001128a', '\U0001128d', WALetter),
+    ('\U0001128f', '\U0001129d', WALetter),
+    ('\U0001129f', '\U000112a8', WALetter),
+    ('\U000112b0', '\U000112de', WALetter),
+    ('\U000112df', '\U000112ea', WExtend),
+    ('\U000112f0', '\U000112f9', WNumeric),
+    ('\U00011300', '\U00011303', WExtend),
+    ('\U00011305', '\U0001130c', WALetter),
+    ('\U0001130f', '\U00011310', WALetter),
+    ('\U00011313', '\U00011328', WALetter),
+    ('\U0001132a', '\U00011330', WALetter),
+    ('\U00011332', '\U00011333', WALetter),
+    ('\U00011335', '\U00011339', WALetter),
+    ('\U0001133b', '\U0001133c', WExtend),
+    ('\U0001133d', '\U0001133d', WALetter),
+    ('\U0001133e', '\U00011344', WExtend),
+    ('\U00011347', '\U00011348', WExtend),
+    ('\U0001134b', '\U0001134d', WExtend),
+    ('\U00011350', '\U00011350', WALetter),
+    ('\U00011357', '\U00011357', WExtend),
+    ('\U0001135d', '\U00011361', WALetter),
+    ('\U00011362', '\U00011363', WExtend),
+    ('\U00011366', '\U0001136c', WExtend),
+    ('\U00011370', '\U00011374', WExtend),
+    ('\U00011380', '\U00011389', WALetter),
+    ('\U0001138b', '\U0001138b', WALetter),
+    ('\U0001138e', '\U0001138e', WALetter),
+    ('\U00011390', '\U000113b5', WALetter),
+    ('\U000113b7', '\U000113b7', WALetter),
+    ('\U000113b8', '\U000113c0', WExtend),
+    ('\U000113c2', '\U000113c2', WExtend),
+    ('\U000113c5', '\U000113c5', WExtend),
+    ('\U000113c7', '\U000113ca', WExtend),
+    ('\U000113cc', '\U000113d0', WExtend),
+    ('\U000113d1', '\U000113d1', WALetter),
+    ('\U000113d2', '\U000113d2', WExtend),
+    ('\U000113d3', '\U000113d3', WALetter),
+    ('\U000113e1', '\U000113e2', WExtend),
+    ('\U00011400', '\U00011434', WALetter),
+    ('\U00011435', '\U00011446', WExtend),
+    ('\U00011447', '\U0001144a', WALetter),
+    ('\U00011450', '\U00011459', WNumeric),
+    ('\U0001145e', '\U0001145e', WExtend),
+    ('\U0001145f', '\U00011461', WALetter),
+    ('\U00011480', '\U000114af', WALetter),
+    ('\U000114b0', '\U000114c3', WExtend),
+    ('\U000114c4', '\U000114c5', WALetter),
+    ('\U000114c7', '\U000114c7', WALetter),
+    ('\U000114d0', '\U000114d9', WNumeric),
+    ('\U00011580', '\U000115ae', WALetter),
+    ('\U000115af', '\U000115b5', WExtend),
+    ('\U000115b8', '\U000115c0', WExtend),
+    ('\U000115d8', '\U000115db', WALetter),
+    ('\U000115dc', '\U000115dd', WExtend),
+    ('\U00011600', '\U0001162f', WALetter),
+    ('\U00011630', '\U00011640', WExtend),
+    ('\U00011644', '\U00011644', WALetter),
+    ('\U00011650', '\U00011659', WNumeric),
+    ('\U00011680', '\U000116aa', WALetter),
+    ('\U000116ab', '\U000116b7', WExtend),
+    ('\U000116b8', '\U000116b8', WALetter),
+    ('\U000116c0', '\U000116c9', WNumeric),
+    ('\U000116d0', '\U000116e3', WNumeric),
+    ('\U0001171d', '\U0001172b', WExtend),
+    ('\U00011730', '\U00011739', WNumeric),
+    ('\U00011800', '\U0001182b', WALetter),
+    ('\U0001182c', '\U0001183a', WExtend),
+    ('\U000118a0', '\U000118df', WALetter),
+    ('\U000118e0', '\U000118e9', WNumeric),
+    ('\U000118ff', '\U00011906', WALetter),
+    ('\U00011909', '\U00011909', WALetter),
+    ('\U0001190c', '\U00011913', WALetter),
+    ('\U00011915', '\U00011916', WALetter),
+    ('\U00011918', '\U0001192f', WALetter),
+    ('\U00011930', '\U00011935', WExtend),
+    ('\U00011937', '\U00011938', WExtend),
+    ('\U0001193b', '\U0001193e', WExtend),
+    ('\U0001193f', '\U0001193f', WALetter),
+    ('\U00011940', '\U00011940', WExtend),
+    ('\U00011941', '\U00011941', WALetter),
+    ('\U00011942', '\U00011943', WExtend),
+    ('\U00011950', '\U00011959', WNumeric),
+    ('\U000119a0', '\U000119a7', WALetter),
+    ('\U000119aa', '\U000119d0', WALetter),
+    ('\U000119d1', '\U000119d7', WExtend),
+    ('\U000119da', '\U000119e0', WExtend),
+    ('\U000119e1', '\U000119e1', WALetter),
+    ('\U000119e3', '\U000119e3', WALetter),
+    ('\U000119e4', '\U000119e4', WExtend),
+    ('\U00011a00', '\U00011a00', WALetter),
+    ('\U00011a01', '\U00011a0a', WExtend),
+    ('\U00011a0b', '\U00011a32', WALetter),
+    ('\U00011a33', '\U00011a39', WExtend),
+    ('\U00011a3a', '\U00011a3a', WALetter),
+    ('\U00011a3b', '\U00011a3e', WExtend),
+    ('\U00011a47', '\U00011a47', WExtend),
+    ('\U00011a50', '\U00011a50', WALetter),
+    ('\U00011a51', '\U00011a5b', WExtend),
+    ('\U00011a5c', '\U00011a89', WALetter),
+    ('\U00011a8a', '\U00011a99', WExtend),
+    ('\U00011a9d', '\U00011a9d', WALetter),
+    ('\U00011ab0', '\U00011af8', WALetter),
+    ('\U00011bc0', '\U00011be0', WALetter),
+    ('\U00011bf0', '\U00011bf9', WNumeric),
+    ('\U00011c00', '\U00011c08', WALetter),
+    ('\U00011c0a', '\U00011c2e', WALetter),
+    ('\U00011c2f', '\U00011c36', WExtend),
+    ('\U00011c38', '\U00011c3f', WExtend),
+    ('\U00011c40', '\U00011c40', WALetter),
+    ('\U00011c50', '\U00011c59', WNumeric),
+    ('\U00011c72', '\U00011c8f', WALetter),
+    ('\U00011c92', '\U00011ca7', WExtend),
+    ('\U00011ca9', '\U00011cb6', WExtend),
+    ('\U00011d00', '\U00011d06', WALetter),
+    ('\U00011d08', '\U00011d09', WALetter),
+    ('\U00011d0b', '\U00011d30', WALetter),
+    ('\U00011d31', '\U00011d36', WExtend),
+    ('\U00011d3a', '\U00011d3a', WExtend),
+    ('\U00011d3c', '\U00011d3d', WExtend),
+    ('\U00011d3f', '\U00011d45', WExtend),
+    ('\U00011d46', '\U00011d46', WALetter),
+    ('\U00011d47', '\U00011d47', WExtend),
+    ('\U00011d50', '\U00011d59', WNumeric),
+    ('\U00011d60', '\U00011d65', WALetter),
+    ('\U00011d67', '\U00011d68', WALetter),
+    ('\U00011d6a', '\U00011d89', WALetter),
+    ('\U00011d8a', '\U00011d8e', WExtend),
+    ('\U00011d90', '\U00011d91', WExtend),
+    ('\U00011d93', '\U00011d97', WExtend),
+    ('\U00011d98', '\U00011d98', WALetter),
+    ('\U00011da0', '\U00011da9', WNumeric),
+    ('\U00011ee0', '\U00011ef2', WALetter),
+    ('\U00011ef3', '\U00011ef6', WExtend),
+    ('\U00011f00', '\U00011f01', WExtend),
+    ('\U00011f02', '\U00011f02', WALetter),
+    ('\U00011f03', '\U00011f03', WExtend),
+    ('\U00011f04', '\U00011f10', WALetter),
+    ('\U00011f12', '\U00011f33', WALetter),
+    ('\U00011f34', '\U00011f3a', WExtend),
+    ('\U00011f3e', '\U00011f42', WExtend),
+    ('\U00011f50', '\U00011f59', WNumeric),
+    ('\U00011f5a', '\U00011f5a', WExtend),
+    ('\U00011fb0', '\U00011fb0', WALetter),
+    ('\U00012000', '\U00012399', WALetter),
+    ('\U00012400', '\U0001246e', WALetter),
+    ('\U00012480', '\U00012543', WALetter),
+    ('\U00012f90', '\U00012ff0', WALetter),
+    ('\U00013000', '\U0001342f', WALetter),
+    ('\U00013430', '\U0001343f', WFormat),
+    ('\U00013440', '\U00013440', WExtend),
+    ('\U00013441', '\U00013446', WALetter),
+    ('\U00013447', '\U00013455', WExtend),
+    ('\U00013460', '\U000143fa', WALetter),
+    ('\U00014400', '\U00014646', WALetter),
+    ('\U00016100', '\U0001611d', WALetter),
+    ('\U0001611e', '\U0001612f', WExtend),
+    ('\U00016130', '\U00016139', WNumeric),
+    ('\U00016800', '\U00016a38', WALetter),
+    ('\U00016a40', '\U00016a5e', WALetter),
+    ('\U00016a60', '\U00016a69', WNumeric),
+    ('\U00016a70', '\U00016abe', WALetter),
+    ('\U00016ac0', '\U00016ac9', WNumeric),
+    ('\U00016ad0', '\U00016aed', WALetter),
+    ('\U00016af0', '\U00016af4', WExtend),
+    ('\U00016b00', '\U00016b2f', WALetter),
+    ('\U00016b30', '\U00016b36', WExtend),
+    ('\U00016b40', '\U00016b43', WALetter),
+    ('\U00016b50', '\U00016b59', WNumeric),
+    ('\U00016b63', '\U00016b77', WALetter),
+    ('\U00016b7d', '\U00016b8f', WALetter),
+    ('\U00016d40', '\U00016d6c', WALetter),
+    ('\U00016d70', '\U00016d79', WNumeric),
+    ('\U00016e40', '\U00016e7f', WALetter),
+    ('\U00016f00', '\U00016f4a', WALetter),
+    ('\U00016f4f', '\U00016f4f', WExtend),
+    ('\U00016f50', '\U00016f50', WALetter),
+    ('\U00016f51', '\U00016f87', WExtend),
+    ('\U00016f8f', '\U00016f92', WExtend),
+    ('\U00016f93', '\U00016f9f', WALetter),
+    ('\U00016fe0', '\U00016fe1', WALetter),
+    ('\U00016fe3', '\U00016fe3', WALetter),
+    ('\U00016fe4', '\U00016fe4', WExtend),
+    ('\U00016ff0', '\U00016ff1', WExtend),
+    ('\U0001aff0', '\U0001aff3', WKatakana),
+    ('\U0001aff5', '\U0001affb', WKatakana),
+    ('\U0001affd', '\U0001affe', WKatakana),
+    ('\U0001b000', '\U0001b000', WKatakana),
+    ('\U0001b120', '\U0001b122', WKatakana),
+    ('\U0001b155', '\U0001b155', WKatakana),
+    ('\U0001b164', '\U0001b167', WKatakana),
+    ('\U0001bc00', '\U0001bc6a', WALetter),
+    ('\U0001bc70', '\U0001bc7c', WALetter),
+    ('\U0001bc80', '\U0001bc88', WALetter),
+    ('\U0001bc90', '\U0001bc99', WALetter),
+    ('\U0001bc9d', '\U0001bc9e', WExtend),
+    ('\U0001bca0', '\U0001bca3', WFormat),
+    ('\U0001ccf0', '\U0001ccf9', WNumeric),
+    ('\U0001cf00', '\U0001cf2d', WExtend),
+    ('\U0001cf30', '\U0001cf46', WExtend),
+    ('\U0001d165', '\U0001d169', WExtend),
+    ('\U0001d16d', '\U0001d172', WExtend),
+    ('\U0001d173', '\U0001d17a', WFormat),
+    ('\U0001d17b', '\U0001d182', WExtend),
+    ('\U0001d185', '\U0001d18b', WExtend),
+    ('\U0001d1aa', '\U0001d1ad', WExtend),
+    ('\U0001d242', '\U0001d244', WExtend),
+    ('\U0001d400', '\U0001d454', WALetter),
+    ('\U0001d456', '\U0001d49c', WALetter),
+    ('\U0001d49e', '\U0001d49f', WALetter),
+    ('\U0001d4a2', '\U0001d4a2', WALetter),
+    ('\U0001d4a5', '\U0001d4a6', WALetter),
+    ('\U0001d4a9', '\U0001d4ac', WALetter),
+    ('\U0001d4ae', '\U0001d4b9', WALetter),
+    ('\U0001d4bb', '\U0001d4bb', WALetter),
+    ('\U0001d4bd', '\U0001d4c3', WALetter),
+    ('\U0001d4c5', '\U0001d505', WALetter),
+    ('\U0001d507', '\U0001d50a', WALetter),
+    ('\U0001d50d', '\U0001d514', WALetter),
+    ('\U0001d516', '\U0001d51c', WALetter),
+    ('\U0001d51e', '\U0001d539', WALetter),
+    ('\U0001d53b', '\U0001d53e', WALetter),
+    ('\U0001d540', '\U0001d544', WALetter),
+    ('\U0001d546', '\U0001d546', WALetter),
+    ('\U0001d54a', '\U0001d550', WALetter),
+    ('\U0001d552', '\U0001d6a5', WALetter),
+    ('\U0001d6a8', '\U0001d6c0', WALetter),
+    ('\U0001d6c2', '\U0001d6da', WALetter),
+    ('\U0001d6dc', '\U0001d6fa', WALetter),
+    ('\U0001d6fc', '\U0001d714', WALetter),
+    ('\U0001d716', '\U0001d734', WALetter),
+    ('\U0001d736', '\U0001d74e', WALetter),
+    ('\U0001d750', '\U0001d76e', WALetter),
+    ('\U0001d770', '\U0001d788', WALetter),
+    ('\U0001d78a', '\U0001d7a8', WALetter),
+    ('\U0001d7aa', '\U0001d7c2', WALetter),
+    ('\U0001d7c4', '\U0001d7cb', WALetter),
+    ('\U0001d7ce', '\U0001d7ff', WNumeric),
+    ('\U0001da00', '\U0001da36', WExtend),
+    ('\U0001da3b', '\U0001da6c', WExtend),
+    ('\U0001da75', '\U0001da75', WExtend),
+    ('\U0001da84', '\U0001da84', WExtend),
+    ('\U0001da9b', '\U0001da9f', WExtend),
+    ('\U0001daa1', '\U0001daaf', WExtend),
+    ('\U0001df00', '\U0001df1e', WALetter),
+    ('\U0001df25', '\U0001df2a', WALetter),
+    ('\U0001e000', '\U0001e006', WExtend),
+    ('\U0001e008', '\U0001e018', WExtend),
+    ('\U0001e01b', '\U0001e021', WExtend),
+    ('\U0001e023', '\U0001e024', WExtend),
+    ('\U0001e026', '\U0001e02a', WExtend),
+    ('\U0001e030', '\U0001e06d', WALetter),
+    ('\U0001e08f', '\U0001e08f', WExtend),
+    ('\U0001e100', '\U0001e12c', WALetter),
+    ('\U0001e130', '\U0001e136', WExtend),
+    ('\U0001e137', '\U0001e13d', WALetter),
+    ('\U0001e140', '\U0001e149', WNumeric),
+    ('\U0001e14e', '\U0001e14e', WALetter),
+    ('\U0001e290', '\U0001e2ad', WALetter),
+    ('\U0001e2ae', '\U0001e2ae', WExtend),
+    ('\U0001e2c0', '\U0001e2eb', WALetter),
+    ('\U0001e2ec', '\U0001e2ef', WExtend),
+    ('\U0001e2f0', '\U0001e2f9', WNumeric),
+    ('\U0001e4d0', '\U0001e4eb', WALetter),
+    ('\U0001e4ec', '\U0001e4ef', WExtend),
+    ('\U0001e4f0', '\U0001e4f9', WNumeric),
+    ('\U0001e5d0', '\U0001e5ed', WALetter),
+    ('\U0001e5ee', '\U0001e5ef', WExtend),
+    ('\U0001e5f0', '\U0001e5f0', WALetter),
+    ('\U0001e5f1', '\U0001e5fa', WNumeric),
+    ('\U0001e7e0', '\U0001e7e6', WALetter),
+    ('\U0001e7e8', '\U0001e7eb', WALetter),
+    ('\U0001e7ed', '\U0001e7ee', WALetter),
+    ('\U0001e7f0', '\U0001e7fe', WALetter),
+    ('\U0001e800', '\U0001e8c4', WALetter),
+    ('\U0001e8d0', '\U0001e8d6', WExtend),
+    ('\U0001e900', '\U0001e943', WALetter),
+    ('\U0001e944', '\U0001e94a', WExtend),
+    ('\U0001e94b', '\U0001e94b', WALetter),
+    ('\U0001e950', '\U0001e959', WNumeric),
+    ('\U0001ee00', '\U0001ee03', WALetter),
+    ('\U0001ee05', '\U0001ee1f', WALetter),
+    ('\U0001ee21', '\U0001ee22', WALetter),
+    ('\U0001ee24', '\U0001ee24', WALetter),
+    ('\U0001ee27', '\U0001ee27', WALetter),
+    ('\U0001ee29', '\U0001ee32', WALetter),
+    ('\U0001ee34', '\U0001ee37', WALetter),
+    ('\U0001ee39', '\U0001ee39', WALetter),
+    ('\U0001ee3b', '\U0001ee3b', WALetter),
+    ('\U0001ee42', '\U0001ee42', WALetter),
+    ('\U0001ee47', '\U0001ee47', WALetter),
+    ('\U0001ee49', '\U0001ee49', WALetter),
+    ('\U0001ee4b', '\U0001ee4b', WALetter),
+    ('\U0001ee4d', '\U0001ee4f', WALetter),
+    ('\U0001ee51', '\U0001ee52', WALetter),
+    ('\U0001ee54', '\U0001ee54', WALetter),
+    ('\U0001ee57', '\U0001ee57', WALetter),
+    ('\U0001ee59', '\U0001ee59', WALetter),
+    ('\U0001ee5b', '\U0001ee5b', WALetter),
+    ('\U0001ee5d', '\U0001ee5d', WALetter),
+    ('\U0001ee5f', '\U0001ee5f', WALetter),
+    ('\U0001ee61', '\U0001ee62', WALetter),
+    ('\U0001ee64', '\U0001ee64', WALetter),
+    ('\U0001ee67', '\U0001ee6a', WALetter),
+    ('\U0001ee6c', '\U0001ee72', WALetter),
+    ('\U0001ee74', '\U0001ee77', WALetter),
+    ('\U0001ee79', '\U0001ee7c', WALetter),
+    ('\U0001ee7e', '\U0001ee7e', WALetter),
+    ('\U0001ee80', '\U0001ee89', WALetter),
+    ('\U0001ee8b', '\U0001ee9b', WALetter),
+    ('\U0001eea1', '\U0001eea3', WALetter),
+    ('\U0001eea5', '\U0001eea9', WALetter),
+    ('\U0001eeab', '\U0001eebb', WALetter),
+    ('\U0001f130', '\U0001f149', WALetter),
+    ('\U0001f150', '\U0001f169', WALetter),
+    ('\U0001f170', '\U0001f189', WALetter),
+    ('\U0001f1e6', '\U0001f1ff', WRegionalIndicator),
+    ('\U0001f3fb', '\U0001f3ff', WExtend),
+    ('\U0001fbf0', '\U0001fbf9', WNumeric),
+    ('\U000e0001', '\U000e0001', WFormat),
+    ('\U000e0020', '\U000e007f', WExtend),
+    ('\U000e0100', '\U000e01ef', WExtend)
+];
+
+// The characters whose full case folding (status F in CaseFolding.txt) is
+// more than one character, with that folding, sorted by character.
+pub static FULL_FOLDS: &'static [(char, &'static str)] = &[
+    ('\U000000df', "ss"),
+    ('\U00000130', "i\u0307"),
+    ('\U00000149', "\u02bcn"),
+    ('\U000001f0', "j\u030c"),
+    ('\U00000390', "\u03b9\u0308\u0301"),
+    ('\U000003b0', "\u03c5\u0308\u0301"),
+    ('\U00000587', "\u0565\u0582"),
+    ('\U00001e96', "h\u0331"),
+    ('\U00001e97', "t\u0308"),
+    ('\U00001e98', "w\u030a"),
+    ('\U00001e99', "y\u030a"),
+    ('\U00001e9a', "a\u02be"),
+    ('\U00001e9e', "ss"),
+    ('\U00001f50', "\u03c5\u0313"),
+    ('\U00001f52', "\u03c5\u0313\u0300"),
+    ('\U00001f54', "\u03c5\u0313\u0301"),
+    ('\U00001f56', "\u03c5\u0313\u0342"),
+    ('\U00001f80', "\u1f00\u03b9"),
+    ('\U00001f81', "\u1f01\u03b9"),
+    ('\U00001f82', "\u1f02\u03b9"),
+    ('\U00001f83', "\u1f03\u03b9"),
+    ('\U00001f84', "\u1f04\u03b9"),
+    ('\U00001f85', "\u1f05\u03b9"),
+    ('\U00001f86', "\u1f06\u03b9"),
+    ('\U00001f87', "\u1f07\u03b9"),
+    ('\U00001f88', "\u1f00\u03b9"),
+    ('\U00001f89', "\u1f01\u03b9"),
+    ('\U00001f8a', "\u1f02\u03b9"),
+    ('\U00001f8b', "\u1f03\u03b9"),
+    ('\U00001f8c', "\u1f04\u03b9"),
+    ('\U00001f8d', "\u1f05\u03b9"),
+    ('\U00001f8e', "\u1f06\u03b9"),
+    ('\U00001f8f', "\u1f07\u03b9"),
+    ('\U00001f90', "\u1f20\u03b9"),
+    ('\U00001f91', "\u1f21\u03b9"),
+    ('\U00001f92', "\u1f22\u03b9"),
+    ('\U00001f93', "\u1f23\u03b9"),
+    ('\U00001f94', "\u1f24\u03b9"),
+    ('\U00001f95', "\u1f25\u03b9"),
+    ('\U00001f96', "\u1f26\u03b9"),
+    ('\U00001f97', "\u1f27\u03b9"),
+    ('\U00001f98', "\u1f20\u03b9"),
+    ('\U00001f99', "\u1f21\u03b9"),
+    ('\U00001f9a', "\u1f22\u03b9"),
+    ('\U00001f9b', "\u1f23\u03b9"),
+    ('\U00001f9c', "\u1f24\u03b9"),
+    ('\U00001f9d', "\u1f25\u03b9"),
+    ('\U00001f9e', "\u1f26\u03b9"),
+    ('\U00001f9f', "\u1f27\u03b9"),
+    ('\U00001fa0', "\u1f60\u03b9"),
+    ('\U00001fa1', "\u1f61\u03b9"),
+    ('\U00001fa2', "\u1f62\u03b9"),
+    ('\U00001fa3', "\u1f63\u03b9"),
+    ('\U00001fa4', "\u1f64\u03b9"),
+    ('\U00001fa5', "\u1f65\u03b9"),
+    ('\U00001fa6', "\u1f66\u03b9"),
+    ('\U00001fa7', "\u1f67\u03b9"),
+    ('\U00001fa8', "\u1f60\u03b9"),
+    ('\U00001fa9', "\u1f61\u03b9"),
+    ('\U00001faa', "\u1f62\u03b9"),
+    ('\U00001fab', "\u1f63\u03b9"),
+    ('\U00001fac', "\u1f64\u03b9"),
+    ('\U00001fad', "\u1f65\u03b9"),
+    ('\U00001fae', "\u1f66\u03b9"),
+    ('\U00001faf', "\u1f67\u03b9"),
+    ('\U00001fb2', "\u1f70\u03b9"),
+    ('\U00001fb3', "\u03b1\u03b9"),
+    ('\U00001fb4', "\u03ac\u03b9"),
+    ('\U00001fb6', "\u03b1\u0342"),
+    ('\U00001fb7', "\u03b1\u0342\u03b9"),
+    ('\U00001fbc', "\u03b1\u03b9"),
+    ('\U00001fc2', "\u1f74\u03b9"),
+    ('\U00001fc3', "\u03b7\u03b9"),
+    ('\U00001fc4', "\u03ae\u03b9"),
+    ('\U00001fc6', "\u03b7\u0342"),
+    ('\U00001fc7', "\u03b7\u0342\u03b9"),
+    ('\U00001fcc', "\u03b7\u03b9"),
+    ('\U00001fd2', "\u03b9\u0308\u0300"),
+    ('\U00001fd3', "\u03b9\u0308\u0301"),
+    ('\U00001fd6', "\u03b9\u0342"),
+    ('\U00001fd7', "\u03b9\u0308\u0342"),
+    ('\U00001fe2', "\u03c5\u0308\u0300"),
+    ('\U00001fe3', "\u03c5\u0308\u0301"),
+    ('\U00001fe4', "\u03c1\u0313"),
+    ('\U00001fe6', "\u03c5\u0342"),
+    ('\U00001fe7', "\u03c5\u0308\u0342"),
+    ('\U00001ff2', "\u1f7c\u03b9"),
+    ('\U00001ff3', "\u03c9\u03b9"),
+    ('\U00001ff4', "\u03ce\u03b9"),
+    ('\U00001ff6', "\u03c9\u0342"),
+    ('\U00001ff7', "\u03c9\u0342\u03b9"),
+    ('\U00001ffc', "\u03c9\u03b9"),
+    ('\U0000fb00', "ff"),
+    ('\U0000fb01', "fi"),
+    ('\U0000fb02', "fl"),
+    ('\U0000fb03', "ffi"),
+    ('\U0000fb04', "ffl"),
+    ('\U0000fb05', "st"),
+    ('\U0000fb06', "st"),
+    ('\U0000fb13', "\u0574\u0576"),
+    ('\U0000fb14', "\u0574\u0565"),
+    ('\U0000fb15', "\u0574\u056b"),
+    ('\U0000fb16', "\u057e\u0576"),
+    ('\U0000fb17', "\u0574\u056d")
+];
+
+// The names and aliases of the general categories in `UNICODE_CLASSES`
+// (from PropertyValueAliases.txt), with the name of their class.
+pub static GC_NAMES: Aliases = &[
+    ("C", "C"),
+    ("Other", "C"),
+    ("Cc", "Cc"),
+    ("Control", "Cc"),
+    ("Cntrl", "Cc"),
+    ("Cf", "Cf"),
+    ("Format", "Cf"),
+    ("Co", "Co"),
+    ("Private_Use", "Co"),
+    ("L", "L"),
+    ("Letter", "L"),
+    ("LC", "LC"),
+    ("Cased_Letter", "LC"),
+    ("Ll", "Ll"),
+    ("Lowercase_Letter", "Ll"),
+    ("Lm", "Lm"),
+    ("Modifier_Letter", "Lm"),
+    ("Lo", "Lo"),
+    ("Other_Letter", "Lo"),
+    ("Lt", "Lt"),
+    ("Titlecase_Letter", "Lt"),
+    ("Lu", "Lu"),
+    ("Uppercase_Letter", "Lu"),
+    ("M", "M"),
+    ("Mark", "M"),
+    ("Combining_Mark", "M"),
+    ("Mc", "Mc"),
+    ("Spacing_Mark", "Mc"),
+    ("Me", "Me"),
+    ("Enclosing_Mark", "Me"),
+    ("Mn", "Mn"),
+    ("Nonspacing_Mark", "Mn"),
+    ("N", "N"),
+    ("Number", "N"),
+    ("Nd", "Nd"),
+    ("Decimal_Number", "Nd"),
+    ("Digit", "Nd"),
+    ("Nl", "Nl"),
+    ("Letter_Number", "Nl"),
+    ("No", "No"),
+    ("Other_Number", "No"),
+    ("P", "P"),
+    ("Punctuation", "P"),
+    ("Punct", "P"),
+    ("Pc", "Pc"),
+    ("Connector_Punctuation", "Pc"),
+    ("Pd", "Pd"),
+    ("Dash_Punctuation", "Pd"),
+    ("Pe", "Pe"),
+    ("Close_Punctuation", "Pe"),
+    ("Pf", "Pf"),
+    ("Final_Punctuation", "Pf"),
+    ("Pi", "Pi"),
+    ("Initial_Punctuation", "Pi"),
+    ("Po", "Po"),
+    ("Other_Punctuation", "Po"),
+    ("Ps", "Ps"),
+    ("Open_Punctuation", "Ps"),
+    ("S", "S"),
+    ("Symbol", "S"),
+    ("Sc", "Sc"),
+    ("Currency_Symbol", "Sc"),
+    ("Sk", "Sk"),
+    ("Modifier_Symbol", "Sk"),
+    ("Sm", "Sm"),
+    ("Math_Symbol", "Sm"),
+    ("So", "So"),
+    ("Other_Symbol", "So"),
+    ("Yi", "Yi"),
+    ("Z", "Z"),
+    ("Separator", "Z"),
+    ("Zl", "Zl"),
+    ("Line_Separator", "Zl"),
+    ("Zp", "Zp"),
+    ("Paragraph_Separator", "Zp"),
+    ("Zs", "Zs"),
+    ("Space_Separator", "Zs")
+];
+
+// The names and aliases of the scripts in `UNICODE_CLASSES` (from
+// PropertyValueAliases.txt), with the name of their class.
+pub static SCRIPT_NAMES: Aliases = &[
+    ("Arab", "Arabic"),
+    ("Arabic", "Arabic"),
+    ("Armn", "Armenian"),
+    ("Armenian", "Armenian"),
+    ("Avst", "Avestan"),
+    ("Avestan", "Avestan"),
+    ("Bali", "Balinese"),
+    ("Balinese", "Balinese"),
+    ("Bamu", "Bamum"),
+    ("Bamum", "Bamum"),
+    ("Batk", "Batak"),
+    ("Batak", "Batak"),
+    ("Beng", "Bengali"),
+    ("Bengali", "Bengali"),
+    ("Bopo", "Bopomofo"),
+    ("Bopomofo", "Bopomofo"),
+    ("Brah", "Brahmi"),
+    ("Brahmi", "Brahmi"),
+    ("Brai", "Braille"),
+    ("Braille", "Braille"),
+    ("Bugi", "Buginese"),
+    ("Buginese", "Buginese"),
+    ("Buhd", "Buhid"),
+    ("Buhid", "Buhid"),
+    ("Cans", "Canadian_Aboriginal"),
+    ("Canadian_Aboriginal", "Canadian_Aboriginal"),
+    ("Cari", "Carian"),
+    ("Carian", "Carian"),
+    ("Cakm", "Chakma"),
+    ("Chakma", "Chakma"),
+    ("Cham", "Cham"),
+    ("Cher", "Cherokee"),
+    ("Cherokee", "Cherokee"),
+    ("Zyyy", "Common"),
+    ("Common", "Common"),
+    ("Copt", "Coptic"),
+    ("Coptic", "Coptic"),
+    ("Qaac", "Coptic"),
+    ("Xsux", "Cuneiform"),
+    ("Cuneiform", "Cuneiform"),
+    ("Cprt", "Cypriot"),
+    ("Cypriot", "Cypriot"),
+    ("Cyrl", "Cyrillic"),
+    ("Cyrillic", "Cyrillic"),
+    ("Dsrt", "Deseret"),
+    ("Deseret", "Deseret"),
+    ("Deva", "Devanagari"),
+    ("Devanagari", "Devanagari"),
+    ("Egyp", "Egyptian_Hieroglyphs"),
+    ("Egyptian_Hieroglyphs", "Egyptian_Hieroglyphs"),
+    ("Ethi", "Ethiopic"),
+    ("Ethiopic", "Ethiopic"),
+    ("Geor", "Georgian"),
+    ("Georgian", "Georgian"),
+    ("Glag", "Glagolitic"),
+    ("Glagolitic", "Glagolitic"),
+    ("Goth", "Gothic"),
+    ("Gothic", "Gothic"),
+    ("Grek", "Greek"),
+    ("Greek", "Greek"),
+    ("Gujr", "Gujarati"),
+    ("Gujarati", "Gujarati"),
+    ("Guru", "Gurmukhi"),
+    ("Gurmukhi", "Gurmukhi"),
+    ("Hani", "Han"),
+    ("Han", "Han"),
+    ("Hang", "Hangul"),
+    ("Hangul", "Hangul"),
+    ("Hano", "Hanunoo"),
+    ("Hanunoo", "Hanunoo"),
+    ("Hebr", "Hebrew"),
+    ("Hebrew", "Hebrew"),
+    ("Hira", "Hiragana"),
+    ("Hiragana", "Hiragana"),
+    ("Armi", "Imperial_Aramaic"),
+    ("Imperial_Aramaic", "Imperial_Aramaic"),
+    ("Zinh", "Inherited"),
+    ("Inherited", "Inherited"),
+    ("Qaai", "Inherited"),
+    ("Phli", "Inscriptional_Pahlavi"),
+    ("Inscriptional_Pahlavi", "Inscriptional_Pahlavi"),
+    ("Prti", "Inscriptional_Parthian"),
+    ("Inscriptional_Parthian", "Inscriptional_Parthian"),
+    ("Java", "Javanese"),
+    ("Javanese", "Javanese"),
+    ("Kthi", "Kaithi"),
+    ("Kaithi", "Kaithi"),
+    ("Knda", "Kannada"),
+    ("Kannada", "Kannada"),
+    ("Kana", "Katakana"),
+    ("Katakana", "Katakana"),
+    ("Kali", "Kayah_Li"),
+    ("Kayah_Li", "Kayah_Li"),
+    ("Khar", "Kharoshthi"),
+    ("Kharoshthi", "Kharoshthi"),
+    ("Khmr", "Khmer"),
+    ("Khmer", "Khmer"),
+    ("Laoo", "Lao"),
+    ("Lao", "Lao"),
+    ("Latn", "Latin"),
+    ("Latin", "Latin"),
+    ("Lepc", "Lepcha"),
+    ("Lepcha", "Lepcha"),
+    ("Limb", "Limbu"),
+    ("Limbu", "Limbu"),
+    ("Linb", "Linear_B"),
+    ("Linear_B", "Linear_B"),
+    ("Lisu", "Lisu"),
+    ("Lyci", "Lycian"),
+    ("Lycian", "Lycian"),
+    ("Lydi", "Lydian"),
+    ("Lydian", "Lydian"),
+    ("Mlym", "Malayalam"),
+    ("Malayalam", "Malayalam"),
+    ("Mand", "Mandaic"),
+    ("Mandaic", "Mandaic"),
+    ("Mtei", "Meetei_Mayek"),
+    ("Meetei_Mayek", "Meetei_Mayek"),
+    ("Merc", "Meroitic_Cursive"),
+    ("Meroitic_Cursive", "Meroitic_Cursive"),
+    ("Mero", "Meroitic_Hieroglyphs"),
+    ("Meroitic_Hieroglyphs", "Meroitic_Hieroglyphs"),
+    ("Plrd", "Miao"),
+    ("Miao", "Miao"),
+    ("Mong", "Mongolian"),
+    ("Mongolian", "Mongolian"),
+    ("Mymr", "Myanmar"),
+    ("Myanmar", "Myanmar"),
+    ("Talu", "New_Tai_Lue"),
+    ("New_Tai_Lue", "New_Tai_Lue"),
+    ("Nkoo", "Nko"),
+    ("Nko", "Nko"),
+    ("Ogam", "Ogham"),
+    ("Ogham", "Ogham"),
+    ("Olck", "Ol_Chiki"),
+    ("Ol_Chiki", "Ol_Chiki"),
+    ("Ital", "Old_Italic"),
+    ("Old_Italic", "Old_Italic"),
+    ("Xpeo", "Old_Persian"),
+    ("Old_Persian", "Old_Persian"),
+    ("Sarb", "Old_South_Arabian"),
+    ("Old_South_Arabian", "Old_South_Arabian"),
+    ("Orkh", "Old_Turkic"),
+    ("Old_Turkic", "Old_Turkic"),
+    ("Orya", "Oriya"),
+    ("Oriya", "Oriya"),
+    ("Osma", "Osmanya"),
+    ("Osmanya", "Osmanya"),
+    ("Phag", "Phags_Pa"),
+    ("Phags_Pa", "Phags_Pa"),
+    ("Phnx", "Phoenician"),
+    ("Phoenician", "Phoenician"),
+    ("Rjng", "Rejang"),
+    ("Rejang", "Rejang"),
+    ("Runr", "Runic"),
+    ("Runic", "Runic"),
+    ("Samr", "Samaritan"),
+    ("Samaritan", "Samaritan"),
+    ("Saur", "Saurashtra"),
+    ("Saurashtra", "Saurashtra"),
+    ("Shrd", "Sharada"),
+    ("Sharada", "Sharada"),
+    ("Shaw", "Shavian"),
+    ("Shavian", "Shavian"),
+    ("Sinh", "Sinhala"),
+    ("Sinhala", "Sinhala"),
+    ("Sora", "Sora_Sompeng"),
+    ("Sora_Sompeng", "Sora_Sompeng"),
+    ("Sund", "Sundanese"),
+    ("Sundanese", "Sundanese"),
+    ("Sylo", "Syloti_Nagri"),
+    ("Syloti_Nagri", "Syloti_Nagri"),
+    ("Syrc", "Syriac"),
+    ("Syriac", "Syriac"),
+    ("Tglg", "Tagalog"),
+    ("Tagalog", "Tagalog"),
+    ("Tagb", "Tagbanwa"),
+    ("Tagbanwa", "Tagbanwa"),
+    ("Tale", "Tai_Le"),
+    ("Tai_Le", "Tai_Le"),
+    ("Lana", "Tai_Tham"),
+    ("Tai_Tham", "Tai_Tham"),
+    ("Tavt", "Tai_Viet"),
+    ("Tai_Viet", "Tai_Viet"),
+    ("Takr", "Takri"),
+    ("Takri", "Takri"),
+    ("Taml", "Tamil"),
+    ("Tamil", "Tamil"),
+    ("Telu", "Telugu"),
+    ("Telugu", "Telugu"),
+    ("Thaa", "Thaana"),
+    ("Thaana", "Thaana"),
+    ("Thai", "Thai"),
+    ("Tibt", "Tibetan"),
+    ("Tibetan", "Tibetan"),
+    ("Tfng", "Tifinagh"),
+    ("Tifinagh", "Tifinagh"),
+    ("Ugar", "Ugaritic"),
+    ("Ugaritic", "Ugaritic"),
+    ("Vaii", "Vai"),
+    ("Vai", "Vai")
+];
+
+// The names and aliases of the properties in `BINARY_PROPERTIES` (from
+// PropertyAliases.txt), with the name of their class.
+pub static BINARY_NAMES: Aliases = &[
+    ("AHex", "ASCII_Hex_Digit"),
+    ("ASCII_Hex_Digit", "ASCII_Hex_Digit"),
+    ("Alpha", "Alphabetic"),
+    ("Alphabetic", "Alphabetic"),
+    ("Dash", "Dash"),
+    ("DI", "Default_Ignorable_Code_Point"),
+    ("Default_Ignorable_Code_Point", "Default_Ignorable_Code_Point"),
+    ("Dia", "Diacritic"),
+    ("Diacritic", "Diacritic"),
+    ("Hex", "Hex_Digit"),
+    ("Hex_Digit", "Hex_Digit"),
+    ("Ideo", "Ideographic"),
+    ("Ideographic", "Ideographic"),
+    ("Lower", "Lowercase"),
+    ("Lowercase", "Lowercase"),
+    ("Math", "Math"),
+    ("NChar", "Noncharacter_Code_Point"),
+    ("Noncharacter_Code_Point", "Noncharacter_Code_Point"),
+    ("Upper", "Uppercase"),
+    ("Uppercase", "Uppercase"),
+    ("WSpace", "White_Space"),
+    ("White_Space", "White_Space"),
+    ("Space", "White_Space"),
+    ("Assigned", "Assigned")
+];
+
+// Binary properties (from PropList.txt and DerivedCoreProperties.txt) and
+// the Assigned pseudo-property, as of Unicode 14.0.
+pub static BINARY_PROPERTIES: NamedClasses = &[
+
+("ASCII_Hex_Digit", &[
+    ('\U00000030', '\U00000039'),
+    ('\U00000041', '\U00000046'),
+    ('\U00000061', '\U00000066')
+    ]),
+
+("Alphabetic", &[
+    ('\U00000041', '\U0000005a'),
+    ('\U00000061', '\U0000007a'),
+    ('\U000000aa', '\U000000aa'),
+    ('\U000000b5', '\U000000b5'),
+    ('\U000000ba', '\U000000ba'),
+    ('\U000000c0', '\U000000d6'),
+    ('\U000000d8', '\U000000f6'),
+    ('\U000000f8', '\U000002c1'),
+    ('\U000002c6', '\U000002d1'),
+    ('\U000002e0', '\U000002e4'),
+    ('\U000002ec', '\U000002ec'),
+    ('\U000002ee', '\U000002ee'),
+    ('\U00000345', '\U00000345'),
+    ('\U00000370', '\U00000374'),
+    ('\U00000376', '\U00000377'),
+    ('\U0000037a', '\U0000037d'),
+    ('\U0000037f', '\U0000037f'),
+    ('\U00000386', '\U00000386'),
+    ('\U00000388', '\U0000038a'),
+    ('\U0000038c', '\U0000038c'),
+    ('\U0000038e', '\U000003a1'),
+    ('\U000003a3', '\U000003f5'),
+    ('\U000003f7', '\U00000481'),
+    ('\U0000048a', '\U0000052f'),
+    ('\U00000531', '\U00000556'),
+    ('\U00000559', '\U00000559'),
+    ('\U00000560', '\U00000588'),
+    ('\U000005b0', '\U000005bd'),
+    ('\U000005bf', '\U000005bf'),
+    ('\U000005c1', '\U000005c2'),
+    ('\U000005c4', '\U000005c5'),
+    ('\U000005c7', '\U000005c7'),
+    ('\U000005d0', '\U000005ea'),
+    ('\U000005ef', '\U000005f2'),
+    ('\U00000610', '\U0000061a'),
+    ('\U00000620', '\U00000657'),
+    ('\U00000659', '\U0000065f'),
+    ('\U0000066e', '\U000006d3'),
+    ('\U000006d5', '\U000006dc'),
+    ('\U000006e1', '\U000006e8'),
+    ('\U000006ed', '\U000006ef'),
+    ('\U000006fa', '\U000006fc'),
+    ('\U000006ff', '\U000006ff'),
+    ('\U00000710', '\U0000073f'),
+    ('\U0000074d', '\U000007b1'),
+    ('\U000007ca', '\U000007ea'),
+    ('\U000007f4', '\U000007f5'),
+    ('\U000007fa', '\U000007fa'),
+    ('\U00000800', '\U00000817'),
+    ('\U0000081a', '\U0000082c'),
+    ('\U00000840', '\U00000858'),
+    ('\U00000860', '\U0000086a'),
+    ('\U00000870', '\U00000887'),
+    ('\U00000889', '\U0000088e'),
+    ('\U000008a0', '\U000008c9'),
+    ('\U000008d4', '\U000008df'),
+    ('\U000008e3', '\U000008e9'),
+    ('\U000008f0', '\U0000093b'),
+    ('\U0000093d', '\U0000094c'),
+    ('\U0000094e', '\U00000950'),
+    ('\U00000955', '\U00000963'),
+    ('\U00000971', '\U00000983'),
+    ('\U00000985', '\U0000098c'),
+    ('\U0000098f', '\U00000990'),
+    ('\U00000993', '\U000009a8'),
+    ('\U000009aa', '\U000009b0'),
+    ('\U000009b2', '\U000009b2'),
+    ('\U000009b6', '\U000009b9'),
+    ('\U000009bd', '\U000009c4'),
+    ('\U000009c7', '\U000009c8'),
+    ('\U000009cb', '\U000009cc'),
+    ('\U000009ce', '\U000009ce'),
+    ('\U000009d7', '\U000009d7'),
+    ('\U000009dc', '\U000009dd'),
+    ('\U000009df', '\U000009e3'),
+    ('\U000009f0', '\U000009f1'),
+    ('\U000009fc', '\U000009fc'),
+    ('\U00000a01', '\U00000a03'),
+    ('\U00000a05', '\U00000a0a'),
+    ('\U00000a0f', '\U00000a10'),
+    ('\U00000a13', '\U00000a28'),
+    ('\U00000a2a', '\U00000a30'),
+    ('\U00000a32', '\U00000a33'),
+    ('\U00000a35', '\U00000a36'),
+    ('\U00000a38', '\U00000a39'),
+    ('\U00000a3e', '\U00000a42'),
+    ('\U00000a47', '\U00000a48'),
+    ('\U00000a4b', '\U00000a4c'),
+    ('\U00000a51', '\U00000a51'),
+    ('\U00000a59', '\U00000a5c'),
+    ('\U00000a5e', '\U00000a5e'),
+    ('\U00000a70', '\U00000a75'),
+    ('\U00000a81', '\U00000a83'),
+    ('\U00000a85', '\U00000a8d'),
+    ('\U00000a8f', '\U00000a91'),
+    ('\U00000a93', '\U00000aa8'),
+    ('\U00000aaa', '\U00000ab0'),
+    ('\U00000ab2', '\U00000ab3'),
+    ('\U00000ab5', '\U00000ab9'),
+    ('\U00000abd', '\U00000ac5'),
+    ('\U00000ac7', '\U00000ac9'),
+    ('\U00000acb', '\U00000acc'),
+    ('\U00000ad0', '\U00000ad0'),
+    ('\U00000ae0', '\U00000ae3'),
+    ('\U00000af9', '\U00000afc'),
+    ('\U00000b01', '\U00000b03'),
+    ('\U00000b05', '\U00000b0c'),
+    ('\U00000b0f', '\U00000b10'),
+    ('\U00000b13', '\U00000b28'),
+    ('\U00000b2a', '\U00000b30'),
+    ('\U00000b32', '\U00000b33'),
+    ('\U00000b35', '\U00000b39'),
+    ('\U00000b3d', '\U00000b44'),
+    ('\U00000b47', '\U00000b48'),
+    ('\U00000b4b', '\U00000b4c'),
+    ('\U00000b56', '\U00000b57'),
+    ('\U00000b5c', '\U00000b5d'),
+    ('\U00000b5f', '\U00000b63'),
+    ('\U00000b71', '\U00000b71'),
+    ('\U00000b82', '\U00000b83'),
+    ('\U00000b85', '\U00000b8a'),
+    ('\U00000b8e', '\U00000b90'),
+    ('\U00000b92', '\U00000b95'),
+    ('\U00000b99', '\U00000b9a'),
+    ('\U00000b9c', '\U00000b9c'),
+    ('\U00000b9e', '\U00000b9f'),
+    ('\U00000ba3', '\U00000ba4'),
+    ('\U00000ba8', '\U00000baa'),
+    ('\U00000bae', '\U00000bb9'),
+    ('\U00000bbe', '\U00000bc2'),
+    ('\U00000bc6', '\U00000bc8'),
+    ('\U00000bca', '\U00000bcc'),
+    ('\U00000bd0', '\U00000bd0'),
+    ('\U00000bd7', '\U00000bd7'),
+    ('\U00000c00', '\U00000c03'),
+    ('\U00000c05', '\U00000c0c'),
+    ('\U00000c0e', '\U00000c10'),
+    ('\U00000c12', '\U00000c28'),
+    ('\U00000c2a', '\U00000c39'),
+    ('\U00000c3d', '\U00000c44'),
+    ('\U00000c46', '\U00000c48'),
+    ('\U00000c4a', '\U00000c4c'),
+    ('\U00000c55', '\U00000c56'),
+    ('\U00000c58', '\U00000c5a'),
+    ('\U00000c5d', '\U00000c5d'),
+    ('\U00000c60', '\U00000c63'),
+    ('\U00000c80', '\U00000c83'),
+    ('\U00000c85', '\U00000c8c'),
+    ('\U00000c8e', '\U00000c90'),
+    ('\U00000c92', '\U00000ca8'),
+    ('\U00000caa', '\U00000cb3'),
+    ('\U00000cb5', '\U00000cb9'),
+    ('\U00000cbd', '\U00000cc4'),
+    ('\U00000cc6', '\U00000cc8'),
+    ('\U00000cca', '\U00000ccc'),
+    ('\U00000cd5', '\U00000cd6'),
+    ('\U00000cdd', '\U00000cde'),
+    ('\U00000ce0', '\U00000ce3'),
+    ('\U00000cf1', '\U00000cf2'),
+    ('\U00000d00', '\U00000d0c'),
+    ('\U00000d0e', '\U00000d10'),
+    ('\U00000d12', '\U00000d3a'),
+    ('\U00000d3d', '\U00000d44'),
+    ('\U00000d46', '\U00000d48'),
+    ('\U00000d4a', '\U00000d4c'),
+    ('\U00000d4e', '\U00000d4e'),
+    ('\U00000d54', '\U00000d57'),
+    ('\U00000d5f', '\U00000d63'),
+    ('\U00000d7a', '\U00000d7f'),
+    ('\U00000d81', '\U00000d83'),
+    ('\U00000d85', '\U00000d96'),
+    ('\U00000d9a', '\U00000db1'),
+    ('\U00000db3', '\U00000dbb'),
+    ('\U00000dbd', '\U00000dbd'),
+    ('\U00000dc0', '\U00000dc6'),
+    ('\U00000dcf', '\U00000dd4'),
+    ('\U00000dd6', '\U00000dd6'),
+    ('\U00000dd8', '\U00000ddf'),
+    ('\U00000df2', '\U00000df3'),
+    ('\U00000e01', '\U00000e3a'),
+    ('\U00000e40', '\U00000e46'),
+    ('\U00000e4d', '\U00000e4d'),
+    ('\U00000e81', '\U00000e82'),
+    ('\U00000e84', '\U00000e84'),
+    ('\U00000e86', '\U00000e8a'),
+    ('\U00000e8c', '\U00000ea3'),
+    ('\U00000ea5', '\U00000ea5'),
+    ('\U00000ea7', '\U00000eb9'),
+    ('\U00000ebb', '\U00000ebd'),
+    ('\U00000ec0', '\U00000ec4'),
+    ('\U00000ec6', '\U00000ec6'),
+    ('\U00000ecd', '\U00000ecd'),
+    ('\U00000edc', '\U00000edf'),
+    ('\U00000f00', '\U00000f00'),
+    ('\U00000f40', '\U00000f47'),
+    ('\U00000f49', '\U00000f6c'),
+    ('\U00000f71', '\U00000f81'),
+    ('\U00000f88', '\U00000f97'),
+    ('\U00000f99', '\U00000fbc'),
+    ('\U00001000', '\U00001036'),
+    ('\U00001038', '\U00001038'),
+    ('\U0000103b', '\U0000103f'),
+    ('\U00001050', '\U0000108f'),
+    ('\U0000109a', '\U0000109d'),
+    ('\U000010a0', '\U000010c5'),
+    ('\U000010c7', '\U000010c7'),
+    ('\U000010cd', '\U000010cd'),
+    ('\U000010d0', '\U000010fa'),
+    ('\U000010fc', '\U00001248'),
+    ('\U0000124a', '\U0000124d'),
+    ('\U00001250', '\U00001256'),
+    ('\U00001258', '\U00001258'),
+    ('\U0000125a', '\U0000125d'),
+    ('\U00001260', '\U00001288'),
+    ('\U0000128a', '\U0000128d'),
+    ('\U00001290', '\U000012b0'),
+    ('\U000012b2', '\U000012b5'),
+    ('\U000012b8', '\U000012be'),
+    ('\U000012c0', '\U000012c0'),
+    ('\U000012c2', '\U000012c5'),
+    ('\U000012c8', '\U000012d6'),
+    ('\U000012d8', '\U00001310'),
+    ('\U00001312', '\U00001315'),
+    ('\U00001318', '\U0000135a'),
+    ('\U00001380', '\U0000138f'),
+    ('\U000013a0', '\U000013f5'),
+    ('\U000013f8', '\U000013fd'),
+    ('\U00001401', '\U0000166c'),
+    ('\U0000166f', '\U0000167f'),
+    ('\U00001681', '\U0000169a'),
+    ('\U000016a0', '\U000016ea'),
+    ('\U000016ee', '\U000016f8'),
+    ('\U00001700', '\U00001713'),
+    ('\U0000171f', '\U00001733'),
+    ('\U00001740', '\U00001753'),
+    ('\U00001760', '\U0000176c'),
+    ('\U0000176e', '\U00001770'),
+    ('\U00001772', '\U00001773'),
+    ('\U00001780', '\U000017b3'),
+    ('\U000017b6', '\U000017c8'),
+    ('\U000017d7', '\U000017d7'),
+    ('\U000017dc', '\U000017dc'),
+    ('\U00001820', '\U00001878'),
+    ('\U00001880', '\U000018aa'),
+    ('\U000018b0', '\U000018f5'),
+    ('\U00001900', '\U0000191e'),
+    ('\U00001920', '\U0000192b'),
+    ('\U00001930', '\U00001938'),
+    ('\U00001950', '\U0000196d'),
+    ('\U00001970', '\U00001974'),
+    ('\U00001980', '\U000019ab'),
+    ('\U000019b0', '\U000019c9'),
+    ('\U00001a00', '\U00001a1b'),
+    ('\U00001a20', '\U00001a5e'),
+    ('\U00001a61', '\U00001a74'),
+    ('\U00001aa7', '\U00001aa7'),
+    ('\U00001abf', '\U00001ac0'),
+    ('\U00001acc', '\U00001ace'),
+    ('\U00001b00', '\U00001b33'),
+    ('\U00001b35', '\U00001b43'),
+    ('\U00001b45', '\U00001b4c'),
+    ('\U00001b80', '\U00001ba9'),
+    ('\U00001bac', '\U00001baf'),
+    ('\U00001bba', '\U00001be5'),
+    ('\U00001be7', '\U00001bf1'),
+    ('\U00001c00', '\U00001c36'),
+    ('\U00001c4d', '\U00001c4f'),
+    ('\U00001c5a', '\U00001c7d'),
+    ('\U00001c80', '\U00001c88'),
+    ('\U00001c90', '\U00001cba'),
+    ('\U00001cbd', '\U00001cbf'),
+    ('\U00001ce9', '\U00001cec'),
+    ('\U00001cee', '\U00001cf3'),
+    ('\U00001cf5', '\U00001cf6'),
+    ('\U00001cfa', '\U00001cfa'),
+    ('\U00001d00', '\U00001dbf'),
+    ('\U00001de7', '\U00001df4'),
+    ('\U00001e00', '\U00001f15'),
+    ('\U00001f18', '\U00001f1d'),
+    ('\U00001f20', '\U00001f45'),
+    ('\U00001f48', '\U00001f4d'),
+    ('\U00001f50', '\U00001f57'),
+    ('\U00001f59', '\U00001f59'),
+    ('\U00001f5b', '\U00001f5b'),
+    ('\U00001f5d', '\U00001f5d'),
+    ('\U00001f5f', '\U00001f7d'),
+    ('\U00001f80', '\U00001fb4'),
+    ('\U00001fb6', '\U00001fbc'),
+    ('\U00001fbe', '\U00001fbe'),
+    ('\U00001fc2', '\U00001fc4'),
+    ('\U00001fc6', '\U00001fcc'),
+    ('\U00001fd0', '\U00001fd3'),
+    ('\U00001fd6', '\U00001fdb'),
+    ('\U00001fe0', '\U00001fec'),
+    ('\U00001ff2', '\U00001ff4'),
+    ('\U00001ff6', '\U00001ffc'),
+    ('\U00002071', '\U00002071'),
+    ('\U0000207f', '\U0000207f'),
+    ('\U00002090', '\U0000209c'),
+    ('\U00002102', '\U00002102'),
+    ('\U00002107', '\U00002107'),
+    ('\U0000210a', '\U00002113'),
+    ('\U00002115', '\U00002115'),
+    ('\U00002119', '\U0000211d'),
+    ('\U00002124', '\U00002124'),
+    ('\U00002126', '\U00002126'),
+    ('\U00002128', '\U00002128'),
+    ('\U0000212a', '\U0000212d'),
+    ('\U0000212f', '\U00002139'),
+    ('\U0000213c', '\U0000213f'),
+    ('\U00002145', '\U00002149'),
+    ('\U0000214e', '\U0000214e'),
+    ('\U00002160', '\U00002188'),
+    ('\U000024b6', '\U000024e9'),
+    ('\U00002c00', '\U00002ce4'),
+    ('\U00002ceb', '\U00002cee'),
+    ('\U00002cf2', '\U00002cf3'),
+    ('\U00002d00', '\U00002d25'),
+    ('\U00002d27', '\U00002d27'),
+    ('\U00002d2d', '\U00002d2d'),
+    ('\U00002d30', '\U00002d67'),
+    ('\U00002d6f', '\U00002d6f'),
+    ('\U00002d80', '\U00002d96'),
+    ('\U00002da0', '\U00002da6'),
+    ('\U00002da8', '\U00002dae'),
+    ('\U00002db0', '\U00002db6'),
+    ('\U00002db8', '\U00002dbe'),
+    ('\U00002dc0', '\U00002dc6'),
+    ('\U00002dc8', '\U00002dce'),
+    ('\U00002dd0', '\U00002dd6'),
+    ('\U00002dd8', '\U00002dde'),
+    ('\U00002de0', '\U00002dff'),
+    ('\U00002e2f', '\U00002e2f'),
+    ('\U00003005', '\U00003007'),
+    ('\U00003021', '\U00003029'),
+    ('\U00003031', '\U00003035'),
+    ('\U00003038', '\U0000303c'),
+    ('\U00003041', '\U00003096'),
+    ('\U0000309d', '\U0000309f'),
+    ('\U000030a1', '\U000030fa'),
+    ('\U000030fc', '\U000030ff'),
+    ('\U00003105', '\U0000312f'),
+    ('\U00003131', '\U0000318e'),
+    ('\U000031a0', '\U000031bf'),
+    ('\U000031f0', '\U000031ff'),
+    ('\U00003400', '\U00004dbf'),
+    ('\U00004e00', '\U0000a48c'),
+    ('\U0000a4d0', '\U0000a4fd'),
+    ('\U0000a500', '\U0000a60c'),
+    ('\U0000a610', '\U0000a61f'),
+    ('\U0000a62a', '\U0000a62b'),
+    ('\U0000a640', '\U0000a66e'),
+    ('\U0000a674', '\U0000a67b'),
+    ('\U0000a67f', '\U0000a6ef'),
+    ('\U0000a717', '\U0000a71f'),
+    ('\U0000a722', '\U0000a788'),
+    ('\U0000a78b', '\U0000a7ca'),
+    ('\U0000a7d0', '\U0000a7d1'),
+    ('\U0000a7d3', '\U0000a7d3'),
+    ('\U0000a7d5', '\U0000a7d9'),
+    ('\U0000a7f2', '\U0000a805'),
+    ('\U0000a807', '\U0000a827'),
+    ('\U0000a840', '\U0000a873'),
+    ('\U0000a880', '\U0000a8c3'),
+    ('\U0000a8c5', '\U0000a8c5'),
+    ('\U0000a8f2', '\U0000a8f7'),
+    ('\U0000a8fb', '\U0000a8fb'),
+    ('\U0000a8fd', '\U0000a8ff'),
+    ('\U0000a90a', '\U0000a92a'),
+    ('\U0000a930', '\U0000a952'),
+    ('\U0000a960', '\U0000a97c'),
+    ('\U0000a980', '\U0000a9b2'),
+    ('\U0000a9b4', '\U0000a9bf'),
+    ('\U0000a9cf', '\U0000a9cf'),
+    ('\U0000a9e0', '\U0000a9ef'),
+    ('\U0000a9fa', '\U0000a9fe'),
+    ('\U0000aa00', '\U0000aa36'),
+    ('\U0000aa40', '\U0000aa4d'),
+    ('\U0000aa60', '\U0000aa76'),
+    ('\U0000aa7a', '\U0000aabe'),
+    ('\U0000aac0', '\U0000aac0'),
+    ('\U0000aac2', '\U0000aac2'),
+    ('\U0000aadb', '\U0000aadd'),
+    ('\U0000aae0', '\U0000aaef'),
+    ('\U0000aaf2', '\U0000aaf5'),
+    ('\U0000ab01', '\U0000ab06'),
+    ('\U0000ab09', '\U0000ab0e'),
+    ('\U0000ab11', '\U0000ab16'),
+    ('\U0000ab20', '\U0000ab26'),
+    ('\U0000ab28', '\U0000ab2e'),
+    ('\U0000ab30', '\U0000ab5a'),
+    ('\U0000ab5c', '\U0000ab69'),
+    ('\U0000ab70', '\U0000abea'),
+    ('\U0000ac00', '\U0000d7a3'),
+    ('\U0000d7b0', '\U0000d7c6'),
+    ('\U0000d7cb', '\U0000d7fb'),
+    ('\U0000f900', '\U0000fa6d'),
+    ('\U0000fa70', '\U0000fad9'),
+    ('\U0000fb00', '\U0000fb06'),
+    ('\U0000fb13', '\U0000fb17'),
+    ('\U0000fb1d', '\U0000fb28'),
+    ('\U0000fb2a', '\U0000fb36'),
+    ('\U0000fb38', '\U0000fb3c'),
+    ('\U0000fb3e', '\U0000fb3e'),
+    ('\U0000fb40', '\U0000fb41'),
+    ('\U0000fb43', '\U0000fb44'),
+    ('\U0000fb46', '\U0000fbb1'),
+    ('\U0000fbd3', '\U0000fd3d'),
+    ('\U0000fd50', '\U0000fd8f'),
+    ('\U0000fd92', '\U0000fdc7'),
+    ('\U0000fdf0', '\U0000fdfb'),
+    ('\U0000fe70', '\U0000fe74'),
+    ('\U0000fe76', '\U0000fefc'),
+    ('\U0000ff21', '\U0000ff3a'),
+    ('\U0000ff41', '\U0000ff5a'),
+    ('\U0000ff66', '\U0000ffbe'),
+    ('\U0000ffc2', '\U0000ffc7'),
+    ('\U0000ffca', '\U0000ffcf'),
+    ('\U0000ffd2', '\U0000ffd7'),
+    ('\U0000ffda', '\U0000ffdc'),
+    ('\U00010000', '\U0001000b'),
+    ('\U0001000d', '\U00010026'),
+    ('\U00010028', '\U0001003a'),
+    ('\U0001003c', '\U0001003d'),
+    ('\U0001003f', '\U0001004d'),
+    ('\U00010050', '\U0001005d'),
+    ('\U00010080', '\U000100fa'),
+    ('\U00010140', '\U00010174'),
+    ('\U00010280', '\U0001029c'),
+    ('\U000102a0', '\U000102d0'),
+    ('\U00010300', '\U0001031f'),
+    ('\U0001032d', '\U0001034a'),
+    ('\U00010350', '\U0001037a'),
+    ('\U00010380', '\U0001039d'),
+    ('\U000103a0', '\U000103c3'),
+    ('\U000103c8', '\U000103cf'),
+    ('\U000103d1', '\U000103d5'),
+    ('\U00010400', '\U0001049d'),
+    ('\U000104b0', '\U000104d3'),
+    ('\U000104d8', '\U000104fb'),
+    ('\U00010500', '\U00010527'),
+    ('\U00010530', '\U00010563'),
+    ('\U00010570', '\U0001057a'),
+    ('\U0001057c', '\U0001058a'),
+    ('\U0001058c', '\U00010592'),
+    ('\U00010594', '\U00010595'),
+    ('\U00010597', '\U000105a1'),
+    ('\U000105a3', '\U000105b1'),
+    ('\U000105b3', '\U000105b9'),
+    ('\U000105bb', '\U000105bc'),
+    ('\U00010600', '\U00010736'),
+    ('\U00010740', '\U00010755'),
+    ('\U00010760', '\U00010767'),
+    ('\U00010780', '\U00010785'),
+    ('\U00010787', '\U000107b0'),
+    ('\U000107b2', '\U000107ba'),
+    ('\U00010800', '\U00010805'),
+    ('\U00010808', '\U00010808'),
+    ('\U0001080a', '\U00010835'),
+    ('\U00010837', '\U00010838'),
+    ('\U0001083c', '\U0001083c'),
+    ('\U0001083f', '\U00010855'),
+    ('\U00010860', '\U00010876'),
+    ('\U00010880', '\U0001089e'),
+    ('\U000108e0', '\U000108f2'),
+    ('\U000108f4', '\U000108f5'),
+    ('\U00010900', '\U00010915'),
+    ('\U00010920', '\U00010939'),
+    ('\U00010980', '\U000109b7'),
+    ('\U000109be', '\U000109bf'),
+    ('\U00010a00', '\U00010a03'),
+    ('\U00010a05', '\U00010a06'),
+    ('\U00010a0c', '\U00010a13'),
+    ('\U00010a15', '\U00010a17'),
+    ('\U00010a19', '\U00010a35'),
+    ('\U00010a60', '\U00010a7c'),
+    ('\U00010a80', '\U00010a9c'),
+    ('\U00010ac0', '\U00010ac7'),
+    ('\U00010ac9', '\U00010ae4'),
+    ('\U00010b00', '\U00010b35'),
+    ('\U00010b40', '\U00010b55'),
+    ('\U00010b60', '\U00010b72'),
+    ('\U00010b80', '\U00010b91'),
+    ('\U00010c00', '\U00010c48'),
+    ('\U00010c80', '\U00010cb2'),
+    ('\U00010cc0', '\U00010cf2'),
+    ('\U00010d00', '\U00010d27'),
+    ('\U00010e80', '\U00010ea9'),
+    ('\U00010eab', '\U00010eac'),
+    ('\U00010eb0', '\U00010eb1'),
+    ('\U00010f00', '\U00010f1c'),
+    ('\U00010f27', '\U00010f27'),
+    ('\U00010f30', '\U00010f45'),
+    ('\U00010f70', '\U00010f81'),
+    ('\U00010fb0', '\U00010fc4'),
+    ('\U00010fe0', '\U00010ff6'),
+    ('\U00011000', '\U00011045'),
+    ('\U00011071', '\U00011075'),
+    ('\U00011082', '\U000110b8'),
+    ('\U000110c2', '\U000110c2'),
+    ('\U000110d0', '\U000110e8'),
+    ('\U00011100', '\U00011132'),
+    ('\U00011144', '\U00011147'),
+    ('\U00011150', '\U00011172'),
+    ('\U00011176', '\U00011176'),
+    ('\U00011180', '\U000111bf'),
+    ('\U000111c1', '\U000111c4'),
+    ('\U000111ce', '\U000111cf'),
+    ('\U000111da', '\U000111da'),
+    ('\U000111dc', '\U000111dc'),
+    ('\U00011200', '\U00011211'),
+    ('\U00011213', '\U00011234'),
+    ('\U00011237', '\U00011237'),
+    ('\U0001123e', '\U0001123e'),
+    ('\U00011280', '\U00011286'),
+    ('\U00011288', '\U00011288'),
+    ('\U0001128a', '\U0001128d'),
+    ('\U0001128f', '\U0001129d'),
+    ('\U0001129f', '\U000112a8'),
+    ('\U000112b0', '\U000112e8'),
+    ('\U00011300', '\U00011303'),
+    ('\U00011305', '\U0001130c'),
+    ('\U0001130f', '\U00011310'),
+    ('\U00011313', '\U00011328'),
+    ('\U0001132a', '\U00011330'),
+    ('\U00011332', '\U00011333'),
+    ('\U00011335', '\U00011339'),
+    ('\U0001133d', '\U00011344'),
+    ('\U00011347', '\U00011348'),
+    ('\U0001134b', '\U0001134c'),
+    ('\U00011350', '\U00011350'),
+    ('\U00011357', '\U00011357'),
+    ('\U0001135d', '\U00011363'),
+    ('\U00011400', '\U00011441'),
+    ('\U00011443', '\U00011445'),
+    ('\U00011447', '\U0001144a'),
+    ('\U0001145f', '\U00011461'),
+    ('\U00011480', '\U000114c1'),
+    ('\U000114c4', '\U000114c5'),
+    ('\U000114c7', '\U000114c7'),
+    ('\U00011580', '\U000115b5'),
+    ('\U000115b8', '\U000115be'),
+    ('\U000115d8', '\U000115dd'),
+    ('\U00011600', '\U0001163e'),
+    ('\U00011640', '\U00011640'),
+    ('\U00011644', '\U00011644'),
+    ('\U00011680', '\U000116b5'),
+    ('\U000116b8', '\U000116b8'),
+    ('\U00011700', '\U0001171a'),
+    ('\U0001171d', '\U0001172a'),
+    ('\U00011740', '\U00011746'),
+    ('\U00011800', '\U00011838'),
+    ('\U000118a0', '\U000118df'),
+    ('\U000118ff', '\U00011906'),
+    ('\U00011909', '\U00011909'),
+    ('\U0001190c', '\U00011913'),
+    ('\U00011915', '\U00011916'),
+    ('\U00011918', '\U00011935'),
+    ('\U00011937', '\U00011938'),
+    ('\U0001193b', '\U0001193c'),
+    ('\U0001193f', '\U00011942'),
+    ('\U000119a0', '\U000119a7'),
+    ('\U000119aa', '\U000119d7'),
+    ('\U000119da', '\U000119df'),
+    ('\U000119e1', '\U000119e1'),
+    ('\U000119e3', '\U000119e4'),
+    ('\U00011a00', '\U00011a32'),
+    ('\U00011a35', '\U00011a3e'),
+    ('\U00011a50', '\U00011a97'),
+    ('\U00011a9d', '\U00011a9d'),
+    ('\U00011ab0', '\U00011af8'),
+    ('\U00011c00', '\U00011c08'),
+    ('\U00011c0a', '\U00011c36'),
+    ('\U00011c38', '\U00011c3e'),
+    ('\U00011c40', '\U00011c40'),
+    ('\U00011c72', '\U00011c8f'),
+    ('\U00011c92', '\U00011ca7'),
+    ('\U00011ca9', '\U00011cb6'),
+    ('\U00011d00', '\U00011d06'),
+    ('\U00011d08', '\U00011d09'),
+    ('\U00011d0b', '\U00011d36'),
+    ('\U00011d3a', '\U00011d3a'),
+    ('\U00011d3c', '\U00011d3d'),
+    ('\U00011d3f', '\U00011d41'),
+    ('\U00011d43', '\U00011d43'),
+    ('\U00011d46', '\U00011d47'),
+    ('\U00011d60', '\U00011d65'),
+    ('\U00011d67', '\U00011d68'),
+    ('\U00011d6a', '\U00011d8e'),
+    ('\U00011d90', '\U00011d91'),
+    ('\U00011d93', '\U00011d96'),
+    ('\U00011d98', '\U00011d98'),
+    ('\U00011ee0', '\U00011ef6'),
+    ('\U00011fb0', '\U00011fb0'),
+    ('\U00012000', '\U00012399'),
+    ('\U00012400', '\U0001246e'),
+    ('\U00012480', '\U00012543'),
+    ('\U00012f90', '\U00012ff0'),
+    ('\U00013000', '\U0001342e'),
+    ('\U00014400', '\U00014646'),
+    ('\U00016800', '\U00016a38'),
+    ('\U00016a40', '\U00016a5e'),
+    ('\U00016a70', '\U00016abe'),
+    ('\U00016ad0', '\U00016aed'),
+    ('\U00016b00', '\U00016b2f'),
+    ('\U00016b40', '\U00016b43'),
+    ('\U00016b63', '\U00016b77'),
+    ('\U00016b7d', '\U00016b8f'),
+    ('\U00016e40', '\U00016e7f'),
+    ('\U00016f00', '\U00016f4a'),
+    ('\U00016f4f', '\U00016f87'),
+    ('\U00016f8f', '\U00016f9f'),
+    ('\U00016fe0', '\U00016fe1'),
+    ('\U00016fe3', '\U00016fe3'),
+    ('\U00016ff0', '\U00016ff1'),
+    ('\U00017000', '\U000187f7'),
+    ('\U00018800', '\U00018cd5'),
+    ('\U00018d00', '\U00018d08'),
+    ('\U0001aff0', '\U0001aff3'),
+    ('\U0001aff5', '\U0001affb'),
+    ('\U0001affd', '\U0001affe'),
+    ('\U0001b000', '\U0001b122'),
+    ('\U0001b150', '\U0001b152'),
+    ('\U0001b164', '\U0001b167'),
+    ('\U0001b170', '\U0001b2fb'),
+    ('\U0001bc00', '\U0001bc6a'),
+    ('\U0001bc70', '\U0001bc7c'),
+    ('\U0001bc80', '\U0001bc88'),
+    ('\U0001bc90', '\U0001bc99'),
+    ('\U0001bc9e', '\U0001bc9e'),
+    ('\U0001d400', '\U0001d454'),
+    ('\U0001d456', '\U0001d49c'),
+    ('\U0001d49e', '\U0001d49f'),
+    ('\U0001d4a2', '\U0001d4a2'),
+    ('\U0001d4a5', '\U0001d4a6'),
+    ('\U0001d4a9', '\U0001d4ac'),
+    ('\U0001d4ae', '\U0001d4b9'),
+    ('\U0001d4bb', '\U0001d4bb'),
+    ('\U0001d4bd', '\U0001d4c3'),
+    ('\U0001d4c5', '\U0001d505'),
+    ('\U0001d507', '\U0001d50a'),
+    ('\U0001d50d', '\U0001d514'),
+    ('\U0001d516', '\U0001d51c'),
+    ('\U0001d51e', '\U0001d539'),
+    ('\U0001d53b', '\U0001d53e'),
+    ('\U0001d540', '\U0001d544'),
+    ('\U0001d546', '\U0001d546'),
+    ('\U0001d54a', '\U0001d550'),
+    ('\U0001d552', '\U0001d6a5'),
+    ('\U0001d6a8', '\U0001d6c0'),
+    ('\U0001d6c2', '\U0001d6da'),
+    ('\U0001d6dc', '\U0001d6fa'),
+    ('\U0001d6fc', '\U0001d714'),
+    ('\U0001d716', '\U0001d734'),
+    ('\U0001d736', '\U0001d74e'),
+    ('\U0001d750', '\U0001d76e'),
+    ('\U0001d770', '\U0001d788'),
+    ('\U0001d78a', '\U0001d7a8'),
+    ('\U0001d7aa', '\U0001d7c2'),
+    ('\U0001d7c4', '\U0001d7cb'),
+    ('\U0001df00', '\U0001df1e'),
+    ('\U0001e000', '\U0001e006'),
+    ('\U0001e008', '\U0001e018'),
+    ('\U0001e01b', '\U0001e021'),
+    ('\U0001e023', '\U0001e024'),
+    ('\U0001e026', '\U0001e02a'),
+    ('\U0001e100', '\U0001e12c'),
+    ('\U0001e137', '\U0001e13d'),
+    ('\U0001e14e', '\U0001e14e'),
+    ('\U0001e290', '\U0001e2ad'),
+    ('\U0001e2c0', '\U0001e2eb'),
+    ('\U0001e7e0', '\U0001e7e6'),
+    ('\U0001e7e8', '\U0001e7eb'),
+    ('\U0001e7ed', '\U0001e7ee'),
+    ('\U0001e7f0', '\U0001e7fe'),
+    ('\U0001e800', '\U0001e8c4'),
+    ('\U0001e900', '\U0001e943'),
+    ('\U0001e947', '\U0001e947'),
+    ('\U0001e94b', '\U0001e94b'),
+    ('\U0001ee00', '\U0001ee03'),
+    ('\U0001ee05', '\U0001ee1f'),
+    ('\U0001ee21', '\U0001ee22'),
+    ('\U0001ee24', '\U0001ee24'),
+    ('\U0001ee27', '\U0001ee27'),
+    ('\U0001ee29', '\U0001ee32'),
+    ('\U0001ee34', '\U0001ee37'),
+    ('\U0001ee39', '\U0001ee39'),
+    ('\U0001ee3b', '\U0001ee3b'),
+    ('\U0001ee42', '\U0001ee42'),
+    ('\U0001ee47', '\U0001ee47'),
+    ('\U0001ee49', '\U0001ee49'),
+    ('\U0001ee4b', '\U0001ee4b'),
+    ('\U0001ee4d', '\U0001ee4f'),
+    ('\U0001ee51', '\U0001ee52'),
+    ('\U0001ee54', '\U0001ee54'),
+    ('\U0001ee57', '\U0001ee57'),
+    ('\U0001ee59', '\U0001ee59'),
+    ('\U0001ee5b', '\U0001ee5b'),
+    ('\U0001ee5d', '\U0001ee5d'),
+    ('\U0001ee5f', '\U0001ee5f'),
+    ('\U0001ee61', '\U0001ee62'),
+    ('\U0001ee64', '\U0001ee64'),
+    ('\U0001ee67', '\U0001ee6a'),
+    ('\U0001ee6c', '\U0001ee72'),
+    ('\U0001ee74', '\U0001ee77'),
+    ('\U0001ee79', '\U0001ee7c'),
+    ('\U0001ee7e', '\U0001ee7e'),
+    ('\U0001ee80', '\U0001ee89'),
+    ('\U0001ee8b', '\U0001ee9b'),
+    ('\U0001eea1', '\U0001eea3'),
+    ('\U0001eea5', '\U0001eea9'),
+    ('\U0001eeab', '\U0001eebb'),
+    ('\U0001f130', '\U0001f149'),
+    ('\U0001f150', '\U0001f169'),
+    ('\U0001f170', '\U0001f189'),
+    ('\U00020000', '\U0002a6df'),
+    ('\U0002a700', '\U0002b738'),
+    ('\U0002b740', '\U0002b81d'),
+    ('\U0002b820', '\U0002cea1'),
+    ('\U0002ceb0', '\U0002ebe0'),
+    ('\U0002f800', '\U0002fa1d'),
+    ('\U00030000', '\U0003134a')
+    ]),
+
+("Assigned", &[
+    ('\U00000000', '\U00000377'),
+    ('\U0000037a', '\U0000037f'),
+    ('\U00000384', '\U0000038a'),
+    ('\U0000038c', '\U0000038c'),
+    ('\U0000038e', '\U000003a1'),
+    ('\U000003a3', '\U0000052f'),
+    ('\U00000531', '\U00000556'),
+    ('\U00000559', '\U0000058a'),
+    ('\U0000058d', '\U0000058f'),
+    ('\U00000591', '\U000005c7'),
+    ('\U000005d0', '\U000005ea'),
+    ('\U000005ef', '\U000005f4'),
+    ('\U00000600', '\U0000070d'),
+    ('\U0000070f', '\U0000074a'),
+    ('\U0000074d', '\U000007b1'),
+    ('\U000007c0', '\U000007fa'),
+    ('\U000007fd', '\U0000082d'),
+    ('\U00000830', '\U0000083e'),
+    ('\U00000840', '\U0000085b'),
+    ('\U0000085e', '\U0000085e'),
+    ('\U00000860', '\U0000086a'),
+    ('\U00000870', '\U0000088e'),
+    ('\U00000890', '\U00000891'),
+    ('\U00000898', '\U00000983'),
+    ('\U00000985', '\U0000098c'),
+    ('\U0000098f', '\U00000990'),
+    ('\U00000993', '\U000009a8'),
+    ('\U000009aa', '\U000009b0'),
+    ('\U000009b2', '\U000009b2'),
+    ('\U000009b6', '\U000009b9'),
+    ('\U000009bc', '\U000009c4'),
+    ('\U000009c7', '\U000009c8'),
+    ('\U000009cb', '\U000009ce'),
+    ('\U000009d7', '\U000009d7'),
+    ('\U000009dc', '\U000009dd'),
+    ('\U000009df', '\U000009e3'),
+    ('\U000009e6', '\U000009fe'),
+    ('\U00000a01', '\U00000a03'),
+    ('\U00000a05', '\U00000a0a'),
+    ('\U00000a0f', '\U00000a10'),
+    ('\U00000a13', '\U00000a28'),
+    ('\U00000a2a', '\U00000a30'),
+    ('\U00000a32', '\U00000a33'),
+    ('\U00000a35', '\U00000a36'),
+    ('\U00000a38', '\U00000a39'),
+    ('\U00000a3c', '\U00000a3c'),
+    ('\U00000a3e', '\U00000a42'),
+    ('\U00000a47', '\U00000a48'),
+    ('\U00000a4b', '\U00000a4d'),
+    ('\U00000a51', '\U00000a51'),
+    ('\U00000a59', '\U00000a5c'),
+    ('\U00000a5e', '\U00000a5e'),
+    ('\U00000a66', '\U00000a76'),
+    ('\U00000a81', '\U00000a83'),
+    ('\U00000a85', '\U00000a8d'),
+    ('\U00000a8f', '\U00000a91'),
+    ('\U00000a93', '\U00000aa8'),
+    ('\U00000aaa', '\U00000ab0'),
+    ('\U00000ab2', '\U00000ab3'),
+    ('\U00000ab5', '\U00000ab9'),
+    ('\U00000abc', '\U00000ac5'),
+    ('\U00000ac7', '\U00000ac9'),
+    ('\U00000acb', '\U00000acd'),
+    ('\U00000ad0', '\U00000ad0'),
+    ('\U00000ae0', '\U00000ae3'),
+    ('\U00000ae6', '\U00000af1'),
+    ('\U00000af9', '\U00000aff'),
+    ('\U00000b01', '\U00000b03'),
+    ('\U00000b05', '\U00000b0c'),
+    ('\U00000b0f', '\U00000b10'),
+    ('\U00000b13', '\U00000b28'),
+    ('\U00000b2a', '\U00000b30'),
+    ('\U00000b32', '\U00000b33'),
+    ('\U00000b35', '\U00000b39'),
+    ('\U00000b3c', '\U00000b44'),
+    ('\U00000b47', '\U00000b48'),
+    ('\U00000b4b', '\U00000b4d'),
+    ('\U00000b55', '\U00000b57'),
+    ('\U00000b5c', '\U00000b5d'),
+    ('\U00000b5f', '\U00000b63'),
+    ('\U00000b66', '\U00000b77'),
+    ('\U00000b82', '\U00000b83'),
+    ('\U00000b85', '\U00000b8a'),
+    ('\U00000b8e', '\U00000b90'),
+    ('\U00000b92', '\U00000b95'),
+    ('\U00000b99', '\U00000b9a'),
+    ('\U00000b9c', '\U00000b9c'),
+    ('\U00000b9e', '\U00000b9f'),
+    ('\U00000ba3', '\U00000ba4'),
+    ('\U00000ba8', '\U00000baa'),
+    ('\U00000bae', '\U00000bb9'),
+    ('\U00000bbe', '\U00000bc2'),
+    ('\U00000bc6', '\U00000bc8'),
+    ('\U00000bca', '\U00000bcd'),
+    ('\U00000bd0', '\U00000bd0'),
+    ('\U00000bd7', '\U00000bd7'),
+    ('\U00000be6', '\U00000bfa'),
+    ('\U00000c00', '\U00000c0c'),
+    ('\U00000c0e', '\U00000c10'),
+    ('\U00000c12', '\U00000c28'),
+    ('\U00000c2a', '\U00000c39'),
+    ('\U00000c3c', '\U00000c44'),
+    ('\U00000c46', '\U00000c48'),
+    ('\U00000c4a', '\U00000c4d'),
+    ('\U00000c55', '\U00000c56'),
+    ('\U00000c58', '\U00000c5a'),
+    ('\U00000c5d', '\U00000c5d'),
+    ('\U00000c60', '\U00000c63'),
+    ('\U00000c66', '\U00000c6f'),
+    ('\U00000c77', '\U00000c8c'),
+    ('\U00000c8e', '\U00000c90'),
+    ('\U00000c92', '\U00000ca8'),
+    ('\U00000caa', '\U00000cb3'),
+    ('\U00000cb5', '\U00000cb9'),
+    ('\U00000cbc', '\U00000cc4'),
+    ('\U00000cc6', '\U00000cc8'),
+    ('\U00000cca', '\U00000ccd'),
+    ('\U00000cd5', '\U00000cd6'),
+    ('\U00000cdd', '\U00000cde'),
+    ('\U00000ce0', '\U00000ce3'),
+    ('\U00000ce6', '\U00000cef'),
+    ('\U00000cf1', '\U00000cf2'),
+    ('\U00000d00', '\U00000d0c'),
+    ('\U00000d0e', '\U00000d10'),
+    ('\U00000d12', '\U00000d44'),
+    ('\U00000d46', '\U00000d48'),
+    ('\U00000d4a', '\U00000d4f'),
+    ('\U00000d54', '\U00000d63'),
+    ('\U00000d66', '\U00000d7f'),
+    ('\U00000d81', '\U00000d83'),
+    ('\U00000d85', '\U00000d96'),
+    ('\U00000d9a', '\U00000db1'),
+    ('\U00000db3', '\U00000dbb'),
+    ('\U00000dbd', '\U00000dbd'),
+    ('\U00000dc0', '\U00000dc6'),
+    ('\U00000dca', '\U00000dca'),
+    ('\U00000dcf', '\U00000dd4'),
+    ('\U00000dd6', '\U00000dd6'),
+    ('\U00000dd8', '\U00000ddf'),
+    ('\U00000de6', '\U00000def'),
+    ('\U00000df2', '\U00000df4'),
+    ('\U00000e01', '\U00000e3a'),
+    ('\U00000e3f', '\U00000e5b'),
+    ('\U00000e81', '\U00000e82'),
+    ('\U00000e84', '\U00000e84'),
+    ('\U00000e86', '\U00000e8a'),
+    ('\U00000e8c', '\U00000ea3'),
+    ('\U00000ea5', '\U00000ea5'),
+    ('\U00000ea7', '\U00000ebd'),
+    ('\U00000ec0', '\U00000ec4'),
+    ('\U00000ec6', '\U00000ec6'),
+    ('\U00000ec8', '\U00000ecd'),
+    ('\U00000ed0', '\U00000ed9'),
+    ('\U00000edc', '\U00000edf'),
+    ('\U00000f00', '\U00000f47'),
+    ('\U00000f49', '\U00000f6c'),
+    ('\U00000f71', '\U00000f97'),
+    ('\U00000f99', '\U00000fbc'),
+    ('\U00000fbe', '\U00000fcc'),
+    ('\U00000fce', '\U00000fda'),
+    ('\U00001000', '\U000010c5'),
+    ('\U000010c7', '\U000010c7'),
+    ('\U000010cd', '\U000010cd'),
+    ('\U000010d0', '\U00001248'),
+    ('\U0000124a', '\U0000124d'),
+    ('\U00001250', '\U00001256'),
+    ('\U00001258', '\U00001258'),
+    ('\U0000125a', '\U0000125d'),
+    ('\U00001260', '\U00001288'),
+    ('\U0000128a', '\U0000128d'),
+    ('\U00001290', '\U000012b0'),
+    ('\U000012b2', '\U000012b5'),
+    ('\U000012b8', '\U000012be'),
+    ('\U000012c0', '\U000012c0'),
+    ('\U000012c2', '\U000012c5'),
+    ('\U000012c8', '\U000012d6'),
+    ('\U000012d8', '\U00001310'),
+    ('\U00001312', '\U00001315'),
+    ('\U00001318', '\U0000135a'),
+    ('\U0000135d', '\U0000137c'),
+    ('\U00001380', '\U00001399'),
+    ('\U000013a0', '\U000013f5'),
+    ('\U000013f8', '\U000013fd'),
+    ('\U00001400', '\U0000169c'),
+    ('\U000016a0', '\U000016f8'),
+    ('\U00001700', '\U00001715'),
+    ('\U0000171f', '\U00001736'),
+    ('\U00001740', '\U00001753'),
+    ('\U00001760', '\U0000176c'),
+    ('\U0000176e', '\U00001770'),
+    ('\U00001772', '\U00001773'),
+    ('\U00001780', '\U000017dd'),
+    ('\U000017e0', '\U000017e9'),
+    ('\U000017f0', '\U000017f9'),
+    ('\U00001800', '\U00001819'),
+    ('\U00001820', '\U00001878'),
+    ('\U00001880', '\U000018aa'),
+    ('\U000018b0', '\U000018f5'),
+    ('\U00001900', '\U0000191e'),
+    ('\U00001920', '\U0000192b'),
+    ('\U00001930', '\U0000193b'),
+    ('\U00001940', '\U00001940'),
+    ('\U00001944', '\U0000196d'),
+    ('\U00001970', '\U00001974'),
+    ('\U00001980', '\U000019ab'),
+    ('\U000019b0', '\U000019c9'),
+    ('\U000019d0', '\U000019da'),
+    ('\U000019de', '\U00001a1b'),
+    ('\U00001a1e', '\U00001a5e'),
+    ('\U00001a60', '\U00001a7c'),
+    ('\U00001a7f', '\U00001a89'),
+    ('\U00001a90', '\U00001a99'),
+    ('\U00001aa0', '\U00001aad'),
+    ('\U00001ab0', '\U00001ace'),
+    ('\U00001b00', '\U00001b4c'),
+    ('\U00001b50', '\U00001b7e'),
+    ('\U00001b80', '\U00001bf3'),
+    ('\U00001bfc', '\U00001c37'),
+    ('\U00001c3b', '\U00001c49'),
+    ('\U00001c4d', '\U00001c88'),
+    ('\U00001c90', '\U00001cba'),
+    ('\U00001cbd', '\U00001cc7'),
+    ('\U00001cd0', '\U00001cfa'),
+    ('\U00001d00', '\U00001f15'),
+    ('\U00001f18', '\U00001f1d'),
+    ('\U00001f20', '\U00001f45'),
+    ('\U00001f48', '\U00001f4d'),
+    ('\U00001f50', '\U00001f57'),
+    ('\U00001f59', '\U00001f59'),
+    ('\U00001f5b', '\U00001f5b'),
+    ('\U00001f5d', '\U00001f5d'),
+    ('\U00001f5f', '\U00001f7d'),
+    ('\U00001f80', '\U00001fb4'),
+    ('\U00001fb6', '\U00001fc4'),
+    ('\U00001fc6', '\U00001fd3'),
+    ('\U00001fd6', '\U00001fdb'),
+    ('\U00001fdd', '\U00001fef'),
+    ('\U00001ff2', '\U00001ff4'),
+    ('\U00001ff6', '\U00001ffe'),
+    ('\U00002000', '\U00002064'),
+    ('\U00002066', '\U00002071'),
+    ('\U00002074', '\U0000208e'),
+    ('\U00002090', '\U0000209c'),
+    ('\U000020a0', '\U000020c0'),
+    ('\U000020d0', '\U000020f0'),
+    ('\U00002100', '\U0000218b'),
+    ('\U00002190', '\U00002426'),
+    ('\U00002440', '\U0000244a'),
+    ('\U00002460', '\U00002b73'),
+    ('\U00002b76', '\U00002b95'),
+    ('\U00002b97', '\U00002cf3'),
+    ('\U00002cf9', '\U00002d25'),
+    ('\U00002d27', '\U00002d27'),
+    ('\U00002d2d', '\U00002d2d'),
+    ('\U00002d30', '\U00002d67'),
+    ('\U00002d6f', '\U00002d70'),
+    ('\U00002d7f', '\U00002d96'),
+    ('\U00002da0', '\U00002da6'),
+    ('\U00002da8', '\U00002dae'),
+    ('\U00002db0', '\U00002db6'),
+    ('\U00002db8', '\U00002dbe'),
+    ('\U00002dc0', '\U00002dc6'),
+    ('\U00002dc8', '\U00002dce'),
+    ('\U00002dd0', '\U00002dd6'),
+    ('\U00002dd8', '\U00002dde'),
+    ('\U00002de0', '\U00002e5d'),
+    ('\U00002e80', '\U00002e99'),
+    ('\U00002e9b', '\U00002ef3'),
+    ('\U00002f00', '\U00002fd5'),
+    ('\U00002ff0', '\U00002ffb'),
+    ('\U00003000', '\U0000303f'),
+    ('\U00003041', '\U00003096'),
+    ('\U00003099', '\U000030ff'),
+    ('\U00003105', '\U0000312f'),
+    ('\U00003131', '\U0000318e'),
+    ('\U00003190', '\U000031e3'),
+    ('\U000031f0', '\U0000321e'),
+    ('\U00003220', '\U0000a48c'),
+    ('\U0000a490', '\U0000a4c6'),
+    ('\U0000a4d0', '\U0000a62b'),
+    ('\U0000a640', '\U0000a6f7'),
+    ('\U0000a700', '\U0000a7ca'),
+    ('\U0000a7d0', '\U0000a7d1'),
+    ('\U0000a7d3', '\U0000a7d3'),
+    ('\U0000a7d5', '\U0000a7d9'),
+    ('\U0000a7f2', '\U0000a82c'),
+    ('\U0000a830', '\U0000a839'),
+    ('\U0000a840', '\U0000a877'),
+    ('\U0000a880', '\U0000a8c5'),
+    ('\U0000a8ce', '\U0000a8d9'),
+    ('\U0000a8e0', '\U0000a953'),
+    ('\U0000a95f', '\U0000a97c'),
+    ('\U0000a980', '\U0000a9cd'),
+    ('\U0000a9cf', '\U0000a9d9'),
+    ('\U0000a9de', '\U0000a9fe'),
+    ('\U0000aa00', '\U0000aa36'),
+    ('\U0000aa40', '\U0000aa4d'),
+    ('\U0000aa50', '\U0000aa59'),
+    ('\U0000aa5c', '\U0000aac2'),
+    ('\U0000aadb', '\U0000aaf6'),
+    ('\U0000ab01', '\U0000ab06'),
+    ('\U0000ab09', '\U0000ab0e'),
+    ('\U0000ab11', '\U0000ab16'),
+    ('\U0000ab20', '\U0000ab26'),
+    ('\U0000ab28', '\U0000ab2e'),
+    ('\U0000ab30', '\U0000ab6b'),
+    ('\U0000ab70', '\U0000abed'),
+    ('\U0000abf0', '\U0000abf9'),
+    ('\U0000ac00', '\U0000d7a3'),
+    ('\U0000d7b0', '\U0000d7c6'),
+    ('\U0000d7cb', '\U0000d7fb'),
+    ('\U0000d800', '\U0000fa6d'),
+    ('\U0000fa70', '\U0000fad9'),
+    ('\U0000fb00', '\U0000fb06'),
+    ('\U0000fb13', '\U0000fb17'),
+    ('\U0000fb1d', '\U0000fb36'),
+    ('\U0000fb38', '\U0000fb3c'),
+    ('\U0000fb3e', '\U0000fb3e'),
+    ('\U0000fb40', '\U0000fb41'),
+    ('\U0000fb43', '\U0000fb44'),
+    ('\U0000fb46', '\U0000fbc2'),
+    ('\U0000fbd3', '\U0000fd8f'),
+    ('\U0000fd92', '\U0000fdc7'),
+    ('\U0000fdcf', '\U0000fdcf'),
+    ('\U0000fdf0', '\U0000fe19'),
+    ('\U0000fe20', '\U0000fe52'),
+    ('\U0000fe54', '\U0000fe66'),
+    ('\U0000fe68', '\U0000fe6b'),
+    ('\U0000fe70', '\U0000fe74'),
+    ('\U0000fe76', '\U0000fefc'),
+    ('\U0000feff', '\U0000feff'),
+    ('\U0000ff01', '\U0000ffbe'),
+    ('\U0000ffc2', '\U0000ffc7'),
+    ('\U0000ffca', '\U0000ffcf'),
+    ('\U0000ffd2', '\U0000ffd7'),
+    ('\U0000ffda', '\U0000ffdc'),
+    ('\U0000ffe0', '\U0000ffe6'),
+    ('\U0000ffe8', '\U0000ffee'),
+    ('\U0000fff9', '\U0000fffd'),
+    ('\U00010000', '\U0001000b'),
+    ('\U0001000d', '\U00010026'),
+    ('\U00010028', '\U0001003a'),
+    ('\U0001003c', '\U0001003d'),
+    ('\U0001003f', '\U0001004d'),
+    ('\U00010050', '\U0001005d'),
+    ('\U00010080', '\U000100fa'),
+    ('\U00010100', '\U00010102'),
+    ('\U00010107', '\U00010133'),
+    ('\U00010137', '\U0001018e'),
+    ('\U00010190', '\U0001019c'),
+    ('\U000101a0', '\U000101a0'),
+    ('\U000101d0', '\U000101fd'),
+    ('\U00010280', '\U0001029c'),
+    ('\U000102a0', '\U000102d0'),
+    ('\U000102e0', '\U000102fb'),
+    ('\U00010300', '\U00010323'),
+    ('\U0001032d', '\U0001034a'),
+    ('\U00010350', '\U0001037a'),
+    ('\U00010380', '\U0001039d'),
+    ('\U0001039f', '\U000103c3'),
+    ('\U000103c8', '\U000103d5'),
+    ('\U00010400', '\U0001049d'),
+    ('\U000104a0', '\U000104a9'),
+    ('\U000104b0', '\U000104d3'),
+    ('\U000104d8', '\U000104fb'),
+    ('\U00010500', '\U00010527'),
+    ('\U00010530', '\U00010563'),
+    ('\U0001056f', '\U0001057a'),
+    ('\U0001057c', '\U0001058a'),
+    ('\U0001058c', '\U00010592'),
+    ('\U00010594', '\U00010595'),
+    ('\U00010597', '\U000105a1'),
+    ('\U000105a3', '\U000105b1'),
+    ('\U000105b3', '\U000105b9'),
+    ('\U000105bb', '\U000105bc'),
+    ('\U00010600', '\U00010736'),
+    ('\U00010740', '\U00010755'),
+    ('\U00010760', '\U00010767'),
+    ('\U00010780', '\U00010785'),
+    ('\U00010787', '\U000107b0'),
+    ('\U000107b2', '\U000107ba'),
+    ('\U00010800', '\U00010805'),
+    ('\U00010808', '\U00010808'),
+    ('\U0001080a', '\U00010835'),
+    ('\U00010837', '\U00010838'),
+    ('\U0001083c', '\U0001083c'),
+    ('\U0001083f', '\U00010855'),
+    ('\U00010857', '\U0001089e'),
+    ('\U000108a7', '\U000108af'),
+    ('\U000108e0', '\U000108f2'),
+    ('\U000108f4', '\U000108f5'),
+    ('\U000108fb', '\U0001091b'),
+    ('\U0001091f', '\U00010939'),
+    ('\U0001093f', '\U0001093f'),
+    ('\U00010980', '\U000109b7'),
+    ('\U000109bc', '\U000109cf'),
+    ('\U000109d2', '\U00010a03'),
+    ('\U00010a05', '\U00010a06'),
+    ('\U00010a0c', '\U00010a13'),
+    ('\U00010a15', '\U00010a17'),
+    ('\U00010a19', '\U00010a35'),
+    ('\U00010a38', '\U00010a3a'),
+    ('\U00010a3f', '\U00010a48'),
+    ('\U00010a50', '\U00010a58'),
+    ('\U00010a60', '\U00010a9f'),
+    ('\U00010ac0', '\U00010ae6'),
+    ('\U00010aeb', '\U00010af6'),
+    ('\U00010b00', '\U00010b35'),
+    ('\U00010b39', '\U00010b55'),
+    ('\U00010b58', '\U00010b72'),
+    ('\U00010b78', '\U00010b91'),
+    ('\U00010b99', '\U00010b9c'),
+    ('\U00010ba9', '\U00010baf'),
+    ('\U00010c00', '\U00010c48'),
+    ('\U00010c80', '\U00010cb2'),
+    ('\U00010cc0', '\U00010cf2'),
+    ('\U00010cfa', '\U00010d27'),
+    ('\U00010d30', '\U00010d39'),
+    ('\U00010e60', '\U00010e7e'),
+    ('\U00010e80', '\U00010ea9'),
+    ('\U00010eab', '\U00010ead'),
+    ('\U00010eb0', '\U00010eb1'),
+    ('\U00010f00', '\U00010f27'),
+    ('\U00010f30', '\U00010f59'),
+    ('\U00010f70', '\U00010f89'),
+    ('\U00010fb0', '\U00010fcb'),
+    ('\U00010fe0', '\U00010ff6'),
+    ('\U00011000', '\U0001104d'),
+    ('\U00011052', '\U00011075'),
+    ('\U0001107f', '\U000110c2'),
+    ('\U000110cd', '\U000110cd'),
+    ('\U000110d0', '\U000110e8'),
+    ('\U000110f0', '\U000110f9'),
+    ('\U00011100', '\U00011134'),
+    ('\U00011136', '\U00011147'),
+    ('\U00011150', '\U00011176'),
+    ('\U00011180', '\U000111df'),
+    ('\U000111e1', '\U000111f4'),
+    ('\U00011200', '\U00011211'),
+    ('\U00011213', '\U0001123e'),
+    ('\U00011280', '\U00011286'),
+    ('\U00011288', '\U00011288'),
+    ('\U0001128a', '\U0001128d'),
+    ('\U0001128f', '\U0001129d'),
+    ('\U0001129f', '\U000112a9'),
+    ('\U000112b0', '\U000112ea'),
+    ('\U000112f0', '\U000112f9'),
+    ('\U00011300', '\U00011303'),
+    ('\U00011305', '\U0001130c'),
+    ('\U0001130f', '\U00011310'),
+    ('\U00011313', '\U00011328'),
+    ('\U0001132a', '\U00011330'),
+    ('\U00011332', '\U00011333'),
+    ('\U00011335', '\U00011339'),
+    ('\U0001133b', '\U00011344'),
+    ('\U00011347', '\U00011348'),
+    ('\U0001134b', '\U0001134d'),
+    ('\U00011350', '\U00011350'),
+    ('\U00011357', '\U00011357'),
+    ('\U0001135d', '\U00011363'),
+    ('\U00011366', '\U0001136c'),
+    ('\U00011370', '\U00011374'),
+    ('\U00011400', '\U0001145b'),
+    ('\U0001145d', '\U00011461'),
+    ('\U00011480', '\U000114c7'),
+    ('\U000114d0', '\U000114d9'),
+    ('\U00011580', '\U000115b5'),
+    ('\U000115b8', '\U000115dd'),
+    ('\U00011600', '\U00011644'),
+    ('\U00011650', '\U00011659'),
+    ('\U00011660', '\U0001166c'),
+    ('\U00011680', '\U000116b9'),
+    ('\U000116c0', '\U000116c9'),
+    ('\U00011700', '\U0001171a'),
+    ('\U0001171d', '\U0001172b'),
+    ('\U00011730', '\U00011746'),
+    ('\U00011800', '\U0001183b'),
+    ('\U000118a0', '\U000118f2'),
+    ('\U000118ff', '\U00011906'),
+    ('\U00011909', '\U00011909'),
+    ('\U0001190c', '\U00011913'),
+    ('\U00011915', '\U00011916'),
+    ('\U00011918', '\U00011935'),
+    ('\U00011937', '\U00011938'),
+    ('\U0001193b', '\U00011946'),
+    ('\U00011950', '\U00011959'),
+    ('\U000119a0', '\U000119a7'),
+    ('\U000119aa', '\U000119d7'),
+    ('\U000119da', '\U000119e4'),
+    ('\U00011a00', '\U00011a47'),
+    ('\U00011a50', '\U00011aa2'),
+    ('\U00011ab0', '\U00011af8'),
+    ('\U00011c00', '\U00011c08'),
+    ('\U00011c0a', '\U00011c36'),
+    ('\U00011c38', '\U00011c45'),
+    ('\U00011c50', '\U00011c6c'),
+    ('\U00011c70', '\U00011c8f'),
+    ('\U00011c92', '\U00011ca7'),
+    ('\U00011ca9', '\U00011cb6'),
+    ('\U00011d00', '\U00011d06'),
+    ('\U00011d08', '\U00011d09'),
+    ('\U00011d0b', '\U00011d36'),
+    ('\U00011d3a', '\U00011d3a'),
+    ('\U00011d3c', '\U00011d3d'),
+    ('\U00011d3f', '\U00011d47'),
+    ('\U00011d50', '\U00011d59'),
+    ('\U00011d60', '\U00011d65'),
+    ('\U00011d67', '\U00011d68'),
+    ('\U00011d6a', '\U00011d8e'),
+    ('\U00011d90', '\U00011d91'),
+    ('\U00011d93', '\U00011d98'),
+    ('\U00011da0', '\U00011da9'),
+    ('\U00011ee0', '\U00011ef8'),
+    ('\U00011fb0', '\U00011fb0'),
+    ('\U00011fc0', '\U00011ff1'),
+    ('\U00011fff', '\U00012399'),
+    ('\U00012400', '\U0001246e'),
+    ('\U00012470', '\U00012474'),
+    ('\U00012480', '\U00012543'),
+    ('\U00012f90', '\U00012ff2'),
+    ('\U00013000', '\U0001342e'),
+    ('\U00013430', '\U00013438'),
+    ('\U00014400', '\U00014646'),
+    ('\U00016800', '\U00016a38'),
+    ('\U00016a40', '\U00016a5e'),
+    ('\U00016a60', '\U00016a69'),
+    ('\U00016a6e', '\U00016abe'),
+    ('\U00016ac0', '\U00016ac9'),
+    ('\U00016ad0', '\U00016aed'),
+    ('\U00016af0', '\U00016af5'),
+    ('\U00016b00', '\U00016b45'),
+    ('\U00016b50', '\U00016b59'),
+    ('\U00016b5b', '\U00016b61'),
+    ('\U00016b63', '\U00016b77'),
+    ('\U00016b7d', '\U00016b8f'),
+    ('\U00016e40', '\U00016e9a'),
+    ('\U00016f00', '\U00016f4a'),
+    ('\U00016f4f', '\U00016f87'),
+    ('\U00016f8f', '\U00016f9f'),
+    ('\U00016fe0', '\U00016fe4'),
+    ('\U00016ff0', '\U00016ff1'),
+    ('\U00017000', '\U000187f7'),
+    ('\U00018800', '\U00018cd5'),
+    ('\U00018d00', '\U00018d08'),
+    ('\U0001aff0', '\U0001aff3'),
+    ('\U0001aff5', '\U0001affb'),
+    ('\U0001affd', '\U0001affe'),
+    ('\U0001b000', '\U0001b122'),
+    ('\U0001b150', '\U0001b152'),
+    ('\U0001b164', '\U0001b167'),
+    ('\U0001b170', '\U0001b2fb'),
+    ('\U0001bc00', '\U0001bc6a'),
+    ('\U0001bc70', '\U0001bc7c'),
+    ('\U0001bc80', '\U0001bc88'),
+    ('\U0001bc90', '\U0001bc99'),
+    ('\U0001bc9c', '\U0001bca3'),
+    ('\U0001cf00', '\U0001cf2d'),
+    ('\U0001cf30', '\U0001cf46'),
+    ('\U0001cf50', '\U0001cfc3'),
+    ('\U0001d000', '\U0001d0f5'),
+    ('\U0001d100', '\U0001d126'),
+    ('\U0001d129', '\U0001d1ea'),
+    ('\U0001d200', '\U0001d245'),
+    ('\U0001d2e0', '\U0001d2f3'),
+    ('\U0001d300', '\U0001d356'),
+    ('\U0001d360', '\U0001d378'),
+    ('\U0001d400', '\U0001d454'),
+    ('\U0001d456', '\U0001d49c'),
+    ('\U0001d49e', '\U0001d49f'),
+    ('\U0001d4a2', '\U0001d4a2'),
+    ('\U0001d4a5', '\U0001d4a6'),
+    ('\U0001d4a9', '\U0001d4ac'),
+    ('\U0001d4ae', '\U0001d4b9'),
+    ('\U0001d4bb', '\U0001d4bb'),
+    ('\U0001d4bd', '\U0001d4c3'),
+    ('\U0001d4c5', '\U0001d505'),
+    ('\U0001d507', '\U0001d50a'),
+    ('\U0001d50d', '\U0001d514'),
+    ('\U0001d516', '\U0001d51c'),
+    ('\U0001d51e', '\U0001d539'),
+    ('\U0001d53b', '\U0001d53e'),
+    ('\U0001d540', '\U0001d544'),
+    ('\U0001d546', '\U0001d546'),
+    ('\U0001d54a', '\U0001d550'),
+    ('\U0001d552', '\U0001d6a5'),
+    ('\U0001d6a8', '\U0001d7cb'),
+    ('\U0001d7ce', '\U0001da8b'),
+    ('\U0001da9b', '\U0001da9f'),
+    ('\U0001daa1', '\U0001daaf'),
+    ('\U0001df00', '\U0001df1e'),
+    ('\U0001e000', '\U0001e006'),
+    ('\U0001e008', '\U0001e018'),
+    ('\U0001e01b', '\U0001e021'),
+    ('\U0001e023', '\U0001e024'),
+    ('\U0001e026', '\U0001e02a'),
+    ('\U0001e100', '\U0001e12c'),
+    ('\U0001e130', '\U0001e13d'),
+    ('\U0001e140', '\U0001e149'),
+    ('\U0001e14e', '\U0001e14f'),
+    ('\U0001e290', '\U0001e2ae'),
+    ('\U0001e2c0', '\U0001e2f9'),
+    ('\U0001e2ff', '\U0001e2ff'),
+    ('\U0001e7e0', '\U0001e7e6'),
+    ('\U0001e7e8', '\U0001e7eb'),
+    ('\U0001e7ed', '\U0001e7ee'),
+    ('\U0001e7f0', '\U0001e7fe'),
+    ('\U0001e800', '\U0001e8c4'),
+    ('\U0001e8c7', '\U0001e8d6'),
+    ('\U0001e900', '\U0001e94b'),
+    ('\U0001e950', '\U0001e959'),
+    ('\U0001e95e', '\U0001e95f'),
+    ('\U0001ec71', '\U0001ecb4'),
+    ('\U0001ed01', '\U0001ed3d'),
+    ('\U0001ee00', '\U0001ee03'),
+    ('\U0001ee05', '\U0001ee1f'),
+    ('\U0001ee21', '\U0001ee22'),
+    ('\U0001ee24', '\U0001ee24'),
+    ('\U0001ee27', '\U0001ee27'),
+    ('\U0001ee29', '\U0001ee32'),
+    ('\U0001ee34', '\U0001ee37'),
+    ('\U0001ee39', '\U0001ee39'),
+    ('\U0001ee3b', '\U0001ee3b'),
+    ('\U0001ee42', '\U0001ee42'),
+    ('\U0001ee47', '\U0001ee47'),
+    ('\U0001ee49', '\U0001ee49'),
+    ('\U0001ee4b', '\U0001ee4b'),
+    ('\U0001ee4d', '\U0001ee4f'),
+    ('\U0001ee51', '\U0001ee52'),
+    ('\U0001ee54', '\U0001ee54'),
+    ('\U0001ee57', '\U0001ee57'),
+    ('\U0001ee59', '\U0001ee59'),
+    ('\U0001ee5b', '\U0001ee5b'),
+    ('\U0001ee5d', '\U0001ee5d'),
+    ('\U0001ee5f', '\U0001ee5f'),
+    ('\U0001ee61', '\U0001ee62'),
+    ('\U0001ee64', '\U0001ee64'),
+    ('\U0001ee67', '\U0001ee6a'),
+    ('\U0001ee6c', '\U0001ee72'),
+    ('\U0001ee74', '\U0001ee77'),
+    ('\U0001ee79', '\U0001ee7c'),
+    ('\U0001ee7e', '\U0001ee7e'),
+    ('\U0001ee80', '\U0001ee89'),
+    ('\U0001ee8b', '\U0001ee9b'),
+    ('\U0001eea1', '\U0001eea3'),
+    ('\U0001eea5', '\U0001eea9'),
+    ('\U0001eeab', '\U0001eebb'),
+    ('\U0001eef0', '\U0001eef1'),
+    ('\U0001f000', '\U0001f02b'),
+    ('\U0001f030', '\U0001f093'),
+    ('\U0001f0a0', '\U0001f0ae'),
+    ('\U0001f0b1', '\U0001f0bf'),
+    ('\U0001f0c1', '\U0001f0cf'),
+    ('\U0001f0d1', '\U0001f0f5'),
+    ('\U0001f100', '\U0001f1ad'),
+    ('\U0001f1e6', '\U0001f202'),
+    ('\U0001f210', '\U0001f23b'),
+    ('\U0001f240', '\U0001f248'),
+    ('\U0001f250', '\U0001f251'),
+    ('\U0001f260', '\U0001f265'),
+    ('\U0001f300', '\U0001f6d7'),
+    ('\U0001f6dd', '\U0001f6ec'),
+    ('\U0001f6f0', '\U0001f6fc'),
+    ('\U0001f700', '\U0001f773'),
+    ('\U0001f780', '\U0001f7d8'),
+    ('\U0001f7e0', '\U0001f7eb'),
+    ('\U0001f7f0', '\U0001f7f0'),
+    ('\U0001f800', '\U0001f80b'),
+    ('\U0001f810', '\U0001f847'),
+    ('\U0001f850', '\U0001f859'),
+    ('\U0001f860', '\U0001f887'),
+    ('\U0001f890', '\U0001f8ad'),
+    ('\U0001f8b0', '\U0001f8b1'),
+    ('\U0001f900', '\U0001fa53'),
+    ('\U0001fa60', '\U0001fa6d'),
+    ('\U0001fa70', '\U0001fa74'),
+    ('\U0001fa78', '\U0001fa7c'),
+    ('\U0001fa80', '\U0001fa86'),
+    ('\U0001fa90', '\U0001faac'),
+    ('\U0001fab0', '\U0001faba'),
+    ('\U0001fac0', '\U0001fac5'),
+    ('\U0001fad0', '\U0001fad9'),
+    ('\U0001fae0', '\U0001fae7'),
+    ('\U0001faf0', '\U0001faf6'),
+    ('\U0001fb00', '\U0001fb92'),
+    ('\U0001fb94', '\U0001fbca'),
+    ('\U0001fbf0', '\U0001fbf9'),
+    ('\U00020000', '\U0002a6df'),
+    ('\U0002a700', '\U0002b738'),
+    ('\U0002b740', '\U0002b81d'),
+    ('\U0002b820', '\U0002cea1'),
+    ('\U0002ceb0', '\U0002ebe0'),
+    ('\U0002f800', '\U0002fa1d'),
+    ('\U00030000', '\U0003134a'),
+    ('\U000e0001', '\U000e0001'),
+    ('\U000e0020', '\U000e007f'),
+    ('\U000e0100', '\U000e01ef'),
+    ('\U000f0000', '\U000ffffd'),
+    ('\U00100000', '\U0010fffd')
+    ]),
+
+("Dash", &[
+    ('\U0000002d', '\U0000002d'),
+    ('\U0000058a', '\U0000058a'),
+    ('\U000005be', '\U000005be'),
+    ('\U00001400', '\U00001400'),
+    ('\U00001806', '\U00001806'),
+    ('\U00002010', '\U00002015'),
+    ('\U00002053', '\U00002053'),
+    ('\U0000207b', '\U0000207b'),
+    ('\U0000208b', '\U0000208b'),
+    ('\U00002212', '\U00002212'),
+    ('\U00002e17', '\U00002e17'),
+    ('\U00002e1a', '\U00002e1a'),
+    ('\U00002e3a', '\U00002e3b'),
+    ('\U00002e40', '\U00002e40'),
+    ('\U00002e5d', '\U00002e5d'),
+    ('\U0000301c', '\U0000301c'),
+    ('\U00003030', '\U00003030'),
+    ('\U000030a0', '\U000030a0'),
+    ('\U0000fe31', '\U0000fe32'),
+    ('\U0000fe58', '\U0000fe58'),
+    ('\U0000fe63', '\U0000fe63'),
+    ('\U0000ff0d', '\U0000ff0d'),
+    ('\U00010ead', '\U00010ead')
+    ]),
+
+("Default_Ignorable_Code_Point", &[
+    ('\U000000ad', '\U000000ad'),
+    ('\U0000034f', '\U0000034f'),
+    ('\U0000061c', '\U0000061c'),
+    ('\U0000115f', '\U00001160'),
+    ('\U000017b4', '\U000017b5'),
+    ('\U0000180b', '\U0000180f'),
+    ('\U0000200b', '\U0000200f'),
+    ('\U0000202a', '\U0000202e'),
+    ('\U00002060', '\U0000206f'),
+    ('\U00003164', '\U00003164'),
+    ('\U0000fe00', '\U0000fe0f'),
+    ('\U0000feff', '\U0000feff'),
+    ('\U0000ffa0', '\U0000ffa0'),
+    ('\U0000fff0', '\U0000fff8'),
+    ('\U0001bca0', '\U0001bca3'),
+    ('\U0001d173', '\U0001d17a'),
+    ('\U000e0000', '\U000e0fff')
+    ]),
+
+("Diacritic", &[
+    ('\U0000005e', '\U0000005e'),
+    ('\U00000060', '\U00000060'),
+    ('\U000000a8', '\U000000a8'),
+    ('\U000000af', '\U000000af'),
+    ('\U000000b4', '\U000000b4'),
+    ('\U000000b7', '\U000000b8'),
+    ('\U000002b0', '\U0000034e'),
+    ('\U00000350', '\U00000357'),
+    ('\U0000035d', '\U00000362'),
+    ('\U00000374', '\U00000375'),
+    ('\U0000037a', '\U0000037a'),
+    ('\U00000384', '\U00000385'),
+    ('\U00000483', '\U00000487'),
+    ('\U00000559', '\U00000559'),
+    ('\U00000591', '\U000005a1'),
+    ('\U000005a3', '\U000005bd'),
+    ('\U000005bf', '\U000005bf'),
+    ('\U000005c1', '\U000005c2'),
+    ('\U000005c4', '\U000005c4'),
+    ('\U0000064b', '\U00000652'),
+    ('\U00000657', '\U00000658'),
+    ('\U000006df', '\U000006e0'),
+    ('\U000006e5', '\U000006e6'),
+    ('\U000006ea', '\U000006ec'),
+    ('\U00000730', '\U0000074a'),
+    ('\U000007a6', '\U000007b0'),
+    ('\U000007eb', '\U000007f5'),
+    ('\U00000818', '\U00000819'),
+    ('\U00000898', '\U0000089f'),
+    ('\U000008c9', '\U000008d2'),
+    ('\U000008e3', '\U000008fe'),
+    ('\U0000093c', '\U0000093c'),
+    ('\U0000094d', '\U0000094d'),
+    ('\U00000951', '\U00000954'),
+    ('\U00000971', '\U00000971'),
+    ('\U000009bc', '\U000009bc'),
+    ('\U000009cd', '\U000009cd'),
+    ('\U00000a3c', '\U00000a3c'),
+    ('\U00000a4d', '\U00000a4d'),
+    ('\U00000abc', '\U00000abc'),
+    ('\U00000acd', '\U00000acd'),
+    ('\U00000afd', '\U00000aff'),
+    ('\U00000b3c', '\U00000b3c'),
+    ('\U00000b4d', '\U00000b4d'),
+    ('\U00000b55', '\U00000b55'),
+    ('\U00000bcd', '\U00000bcd'),
+    ('\U00000c3c', '\U00000c3c'),
+    ('\U00000c4d', '\U00000c4d'),
+    ('\U00000cbc', '\U00000cbc'),
+    ('\U00000ccd', '\U00000ccd'),
+    ('\U00000d3b', '\U00000d3c'),
+    ('\U00000d4d', '\U00000d4d'),
+    ('\U00000dca', '\U00000dca'),
+    ('\U00000e47', '\U00000e4c'),
+    ('\U00000e4e', '\U00000e4e'),
+    ('\U00000eba', '\U00000eba'),
+    ('\U00000ec8', '\U00000ecc'),
+    ('\U00000f18', '\U00000f19'),
+    ('\U00000f35', '\U00000f35'),
+    ('\U00000f37', '\U00000f37'),
+    ('\U00000f39', '\U00000f39'),
+    ('\U00000f3e', '\U00000f3f'),
+    ('\U00000f82', '\U00000f84'),
+    ('\U00000f86', '\U00000f87'),
+    ('\U00000fc6', '\U00000fc6'),
+    ('\U00001037', '\U00001037'),
+    ('\U00001039', '\U0000103a'),
+    ('\U00001063', '\U00001064'),
+    ('\U00001069', '\U0000106d'),
+    ('\U00001087', '\U0000108d'),
+    ('\U0000108f', '\U0000108f'),
+    ('\U0000109a', '\U0000109b'),
+    ('\U0000135d', '\U0000135f'),
+    ('\U00001714', '\U00001715'),
+    ('\U000017c9', '\U000017d3'),
+    ('\U000017dd', '\U000017dd'),
+    ('\U00001939', '\U0000193b'),
+    ('\U00001a75', '\U00001a7c'),
+    ('\U00001a7f', '\U00001a7f'),
+    ('\U00001ab0', '\U00001abe'),
+    ('\U00001ac1', '\U00001acb'),
+    ('\U00001b34', '\U00001b34'),
+    ('\U00001b44', '\U00001b44'),
+    ('\U00001b6b', '\U00001b73'),
+    ('\U00001baa', '\U00001bab'),
+    ('\U00001c36', '\U00001c37'),
+    ('\U00001c78', '\U00001c7d'),
+    ('\U00001cd0', '\U00001ce8'),
+    ('\U00001ced', '\U00001ced'),
+    ('\U00001cf4', '\U00001cf4'),
+    ('\U00001cf7', '\U00001cf9'),
+    ('\U00001d2c', '\U00001d6a'),
+    ('\U00001dc4', '\U00001dcf'),
+    ('\U00001df5', '\U00001dff'),
+    ('\U00001fbd', '\U00001fbd'),
+    ('\U00001fbf', '\U00001fc1'),
+    ('\U00001fcd', '\U00001fcf'),
+    ('\U00001fdd', '\U00001fdf'),
+    ('\U00001fed', '\U00001fef'),
+    ('\U00001ffd', '\U00001ffe'),
+    ('\U00002cef', '\U00002cf1'),
+    ('\U00002e2f', '\U00002e2f'),
+    ('\U0000302a', '\U0000302f'),
+    ('\U00003099', '\U0000309c'),
+    ('\U000030fc', '\U000030fc'),
+    ('\U0000a66f', '\U0000a66f'),
+    ('\U0000a67c', '\U0000a67d'),
+    ('\U0000a67f', '\U0000a67f'),
+    ('\U0000a69c', '\U0000a69d'),
+    ('\U0000a6f0', '\U0000a6f1'),
+    ('\U0000a700', '\U0000a721'),
+    ('\U0000a788', '\U0000a78a'),
+    ('\U0000a7f8', '\U0000a7f9'),
+    ('\U0000a8c4', '\U0000a8c4'),
+    ('\U0000a8e0', '\U0000a8f1'),
+    ('\U0000a92b', '\U0000a92e'),
+    ('\U0000a953', '\U0000a953'),
+    ('\U0000a9b3', '\U0000a9b3'),
+    ('\U0000a9c0', '\U0000a9c0'),
+    ('\U0000a9e5', '\U0000a9e5'),
+    ('\U0000aa7b', '\U0000aa7d'),
+    ('\U0000aabf', '\U0000aac2'),
+    ('\U0000aaf6', '\U0000aaf6'),
+    ('\U0000ab5b', '\U0000ab5f'),
+    ('\U0000ab69', '\U0000ab6b'),
+    ('\U0000abec', '\U0000abed'),
+    ('\U0000fb1e', '\U0000fb1e'),
+    ('\U0000fe20', '\U0000fe2f'),
+    ('\U0000ff3e', '\U0000ff3e'),
+    ('\U0000ff40', '\U0000ff40'),
+    ('\U0000ff70', '\U0000ff70'),
+    ('\U0000ff9e', '\U0000ff9f'),
+    ('\U0000ffe3', '\U0000ffe3'),
+    ('\U000102e0', '\U000102e0'),
+    ('\U00010780', '\U00010785'),
+    ('\U00010787', '\U000107b0'),
+    ('\U000107b2', '\U000107ba'),
+    ('\U00010ae5', '\U00010ae6'),
+    ('\U00010d22', '\U00010d27'),
+    ('\U00010f46', '\U00010f50'),
+    ('\U00010f82', '\U00010f85'),
+    ('\U00011046', '\U00011046'),
+    ('\U00011070', '\U00011070'),
+    ('\U000110b9', '\U000110ba'),
+    ('\U00011133', '\U00011134'),
+    ('\U00011173', '\U00011173'),
+    ('\U000111c0', '\U000111c0'),
+    ('\U000111ca', '\U000111cc'),
+    ('\U00011235', '\U00011236'),
+    ('\U000112e9', '\U000112ea'),
+    ('\U0001133c', '\U0001133c'),
+    ('\U0001134d', '\U0001134d'),
+    ('\U00011366', '\U0001136c'),
+    ('\U00011370', '\U00011374'),
+    ('\U00011442', '\U00011442'),
+    ('\U00011446', '\U00011446'),
+    ('\U000114c2', '\U000114c3'),
+    ('\U000115bf', '\U000115c0'),
+    ('\U0001163f', '\U0001163f'),
+    ('\U000116b6', '\U000116b7'),
+    ('\U0001172b', '\U0001172b'),
+    ('\U00011839', '\U0001183a'),
+    ('\U0001193d', '\U0001193e'),
+    ('\U00011943', '\U00011943'),
+    ('\U000119e0', '\U000119e0'),
+    ('\U00011a34', '\U00011a34'),
+    ('\U00011a47', '\U00011a47'),
+    ('\U00011a99', '\U00011a99'),
+    ('\U00011c3f', '\U00011c3f'),
+    ('\U00011d42', '\U00011d42'),
+    ('\U00011d44', '\U00011d45'),
+    ('\U00011d97', '\U00011d97'),
+    ('\U00016af0', '\U00016af4'),
+    ('\U00016b30', '\U00016b36'),
+    ('\U00016f8f', '\U00016f9f'),
+    ('\U00016ff0', '\U00016ff1'),
+    ('\U0001aff0', '\U0001aff3'),
+    ('\U0001aff5', '\U0001affb'),
+    ('\U0001affd', '\U0001affe'),
+    ('\U0001cf00', '\U0001cf2d'),
+    ('\U0001cf30', '\U0001cf46'),
+    ('\U0001d167', '\U0001d169'),
+    ('\U0001d16d', '\U0001d172'),
+    ('\U0001d17b', '\U0001d182'),
+    ('\U0001d185', '\U0001d18b'),
+    ('\U0001d1aa', '\U0001d1ad'),
+    ('\U0001e130', '\U0001e136'),
+    ('\U0001e2ae', '\U0001e2ae'),
+    ('\U0001e2ec', '\U0001e2ef'),
+    ('\U0001e8d0', '\U0001e8d6'),
+    ('\U0001e944', '\U0001e946'),
+    ('\U0001e948', '\U0001e94a')
+    ]),
+
+("Hex_Digit", &[
+    ('\U00000030', '\U00000039'),
+    ('\U00000041', '\U00000046'),
+    ('\U00000061', '\U00000066'),
+    ('\U0000ff10', '\U0000ff19'),
+    ('\U0000ff21', '\U0000ff26'),
+    ('\U0000ff41', '\U0000ff46')
+    ]),
+
+("Ideographic", &[
+    ('\U00003006', '\U00003007'),
+    ('\U00003021', '\U00003029'),
+    ('\U00003038', '\U0000303a'),
+    ('\U00003400', '\U00004dbf'),
+    ('\U00004e00', '\U00009fff'),
+    ('\U0000f900', '\U0000fa6d'),
+    ('\U0000fa70', '\U0000fad9'),
+    ('\U00016fe4', '\U00016fe4'),
+    ('\U00017000', '\U000187f7'),
+    ('\U00018800', '\U00018cd5'),
+    ('\U00018d00', '\U00018d08'),
+    ('\U0001b170', '\U0001b2fb'),
+    ('\U00020000', '\U0002a6df'),
+    ('\U0002a700', '\U0002b738'),
+    ('\U0002b740', '\U0002b81d'),
+    ('\U0002b820', '\U0002cea1'),
+    ('\U0002ceb0', '\U0002ebe0'),
+    ('\U0002f800', '\U0002fa1d'),
+    ('\U00030000', '\U0003134a')
+    ]),
+
+("Lowercase", &[
+    ('\U00000061', '\U0000007a'),
+    ('\U000000aa', '\U000000aa'),
+    ('\U000000b5', '\U000000b5'),
+    ('\U000000ba', '\U000000ba'),
+    ('\U000000df', '\U000000f6'),
+    ('\U000000f8', '\U000000ff'),
+    ('\U00000101', '\U00000101'),
+    ('\U00000103', '\U00000103'),
+    ('\U00000105', '\U00000105'),
+    ('\U00000107', '\U00000107'),
+    ('\U00000109', '\U00000109'),
+    ('\U0000010b', '\U0000010b'),
+    ('\U0000010d', '\U0000010d'),
+    ('\U0000010f', '\U0000010f'),
+    ('\U00000111', '\U00000111'),
+    ('\U00000113', '\U00000113'),
+    ('\U00000115', '\U00000115'),
+    ('\U00000117', '\U00000117'),
+    ('\U00000119', '\U00000119'),
+    ('\U0000011b', '\U0000011b'),
+    ('\U0000011d', '\U0000011d'),
+    ('\U0000011f', '\U0000011f'),
+    ('\U00000121', '\U00000121'),
+    ('\U00000123', '\U00000123'),
+    ('\U00000125', '\U00000125'),
+    ('\U00000127', '\U00000127'),
+    ('\U00000129', '\U00000129'),
+    ('\U0000012b', '\U0000012b'),
+    ('\U0000012d', '\U0000012d'),
+    ('\U0000012f', '\U0000012f'),
+    ('\U00000131', '\U00000131'),
+    ('\U00000133', '\U00000133'),
+    ('\U00000135', '\U00000135'),
+    ('\U00000137', '\U00000138'),
+    ('\U0000013a', '\U0000013a'),
+    ('\U0000013c', '\U0000013c'),
+    ('\U0000013e', '\U0000013e'),
+    ('\U00000140', '\U00000140'),
+    ('\U00000142', '\U00000142'),
+    ('\U00000144', '\U00000144'),
+    ('\U00000146', '\U00000146'),
+    ('\U00000148', '\U00000149'),
+    ('\U0000014b', '\U0000014b'),
+    ('\U0000014d', '\U0000014d'),
+    ('\U0000014f', '\U0000014f'),
+    ('\U00000151', '\U00000151'),
+    ('\U00000153', '\U00000153'),
+    ('\U00000155', '\U00000155'),
+    ('\U00000157', '\U00000157'),
+    ('\U00000159', '\U00000159'),
+    ('\U0000015b', '\U0000015b'),
+    ('\U0000015d', '\U0000015d'),
+    ('\U0000015f', '\U0000015f'),
+    ('\U00000161', '\U00000161'),
+    ('\U00000163', '\U00000163'),
+    ('\U00000165', '\U00000165'),
+    ('\U00000167', '\U00000167'),
+    ('\U00000169', '\U00000169'),
+    ('\U0000016b', '\U0000016b'),
+    ('\U0000016d', '\U0000016d'),
+    ('\U0000016f', '\U0000016f'),
+    ('\U00000171', '\U00000171'),
+    ('\U00000173', '\U00000173'),
+    ('\U00000175', '\U00000175'),
+    ('\U00000177', '\U00000177'),
+    ('\U0000017a', '\U0000017a'),
+    ('\U0000017c', '\U0000017c'),
+    ('\U0000017e', '\U00000180'),
+    ('\U00000183', '\U00000183'),
+    ('\U00000185', '\U00000185'),
+    ('\U00000188', '\U00000188'),
+    ('\U0000018c', '\U0000018d'),
+    ('\U00000192', '\U00000192'),
+    ('\U00000195', '\U00000195'),
+    ('\U00000199', '\U0000019b'),
+    ('\U0000019e', '\U0000019e'),
+    ('\U000001a1', '\U000001a1'),
+    ('\U000001a3', '\U000001a3'),
+    ('\U000001a5', '\U000001a5'),
+    ('\U000001a8', '\U000001a8'),
+    ('\U000001aa', '\U000001ab'),
+    ('\U000001ad', '\U000001ad'),
+    ('\U000001b0', '\U000001b0'),
+    ('\U000001b4', '\U000001b4'),
+    ('\U000001b6', '\U000001b6'),
+    ('\U000001b9', '\U000001ba'),
+    ('\U000001bd', '\U000001bf'),
+    ('\U000001c6', '\U000001c6'),
+    ('\U000001c9', '\U000001c9'),
+    ('\U000001cc', '\U000001cc'),
+    ('\U000001ce', '\U000001ce'),
+    ('\U000001d0', '\U000001d0'),
+    ('\U000001d2', '\U000001d2'),
+    ('\U000001d4', '\U000001d4'),
+    ('\U000001d6', '\U000001d6'),
+    ('\U000001d8', '\U000001d8'),
+    ('\U000001da', '\U000001da'),
+    ('\U000001dc', '\U000001dd'),
+    ('\U000001df', '\U000001df'),
+    ('\U000001e1', '\U000001e1'),
+    ('\U000001e3', '\U000001e3'),
+    ('\U000001e5', '\U000001e5'),
+    ('\U000001e7', '\U000001e7'),
+    ('\U000001e9', '\U000001e9'),
+    ('\U000001eb', '\U000001eb'),
+    ('\U000001ed', '\U000001ed'),
+    ('\U000001ef', '\U000001f0'),
+    ('\U000001f3', '\U000001f3'),
+    ('\U000001f5', '\U000001f5'),
+    ('\U000001f9', '\U000001f9'),
+    ('\U000001fb', '\U000001fb'),
+    ('\U000001fd', '\U000001fd'),
+    ('\U000001ff', '\U000001ff'),
+    ('\U00000201', '\U00000201'),
+    ('\U00000203', '\U00000203'),
+    ('\U00000205', '\U00000205'),
+    ('\U00000207', '\U00000207'),
+    ('\U00000209', '\U00000209'),
+    ('\U0000020b', '\U0000020b'),
+    ('\U0000020d', '\U0000020d'),
+    ('\U0000020f', '\U0000020f'),
+    ('\U00000211', '\U00000211'),
+    ('\U00000213', '\U00000213'),
+    ('\U00000215', '\U00000215'),
+    ('\U00000217', '\U00000217'),
+    ('\U00000219', '\U00000219'),
+    ('\U0000021b', '\U0000021b'),
+    ('\U0000021d', '\U0000021d'),
+    ('\U0000021f', '\U0000021f'),
+    ('\U00000221', '\U00000221'),
+    ('\U00000223', '\U00000223'),
+    ('\U00000225', '\U00000225'),
+    ('\U00000227', '\U00000227'),
+    ('\U00000229', '\U00000229'),
+    ('\U0000022b', '\U0000022b'),
+    ('\U0000022d', '\U0000022d'),
+    ('\U0000022f', '\U0000022f'),
+    ('\U00000231', '\U00000231'),
+    ('\U00000233', '\U00000239'),
+    ('\U0000023c', '\U0000023c'),
+    ('\U0000023f', '\U00000240'),
+    ('\U00000242', '\U00000242'),
+    ('\U00000247', '\U00000247'),
+    ('\U00000249', '\U00000249'),
+    ('\U0000024b', '\U0000024b'),
+    ('\U0000024d', '\U0000024d'),
+    ('\U0000024f', '\U00000293'),
+    ('\U00000295', '\U000002b8'),
+    ('\U000002c0', '\U000002c1'),
+    ('\U000002e0', '\U000002e4'),
+    ('\U00000345', '\U00000345'),
+    ('\U00000371', '\U00000371'),
+    ('\U00000373', '\U00000373'),
+    ('\U00000377', '\U00000377'),
+    ('\U0000037a', '\U0000037d'),
+    ('\U00000390', '\U00000390'),
+    ('\U000003ac', '\U000003ce'),
+    ('\U000003d0', '\U000003d1'),
+    ('\U000003d5', '\U000003d7'),
+    ('\U000003d9', '\U000003d9'),
+    ('\U000003db', '\U000003db'),
+    ('\U000003dd', '\U000003dd'),
+    ('\U000003df', '\U000003df'),
+    ('\U000003e1', '\U000003e1'),
+    ('\U000003e3', '\U000003e3'),
+    ('\U000003e5', '\U000003e5'),
+    ('\U000003e7', '\U000003e7'),
+    ('\U000003e9', '\U000003e9'),
+    ('\U000003eb', '\U000003eb'),
+    ('\U000003ed', '\U000003ed'),
+    ('\U000003ef', '\U000003f3'),
+    ('\U000003f5', '\U000003f5'),
+    ('\U000003f8', '\U000003f8'),
+    ('\U000003fb', '\U000003fc'),
+    ('\U00000430', '\U0000045f'),
+    ('\U00000461', '\U00000461'),
+    ('\U00000463', '\U00000463'),
+    ('\U00000465', '\U00000465'),
+    ('\U00000467', '\U00000467'),
+    ('\U00000469', '\U00000469'),
+    ('\U0000046b', '\U0000046b'),
+    ('\U0000046d', '\U0000046d'),
+    ('\U0000046f', '\U0000046f'),
+    ('\U00000471', '\U00000471'),
+    ('\U00000473', '\U00000473'),
+    ('\U00000475', '\U00000475'),
+    ('\U00000477', '\U00000477'),
+    ('\U00000479', '\U00000479'),
+    ('\U0000047b', '\U0000047b'),
+    ('\U0000047d', '\U0000047d'),
+    ('\U0000047f', '\U0000047f'),
+    ('\U00000481', '\U00000481'),
+    ('\U0000048b', '\U0000048b'),
+    ('\U0000048d', '\U0000048d'),
+    ('\U0000048f', '\U0000048f'),
+    ('\U00000491', '\U00000491'),
+    ('\U00000493', '\U00000493'),
+    ('\U00000495', '\U00000495'),
+    ('\U00000497', '\U00000497'),
+    ('\U00000499', '\U00000499'),
+    ('\U0000049b', '\U0000049b'),
+    ('\U0000049d', '\U0000049d'),
+    ('\U0000049f', '\U0000049f'),
+    ('\U000004a1', '\U000004a1'),
+    ('\U000004a3', '\U000004a3'),
+    ('\U000004a5', '\U000004a5'),
+    ('\U000004a7', '\U000004a7'),
+    ('\U000004a9', '\U000004a9'),
+    ('\U000004ab', '\U000004ab'),
+    ('\U000004ad', '\U000004ad'),
+    ('\U000004af', '\U000004af'),
+    ('\U000004b1', '\U000004b1'),
+    ('\U000004b3', '\U000004b3'),
+    ('\U000004b5', '\U000004b5'),
+    ('\U000004b7', '\U000004b7'),
+    ('\U000004b9', '\U000004b9'),
+    ('\U000004bb', '\U000004bb'),
+    ('\U000004bd', '\U000004bd'),
+    ('\U000004bf', '\U000004bf'),
+    ('\U000004c2', '\U000004c2'),
+    ('\U000004c4', '\U000004c4'),
+    ('\U000004c6', '\U000004c6'),
+    ('\U000004c8', '\U000004c8'),
+    ('\U000004ca', '\U000004ca'),
+    ('\U000004cc', '\U000004cc'),
+    ('\U000004ce', '\U000004cf'),
+    ('\U000004d1', '\U000004d1'),
+    ('\U000004d3', '\U000004d3'),
+    ('\U000004d5', '\U000004d5'),
+    ('\U000004d7', '\U000004d7'),
+    ('\U000004d9', '\U000004d9'),
+    ('\U000004db', '\U000004db'),
+    ('\U000004dd', '\U000004dd'),
+    ('\U000004df', '\U000004df'),
+    ('\U000004e1', '\U000004e1'),
+    ('\U000004e3', '\U000004e3'),
+    ('\U000004e5', '\U000004e5'),
+    ('\U000004e7', '\U000004e7'),
+    ('\U000004e9', '\U000004e9'),
+    ('\U000004eb', '\U000004eb'),
+    ('\U000004ed', '\U000004ed'),
+    ('\U000004ef', '\U000004ef'),
+    ('\U000004f1', '\U000004f1'),
+    ('\U000004f3', '\U000004f3'),
+    ('\U000004f5', '\U000004f5'),
+    ('\U000004f7', '\U000004f7'),
+    ('\U000004f9', '\U000004f9'),
+    ('\U000004fb', '\U000004fb'),
+    ('\U000004fd', '\U000004fd'),
+    ('\U000004ff', '\U000004ff'),
+    ('\U00000501', '\U00000501'),
+    ('\U00000503', '\U00000503'),
+    ('\U00000505', '\U00000505'),
+    ('\U00000507', '\U00000507'),
+    ('\U00000509', '\U00000509'),
+    ('\U0000050b', '\U0000050b'),
+    ('\U0000050d', '\U0000050d'),
+    ('\U0000050f', '\U0000050f'),
+    ('\U00000511', '\U00000511'),
+    ('\U00000513', '\U00000513'),
+    ('\U00000515', '\U00000515'),
+    ('\U00000517', '\U00000517'),
+    ('\U00000519', '\U00000519'),
+    ('\U0000051b', '\U0000051b'),
+    ('\U0000051d', '\U0000051d'),
+    ('\U0000051f', '\U0000051f'),
+    ('\U00000521', '\U00000521'),
+    ('\U00000523', '\U00000523'),
+    ('\U00000525', '\U00000525'),
+    ('\U00000527', '\U00000527'),
+    ('\U00000529', '\U00000529'),
+    ('\U0000052b', '\U0000052b'),
+    ('\U0000052d', '\U0000052d'),
+    ('\U0000052f', '\U0000052f'),
+    ('\U00000560', '\U00000588'),
+    ('\U000010d0', '\U000010fa'),
+    ('\U000010fd', '\U000010ff'),
+    ('\U000013f8', '\U000013fd'),
+    ('\U00001c80', '\U00001c88'),
+    ('\U00001d00', '\U00001dbf'),
+    ('\U00001e01', '\U00001e01'),
+    ('\U00001e03', '\U00001e03'),
+    ('\U00001e05', '\U00001e05'),
+    ('\U00001e07', '\U00001e07'),
+    ('\U00001e09', '\U00001e09'),
+    ('\U00001e0b', '\U00001e0b'),
+    ('\U00001e0d', '\U00001e0d'),
+    ('\U00001e0f', '\U00001e0f'),
+    ('\U00001e11', '\U00001e11'),
+    ('\U00001e13', '\U00001e13'),
+    ('\U00001e15', '\U00001e15'),
+    ('\U00001e17', '\U00001e17'),
+    ('\U00001e19', '\U00001e19'),
+    ('\U00001e1b', '\U00001e1b'),
+    ('\U00001e1d', '\U00001e1d'),
+    ('\U00001e1f', '\U00001e1f'),
+    ('\U00001e21', '\U00001e21'),
+    ('\U00001e23', '\U00001e23'),
+    ('\U00001e25', '\U00001e25'),
+    ('\U00001e27', '\U00001e27'),
+    ('\U00001e29', '\U00001e29'),
+    ('\U00001e2b', '\U00001e2b'),
+    ('\U00001e2d', '\U00001e2d'),
+    ('\U00001e2f', '\U00001e2f'),
+    ('\U00001e31', '\U00001e31'),
+    ('\U00001e33', '\U00001e33'),
+    ('\U00001e35', '\U00001e35'),
+    ('\U00001e37', '\U00001e37'),
+    ('\U00001e39', '\U00001e39'),
+    ('\U00001e3b', '\U00001e3b'),
+    ('\U00001e3d', '\U00001e3d'),
+    ('\U00001e3f', '\U00001e3f'),
+    ('\U00001e41', '\U00001e41'),
+    ('\U00001e43', '\U00001e43'),
+    ('\U00001e45', '\U00001e45'),
+    ('\U00001e47', '\U00001e47'),
+    ('\U00001e49', '\U00001e49'),
+    ('\U00001e4b', '\U00001e4b'),
+    ('\U00001e4d', '\U00001e4d'),
+    ('\U00001e4f', '\U00001e4f'),
+    ('\U00001e51', '\U00001e51'),
+    ('\U00001e53', '\U00001e53'),
+    ('\U00001e55', '\U00001e55'),
+    ('\U00001e57', '\U00001e57'),
+    ('\U00001e59', '\U00001e59'),
+    ('\U00001e5b', '\U00001e5b'),
+    ('\U00001e5d', '\U00001e5d'),
+    ('\U00001e5f', '\U00001e5f'),
+    ('\U00001e61', '\U00001e61'),
+    ('\U00001e63', '\U00001e63'),
+    ('\U00001e65', '\U00001e65'),
+    ('\U00001e67', '\U00001e67'),
+    ('\U00001e69', '\U00001e69'),
+    ('\U00001e6b', '\U00001e6b'),
+    ('\U00001e6d', '\U00001e6d'),
+    ('\U00001e6f', '\U00001e6f'),
+    ('\U00001e71', '\U00001e71'),
+    ('\U00001e73', '\U00001e73'),
+    ('\U00001e75', '\U00001e75'),
+    ('\U00001e77', '\U00001e77'),
+    ('\U00001e79', '\U00001e79'),
+    ('\U00001e7b', '\U00001e7b'),
+    ('\U00001e7d', '\U00001e7d'),
+    ('\U00001e7f', '\U00001e7f'),
+    ('\U00001e81', '\U00001e81'),
+    ('\U00001e83', '\U00001e83'),
+    ('\U00001e85', '\U00001e85'),
+    ('\U00001e87', '\U00001e87'),
+    ('\U00001e89', '\U00001e89'),
+    ('\U00001e8b', '\U00001e8b'),
+    ('\U00001e8d', '\U00001e8d'),
+    ('\U00001e8f', '\U00001e8f'),
+    ('\U00001e91', '\U00001e91'),
+    ('\U00001e93', '\U00001e93'),
+    ('\U00001e95', '\U00001e9d'),
+    ('\U00001e9f', '\U00001e9f'),
+    ('\U00001ea1', '\U00001ea1'),
+    ('\U00001ea3', '\U00001ea3'),
+    ('\U00001ea5', '\U00001ea5'),
+    ('\U00001ea7', '\U00001ea7'),
+    ('\U00001ea9', '\U00001ea9'),
+    ('\U00001eab', '\U00001eab'),
+    ('\U00001ead', '\U00001ead'),
+    ('\U00001eaf', '\U00001eaf'),
+    ('\U00001eb1', '\U00001eb1'),
+    ('\U00001eb3', '\U00001eb3'),
+    ('\U00001eb5', '\U00001eb5'),
+    ('\U00001eb7', '\U00001eb7'),
+    ('\U00001eb9', '\U00001eb9'),
+    ('\U00001ebb', '\U00001ebb'),
+    ('\U00001ebd', '\U00001ebd'),
+    ('\U00001ebf', '\U00001ebf'),
+    ('\U00001ec1', '\U00001ec1'),
+    ('\U00001ec3', '\U00001ec3'),
+    ('\U00001ec5', '\U00001ec5'),
+    ('\U00001ec7', '\U00001ec7'),
+    ('\U00001ec9', '\U00001ec9'),
+    ('\U00001ecb', '\U00001ecb'),
+    ('\U00001ecd', '\U00001ecd'),
+    ('\U00001ecf', '\U00001ecf'),
+    ('\U00001ed1', '\U00001ed1'),
+    ('\U00001ed3', '\U00001ed3'),
+    ('\U00001ed5', '\U00001ed5'),
+    ('\U00001ed7', '\U00001ed7'),
+    ('\U00001ed9', '\U00001ed9'),
+    ('\U00001edb', '\U00001edb'),
+    ('\U00001edd', '\U00001edd'),
+    ('\U00001edf', '\U00001edf'),
+    ('\U00001ee1', '\U00001ee1'),
+    ('\U00001ee3', '\U00001ee3'),
+    ('\U00001ee5', '\U00001ee5'),
+    ('\U00001ee7', '\U00001ee7'),
+    ('\U00001ee9', '\U00001ee9'),
+    ('\U00001eeb', '\U00001eeb'),
+    ('\U00001eed', '\U00001eed'),
+    ('\U00001eef', '\U00001eef'),
+    ('\U00001ef1', '\U00001ef1'),
+    ('\U00001ef3', '\U00001ef3'),
+    ('\U00001ef5', '\U00001ef5'),
+    ('\U00001ef7', '\U00001ef7'),
+    ('\U00001ef9', '\U00001ef9'),
+    ('\U00001efb', '\U00001efb'),
+    ('\U00001efd', '\U00001efd'),
+    ('\U00001eff', '\U00001f07'),
+    ('\U00001f10', '\U00001f15'),
+    ('\U00001f20', '\U00001f27'),
+    ('\U00001f30', '\U00001f37'),
+    ('\U00001f40', '\U00001f45'),
+    ('\U00001f50', '\U00001f57'),
+    ('\U00001f60', '\U00001f67'),
+    ('\U00001f70', '\U00001f7d'),
+    ('\U00001f80', '\U00001f87'),
+    ('\U00001f90', '\U00001f97'),
+    ('\U00001fa0', '\U00001fa7'),
+    ('\U00001fb0', '\U00001fb4'),
+    ('\U00001fb6', '\U00001fb7'),
+    ('\U00001fbe', '\U00001fbe'),
+    ('\U00001fc2', '\U00001fc4'),
+    ('\U00001fc6', '\U00001fc7'),
+    ('\U00001fd0', '\U00001fd3'),
+    ('\U00001fd6', '\U00001fd7'),
+    ('\U00001fe0', '\U00001fe7'),
+    ('\U00001ff2', '\U00001ff4'),
+    ('\U00001ff6', '\U00001ff7'),
+    ('\U00002071', '\U00002071'),
+    ('\U0000207f', '\U0000207f'),
+    ('\U00002090', '\U0000209c'),
+    ('\U0000210a', '\U0000210a'),
+    ('\U0000210e', '\U0000210f'),
+    ('\U00002113', '\U00002113'),
+    ('\U0000212f', '\U0000212f'),
+    ('\U00002134', '\U00002134'),
+    ('\U00002139', '\U00002139'),
+    ('\U0000213c', '\U0000213d'),
+    ('\U00002146', '\U00002149'),
+    ('\U0000214e', '\U0000214e'),
+    ('\U00002170', '\U0000217f'),
+    ('\U00002184', '\U00002184'),
+    ('\U000024d0', '\U000024e9'),
+    ('\U00002c30', '\U00002c5f'),
+    ('\U00002c61', '\U00002c61'),
+    ('\U00002c65', '\U00002c66'),
+    ('\U00002c68', '\U00002c68'),
+    ('\U00002c6a', '\U00002c6a'),
+    ('\U00002c6c', '\U00002c6c'),
+    ('\U00002c71', '\U00002c71'),
+    ('\U00002c73', '\U00002c74'),
+    ('\U00002c76', '\U00002c7d'),
+    ('\U00002c81', '\U00002c81'),
+    ('\U00002c83', '\U00002c83'),
+    ('\U00002c85', '\U00002c85'),
+    ('\U00002c87', '\U00002c87'),
+    ('\U00002c89', '\U00002c89'),
+    ('\U00002c8b', '\U00002c8b'),
+    ('\U00002c8d', '\U00002c8d'),
+    ('\U00002c8f', '\U00002c8f'),
+    ('\U00002c91', '\U00002c91'),
+    ('\U00002c93', '\U00002c93'),
+    ('\U00002c95', '\U00002c95'),
+    ('\U00002c97', '\U00002c97'),
+    ('\U00002c99', '\U00002c99'),
+    ('\U00002c9b', '\U00002c9b'),
+    ('\U00002c9d', '\U00002c9d'),
+    ('\U00002c9f', '\U00002c9f'),
+    ('\U00002ca1', '\U00002ca1'),
+    ('\U00002ca3', '\U00002ca3'),
+    ('\U00002ca5', '\U00002ca5'),
+    ('\U00002ca7', '\U00002ca7'),
+    ('\U00002ca9', '\U00002ca9'),
+    ('\U00002cab', '\U00002cab'),
+    ('\U00002cad', '\U00002cad'),
+    ('\U00002caf', '\U00002caf'),
+    ('\U00002cb1', '\U00002cb1'),
+    ('\U00002cb3', '\U00002cb3'),
+    ('\U00002cb5', '\U00002cb5'),
+    ('\U00002cb7', '\U00002cb7'),
+    ('\U00002cb9', '\U00002cb9'),
+    ('\U00002cbb', '\U00002cbb'),
+    ('\U00002cbd', '\U00002cbd'),
+    ('\U00002cbf', '\U00002cbf'),
+    ('\U00002cc1', '\U00002cc1'),
+    ('\U00002cc3', '\U00002cc3'),
+    ('\U00002cc5', '\U00002cc5'),
+    ('\U00002cc7', '\U00002cc7'),
+    ('\U00002cc9', '\U00002cc9'),
+    ('\U00002ccb', '\U00002ccb'),
+    ('\U00002ccd', '\U00002ccd'),
+    ('\U00002ccf', '\U00002ccf'),
+    ('\U00002cd1', '\U00002cd1'),
+    ('\U00002cd3', '\U00002cd3'),
+    ('\U00002cd5', '\U00002cd5'),
+    ('\U00002cd7', '\U00002cd7'),
+    ('\U00002cd9', '\U00002cd9'),
+    ('\U00002cdb', '\U00002cdb'),
+    ('\U00002cdd', '\U00002cdd'),
+    ('\U00002cdf', '\U00002cdf'),
+    ('\U00002ce1', '\U00002ce1'),
+    ('\U00002ce3', '\U00002ce4'),
+    ('\U00002cec', '\U00002cec'),
+    ('\U00002cee', '\U00002cee'),
+    ('\U00002cf3', '\U00002cf3'),
+    ('\U00002d00', '\U00002d25'),
+    ('\U00002d27', '\U00002d27'),
+    ('\U00002d2d', '\U00002d2d'),
+    ('\U0000a641', '\U0000a641'),
+    ('\U0000a643', '\U0000a643'),
+    ('\U0000a645', '\U0000a645'),
+    ('\U0000a647', '\U0000a647'),
+    ('\U0000a649', '\U0000a649'),
+    ('\U0000a64b', '\U0000a64b'),
+    ('\U0000a64d', '\U0000a64d'),
+    ('\U0000a64f', '\U0000a64f'),
+    ('\U0000a651', '\U0000a651'),
+    ('\U0000a653', '\U0000a653'),
+    ('\U0000a655', '\U0000a655'),
+    ('\U0000a657', '\U0000a657'),
+    ('\U0000a659', '\U0000a659'),
+    ('\U0000a65b', '\U0000a65b'),
+    ('\U0000a65d', '\U0000a65d'),
+    ('\U0000a65f', '\U0000a65f'),
+    ('\U0000a661', '\U0000a661'),
+    ('\U0000a663', '\U0000a663'),
+    ('\U0000a665', '\U0000a665'),
+    ('\U0000a667', '\U0000a667'),
+    ('\U0000a669', '\U0000a669'),
+    ('\U0000a66b', '\U0000a66b'),
+    ('\U0000a66d', '\U0000a66d'),
+    ('\U0000a681', '\U0000a681'),
+    ('\U0000a683', '\U0000a683'),
+    ('\U0000a685', '\U0000a685'),
+    ('\U0000a687', '\U0000a687'),
+    ('\U0000a689', '\U0000a689'),
+    ('\U0000a68b', '\U0000a68b'),
+    ('\U0000a68d', '\U0000a68d'),
+    ('\U0000a68f', '\U0000a68f'),
+    ('\U0000a691', '\U0000a691'),
+    ('\U0000a693', '\U0000a693'),
+    ('\U0000a695', '\U0000a695'),
+    ('\U0000a697', '\U0000a697'),
+    ('\U0000a699', '\U0000a699'),
+    ('\U0000a69b', '\U0000a69d'),
+    ('\U0000a723', '\U0000a723'),
+    ('\U0000a725', '\U0000a725'),
+    ('\U0000a727', '\U0000a727'),
+    ('\U0000a729', '\U0000a729'),
+    ('\U0000a72b', '\U0000a72b'),
+    ('\U0000a72d', '\U0000a72d'),
+    ('\U0000a72f', '\U0000a731'),
+    ('\U0000a733', '\U0000a733'),
+    ('\U0000a735', '\U0000a735'),
+    ('\U0000a737', '\U0000a737'),
+    ('\U0000a739', '\U0000a739'),
+    ('\U0000a73b', '\U0000a73b'),
+    ('\U0000a73d', '\U0000a73d'),
+    ('\U0000a73f', '\U0000a73f'),
+    ('\U0000a741', '\U0000a741'),
+    ('\U0000a743', '\U0000a743'),
+    ('\U0000a745', '\U0000a745'),
+    ('\U0000a747', '\U0000a747'),
+    ('\U0000a749', '\U0000a749'),
+    ('\U0000a74b', '\U0000a74b'),
+    ('\U0000a74d', '\U0000a74d'),
+    ('\U0000a74f', '\U0000a74f'),
+    ('\U0000a751', '\U0000a751'),
+    ('\U0000a753', '\U0000a753'),
+    ('\U0000a755', '\U0000a755'),
+    ('\U0000a757', '\U0000a757'),
+    ('\U0000a759', '\U0000a759'),
+    ('\U0000a75b', '\U0000a75b'),
+    ('\U0000a75d', '\U0000a75d'),
+    ('\U0000a75f', '\U0000a75f'),
+    ('\U0000a761', '\U0000a761'),
+    ('\U0000a763', '\U0000a763'),
+    ('\U0000a765', '\U0000a765'),
+    ('\U0000a767', '\U0000a767'),
+    ('\U0000a769', '\U0000a769'),
+    ('\U0000a76b', '\U0000a76b'),
+    ('\U0000a76d', '\U0000a76d'),
+    ('\U0000a76f', '\U0000a778'),
+    ('\U0000a77a', '\U0000a77a'),
+    ('\U0000a77c', '\U0000a77c'),
+    ('\U0000a77f', '\U0000a77f'),
+    ('\U0000a781', '\U0000a781'),
+    ('\U0000a783', '\U0000a783'),
+    ('\U0000a785', '\U0000a785'),
+    ('\U0000a787', '\U0000a787'),
+    ('\U0000a78c', '\U0000a78c'),
+    ('\U0000a78e', '\U0000a78e'),
+    ('\U0000a791', '\U0000a791'),
+    ('\U0000a793', '\U0000a795'),
+    ('\U0000a797', '\U0000a797'),
+    ('\U0000a799', '\U0000a799'),
+    ('\U0000a79b', '\U0000a79b'),
+    ('\U0000a79d', '\U0000a79d'),
+    ('\U0000a79f', '\U0000a79f'),
+    ('\U0000a7a1', '\U0000a7a1'),
+    ('\U0000a7a3', '\U0000a7a3'),
+    ('\U0000a7a5', '\U0000a7a5'),
+    ('\U0000a7a7', '\U0000a7a7'),
+    ('\U0000a7a9', '\U0000a7a9'),
+    ('\U0000a7af', '\U0000a7af'),
+    ('\U0000a7b5', '\U0000a7b5'),
+    ('\U0000a7b7', '\U0000a7b7'),
+    ('\U0000a7b9', '\U0000a7b9'),
+    ('\U0000a7bb', '\U0000a7bb'),
+    ('\U0000a7bd', '\U0000a7bd'),
+    ('\U0000a7bf', '\U0000a7bf'),
+    ('\U0000a7c1', '\U0000a7c1'),
+    ('\U0000a7c3', '\U0000a7c3'),
+    ('\U0000a7c8', '\U0000a7c8'),
+    ('\U0000a7ca', '\U0000a7ca'),
+    ('\U0000a7d1', '\U0000a7d1'),
+    ('\U0000a7d3', '\U0000a7d3'),
+    ('\U0000a7d5', '\U0000a7d5'),
+    ('\U0000a7d7', '\U0000a7d7'),
+    ('\U0000a7d9', '\U0000a7d9'),
+    ('\U0000a7f6', '\U0000a7f6'),
+    ('\U0000a7f8', '\U0000a7fa'),
+    ('\U0000ab30', '\U0000ab5a'),
+    ('\U0000ab5c', '\U0000ab68'),
+    ('\U0000ab70', '\U0000abbf'),
+    ('\U0000fb00', '\U0000fb06'),
+    ('\U0000fb13', '\U0000fb17'),
+    ('\U0000ff41', '\U0000ff5a'),
+    ('\U00010428', '\U0001044f'),
+    ('\U000104d8', '\U000104fb'),
+    ('\U00010597', '\U000105a1'),
+    ('\U000105a3', '\U000105b1'),
+    ('\U000105b3', '\U000105b9'),
+    ('\U000105bb', '\U000105bc'),
+    ('\U00010780', '\U00010780'),
+    ('\U00010783', '\U00010785'),
+    ('\U00010787', '\U000107b0'),
+    ('\U000107b2', '\U000107ba'),
+    ('\U00010cc0', '\U00010cf2'),
+    ('\U000118c0', '\U000118df'),
+    ('\U00016e60', '\U00016e7f'),
+    ('\U0001d41a', '\U0001d433'),
+    ('\U0001d44e', '\U0001d454'),
+    ('\U0001d456', '\U0001d467'),
+    ('\U0001d482', '\U0001d49b'),
+    ('\U0001d4b6', '\U0001d4b9'),
+    ('\U0001d4bb', '\U0001d4bb'),
+    ('\U0001d4bd', '\U0001d4c3'),
+    ('\U0001d4c5', '\U0001d4cf'),
+    ('\U0001d4ea', '\U0001d503'),
+    ('\U0001d51e', '\U0001d537'),
+    ('\U0001d552', '\U0001d56b'),
+    ('\U0001d586', '\U0001d59f'),
+    ('\U0001d5ba', '\U0001d5d3'),
+    ('\U0001d5ee', '\U0001d607'),
+    ('\U0001d622', '\U0001d63b'),
+    ('\U0001d656', '\U0001d66f'),
+    ('\U0001d68a', '\U0001d6a5'),
+    ('\U0001d6c2', '\U0001d6da'),
+    ('\U0001d6dc', '\U0001d6e1'),
+    ('\U0001d6fc', '\U0001d714'),
+    ('\U0001d716', '\U0001d71b'),
+    ('\U0001d736', '\U0001d74e'),
+    ('\U0001d750', '\U0001d755'),
+    ('\U0001d770', '\U0001d788'),
+    ('\U0001d78a', '\U0001d78f'),
+    ('\U0001d7aa', '\U0001d7c2'),
+    ('\U0001d7c4', '\U0001d7c9'),
+    ('\U0001d7cb', '\U0001d7cb'),
+    ('\U0001df00', '\U0001df09'),
+    ('\U0001df0b', '\U0001df1e'),
+    ('\U0001e922', '\U0001e943')
+    ]),
+
+("Math", &[
+    ('\U0000002b', '\U0000002b'),
+    ('\U0000003c', '\U0000003e'),
+    ('\U0000005e', '\U0000005e'),
+    ('\U0000007c', '\U0000007c'),
+    ('\U0000007e', '\U0000007e'),
+    ('\U000000ac', '\U000000ac'),
+    ('\U000000b1', '\U000000b1'),
+    ('\U000000d7', '\U000000d7'),
+    ('\U000000f7', '\U000000f7'),
+    ('\U000003d0', '\U000003d2'),
+    ('\U000003d5', '\U000003d5'),
+    ('\U000003f0', '\U000003f1'),
+    ('\U000003f4', '\U000003f6'),
+    ('\U00000606', '\U00000608'),
+    ('\U00002016', '\U00002016'),
+    ('\U00002032', '\U00002034'),
+    ('\U00002040', '\U00002040'),
+    ('\U00002044', '\U00002044'),
+    ('\U00002052', '\U00002052'),
+    ('\U00002061', '\U00002064'),
+    ('\U0000207a', '\U0000207e'),
+    ('\U0000208a', '\U0000208e'),
+    ('\U000020d0', '\U000020dc'),
+    ('\U000020e1', '\U000020e1'),
+    ('\U000020e5', '\U000020e6'),
+    ('\U000020eb', '\U000020ef'),
+    ('\U00002102', '\U00002102'),
+    ('\U00002107', '\U00002107'),
+    ('\U0000210a', '\U00002113'),
+    ('\U00002115', '\U00002115'),
+    ('\U00002118', '\U0000211d'),
+    ('\U00002124', '\U00002124'),
+    ('\U00002128', '\U00002129'),
+    ('\U0000212c', '\U0000212d'),
+    ('\U0000212f', '\U00002131'),
+    ('\U00002133', '\U00002138'),
+    ('\U0000213c', '\U00002149'),
+    ('\U0000214b', '\U0000214b'),
+    ('\U00002190', '\U000021a7'),
+    ('\U000021a9', '\U000021ae'),
+    ('\U000021b0', '\U000021b1'),
+    ('\U000021b6', '\U000021b7'),
+    ('\U000021bc', '\U000021db'),
+    ('\U000021dd', '\U000021dd'),
+    ('\U000021e4', '\U000021e5'),
+    ('\U000021f4', '\U000022ff'),
+    ('\U00002308', '\U0000230b'),
+    ('\U00002320', '\U00002321'),
+    ('\U0000237c', '\U0000237c'),
+    ('\U0000239b', '\U000023b5'),
+    ('\U000023b7', '\U000023b7'),
+    ('\U000023d0', '\U000023d0'),
+    ('\U000023dc', '\U000023e2'),
+    ('\U000025a0', '\U000025a1'),
+    ('\U000025ae', '\U000025b7'),
+    ('\U000025bc', '\U000025c1'),
+    ('\U000025c6', '\U000025c7'),
+    ('\U000025ca', '\U000025cb'),
+    ('\U000025cf', '\U000025d3'),
+    ('\U000025e2', '\U000025e2'),
+    ('\U000025e4', '\U000025e4'),
+    ('\U000025e7', '\U000025ec'),
+    ('\U000025f8', '\U000025ff'),
+    ('\U00002605', '\U00002606'),
+    ('\U00002640', '\U00002640'),
+    ('\U00002642', '\U00002642'),
+    ('\U00002660', '\U00002663'),
+    ('\U0000266d', '\U0000266f'),
+    ('\U000027c0', '\U000027ff'),
+    ('\U00002900', '\U00002aff'),
+    ('\U00002b30', '\U00002b44'),
+    ('\U00002b47', '\U00002b4c'),
+    ('\U0000fb29', '\U0000fb29'),
+    ('\U0000fe61', '\U0000fe66'),
+    ('\U0000fe68', '\U0000fe68'),
+    ('\U0000ff0b', '\U0000ff0b'),
+    ('\U0000ff1c', '\U0000ff1e'),
+    ('\U0000ff3c', '\U0000ff3c'),
+    ('\U0000ff3e', '\U0000ff3e'),
+    ('\U0000ff5c', '\U0000ff5c'),
+    ('\U0000ff5e', '\U0000ff5e'),
+    ('\U0000ffe2', '\U0000ffe2'),
+    ('\U0000ffe9', '\U0000ffec'),
+    ('\U0001d400', '\U0001d454'),
+    ('\U0001d456', '\U0001d49c'),
+    ('\U0001d49e', '\U0001d49f'),
+    ('\U0001d4a2', '\U0001d4a2'),
+    ('\U0001d4a5', '\U0001d4a6'),
+    ('\U0001d4a9', '\U0001d4ac'),
+    ('\U0001d4ae', '\U0001d4b9'),
+    ('\U0001d4bb', '\U0001d4bb'),
+    ('\U0001d4bd', '\U0001d4c3'),
+    ('\U0001d4c5', '\U0001d505'),
+    ('\U0001d507', '\U0001d50a'),
+    ('\U0001d50d', '\U0001d514'),
+    ('\U0001d516', '\U0001d51c'),
+    ('\U0001d51e', '\U0001d539'),
+    ('\U0001d53b', '\U0001d53e'),
+    ('\U0001d540', '\U0001d544'),
+    ('\U0001d546', '\U0001d546'),
+    ('\U0001d54a', '\U0001d550'),
+    ('\U0001d552', '\U0001d6a5'),
+    ('\U0001d6a8', '\U0001d7cb'),
+    ('\U0001d7ce', '\U0001d7ff'),
+    ('\U0001ee00', '\U0001ee03'),
+    ('\U0001ee05', '\U0001ee1f'),
+    ('\U0001ee21', '\U0001ee22'),
+    ('\U0001ee24', '\U0001ee24'),
+    ('\U0001ee27', '\U0001ee27'),
+    ('\U0001ee29', '\U0001ee32'),
+    ('\U0001ee34', '\U0001ee37'),
+    ('\U0001ee39', '\U0001ee39'),
+    ('\U0001ee3b', '\U0001ee3b'),
+    ('\U0001ee42', '\U0001ee42'),
+    ('\U0001ee47', '\U0001ee47'),
+    ('\U0001ee49', '\U0001ee49'),
+    ('\U0001ee4b', '\U0001ee4b'),
+    ('\U0001ee4d', '\U0001ee4f'),
+    ('\U0001ee51', '\U0001ee52'),
+    ('\U0001ee54', '\U0001ee54'),
+    ('\U0001ee57', '\U0001ee57'),
+    ('\U0001ee59', '\U0001ee59'),
+    ('\U0001ee5b', '\U0001ee5b'),
+    ('\U0001ee5d', '\U0001ee5d'),
+    ('\U0001ee5f', '\U0001ee5f'),
+    ('\U0001ee61', '\U0001ee62'),
+    ('\U0001ee64', '\U0001ee64'),
+    ('\U0001ee67', '\U0001ee6a'),
+    ('\U0001ee6c', '\U0001ee72'),
+    ('\U0001ee74', '\U0001ee77'),
+    ('\U0001ee79', '\U0001ee7c'),
+    ('\U0001ee7e', '\U0001ee7e'),
+    ('\U0001ee80', '\U0001ee89'),
+    ('\U0001ee8b', '\U0001ee9b'),
+    ('\U0001eea1', '\U0001eea3'),
+    ('\U0001eea5', '\U0001eea9'),
+    ('\U0001eeab', '\U0001eebb'),
+    ('\U0001eef0', '\U0001eef1')
+    ]),
+
+("Noncharacter_Code_Point", &[
+    ('\U0000fdd0', '\U0000fdef'),
+    ('\U0000fffe', '\U0000ffff'),
+    ('\U0001fffe', '\U0001ffff'),
+    ('\U0002fffe', '\U0002ffff'),
+    ('\U0003fffe', '\U0003ffff'),
+    ('\U0004fffe', '\U0004ffff'),
+    ('\U0005fffe', '\U0005ffff'),
+    ('\U0006fffe', '\U0006ffff'),
+    ('\U0007fffe', '\U0007ffff'),
+    ('\U0008fffe', '\U0008ffff'),
+    ('\U0009fffe', '\U0009ffff'),
+    ('\U000afffe', '\U000affff'),
+    ('\U000bfffe', '\U000bffff'),
+    ('\U000cfffe', '\U000cffff'),
+    ('\U000dfffe', '\U000dffff'),
+    ('\U000efffe', '\U000effff'),
+    ('\U000ffffe', '\U000fffff'),
+    ('\U0010fffe', '\U0010ffff')
+    ]),
+
+("Uppercase", &[
+    ('\U00000041', '\U0000005a'),
+    ('\U000000c0', '\U000000d6'),
+    ('\U000000d8', '\U000000de'),
+    ('\U00000100', '\U00000100'),
+    ('\U00000102', '\U00000102'),
+    ('\U00000104', '\U00000104'),
+    ('\U00000106', '\U00000106'),
+    ('\U00000108', '\U00000108'),
+    ('\U0000010a', '\U0000010a'),
+    ('\U0000010c', '\U0000010c'),
+    ('\U0000010e', '\U0000010e'),
+    ('\U00000110', '\U00000110'),
+    ('\U00000112', '\U00000112'),
+    ('\U00000114', '\U00000114'),
+    ('\U00000116', '\U00000116'),
+    ('\U00000118', '\U00000118'),
+    ('\U0000011a', '\U0000011a'),
+    ('\U0000011c', '\U0000011c'),
+    ('\U0000011e', '\U0000011e'),
+    ('\U00000120', '\U00000120'),
+    ('\U00000122', '\U00000122'),
+    ('\U00000124', '\U00000124'),
+    ('\U00000126', '\U00000126'),
+    ('\U00000128', '\U00000128'),
+    ('\U0000012a', '\U0000012a'),
+    ('\U0000012c', '\U0000012c'),
+    ('\U0000012e', '\U0000012e'),
+    ('\U00000130', '\U00000130'),
+    ('\U00000132', '\U00000132'),
+    ('\U00000134', '\U00000134'),
+    ('\U00000136', '\U00000136'),
+    ('\U00000139', '\U00000139'),
+    ('\U0000013b', '\U0000013b'),
+    ('\U0000013d', '\U0000013d'),
+    ('\U0000013f', '\U0000013f'),
+    ('\U00000141', '\U00000141'),
+    ('\U00000143', '\U00000143'),
+    ('\U00000145', '\U00000145'),
+    ('\U00000147', '\U00000147'),
+    ('\U0000014a', '\U0000014a'),
+    ('\U0000014c', '\U0000014c'),
+    ('\U0000014e', '\U0000014e'),
+    ('\U00000150', '\U00000150'),
+    ('\U00000152', '\U00000152'),
+    ('\U00000154', '\U00000154'),
+    ('\U00000156', '\U00000156'),
+    ('\U00000158', '\U00000158'),
+    ('\U0000015a', '\U0000015a'),
+    ('\U0000015c', '\U0000015c'),
+    ('\U0000015e', '\U0000015e'),
+    ('\U00000160', '\U00000160'),
+    ('\U00000162', '\U00000162'),
+    ('\U00000164', '\U00000164'),
+    ('\U00000166', '\U00000166'),
+    ('\U00000168', '\U00000168'),
+    ('\U0000016a', '\U0000016a'),
+    ('\U0000016c', '\U0000016c'),
+    ('\U0000016e', '\U0000016e'),
+    ('\U00000170', '\U00000170'),
+    ('\U00000172', '\U00000172'),
+    ('\U00000174', '\U00000174'),
+    ('\U00000176', '\U00000176'),
+    ('\U00000178', '\U00000179'),
+    ('\U0000017b', '\U0000017b'),
+    ('\U0000017d', '\U0000017d'),
+    ('\U00000181', '\U00000182'),
+    ('\U00000184', '\U00000184'),
+    ('\U00000186', '\U00000187'),
+    ('\U00000189', '\U0000018b'),
+    ('\U0000018e', '\U00000191'),
+    ('\U00000193', '\U00000194'),
+    ('\U00000196', '\U00000198'),
+    ('\U0000019c', '\U0000019d'),
+    ('\U0000019f', '\U000001a0'),
+    ('\U000001a2', '\U000001a2'),
+    ('\U000001a4', '\U000001a4'),
+    ('\U000001a6', '\U000001a7'),
+    ('\U000001a9', '\U000001a9'),
+    ('\U000001ac', '\U000001ac'),
+    ('\U000001ae', '\U000001af'),
+    ('\U000001b1', '\U000001b3'),
+    ('\U000001b5', '\U000001b5'),
+    ('\U000001b7', '\U000001b8'),
+    ('\U000001bc', '\U000001bc'),
+    ('\U000001c4', '\U000001c4'),
+    ('\U000001c7', '\U000001c7'),
+    ('\U000001ca', '\U000001ca'),
+    ('\U000001cd', '\U000001cd'),
+    ('\U000001cf', '\U000001cf'),
+    ('\U000001d1', '\U000001d1'),
+    ('\U000001d3', '\U000001d3'),
+    ('\U000001d5', '\U000001d5'),
+    ('\U000001d7', '\U000001d7'),
+    ('\U000001d9', '\U000001d9'),
+    ('\U000001db', '\U000001db'),
+    ('\U000001de', '\U000001de'),
+    ('\U000001e0', '\U000001e0'),
+    ('\U000001e2', '\U000001e2'),
+    ('\U000001e4', '\U000001e4'),
+    ('\U000001e6', '\U000001e6'),
+    ('\U000001e8', '\U000001e8'),
+    ('\U000001ea', '\U000001ea'),
+    ('\U000001ec', '\U000001ec'),
+    ('\U000001ee', '\U000001ee'),
+    ('\U000001f1', '\U000001f1'),
+    ('\U000001f4', '\U000001f4'),
+    ('\U000001f6', '\U000001f8'),
+    ('\U000001fa', '\U000001fa'),
+    ('\U000001fc', '\U000001fc'),
+    ('\U000001fe', '\U000001fe'),
+    ('\U00000200', '\U00000200'),
+    ('\U00000202', '\U00000202'),
+    ('\U00000204', '\U00000204'),
+    ('\U00000206', '\U00000206'),
+    ('\U00000208', '\U00000208'),
+    ('\U0000020a', '\U0000020a'),
+    ('\U0000020c', '\U0000020c'),
+    ('\U0000020e', '\U0000020e'),
+    ('\U00000210', '\U00000210'),
+    ('\U00000212', '\U00000212'),
+    ('\U00000214', '\U00000214'),
+    ('\U00000216', '\U00000216'),
+    ('\U00000218', '\U00000218'),
+    ('\U0000021a', '\U0000021a'),
+    ('\U0000021c', '\U0000021c'),
+    ('\U0000021e', '\U0000021e'),
+    ('\U00000220', '\U00000220'),
+    ('\U00000222', '\U00000222'),
+    ('\U00000224', '\U00000224'),
+    ('\U00000226', '\U00000226'),
+    ('\U00000228', '\U00000228'),
+    ('\U0000022a', '\U0000022a'),
+    ('\U0000022c', '\U0000022c'),
+    ('\U0000022e', '\U0000022e'),
+    ('\U00000230', '\U00000230'),
+    ('\U00000232', '\U00000232'),
+    ('\U0000023a', '\U0000023b'),
+    ('\U0000023d', '\U0000023e'),
+    ('\U00000241', '\U00000241'),
+    ('\U00000243', '\U00000246'),
+    ('\U00000248', '\U00000248'),
+    ('\U0000024a', '\U0000024a'),
+    ('\U0000024c', '\U0000024c'),
+    ('\U0000024e', '\U0000024e'),
+    ('\U00000370', '\U00000370'),
+    ('\U00000372', '\U00000372'),
+    ('\U00000376', '\U00000376'),
+    ('\U0000037f', '\U0000037f'),
+    ('\U00000386', '\U00000386'),
+    ('\U00000388', '\U0000038a'),
+    ('\U0000038c', '\U0000038c'),
+    ('\U0000038e', '\U0000038f'),
+    ('\U00000391', '\U000003a1'),
+    ('\U000003a3', '\U000003ab'),
+    ('\U000003cf', '\U000003cf'),
+    ('\U000003d2', '\U000003d4'),
+    ('\U000003d8', '\U000003d8'),
+    ('\U000003da', '\U000003da'),
+    ('\U000003dc', '\U000003dc'),
+    ('\U000003de', '\U000003de'),
+    ('\U000003e0', '\U000003e0'),
+    ('\U000003e2', '\U000003e2'),
+    ('\U000003e4', '\U000003e4'),
+    ('\U000003e6', '\U000003e6'),
+    ('\U000003e8', '\U000003e8'),
+    ('\U000003ea', '\U000003ea'),
+    ('\U000003ec', '\U000003ec'),
+    ('\U000003ee', '\U000003ee'),
+    ('\U000003f4', '\U000003f4'),
+    ('\U000003f7', '\U000003f7'),
+    ('\U000003f9', '\U000003fa'),
+    ('\U000003fd', '\U0000042f'),
+    ('\U00000460', '\U00000460'),
+    ('\U00000462', '\U00000462'),
+    ('\U00000464', '\U00000464'),
+    ('\U00000466', '\U00000466'),
+    ('\U00000468', '\U00000468'),
+    ('\U0000046a', '\U0000046a'),
+    ('\U0000046c', '\U0000046c'),
+    ('\U0000046e', '\U0000046e'),
+    ('\U00000470', '\U00000470'),
+    ('\U00000472', '\U00000472'),
+    ('\U00000474', '\U00000474'),
+    ('\U00000476', '\U00000476'),
+    ('\U00000478', '\U00000478'),
+    ('\U0000047a', '\U0000047a'),
+    ('\U0000047c', '\U0000047c'),
+    ('\U0000047e', '\U0000047e'),
+    ('\U00000480', '\U00000480'),
+    ('\U0000048a', '\U0000048a'),
+    ('\U0000048c', '\U0000048c'),
+    ('\U0000048e', '\U0000048e'),
+    ('\U00000490', '\U00000490'),
+    ('\U00000492', '\U00000492'),
+    ('\U00000494', '\U00000494'),
+    ('\U00000496', '\U00000496'),
+    ('\U00000498', '\U00000498'),
+    ('\U0000049a', '\U0000049a'),
+    ('\U0000049c', '\U0000049c'),
+    ('\U0000049e', '\U0000049e'),
+    ('\U000004a0', '\U000004a0'),
+    ('\U000004a2', '\U000004a2'),
+    ('\U000004a4', '\U000004a4'),
+    ('\U000004a6', '\U000004a6'),
+    ('\U000004a8', '\U000004a8'),
+    ('\U000004aa', '\U000004aa'),
+    ('\U000004ac', '\U000004ac'),
+    ('\U000004ae', '\U000004ae'),
+    ('\U000004b0', '\U000004b0'),
+    ('\U000004b2', '\U000004b2'),
+    ('\U000004b4', '\U000004b4'),
+    ('\U000004b6', '\U000004b6'),
+    ('\U000004b8', '\U000004b8'),
+    ('\U000004ba', '\U000004ba'),
+    ('\U000004bc', '\U000004bc'),
+    ('\U000004be', '\U000004be'),
+    ('\U000004c0', '\U000004c1'),
+    ('\U000004c3', '\U000004c3'),
+    ('\U000004c5', '\U000004c5'),
+    ('\U000004c7', '\U000004c7'),
+    ('\U000004c9', '\U000004c9'),
+    ('\U000004cb', '\U000004cb'),
+    ('\U000004cd', '\U000004cd'),
+    ('\U000004d0', '\U000004d0'),
+    ('\U000004d2', '\U000004d2'),
+    ('\U000004d4', '\U000004d4'),
+    ('\U000004d6', '\U000004d6'),
+    ('\U000004d8', '\U000004d8'),
+    ('\U000004da', '\U000004da'),
+    ('\U000004dc', '\U000004dc'),
+    ('\U000004de', '\U000004de'),
+    ('\U000004e0', '\U000004e0'),
+    ('\U000004e2', '\U000004e2'),
+    ('\U000004e4', '\U000004e4'),
+    ('\U000004e6', '\U000004e6'),
+    ('\U000004e8', '\U000004e8'),
+    ('\U000004ea', '\U000004ea'),
+    ('\U000004ec', '\U000004ec'),
+    ('\U000004ee', '\U000004ee'),
+    ('\U000004f0', '\U000004f0'),
+    ('\U000004f2', '\U000004f2'),
+    ('\U000004f4', '\U000004f4'),
+    ('\U000004f6', '\U000004f6'),
+    ('\U000004f8', '\U000004f8'),
+    ('\U000004fa', '\U000004fa'),
+    ('\U000004fc', '\U000004fc'),
+    ('\U000004fe', '\U000004fe'),
+    ('\U00000500', '\U00000500'),
+    ('\U00000502', '\U00000502'),
+    ('\U00000504', '\U00000504'),
+    ('\U00000506', '\U00000506'),
+    ('\U00000508', '\U00000508'),
+    ('\U0000050a', '\U0000050a'),
+    ('\U0000050c', '\U0000050c'),
+    ('\U0000050e', '\U0000050e'),
+    ('\U00000510', '\U00000510'),
+    ('\U00000512', '\U00000512'),
+    ('\U00000514', '\U00000514'),
+    ('\U00000516', '\U00000516'),
+    ('\U00000518', '\U00000518'),
+    ('\U0000051a', '\U0000051a'),
+    ('\U0000051c', '\U0000051c'),
+    ('\U0000051e', '\U0000051e'),
+    ('\U00000520', '\U00000520'),
+    ('\U00000522', '\U00000522'),
+    ('\U00000524', '\U00000524'),
+    ('\U00000526', '\U00000526'),
+    ('\U00000528', '\U00000528'),
+    ('\U0000052a', '\U0000052a'),
+    ('\U0000052c', '\U0000052c'),
+    ('\U0000052e', '\U0000052e'),
+    ('\U00000531', '\U00000556'),
+    ('\U000010a0', '\U000010c5'),
+    ('\U000010c7', '\U000010c7'),
+    ('\U000010cd', '\U000010cd'),
+    ('\U000013a0', '\U000013f5'),
+    ('\U00001c90', '\U00001cba'),
+    ('\U00001cbd', '\U00001cbf'),
+    ('\U00001e00', '\U00001e00'),
+    ('\U00001e02', '\U00001e02'),
+    ('\U00001e04', '\U00001e04'),
+    ('\U00001e06', '\U00001e06'),
+    ('\U00001e08', '\U00001e08'),
+    ('\U00001e0a', '\U00001e0a'),
+    ('\U00001e0c', '\U00001e0c'),
+    ('\U00001e0e', '\U00001e0e'),
+    ('\U00001e10', '\U00001e10'),
+    ('\U00001e12', '\U00001e12'),
+    ('\U00001e14', '\U00001e14'),
+    ('\U00001e16', '\U00001e16'),
+    ('\U00001e18', '\U00001e18'),
+    ('\U00001e1a', '\U00001e1a'),
+    ('\U00001e1c', '\U00001e1c'),
+    ('\U00001e1e', '\U00001e1e'),
+    ('\U00001e20', '\U00001e20'),
+    ('\U00001e22', '\U00001e22'),
+    ('\U00001e24', '\U00001e24'),
+    ('\U00001e26', '\U00001e26'),
+    ('\U00001e28', '\U00001e28'),
+    ('\U00001e2a', '\U00001e2a'),
+    ('\U00001e2c', '\U00001e2c'),
+    ('\U00001e2e', '\U00001e2e'),
+    ('\U00001e30', '\U00001e30'),
+    ('\U00001e32', '\U00001e32'),
+    ('\U00001e34', '\U00001e34'),
+    ('\U00001e36', '\U00001e36'),
+    ('\U00001e38', '\U00001e38'),
+    ('\U00001e3a', '\U00001e3a'),
+    ('\U00001e3c', '\U00001e3c'),
+    ('\U00001e3e', '\U00001e3e'),
+    ('\U00001e40', '\U00001e40'),
+    ('\U00001e42', '\U00001e42'),
+    ('\U00001e44', '\U00001e44'),
+    ('\U00001e46', '\U00001e46'),
+    ('\U00001e48', '\U00001e48'),
+    ('\U00001e4a', '\U00001e4a'),
+    ('\U00001e4c', '\U00001e4c'),
+    ('\U00001e4e', '\U00001e4e'),
+    ('\U00001e50', '\U00001e50'),
+    ('\U00001e52', '\U00001e52'),
+    ('\U00001e54', '\U00001e54'),
+    ('\U00001e56', '\U00001e56'),
+    ('\U00001e58', '\U00001e58'),
+    ('\U00001e5a', '\U00001e5a'),
+    ('\U00001e5c', '\U00001e5c'),
+    ('\U00001e5e', '\U00001e5e'),
+    ('\U00001e60', '\U00001e60'),
+    ('\U00001e62', '\U00001e62'),
+    ('\U00001e64', '\U00001e64'),
+    ('\U00001e66', '\U00001e66'),
+    ('\U00001e68', '\U00001e68'),
+    ('\U00001e6a', '\U00001e6a'),
+    ('\U00001e6c', '\U00001e6c'),
+    ('\U00001e6e', '\U00001e6e'),
+    ('\U00001e70', '\U00001e70'),
+    ('\U00001e72', '\U00001e72'),
+    ('\U00001e74', '\U00001e74'),
+    ('\U00001e76', '\U00001e76'),
+    ('\U00001e78', '\U00001e78'),
+    ('\U00001e7a', '\U00001e7a'),
+    ('\U00001e7c', '\U00001e7c'),
+    ('\U00001e7e', '\U00001e7e'),
+    ('\U00001e80', '\U00001e80'),
+    ('\U00001e82', '\U00001e82'),
+    ('\U00001e84', '\U00001e84'),
+    ('\U00001e86', '\U00001e86'),
+    ('\U00001e88', '\U00001e88'),
+    ('\U00001e8a', '\U00001e8a'),
+    ('\U00001e8c', '\U00001e8c'),
+    ('\U00001e8e', '\U00001e8e'),
+    ('\U00001e90', '\U00001e90'),
+    ('\U00001e92', '\U00001e92'),
+    ('\U00001e94', '\U00001e94'),
+    ('\U00001e9e', '\U00001e9e'),
+    ('\U00001ea0', '\U00001ea0'),
+    ('\U00001ea2', '\U00001ea2'),
+    ('\U00001ea4', '\U00001ea4'),
+    ('\U00001ea6', '\U00001ea6'),
+    ('\U00001ea8', '\U00001ea8'),
+    ('\U00001eaa', '\U00001eaa'),
+    ('\U00001eac', '\U00001eac'),
+    ('\U00001eae', '\U00001eae'),
+    ('\U00001eb0', '\U00001eb0'),
+    ('\U00001eb2', '\U00001eb2'),
+    ('\U00001eb4', '\U00001eb4'),
+    ('\U00001eb6', '\U00001eb6'),
+    ('\U00001eb8', '\U00001eb8'),
+    ('\U00001eba', '\U00001eba'),
+    ('\U00001ebc', '\U00001ebc'),
+    ('\U00001ebe', '\U00001ebe'),
+    ('\U00001ec0', '\U00001ec0'),
+    ('\U00001ec2', '\U00001ec2'),
+    ('\U00001ec4', '\U00001ec4'),
+    ('\U00001ec6', '\U00001ec6'),
+    ('\U00001ec8', '\U00001ec8'),
+    ('\U00001eca', '\U00001eca'),
+    ('\U00001ecc', '\U00001ecc'),
+    ('\U00001ece', '\U00001ece'),
+    ('\U00001ed0', '\U00001ed0'),
+    ('\U00001ed2', '\U00001ed2'),
+    ('\U00001ed4', '\U00001ed4'),
+    ('\U00001ed6', '\U00001ed6'),
+    ('\U00001ed8', '\U00001ed8'),
+    ('\U00001eda', '\U00001eda'),
+    ('\U00001edc', '\U00001edc'),
+    ('\U00001ede', '\U00001ede'),
+    ('\U00001ee0', '\U00001ee0'),
+    ('\U00001ee2', '\U00001ee2'),
+    ('\U00001ee4', '\U00001ee4'),
+    ('\U00001ee6', '\U00001ee6'),
+    ('\U00001ee8', '\U00001ee8'),
+    ('\U00001eea', '\U00001eea'),
+    ('\U00001eec', '\U00001eec'),
+    ('\U00001eee', '\U00001eee'),
+    ('\U00001ef0', '\U00001ef0'),
+    ('\U00001ef2', '\U00001ef2'),
+    ('\U00001ef4', '\U00001ef4'),
+    ('\U00001ef6', '\U00001ef6'),
+    ('\U00001ef8', '\U00001ef8'),
+    ('\U00001efa', '\U00001efa'),
+    ('\U00001efc', '\U00001efc'),
+    ('\U00001efe', '\U00001efe'),
+    ('\U00001f08', '\U00001f0f'),
+    ('\U00001f18', '\U00001f1d'),
+    ('\U00001f28', '\U00001f2f'),
+    ('\U00001f38', '\U00001f3f'),
+    ('\U00001f48', '\U00001f4d'),
+    ('\U00001f59', '\U00001f59'),
+    ('\U00001f5b', '\U00001f5b'),
+    ('\U00001f5d', '\U00001f5d'),
+    ('\U00001f5f', '\U00001f5f'),
+    ('\U00001f68', '\U00001f6f'),
+    ('\U00001fb8', '\U00001fbb'),
+    ('\U00001fc8', '\U00001fcb'),
+    ('\U00001fd8', '\U00001fdb'),
+    ('\U00001fe8', '\U00001fec'),
+    ('\U00001ff8', '\U00001ffb'),
+    ('\U00002102', '\U00002102'),
+    ('\U00002107', '\U00002107'),
+    ('\U0000210b', '\U0000210d'),
+    ('\U00002110', '\U00002112'),
+    ('\U00002115', '\U00002115'),
+    ('\U00002119', '\U0000211d'),
+    ('\U00002124', '\U00002124'),
+    ('\U00002126', '\U00002126'),
+    ('\U00002128', '\U00002128'),
+    ('\U0000212a', '\U0000212d'),
+    ('\U00002130', '\U00002133'),
+    ('\U0000213e', '\U0000213f'),
+    ('\U00002145', '\U00002145'),
+    ('\U00002160', '\U0000216f'),
+    ('\U00002183', '\U00002183'),
+    ('\U000024b6', '\U000024cf'),
+    ('\U00002c00', '\U00002c2f'),
+    ('\U00002c60', '\U00002c60'),
+    ('\U00002c62', '\U00002c64'),
+    ('\U00002c67', '\U00002c67'),
+    ('\U00002c69', '\U00002c69'),
+    ('\U00002c6b', '\U00002c6b'),
+    ('\U00002c6d', '\U00002c70'),
+    ('\U00002c72', '\U00002c72'),
+    ('\U00002c75', '\U00002c75'),
+    ('\U00002c7e', '\U00002c80'),
+    ('\U00002c82', '\U00002c82'),
+    ('\U00002c84', '\U00002c84'),
+    ('\U00002c86', '\U00002c86'),
+    ('\U00002c88', '\U00002c88'),
+    ('\U00002c8a', '\U00002c8a'),
+    ('\U00002c8c', '\U00002c8c'),
+    ('\U00002c8e', '\U00002c8e'),
+    ('\U00002c90', '\U00002c90'),
+    ('\U00002c92', '\U00002c92'),
+    ('\U00002c94', '\U00002c94'),
+    ('\U00002c96', '\U00002c96'),
+    ('\U00002c98', '\U00002c98'),
+    ('\U00002c9a', '\U00002c9a'),
+    ('\U00002c9c', '\U00002c9c'),
+    ('\U00002c9e', '\U00002c9e'),
+    ('\U00002ca0', '\U00002ca0'),
+    ('\U00002ca2', '\U00002ca2'),
+    ('\U00002ca4', '\U00002ca4'),
+    ('\U00002ca6', '\U00002ca6'),
+    ('\U00002ca8', '\U00002ca8'),
+    ('\U00002caa', '\U00002caa'),
+    ('\U00002cac', '\U00002cac'),
+    ('\U00002cae', '\U00002cae'),
+    ('\U00002cb0', '\U00002cb0'),
+    ('\U00002cb2', '\U00002cb2'),
+    ('\U00002cb4', '\U00002cb4'),
+    ('\U00002cb6', '\U00002cb6'),
+    ('\U00002cb8', '\U00002cb8'),
+    ('\U00002cba', '\U00002cba'),
+    ('\U00002cbc', '\U00002cbc'),
+    ('\U00002cbe', '\U00002cbe'),
+    ('\U00002cc0', '\U00002cc0'),
+    ('\U00002cc2', '\U00002cc2'),
+    ('\U00002cc4', '\U00002cc4'),
+    ('\U00002cc6', '\U00002cc6'),
+    ('\U00002cc8', '\U00002cc8'),
+    ('\U00002cca', '\U00002cca'),
+    ('\U00002ccc', '\U00002ccc'),
+    ('\U00002cce', '\U00002cce'),
+    ('\U00002cd0', '\U00002cd0'),
+    ('\U00002cd2', '\U00002cd2'),
+    ('\U00002cd4', '\U00002cd4'),
+    ('\U00002cd6', '\U00002cd6'),
+    ('\U00002cd8', '\U00002cd8'),
+    ('\U00002cda', '\U00002cda'),
+    ('\U00002cdc', '\U00002cdc'),
+    ('\U00002cde', '\U00002cde'),
+    ('\U00002ce0', '\U00002ce0'),
+    ('\U00002ce2', '\U00002ce2'),
+    ('\U00002ceb', '\U00002ceb'),
+    ('\U00002ced', '\U00002ced'),
+    ('\U00002cf2', '\U00002cf2'),
+    ('\U0000a640', '\U0000a640'),
+    ('\U0000a642', '\U0000a642'),
+    ('\U0000a644', '\U0000a644'),
+    ('\U0000a646', '\U0000a646'),
+    ('\U0000a648', '\U0000a648'),
+    ('\U0000a64a', '\U0000a64a'),
+    ('\U0000a64c', '\U0000a64c'),
+    ('\U0000a64e', '\U0000a64e'),
+    ('\U0000a650', '\U0000a650'),
+    ('\U0000a652', '\U0000a652'),
+    ('\U0000a654', '\U0000a654'),
+    ('\U0000a656', '\U0000a656'),
+    ('\U0000a658', '\U0000a658'),
+    ('\U0000a65a', '\U0000a65a'),
+    ('\U0000a65c', '\U0000a65c'),
+    ('\U0000a65e', '\U0000a65e'),
+    ('\U0000a660', '\U0000a660'),
+    ('\U0000a662', '\U0000a662'),
+    ('\U0000a664', '\U0000a664'),
+    ('\U0000a666', '\U0000a666'),
+    ('\U0000a668', '\U0000a668'),
+    ('\U0000a66a', '\U0000a66a'),
+    ('\U0000a66c', '\U0000a66c'),
+    ('\U0000a680', '\U0000a680'),
+    ('\U0000a682', '\U0000a682'),
+    ('\U0000a684', '\U0000a684'),
+    ('\U0000a686', '\U0000a686'),
+    ('\U0000a688', '\U0000a688'),
+    ('\U0000a68a', '\U0000a68a'),
+    ('\U0000a68c', '\U0000a68c'),
+    ('\U0000a68e', '\U0000a68e'),
+    ('\U0000a690', '\U0000a690'),
+    ('\U0000a692', '\U0000a692'),
+    ('\U0000a694', '\U0000a694'),
+    ('\U0000a696', '\U0000a696'),
+    ('\U0000a698', '\U0000a698'),
+    ('\U0000a69a', '\U0000a69a'),
+    ('\U0000a722', '\U0000a722'),
+    ('\U0000a724', '\U0000a724'),
+    ('\U0000a726', '\U0000a726'),
+    ('\U0000a728', '\U0000a728'),
+    ('\U0000a72a', '\U0000a72a'),
+    ('\U0000a72c', '\U0000a72c'),
+    ('\U0000a72e', '\U0000a72e'),
+    ('\U0000a732', '\U0000a732'),
+    ('\U0000a734', '\U0000a734'),
+    ('\U0000a736', '\U0000a736'),
+    ('\U0000a738', '\U0000a738'),
+    ('\U0000a73a', '\U0000a73a'),
+    ('\U0000a73c', '\U0000a73c'),
+    ('\U0000a73e', '\U0000a73e'),
+    ('\U0000a740', '\U0000a740'),
+    ('\U0000a742', '\U0000a742'),
+    ('\U0000a744', '\U0000a744'),
+    ('\U0000a746', '\U0000a746'),
+    ('\U0000a748', '\U0000a748'),
+    ('\U0000a74a', '\U0000a74a'),
+    ('\U0000a74c', '\U0000a74c'),
+    ('\U0000a74e', '\U0000a74e'),
+    ('\U0000a750', '\U0000a750'),
+    ('\U0000a752', '\U0000a752'),
+    ('\U0000a754', '\U0000a754'),
+    ('\U0000a756', '\U0000a756'),
+    ('\U0000a758', '\U0000a758'),
+    ('\U0000a75a', '\U0000a75a'),
+    ('\U0000a75c', '\U0000a75c'),
+    ('\U0000a75e', '\U0000a75e'),
+    ('\U0000a760', '\U0000a760'),
+    ('\U0000a762', '\U0000a762'),
+    ('\U0000a764', '\U0000a764'),
+    ('\U0000a766', '\U0000a766'),
+    ('\U0000a768', '\U0000a768'),
+    ('\U0000a76a', '\U0000a76a'),
+    ('\U0000a76c', '\U0000a76c'),
+    ('\U0000a76e', '\U0000a76e'),
+    ('\U0000a779', '\U0000a779'),
+    ('\U0000a77b', '\U0000a77b'),
+    ('\U0000a77d', '\U0000a77e'),
+    ('\U0000a780', '\U0000a780'),
+    ('\U0000a782', '\U0000a782'),
+    ('\U0000a784', '\U0000a784'),
+    ('\U0000a786', '\U0000a786'),
+    ('\U0000a78b', '\U0000a78b'),
+    ('\U0000a78d', '\U0000a78d'),
+    ('\U0000a790', '\U0000a790'),
+    ('\U0000a792', '\U0000a792'),
+    ('\U0000a796', '\U0000a796'),
+    ('\U0000a798', '\U0000a798'),
+    ('\U0000a79a', '\U0000a79a'),
+    ('\U0000a79c', '\U0000a79c'),
+    ('\U0000a79e', '\U0000a79e'),
+    ('\U0000a7a0', '\U0000a7a0'),
+    ('\U0000a7a2', '\U0000a7a2'),
+    ('\U0000a7a4', '\U0000a7a4'),
+    ('\U0000a7a6', '\U0000a7a6'),
+    ('\U0000a7a8', '\U0000a7a8'),
+    ('\U0000a7aa', '\U0000a7ae'),
+    ('\U0000a7b0', '\U0000a7b4'),
+    ('\U0000a7b6', '\U0000a7b6'),
+    ('\U0000a7b8', '\U0000a7b8'),
+    ('\U0000a7ba', '\U0000a7ba'),
+    ('\U0000a7bc', '\U0000a7bc'),
+    ('\U0000a7be', '\U0000a7be'),
+    ('\U0000a7c0', '\U0000a7c0'),
+    ('\U0000a7c2', '\U0000a7c2'),
+    ('\U0000a7c4', '\U0000a7c7'),
+    ('\U0000a7c9', '\U0000a7c9'),
+    ('\U0000a7d0', '\U0000a7d0'),
+    ('\U0000a7d6', '\U0000a7d6'),
+    ('\U0000a7d8', '\U0000a7d8'),
+    ('\U0000a7f5', '\U0000a7f5'),
+    ('\U0000ff21', '\U0000ff3a'),
+    ('\U00010400', '\U00010427'),
+    ('\U000104b0', '\U000104d3'),
+    ('\U00010570', '\U0001057a'),
+    ('\U0001057c', '\U0001058a'),
+    ('\U0001058c', '\U00010592'),
+    ('\U00010594', '\U00010595'),
+    ('\U00010c80', '\U00010cb2'),
+    ('\U000118a0', '\U000118bf'),
+    ('\U00016e40', '\U00016e5f'),
+    ('\U0001d400', '\U0001d419'),
+    ('\U0001d434', '\U0001d44d'),
+    ('\U0001d468', '\U0001d481'),
+    ('\U0001d49c', '\U0001d49c'),
+    ('\U0001d49e', '\U0001d49f'),
+    ('\U0001d4a2', '\U0001d4a2'),
+    ('\U0001d4a5', '\U0001d4a6'),
+    ('\U0001d4a9', '\U0001d4ac'),
+    ('\U0001d4ae', '\U0001d4b5'),
+    ('\U0001d4d0', '\U0001d4e9'),
+    ('\U0001d504', '\U0001d505'),
+    ('\U0001d507', '\U0001d50a'),
+    ('\U0001d50d', '\U0001d514'),
+    ('\U0001d516', '\U0001d51c'),
+    ('\U0001d538', '\U0001d539'),
+    ('\U0001d53b', '\U0001d53e'),
+    ('\U0001d540', '\U0001d544'),
+    ('\U0001d546', '\U0001d546'),
+    ('\U0001d54a', '\U0001d550'),
+    ('\U0001d56c', '\U0001d585'),
+    ('\U0001d5a0', '\U0001d5b9'),
+    ('\U0001d5d4', '\U0001d5ed'),
+    ('\U0001d608', '\U0001d621'),
+    ('\U0001d63c', '\U0001d655'),
+    ('\U0001d670', '\U0001d689'),
+    ('\U0001d6a8', '\U0001d6c0'),
+    ('\U0001d6e2', '\U0001d6fa'),
+    ('\U0001d71c', '\U0001d734'),
+    ('\U0001d756', '\U0001d76e'),
+    ('\U0001d790', '\U0001d7a8'),
+    ('\U0001d7ca', '\U0001d7ca'),
+    ('\U0001e900', '\U0001e921'),
+    ('\U0001f130', '\U0001f149'),
+    ('\U0001f150', '\U0001f169'),
+    ('\U0001f170', '\U0001f189')
+    ]),
+
+("White_Space", &[
+    ('\U00000009', '\U0000000d'),
+    ('\U00000020', '\U00000020'),
+    ('\U00000085', '\U00000085'),
+    ('\U000000a0', '\U000000a0'),
+    ('\U00001680', '\U00001680'),
+    ('\U00002000', '\U0000200a'),
+    ('\U00002028', '\U00002029'),
+    ('\U0000202f', '\U0000202f'),
+    ('\U0000205f', '\U0000205f'),
+    ('\U00003000', '\U00003000')
+    ]),
+
+];
+
+// For every script whose Script_Extensions (ScriptExtensions.txt) include
+// characters of other scripts, those characters, as of Unicode 14.0.
+pub static SCRIPT_EXTRAS: NamedClasses = &[
+
+("Arabic", &[
+    ('\U0000060c', '\U0000060c'),
+    ('\U0000061b', '\U0000061b'),
+    ('\U0000061f', '\U0000061f'),
+    ('\U00000640', '\U00000640'),
+    ('\U0000064b', '\U00000655'),
+    ('\U00000670', '\U00000670'),
+    ('\U0000fd3e', '\U0000fd3f'),
+    ('\U000102e0', '\U000102fb')
+    ]),
+
+("Bengali", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U00001cd0', '\U00001cd0'),
+    ('\U00001cd2', '\U00001cd2'),
+    ('\U00001cd5', '\U00001cd6'),
+    ('\U00001cd8', '\U00001cd8'),
+    ('\U00001ce1', '\U00001ce1'),
+    ('\U00001cea', '\U00001cea'),
+    ('\U00001ced', '\U00001ced'),
+    ('\U00001cf2', '\U00001cf2'),
+    ('\U00001cf5', '\U00001cf7'),
+    ('\U0000a8f1', '\U0000a8f1')
+    ]),
+
+("Bopomofo", &[
+    ('\U00003001', '\U00003003'),
+    ('\U00003008', '\U00003011'),
+    ('\U00003013', '\U0000301f'),
+    ('\U0000302a', '\U0000302d'),
+    ('\U00003030', '\U00003030'),
+    ('\U00003037', '\U00003037'),
+    ('\U000030fb', '\U000030fb'),
+    ('\U0000fe45', '\U0000fe46'),
+    ('\U0000ff61', '\U0000ff65')
+    ]),
+
+("Buginese", &[
+    ('\U0000a9cf', '\U0000a9cf')
+    ]),
+
+("Buhid", &[
+    ('\U00001735', '\U00001736')
+    ]),
+
+("Chakma", &[
+    ('\U000009e6', '\U000009ef'),
+    ('\U00001040', '\U00001049')
+    ]),
+
+("Coptic", &[
+    ('\U000102e0', '\U000102fb')
+    ]),
+
+("Cypriot", &[
+    ('\U00010100', '\U00010102'),
+    ('\U00010107', '\U00010133'),
+    ('\U00010137', '\U0001013f')
+    ]),
+
+("Cyrillic", &[
+    ('\U00000485', '\U00000486'),
+    ('\U00001df8', '\U00001df8'),
+    ('\U00002e43', '\U00002e43')
+    ]),
+
+("Devanagari", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U00001cd0', '\U00001cf6'),
+    ('\U00001cf8', '\U00001cf9'),
+    ('\U000020f0', '\U000020f0'),
+    ('\U0000a830', '\U0000a839')
+    ]),
+
+("Georgian", &[
+    ('\U000010fb', '\U000010fb')
+    ]),
+
+("Glagolitic", &[
+    ('\U00000484', '\U00000484'),
+    ('\U00000487', '\U00000487'),
+    ('\U00002e43', '\U00002e43'),
+    ('\U0000a66f', '\U0000a66f')
+    ]),
+
+("Greek", &[
+    ('\U00000342', '\U00000342'),
+    ('\U00000345', '\U00000345'),
+    ('\U00001dc0', '\U00001dc1')
+    ]),
+
+("Gujarati", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U0000a830', '\U0000a839')
+    ]),
+
+("Gurmukhi", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U0000a830', '\U0000a839')
+    ]),
+
+("Han", &[
+    ('\U00003001', '\U00003003'),
+    ('\U00003006', '\U00003006'),
+    ('\U00003008', '\U00003011'),
+    ('\U00003013', '\U0000301f'),
+    ('\U0000302a', '\U0000302d'),
+    ('\U00003030', '\U00003030'),
+    ('\U00003037', '\U00003037'),
+    ('\U0000303c', '\U0000303f'),
+    ('\U000030fb', '\U000030fb'),
+    ('\U00003190', '\U0000319f'),
+    ('\U000031c0', '\U000031e3'),
+    ('\U00003220', '\U00003247'),
+    ('\U00003280', '\U000032b0'),
+    ('\U000032c0', '\U000032cb'),
+    ('\U000032ff', '\U000032ff'),
+    ('\U00003358', '\U00003370'),
+    ('\U0000337b', '\U0000337f'),
+    ('\U000033e0', '\U000033fe'),
+    ('\U0000a700', '\U0000a707'),
+    ('\U0000fe45', '\U0000fe46'),
+    ('\U0000ff61', '\U0000ff65'),
+    ('\U0001d360', '\U0001d371'),
+    ('\U0001f250', '\U0001f251')
+    ]),
+
+("Hangul", &[
+    ('\U00003001', '\U00003003'),
+    ('\U00003008', '\U00003011'),
+    ('\U00003013', '\U0000301f'),
+    ('\U00003030', '\U00003030'),
+    ('\U00003037', '\U00003037'),
+    ('\U000030fb', '\U000030fb'),
+    ('\U0000fe45', '\U0000fe46'),
+    ('\U0000ff61', '\U0000ff65')
+    ]),
+
+("Hanunoo", &[
+    ('\U00001735', '\U00001736')
+    ]),
+
+("Hiragana", &[
+    ('\U00003001', '\U00003003'),
+    ('\U00003008', '\U00003011'),
+    ('\U00003013', '\U0000301f'),
+    ('\U00003030', '\U00003035'),
+    ('\U00003037', '\U00003037'),
+    ('\U0000303c', '\U0000303d'),
+    ('\U00003099', '\U0000309c'),
+    ('\U000030a0', '\U000030a0'),
+    ('\U000030fb', '\U000030fc'),
+    ('\U0000fe45', '\U0000fe46'),
+    ('\U0000ff61', '\U0000ff65'),
+    ('\U0000ff70', '\U0000ff70'),
+    ('\U0000ff9e', '\U0000ff9f')
+    ]),
+
+("Javanese", &[
+    ('\U0000a9cf', '\U0000a9cf')
+    ]),
+
+("Kaithi", &[
+    ('\U00000966', '\U0000096f'),
+    ('\U0000a830', '\U0000a839')
+    ]),
+
+("Kannada", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U00001cd0', '\U00001cd0'),
+    ('\U00001cd2', '\U00001cd2'),
+    ('\U00001cda', '\U00001cda'),
+    ('\U00001cf2', '\U00001cf2'),
+    ('\U00001cf4', '\U00001cf4'),
+    ('\U0000a830', '\U0000a835')
+    ]),
+
+("Katakana", &[
+    ('\U00003001', '\U00003003'),
+    ('\U00003008', '\U00003011'),
+    ('\U00003013', '\U0000301f'),
+    ('\U00003030', '\U00003035'),
+    ('\U00003037', '\U00003037'),
+    ('\U0000303c', '\U0000303d'),
+    ('\U00003099', '\U0000309c'),
+    ('\U000030a0', '\U000030a0'),
+    ('\U000030fb', '\U000030fc'),
+    ('\U0000fe45', '\U0000fe46'),
+    ('\U0000ff61', '\U0000ff65'),
+    ('\U0000ff70', '\U0000ff70'),
+    ('\U0000ff9e', '\U0000ff9f')
+    ]),
+
+("Kayah_Li", &[
+    ('\U0000a92e', '\U0000a92e')
+    ]),
+
+("Latin", &[
+    ('\U00000363', '\U0000036f'),
+    ('\U00000485', '\U00000486'),
+    ('\U00000951', '\U00000952'),
+    ('\U000010fb', '\U000010fb'),
+    ('\U0000202f', '\U0000202f'),
+    ('\U000020f0', '\U000020f0'),
+    ('\U0000a700', '\U0000a707'),
+    ('\U0000a92e', '\U0000a92e')
+    ]),
+
+("Limbu", &[
+    ('\U00000965', '\U00000965')
+    ]),
+
+("Linear_B", &[
+    ('\U00010100', '\U00010102'),
+    ('\U00010107', '\U00010133'),
+    ('\U00010137', '\U0001013f')
+    ]),
+
+("Malayalam", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U00001cda', '\U00001cda'),
+    ('\U0000a830', '\U0000a832')
+    ]),
+
+("Mandaic", &[
+    ('\U00000640', '\U00000640')
+    ]),
+
+("Mongolian", &[
+    ('\U00001802', '\U00001803'),
+    ('\U00001805', '\U00001805'),
+    ('\U0000202f', '\U0000202f')
+    ]),
+
+("Myanmar", &[
+    ('\U0000a92e', '\U0000a92e')
+    ]),
+
+("Nko", &[
+    ('\U0000060c', '\U0000060c'),
+    ('\U0000061b', '\U0000061b'),
+    ('\U0000061f', '\U0000061f'),
+    ('\U0000fd3e', '\U0000fd3f')
+    ]),
+
+("Oriya", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U00001cda', '\U00001cda'),
+    ('\U00001cf2', '\U00001cf2')
+    ]),
+
+("Phags_Pa", &[
+    ('\U00001802', '\U00001803'),
+    ('\U00001805', '\U00001805')
+    ]),
+
+("Sharada", &[
+    ('\U00000951', '\U00000951'),
+    ('\U00001cd7', '\U00001cd7'),
+    ('\U00001cd9', '\U00001cd9'),
+    ('\U00001cdc', '\U00001cdd'),
+    ('\U00001ce0', '\U00001ce0')
+    ]),
+
+("Sinhala", &[
+    ('\U00000964', '\U00000965')
+    ]),
+
+("Syloti_Nagri", &[
+    ('\U00000964', '\U00000965'),
+    ('\U000009e6', '\U000009ef')
+    ]),
+
+("Syriac", &[
+    ('\U0000060c', '\U0000060c'),
+    ('\U0000061b', '\U0000061c'),
+    ('\U0000061f', '\U0000061f'),
+    ('\U00000640', '\U00000640'),
+    ('\U0000064b', '\U00000655'),
+    ('\U00000670', '\U00000670'),
+    ('\U00001df8', '\U00001df8'),
+    ('\U00001dfa', '\U00001dfa')
+    ]),
+
+("Tagalog", &[
+    ('\U00001735', '\U00001736')
+    ]),
+
+("Tagbanwa", &[
+    ('\U00001735', '\U00001736')
+    ]),
+
+("Tai_Le", &[
+    ('\U00001040', '\U00001049')
+    ]),
+
+("Takri", &[
+    ('\U00000964', '\U00000965'),
+    ('\U0000a830', '\U0000a839')
+    ]),
+
+("Tamil", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U00001cda', '\U00001cda'),
+    ('\U0000a8f3', '\U0000a8f3'),
+    ('\U00011301', '\U00011301'),
+    ('\U00011303', '\U00011303'),
+    ('\U0001133b', '\U0001133c')
+    ]),
+
+("Telugu", &[
+    ('\U00000951', '\U00000952'),
+    ('\U00000964', '\U00000965'),
+    ('\U00001cda', '\U00001cda'),
+    ('\U00001cf2', '\U00001cf2')
+    ]),
+
+("Thaana", &[
+    ('\U0000060c', '\U0000060c'),
+    ('\U0000061b', '\U0000061c'),
+    ('\U0000061f', '\U0000061f'),
+    ('\U00000660', '\U00000669'),
+    ('\U0000fdf2', '\U0000fdf2'),
+    ('\U0000fdfd', '\U0000fdfd')
+    ]),
+
+];
@@ -33,16 +33,26 @@
 //
 // [1] - http://swtch.com/~rsc/regex/regex3.html
 
+use std::cell::RefCell;
 use std::cmp;
 use std::mem;
+use std::str;
+use std::sync::atomics::{AtomicBool, SeqCst};
+use std::uint;
 use std::slice::MutableVector;
+use sync::Arc;
 use compile::{
-    Program,
+    Program, Inst,
     Match, OneChar, CharClass, Any, EmptyBegin, EmptyEnd, EmptyWordBoundary,
-    Save, Jump, Split,
+    EmptySegmentBoundary, EmptyLook, Cut, LookMatch, GroupRef,
+    Save, Jump, Split, describe,
 };
-use parse::{FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL, FLAG_NEGATED};
-use parse::unicode::PERLW;
+use segment;
+use segment::Segment;
+use stats::{Counters, Engine};
+use parse::{FLAG_NOCASE, FLAG_MULTI, FLAG_DOTNL, FLAG_NEGATED, FLAG_SEARCH};
+use parse::{Flags, FLAG_ASCII, FLAG_UWORD, FLAG_FINALNL};
+use parse::unicode::{PERLW, UNICODE_WORD};
 
 pub type CaptureLocs = Vec<Option<uint>>;
 
@@ -57,6 +67,138 @@ pub enum MatchKind {
     Submatches,
 }
 
+/// The error returned by a search that took more steps than it was allowed.
+#[deriving(Clone, Eq, Show)]
+pub struct StepLimitExceeded;
+
+/// The error returned by a search that was cancelled.
+#[deriving(Clone, Eq, Show)]
+pub struct Cancelled;
+
+/// Cancel is a flag that stops the searches given it (e.g., with
+/// `Regex::is_match_cancel`). Clones share the same flag, so a search can be
+/// cancelled from another task.
+///
+/// A search only looks at the flag every few thousand steps, so that it
+/// stays fast.
+#[deriving(Clone)]
+pub struct Cancel {
+    flag: Arc<AtomicBool>,
+}
+
+impl Cancel {
+    /// Returns a new flag that isn't set.
+    pub fn new() -> Cancel {
+        Cancel { flag: Arc::new(AtomicBool::new(false)) }
+    }
+
+    /// Sets the flag, which stops every search using it (or a clone of it).
+    pub fn cancel(&self) {
+        self.flag.store(true, SeqCst)
+    }
+
+    /// Returns true if the flag has been set.
+    pub fn is_cancelled(&self) -> bool {
+        self.flag.load(SeqCst)
+    }
+}
+
+// The number of steps taken between polls of a cancellation flag.
+static POLL_STEPS: uint = 4096;
+
+/// Budget is the number of steps a search may still take. A step is one
+/// thread of the NFA simulation (or one instruction of the backtracker)
+/// visited at one position, or one character consumed by the DFA.
+///
+/// A budget may also be cancelled. It's polled whenever `POLL_STEPS` steps
+/// have been taken, which keeps `take` as cheap as it is without it.
+///
+/// The counters of a regex whose searches are counted go along with the
+/// budget, since it's given to every engine.
+pub struct Budget<'a> {
+    // The steps that may be taken before polling.
+    left: uint,
+    // The steps that remain after `left`.
+    reserve: uint,
+    cancel: Option<&'a Cancel>,
+    stats: Option<&'a Counters>,
+}
+
+impl<'a> Budget<'a> {
+    /// Returns a budget of `limit` steps, or an unlimited budget if `limit`
+    /// is `None`. If `cancel` is given, then the budget runs out as soon as
+    /// it's set.
+    pub fn new(limit: Option<uint>, cancel: Option<&'a Cancel>)
+              -> Budget<'a> {
+        let limit = limit.unwrap_or(uint::MAX);
+        let left = match cancel {
+            None => limit,
+            Some(_) => cmp::min(limit, POLL_STEPS),
+        };
+        Budget {
+            left: left,
+            reserve: limit - left,
+            cancel: cancel,
+            stats: None,
+        }
+    }
+
+    /// Returns this budget with the counters given, which count what the
+    /// search does.
+    pub fn with_stats(self, stats: Option<&'a Counters>) -> Budget<'a> {
+        Budget { stats: stats, ..self }
+    }
+
+    /// Counts a run of the engine given, if searches are counted.
+    #[inline]
+    pub fn count_engine(&self, engine: Engine) {
+        match self.stats {
+            None => {}
+            Some(stats) => stats.engine(engine),
+        }
+    }
+
+    /// Counts a position reported by a prefilter, if searches are counted.
+    #[inline]
+    pub fn count_candidate(&self) {
+        match self.stats {
+            None => {}
+            Some(stats) => stats.candidate(),
+        }
+    }
+
+    /// Takes `n` steps from the budget, returning false if there aren't
+    /// enough steps left (or the search was cancelled).
+    #[inline]
+    pub fn take(&mut self, n: uint) -> bool {
+        if n <= self.left {
+            self.left -= n;
+            true
+        } else {
+            self.refill(n)
+        }
+    }
+
+    // Moves steps from the reserve to `left` after polling the cancellation
+    // flag, and then takes `n` steps.
+    fn refill(&mut self, n: uint) -> bool {
+        let cancelled = match self.cancel {
+            None => false,
+            Some(cancel) => cancel.is_cancelled(),
+        };
+        let need = n - self.left;
+        if cancelled || need > self.reserve {
+            self.left = 0;
+            self.reserve = 0;
+            return false
+        }
+        let more = cmp::min(self.reserve, cmp::max(need, POLL_STEPS));
+        self.reserve -= more;
+        self.left = self.left + more - n;
+        true
+    }
+}
+
 /// Runs an NFA simulation on the compiled expression given on the search text
 /// `input`. The search begins at byte index `start` and ends at byte index
 /// `end`. (The range is specified here so that zero-width assertions will work
@@ -66,27 +208,148 @@ pub enum MatchKind {
 /// wants. There are three choices: match existence only, the location of the
 /// entire match or the locations of the entire match in addition to the
 /// locations of each submatch.
-pub fn run<'r, 't>(which: MatchKind, prog: &'r Program, input: &'t str,
-                   start: uint, end: uint) -> CaptureLocs {
-    Nfa {
-        which: which,
-        prog: prog,
-        input: input,
-        start: start,
-        end: end,
-        ic: 0,
-        chars: CharReader::new(input),
-    }.run()
-}
-
-struct Nfa<'r, 't> {
+///
+/// If `anchored` is true, then only a match starting at `start` is found.
+///
+/// The steps taken are subtracted from `budget`. If it runs out, then the
+/// search is abandoned.
+pub fn run<'r, 't, 'b>(which: MatchKind, prog: &'r Program, input: &'t str,
+                       start: uint, end: uint, anchored: bool,
+                       budget: &mut Budget<'b>)
+                  -> Result<CaptureLocs, StepLimitExceeded> {
+    exec(which, prog, input, start, end, anchored, false, budget)
+}
+
+/// Runs an NFA simulation like `run`, except that the leftmost-longest match
+/// is found instead of the leftmost-first one. (Among the matches starting
+/// at the leftmost position, the longest one is found.) The capture groups
+/// are those of the first thread, in the order of `run`, to have found that
+/// match.
+pub fn run_longest<'r, 't, 'b>(which: MatchKind, prog: &'r Program,
+                               input: &'t str, start: uint, end: uint,
+                               anchored: bool, budget: &mut Budget<'b>)
+                  -> Result<CaptureLocs, StepLimitExceeded> {
+    exec(which, prog, input, start, end, anchored, true, budget)
+}
+
+/// Runs an NFA simulation like `run`, except that the capture locations are
+/// appended to `caps` rather than returned, so that the memory of `caps`
+/// can be reused from one search to the next. Nothing is appended if the
+/// search is abandoned.
+pub fn run_into<'r, 't, 'b>(which: MatchKind, prog: &'r Program,
+                            input: &'t str, start: uint, end: uint,
+                            anchored: bool, budget: &mut Budget<'b>,
+                            caps: &mut CaptureLocs)
+               -> Result<(), StepLimitExceeded> {
+    exec_into(which, prog, input, start, end, anchored, false, budget, caps)
+}
+
+/// Appends the capture locations `found` to `caps`. If `caps` is empty and
+/// too small to hold them, `found` replaces it instead of being copied.
+pub fn push_locs(caps: &mut CaptureLocs, found: CaptureLocs) {
+    if caps.len() == 0 && caps.capacity() < found.len() {
+        *caps = found
+    } else {
+        caps.push_all(found.as_slice())
+    }
+}
+
+/// Runs an NFA simulation like `run` for the leftmost-first match in all of
+/// `input`, and returns a transcript of the search along with the match.
+/// Every position of the input that the search steps over gets a line, and
+/// so does every thread created there and every character consumed by one.
+/// The format is meant for debugging and may change.
+///
+/// The search is always run by the NFA, so programs that only the
+/// backtracker can run (see `Program::backtrack_only`) aren't matched
+/// correctly.
+pub fn trace(prog: &Program, input: &str) -> (CaptureLocs, ~str) {
+    let mut nfa = Nfa::new(Location, prog, input, 0, input.len(), false,
+                           false, Budget::new(None, None),
+                           RefCell::new(StrBuf::new()));
+    let mut caps = vec![None, None];
+    // A search without a limit can't run out of steps.
+    nfa.run(caps.as_mut_slice()).unwrap();
+    (caps, nfa.trace.unwrap().into_owned())
+}
+
+fn exec<'r, 't, 'b>(which: MatchKind, prog: &'r Program, input: &'t str,
+                    start: uint, end: uint, anchored: bool, longest: bool,
+                    budget: &mut Budget<'b>)
+               -> Result<CaptureLocs, StepLimitExceeded> {
+    let mut caps = vec!();
+    try!(exec_into(which, prog, input, start, end, anchored, longest, budget,
+                   &mut caps));
+    Ok(caps)
+}
+
+fn exec_into<'r, 't, 'b>(which: MatchKind, prog: &'r Program,
+                         input: &'t str, start: uint, end: uint,
+                         anchored: bool, longest: bool,
+                         budget: &mut Budget<'b>, caps: &mut CaptureLocs)
+                        -> Result<(), StepLimitExceeded> {
+    let nlocs = match which {
+        Exists | Location => 2,
+        Submatches => prog.num_captures() * 2,
+    };
+    let base = caps.len();
+    caps.grow(nlocs, &None);
+    let mut nfa = Nfa::new(which, prog, input, start, end, anchored, longest,
+                           *budget, NoTrace);
+    let found = nfa.run(caps.mut_slice_from(base));
+    *budget = nfa.budget;
+    match found {
+        Err(err) => {
+            caps.truncate(base);
+            Err(err)
+        }
+        Ok(matched) => {
+            match which {
+                Exists if matched => {
+                    *caps.get_mut(base) = Some(0);
+                    *caps.get_mut(base + 1) = Some(0);
+                }
+                _ => {}
+            }
+            Ok(())
+        }
+    }
+}
+
+// The transcript of a search (see `trace`). A search that isn't traced has
+// `NoTrace`, whose notes compile to nothing, so that it doesn't check
+// whether it's traced at every step.
+trait Tracer {
+    fn note(&self, line: || -> ~str);
+}
+
+struct NoTrace;
+
+impl Tracer for NoTrace {
+    #[inline(always)]
+    fn note(&self, _: || -> ~str) {}
+}
+
+impl Tracer for RefCell<StrBuf> {
+    fn note(&self, line: || -> ~str) {
+        let mut trace = self.borrow_mut();
+        trace.push_str(line().as_slice());
+        trace.push_char('\n');
+    }
+}
+
+struct Nfa<'r, 't, 'b, T> {
     which: MatchKind,
     prog: &'r Program,
     input: &'t str,
     start: uint,
     end: uint,
+    anchored: bool,
+    longest: bool,
     ic: uint,
     chars: CharReader<'t>,
+    budget: Budget<'b>,
+    trace: T,
 }
 
 /// Indicates the next action to take after a single non-empty instruction
@@ -104,25 +367,49 @@ pub enum StepState {
     StepContinue,
 }
 
-impl<'r, 't> Nfa<'r, 't> {
-    fn run(&mut self) -> CaptureLocs {
+impl<'r, 't, 'b, T: Tracer> Nfa<'r, 't, 'b, T> {
+    fn new(which: MatchKind, prog: &'r Program, input: &'t str, start: uint,
+           end: uint, anchored: bool, longest: bool, budget: Budget<'b>,
+           trace: T)
+          -> Nfa<'r, 't, 'b, T> {
+        Nfa {
+            which: which,
+            prog: prog,
+            input: input,
+            start: start,
+            end: end,
+            anchored: anchored,
+            // Any match will do when only its existence matters.
+            longest: longest && match which { Exists => false, _ => true },
+            ic: 0,
+            chars: CharReader::new(input),
+            budget: budget,
+            trace: trace,
+        }
+    }
+
+    // Runs the simulation and returns whether there's a match. The
+    // locations of the match are written to `groups`, which is empty for
+    // `Exists`.
+    fn run(&mut self, groups: &mut [Option<uint>])
+          -> Result<bool, StepLimitExceeded> {
         let ncaps = match self.which {
             Exists => 0,
             Location => 1,
             Submatches => self.prog.num_captures(),
         };
+        let groups = groups.mut_slice_to(ncaps * 2);
         let mut matched = false;
         let ninsts = self.prog.insts.len();
         let mut clist = &mut Threads::new(self.which, ninsts, ncaps);
         let mut nlist = &mut Threads::new(self.which, ninsts, ncaps);
 
-        let mut groups = Vec::from_elem(ncaps * 2, None);
-
         // Determine if the expression starts with a '^' so we can avoid
         // simulating .*?
         // Make sure multi-line mode isn't enabled for it, otherwise we can't
         // drop the initial .*?
-        let prefix_anchor =
+        // An anchored search is treated the same way.
+        let prefix_anchor = self.anchored ||
             match *self.prog.insts.get(1) {
                 EmptyBegin(flags) if flags & FLAG_MULTI == 0 => true,
                 _ => false,
@@ -137,20 +424,28 @@ impl<'r, 't> Nfa<'r, 't> {
                 if matched {
                     break
                 }
+                // An anchored search can't start over.
+                if self.anchored && self.ic > self.start {
+                    break
+                }
 
                 // If there are no threads to try, then we'll have to start
                 // over at the beginning of the regex.
-                // BUT, if there's a literal prefix for the program, try to
+                // BUT, if there's a prefilter for the program, try to
                 // jump ahead quickly. If it can't be found, then we can bail
                 // out early.
-                if self.prog.prefix.len() > 0 && clist.size == 0 {
-                    let needle = self.prog.prefix.as_slice().as_bytes();
+                if !self.anchored && self.prog.prefilter.is_some()
+                   && clist.size == 0 {
                     let haystack = self.input.as_bytes().slice_from(self.ic);
-                    match find_prefix(needle, haystack) {
+                    match self.prog.prefilter.find(haystack) {
                         None => break,
                         Some(i) => {
+                            self.budget.count_candidate();
                             self.ic += i;
                             next_ic = self.chars.set(self.ic);
+                            self.note(|| {
+                                format!("prefilter skips to {}", self.ic)
+                            });
                         }
                     }
                 }
@@ -160,23 +455,44 @@ impl<'r, 't> Nfa<'r, 't> {
             // a state starting at the current position in the input for the
             // beginning of the program only if we don't already have a match.
             if clist.size == 0 || (!prefix_anchor && !matched) {
-                self.add(clist, 0, groups.as_mut_slice())
+                self.add(clist, 0, groups)
             }
 
             // Now we try to read the next character.
             // As a result, the 'step' method will look at the previous
             // character.
+            let at = self.ic;
             self.ic = next_ic;
             next_ic = self.chars.advance();
+            self.note(|| {
+                let c = match self.chars.prev {
+                    None => ~"end",
+                    Some(c) => {
+                        format!("'{}'", str::from_char(c).escape_default())
+                    }
+                };
+                format!("at {} ({}): {} threads", at, c, clist.size)
+            });
 
+            // The budget is checked once per position rather than once per
+            // thread.
+            if !self.budget.take(clist.size + 1) {
+                return Err(StepLimitExceeded)
+            }
             let mut i = 0;
             while i < clist.size {
                 let pc = clist.pc(i);
-                let step_state = self.step(groups.as_mut_slice(), nlist,
-                                           clist.groups(i), pc);
+                let step_state = self.step(groups, nlist, clist.groups(i), pc);
                 match step_state {
-                    StepMatchEarlyReturn => return vec![Some(0), Some(0)],
-                    StepMatch => { matched = true; clist.empty() },
+                    StepMatchEarlyReturn => return Ok(true),
+                    StepMatch => {
+                        matched = true;
+                        // Threads of lower priority may still find a longer
+                        // match.
+                        if !self.longest {
+                            clist.empty()
+                        }
+                    }
                     StepContinue => {},
                 }
                 i += 1;
@@ -184,22 +500,26 @@ impl<'r, 't> Nfa<'r, 't> {
             mem::swap(&mut clist, &mut nlist);
             nlist.empty();
         }
-        match self.which {
-            Exists if matched     => vec![Some(0), Some(0)],
-            Exists                => vec![None, None],
-            Location | Submatches => groups,
-        }
+        Ok(matched)
     }
 
     fn step(&self, groups: &mut [Option<uint>], nlist: &mut Threads,
             caps: &mut [Option<uint>], pc: uint)
            -> StepState {
+        if self.longest && starts_after(caps, groups) {
+            // A match starting further left has been found already.
+            return StepContinue
+        }
         match *self.prog.insts.get(pc) {
             Match => {
+                self.note(|| format!("  {:04} match", pc));
                 match self.which {
                     Exists => {
                         return StepMatchEarlyReturn
                     }
+                    _ if self.longest && !is_longer(caps, groups) => {
+                        return StepMatch
+                    }
                     Location => {
                         groups[0] = caps[0];
                         groups[1] = caps[1];
@@ -213,40 +533,36 @@ impl<'r, 't> Nfa<'r, 't> {
                     }
                 }
             }
-            OneChar(c, flags) => {
-                if self.char_eq(flags & FLAG_NOCASE > 0, self.chars.prev, c) {
+            EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_)
+            | EmptySegmentBoundary(_, _)
+            | Save(_) | Jump(_) | Split(_, _) => {},
+            ref inst => {
+                let matched = char_matches(inst, self.chars.prev);
+                self.note(|| {
+                    let result = if matched { "matched" } else { "failed" };
+                    format!("  {:04} {}: {}", pc, describe(inst), result)
+                });
+                if matched {
                     self.add(nlist, pc+1, caps);
                 }
             }
-            CharClass(ref ranges, flags) => {
-                if self.chars.prev.is_some() {
-                    let c = self.chars.prev.unwrap();
-                    let negate = flags & FLAG_NEGATED > 0;
-                    let casei = flags & FLAG_NOCASE > 0;
-                    let found = ranges.as_slice();
-                    let found = found.bsearch(|&rc| class_cmp(casei, c, rc));
-                    let found = found.is_some();
-                    if (found && !negate) || (!found && negate) {
-                        self.add(nlist, pc+1, caps);
-                    }
-                }
-            }
-            Any(flags) => {
-                if flags & FLAG_DOTNL > 0
-                   || !self.char_eq(false, self.chars.prev, '\n') {
-                    self.add(nlist, pc+1, caps)
-                }
-            }
-            EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_)
-            | Save(_) | Jump(_) | Split(_, _) => {},
         }
         StepContinue
     }
 
+    // Adds a line to the transcript of a traced search.
+    #[inline]
+    fn note(&self, line: || -> ~str) {
+        self.trace.note(line)
+    }
+
     fn add(&self, nlist: &mut Threads, pc: uint, groups: &mut [Option<uint>]) {
         if nlist.contains(pc) {
             return
         }
+        self.note(|| {
+            format!("  add {:04} {}", pc, describe(self.prog.insts.get(pc)))
+        });
         // We have to add states to the threads list even if their empty.
         // TL;DR - It prevents cycles.
         // If we didn't care about cycles, we'd *only* add threads that
@@ -262,25 +578,25 @@ impl<'r, 't> Nfa<'r, 't> {
         // We make a minor optimization by indicating that the state is "empty"
         // so that its capture groups are not filled in.
         match *self.prog.insts.get(pc) {
-            EmptyBegin(flags) => {
-                let multi = flags & FLAG_MULTI > 0;
+            // `\G` depends on where the search started, which
+            // `empty_matches` can't know about.
+            EmptyBegin(flags) if flags & FLAG_SEARCH > 0 => {
                 nlist.add(pc, groups, true);
-                if self.chars.is_begin()
-                   || (multi && self.char_is(self.chars.prev, '\n')) {
+                if self.ic == self.start {
                     self.add(nlist, pc + 1, groups)
                 }
             }
-            EmptyEnd(flags) => {
-                let multi = flags & FLAG_MULTI > 0;
+            EmptyBegin(_) | EmptyEnd(_) | EmptyWordBoundary(_) => {
                 nlist.add(pc, groups, true);
-                if self.chars.is_end()
-                   || (multi && self.char_is(self.chars.cur, '\n')) {
+                let inst = self.prog.insts.get(pc);
+                if empty_matches(inst, self.chars.prev, self.chars.cur,
+                                 self.chars.is_last()) {
                     self.add(nlist, pc + 1, groups)
                 }
             }
-            EmptyWordBoundary(flags) => {
+            EmptySegmentBoundary(seg, flags) => {
                 nlist.add(pc, groups, true);
-                if self.chars.is_word_boundary() == !(flags & FLAG_NEGATED > 0) {
+                if segment_matches(seg, flags, self.input, self.ic) {
                     self.add(nlist, pc + 1, groups)
                 }
             }
@@ -314,31 +630,146 @@ impl<'r, 't> Nfa<'r, 't> {
             Match | OneChar(_, _) | CharClass(_, _) | Any(_) => {
                 nlist.add(pc, groups, false);
             }
+            // Programs with lookaround or backreferences are run by the
+            // backtracker instead.
+            EmptyLook(_, _) | Cut(_) | LookMatch | GroupRef(_, _) => {}
         }
     }
 
-    // FIXME: For case insensitive comparisons, it uses the uppercase
-    // character and tests for equality. IIUC, this does not generalize to
-    // all of Unicode. I believe we need to check the entire fold for each
-    // character. This will be easy to add if and when it gets added to Rust's
-    // standard library.
-    #[inline]
-    fn char_eq(&self, casei: bool, textc: Option<char>, regc: char) -> bool {
-        match textc {
-            None => false,
-            Some(textc) => {
-                regc == textc
-                    || (casei && regc.to_uppercase() == textc.to_uppercase())
-            }
+}
+
+/// Returns true if the thread with capture groups `caps` started to the right
+/// of the match found so far (in `groups`), if any.
+#[inline]
+pub fn starts_after(caps: &[Option<uint>], groups: &[Option<uint>]) -> bool {
+    match (caps[0], groups[0]) {
+        (Some(s), Some(best)) => s > best,
+        _ => false,
+    }
+}
+
+/// Returns true if the match of the thread with capture groups `caps` is
+/// preferred to the match found so far (in `groups`) by leftmost-longest
+/// semantics.
+#[inline]
+pub fn is_longer(caps: &[Option<uint>], groups: &[Option<uint>]) -> bool {
+    match ((caps[0], caps[1]), (groups[0], groups[1])) {
+        (_, (None, _)) | (_, (_, None)) => true,
+        ((Some(s), Some(e)), (Some(bs), Some(be))) => {
+            s < bs || (s == bs && e > be)
         }
+        _ => false,
     }
+}
 
-    #[inline]
-    fn char_is(&self, textc: Option<char>, regc: char) -> bool {
-        textc == Some(regc)
+/// Returns true if and only if the character consuming instruction `inst`
+/// matches the character `c`. A `None` character (i.e., the end of the input)
+/// never matches.
+///
+/// This is shared by every matching engine in this crate so that they all
+/// agree on what a single character step means.
+#[inline]
+pub fn char_matches(inst: &Inst, c: Option<char>) -> bool {
+    let c = match c {
+        None => return false,
+        Some(c) => c,
+    };
+    match *inst {
+        OneChar(regc, flags) => char_eq(flags & FLAG_NOCASE > 0, c, regc),
+        CharClass(ref set, _) => set.matches(c),
+        Any(flags) => flags & FLAG_DOTNL > 0 || c != '\n',
+        _ => false,
+    }
+}
+
+/// Returns true if and only if the zero-width assertion `inst` is satisfied
+/// at a position in the input where `prev` is the preceding character and
+/// `cur` is the following character. (`None` indicates the beginning or end
+/// of the input, respectively.) `last` tells whether `cur` is the last
+/// character of the input, for `\Z`. (Since that only matters when `cur` is
+/// a new line, it's enough to check that `cur` starts at the last byte.)
+///
+/// Instructions that aren't zero-width assertions are never satisfied.
+/// `\G` is treated like `\A`, which is only right for a search that starts
+/// at the beginning of the input.
+#[inline]
+pub fn empty_matches(inst: &Inst, prev: Option<char>, cur: Option<char>,
+                     last: bool) -> bool {
+    match *inst {
+        EmptyBegin(flags) => {
+            prev.is_none() || (flags & FLAG_MULTI > 0 && prev == Some('\n'))
+        }
+        EmptyEnd(flags) if flags & FLAG_FINALNL > 0 => {
+            cur.is_none() || (last && cur == Some('\n'))
+        }
+        EmptyEnd(flags) => {
+            cur.is_none() || (flags & FLAG_MULTI > 0 && cur == Some('\n'))
+        }
+        EmptyWordBoundary(flags) => {
+            let boundary =
+                if flags & FLAG_ASCII > 0 {
+                    is_ascii_word_boundary(prev, cur)
+                } else if flags & FLAG_UWORD > 0 {
+                    is_unicode_word_boundary(prev, cur)
+                } else {
+                    is_word_boundary(prev, cur)
+                };
+            boundary == !(flags & FLAG_NEGATED > 0)
+        }
+        _ => false,
     }
 }
 
+/// Returns true if and only if the assertion `EmptySegmentBoundary(seg,
+/// flags)` is satisfied at the byte index `ic` in `input`. Unlike the
+/// assertions of `empty_matches`, it can depend on more than the characters
+/// on either side of the position.
+#[inline]
+pub fn segment_matches(seg: Segment, flags: Flags, input: &str, ic: uint)
+                      -> bool {
+    segment::is_boundary(seg, input, ic) == !(flags & FLAG_NEGATED > 0)
+}
+
+// FIXME: For case insensitive comparisons, it uses the uppercase
+// character and tests for equality. IIUC, this does not generalize to
+// all of Unicode. I believe we need to check the entire fold for each
+// character. This will be easy to add if and when it gets added to Rust's
+// standard library.
+#[inline]
+fn char_eq(casei: bool, textc: char, regc: char) -> bool {
+    regc == textc || (casei && regc.to_uppercase() == textc.to_uppercase())
+}
+
+/// Returns true if and only if the position between `prev` and `cur` is a
+/// word boundary.
+#[inline]
+pub fn is_word_boundary(prev: Option<char>, cur: Option<char>) -> bool {
+    if prev.is_none() {
+        return is_word(cur)
+    }
+    if cur.is_none() {
+        return is_word(prev)
+    }
+    (is_word(cur) && !is_word(prev)) || (is_word(prev) && !is_word(cur))
+}
+
+/// Returns true if and only if the position between `prev` and `cur` is an
+/// ASCII word boundary (where only ASCII characters are word characters), as
+/// used when Unicode is off.
+#[inline]
+pub fn is_ascii_word_boundary(prev: Option<char>, cur: Option<char>) -> bool {
+    is_ascii_word(prev) != is_ascii_word(cur)
+}
+
+/// Returns true if and only if the position between `prev` and `cur` is a
+/// Unicode word boundary (see `is_unicode_word`), as used with the `w`
+/// flag.
+#[inline]
+pub fn is_unicode_word_boundary(prev: Option<char>, cur: Option<char>)
+                               -> bool {
+    is_unicode_word(prev) != is_unicode_word(cur)
+}
+
 /// CharReader is responsible for maintaining a "previous" and a "current"
 /// character. This one-character lookahead is necessary for assertions that
 /// look one character before or after the current position.
@@ -417,17 +848,43 @@ impl<'t> CharReader<'t> {
     #[inline]
     pub fn is_end(&self) -> bool { self.cur.is_none() }
 
+    /// Returns true if and only if the current character is the last one in
+    /// the input (ignoring the range of the input to search).
+    #[inline]
+    pub fn is_last(&self) -> bool {
+        self.cur.is_some() && self.next >= self.input.len()
+    }
+
     /// Returns true if and only if the current position is a word boundary.
     /// (Ignoring the range of the input to search.)
     pub fn is_word_boundary(&self) -> bool {
-        if self.is_begin() {
-            return is_word(self.cur)
-        }
-        if self.is_end() {
-            return is_word(self.prev)
+        is_word_boundary(self.prev, self.cur)
+    }
+
+    /// Returns true if and only if the current position is an ASCII word
+    /// boundary. (Ignoring the range of the input to search.)
+    pub fn is_ascii_word_boundary(&self) -> bool {
+        is_ascii_word_boundary(self.prev, self.cur)
+    }
+
+    /// Returns true if and only if the current position is a Unicode word
+    /// boundary. (Ignoring the range of the input to search.)
+    pub fn is_unicode_word_boundary(&self) -> bool {
+        is_unicode_word_boundary(self.prev, self.cur)
+    }
+
+    /// Returns true if and only if the current position is a boundary
+    /// between segments of the kind given (`\b{g}` or `\b{wb}`).
+    pub fn is_segment_boundary(&self, seg: Segment) -> bool {
+        segment::is_boundary(seg, self.input, self.pos())
+    }
+
+    // Returns the byte index of the current position.
+    fn pos(&self) -> uint {
+        match self.cur {
+            None => self.input.len(),
+            Some(c) => self.next - c.len_utf8_bytes(),
         }
-        (is_word(self.cur) && !is_word(self.prev))
-        || (is_word(self.prev) && !is_word(self.cur))
     }
 }
 
@@ -436,11 +893,14 @@ struct Thread {
     groups: Vec<Option<uint>>,
 }
 
-struct Threads {
+/// A sparse set of threads (with their capture locations) used by the NFA
+/// simulations in this crate.
+pub struct Threads {
     which: MatchKind,
     queue: Vec<Thread>,
     sparse: Vec<uint>,
-    size: uint,
+    /// The number of threads currently in the queue.
+    pub size: uint,
 }
 
 impl Threads {
@@ -451,7 +911,7 @@ impl Threads {
     // the execution of a VM.
     //
     // See http://research.swtch.com/sparse for the deets.
-    fn new(which: MatchKind, num_insts: uint, ncaps: uint) -> Threads {
+    pub fn new(which: MatchKind, num_insts: uint, ncaps: uint) -> Threads {
         Threads {
             which: which,
             queue: Vec::from_fn(num_insts, |_| {
@@ -462,7 +922,14 @@ impl Threads {
         }
     }
 
-    fn add(&mut self, pc: uint, groups: &[Option<uint>], empty: bool) {
+    /// Returns the number of bytes that `new` allocates for the arguments
+    /// given.
+    pub fn heap_size(num_insts: uint, ncaps: uint) -> uint {
+        num_insts * (mem::size_of::<Thread>() + uint::BYTES
+                     + ncaps * 2 * mem::size_of::<Option<uint>>())
+    }
+
+    pub fn add(&mut self, pc: uint, groups: &[Option<uint>], empty: bool) {
         let t = self.queue.get_mut(self.size);
         t.pc = pc;
         match (empty, self.which) {
@@ -482,23 +949,23 @@ impl Threads {
     }
 
     #[inline]
-    fn contains(&self, pc: uint) -> bool {
+    pub fn contains(&self, pc: uint) -> bool {
         let s = *self.sparse.get(pc);
         s < self.size && self.queue.get(s).pc == pc
     }
 
     #[inline]
-    fn empty(&mut self) {
+    pub fn empty(&mut self) {
         self.size = 0;
     }
 
     #[inline]
-    fn pc(&self, i: uint) -> uint {
+    pub fn pc(&self, i: uint) -> uint {
         self.queue.get(i).pc
     }
 
     #[inline]
-    fn groups<'r>(&'r mut self, i: uint) -> &'r mut [Option<uint>] {
+    pub fn groups<'r>(&'r mut self, i: uint) -> &'r mut [Option<uint>] {
         self.queue.get_mut(i).groups.as_mut_slice()
     }
 }
@@ -527,61 +994,102 @@ pub fn is_word(c: Option<char>) -> bool {
     }
 }
 
-/// Given a character and a single character class range, return an ordering
-/// indicating whether the character is less than the start of the range,
-/// in the range (inclusive) or greater than the end of the range.
-///
-/// If `casei` is `true`, then this ordering is computed case insensitively.
-///
-/// This function is meant to be used with a binary search.
-#[inline]
-fn class_cmp(casei: bool, mut textc: char,
-             (mut start, mut end): (char, char)) -> Ordering {
-    if casei {
-        // FIXME: This is pretty ridiculous. All of this case conversion
-        // can be moved outside this function:
-        // 1) textc should be uppercased outside the bsearch.
-        // 2) the character class itself should be uppercased either in the
-        //    parser or the compiler.
-        // FIXME: This is too simplistic for correct Unicode support.
-        //        See also: char_eq
-        textc = textc.to_uppercase();
-        start = start.to_uppercase();
-        end = end.to_uppercase();
-    }
-    if textc >= start && textc <= end {
-        Equal
-    } else if start > textc {
-        Greater
-    } else {
-        Less
+/// Returns true if the character is an ASCII word character (i.e., in
+/// `[0-9A-Za-z_]`).
+pub fn is_ascii_word(c: Option<char>) -> bool {
+    match c {
+        Some('_') | Some('0' .. '9') | Some('a' .. 'z') | Some('A' .. 'Z') => {
+            true
+        }
+        _ => false,
+    }
+}
+
+/// Returns true if the character is a word character by the Unicode
+/// definition used for word boundaries with the `w` flag: a letter (`\pL`),
+/// a mark (`\pM`), a number (`\pN`), connector punctuation (`\p{Pc}`) or
+/// an ASCII word character. Unlike `is_word`, this keeps a combining mark
+/// in the same word as the letter it follows.
+pub fn is_unicode_word(c: Option<char>) -> bool {
+    let c = match c {
+        None => return false,
+        Some(c) => c,
+    };
+    match c {
+        '_' | '0' .. '9' | 'a' .. 'z' | 'A' .. 'Z' => true,
+        '\x00' .. '\x7F' => false,
+        _ => UNICODE_WORD.bsearch(|&(start, end)| {
+            if c >= start && c <= end {
+                Equal
+            } else if start > c {
+                Greater
+            } else {
+                Less
+            }
+        }).is_some()
     }
 }
 
 /// Returns the starting location of `needle` in `haystack`.
 /// If `needle` is not in `haystack`, then `None` is returned.
 ///
-/// Note that this is using a naive substring algorithm.
+/// Candidate positions are found by scanning for the first byte of `needle`
+/// with `memchr`, and only then is the rest of `needle` compared.
 #[inline]
 pub fn find_prefix(needle: &[u8], haystack: &[u8]) -> Option<uint> {
     let (hlen, nlen) = (haystack.len(), needle.len());
     if nlen > hlen || nlen == 0 {
         return None
     }
+    let (first, rest) = (needle[0], needle.slice_from(1));
+    // The last position at which `needle` could start.
+    let last = hlen - nlen;
     let mut hayi = 0u;
-    'HAYSTACK: loop {
-        if hayi > hlen - nlen {
+    while hayi <= last {
+        match memchr(first, haystack.slice(hayi, last + 1)) {
+            None => return None,
+            Some(i) => hayi += i,
+        }
+        if haystack.slice(hayi + 1, hayi + nlen) == rest {
+            return Some(hayi)
+        }
+        hayi += 1;
+    }
+    None
+}
+
+/// Returns the index of the first occurrence of the byte `b` in `haystack`.
+///
+/// The bytes are compared a machine word at a time, which is much faster than
+/// comparing them one at a time when the byte is rare.
+#[inline]
+pub fn memchr(b: u8, haystack: &[u8]) -> Option<uint> {
+    static LO: u64 = 0x0101010101010101;
+    static HI: u64 = 0x8080808080808080;
+
+    let len = haystack.len();
+    let repeated = (b as u64) * LO;
+    let mut i = 0u;
+    while i + 8 <= len {
+        // This is an unaligned read, which is fine on the platforms we
+        // support. Byte order doesn't matter since we only care whether
+        // *any* byte in the word is a match.
+        let word = unsafe {
+            *(haystack.as_ptr().offset(i as int) as *u64)
+        };
+        // A byte of `x` is zero if and only if the corresponding byte of
+        // `word` is `b`.
+        let x = word ^ repeated;
+        if (x - LO) & !x & HI != 0 {
             break
         }
-        let mut nedi = 0;
-        while nedi < nlen {
-            if haystack[hayi+nedi] != needle[nedi] {
-                hayi += 1;
-                continue 'HAYSTACK
-            }
-            nedi += 1;
+        i += 8;
+    }
+    while i < len {
+        if haystack[i] == b {
+            return Some(i)
         }
-        return Some(hayi)
+        i += 1;
     }
     None
 }